@@ -6,10 +6,13 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -27,6 +30,8 @@ import (
 
 	"github.com/FuturFusion/migration-manager/internal"
 	"github.com/FuturFusion/migration-manager/internal/logger"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/progress"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/vmware"
 	"github.com/FuturFusion/migration-manager/internal/source"
 	"github.com/FuturFusion/migration-manager/internal/util"
 	"github.com/FuturFusion/migration-manager/internal/version"
@@ -208,8 +213,17 @@ func (w *Worker) importDisks(ctx context.Context, cmd api.WorkerCommand) {
 		return
 	}
 
+	if cmd.Command == api.WORKERCOMMAND_FINALIZE_IMPORT && cmd.DiskVerification != api.DISKVERIFICATION_NONE {
+		slog.Info("Verifying imported disks", slog.String("mode", string(cmd.DiskVerification)))
+		err := w.verifyDisks(ctx, cmd)
+		if err != nil {
+			w.sendErrorResponse(err)
+			return
+		}
+	}
+
 	slog.Info("Performing dry-run of post-import steps")
-	err = w.postImportTasks(ctx, cmd, true)
+	_, err = w.postImportTasks(ctx, cmd, true)
 	if err != nil {
 		w.sendErrorResponse(err)
 		return
@@ -226,21 +240,24 @@ func (w *Worker) importDisksHelper(ctx context.Context, cmd api.WorkerCommand) e
 		return err
 	}
 
-	sdkFile, imported, err := w.getArtifact(api.ARTIFACTTYPE_SDK, cmd, "")
-	if err != nil {
-		return err
-	}
-
-	if imported {
-		err := os.RemoveAll(filepath.Dir(worker.VMwareSDKPath))
+	// Only VMware sources need the VDDK to read disks.
+	if cmd.SourceType == api.SOURCETYPE_VMWARE {
+		sdkFile, imported, err := w.getArtifact(api.ARTIFACTTYPE_SDK, cmd, "")
 		if err != nil {
 			return err
 		}
 
-		// unpack the vmware SDK.
-		err = util.UnpackTarball(filepath.Dir(worker.VMwareSDKPath), sdkFile)
-		if err != nil {
-			return fmt.Errorf("Failed to unpack SDK: %w", err)
+		if imported {
+			err := os.RemoveAll(filepath.Dir(worker.VMwareSDKPath))
+			if err != nil {
+				return err
+			}
+
+			// unpack the vmware SDK.
+			err = util.UnpackTarball(filepath.Dir(worker.VMwareSDKPath), sdkFile)
+			if err != nil {
+				return fmt.Errorf("Failed to unpack SDK: %w", err)
+			}
 		}
 	}
 
@@ -256,7 +273,24 @@ func (w *Worker) importDisksHelper(ctx context.Context, cmd api.WorkerCommand) e
 	}
 
 	// Do the actual import.
-	return w.source.ImportDisks(ctx, cmd.Location, worker.VMwareSDKPath, instance.Disks, func(status string, isImportant bool) {
+	tracker := progress.NewTransferTracker(instance.Disks)
+	changeIDs := &changeIDStore{w: w, changeIDs: cmd.ChangeIDs}
+	return w.source.ImportDisks(ctx, cmd.Location, worker.VMwareSDKPath, instance.Disks, changeIDs, func(status string, isImportant bool) {
+		slog.Info(status) //nolint:sloglint
+
+		// Only send updates back to the server if important or once every 5 seconds.
+		if isImportant || time.Since(w.lastUpdate).Seconds() >= 5 {
+			w.lastUpdate = time.Now().UTC()
+			transferProgress := tracker.Progress()
+			w.sendResponse(api.WorkerResponse{Status: api.WORKERRESPONSE_RUNNING, StatusMessage: status, Progress: &transferProgress})
+		}
+	}, tracker.Update)
+}
+
+// verifyDisks compares the imported disks against the source, and reports the results to migration manager.
+// Returns an error if any of the disks differ from the source.
+func (w *Worker) verifyDisks(ctx context.Context, cmd api.WorkerCommand) error {
+	results, err := w.source.VerifyDisks(ctx, cmd.Location, worker.VMwareSDKPath, cmd.DiskVerification, func(status string, isImportant bool) {
 		slog.Info(status) //nolint:sloglint
 
 		// Only send updates back to the server if important or once every 5 seconds.
@@ -265,63 +299,141 @@ func (w *Worker) importDisksHelper(ctx context.Context, cmd api.WorkerCommand) e
 			w.sendStatusResponse(api.WORKERRESPONSE_RUNNING, status)
 		}
 	})
+	if err != nil {
+		return fmt.Errorf("Failed to verify disks: %w", err)
+	}
+
+	w.sendResponse(api.WorkerResponse{Status: api.WORKERRESPONSE_RUNNING, StatusMessage: "Disk verification completed", VerificationResults: results})
+	for _, r := range results {
+		if len(r.MismatchedRanges) > 0 {
+			return fmt.Errorf("Disk %q differs from the source in %d ranges", r.Disk, len(r.MismatchedRanges))
+		}
+	}
+
+	return nil
+}
+
+// changeIDStore keeps the change IDs of the instance's disks on migration manager, so that incremental disk imports survive a restart of the worker.
+type changeIDStore struct {
+	w         *Worker
+	changeIDs []api.DiskChangeID
+}
+
+// GetChangeID returns the change ID recorded by migration manager for the disk, if it was copied to the same target device of the same size.
+func (s *changeIDStore) GetChangeID(ctx context.Context, diskName string, targetDevice string, targetSize int64) (*vmware.ChangeID, error) {
+	for _, c := range s.changeIDs {
+		if c.Disk != diskName {
+			continue
+		}
+
+		if c.TargetDevice != targetDevice {
+			slog.Warn("Recorded change ID belongs to a different target device", slog.String("disk", diskName), slog.String("device", targetDevice), slog.String("recorded_device", c.TargetDevice))
+			return nil, nil
+		}
+
+		// A device of a different size is a different volume, which holds none of the copied data.
+		if c.TargetSize != targetSize {
+			slog.Warn("Recorded change ID belongs to a target device of a different size", slog.String("disk", diskName), slog.String("device", targetDevice), slog.Int64("size", targetSize), slog.Int64("recorded_size", c.TargetSize))
+			return nil, nil
+		}
+
+		return vmware.ParseChangeID(c.ChangeID)
+	}
+
+	return nil, nil
+}
+
+// WriteChangeID reports the change ID of the copied disk to migration manager.
+func (s *changeIDStore) WriteChangeID(ctx context.Context, diskName string, targetDevice string, targetSize int64, snapshot string, changeID *vmware.ChangeID) error {
+	c := api.DiskChangeID{
+		Disk:         diskName,
+		ChangeID:     changeID.Value,
+		Snapshot:     snapshot,
+		TargetDevice: targetDevice,
+		TargetSize:   targetSize,
+		LastUpdated:  time.Now().UTC(),
+	}
+
+	content, err := json.Marshal(api.WorkerResponse{Status: api.WORKERRESPONSE_RUNNING, StatusMessage: fmt.Sprintf("Finished importing disk %q", diskName), ChangeIDs: []api.DiskChangeID{c}})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.w.doHTTPRequestV1("/internal/worker/"+s.w.uuid+"/:update", http.MethodPost, "secret="+s.w.token, content)
+	if err != nil {
+		return fmt.Errorf("Failed to record change ID of disk %q: %w", diskName, err)
+	}
+
+	idx := slices.IndexFunc(s.changeIDs, func(existing api.DiskChangeID) bool { return existing.Disk == diskName })
+	if idx >= 0 {
+		s.changeIDs[idx] = c
+	} else {
+		s.changeIDs = append(s.changeIDs, c)
+	}
+
+	return nil
 }
 
-func (w *Worker) postImportTasks(ctx context.Context, cmd api.WorkerCommand, dryRun bool) error {
+func (w *Worker) postImportTasks(ctx context.Context, cmd api.WorkerCommand, dryRun bool) ([]api.PostMigrationScriptResult, error) {
 	if w.source == nil {
 		err := w.connectSource(ctx, cmd.SourceType, cmd.Source)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	resp, err := w.doHTTPRequestV1("/1.0/instances/"+w.uuid, http.MethodGet, "secret="+w.token+"&instance="+w.uuid, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var instance api.Instance
 	err = responseToStruct(resp, &instance)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	switch cmd.OSType {
 	case api.OSTYPE_WINDOWS:
 		file, _, err := w.getArtifact(api.ARTIFACTTYPE_DRIVER, cmd, "")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err = worker.WindowsInjectDrivers(ctx, cmd.DistributionVersion, cmd.Architecture, file, dryRun)
+		err = worker.WindowsInjectDrivers(ctx, cmd.DistributionVersion, cmd.Architecture, file, cmd.BitLocker, dryRun)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 	case api.OSTYPE_FORTIGATE:
 		ver, err := worker.DetermineFortigateVersion()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		file, _, err := w.getArtifact(api.ARTIFACTTYPE_OSIMAGE, cmd, ver)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		err = worker.ReplaceFortigateBoot(file, dryRun)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 	case api.OSTYPE_LINUX:
-		err := worker.LinuxDoPostMigrationConfig(ctx, instance, cmd.Distribution, cmd.DistributionVersion, dryRun)
-		if err != nil {
-			return err
+		var scripts []worker.UserScript
+		if !dryRun && len(cmd.PostMigrationScripts) > 0 {
+			scripts, err = w.getScriptArtifacts(cmd)
+			if err != nil {
+				return nil, err
+			}
 		}
+
+		return worker.LinuxDoPostMigrationConfig(ctx, instance, cmd.Distribution, cmd.DistributionVersion, scripts, dryRun)
 	}
 
-	return nil
+	return nil, nil
 }
 
 // doPostImportTasks performs some finalizing tasks. If everything is executed
@@ -341,9 +453,9 @@ func (w *Worker) doPostImportTasks(ctx context.Context, cmd api.WorkerCommand) (
 	slog.Info("Performing final migration tasks")
 	w.sendStatusResponse(api.WORKERRESPONSE_RUNNING, "Performing final migration tasks")
 
-	err := w.postImportTasks(ctx, cmd, false)
+	scriptResults, err := w.postImportTasks(ctx, cmd, false)
 	if err != nil {
-		w.sendErrorResponse(err)
+		w.sendFailedResponse(err, scriptResults)
 		return false
 	}
 
@@ -351,7 +463,7 @@ func (w *Worker) doPostImportTasks(ctx context.Context, cmd api.WorkerCommand) (
 	unix.Sync()
 
 	slog.Info("Final migration tasks completed successfully")
-	w.sendStatusResponse(api.WORKERRESPONSE_SUCCESS, "Final migration tasks completed successfully")
+	w.sendResponse(api.WorkerResponse{Status: api.WORKERRESPONSE_SUCCESS, StatusMessage: "Final migration tasks completed successfully", ScriptResults: scriptResults})
 
 	// When we've finished the import, shutdown the worker.
 	return true
@@ -376,6 +488,24 @@ func (w *Worker) connectSource(ctx context.Context, sourceType api.SourceType, s
 			return err
 		}
 
+	case api.SOURCETYPE_OVF:
+		w.source, err = source.NewVMSource(src)
+		if err != nil {
+			return err
+		}
+
+		ovfSource, ok := w.source.(*source.InternalOVFSource)
+		if !ok {
+			return fmt.Errorf("Unexpected implementation %T for source type %q", w.source, src.SourceType)
+		}
+
+		diskURL, err := w.serveDisks(ctx)
+		if err != nil {
+			return err
+		}
+
+		ovfSource.WithDiskURL(diskURL)
+
 	default:
 		return fmt.Errorf("Provided source type %q is not usable with `migration-manager-worker`", sourceType)
 	}
@@ -383,9 +513,52 @@ func (w *Worker) connectSource(ctx context.Context, sourceType api.SourceType, s
 	return w.source.Connect(ctx)
 }
 
+// serveDisks starts a local proxy to the migration manager's disk endpoint, and returns a function that maps disk names to proxy URLs.
+// The proxy adds the worker credentials and pins the migration manager's certificate, which qemu-img is not able to do by itself.
+func (w *Worker) serveDisks(ctx context.Context) (func(diskName string) string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen for disk requests: %w", err)
+	}
+
+	target := *w.endpoint
+	target.Path = "/internal/worker/" + w.uuid + "/disk"
+	proxy := &httputil.ReverseProxy{
+		Transport: w.transport(),
+		Rewrite: func(r *httputil.ProxyRequest) {
+			query := url.Values{}
+			query.Set("secret", w.token)
+			query.Set("instance", w.uuid)
+			query.Set("name", r.In.URL.Query().Get("name"))
+
+			r.Out.URL = &url.URL{Scheme: target.Scheme, Host: target.Host, Path: target.Path, RawQuery: query.Encode()}
+			r.Out.Host = target.Host
+		},
+	}
+
+	server := &http.Server{Handler: proxy, ReadHeaderTimeout: 30 * time.Second}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Disk proxy stopped", logger.Err(err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	return func(diskName string) string {
+		return "http://" + listener.Addr().String() + "/disk?name=" + url.QueryEscape(diskName)
+	}, nil
+}
+
 func (w *Worker) sendStatusResponse(statusVal api.WorkerResponseType, statusMessage string) {
-	resp := api.WorkerResponse{Status: statusVal, StatusMessage: statusMessage}
+	w.sendResponse(api.WorkerResponse{Status: statusVal, StatusMessage: statusMessage})
+}
 
+func (w *Worker) sendResponse(resp api.WorkerResponse) {
 	content, err := json.Marshal(resp)
 	if err != nil {
 		slog.Error("Failed to marshal status response for migration manager", logger.Err(err))
@@ -400,13 +573,18 @@ func (w *Worker) sendStatusResponse(statusVal api.WorkerResponseType, statusMess
 }
 
 func (w *Worker) sendErrorResponse(err error) {
+	w.sendFailedResponse(err, nil)
+}
+
+// sendFailedResponse reports the error to migration manager, along with the results of any post-migration scripts that were run.
+func (w *Worker) sendFailedResponse(err error, scriptResults []api.PostMigrationScriptResult) {
 	slog.Error("worker error", logger.Err(err))
 	b, err2 := os.ReadFile(w.logFile)
 	if err2 != nil && !os.IsNotExist(err2) {
 		slog.Error("Failed to read log file", slog.String("file", w.logFile), slog.Any("error", err2))
 	}
 
-	resp := api.WorkerResponse{Status: api.WORKERRESPONSE_FAILED, StatusMessage: err.Error(), Metadata: b}
+	resp := api.WorkerResponse{Status: api.WORKERRESPONSE_FAILED, StatusMessage: err.Error(), Metadata: b, ScriptResults: scriptResults}
 	content, err := json.Marshal(resp)
 	if err != nil {
 		slog.Error("Failed to send error back to migration manager", logger.Err(err))
@@ -431,7 +609,13 @@ func (w *Worker) makeRequest(endpoint string, method string, query string, reade
 		return nil, nil, err
 	}
 
-	transport := &http.Transport{
+	client := &http.Client{Transport: w.transport()}
+	return req, client, nil
+}
+
+// transport returns an HTTP transport that only trusts the migration manager's certificate fingerprint.
+func (w *Worker) transport() *http.Transport {
+	return &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
 			VerifyPeerCertificate: func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
@@ -457,9 +641,6 @@ func (w *Worker) makeRequest(endpoint string, method string, query string, reade
 			},
 		},
 	}
-
-	client := &http.Client{Transport: transport}
-	return req, client, nil
 }
 
 func (w *Worker) doHTTPRequestV1Writer(endpoint string, method string, query string, writer io.WriteSeeker) error {
@@ -641,6 +822,77 @@ func (w *Worker) getArtifact(artifactType api.ArtifactType, cmd api.WorkerComman
 	return artifactPath, newArtifact, nil
 }
 
+// getScriptArtifacts downloads the files of the given script artifacts that apply to the OS of the instance, in the order they should be run.
+func (w *Worker) getScriptArtifacts(cmd api.WorkerCommand) ([]worker.UserScript, error) {
+	query := fmt.Sprintf("secret=%s&instance=%s", w.token, w.uuid)
+	resp, err := w.doHTTPRequestV1("/1.0/artifacts", http.MethodGet, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []api.Artifact
+	err = responseToStruct(resp, &artifacts)
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := []worker.UserScript{}
+	for _, script := range cmd.PostMigrationScripts {
+		idx := slices.IndexFunc(artifacts, func(a api.Artifact) bool { return a.UUID == script.Artifact })
+		if idx < 0 {
+			return nil, fmt.Errorf("Failed to find post-migration script artifact %q", script.Artifact)
+		}
+
+		artifact := artifacts[idx]
+		if artifact.Type != api.ARTIFACTTYPE_SCRIPT {
+			return nil, fmt.Errorf("Artifact %q is not a script", artifact.UUID)
+		}
+
+		if artifact.OS != cmd.OSType {
+			continue
+		}
+
+		dir := filepath.Join("/tmp", artifact.UUID.String())
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return nil, err
+		}
+
+		// Track the artifact so its directory is cleaned up with the others.
+		w.lastArtifactUpdates[artifact.UUID] = artifact.LastUpdated
+
+		files := slices.Clone(artifact.Files)
+		slices.Sort(files)
+		for _, file := range files {
+			path := filepath.Join(dir, file)
+			err := w.downloadArtifactFile(artifact.UUID, file, path, query)
+			if err != nil {
+				return nil, err
+			}
+
+			scripts = append(scripts, worker.UserScript{Artifact: artifact.UUID, Path: path, Timeout: script.Timeout.Duration})
+		}
+	}
+
+	return scripts, nil
+}
+
+func (w *Worker) downloadArtifactFile(artifactUUID uuid.UUID, file string, path string, query string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return w.doHTTPRequestV1Writer("/1.0/artifacts/"+artifactUUID.String()+"/files/"+file, http.MethodGet, query, f)
+}
+
 func (w *Worker) matchSourceArtifact(artifacts []api.Artifact, cmd api.WorkerCommand) (*api.Artifact, error) {
 	var artifact *api.Artifact
 	for _, a := range artifacts {
@@ -22,6 +22,8 @@ import (
 
 	"github.com/FuturFusion/migration-manager/cmd/migration-manager-worker/internal/worker"
 	"github.com/FuturFusion/migration-manager/internal/logger"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/vmware"
 	"github.com/FuturFusion/migration-manager/internal/server/response"
 	"github.com/FuturFusion/migration-manager/internal/source"
 	"github.com/FuturFusion/migration-manager/shared/api"
@@ -32,10 +34,11 @@ const uuidA = "ced6491e-b614-11ef-a01b-677fcc190026"
 var errGracefulEndOfTest = fmt.Errorf("graceful end of test")
 
 type instanceDetails struct {
-	Location      string
-	OSType        api.OSType
-	Distro        api.Distro
-	DistroVersion string
+	Location         string
+	OSType           api.OSType
+	Distro           api.Distro
+	DistroVersion    string
+	DiskVerification api.DiskVerificationMode
 }
 
 func TestNewWorker(t *testing.T) {
@@ -107,6 +110,8 @@ func TestRun(t *testing.T) {
 		sourceDeleteVMSnapshotErr  error
 		sourceImportDisksErr       error
 		sourcePowerOffVMErr        error
+		sourceWritesChangeID       bool
+		sourceVerifyDisksResults   []api.DiskVerificationResult
 
 		wantWorkerResponses []api.WorkerResponseType
 		wantEndOfTestCause  error
@@ -134,6 +139,21 @@ func TestRun(t *testing.T) {
 			},
 			wantEndOfTestCause: errGracefulEndOfTest,
 		},
+		{
+			name: "success - import disks records change IDs",
+			migrationManagerdResponses: []func(instanceSpec instanceDetails, cancel context.CancelCauseFunc, w http.ResponseWriter, r *http.Request){
+				workerCommandResponse(api.WORKERCOMMAND_IDLE, false), // newWorker connectivity test
+				workerCommandResponse(api.WORKERCOMMAND_IMPORT_DISKS, false),
+				workerCommandResponse(api.WORKERCOMMAND_IDLE, true),
+			},
+			sourceWritesChangeID: true,
+
+			wantWorkerResponses: []api.WorkerResponseType{
+				api.WORKERRESPONSE_RUNNING,
+				api.WORKERRESPONSE_SUCCESS,
+			},
+			wantEndOfTestCause: errGracefulEndOfTest,
+		},
 		{
 			name: "success - finalize import",
 			migrationManagerdResponses: []func(instanceSpec instanceDetails, cancel context.CancelCauseFunc, w http.ResponseWriter, r *http.Request){
@@ -147,6 +167,22 @@ func TestRun(t *testing.T) {
 			},
 			wantEndOfTestCause: errGracefulEndOfTest, // if finalize import is successful, the worker ends it self gracefully, so no cause is given.
 		},
+		{
+			name: "success - finalize import with disk verification",
+			migrationManagerdResponses: []func(instanceSpec instanceDetails, cancel context.CancelCauseFunc, w http.ResponseWriter, r *http.Request){
+				workerCommandResponse(api.WORKERCOMMAND_IDLE, false), // newWorker connectivity test
+				workerCommandResponse(api.WORKERCOMMAND_FINALIZE_IMPORT, false),
+				workerCommandResponse(api.WORKERCOMMAND_IDLE, true),
+			},
+			instanceSpec:             instanceDetails{DiskVerification: api.DISKVERIFICATION_SAMPLE},
+			sourceVerifyDisksResults: []api.DiskVerificationResult{{Disk: "[datastore] vm/vm.vmdk", Mode: api.DISKVERIFICATION_SAMPLE, BytesVerified: 4096, BytesTotal: 65536}},
+
+			wantWorkerResponses: []api.WorkerResponseType{
+				api.WORKERRESPONSE_RUNNING,
+				api.WORKERRESPONSE_SUCCESS,
+			},
+			wantEndOfTestCause: errGracefulEndOfTest,
+		},
 		{
 			name: "success - post- import",
 			migrationManagerdResponses: []func(instanceSpec instanceDetails, cancel context.CancelCauseFunc, w http.ResponseWriter, r *http.Request){
@@ -282,6 +318,28 @@ func TestRun(t *testing.T) {
 			},
 			wantEndOfTestCause: errGracefulEndOfTest,
 		},
+		{
+			name: "error - finalize import disk verification mismatch",
+			migrationManagerdResponses: []func(instanceSpec instanceDetails, cancel context.CancelCauseFunc, w http.ResponseWriter, r *http.Request){
+				workerCommandResponse(api.WORKERCOMMAND_IDLE, false), // newWorker connectivity test
+				workerCommandResponse(api.WORKERCOMMAND_FINALIZE_IMPORT, false),
+				workerCommandResponse(api.WORKERCOMMAND_IDLE, true),
+			},
+			instanceSpec: instanceDetails{DiskVerification: api.DISKVERIFICATION_FULL},
+			sourceVerifyDisksResults: []api.DiskVerificationResult{{
+				Disk:             "[datastore] vm/vm.vmdk",
+				Mode:             api.DISKVERIFICATION_FULL,
+				BytesVerified:    65536,
+				BytesTotal:       65536,
+				MismatchedRanges: []api.DiskRange{{Offset: 4096, Length: 4096}},
+			}},
+
+			wantWorkerResponses: []api.WorkerResponseType{
+				api.WORKERRESPONSE_RUNNING,
+				api.WORKERRESPONSE_FAILED,
+			},
+			wantEndOfTestCause: errGracefulEndOfTest,
+		},
 		{
 			name: "error - post import unknown version of windows",
 			migrationManagerdResponses: []func(instanceSpec instanceDetails, cancel context.CancelCauseFunc, w http.ResponseWriter, r *http.Request){
@@ -476,12 +534,22 @@ func TestRun(t *testing.T) {
 				DeleteVMSnapshotFunc: func(ctx context.Context, vmName string, snapshotName string) error {
 					return tc.sourceDeleteVMSnapshotErr
 				},
-				ImportDisksFunc: func(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, statusCallback func(string, bool)) error {
+				ImportDisksFunc: func(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
+					if tc.sourceWritesChangeID {
+						err := changeIDs.WriteChangeID(ctx, "[datastore] vm/vm.vmdk", "root", 10737418240, "snapshot-1", &vmware.ChangeID{Value: "52 d2 3a 5e/12"})
+						if err != nil {
+							return err
+						}
+					}
+
 					return tc.sourceImportDisksErr
 				},
 				PowerOffVMFunc: func(ctx context.Context, vmName string) error {
 					return tc.sourcePowerOffVMErr
 				},
+				VerifyDisksFunc: func(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error) {
+					return tc.sourceVerifyDisksResults, nil
+				},
 			}
 
 			worker, err := worker.NewWorker(context.Background(), client,
@@ -534,6 +602,7 @@ func workerCommandResponse(command api.WorkerCommandType, signalEndOfTest bool)
 			Location:            instanceSpec.Location,
 			Architecture:        "x86_64",
 			SourceType:          api.SOURCETYPE_VMWARE,
+			DiskVerification:    instanceSpec.DiskVerification,
 		}
 
 		metadata, err := json.Marshal(cmd)
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -276,10 +277,11 @@ func (c *cmdArtifactUpload) Command() *cobra.Command {
 
 	Upload an artifact file with the given set of properties.
 
-	Supported types: sdk, os-image, driver
+	Supported types: sdk, os-image, driver, script
 
 	- 'architectures' is a comma-delimited list applying to 'driver' and 'os-image'.
 	- 'versions' is a comma-delimited list applying to 'os-image'.
+	- 'script' artifacts take an OS name of 'linux' or 'windows', and keep the name of the uploaded file.
 	`
 
 	cmd.RunE = c.Run
@@ -312,6 +314,13 @@ func (c *cmdArtifactUpload) Run(cmd *cobra.Command, args []string) error {
 		if len(args) == 5 {
 			data.Versions = strings.Split(args[4], ",")
 		}
+	} else if data.Type == api.ARTIFACTTYPE_SCRIPT {
+		exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+		if exit {
+			return err
+		}
+
+		data.OS = api.OSType(args[1])
 	} else {
 		exit, err := c.global.CheckArgs(cmd, args, 3, 3)
 		if exit {
@@ -362,8 +371,13 @@ func (c *cmdArtifactUpload) Run(cmd *cobra.Command, args []string) error {
 		},
 	}
 
+	query := ""
+	if data.Type == api.ARTIFACTTYPE_SCRIPT {
+		query = "name=" + url.QueryEscape(filepath.Base(filePath))
+	}
+
 	location = strings.TrimPrefix(location, "/"+api.APIVersion)
-	_, _, err = c.global.doHTTPRequestV1Reader(location+"/files", http.MethodPost, "", reader)
+	_, _, err = c.global.doHTTPRequestV1Reader(location+"/files", http.MethodPost, query, reader)
 	if err != nil {
 		return err
 	}
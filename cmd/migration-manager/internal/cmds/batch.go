@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -57,6 +59,10 @@ func (c *CmdBatch) Command() *cobra.Command {
 	batchStartCmd := cmdBatchStart{global: c.Global}
 	cmd.AddCommand(batchStartCmd.Command())
 
+	// Plan
+	batchPlanCmd := cmdBatchPlan{global: c.Global}
+	cmd.AddCommand(batchPlanCmd.Command())
+
 	// Stop
 	batchStopCmd := cmdBatchStop{global: c.Global}
 	cmd.AddCommand(batchStopCmd.Command())
@@ -69,6 +75,10 @@ func (c *CmdBatch) Command() *cobra.Command {
 	batchEditCmd := cmdBatchEdit{global: c.Global}
 	cmd.AddCommand(batchEditCmd.Command())
 
+	// Import windows
+	batchImportWindowsCmd := cmdBatchImportWindows{global: c.Global}
+	cmd.AddCommand(batchImportWindowsCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -118,6 +128,7 @@ func (c *cmdBatchAdd) Run(cmd *cobra.Command, args []string) error {
 		BatchPut: api.BatchPut{
 			Name:              args[0],
 			Constraints:       []api.BatchConstraint{},
+			InstanceGroups:    []api.BatchInstanceGroup{},
 			IncludeExpression: "false",
 			MigrationWindows:  []api.MigrationWindow{},
 			Defaults: api.BatchDefaults{
@@ -403,6 +414,93 @@ func (c *cmdBatchStart) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Plan the batch.
+type cmdBatchPlan struct {
+	global *CmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdBatchPlan) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "plan <name>"
+	cmd.Short = "Show the migration plan of a batch"
+	cmd.Long = `Description:
+  Show how the instances of a batch would be migrated if it were started.
+
+  Runs placement, restriction, artifact, target and migration window checks without persisting anything.
+`
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", `Format (csv|json|table|yaml|compact), use suffix ",noheader" to disable headers and ",header" to enable if demanded, e.g. csv,header`)
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return validateFlagFormat(cmd.Flag("format").Value.String())
+	}
+
+	return cmd
+}
+
+func (c *cmdBatchPlan) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	name := args[0]
+
+	// Plan the batch.
+	resp, _, err := c.global.doHTTPRequestV1("/batches/"+name+"/:plan", http.MethodPost, "", nil)
+	if err != nil {
+		return err
+	}
+
+	plan := []api.BatchPlanEntry{}
+	err = responseToStruct(resp, &plan)
+	if err != nil {
+		return err
+	}
+
+	// Render the table.
+	header := []string{"Location", "Status", "Target", "Project", "Storage Pools", "Networks", "Migration Window", "Blocking Reasons"}
+	data := [][]string{}
+
+	for _, p := range plan {
+		pools := []string{}
+		for _, pool := range p.Placement.StoragePools {
+			if !slices.Contains(pools, pool) {
+				pools = append(pools, pool)
+			}
+		}
+
+		networks := []string{}
+		for _, net := range p.Placement.Networks {
+			name := net.Network
+			if slices.ContainsFunc(p.CreatedNetworks, func(n api.BatchCreatedNetwork) bool { return n.Name == net.Network }) {
+				name += " (new)"
+			}
+
+			if !slices.Contains(networks, name) {
+				networks = append(networks, name)
+			}
+		}
+
+		sort.Strings(pools)
+		sort.Strings(networks)
+
+		window := "none"
+		if p.MigrationWindow.Name != "" {
+			window = fmt.Sprintf("%s (%s - %s)", p.MigrationWindow.Name, p.MigrationWindow.Start.String(), p.MigrationWindow.End.String())
+		}
+
+		data = append(data, []string{p.Location, string(p.MigrationStatus), p.Placement.TargetName, p.Placement.TargetProject, strings.Join(pools, ", "), strings.Join(networks, ", "), window, strings.Join(p.BlockingReasons, "\n")})
+	}
+
+	sort.Sort(util.SortColumnsNaturally(data))
+
+	return util.RenderTable(cmd.OutOrStdout(), c.flagFormat, header, data, plan)
+}
+
 // Stop the batch.
 type cmdBatchStop struct {
 	global *CmdGlobal
@@ -485,6 +583,66 @@ func (c *cmdBatchReset) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Import migration windows into the batch.
+type cmdBatchImportWindows struct {
+	global *CmdGlobal
+
+	flagCapacity int
+	flagFreeze   bool
+}
+
+func (c *cmdBatchImportWindows) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "import-windows <name> <file.ics>"
+	cmd.Short = "Import migration windows from an iCalendar file"
+	cmd.Long = `Description:
+  Add a migration window to the batch for each upcoming event in an iCalendar file.
+
+  Existing migration windows with the same name are replaced, keeping their capacity unless the event or --capacity sets one.
+  Freeze events (those in the FREEZE category, or all events with --freeze) are instead added as exclusions,
+  which trim or remove the migration windows of the batch that overlap them.
+  Recurring events are not supported.
+`
+
+	cmd.Flags().IntVar(&c.flagCapacity, "capacity", 0, "Number of instances that can be assigned to each imported window whose event does not set a capacity (0 for unlimited)")
+	cmd.Flags().BoolVar(&c.flagFreeze, "freeze", false, "Import all events as freeze events, during which no migrations can take place")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdBatchImportWindows) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	name := args[0]
+
+	content, err := os.ReadFile(args[1])
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if cmd.Flags().Changed("capacity") {
+		query.Set("capacity", strconv.Itoa(c.flagCapacity))
+	}
+
+	if c.flagFreeze {
+		query.Set("freeze", "1")
+	}
+
+	_, _, err = c.global.doHTTPRequestV1("/batches/"+name+"/:import-windows", http.MethodPost, query.Encode(), content)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Successfully imported migration windows into batch %q.\n", name)
+	return nil
+}
+
 // Edit the batch.
 type cmdBatchEdit struct {
 	global *CmdGlobal
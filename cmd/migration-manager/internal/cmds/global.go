@@ -376,6 +376,31 @@ func (c *CmdGlobal) doHTTPRequestV1Writer(endpoint string, method string, writer
 	return nil
 }
 
+// doHTTPRequestV1Stream performs a GET request against a streaming endpoint and returns the response body for reading as data arrives.
+func (c *CmdGlobal) doHTTPRequestV1Stream(endpoint string, query string) (io.ReadCloser, error) {
+	req, client, err := c.buildRequest(endpoint, http.MethodGet, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.requestFunc(client)(req) //nolint:bodyclose // The body is returned to the caller.
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		_, err := c.parseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("Received unexpected status from the server: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
 func responseToStruct(response *incusAPI.Response, targetStruct any) error {
 	return json.Unmarshal(response.Metadata, &targetStruct)
 }
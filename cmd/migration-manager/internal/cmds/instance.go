@@ -37,6 +37,10 @@ func (c *CmdInstance) Command() *cobra.Command {
 	instanceShowCmd := cmdInstanceShow{global: c.Global}
 	cmd.AddCommand(instanceShowCmd.Command())
 
+	// Network ACLs
+	instanceNetworkACLsCmd := cmdInstanceNetworkACLs{global: c.Global}
+	cmd.AddCommand(instanceNetworkACLsCmd.Command())
+
 	// Override
 	instanceOverrideCmd := CmdInstanceOverride{Global: c.Global}
 	cmd.AddCommand(instanceOverrideCmd.Command())
@@ -175,3 +179,51 @@ func (c *cmdInstanceShow) Run(cmd *cobra.Command, args []string) error {
 	fmt.Println(string(b))
 	return nil
 }
+
+// Preview the network ACLs of an instance.
+type cmdInstanceNetworkACLs struct {
+	global *CmdGlobal
+}
+
+func (c *cmdInstanceNetworkACLs) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "network-acls <uuid>"
+	cmd.Short = "Preview instance network ACLs"
+	cmd.Long = `Description:
+  Preview the network ACLs generated from the source firewall rules that apply
+  to the instance, as YAML. Rules that cannot be represented are listed as warnings.
+
+  Network ACLs are only applied if "migrate_network_acls" is enabled on the batch.
+`
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdInstanceNetworkACLs) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	resp, _, err := c.global.doHTTPRequestV1("/instances/"+args[0]+"/network-acls", http.MethodGet, "", nil)
+	if err != nil {
+		return err
+	}
+
+	var obj api.InstanceNetworkACLs
+	err = responseToStruct(resp, &obj)
+	if err != nil {
+		return err
+	}
+
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(b))
+	return nil
+}
@@ -0,0 +1,188 @@
+package cmds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+type CmdMonitor struct {
+	Global *CmdGlobal
+
+	flagTypes    []string
+	flagLogLevel string
+	flagFormat   string
+}
+
+func (c *CmdMonitor) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "monitor"
+	cmd.Short = "Monitor events"
+	cmd.Long = `Description:
+  Monitor lifecycle and logging events as they occur.
+
+  By default, all event types are shown. Logging events are shown according
+  to the server's log level, unless a different level is requested.
+`
+
+	cmd.Example = `  migration-manager monitor
+      Show all lifecycle and logging events.
+
+  migration-manager monitor --type=lifecycle
+      Only show lifecycle events.
+
+  migration-manager monitor --type=logging --loglevel=debug
+      Show all log messages, including debug messages.`
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringSliceVar(&c.flagTypes, "type", []string{}, "Event type(s) to listen for (lifecycle|logging)")
+	cmd.Flags().StringVar(&c.flagLogLevel, "loglevel", "", "Minimum level for logging events (debug|info|warn|error)")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "pretty", "Format (json|pretty|yaml)")
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		for _, t := range c.flagTypes {
+			switch api.LogScope(t) {
+			case api.LogScopeLifecycle:
+			case api.LogScopeLogging:
+			default:
+				return fmt.Errorf("Unknown event type %q", t)
+			}
+		}
+
+		switch c.flagFormat {
+		case "json":
+		case "pretty":
+		case "yaml":
+		default:
+			return fmt.Errorf("Invalid format %q", c.flagFormat)
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+func (c *CmdMonitor) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.Global.CheckArgs(cmd, args, 0, 0)
+	if exit {
+		return err
+	}
+
+	query := url.Values{}
+	if len(c.flagTypes) > 0 {
+		query.Set("type", strings.Join(c.flagTypes, ","))
+	}
+
+	if c.flagLogLevel != "" {
+		query.Set("level", strings.ToUpper(c.flagLogLevel))
+	}
+
+	stream, err := c.Global.doHTTPRequestV1Stream("/events", query.Encode())
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stream.Close() }()
+
+	decoder := json.NewDecoder(stream)
+	for {
+		var event api.Event
+		err := decoder.Decode(&event)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("Failed to read event stream: %w", err)
+		}
+
+		err = c.render(cmd.OutOrStdout(), event)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// render writes a single event to the writer in the selected format.
+func (c *CmdMonitor) render(w io.Writer, event api.Event) error {
+	switch c.flagFormat {
+	case "json":
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "yaml":
+		var metadata any
+		err := json.Unmarshal(event.Metadata, &metadata)
+		if err != nil {
+			return err
+		}
+
+		b, err := yaml.Marshal(map[string]any{"time": event.Time, "type": event.Type, "metadata": metadata})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+
+	timestamp := event.Time.Local().Format(time.DateTime)
+	switch event.Type {
+	case api.LogScopeLogging:
+		var logEvent api.EventLogging
+		err := json.Unmarshal(event.Metadata, &logEvent)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(logEvent.Context))
+		for k := range logEvent.Context {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		ctx := make([]string, 0, len(keys))
+		for _, k := range keys {
+			ctx = append(ctx, fmt.Sprintf("%s=%q", k, logEvent.Context[k]))
+		}
+
+		_, err = fmt.Fprintf(w, "%s %-5s %s %s\n", timestamp, logEvent.Level, logEvent.Message, strings.Join(ctx, " "))
+		return err
+	case api.LogScopeLifecycle:
+		var lifecycle api.EventLifecycle
+		err := json.Unmarshal(event.Metadata, &lifecycle)
+		if err != nil {
+			return err
+		}
+
+		requestor := ""
+		if lifecycle.Requestor != nil {
+			b, err := lifecycle.Requestor.MarshalText()
+			if err != nil {
+				return err
+			}
+
+			requestor = " by " + string(b)
+		}
+
+		_, err = fmt.Fprintf(w, "%s %s [%s]%s\n", timestamp, lifecycle.Action, strings.Join(lifecycle.Entities, ", "), requestor)
+		return err
+	}
+
+	return nil
+}
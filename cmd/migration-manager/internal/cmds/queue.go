@@ -6,6 +6,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/lxc/incus/v6/shared/units"
 	"github.com/spf13/cobra"
 
 	"github.com/FuturFusion/migration-manager/internal/util"
@@ -42,10 +43,18 @@ func (c *CmdQueue) Command() *cobra.Command {
 	queueRetryCmd := cmdQueueRetry{global: c.Global}
 	cmd.AddCommand(queueRetryCmd.Command())
 
+	// Rollback
+	queueRollbackCmd := cmdQueueRollback{global: c.Global}
+	cmd.AddCommand(queueRollbackCmd.Command())
+
 	// Resolve
 	queueResolveCmd := cmdQueueResolve{global: c.Global}
 	cmd.AddCommand(queueResolveCmd.Command())
 
+	// History
+	queueHistoryCmd := cmdQueueHistory{global: c.Global}
+	cmd.AddCommand(queueHistoryCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -101,7 +110,7 @@ func (c *cmdQueueList) Run(cmd *cobra.Command, args []string) error {
 
 	// Render the table.
 	batchesByName := map[string]api.Batch{}
-	header := []string{"UUID", "Name", "Batch", "Last Update", "Status", "Status Message", "Migration Window"}
+	header := []string{"UUID", "Name", "Batch", "Last Update", "Status", "Status Message", "Transfer Progress", "Migration Window"}
 	if c.flagVerbose {
 		header = append(header, "Batch Status", "Batch Status Message", "Target", "Target Project`")
 
@@ -143,7 +152,7 @@ func (c *cmdQueueList) Run(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		row := []string{q.InstanceUUID.String(), q.InstanceName, q.BatchName, lastUpdate, string(q.MigrationStatus), q.MigrationStatusMessage, window}
+		row := []string{q.InstanceUUID.String(), q.InstanceName, q.BatchName, lastUpdate, string(q.MigrationStatus), q.MigrationStatusMessage, transferProgressString(q.TransferProgress), window}
 		if c.flagVerbose {
 			row = append(row, string(batchesByName[q.BatchName].Status), batchesByName[q.BatchName].StatusMessage, q.Placement.TargetName, q.Placement.TargetProject)
 		}
@@ -156,6 +165,88 @@ func (c *cmdQueueList) Run(cmd *cobra.Command, args []string) error {
 	return util.RenderTable(cmd.OutOrStdout(), c.flagFormat, header, data, queueEntries)
 }
 
+// Show the migration history of a queue entry.
+type cmdQueueHistory struct {
+	global *CmdGlobal
+
+	flagFormat string
+}
+
+func (c *cmdQueueHistory) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "history <instance UUID>"
+	cmd.Short = "Show the migration history of the queue entry"
+	cmd.Long = `Description:
+  Show the migration state transitions recorded for the instance, oldest first.
+
+  The history remains available after the batch has finished or was removed.
+`
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", `Format (csv|json|table|yaml|compact), use suffix ",noheader" to disable headers and ",header" to enable if demanded, e.g. csv,header`)
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return validateFlagFormat(cmd.Flag("format").Value.String())
+	}
+
+	return cmd
+}
+
+func (c *cmdQueueHistory) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	instanceUUID := args[0]
+
+	// Get the migration history.
+	resp, _, err := c.global.doHTTPRequestV1("/queue/"+instanceUUID+"/history", http.MethodGet, "", nil)
+	if err != nil {
+		return err
+	}
+
+	history := []api.QueueHistoryEntry{}
+
+	err = responseToStruct(resp, &history)
+	if err != nil {
+		return err
+	}
+
+	// Render the table.
+	header := []string{"Date", "Batch", "Status", "Status Message", "Import Stage", "Migration Window"}
+	data := [][]string{}
+
+	for _, h := range history {
+		window := h.MigrationWindowName
+		if window == "" {
+			window = "none"
+		}
+
+		data = append(data, []string{h.Date.Format(time.DateTime), h.BatchName, string(h.MigrationStatus), h.MigrationStatusMessage, h.ImportStage, window})
+	}
+
+	return util.RenderTable(cmd.OutOrStdout(), c.flagFormat, header, data, history)
+}
+
+// transferProgressString returns a summary of the disk transfer progress, including the transfer rate and estimated time remaining.
+func transferProgressString(progress *api.DiskTransferProgress) string {
+	if progress == nil || progress.BytesTotal == 0 {
+		return ""
+	}
+
+	summary := fmt.Sprintf("%.2f%% (%s/%s)", float64(progress.BytesCopied)/float64(progress.BytesTotal)*100, units.GetByteSizeStringIEC(progress.BytesCopied, 2), units.GetByteSizeStringIEC(progress.BytesTotal, 2))
+	if progress.BytesPerSecond > 0 {
+		summary = fmt.Sprintf("%s, %s/s", summary, units.GetByteSizeStringIEC(progress.BytesPerSecond, 2))
+	}
+
+	if progress.ETA.Duration > 0 {
+		summary = fmt.Sprintf("%s, ETA %s", summary, progress.ETA.String())
+	}
+
+	return summary
+}
+
 // Remove the queue entry.
 type cmdQueueRemove struct {
 	global *CmdGlobal
@@ -276,6 +367,55 @@ func (c *cmdQueueRetry) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Roll back the queue entry.
+type cmdQueueRollback struct {
+	global *CmdGlobal
+
+	flagDelete bool
+}
+
+func (c *cmdQueueRollback) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "rollback <instance UUID>"
+	cmd.Short = "Roll back a finished queue entry"
+	cmd.Long = `Description:
+  Roll back a finished migration to the source VM.
+
+  The target instance is stopped and renamed with a "-rolled-back" suffix, or deleted if --delete is given.
+  The source VM is powered back on if it was running before the migration.
+  Rollback is only possible within the rollback grace period configured on the batch.
+`
+
+	cmd.Flags().BoolVarP(&c.flagDelete, "delete", "d", false, "Delete the target instance instead of renaming it")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdQueueRollback) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	instanceUUID := args[0]
+	query := ""
+	if c.flagDelete {
+		query = "delete=1"
+	}
+
+	// Roll back the queue entry.
+	_, _, err = c.global.doHTTPRequestV1("/queue/"+instanceUUID+"/:rollback", http.MethodPost, query, nil)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Successfully rolled back queue entry %q.\n", instanceUUID)
+	return nil
+}
+
 // Resolve the queue entry.
 type cmdQueueResolve struct {
 	global *CmdGlobal
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lxc/incus/v6/shared/validate"
 	"github.com/spf13/cobra"
 
@@ -17,7 +18,7 @@ import (
 	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
-var supportedSourceTypes = []string{string(api.SOURCETYPE_VMWARE), string(api.SOURCETYPE_NSX)}
+var supportedSourceTypes = []string{string(api.SOURCETYPE_VMWARE), string(api.SOURCETYPE_NSX), string(api.SOURCETYPE_OVF), string(api.SOURCETYPE_VMWARE_DUMP)}
 
 type CmdSource struct {
 	Global *CmdGlobal
@@ -45,6 +46,10 @@ func (c *CmdSource) Command() *cobra.Command {
 	sourceRemoveCmd := cmdSourceRemove{global: c.Global}
 	cmd.AddCommand(sourceRemoveCmd.Command())
 
+	// Sync
+	sourceSyncCmd := cmdSourceSync{global: c.Global}
+	cmd.AddCommand(sourceSyncCmd.Command())
+
 	// Update
 	sourceUpdateCmd := cmdSourceUpdate{global: c.Global}
 	cmd.AddCommand(sourceUpdateCmd.Command())
@@ -65,7 +70,7 @@ type cmdSourceAdd struct {
 
 func (c *cmdSourceAdd) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = "add [type] <name> <IP|FQDN|URL>"
+	cmd.Use = "add [type] <name> <IP|FQDN|URL|path>"
 	cmd.Short = "Add a new source"
 	cmd.Long = `Description:
   Add a new source
@@ -73,6 +78,13 @@ func (c *cmdSourceAdd) Command() *cobra.Command {
   Adds a new source for the migration manager to use. The "type" argument is optional,
   and defaults to "vmware" if not specified.
 
+  For "ovf" sources, the last argument is the absolute path of a directory on the migration
+  manager host containing OVF descriptors or OVA archives, such as those exported by Hyper-V.
+
+  For "vmware-dump" sources, the last argument is the absolute path of a directory created by
+  dumping a VMware source, or of a tar archive of such a directory, on the migration manager host.
+  Instances are replayed from the dump, and can not be migrated.
+
   Depending on the source type, you may be prompted for additional information required
   to connect to the source.
 `
@@ -164,46 +176,107 @@ func (c *cmdSourceAdd) Run(cmd *cobra.Command, args []string) error {
 			Datacenters:                         strings.Split(dcPathStr, ","),
 		}
 
-		s := api.Source{
-			SourcePut: api.SourcePut{
-				Name: sourceName,
-			},
-			SourceType: api.SourceType(sourceType),
+		err = c.addSource(cmd, sourceName, api.SourceType(sourceType), vmwareProperties)
+		if err != nil {
+			return err
 		}
 
-		s.Properties, err = json.Marshal(vmwareProperties)
+	case api.SOURCETYPE_OVF:
+		var importLimit int64 = 50
+		importLimit, err = c.global.Asker.AskInt(fmt.Sprintf("How many instances can be concurrently imported? [default=%d]: ", importLimit), 0, 1024, strconv.Itoa(int(importLimit)), nil)
 		if err != nil {
 			return err
 		}
 
-		// Insert into database.
-		content, err := json.Marshal(s)
+		connTimeoutStr := (time.Minute * 10).String()
+		connTimeoutStr, err = c.global.Asker.AskString(fmt.Sprintf("Connection timeout for the source [default=%s]: ", connTimeoutStr), connTimeoutStr, nil)
 		if err != nil {
 			return err
 		}
 
-		resp, _, err := c.global.doHTTPRequestV1("/sources", http.MethodPost, "", content)
+		connTimeout, err := api.ParseDuration(connTimeoutStr)
+		if err != nil {
+			return err
+		}
+
+		ovfProperties := api.OVFProperties{
+			Path:              sourceEndpoint,
+			ImportLimit:       int(importLimit),
+			ConnectionTimeout: connTimeout,
+		}
+
+		err = c.addSource(cmd, sourceName, api.SourceType(sourceType), ovfProperties)
+		if err != nil {
+			return err
+		}
+
+	case api.SOURCETYPE_VMWARE_DUMP:
+		connTimeoutStr := (time.Minute * 10).String()
+		connTimeoutStr, err = c.global.Asker.AskString(fmt.Sprintf("Connection timeout for the source [default=%s]: ", connTimeoutStr), connTimeoutStr, nil)
 		if err != nil {
 			return err
 		}
 
-		metadata := make(map[string]string)
-		err = json.Unmarshal(resp.Metadata, &metadata)
+		connTimeout, err := api.ParseDuration(connTimeoutStr)
 		if err != nil {
 			return err
 		}
 
-		connectivityStatus := api.ExternalConnectivityStatus(metadata["ConnectivityStatus"])
+		dumpProperties := api.VMwareDumpProperties{
+			Path:              sourceEndpoint,
+			ConnectionTimeout: connTimeout,
+		}
 
-		if connectivityStatus == api.EXTERNALCONNECTIVITYSTATUS_TLS_CONFIRM_FINGERPRINT {
-			return fmt.Errorf("Successfully added new source %q, but received an untrusted TLS server certificate with fingerprint %s. Please update the source to correct the issue.", sourceName, metadata["certFingerprint"])
-		} else if connectivityStatus != api.EXTERNALCONNECTIVITYSTATUS_OK {
-			return fmt.Errorf("Successfully added new source %q, but connectivity check reported an issue: %s. Please update the source to correct the issue.", sourceName, connectivityStatus)
+		err = c.addSource(cmd, sourceName, api.SourceType(sourceType), dumpProperties)
+		if err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+func (c *cmdSourceAdd) addSource(cmd *cobra.Command, sourceName string, sourceType api.SourceType, properties any) error {
+	s := api.Source{
+		SourcePut: api.SourcePut{
+			Name: sourceName,
+		},
+		SourceType: sourceType,
+	}
+
+	var err error
+	s.Properties, err = json.Marshal(properties)
+	if err != nil {
+		return err
+	}
+
+	// Insert into database.
+	content, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	resp, _, err := c.global.doHTTPRequestV1("/sources", http.MethodPost, "", content)
+	if err != nil {
+		return err
+	}
 
-		cmd.Printf("Successfully added new source %q.\n", sourceName)
+	metadata := make(map[string]string)
+	err = json.Unmarshal(resp.Metadata, &metadata)
+	if err != nil {
+		return err
 	}
 
+	connectivityStatus := api.ExternalConnectivityStatus(metadata["ConnectivityStatus"])
+
+	if connectivityStatus == api.EXTERNALCONNECTIVITYSTATUS_TLS_CONFIRM_FINGERPRINT {
+		return fmt.Errorf("Successfully added new source %q, but received an untrusted TLS server certificate with fingerprint %s. Please update the source to correct the issue.", sourceName, metadata["certFingerprint"])
+	} else if connectivityStatus != api.EXTERNALCONNECTIVITYSTATUS_OK {
+		return fmt.Errorf("Successfully added new source %q, but connectivity check reported an issue: %s. Please update the source to correct the issue.", sourceName, connectivityStatus)
+	}
+
+	cmd.Printf("Successfully added new source %q.\n", sourceName)
+
 	return nil
 }
 
@@ -267,6 +340,22 @@ func (c *cmdSourceList) Run(cmd *cobra.Command, args []string) error {
 			}
 
 			data = append(data, []string{s.Name, string(s.SourceType), vmwareProperties.Endpoint, string(vmwareProperties.ConnectivityStatus), strconv.FormatBool(s.Syncing), vmwareProperties.Username, vmwareProperties.TrustedServerCertificateFingerprint})
+		case api.SOURCETYPE_OVF:
+			ovfProperties := api.OVFProperties{}
+			err := json.Unmarshal(s.Properties, &ovfProperties)
+			if err != nil {
+				return err
+			}
+
+			data = append(data, []string{s.Name, string(s.SourceType), ovfProperties.Path, string(ovfProperties.ConnectivityStatus), strconv.FormatBool(s.Syncing), "", ""})
+		case api.SOURCETYPE_VMWARE_DUMP:
+			dumpProperties := api.VMwareDumpProperties{}
+			err := json.Unmarshal(s.Properties, &dumpProperties)
+			if err != nil {
+				return err
+			}
+
+			data = append(data, []string{s.Name, string(s.SourceType), dumpProperties.Path, string(dumpProperties.ConnectivityStatus), strconv.FormatBool(s.Syncing), "", ""})
 		default:
 			return fmt.Errorf("Unsupported source type %s", s.SourceType)
 		}
@@ -314,6 +403,73 @@ func (c *cmdSourceRemove) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Sync the source.
+type cmdSourceSync struct {
+	global *CmdGlobal
+
+	flagInstances         []string
+	flagSourceSpecificIDs []string
+	flagIncludeExpression string
+}
+
+func (c *cmdSourceSync) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "sync <name>"
+	cmd.Short = "Sync source"
+	cmd.Long = `Description:
+  Sync source
+
+  Fetches up-to-date instance and network data from the source. If instances are selected,
+  only those instances and the networks they use are refreshed, leaving the records and
+  warnings of the rest of the source untouched.
+`
+
+	cmd.Flags().StringSliceVar(&c.flagInstances, "instance", nil, "UUID of an instance to sync")
+	cmd.Flags().StringSliceVar(&c.flagSourceSpecificIDs, "id", nil, "Source-specific ID or inventory path of an instance to sync")
+	cmd.Flags().StringVar(&c.flagIncludeExpression, "include", "", "Include expression selecting the instances to sync")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdSourceSync) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	name := args[0]
+
+	req := api.SourceSyncPost{
+		SourceSpecificIDs: c.flagSourceSpecificIDs,
+		IncludeExpression: c.flagIncludeExpression,
+	}
+
+	for _, u := range c.flagInstances {
+		instUUID, err := uuid.Parse(u)
+		if err != nil {
+			return fmt.Errorf("Invalid instance UUID %q: %w", u, err)
+		}
+
+		req.Instances = append(req.Instances, instUUID)
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	// Sync the source.
+	_, _, err = c.global.doHTTPRequestV1("/sources/"+name+"/:sync", http.MethodPost, "", b)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Successfully synced source %q.\n", name)
+	return nil
+}
+
 // Update the source.
 type cmdSourceUpdate struct {
 	global *CmdGlobal
@@ -445,6 +601,86 @@ func (c *cmdSourceUpdate) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		newSourceName = src.Name
+	case api.SOURCETYPE_OVF:
+		ovfProperties := api.OVFProperties{}
+		err := json.Unmarshal(src.Properties, &ovfProperties)
+		if err != nil {
+			return err
+		}
+
+		origSourceName = src.Name
+
+		src.Name, err = c.global.Asker.AskString("Source name [default="+src.Name+"]: ", src.Name, nil)
+		if err != nil {
+			return err
+		}
+
+		ovfProperties.Path, err = c.global.Asker.AskString("Path [default="+ovfProperties.Path+"]: ", ovfProperties.Path, nil)
+		if err != nil {
+			return err
+		}
+
+		importLimit := int64(ovfProperties.ImportLimit)
+		importLimit, err = c.global.Asker.AskInt(fmt.Sprintf("How many instances can be concurrently imported? [default=%d]: ", importLimit), 0, 1024, strconv.Itoa(int(importLimit)), nil)
+		if err != nil {
+			return err
+		}
+
+		ovfProperties.ImportLimit = int(importLimit)
+
+		connTimeoutStr := ovfProperties.ConnectionTimeout.String()
+		connTimeoutStr, err = c.global.Asker.AskString(fmt.Sprintf("Connection timeout for the source [default=%s]: ", connTimeoutStr), connTimeoutStr, nil)
+		if err != nil {
+			return err
+		}
+
+		ovfProperties.ConnectionTimeout, err = api.ParseDuration(connTimeoutStr)
+		if err != nil {
+			return err
+		}
+
+		src.Properties, err = json.Marshal(ovfProperties)
+		if err != nil {
+			return err
+		}
+
+		newSourceName = src.Name
+	case api.SOURCETYPE_VMWARE_DUMP:
+		dumpProperties := api.VMwareDumpProperties{}
+		err := json.Unmarshal(src.Properties, &dumpProperties)
+		if err != nil {
+			return err
+		}
+
+		origSourceName = src.Name
+
+		src.Name, err = c.global.Asker.AskString("Source name [default="+src.Name+"]: ", src.Name, nil)
+		if err != nil {
+			return err
+		}
+
+		dumpProperties.Path, err = c.global.Asker.AskString("Path [default="+dumpProperties.Path+"]: ", dumpProperties.Path, nil)
+		if err != nil {
+			return err
+		}
+
+		connTimeoutStr := dumpProperties.ConnectionTimeout.String()
+		connTimeoutStr, err = c.global.Asker.AskString(fmt.Sprintf("Connection timeout for the source [default=%s]: ", connTimeoutStr), connTimeoutStr, nil)
+		if err != nil {
+			return err
+		}
+
+		dumpProperties.ConnectionTimeout, err = api.ParseDuration(connTimeoutStr)
+		if err != nil {
+			return err
+		}
+
+		src.Properties, err = json.Marshal(dumpProperties)
+		if err != nil {
+			return err
+		}
+
 		newSourceName = src.Name
 	default:
 		return fmt.Errorf("Unsupported source type %s; must be one of %q", src.SourceType, supportedSourceTypes)
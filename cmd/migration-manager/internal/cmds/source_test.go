@@ -439,6 +439,127 @@ func TestSourceRemove(t *testing.T) {
 	}
 }
 
+func TestSourceSync(t *testing.T) {
+	tests := []struct {
+		name                        string
+		args                        []string
+		flagInstances               []string
+		flagSourceSpecificIDs       []string
+		flagIncludeExpression       string
+		migrationManagerdHTTPStatus int
+		migrationManagerdResponse   string
+
+		assertErr   require.ErrorAssertionFunc
+		wantRequest string
+	}{
+		{
+			name:                        "success - whole source",
+			migrationManagerdHTTPStatus: http.StatusOK,
+			migrationManagerdResponse: `{
+  "status_code": 200,
+  "status": "Success"
+}`,
+			args: []string{"source 1"},
+
+			assertErr:   require.NoError,
+			wantRequest: `{}`,
+		},
+		{
+			name:                        "success - selected instances",
+			flagInstances:               []string{"a2095069-a527-4b2a-ab23-1739325dcac7"},
+			flagSourceSpecificIDs:       []string{"vm-123", "/dc/vm/vm1"},
+			flagIncludeExpression:       `name == "vm2"`,
+			migrationManagerdHTTPStatus: http.StatusOK,
+			migrationManagerdResponse: `{
+  "status_code": 200,
+  "status": "Success"
+}`,
+			args: []string{"source 1"},
+
+			assertErr:   require.NoError,
+			wantRequest: `{"instances":["a2095069-a527-4b2a-ab23-1739325dcac7"],"source_specific_ids":["vm-123","/dc/vm/vm1"],"include_expression":"name == \"vm2\""}`,
+		},
+		{
+			name:                        "error - no name argument",
+			migrationManagerdHTTPStatus: http.StatusOK,
+			migrationManagerdResponse: `{
+  "status_code": 200,
+  "status": "Success"
+}`,
+
+			assertErr: require.Error, // handled by root command, show usage
+		},
+		{
+			name:                        "error - invalid instance UUID",
+			flagInstances:               []string{"invalid"},
+			migrationManagerdHTTPStatus: http.StatusOK,
+			migrationManagerdResponse: `{
+  "status_code": 200,
+  "status": "Success"
+}`,
+			args: []string{"source 1"},
+
+			assertErr: require.Error,
+		},
+		{
+			name:                        "error - invalid API response",
+			migrationManagerdHTTPStatus: http.StatusOK,
+			migrationManagerdResponse:   `{`, // invalid JSON
+			args:                        []string{"source 1"},
+
+			assertErr: require.Error,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotRequest string
+			migrationManagerd := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotRequest = string(body)
+				w.WriteHeader(tc.migrationManagerdHTTPStatus)
+				_, _ = w.Write([]byte(tc.migrationManagerdResponse))
+			}))
+			defer migrationManagerd.Close()
+
+			serverCert, _ := x509.ParseCertificate(migrationManagerd.TLS.Certificates[0].Certificate[0])
+			sync := cmdSourceSync{
+				global: &CmdGlobal{
+					config: &config.Config{
+						DefaultRemote: "default",
+						Remotes: map[string]config.Remote{
+							"default": {
+								Addr:       migrationManagerd.URL,
+								AuthType:   config.AuthTypeTLS,
+								ServerCert: api.Certificate{Certificate: serverCert},
+							},
+						},
+					},
+				},
+				flagInstances:         tc.flagInstances,
+				flagSourceSpecificIDs: tc.flagSourceSpecificIDs,
+				flagIncludeExpression: tc.flagIncludeExpression,
+			}
+
+			buf := bytes.Buffer{}
+
+			cmd := &cobra.Command{}
+			cmd.SetOut(&buf)
+
+			err := sync.Run(cmd, tc.args)
+			tc.assertErr(t, err)
+
+			if tc.wantRequest != "" {
+				require.JSONEq(t, tc.wantRequest, gotRequest)
+			}
+
+			if testing.Verbose() {
+				t.Logf("\n%s", buf.String())
+			}
+		})
+	}
+}
+
 type httpResponse struct {
 	status int
 	body   string
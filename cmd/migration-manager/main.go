@@ -61,6 +61,10 @@ func main() {
 	instanceCmd := cmds.CmdInstance{Global: &globalCmd}
 	app.AddCommand(instanceCmd.Command())
 
+	// monitor sub-command
+	monitorCmd := cmds.CmdMonitor{Global: &globalCmd}
+	app.AddCommand(monitorCmd.Command())
+
 	// network sub-command
 	networkCmd := cmds.CmdNetwork{Global: &globalCmd}
 	app.AddCommand(networkCmd.Command())
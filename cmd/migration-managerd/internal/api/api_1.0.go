@@ -22,22 +22,29 @@ var api10 = []APIEndpoint{
 	batchCmd,
 	batchInstancesCmd,
 	batchResetCmd,
+	batchImportWindowsCmd,
 	batchStartCmd,
+	batchPlanCmd,
 	batchStopCmd,
 	batchesCmd,
+	eventsCmd,
 	instanceCmd,
+	instanceNetworkACLsCmd,
 	instanceOverrideCmd,
 	instanceResetBackgroundImportCmd,
 	instanceEnableBackgroundImportCmd,
 	instancePowerCmd,
 	instancesCmd,
+	metricsCmd,
 	networkCmd,
 	networkInstancesCmd,
 	networkOverrideCmd,
 	networksCmd,
 	queueCancelCmd,
+	queueHistoryCmd,
 	queueResolveCmd,
 	queueRetryCmd,
+	queueRollbackCmd,
 	queueRootCmd,
 	queueCmd,
 	sourceCmd,
@@ -59,6 +66,7 @@ var api10 = []APIEndpoint{
 var apiInternal = []APIEndpoint{
 	workerUpdateCmd,
 	workerCommandCmd,
+	workerDiskCmd,
 }
 
 // swagger:operation GET /1.0 server server_get_untrusted
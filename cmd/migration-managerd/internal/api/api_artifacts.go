@@ -22,29 +22,29 @@ import (
 var artifactsCmd = APIEndpoint{
 	Path: "artifacts",
 
-	Get:  APIEndpointAction{Handler: artifactsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView), Authenticator: TokenAuthenticate},
+	Get:  APIEndpointAction{Handler: artifactsGet, AccessHandler: allowAuthenticated, Authenticator: TokenAuthenticate},
 	Post: APIEndpointAction{Handler: artifactsPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanCreate), Authenticator: TokenAuthenticate},
 }
 
 var artifactCmd = APIEndpoint{
 	Path: "artifacts/{uuid}",
 
-	Get:    APIEndpointAction{Handler: artifactGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView), Authenticator: TokenAuthenticate},
-	Put:    APIEndpointAction{Handler: artifactPut, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit), Authenticator: TokenAuthenticate},
-	Delete: APIEndpointAction{Handler: artifactDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete), Authenticator: TokenAuthenticate},
+	Get:    APIEndpointAction{Handler: artifactGet, AccessHandler: allowPermission(auth.ObjectTypeArtifact, auth.EntitlementCanView, "uuid"), Authenticator: TokenAuthenticate},
+	Put:    APIEndpointAction{Handler: artifactPut, AccessHandler: allowPermission(auth.ObjectTypeArtifact, auth.EntitlementCanEdit, "uuid"), Authenticator: TokenAuthenticate},
+	Delete: APIEndpointAction{Handler: artifactDelete, AccessHandler: allowPermission(auth.ObjectTypeArtifact, auth.EntitlementCanDelete, "uuid"), Authenticator: TokenAuthenticate},
 }
 
 var artifactFilesCmd = APIEndpoint{
 	Path: "artifacts/{uuid}/files",
 
-	Post: APIEndpointAction{Handler: artifactFilesPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit), Authenticator: TokenAuthenticate},
+	Post: APIEndpointAction{Handler: artifactFilesPost, AccessHandler: allowPermission(auth.ObjectTypeArtifact, auth.EntitlementCanEdit, "uuid"), Authenticator: TokenAuthenticate},
 }
 
 var artifactFileCmd = APIEndpoint{
 	Path: "artifacts/{uuid}/files/{name}",
 
-	Get:    APIEndpointAction{Handler: artifactFileGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView), Authenticator: TokenAuthenticate},
-	Delete: APIEndpointAction{Handler: artifactFileDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete), Authenticator: TokenAuthenticate},
+	Get:    APIEndpointAction{Handler: artifactFileGet, AccessHandler: allowPermission(auth.ObjectTypeArtifact, auth.EntitlementCanView, "uuid"), Authenticator: TokenAuthenticate},
+	Delete: APIEndpointAction{Handler: artifactFileDelete, AccessHandler: allowPermission(auth.ObjectTypeArtifact, auth.EntitlementCanDelete, "uuid"), Authenticator: TokenAuthenticate},
 }
 
 // artifactLock helps to manage concurrent reads and writes of artifact files.
@@ -89,6 +89,11 @@ var artifactLock sync.RWMutex
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func artifactsGet(d *Daemon, r *http.Request) response.Response {
+	canView, err := d.Authorizer().GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeArtifact)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	dbArts, err := d.artifact.GetAll(r.Context())
 	if err != nil {
 		return response.SmartError(err)
@@ -96,6 +101,10 @@ func artifactsGet(d *Daemon, r *http.Request) response.Response {
 
 	artifacts := make([]api.Artifact, 0, len(dbArts))
 	for _, a := range dbArts {
+		if !canView(auth.ObjectArtifact(a.UUID.String())) {
+			continue
+		}
+
 		artifacts = append(artifacts, a.ToAPI())
 	}
 
@@ -341,6 +350,11 @@ func artifactDelete(d *Daemon, r *http.Request) response.Response {
 //	produces:
 //	  - application/json
 //	parameters:
+//	  - in: query
+//	    name: name
+//	    description: File name, required for script artifacts
+//	    type: string
+//	    example: register.sh
 //	  - in: body
 //	    name: raw_file
 //	    description: Raw file content
@@ -365,16 +379,25 @@ func artifactFilesPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	defaultFileName, err := art.ToAPI().DefaultArtifactFile()
-	if err != nil {
-		return response.SmartError(err)
+	var fileName string
+	if art.Type == api.ARTIFACTTYPE_SCRIPT {
+		// Script artifacts can hold any number of files, so the name is supplied by the client.
+		fileName = r.URL.Query().Get("name")
+		if fileName == "" || fileName == "." || fileName == ".." || filepath.Base(fileName) != fileName {
+			return response.BadRequest(fmt.Errorf("Invalid script file name %q", fileName))
+		}
+	} else {
+		fileName, err = art.ToAPI().DefaultArtifactFile()
+		if err != nil {
+			return response.SmartError(err)
+		}
 	}
 
 	// lock the artifact for writing.
 	artifactLock.Lock()
 	defer artifactLock.Unlock()
 
-	err = d.artifact.WriteFile(art.UUID, defaultFileName, r.Body)
+	err = d.artifact.WriteFile(art.UUID, fileName, r.Body)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -3,10 +3,15 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,40 +32,52 @@ import (
 var batchesCmd = APIEndpoint{
 	Path: "batches",
 
-	Get:  APIEndpointAction{Handler: batchesGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
-	Post: APIEndpointAction{Handler: batchesPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanCreate)},
+	Get:  APIEndpointAction{Handler: batchesGet, AccessHandler: allowAuthenticated},
+	Post: APIEndpointAction{Handler: batchesPost, AccessHandler: allowAuthenticated},
 }
 
 var batchCmd = APIEndpoint{
 	Path: "batches/{name}",
 
-	Delete: APIEndpointAction{Handler: batchDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
-	Get:    APIEndpointAction{Handler: batchGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
-	Put:    APIEndpointAction{Handler: batchPut, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: batchDelete, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanDelete, "name")},
+	Get:    APIEndpointAction{Handler: batchGet, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanView, "name")},
+	Put:    APIEndpointAction{Handler: batchPut, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanEdit, "name")},
 }
 
 var batchInstancesCmd = APIEndpoint{
 	Path: "batches/{name}/instances",
 
-	Get: APIEndpointAction{Handler: batchInstancesGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+	Get: APIEndpointAction{Handler: batchInstancesGet, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanView, "name")},
 }
 
 var batchStartCmd = APIEndpoint{
 	Path: "batches/{name}/:start",
 
-	Post: APIEndpointAction{Handler: batchStartPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanCreate)},
+	Post: APIEndpointAction{Handler: batchStartPost, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanCreate, "name")},
+}
+
+var batchPlanCmd = APIEndpoint{
+	Path: "batches/{name}/:plan",
+
+	Post: APIEndpointAction{Handler: batchPlanPost, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanView, "name")},
 }
 
 var batchStopCmd = APIEndpoint{
 	Path: "batches/{name}/:stop",
 
-	Post: APIEndpointAction{Handler: batchStopPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
+	Post: APIEndpointAction{Handler: batchStopPost, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanDelete, "name")},
+}
+
+var batchImportWindowsCmd = APIEndpoint{
+	Path: "batches/{name}/:import-windows",
+
+	Post: APIEndpointAction{Handler: batchImportWindowsPost, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanEdit, "name")},
 }
 
 var batchResetCmd = APIEndpoint{
 	Path: "batches/{name}/:reset",
 
-	Post: APIEndpointAction{Handler: batchResetPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
+	Post: APIEndpointAction{Handler: batchResetPost, AccessHandler: allowPermission(auth.ObjectTypeBatch, auth.EntitlementCanDelete, "name")},
 }
 
 // swagger:operation GET /1.0/batches batches batches_get
@@ -152,6 +169,11 @@ func batchesGet(d *Daemon, r *http.Request) response.Response {
 		recursion = 0
 	}
 
+	canView, err := d.Authorizer().GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeBatch)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if recursion == 1 {
 		ctx, trans := transaction.Begin(r.Context())
 		defer func() {
@@ -169,6 +191,10 @@ func batchesGet(d *Daemon, r *http.Request) response.Response {
 		result := make([]api.Batch, 0, len(batches))
 
 		for _, batch := range batches {
+			if !canView(auth.ObjectBatch(batch.Name), batchAuthParents(batch)...) {
+				continue
+			}
+
 			windows, err := d.window.GetAllByBatch(ctx, batch.Name)
 			if err != nil {
 				return response.SmartError(err)
@@ -180,14 +206,18 @@ func batchesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SyncResponse(true, result)
 	}
 
-	batchNames, err := d.batch.GetAllNames(r.Context())
+	batches, err := d.batch.GetAll(r.Context())
 	if err != nil {
 		return response.SmartError(err)
 	}
 
-	result := make([]string, 0, len(batchNames))
-	for _, name := range batchNames {
-		result = append(result, fmt.Sprintf("/%s/batches/%s", api.APIVersion, name))
+	result := make([]string, 0, len(batches))
+	for _, batch := range batches {
+		if !canView(auth.ObjectBatch(batch.Name), batchAuthParents(batch)...) {
+			continue
+		}
+
+		result = append(result, fmt.Sprintf("/%s/batches/%s", api.APIVersion, batch.Name))
 	}
 
 	return response.SyncResponse(true, result)
@@ -264,7 +294,27 @@ func batchesPost(d *Daemon, r *http.Request) response.Response {
 		IncludeExpression: apiBatch.IncludeExpression,
 		Defaults:          apiBatch.Defaults,
 		Constraints:       apiBatch.Constraints,
+		InstanceGroups:    apiBatch.InstanceGroups,
 		Config:            apiBatch.Config,
+
+		MigrationWindowSchedules:  apiBatch.MigrationWindowSchedules,
+		MigrationWindowExclusions: apiBatch.MigrationWindowExclusions,
+	}
+
+	// The batch doesn't exist yet, so access is scoped by the targets and projects it places instances on.
+	err = d.checkBatchPlacementPermission(ctx, r, batch.Name, batch, auth.EntitlementCanCreate)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = d.checkPostMigrationScripts(ctx, batch.Config.PostMigrationScripts)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = d.checkBatchProfiles(ctx, batch)
+	if err != nil {
+		return response.SmartError(err)
 	}
 
 	_, err = d.batch.Create(ctx, batch)
@@ -272,23 +322,40 @@ func batchesPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	windows := make(migration.Windows, len(apiBatch.MigrationWindows))
-	for i, w := range apiBatch.MigrationWindows {
-		windows[i] = migration.Window{
+	windows := make(migration.Windows, 0, len(apiBatch.MigrationWindows))
+	for _, w := range apiBatch.MigrationWindows {
+		// Generated windows are managed by their schedule.
+		if w.Schedule != "" {
+			continue
+		}
+
+		windows = append(windows, migration.Window{
 			Name:    w.Name,
 			Start:   w.Start,
 			End:     w.End,
 			Lockout: w.Lockout,
 			Batch:   apiBatch.Name,
 			Config:  w.Config,
-		}
+		})
+	}
 
-		_, err = d.window.Create(ctx, windows[i])
+	for _, w := range windows.Exclude(batch.MigrationWindowExclusions) {
+		_, err = d.window.Create(ctx, w)
 		if err != nil {
 			return response.SmartError(err)
 		}
 	}
 
+	err = d.window.SyncSchedules(ctx, d.queue, batch.Name, batch.MigrationWindowSchedules, batch.MigrationWindowExclusions)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to generate scheduled migration windows for batch %q: %w", batch.Name, err))
+	}
+
+	windows, err = d.window.GetAllByBatch(ctx, batch.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = trans.Commit()
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed commit transaction: %w", err))
@@ -448,6 +515,15 @@ func batchPut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Fetch target details before opening the transaction so the DB isn't locked while connecting to targets.
+	var targetInfo map[string]*target.IncusDetails
+	if batch.Config.RerunScriptlets {
+		targetInfo, _, err = d.getTargetDetails(r.Context())
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	ctx, trans := transaction.Begin(r.Context())
 	defer func() {
 		rollbackErr := trans.Rollback()
@@ -476,8 +552,41 @@ func batchPut(d *Daemon, r *http.Request) response.Response {
 		IncludeExpression: batch.IncludeExpression,
 		StartDate:         currentBatch.StartDate,
 		Constraints:       batch.Constraints,
+		InstanceGroups:    batch.InstanceGroups,
 		Config:            batch.Config,
 		Defaults:          batch.Defaults,
+		CreatedNetworks:   currentBatch.CreatedNetworks,
+
+		MigrationWindowSchedules:  batch.MigrationWindowSchedules,
+		MigrationWindowExclusions: batch.MigrationWindowExclusions,
+	}
+
+	// The access handler only checked the current placement, so also check the submitted one.
+	err = d.checkBatchPlacementPermission(ctx, r, name, *newBatch, auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = d.checkPostMigrationScripts(ctx, newBatch.Config.PostMigrationScripts)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Profiles are checked again whenever the placement of the batch instances can change.
+	if !slices.Equal(currentBatch.Defaults.Profiles, newBatch.Defaults.Profiles) || currentBatch.Defaults.Placement != newBatch.Defaults.Placement ||
+		currentBatch.IncludeExpression != newBatch.IncludeExpression || currentBatch.Config.PlacementScriptlet != newBatch.Config.PlacementScriptlet {
+		err = d.checkBatchProfiles(ctx, *newBatch)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	// Placement of started batches is only determined again if scriptlets are re-run, so only then can it change the required capacity.
+	if currentBatch.Status != api.BATCHSTATUS_DEFINED && newBatch.Config.RerunScriptlets && currentBatch.Defaults.Placement != newBatch.Defaults.Placement && currentBatch.Config.PlacementScriptlet == newBatch.Config.PlacementScriptlet {
+		err = d.checkBatchCapacity(ctx, *newBatch, targetInfo, true)
+		if err != nil {
+			return response.SmartError(err)
+		}
 	}
 
 	err = d.batch.Update(ctx, d.queue, name, newBatch)
@@ -488,20 +597,31 @@ func batchPut(d *Daemon, r *http.Request) response.Response {
 	windows := make(migration.Windows, 0, len(batch.MigrationWindows))
 	for _, w := range batch.MigrationWindows {
 		windows = append(windows, migration.Window{
-			Name:    w.Name,
-			Start:   w.Start,
-			End:     w.End,
-			Lockout: w.Lockout,
-			Batch:   batch.Name,
-			Config:  w.Config,
+			Name:     w.Name,
+			Start:    w.Start,
+			End:      w.End,
+			Lockout:  w.Lockout,
+			Batch:    batch.Name,
+			Config:   w.Config,
+			Schedule: w.Schedule,
 		})
 	}
 
-	err = d.window.ReplaceByBatch(ctx, d.queue, batch.Name, windows)
+	err = d.window.ReplaceByBatch(ctx, d.queue, batch.Name, windows.Exclude(newBatch.MigrationWindowExclusions))
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed to update migration windows for batch %q: %w", batch.Name, err))
 	}
 
+	err = d.window.SyncSchedules(ctx, d.queue, batch.Name, newBatch.MigrationWindowSchedules, newBatch.MigrationWindowExclusions)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to generate scheduled migration windows for batch %q: %w", batch.Name, err))
+	}
+
+	windows, err = d.window.GetAllByBatch(ctx, batch.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = trans.Commit()
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed commit transaction: %w", err))
@@ -512,6 +632,143 @@ func batchPut(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponseLocation(true, nil, "/"+api.APIVersion+"/batches/"+batch.Name)
 }
 
+// swagger:operation POST /1.0/batches/{name}/:import-windows batches batch_import_windows_post
+//
+//	Import migration windows
+//
+//	Adds a migration window to the batch for each upcoming event in an iCalendar file.
+//	Existing migration windows with the same name are replaced.
+//	Freeze events are added as migration window exclusions instead, which trim or remove the overlapping migration windows of the batch.
+//
+//	---
+//	consumes:
+//	  - text/calendar
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: capacity
+//	    description: Number of instances that can be assigned to each imported window whose event does not set a capacity
+//	    type: integer
+//	    example: 10
+//	  - in: query
+//	    name: freeze
+//	    description: Whether all events are freeze events, rather than only those in the FREEZE category
+//	    type: integer
+//	    example: 1
+//	  - in: body
+//	    name: calendar
+//	    description: iCalendar file
+//	    required: true
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func batchImportWindowsPost(d *Daemon, r *http.Request) response.Response {
+	name := r.PathValue("name")
+
+	// The request body holds the calendar, so only read the capacity from the URL.
+	var capacity *int
+	if r.URL.Query().Has("capacity") {
+		capacityStr := r.URL.Query().Get("capacity")
+		value, err := strconv.Atoi(capacityStr)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid capacity %q: %w", capacityStr, err))
+		}
+
+		capacity = &value
+	}
+
+	freeze := r.URL.Query().Get("freeze") == "1"
+	imported, importedExclusions, err := migration.ParseICalendarWindows(r.Body, time.Now().UTC(), freeze)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var batch api.Batch
+	err = transaction.Do(r.Context(), func(ctx context.Context) error {
+		b, err := d.batch.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		oldWindows, err := d.window.GetAllByBatch(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		// Imported exclusions replace existing ones with the same name.
+		exclusions := make([]api.MigrationWindowExclusion, 0, len(b.MigrationWindowExclusions)+len(importedExclusions))
+		for _, e := range b.MigrationWindowExclusions {
+			if !slices.ContainsFunc(importedExclusions, func(imported api.MigrationWindowExclusion) bool { return imported.Name == e.Name }) {
+				exclusions = append(exclusions, e)
+			}
+		}
+
+		b.MigrationWindowExclusions = append(exclusions, importedExclusions...)
+		err = d.batch.Update(ctx, d.queue, name, b)
+		if err != nil {
+			return err
+		}
+
+		windowsByName := map[string]migration.Window{}
+		for _, w := range oldWindows {
+			windowsByName[w.Name] = w
+		}
+
+		for _, w := range imported {
+			w.Batch = name
+
+			// Events with a capacity of their own keep it. Otherwise, use the requested capacity, or keep that of the replaced window.
+			if w.Config.Capacity == 0 {
+				if capacity != nil {
+					w.Config.Capacity = *capacity
+				} else {
+					w.Config.Capacity = windowsByName[w.Name].Config.Capacity
+				}
+			}
+
+			windowsByName[w.Name] = w
+		}
+
+		windows := make(migration.Windows, 0, len(windowsByName))
+		for _, w := range windowsByName {
+			windows = append(windows, w)
+		}
+
+		err = d.window.ReplaceByBatch(ctx, d.queue, name, windows.Exclude(b.MigrationWindowExclusions))
+		if err != nil {
+			return fmt.Errorf("Failed to update migration windows for batch %q: %w", name, err)
+		}
+
+		err = d.window.SyncSchedules(ctx, d.queue, name, b.MigrationWindowSchedules, b.MigrationWindowExclusions)
+		if err != nil {
+			return fmt.Errorf("Failed to generate scheduled migration windows for batch %q: %w", name, err)
+		}
+
+		windows, err = d.window.GetAllByBatch(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		batch = b.ToAPI(windows)
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.logHandler.SendLifecycle(r.Context(), event.NewBatchEvent(event.BatchModified, r, batch, batch.Name))
+
+	return response.SyncResponse(true, nil)
+}
+
 // swagger:operation GET /1.0/batches/{name}/instances batches batches_instances_get
 //
 //	Get instances for the batch
@@ -678,9 +935,44 @@ func batchStartPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Worker endpoint cannot use a wildcard address: %q", d.getWorkerEndpoint()))
 	}
 
+	// Fetch target details before opening the transaction so the DB isn't locked while connecting to targets.
+	targetInfo, _, err := d.getTargetDetails(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var batch api.Batch
 	err = transaction.Do(r.Context(), func(ctx context.Context) error {
-		b, err := d.batch.StartBatchByName(ctx, batchName, d.window, d.network, d.queue)
+		b, err := d.batch.GetByName(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		// The access handler only checked the default placement, so also check the selected instances and their placements.
+		err = d.checkBatchPlacementPermission(ctx, r, batchName, *b, auth.EntitlementCanCreate)
+		if err != nil {
+			return err
+		}
+
+		// Replayed instances only exist in a dump, so batches selecting them can be planned but not started.
+		instances, err := d.instance.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return fmt.Errorf("Failed to get instances for batch %q: %w", batchName, err)
+		}
+
+		for _, inst := range instances {
+			if inst.SourceType == api.SOURCETYPE_VMWARE_DUMP {
+				return migration.NewValidationErrf("Cannot start batch %q, instance %q of %q source %q can not be migrated", batchName, inst.Properties.Location, inst.SourceType, inst.Source)
+			}
+		}
+
+		b, err = d.batch.StartBatchByName(ctx, batchName, d.window, d.network, d.queue)
+		if err != nil {
+			return err
+		}
+
+		// Refuse to start a batch whose instances can't fit on their targets, rather than leaving them blocked.
+		err = d.checkBatchCapacity(ctx, *b, targetInfo, false)
 		if err != nil {
 			return err
 		}
@@ -703,6 +995,332 @@ func batchStartPost(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, nil)
 }
 
+// getTargetDetails connects to every target and returns their details keyed by target name.
+// Targets that can't be reached are returned with their error instead.
+func (d *Daemon) getTargetDetails(ctx context.Context) (map[string]*target.IncusDetails, map[string]error, error) {
+	targets, err := d.target.GetAll(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to get all targets: %w", err)
+	}
+
+	targetInfo := make(map[string]*target.IncusDetails, len(targets))
+	targetErrs := map[string]error{}
+	for _, t := range targets {
+		it, err := target.NewTarget(t.ToAPI())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, it.Timeout())
+		err = it.Connect(ctx)
+		if err != nil {
+			cancel()
+			targetErrs[t.Name] = err
+			continue
+		}
+
+		info, err := it.GetDetails(ctx)
+		cancel()
+		if err != nil {
+			targetErrs[t.Name] = err
+			continue
+		}
+
+		targetInfo[info.Name] = info
+	}
+
+	return targetInfo, targetErrs, nil
+}
+
+// checkBatchCapacity verifies that the queue entries of the batch that have not been created on their target yet fit there, accounting for each other.
+// If determinePlacement is true, the placement of each entry is determined again from the batch instead of using its recorded placement.
+// Entries placed on targets without details are checked when their import begins.
+func (d *Daemon) checkBatchCapacity(ctx context.Context, batch migration.Batch, targetInfo map[string]*target.IncusDetails, determinePlacement bool) error {
+	entries, err := d.queue.GetAllByBatch(ctx, batch.Name)
+	if err != nil {
+		return err
+	}
+
+	queuedInstances, err := d.instance.GetAllQueued(ctx, entries)
+	if err != nil {
+		return err
+	}
+
+	instances := make(map[uuid.UUID]migration.Instance, len(queuedInstances))
+	for _, inst := range queuedInstances {
+		instances[inst.UUID] = inst
+	}
+
+	var networks migration.Networks
+	var windows migration.Windows
+	if determinePlacement {
+		networks, err = d.network.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to get all networks: %w", err)
+		}
+
+		windows, err = d.window.GetAllByBatch(ctx, batch.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reserve capacity in a stable order.
+	slices.SortFunc(entries, func(a migration.QueueEntry, b migration.QueueEntry) int {
+		return strings.Compare(instances[a.InstanceUUID].Properties.Location, instances[b.InstanceUUID].Properties.Location)
+	})
+
+	failures := []string{}
+	for _, q := range entries {
+		if q.MigrationStatus != api.MIGRATIONSTATUS_WAITING && q.MigrationStatus != api.MIGRATIONSTATUS_BLOCKED {
+			continue
+		}
+
+		inst := instances[q.InstanceUUID]
+		if determinePlacement {
+			placement, err := d.batch.DeterminePlacement(ctx, inst, migration.FilterUsedNetworks(networks, migration.Instances{inst}), batch, windows)
+			if err != nil {
+				return err
+			}
+
+			q.Placement = *placement
+		}
+
+		info, ok := targetInfo[q.Placement.TargetName]
+		if !ok {
+			continue
+		}
+
+		err := target.CheckCapacity(info, q.Placement, inst.ToAPI())
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%q: %v", inst.Properties.Location, err))
+			continue
+		}
+
+		info.ReserveCapacity(q, inst.ToAPI())
+	}
+
+	if len(failures) > 0 {
+		return migration.NewValidationErrf("Not enough capacity on targets for %d instances in batch %q: %s", len(failures), batch.Name, strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// errBatchPlanRollback discards all changes made to the database while computing a batch plan.
+var errBatchPlanRollback = errors.New("Batch plan complete")
+
+// swagger:operation POST /1.0/batches/{name}/plan batches batches_plan_post
+//
+//	Plan a batch
+//
+//	Performs a dry-run of starting the batch, and returns how each instance would be migrated without persisting anything.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Batch plan
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: Per-instance migration plan
+//	          items:
+//	            $ref: "#/definitions/BatchPlanEntry"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func batchPlanPost(d *Daemon, r *http.Request) response.Response {
+	// Hold the start lock so that the plan can't interleave with a real batch start.
+	batchStartLock.Lock()
+	defer batchStartLock.Unlock()
+
+	err := d.WaitForSchemaUpdate(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	batchName := r.PathValue("name")
+
+	// Fetch target details before opening the transaction so the DB isn't locked while connecting to targets.
+	targetInfo, targetErrs, err := d.getTargetDetails(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Start the batch and simulate window assignment within a transaction that is always rolled back.
+	plan := []api.BatchPlanEntry{}
+	err = transaction.Do(r.Context(), func(ctx context.Context) error {
+		batch, err := d.batch.GetByName(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		if batch.Status == api.BATCHSTATUS_DEFINED {
+			err = d.batch.UpdateInstancesAssignedToBatch(ctx, *batch)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Only plan for instances that are not already queued from a previous run of the batch.
+		existingEntries, err := d.queue.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		alreadyQueued := make(map[uuid.UUID]bool, len(existingEntries))
+		for _, q := range existingEntries {
+			alreadyQueued[q.InstanceUUID] = true
+		}
+
+		_, err = d.batch.StartBatchByName(ctx, batchName, d.window, d.network, d.queue)
+		if err != nil {
+			return err
+		}
+
+		windows, err := d.window.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		artifacts, err := d.artifact.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to fetch artifact records: %w", err)
+		}
+
+		networks, err := d.network.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to get all networks: %w", err)
+		}
+
+		entries, err := d.queue.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		queuedInstances, err := d.instance.GetAllQueued(ctx, entries)
+		if err != nil {
+			return err
+		}
+
+		instances := make(map[uuid.UUID]migration.Instance, len(queuedInstances))
+		for _, inst := range queuedInstances {
+			instances[inst.UUID] = inst
+		}
+
+		// Assign windows in a stable order.
+		slices.SortFunc(entries, func(a migration.QueueEntry, b migration.QueueEntry) int {
+			return strings.Compare(instances[a.InstanceUUID].Properties.Location, instances[b.InstanceUUID].Properties.Location)
+		})
+
+		for _, q := range entries {
+			if alreadyQueued[q.InstanceUUID] {
+				continue
+			}
+
+			inst := instances[q.InstanceUUID]
+			entry := api.BatchPlanEntry{
+				InstanceUUID:    inst.UUID,
+				InstanceName:    inst.GetName(),
+				Location:        inst.Properties.Location,
+				MigrationStatus: q.MigrationStatus,
+				Placement:       q.Placement,
+				BlockingReasons: []string{},
+			}
+
+			if q.MigrationStatus != api.MIGRATIONSTATUS_WAITING {
+				entry.BlockingReasons = append(entry.BlockingReasons, q.MigrationStatusMessage)
+			}
+
+			err := d.artifact.HasRequiredArtifactsForInstance(artifacts, inst)
+			if err != nil {
+				entry.BlockingReasons = append(entry.BlockingReasons, fmt.Sprintf("Artifact error: %v", err))
+			}
+
+			targetErr, ok := targetErrs[q.Placement.TargetName]
+			if ok {
+				entry.BlockingReasons = append(entry.BlockingReasons, fmt.Sprintf("Failed to fetch details for target %q: %v", q.Placement.TargetName, targetErr))
+			} else {
+				info := targetInfo[q.Placement.TargetName]
+				if batch.Config.CreateTargetNetworks && info != nil {
+					_, entry.CreatedNetworks, err = info.ReserveNetworks(q, inst, networks, *batch)
+					if err != nil {
+						entry.BlockingReasons = append(entry.BlockingReasons, err.Error())
+					}
+				}
+
+				err = target.CanPlaceInstance(ctx, info, q, inst.ToAPI(), batch.ToAPI(windows))
+				if err != nil {
+					entry.BlockingReasons = append(entry.BlockingReasons, err.Error())
+				} else {
+					info.ReserveCapacity(q, inst.ToAPI())
+				}
+			}
+
+			if len(entry.BlockingReasons) > 0 {
+				if entry.MigrationStatus == api.MIGRATIONSTATUS_WAITING {
+					entry.MigrationStatus = api.MIGRATIONSTATUS_BLOCKED
+				}
+
+				plan = append(plan, entry)
+				continue
+			}
+
+			// Mark the entry as idle, as it would be after its background import, so that it can be assigned a window.
+			idleEntry, err := d.queue.UpdateStatusByUUID(ctx, q.InstanceUUID, api.MIGRATIONSTATUS_IDLE, string(api.MIGRATIONSTATUS_IDLE), q.ImportStage, nil)
+			if err != nil {
+				return err
+			}
+
+			window, err := d.queue.GetNextWindow(ctx, *idleEntry)
+			if err != nil {
+				if !incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+					return err
+				}
+
+				entry.BlockingReasons = append(entry.BlockingReasons, err.Error())
+			} else if window.Name != "" {
+				entry.MigrationWindow = window.ToAPI()
+
+				// Record the window so that its capacity accounts for this instance.
+				_, err = d.queue.UpdateStatusByUUID(ctx, q.InstanceUUID, api.MIGRATIONSTATUS_IDLE, string(api.MIGRATIONSTATUS_IDLE), q.ImportStage, &window.Name)
+				if err != nil {
+					return err
+				}
+			}
+
+			plan = append(plan, entry)
+		}
+
+		return errBatchPlanRollback
+	})
+	if err != nil && !errors.Is(err, errBatchPlanRollback) {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, plan)
+}
+
 // swagger:operation POST /1.0/batches/{name}/stop batches batches_stop_post
 //
 //	Stop a batch
@@ -781,6 +1399,7 @@ func batchResetPost(d *Daemon, r *http.Request) response.Response {
 	name := r.PathValue("name")
 	force := r.FormValue("force") == "1"
 	var apiBatch api.Batch
+	var networkErrs []error
 	err := transaction.Do(r.Context(), func(ctx context.Context) error {
 		// Get a record of all queue entries before we wipe the records.
 		entries, err := d.queue.GetAllByBatch(ctx, name)
@@ -788,6 +1407,12 @@ func batchResetPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		// Get a record of the networks created for the batch before we wipe the records.
+		oldBatch, err := d.batch.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+
 		batch, err := d.batch.ResetBatchByName(ctx, name, d.queue, d.source, d.target, force)
 		if err != nil {
 			return err
@@ -890,6 +1515,49 @@ func batchResetPost(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
+		// Remove networks created for the batch now that its instances are cleaned up.
+		// Networks that can't be removed yet stay recorded so that a later reset can remove them.
+		remaining := []api.BatchCreatedNetwork{}
+		for _, n := range oldBatch.CreatedNetworks {
+			t, ok := targetMap[n.Target]
+			if !ok {
+				networkErrs = append(networkErrs, fmt.Errorf("Network %q: Target %q does not exist", n.Name, n.Target))
+				remaining = append(remaining, n)
+				continue
+			}
+
+			it, err := target.NewTarget(t.ToAPI())
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, it.Timeout())
+			err = it.Connect(ctx)
+			if err == nil {
+				err = it.SetProject(n.TargetProject)
+			}
+
+			if err == nil {
+				err = it.DeleteNetwork(ctx, n.Name, name)
+			}
+
+			cancel()
+			if err != nil && !incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+				slog.Warn("Failed to remove network created for batch", slog.String("batch", name), slog.String("network", n.Name), slog.String("target", n.Target), slog.String("project", n.TargetProject), slog.Any("error", err))
+				networkErrs = append(networkErrs, fmt.Errorf("Network %q on target %q: %w", n.Name, n.Target, err))
+				remaining = append(remaining, n)
+			}
+		}
+
+		if len(remaining) > 0 {
+			batch, err := d.batch.AddCreatedNetworks(ctx, name, remaining)
+			if err != nil {
+				return err
+			}
+
+			apiBatch = batch.ToAPI(windows)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -898,5 +1566,119 @@ func batchResetPost(d *Daemon, r *http.Request) response.Response {
 
 	d.logHandler.SendLifecycle(r.Context(), event.NewBatchEvent(event.BatchReset, r, apiBatch, apiBatch.Name))
 
+	// The batch itself is reset, but report the networks that are still recorded for a later reset.
+	if len(networkErrs) > 0 {
+		return response.SmartError(fmt.Errorf("Batch %q was reset, but failed to remove %d networks created for it: %w", name, len(networkErrs), errors.Join(networkErrs...)))
+	}
+
 	return response.SyncResponse(true, nil)
 }
+
+// checkBatchProfiles ensures the profiles in the batch defaults exist in every target project the batch places instances in.
+// This is the default placement of the batch, and the placement of each matching instance, as determined by the placement scriptlet.
+func (d *Daemon) checkBatchProfiles(ctx context.Context, batch migration.Batch) error {
+	if len(batch.Defaults.Profiles) == 0 {
+		return nil
+	}
+
+	var instances migration.Instances
+	var networks migration.Networks
+	var windows migration.Windows
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		var err error
+		instances, err = d.instance.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to get all instances: %w", err)
+		}
+
+		networks, err = d.network.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to get all networks: %w", err)
+		}
+
+		windows, err = d.window.GetAllByBatch(ctx, batch.Name)
+		if err != nil {
+			return fmt.Errorf("Failed to get migration windows for batch %q: %w", batch.Name, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	projectsByTarget := map[string][]string{batch.Defaults.Placement.Target: {batch.Defaults.Placement.TargetProject}}
+	for _, inst := range instances {
+		isMatch, err := inst.MatchesCriteria(batch.IncludeExpression, false)
+		if err != nil {
+			return err
+		}
+
+		if !isMatch {
+			continue
+		}
+
+		placement, err := d.batch.DeterminePlacement(ctx, inst, migration.FilterUsedNetworks(networks, migration.Instances{inst}), batch, windows)
+		if err != nil {
+			return fmt.Errorf("Failed to determine placement of instance %q: %w", inst.Properties.Location, err)
+		}
+
+		if !slices.Contains(projectsByTarget[placement.TargetName], placement.TargetProject) {
+			projectsByTarget[placement.TargetName] = append(projectsByTarget[placement.TargetName], placement.TargetProject)
+		}
+	}
+
+	for _, targetName := range slices.Sorted(maps.Keys(projectsByTarget)) {
+		err := d.checkTargetProfiles(ctx, targetName, projectsByTarget[targetName], batch.Defaults.Profiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkTargetProfiles ensures the profiles exist in each of the given projects of the target.
+func (d *Daemon) checkTargetProfiles(ctx context.Context, targetName string, projects []string, profiles []string) error {
+	t, err := d.target.GetByName(ctx, targetName)
+	if err != nil {
+		if errors.Is(err, migration.ErrNotFound) {
+			return migration.NewValidationErrf("Target %q does not exist", targetName)
+		}
+
+		return fmt.Errorf("Failed to get target %q: %w", targetName, err)
+	}
+
+	it, err := target.NewTarget(t.ToAPI())
+	if err != nil {
+		return fmt.Errorf("Failed to construct target %q: %w", t.Name, err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, it.Timeout())
+	defer cancel()
+
+	err = it.Connect(timeoutCtx)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to target %q: %w", it.GetName(), err)
+	}
+
+	for _, project := range projects {
+		err = it.SetProject(project)
+		if err != nil {
+			return fmt.Errorf("Failed to set project %q for target %q: %w", project, it.GetName(), err)
+		}
+
+		existing, err := it.GetProfileNames()
+		if err != nil {
+			return fmt.Errorf("Failed to get profiles of target %q: %w", it.GetName(), err)
+		}
+
+		for _, profile := range profiles {
+			if !slices.Contains(existing, profile) {
+				return migration.NewValidationErrf("Profile %q does not exist in project %q of target %q", profile, project, it.GetName())
+			}
+		}
+	}
+
+	return nil
+}
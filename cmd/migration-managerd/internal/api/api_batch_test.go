@@ -5,6 +5,9 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"testing"
 	"time"
@@ -17,6 +20,7 @@ import (
 	"github.com/FuturFusion/migration-manager/internal/properties"
 	"github.com/FuturFusion/migration-manager/internal/source"
 	"github.com/FuturFusion/migration-manager/internal/target"
+	"github.com/FuturFusion/migration-manager/internal/testing/boom"
 	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
@@ -33,14 +37,16 @@ func TestBatchAPI_reset(t *testing.T) {
 
 		status api.BatchStatusType
 
-		vms            vms
-		queue          queue
-		wantHTTPStatus int
+		vms             vms
+		queue           queue
+		createdNetworks []api.BatchCreatedNetwork
+		wantHTTPStatus  int
 
-		wantStatus       api.BatchStatusType
-		cleanupVMs       []string
-		powerOnVMs       []string
-		wantQueueEntries int
+		wantStatus          api.BatchStatusType
+		cleanupVMs          []string
+		powerOnVMs          []string
+		wantQueueEntries    int
+		wantCreatedNetworks []api.BatchCreatedNetwork
 	}{
 		{
 			name:   "success - clear all queue entries with force flag",
@@ -188,6 +194,40 @@ func TestBatchAPI_reset(t *testing.T) {
 
 			wantQueueEntries: 0,
 		},
+		{
+			name:   "success - remove created networks",
+			status: api.BATCHSTATUS_RUNNING,
+			vms:    vms{true: {"vm1"}},
+			queue:  queue{api.MIGRATIONSTATUS_WAITING: {"vm1"}},
+			createdNetworks: []api.BatchCreatedNetwork{
+				{Name: "net1", Type: "bridge", Target: "tgt", TargetProject: "default"},
+			},
+
+			wantHTTPStatus:   http.StatusOK,
+			wantStatus:       api.BATCHSTATUS_DEFINED,
+			cleanupVMs:       []string{"vm1"},
+			powerOnVMs:       []string{"vm1"},
+			wantQueueEntries: 0,
+		},
+		{
+			name:   "error - created networks on a missing target stay recorded",
+			status: api.BATCHSTATUS_RUNNING,
+			vms:    vms{true: {"vm1"}},
+			queue:  queue{api.MIGRATIONSTATUS_WAITING: {"vm1"}},
+			createdNetworks: []api.BatchCreatedNetwork{
+				{Name: "net1", Type: "bridge", Target: "tgt", TargetProject: "default"},
+				{Name: "net2", Type: "bridge", Target: "removed", TargetProject: "default"},
+			},
+
+			wantHTTPStatus:   http.StatusInternalServerError,
+			wantStatus:       api.BATCHSTATUS_DEFINED,
+			cleanupVMs:       []string{"vm1"},
+			powerOnVMs:       []string{"vm1"},
+			wantQueueEntries: 0,
+			wantCreatedNetworks: []api.BatchCreatedNetwork{
+				{Name: "net2", Type: "bridge", Target: "removed", TargetProject: "default"},
+			},
+		},
 		{
 			name:   "error - late-stage queue entries without force flag",
 			status: api.BATCHSTATUS_RUNNING,
@@ -269,6 +309,10 @@ func TestBatchAPI_reset(t *testing.T) {
 			batch.Status = api.BATCHSTATUS_RUNNING
 			_, err = d.batch.UpdateStatusByName(d.ShutdownCtx, batch.Name, batch.Status, batch.StatusMessage)
 			require.NoError(t, err)
+			if len(tc.createdNetworks) > 0 {
+				_, err = d.batch.AddCreatedNetworks(d.ShutdownCtx, batch.Name, tc.createdNetworks)
+				require.NoError(t, err)
+			}
 
 			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: defaultSourceEndpointFunc}
 			_, err = d.source.Create(d.ShutdownCtx, src)
@@ -336,14 +380,19 @@ func TestBatchAPI_reset(t *testing.T) {
 						require.Contains(t, tc.cleanupVMs, name)
 						return nil
 					},
+					DeleteNetworkFunc: func(ctx context.Context, name string, batchName string) error {
+						require.True(t, slices.ContainsFunc(tc.createdNetworks, func(n api.BatchCreatedNetwork) bool { return n.Name == name }))
+						return nil
+					},
 				}, nil
 			}
 
 			source.NewVMSource = func(s api.Source) (source.Source, error) {
 				return &source.SourceMock{
-					TimeoutFunc: func() time.Duration { return time.Second },
-					GetNameFunc: func() string { return s.Name },
-					ConnectFunc: func(ctx context.Context) error { return nil },
+					TimeoutFunc:    func() time.Duration { return time.Second },
+					GetNameFunc:    func() string { return s.Name },
+					ConnectFunc:    func(ctx context.Context) error { return nil },
+					DisconnectFunc: func(ctx context.Context) error { return nil },
 					PowerOnVMFunc: func(ctx context.Context, name string) error {
 						status, ok := statesByName[name]
 						require.True(t, ok)
@@ -363,6 +412,575 @@ func TestBatchAPI_reset(t *testing.T) {
 			resultQueue, err := d.queue.GetAllByBatch(t.Context(), "b1")
 			require.NoError(t, err)
 			require.Len(t, resultQueue, tc.wantQueueEntries)
+			require.Equal(t, tc.wantCreatedNetworks, resultBatch.CreatedNetworks)
+		})
+	}
+}
+
+func TestBatchAPI_plan(t *testing.T) {
+	defaultTargetEndpoint := func(api.Target) (migration.TargetEndpoint, error) {
+		return &mock.TargetEndpointMock{
+			ConnectFunc:                func(ctx context.Context) error { return nil },
+			IsWaitingForOIDCTokensFunc: func() bool { return false },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	defaultSourceEndpointFunc := func(api.Source) (migration.SourceEndpoint, error) {
+		return &mock.SourceEndpointMock{
+			ConnectFunc: func(ctx context.Context) error { return nil },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	windowStart := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	uuids := uuidCache{}
+	cases := []struct {
+		name string
+
+		status        api.BatchStatusType
+		instances     migration.Instances
+		windows       migration.Windows
+		targetDetails *target.IncusDetails
+		hasVMwareSDK  bool
+
+		wantHTTPStatus int
+		wantStatus     map[string]api.MigrationStatusType
+		wantWindows    map[string]string
+		wantReasons    map[string][]string
+	}{
+		{
+			name:   "success - all instances placed",
+			status: api.BATCHSTATUS_DEFINED,
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			windows:       migration.Windows{{Name: "w1", Batch: "b1", Start: windowStart, End: windowStart.Add(time.Hour)}},
+			targetDetails: &target.IncusDetails{Name: "tgt", Projects: []string{"default"}, StoragePools: []string{"default"}, InstancesByProject: map[string][]string{"default": {}}},
+			hasVMwareSDK:  true,
+
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     map[string]api.MigrationStatusType{"vm1": api.MIGRATIONSTATUS_WAITING, "vm2": api.MIGRATIONSTATUS_WAITING},
+			wantWindows:    map[string]string{"vm1": "w1", "vm2": "w1"},
+			wantReasons:    map[string][]string{"vm1": {}, "vm2": {}},
+		},
+		{
+			name:   "success - window capacity spreads instances",
+			status: api.BATCHSTATUS_DEFINED,
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm3", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			windows: migration.Windows{
+				{Name: "w1", Batch: "b1", Start: windowStart, End: windowStart.Add(time.Hour), Config: api.MigrationWindowConfig{Capacity: 1}},
+				{Name: "w2", Batch: "b1", Start: windowStart.Add(2 * time.Hour), End: windowStart.Add(3 * time.Hour), Config: api.MigrationWindowConfig{Capacity: 1}},
+			},
+			targetDetails: &target.IncusDetails{Name: "tgt", Projects: []string{"default"}, StoragePools: []string{"default"}, InstancesByProject: map[string][]string{"default": {}}},
+			hasVMwareSDK:  true,
+
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     map[string]api.MigrationStatusType{"vm1": api.MIGRATIONSTATUS_WAITING, "vm2": api.MIGRATIONSTATUS_WAITING, "vm3": api.MIGRATIONSTATUS_WAITING},
+			wantWindows:    map[string]string{"vm1": "w1", "vm2": "w2", "vm3": ""},
+			wantReasons:    map[string][]string{"vm1": {}, "vm2": {}, "vm3": {}},
+		},
+		{
+			name:   "success - blocking reasons are reported",
+			status: api.BATCHSTATUS_DEFINED,
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			targetDetails: &target.IncusDetails{Name: "tgt", Projects: []string{"default"}, StoragePools: []string{"default"}, InstancesByProject: map[string][]string{"default": {"vm2"}}},
+
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     map[string]api.MigrationStatusType{"vm1": api.MIGRATIONSTATUS_BLOCKED, "vm2": api.MIGRATIONSTATUS_BLOCKED},
+			wantWindows:    map[string]string{"vm1": "", "vm2": ""},
+			wantReasons: map[string][]string{
+				"vm1": {"Artifact error: Missing required SDK artifact"},
+				"vm2": {"Artifact error: Missing required SDK artifact", `Instance already exists with name "vm2" on target "tgt" in project "default"`},
+			},
+		},
+		{
+			name:   "success - unreachable target",
+			status: api.BATCHSTATUS_DEFINED,
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			hasVMwareSDK: true,
+
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     map[string]api.MigrationStatusType{"vm1": api.MIGRATIONSTATUS_BLOCKED},
+			wantWindows:    map[string]string{"vm1": ""},
+			wantReasons:    map[string][]string{"vm1": {`Failed to fetch details for target "tgt": boom!`}},
+		},
+		{
+			name:   "error - batch is running",
+			status: api.BATCHSTATUS_RUNNING,
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			targetDetails: &target.IncusDetails{Name: "tgt", Projects: []string{"default"}, StoragePools: []string{"default"}, InstancesByProject: map[string][]string{"default": {}}},
+			hasVMwareSDK:  true,
+
+			wantHTTPStatus: http.StatusBadRequest,
+		},
+	}
+
+	require.NoError(t, properties.InitDefinitions())
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{batchPlanCmd}, nil)
+
+			// Mark schema updates as complete.
+			close(d.migrationCh)
+
+			origTarget := target.NewTarget
+			defer func() {
+				target.NewTarget = origTarget
+			}()
+
+			target.NewTarget = func(tgt api.Target) (target.Target, error) {
+				return &target.TargetMock{
+					TimeoutFunc: func() time.Duration { return time.Second },
+					GetNameFunc: func() string { return tgt.Name },
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					GetDetailsFunc: func(ctx context.Context) (*target.IncusDetails, error) {
+						if tc.targetDetails == nil {
+							return nil, boom.Error
+						}
+
+						return tc.targetDetails, nil
+					},
+				}, nil
+			}
+
+			if tc.hasVMwareSDK {
+				art := migration.Artifact{UUID: uuid.New(), Type: api.ARTIFACTTYPE_SDK, Properties: api.ArtifactPut{SourceType: api.SOURCETYPE_VMWARE}}
+				_, err := d.artifact.Create(d.ShutdownCtx, art)
+				require.NoError(t, err)
+
+				require.NoError(t, os.MkdirAll(d.artifact.FileDirectory(art.UUID), 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(d.artifact.FileDirectory(art.UUID), "vmware-sdk.tar.gz"), nil, 0o644))
+			}
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: defaultSourceEndpointFunc}
+			_, err := d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			tgt := migration.Target{Name: "tgt", TargetType: api.TARGETTYPE_INCUS, Properties: json.RawMessage(`{"endpoint": "bar", "create_limit": 5, "connection_timeout": "30s"}`), EndpointFunc: defaultTargetEndpoint}
+			_, err = d.target.Create(d.ShutdownCtx, tgt)
+			require.NoError(t, err)
+
+			for _, inst := range tc.instances {
+				_, err = d.instance.Create(d.ShutdownCtx, inst)
+				require.NoError(t, err)
+			}
+
+			batch := migration.Batch{
+				Name: "b1",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "tgt", TargetProject: "default", StoragePool: "default"},
+				},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					RestrictionOverrides:     api.InstanceRestrictionOverride{AllowNoIPv4: true},
+				},
+			}
+
+			_, err = d.batch.Create(d.ShutdownCtx, batch)
+			require.NoError(t, err)
+
+			if tc.status != api.BATCHSTATUS_DEFINED {
+				_, err = d.batch.UpdateStatusByName(d.ShutdownCtx, batch.Name, tc.status, string(tc.status))
+				require.NoError(t, err)
+			}
+
+			for _, w := range tc.windows {
+				_, err = d.window.Create(d.ShutdownCtx, w)
+				require.NoError(t, err)
+			}
+
+			statusCode, body := probeAPI(t, client, http.MethodPost, srvURL+"/1.0/batches/b1/:plan", nil, nil)
+			require.Equal(t, tc.wantHTTPStatus, statusCode, body)
+
+			// Nothing may be persisted by the plan.
+			resultBatch, err := d.batch.GetByName(t.Context(), "b1")
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resultBatch.Status)
+
+			resultQueue, err := d.queue.GetAllByBatch(t.Context(), "b1")
+			require.NoError(t, err)
+			require.Empty(t, resultQueue)
+
+			if tc.wantHTTPStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Metadata []api.BatchPlanEntry `json:"metadata"`
+			}
+
+			require.NoError(t, json.Unmarshal([]byte(body), &resp))
+			require.Len(t, resp.Metadata, len(tc.instances))
+			for _, entry := range resp.Metadata {
+				require.True(t, slices.ContainsFunc(tc.instances, func(inst migration.Instance) bool {
+					return inst.UUID == entry.InstanceUUID && inst.GetName() == entry.InstanceName
+				}))
+				require.Equal(t, tc.wantStatus[entry.InstanceName], entry.MigrationStatus)
+				require.Equal(t, tc.wantWindows[entry.InstanceName], entry.MigrationWindow.Name)
+				require.Equal(t, tc.wantReasons[entry.InstanceName], entry.BlockingReasons)
+				require.Equal(t, "tgt", entry.Placement.TargetName)
+				require.Equal(t, map[string]string{entry.InstanceName + "_disk_1": "default"}, entry.Placement.StoragePools)
+			}
+		})
+	}
+}
+
+func TestBatchAPI_start(t *testing.T) {
+	defaultTargetEndpoint := func(api.Target) (migration.TargetEndpoint, error) {
+		return &mock.TargetEndpointMock{
+			ConnectFunc:                func(ctx context.Context) error { return nil },
+			IsWaitingForOIDCTokensFunc: func() bool { return false },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	defaultSourceEndpointFunc := func(api.Source) (migration.SourceEndpoint, error) {
+		return &mock.SourceEndpointMock{
+			ConnectFunc: func(ctx context.Context) error { return nil },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	uuids := uuidCache{}
+	cases := []struct {
+		name string
+
+		instances     migration.Instances
+		sourceType    api.SourceType
+		targetDetails *target.IncusDetails
+
+		wantHTTPStatus int
+		wantStatus     api.BatchStatusType
+	}{
+		{
+			name: "success - instances fit on the target",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			targetDetails: &target.IncusDetails{Name: "tgt", StoragePoolFreeSpace: map[string]int64{"default": 2 * 1024 * 1024 * 1024}},
+
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     api.BATCHSTATUS_RUNNING,
+		},
+		{
+			name: "success - unreachable target is checked when the import begins",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     api.BATCHSTATUS_RUNNING,
+		},
+		{
+			name: "error - instances together don't fit in the storage pool",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			targetDetails: &target.IncusDetails{Name: "tgt", StoragePoolFreeSpace: map[string]int64{"default": 1024 * 1024 * 1024}},
+
+			wantHTTPStatus: http.StatusBadRequest,
+			wantStatus:     api.BATCHSTATUS_DEFINED,
+		},
+		{
+			name: "error - project limits exceeded",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			targetDetails: &target.IncusDetails{Name: "tgt", ProjectQuotas: map[string]target.ProjectQuota{"default": {CPUs: 0, Memory: -1, Disk: -1}}},
+
+			wantHTTPStatus: http.StatusBadRequest,
+			wantStatus:     api.BATCHSTATUS_DEFINED,
+		},
+		{
+			name: "error - instances replayed from a dump can't be migrated",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false),
+			},
+			sourceType:    api.SOURCETYPE_VMWARE_DUMP,
+			targetDetails: &target.IncusDetails{Name: "tgt", StoragePoolFreeSpace: map[string]int64{"default": 2 * 1024 * 1024 * 1024}},
+
+			wantHTTPStatus: http.StatusBadRequest,
+			wantStatus:     api.BATCHSTATUS_DEFINED,
+		},
+	}
+
+	require.NoError(t, properties.InitDefinitions())
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			d.config.Network.WorkerEndpoint = "https://10.10.10.10:7777"
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{batchStartCmd}, nil)
+
+			// Mark schema updates as complete.
+			close(d.migrationCh)
+
+			origTarget := target.NewTarget
+			defer func() {
+				target.NewTarget = origTarget
+			}()
+
+			target.NewTarget = func(tgt api.Target) (target.Target, error) {
+				return &target.TargetMock{
+					TimeoutFunc: func() time.Duration { return time.Second },
+					GetNameFunc: func() string { return tgt.Name },
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					GetDetailsFunc: func(ctx context.Context) (*target.IncusDetails, error) {
+						if tc.targetDetails == nil {
+							return nil, boom.Error
+						}
+
+						return tc.targetDetails, nil
+					},
+				}, nil
+			}
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: defaultSourceEndpointFunc}
+			if tc.sourceType == api.SOURCETYPE_VMWARE_DUMP {
+				src.SourceType = tc.sourceType
+				src.Properties = json.RawMessage(`{"path": "/srv/dump"}`)
+			}
+
+			_, err := d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			tgt := migration.Target{Name: "tgt", TargetType: api.TARGETTYPE_INCUS, Properties: json.RawMessage(`{"endpoint": "bar", "create_limit": 5, "connection_timeout": "30s"}`), EndpointFunc: defaultTargetEndpoint}
+			_, err = d.target.Create(d.ShutdownCtx, tgt)
+			require.NoError(t, err)
+
+			for _, inst := range tc.instances {
+				inst.SourceType = src.SourceType
+				_, err = d.instance.Create(d.ShutdownCtx, inst)
+				require.NoError(t, err)
+			}
+
+			batch := migration.Batch{
+				Name: "b1",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "tgt", TargetProject: "default", StoragePool: "default"},
+				},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					RestrictionOverrides:     api.InstanceRestrictionOverride{AllowNoIPv4: true},
+				},
+			}
+
+			_, err = d.batch.Create(d.ShutdownCtx, batch)
+			require.NoError(t, err)
+
+			statusCode, body := probeAPI(t, client, http.MethodPost, srvURL+"/1.0/batches/b1/:start", nil, nil)
+			require.Equal(t, tc.wantHTTPStatus, statusCode, body)
+
+			resultBatch, err := d.batch.GetByName(t.Context(), "b1")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, resultBatch.Status)
+
+			resultQueue, err := d.queue.GetAllByBatch(t.Context(), "b1")
+			require.NoError(t, err)
+			if tc.wantStatus == api.BATCHSTATUS_DEFINED {
+				require.Empty(t, resultQueue)
+			} else {
+				require.Len(t, resultQueue, len(tc.instances))
+			}
+		})
+	}
+}
+
+func TestBatchAPI_checkBatchProfiles(t *testing.T) {
+	defaultTargetEndpoint := func(api.Target) (migration.TargetEndpoint, error) {
+		return &mock.TargetEndpointMock{
+			ConnectFunc:                func(ctx context.Context) error { return nil },
+			IsWaitingForOIDCTokensFunc: func() bool { return false },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	defaultSourceEndpointFunc := func(api.Source) (migration.SourceEndpoint, error) {
+		return &mock.SourceEndpointMock{
+			ConnectFunc: func(ctx context.Context) error { return nil },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	placeVM2 := `
+def placement(instance, batch):
+    if instance.name == "vm2":
+        set_target("tgt2")
+        set_project("project2")
+`
+
+	uuids := uuidCache{}
+	cases := []struct {
+		name               string
+		profiles           []string
+		includeExpression  string
+		placementScriptlet string
+		profilesByProject  map[string][]string
+
+		wantProjects []string
+		assertErr    require.ErrorAssertionFunc
+	}{
+		{
+			name:              "success - no profiles",
+			includeExpression: "true",
+
+			assertErr: require.NoError,
+		},
+		{
+			name:              "success - profiles in the default placement",
+			profiles:          []string{"web"},
+			includeExpression: "true",
+			profilesByProject: map[string][]string{"tgt/default": {"default", "web"}},
+
+			wantProjects: []string{"tgt/default"},
+			assertErr:    require.NoError,
+		},
+		{
+			name:               "success - profiles in the placement of each instance",
+			profiles:           []string{"web"},
+			includeExpression:  "true",
+			placementScriptlet: placeVM2,
+			profilesByProject:  map[string][]string{"tgt/default": {"web"}, "tgt2/project2": {"web"}},
+
+			wantProjects: []string{"tgt/default", "tgt2/project2"},
+			assertErr:    require.NoError,
+		},
+		{
+			name:               "success - instance placed elsewhere does not match the batch",
+			profiles:           []string{"web"},
+			includeExpression:  `name == "vm1"`,
+			placementScriptlet: placeVM2,
+			profilesByProject:  map[string][]string{"tgt/default": {"web"}},
+
+			wantProjects: []string{"tgt/default"},
+			assertErr:    require.NoError,
+		},
+		{
+			name:              "error - profile missing in the default placement",
+			profiles:          []string{"web"},
+			includeExpression: "true",
+			profilesByProject: map[string][]string{"tgt/default": {"default"}},
+
+			wantProjects: []string{"tgt/default"},
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Profile "web" does not exist in project "default" of target "tgt"`)
+			},
+		},
+		{
+			name:               "error - profile missing in the placement of an instance",
+			profiles:           []string{"web"},
+			includeExpression:  "true",
+			placementScriptlet: placeVM2,
+			profilesByProject:  map[string][]string{"tgt/default": {"web"}, "tgt2/project2": {"default"}},
+
+			wantProjects: []string{"tgt/default", "tgt2/project2"},
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Profile "web" does not exist in project "project2" of target "tgt2"`)
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+
+			origTarget := target.NewTarget
+			defer func() {
+				target.NewTarget = origTarget
+			}()
+
+			checkedProjects := []string{}
+			target.NewTarget = func(tgt api.Target) (target.Target, error) {
+				var project string
+				return &target.TargetMock{
+					TimeoutFunc: func() time.Duration { return time.Second },
+					GetNameFunc: func() string { return tgt.Name },
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					SetProjectFunc: func(p string) error {
+						project = p
+						return nil
+					},
+					GetProfileNamesFunc: func() ([]string, error) {
+						checkedProjects = append(checkedProjects, tgt.Name+"/"+project)
+						return tc.profilesByProject[tgt.Name+"/"+project], nil
+					},
+				}, nil
+			}
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: defaultSourceEndpointFunc}
+			_, err := d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			for _, name := range []string{"tgt", "tgt2"} {
+				tgt := migration.Target{Name: name, TargetType: api.TARGETTYPE_INCUS, Properties: json.RawMessage(`{"endpoint": "bar", "create_limit": 5, "connection_timeout": "30s"}`), EndpointFunc: defaultTargetEndpoint}
+				_, err = d.target.Create(d.ShutdownCtx, tgt)
+				require.NoError(t, err)
+			}
+
+			for _, name := range []string{"vm1", "vm2"} {
+				_, err = d.instance.Create(d.ShutdownCtx, uuids.newTestInstance(name, map[int]bool{1: true}, map[int]string{}, api.OSTYPE_LINUX, false))
+				require.NoError(t, err)
+			}
+
+			batch := migration.Batch{
+				Name: "b1",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "tgt", TargetProject: "default", StoragePool: "default"},
+					Profiles:  tc.profiles,
+				},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: tc.includeExpression,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					PlacementScriptlet:       tc.placementScriptlet,
+				},
+			}
+
+			err = d.checkBatchProfiles(t.Context(), batch)
+			tc.assertErr(t, err)
+			require.ElementsMatch(t, tc.wantProjects, checkedProjects)
 		})
 	}
 }
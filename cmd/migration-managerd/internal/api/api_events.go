@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/FuturFusion/migration-manager/internal/logger"
+	"github.com/FuturFusion/migration-manager/internal/server/auth"
+	"github.com/FuturFusion/migration-manager/internal/server/response"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+var eventsCmd = APIEndpoint{
+	Path: "events",
+
+	Get: APIEndpointAction{Handler: eventsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+}
+
+// swagger:operation GET /1.0/events server events_get
+//
+//	Get the event stream
+//
+//	Streams lifecycle and logging events as they occur, as newline-delimited JSON.
+//	The connection is kept open until the client disconnects or the server shuts down.
+//
+//	---
+//	produces:
+//	  - application/x-ndjson
+//	parameters:
+//	  - in: query
+//	    name: type
+//	    description: Comma separated list of event types to receive (lifecycle, logging)
+//	    type: string
+//	    example: lifecycle,logging
+//	  - in: query
+//	    name: level
+//	    description: Minimum level of logging events (DEBUG, INFO, WARN, ERROR), defaults to the server log level
+//	    type: string
+//	    example: INFO
+//	responses:
+//	  "200":
+//	    description: Event stream
+//	    schema:
+//	      $ref: "#/definitions/Event"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func eventsGet(d *Daemon, r *http.Request) response.Response {
+	scopes := []api.LogScope{api.LogScopeLifecycle, api.LogScopeLogging}
+	if r.FormValue("type") != "" {
+		scopes = []api.LogScope{}
+		for _, s := range strings.Split(r.FormValue("type"), ",") {
+			scope := api.LogScope(strings.TrimSpace(s))
+			switch scope {
+			case api.LogScopeLifecycle:
+			case api.LogScopeLogging:
+			default:
+				return response.BadRequest(fmt.Errorf("Unknown event type %q", scope))
+			}
+
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	level := d.logHandler.Level()
+	if r.FormValue("level") != "" {
+		levelStr := strings.ToUpper(r.FormValue("level"))
+		err := logger.ValidateLevel(levelStr)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		level = logger.ParseLevel(levelStr)
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return fmt.Errorf("Streaming is not supported by the connection")
+		}
+
+		listener := d.logHandler.AddListener(level, scopes)
+		defer listener.Close()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		encoder := json.NewEncoder(w)
+		for {
+			select {
+			case <-r.Context().Done():
+				return nil
+			case <-d.ShutdownCtx.Done():
+				return nil
+			case event := <-listener.Events():
+				err := encoder.Encode(event)
+				if err != nil {
+					// The client has most likely gone away.
+					return nil
+				}
+
+				flusher.Flush()
+			}
+		}
+	})
+}
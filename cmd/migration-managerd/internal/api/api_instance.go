@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lxc/incus/v6/shared/revert"
 
+	"github.com/FuturFusion/migration-manager/internal/migration"
 	"github.com/FuturFusion/migration-manager/internal/server/auth"
 	"github.com/FuturFusion/migration-manager/internal/server/response"
 	"github.com/FuturFusion/migration-manager/internal/server/util"
@@ -22,21 +25,27 @@ import (
 var instancesCmd = APIEndpoint{
 	Path: "instances",
 
-	Get: APIEndpointAction{Handler: instancesGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+	Get: APIEndpointAction{Handler: instancesGet, AccessHandler: allowAuthenticated},
 }
 
 var instanceCmd = APIEndpoint{
 	Path: "instances/{uuid}",
 
-	Get: APIEndpointAction{Handler: instanceGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView), Authenticator: TokenAuthenticate},
+	Get: APIEndpointAction{Handler: instanceGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "uuid"), Authenticator: TokenAuthenticate},
+}
+
+var instanceNetworkACLsCmd = APIEndpoint{
+	Path: "instances/{uuid}/network-acls",
+
+	Get: APIEndpointAction{Handler: instanceNetworkACLsGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "uuid")},
 }
 
 var instanceOverrideCmd = APIEndpoint{
 	Path: "instances/{uuid}/override",
 
-	Delete: APIEndpointAction{Handler: instanceOverrideDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
-	Get:    APIEndpointAction{Handler: instanceOverrideGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
-	Put:    APIEndpointAction{Handler: instanceOverridePut, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: instanceOverrideDelete, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanDelete, "uuid")},
+	Get:    APIEndpointAction{Handler: instanceOverrideGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "uuid")},
+	Put:    APIEndpointAction{Handler: instanceOverridePut, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
 }
 
 // swagger:operation GET /1.0/instances instances instances_get
@@ -139,22 +148,44 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		recursion = 1
 	}
 
-	if recursion == 1 {
-		ctx, trans := transaction.Begin(r.Context())
-		defer func() {
-			rollbackErr := trans.Rollback()
-			if rollbackErr != nil {
-				response.SmartError(fmt.Errorf("Transaction rollback failed: %v, reason: %w", rollbackErr, err))
-			}
-		}()
+	canView, err := d.Authorizer().GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeInstance)
+	if err != nil {
+		return response.SmartError(err)
+	}
 
-		instances, err := d.instance.GetAll(ctx)
-		if err != nil {
-			return response.SmartError(err)
+	ctx, trans := transaction.Begin(r.Context())
+	defer func() {
+		rollbackErr := trans.Rollback()
+		if rollbackErr != nil {
+			response.SmartError(fmt.Errorf("Transaction rollback failed: %v, reason: %w", rollbackErr, err))
 		}
+	}()
 
-		result := make([]api.Instance, 0, len(instances))
-		for _, instance := range instances {
+	instances, err := d.instance.GetAll(ctx)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	queueEntries, err := d.queue.GetAll(ctx)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	queueEntriesByUUID := make(map[uuid.UUID]*migration.QueueEntry, len(queueEntries))
+	for i := range queueEntries {
+		queueEntriesByUUID[queueEntries[i].InstanceUUID] = &queueEntries[i]
+	}
+
+	visibleInstances := make(migration.Instances, 0, len(instances))
+	for _, instance := range instances {
+		if canView(auth.ObjectInstance(instance.UUID.String()), instanceAuthParents(instance, queueEntriesByUUID[instance.UUID])...) {
+			visibleInstances = append(visibleInstances, instance)
+		}
+	}
+
+	if recursion == 1 {
+		result := make([]api.Instance, 0, len(visibleInstances))
+		for _, instance := range visibleInstances {
 			if includeExpression == "" {
 				result = append(result, instance.ToAPI())
 				continue
@@ -182,14 +213,9 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SyncResponse(true, result)
 	}
 
-	instanceUUIDs, err := d.instance.GetAllUUIDs(r.Context())
-	if err != nil {
-		return response.SmartError(err)
-	}
-
-	result := make([]string, 0, len(instanceUUIDs))
-	for _, UUID := range instanceUUIDs {
-		result = append(result, fmt.Sprintf("/%s/instances/%s", api.APIVersion, UUID))
+	result := make([]string, 0, len(visibleInstances))
+	for _, instance := range visibleInstances {
+		result = append(result, fmt.Sprintf("/%s/instances/%s", api.APIVersion, instance.UUID))
 	}
 
 	return response.SyncResponse(true, result)
@@ -257,6 +283,71 @@ func instanceGet(d *Daemon, r *http.Request) response.Response {
 	)
 }
 
+// swagger:operation GET /1.0/instances/{uuid}/network-acls instances instance_network_acls_get
+//
+//	Preview the instance network ACLs
+//
+//	Translates the source firewall rules that apply to the instance into network ACLs, without applying them.
+//	Rules that cannot be represented are listed as warnings.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: InstanceNetworkACLs
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceNetworkACLs"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceNetworkACLsGet(d *Daemon, r *http.Request) response.Response {
+	UUIDString := r.PathValue("uuid")
+
+	UUID, err := uuid.Parse(UUIDString)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var acls *api.InstanceNetworkACLs
+	err = transaction.Do(r.Context(), func(ctx context.Context) error {
+		instance, err := d.instance.GetByUUID(ctx, UUID)
+		if err != nil {
+			return fmt.Errorf("Failed to get instance %q: %w", UUID, err)
+		}
+
+		networks, err := d.network.GetAllBySource(ctx, instance.Source)
+		if err != nil {
+			return fmt.Errorf("Failed to get networks for source %q: %w", instance.Source, err)
+		}
+
+		acls, err = instance.NSXNetworkACLs(networks)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, acls)
+}
+
 // swagger:operation GET /1.0/instances/{uuid}/override instances instance_override_get
 //
 //	Get the instance override
@@ -379,9 +470,52 @@ func instanceOverridePut(d *Daemon, r *http.Request) response.Response {
 		return response.PreconditionFailed(err)
 	}
 
+	storedKey, err := d.os.LoadBitLockerKey(UUID)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Keep BitLocker key material out of the database, only recording that a key has been set.
+	var bitLockerKey *api.BitLockerKey
+	if override.BitLocker != nil {
+		err = migration.ValidateBitLockerKey(*override.BitLocker)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		bitLockerKey, err = mergeBitLockerKey(*override.BitLocker, storedKey)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		redactedKey := bitLockerKey.Redacted()
+		override.BitLocker = &redactedKey
+	}
+
+	err = d.checkPostMigrationScripts(ctx, override.PostMigrationScripts)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	override.LastUpdate = time.Now().UTC()
 	currentInstance.Overrides = override
 
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Store the key material before recording the override, so that a redacted override always has a key to go with it.
+	err = d.setBitLockerKey(UUID, bitLockerKey)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating BitLocker key for instance %q: %w", UUID, err))
+	}
+
+	reverter.Add(func() {
+		err := d.setBitLockerKey(UUID, storedKey)
+		if err != nil {
+			slog.Error("Failed to restore BitLocker key", slog.String("instance", UUID.String()), slog.Any("error", err))
+		}
+	})
+
 	err = d.instance.UpdateOverride(ctx, UUID, override)
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed updating override for instance %q: %w", UUID, err))
@@ -392,6 +526,8 @@ func instanceOverridePut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed commit transaction: %w", err))
 	}
 
+	reverter.Success()
+
 	apiInstance := currentInstance.ToAPI()
 	d.logHandler.SendLifecycle(r.Context(), event.NewInstanceEvent(event.InstanceOverrideModified, r, apiInstance, apiInstance.UUID))
 
@@ -428,6 +564,11 @@ func instanceOverrideDelete(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	storedKey, err := d.os.LoadBitLockerKey(instanceUUID)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var apiInstance api.Instance
 	err = transaction.Do(r.Context(), func(ctx context.Context) error {
 		inst, err := d.instance.GetByUUID(ctx, instanceUUID)
@@ -439,9 +580,21 @@ func instanceOverrideDelete(d *Daemon, r *http.Request) response.Response {
 
 		apiInstance = inst.ToAPI()
 
-		return d.instance.UpdateOverride(ctx, instanceUUID, api.InstanceOverride{})
+		err = d.instance.UpdateOverride(ctx, instanceUUID, api.InstanceOverride{})
+		if err != nil {
+			return err
+		}
+
+		// Remove the key material last, so that a failure leaves the override untouched.
+		return d.os.DeleteBitLockerKey(instanceUUID)
 	})
 	if err != nil {
+		// The key may have been removed before the transaction failed to commit.
+		restoreErr := d.setBitLockerKey(instanceUUID, storedKey)
+		if restoreErr != nil {
+			slog.Error("Failed to restore BitLocker key", slog.String("instance", instanceUUID.String()), slog.Any("error", restoreErr))
+		}
+
 		return response.SmartError(err)
 	}
 
@@ -449,3 +602,33 @@ func instanceOverrideDelete(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// setBitLockerKey stores the given BitLocker key for the instance, or removes any stored key if it is nil.
+func (d *Daemon) setBitLockerKey(instanceUUID uuid.UUID, key *api.BitLockerKey) error {
+	if key == nil {
+		return d.os.DeleteBitLockerKey(instanceUUID)
+	}
+
+	return d.os.SaveBitLockerKey(instanceUUID, *key)
+}
+
+// mergeBitLockerKey resolves redacted values in the new key with the values of the stored key.
+func mergeBitLockerKey(newKey api.BitLockerKey, storedKey *api.BitLockerKey) (*api.BitLockerKey, error) {
+	if newKey.RecoveryPassword == api.RedactedSecret {
+		if storedKey == nil || storedKey.RecoveryPassword == "" {
+			return nil, fmt.Errorf("No stored BitLocker recovery password to keep")
+		}
+
+		newKey.RecoveryPassword = storedKey.RecoveryPassword
+	}
+
+	if newKey.KeyFile == api.RedactedSecret {
+		if storedKey == nil || storedKey.KeyFile == "" {
+			return nil, fmt.Errorf("No stored BitLocker key file to keep")
+		}
+
+		newKey.KeyFile = storedKey.KeyFile
+	}
+
+	return &newKey, nil
+}
@@ -22,19 +22,19 @@ import (
 var instanceResetBackgroundImportCmd = APIEndpoint{
 	Path: "instances/{uuid}/:reset-background-import",
 
-	Post: APIEndpointAction{Handler: instanceResetBackgroundImport, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: instanceResetBackgroundImport, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
 }
 
 var instanceEnableBackgroundImportCmd = APIEndpoint{
 	Path: "instances/{uuid}/:enable-background-import",
 
-	Post: APIEndpointAction{Handler: instanceEnableBackgroundImport, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: instanceEnableBackgroundImport, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
 }
 
 var instancePowerCmd = APIEndpoint{
 	Path: "instances/{uuid}/:power",
 
-	Post: APIEndpointAction{Handler: instancePower, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: instancePower, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
 }
 
 // swagger:operation POST /1.0/instances/{uuid}/:reset-background-import instances instance_reset_background_import
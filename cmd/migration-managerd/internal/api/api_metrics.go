@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/FuturFusion/migration-manager/internal/metrics"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/server/auth"
+	"github.com/FuturFusion/migration-manager/internal/server/request"
+	"github.com/FuturFusion/migration-manager/internal/server/response"
+	"github.com/FuturFusion/migration-manager/internal/transaction"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+var metricsCmd = APIEndpoint{
+	Path: "metrics",
+
+	Get: APIEndpointAction{Handler: metricsGet, AccessHandler: allowMetrics, Authenticator: MetricsAuthenticate},
+}
+
+// allowMetrics lets clients authenticated with a metrics certificate through, and otherwise requires view permission on the server.
+func allowMetrics(d *Daemon, r *http.Request) response.Response {
+	protocol, _ := r.Context().Value(request.CtxProtocol).(string)
+	if protocol == metricsProtocol {
+		return response.EmptySyncResponse
+	}
+
+	return allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)(d, r)
+}
+
+// swagger:operation GET /1.0/metrics metrics metrics_get
+//
+//	Get the metrics
+//
+//	Gets metrics of queue entries, batches, sources, targets, warnings and running migrations in OpenMetrics format.
+//
+//	---
+//	produces:
+//	  - text/plain
+//	responses:
+//	  "200":
+//	    description: Metrics
+//	    schema:
+//	      type: string
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func metricsGet(d *Daemon, r *http.Request) response.Response {
+	var queueEntries migration.QueueEntries
+	var batches migration.Batches
+	var sources migration.Sources
+	var targets migration.Targets
+	var warnings migration.Warnings
+	err := transaction.Do(r.Context(), func(ctx context.Context) error {
+		var err error
+		queueEntries, err = d.queue.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		batches, err = d.batch.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		sources, err = d.source.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		targets, err = d.target.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		warnings, err = d.warning.GetAll(ctx)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	set := metrics.NewMetricSet()
+
+	type queueKey struct {
+		batch  string
+		status api.MigrationStatusType
+	}
+
+	queueCounts := map[queueKey]int{}
+	for _, q := range queueEntries {
+		queueCounts[queueKey{batch: q.BatchName, status: q.MigrationStatus}]++
+
+		if !q.IsMigrating() {
+			continue
+		}
+
+		labels := map[string]string{"batch": q.BatchName, "instance": q.InstanceUUID.String()}
+		lastUpdate := d.queueHandler.LastWorkerUpdate(q.InstanceUUID)
+		if !lastUpdate.IsZero() {
+			set.AddSamples(metrics.WorkerLastSeenSeconds, metrics.Sample{Labels: labels, Value: time.Since(lastUpdate).Seconds()})
+		}
+
+		if q.TransferProgress != nil {
+			set.AddSamples(metrics.TransferCopiedBytes, metrics.Sample{Labels: labels, Value: float64(q.TransferProgress.BytesCopied)})
+			set.AddSamples(metrics.TransferTotalBytes, metrics.Sample{Labels: labels, Value: float64(q.TransferProgress.BytesTotal)})
+		}
+	}
+
+	for key, count := range queueCounts {
+		set.AddSamples(metrics.QueueEntries, metrics.Sample{Labels: map[string]string{"batch": key.batch, "status": string(key.status)}, Value: float64(count)})
+	}
+
+	batchCounts := map[api.BatchStatusType]int{}
+	for _, b := range batches {
+		batchCounts[b.Status]++
+	}
+
+	for status, count := range batchCounts {
+		set.AddSamples(metrics.Batches, metrics.Sample{Labels: map[string]string{"status": string(status)}, Value: float64(count)})
+	}
+
+	for _, s := range sources {
+		labels := map[string]string{"source": s.Name, "type": string(s.SourceType), "status": string(s.GetExternalConnectivityStatus())}
+		set.AddSamples(metrics.SourceConnectivity, metrics.Sample{Labels: labels, Value: 1})
+
+		if !s.SyncStatus.LastSync.IsZero() {
+			set.AddSamples(metrics.SourceSyncDurationSeconds, metrics.Sample{Labels: map[string]string{"source": s.Name}, Value: s.SyncStatus.LastSyncDuration.Seconds()})
+		}
+	}
+
+	for _, t := range targets {
+		labels := map[string]string{"target": t.Name, "type": string(t.TargetType), "status": string(t.GetExternalConnectivityStatus())}
+		set.AddSamples(metrics.TargetConnectivity, metrics.Sample{Labels: labels, Value: 1})
+	}
+
+	warningCounts := map[api.WarningType]int{}
+	for _, w := range warnings {
+		if w.Status == api.WARNINGSTATUS_ACKNOWLEDGED {
+			continue
+		}
+
+		warningCounts[w.Type]++
+	}
+
+	for warningType, count := range warningCounts {
+		set.AddSamples(metrics.Warnings, metrics.Sample{Labels: map[string]string{"type": string(warningType)}, Value: float64(count)})
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		_, err := w.Write([]byte(set.String()))
+		return err
+	})
+}
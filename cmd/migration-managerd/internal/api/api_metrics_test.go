@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/migration/endpoint/mock"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestMetricsAPI_get(t *testing.T) {
+	cases := []struct {
+		name string
+
+		metricsCertOnly bool
+		untrusted       bool
+
+		wantHTTPStatus         int
+		wantWarningsHTTPStatus int
+	}{
+		{
+			name:                   "success - trusted client",
+			wantHTTPStatus:         http.StatusOK,
+			wantWarningsHTTPStatus: http.StatusOK,
+		},
+		{
+			name:                   "success - metrics certificate can only access metrics",
+			metricsCertOnly:        true,
+			wantHTTPStatus:         http.StatusOK,
+			wantWarningsHTTPStatus: http.StatusUnauthorized,
+		},
+		{
+			name:                   "error - untrusted client",
+			untrusted:              true,
+			wantHTTPStatus:         http.StatusUnauthorized,
+			wantWarningsHTTPStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			instUUID := uuid.New()
+			d := daemonSetup(t)
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{metricsCmd, warningsCmd}, nil)
+
+			batch := migration.Batch{
+				Name: "b1",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "default", TargetProject: "default", StoragePool: "default"},
+				},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			}
+
+			_, err := d.batch.Create(d.ShutdownCtx, batch)
+			require.NoError(t, err)
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+				return &mock.SourceEndpointMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+						return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+					},
+				}, nil
+			}}
+
+			_, err = d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			inst := migration.Instance{
+				UUID:                 instUUID,
+				Source:               src.Name,
+				SourceType:           src.SourceType,
+				LastUpdateFromSource: time.Now(),
+				Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm"}, Location: "vm"},
+			}
+
+			_, err = d.instance.Create(t.Context(), inst)
+			require.NoError(t, err)
+
+			_, err = d.queue.CreateEntry(t.Context(), migration.QueueEntry{
+				InstanceUUID:     instUUID,
+				BatchName:        batch.Name,
+				MigrationStatus:  api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+				SecretToken:      uuid.New(),
+				ImportStage:      migration.IMPORTSTAGE_BACKGROUND,
+				Placement:        api.Placement{TargetName: "tgt", TargetProject: "default", StoragePools: map[string]string{"root": "default"}, Networks: map[string]api.NetworkPlacement{}},
+				TransferProgress: &api.DiskTransferProgress{BytesCopied: 512, BytesTotal: 2048, Disks: []api.DiskProgress{{Name: "disk1", BytesCopied: 512, BytesTotal: 2048}}},
+			})
+			require.NoError(t, err)
+
+			_, err = d.warning.Emit(t.Context(), migration.NewSyncWarning(api.InstanceImportFailed, src.Name, "failed"))
+			require.NoError(t, err)
+
+			d.queueHandler.RecordWorkerUpdate(instUUID)
+			err = d.source.UpdateSyncStatus(t.Context(), src.Name, api.SourceSyncStatus{LastSync: time.Now().UTC(), LastSyncDuration: api.AsDuration(1500 * time.Millisecond)})
+			require.NoError(t, err)
+
+			if tc.metricsCertOnly {
+				d.config.Security.TrustedMetricsTLSClientCertFingerprints = d.config.Security.TrustedTLSClientCertFingerprints
+				d.config.Security.TrustedTLSClientCertFingerprints = nil
+			}
+
+			if tc.untrusted {
+				d.config.Security.TrustedTLSClientCertFingerprints = nil
+			}
+
+			statusCode, body := probeAPI(t, client, http.MethodGet, srvURL+"/1.0/warnings", nil, nil)
+			require.Equal(t, tc.wantWarningsHTTPStatus, statusCode, body)
+
+			statusCode, body = probeAPI(t, client, http.MethodGet, srvURL+"/1.0/metrics", nil, nil)
+			require.Equal(t, tc.wantHTTPStatus, statusCode, body)
+			if tc.wantHTTPStatus != http.StatusOK {
+				return
+			}
+
+			require.Contains(t, body, "# TYPE migration_manager_queue_entries gauge\n")
+			require.Contains(t, body, `migration_manager_queue_entries{batch="b1",status="Performing background import tasks"} 1`+"\n")
+			require.Contains(t, body, `migration_manager_batches{status="Defined"} 1`+"\n")
+			require.Contains(t, body, `migration_manager_source_connectivity{source="src",status="OK",type="vmware"} 1`+"\n")
+			require.Contains(t, body, `migration_manager_warnings{type="Instances not imported"} 1`+"\n")
+			require.Contains(t, body, `migration_manager_source_sync_duration_seconds{source="src"} 1.5`+"\n")
+			require.Contains(t, body, `migration_manager_worker_last_seen_seconds{batch="b1",instance="`+instUUID.String()+`"}`)
+			require.Contains(t, body, `migration_manager_transfer_copied_bytes{batch="b1",instance="`+instUUID.String()+`"} 512`+"\n")
+			require.Contains(t, body, `migration_manager_transfer_total_bytes{batch="b1",instance="`+instUUID.String()+`"} 2048`+"\n")
+			require.Contains(t, body, "# EOF\n")
+		})
+	}
+}
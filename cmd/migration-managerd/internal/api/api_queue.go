@@ -23,29 +23,40 @@ import (
 var queueRootCmd = APIEndpoint{
 	Path: "queue",
 
-	Get: APIEndpointAction{Handler: queueRootGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+	Get: APIEndpointAction{Handler: queueRootGet, AccessHandler: allowAuthenticated},
 }
 
 var queueCmd = APIEndpoint{
 	Path: "queue/{uuid}",
 
-	Get:    APIEndpointAction{Handler: queueGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
-	Delete: APIEndpointAction{Handler: queueDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
+	Get:    APIEndpointAction{Handler: queueGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "uuid")},
+	Delete: APIEndpointAction{Handler: queueDelete, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanDelete, "uuid")},
 }
 
 var queueCancelCmd = APIEndpoint{
 	Path: "queue/{uuid}/:cancel",
-	Post: APIEndpointAction{Handler: queueCancel, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: queueCancel, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
 }
 
 var queueRetryCmd = APIEndpoint{
 	Path: "queue/{uuid}/:retry",
-	Post: APIEndpointAction{Handler: queueRetry, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: queueRetry, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
+}
+
+var queueRollbackCmd = APIEndpoint{
+	Path: "queue/{uuid}/:rollback",
+	Post: APIEndpointAction{Handler: queueRollback, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
+}
+
+var queueHistoryCmd = APIEndpoint{
+	Path: "queue/{uuid}/history",
+
+	Get: APIEndpointAction{Handler: queueHistoryGet, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanView, "uuid")},
 }
 
 var queueResolveCmd = APIEndpoint{
 	Path: "queue/{uuid}/:resolve",
-	Post: APIEndpointAction{Handler: queueResolve, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: queueResolve, AccessHandler: allowPermission(auth.ObjectTypeInstance, auth.EntitlementCanEdit, "uuid")},
 }
 
 // swagger:operation GET /1.0/queue queue queueRoot_get
@@ -135,6 +146,11 @@ func queueRootGet(d *Daemon, r *http.Request) response.Response {
 		recursion = 0
 	}
 
+	canView, err := d.Authorizer().GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeInstance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var result []api.QueueEntry
 	var paths []string
 	err = transaction.Do(r.Context(), func(ctx context.Context) error {
@@ -151,6 +167,10 @@ func queueRootGet(d *Daemon, r *http.Request) response.Response {
 					return err
 				}
 
+				if !canView(auth.ObjectInstance(queueItem.InstanceUUID.String()), instanceAuthParents(*instance, &queueItem)...) {
+					continue
+				}
+
 				var migrationWindow *migration.Window
 				windowID := queueItem.GetWindowName()
 				if windowID != nil {
@@ -178,6 +198,15 @@ func queueRootGet(d *Daemon, r *http.Request) response.Response {
 
 		paths = make([]string, 0, len(queueItems))
 		for _, queueItem := range queueItems {
+			instance, err := d.instance.GetByUUID(ctx, queueItem.InstanceUUID)
+			if err != nil {
+				return err
+			}
+
+			if !canView(auth.ObjectInstance(queueItem.InstanceUUID.String()), instanceAuthParents(*instance, &queueItem)...) {
+				continue
+			}
+
 			paths = append(paths, fmt.Sprintf("/%s/queue/%s", api.APIVersion, queueItem.InstanceUUID))
 		}
 
@@ -288,6 +317,65 @@ func queueGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponseETag(true, queueItem.ToAPI(instanceName, d.queueHandler.LastWorkerUpdate(queueItem.InstanceUUID), *migrationWindow), queueItem)
 }
 
+// swagger:operation GET /1.0/queue/{uuid}/history queue queue_history_get
+//
+//	Get the migration history of an instance
+//
+//	Returns the recorded migration state transitions of the instance, oldest first.
+//	The history is retained after the queue entry or its batch is removed.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Migration history
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of migration state transitions
+//	          items:
+//	            $ref: "#/definitions/QueueHistoryEntry"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func queueHistoryGet(d *Daemon, r *http.Request) response.Response {
+	instanceUUID, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	history, err := d.queue.GetHistoryByInstanceUUID(r.Context(), instanceUUID)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	result := make([]api.QueueHistoryEntry, 0, len(history))
+	for _, entry := range history {
+		result = append(result, entry.ToAPI())
+	}
+
+	return response.SyncResponse(true, result)
+}
+
 // swagger:operation DELETE /1.0/queue/{uuid} queue queue_delete
 //
 //	Delete the queue
@@ -453,13 +541,17 @@ func queueCancel(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
-		err = is.Connect(r.Context())
+		srcCtx, srcCancel := context.WithTimeout(r.Context(), is.Timeout())
+		defer srcCancel()
+		err = is.Connect(srcCtx)
 		if err != nil {
 			return response.SmartError(err)
 		}
 
+		defer func() { _ = is.Disconnect(r.Context()) }()
+
 		// Try to power on the VM in case it was powered off during migration.
-		err = is.PowerOnVM(r.Context(), location)
+		err = is.PowerOnVM(srcCtx, location)
 		if err != nil {
 			return response.SmartError(err)
 		}
@@ -611,3 +703,193 @@ func queueResolve(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// swagger:operation POST /1.0/queue/{uuid}/:rollback queue queue_rollback
+//
+//	Rolls back the queue entry
+//
+//	Rolls back a finished migration by stopping and renaming, or deleting, the target instance,
+//	and powering the source VM back on if it was running before the migration.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: delete
+//	    description: Whether to delete the target instance instead of renaming it.
+//	    type: string
+//	    example: "1"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func queueRollback(d *Daemon, r *http.Request) response.Response {
+	// Exclusively grab the worker lock so migration actions don't interfere.
+	workerLock.Lock()
+	defer workerLock.Unlock()
+
+	uuidStr := r.PathValue("uuid")
+	queueUUID, err := uuid.Parse(uuidStr)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	deleteTarget := r.FormValue("delete") == "1"
+	apiQueue, err := d.rollbackQueueEntry(r.Context(), queueUUID, deleteTarget, "Rolled back to source")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	d.logHandler.SendLifecycle(r.Context(), event.NewQueueEntryEvent(event.QueueEntryRolledBack, r, *apiQueue, apiQueue.InstanceUUID))
+
+	return response.EmptySyncResponse
+}
+
+// rollbackQueueEntry stops and renames the target instance (or deletes it if deleteTarget is set), and powers the source VM back on
+// if it was running before the migration. The queue entry is only marked as rolled back once all of these succeeded,
+// so that a failed rollback can be retried.
+func (d *Daemon) rollbackQueueEntry(ctx context.Context, queueUUID uuid.UUID, deleteTarget bool, statusMessage string) (*api.QueueEntry, error) {
+	var src *migration.Source
+	var tgt *migration.Target
+	var location string
+	var instName string
+	var q *migration.QueueEntry
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		var err error
+		q, err = d.queue.CanRollbackByUUID(ctx, queueUUID)
+		if err != nil {
+			return err
+		}
+
+		inst, err := d.instance.GetByUUID(ctx, queueUUID)
+		if err != nil {
+			return err
+		}
+
+		if q.Placement.Running {
+			location = inst.Properties.Location
+			src, err = d.source.GetByName(ctx, inst.Source)
+			if err != nil {
+				return err
+			}
+		}
+
+		tgt, err = d.target.GetByName(ctx, q.Placement.TargetName)
+		if err != nil {
+			return err
+		}
+
+		instName = inst.GetName()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := target.NewTarget(tgt.ToAPI())
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.Timeout())
+	defer cancel()
+	err = t.Connect(timeoutCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.SetProject(q.Placement.TargetProject)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleteTarget {
+		err = t.CleanupVM(timeoutCtx, instName, false)
+		if err != nil && !incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, err
+		}
+	} else {
+		instInfo, _, err := t.GetInstance(instName)
+		if err != nil && !incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, err
+		}
+
+		// The target instance may already have been renamed by a previous rollback attempt.
+		if instInfo != nil {
+			if instInfo.Status == "Running" {
+				err = t.StopVM(timeoutCtx, instName, true)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to stop target instance %q: %w", instName, err)
+				}
+			}
+
+			newName, err := rolledBackInstanceName(t, instName)
+			if err != nil {
+				return nil, err
+			}
+
+			err = t.RenameVM(timeoutCtx, instName, newName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to rename target instance %q: %w", instName, err)
+			}
+		}
+	}
+
+	if src != nil {
+		is, err := source.NewVMSource(src.ToAPI())
+		if err != nil {
+			return nil, err
+		}
+
+		srcCtx, srcCancel := context.WithTimeout(ctx, is.Timeout())
+		defer srcCancel()
+		err = is.Connect(srcCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = is.Disconnect(ctx) }()
+
+		err = is.PowerOnVM(srcCtx, location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	q, err = d.queue.RollbackByUUID(ctx, queueUUID, statusMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use an empty window since the queue entry is rolled back.
+	apiQueue := q.ToAPI(instName, d.queueHandler.LastWorkerUpdate(q.InstanceUUID), migration.Window{})
+
+	return &apiQueue, nil
+}
+
+// rolledBackInstanceName returns the first name of the form <name>-rolled-back[-N] that is not yet used on the target,
+// so that an instance can be rolled back again after being migrated a second time.
+func rolledBackInstanceName(t target.Target, instName string) (string, error) {
+	for i := 1; ; i++ {
+		newName := instName + "-rolled-back"
+		if i > 1 {
+			newName = fmt.Sprintf("%s-%d", newName, i)
+		}
+
+		_, _, err := t.GetInstance(newName)
+		if err != nil {
+			if incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+				return newName, nil
+			}
+
+			return "", fmt.Errorf("Failed to check for existing target instance %q: %w", newName, err)
+		}
+	}
+}
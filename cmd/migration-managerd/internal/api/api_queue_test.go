@@ -5,10 +5,12 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"net/http"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/stretchr/testify/require"
 
 	"github.com/FuturFusion/migration-manager/internal/migration"
@@ -16,6 +18,7 @@ import (
 	"github.com/FuturFusion/migration-manager/internal/properties"
 	"github.com/FuturFusion/migration-manager/internal/source"
 	"github.com/FuturFusion/migration-manager/internal/target"
+	"github.com/FuturFusion/migration-manager/internal/testing/boom"
 	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
@@ -258,9 +261,10 @@ func TestQueueAPI_cancel(t *testing.T) {
 			var ranPowerOn bool
 			source.NewVMSource = func(s api.Source) (source.Source, error) {
 				return &source.SourceMock{
-					TimeoutFunc: func() time.Duration { return time.Second },
-					GetNameFunc: func() string { return s.Name },
-					ConnectFunc: func(ctx context.Context) error { return nil },
+					TimeoutFunc:    func() time.Duration { return time.Second },
+					GetNameFunc:    func() string { return s.Name },
+					ConnectFunc:    func(ctx context.Context) error { return nil },
+					DisconnectFunc: func(ctx context.Context) error { return nil },
 					PowerOnVMFunc: func(ctx context.Context, name string) error {
 						require.True(t, tc.wantPowerOn)
 						ranPowerOn = true
@@ -279,3 +283,408 @@ func TestQueueAPI_cancel(t *testing.T) {
 		})
 	}
 }
+
+func TestQueueAPI_rollback(t *testing.T) {
+	cases := []struct {
+		name string
+
+		deleteTarget      bool
+		status            api.MigrationStatusType
+		placementRunning  bool
+		targetMissing     bool
+		targetRunning     bool
+		gracePeriod       time.Duration
+		finishedSinceTime time.Duration
+		existingNames     []string
+		powerOnErr        error
+
+		wantHTTPStatus int
+		wantStatus     api.MigrationStatusType
+		wantStop       bool
+		wantRename     bool
+		wantRenamedTo  string
+		wantCleanup    bool
+		wantPowerOn    bool
+	}{
+		{
+			name:             "success - rename running target instance, power on source",
+			status:           api.MIGRATIONSTATUS_FINISHED,
+			placementRunning: true,
+			targetRunning:    true,
+			wantHTTPStatus:   http.StatusOK,
+			wantStatus:       api.MIGRATIONSTATUS_ROLLED_BACK,
+			wantStop:         true,
+			wantRename:       true,
+			wantPowerOn:      true,
+		},
+		{
+			name:           "success - rename stopped target instance, source was not running",
+			status:         api.MIGRATIONSTATUS_FINISHED,
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     api.MIGRATIONSTATUS_ROLLED_BACK,
+			wantRename:     true,
+		},
+		{
+			name:           "success - rename with unique suffix after an earlier rollback",
+			status:         api.MIGRATIONSTATUS_FINISHED,
+			existingNames:  []string{"vm-rolled-back", "vm-rolled-back-2"},
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     api.MIGRATIONSTATUS_ROLLED_BACK,
+			wantRename:     true,
+			wantRenamedTo:  "vm-rolled-back-3",
+		},
+		{
+			name:             "error - source power on fails, queue entry is not rolled back",
+			status:           api.MIGRATIONSTATUS_FINISHED,
+			placementRunning: true,
+			powerOnErr:       boom.Error,
+			wantHTTPStatus:   http.StatusInternalServerError,
+			wantStatus:       api.MIGRATIONSTATUS_FINISHED,
+			wantRename:       true,
+			wantPowerOn:      true,
+		},
+		{
+			name:             "success - delete target instance",
+			deleteTarget:     true,
+			status:           api.MIGRATIONSTATUS_FINISHED,
+			placementRunning: true,
+			wantHTTPStatus:   http.StatusOK,
+			wantStatus:       api.MIGRATIONSTATUS_ROLLED_BACK,
+			wantCleanup:      true,
+			wantPowerOn:      true,
+		},
+		{
+			name:           "success - repeated rollback with target instance already renamed",
+			status:         api.MIGRATIONSTATUS_ROLLED_BACK,
+			targetMissing:  true,
+			wantHTTPStatus: http.StatusOK,
+			wantStatus:     api.MIGRATIONSTATUS_ROLLED_BACK,
+		},
+		{
+			name:             "success - within grace period",
+			status:           api.MIGRATIONSTATUS_FINISHED,
+			gracePeriod:      time.Hour,
+			wantHTTPStatus:   http.StatusOK,
+			wantStatus:       api.MIGRATIONSTATUS_ROLLED_BACK,
+			wantRename:       true,
+			placementRunning: true,
+			wantPowerOn:      true,
+		},
+		{
+			name:              "error - grace period has passed",
+			status:            api.MIGRATIONSTATUS_FINISHED,
+			gracePeriod:       time.Nanosecond,
+			finishedSinceTime: time.Millisecond,
+			wantHTTPStatus:    http.StatusBadRequest,
+			wantStatus:        api.MIGRATIONSTATUS_FINISHED,
+		},
+		{
+			name:           "error - migration not finished",
+			status:         api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+			wantHTTPStatus: http.StatusBadRequest,
+			wantStatus:     api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+		},
+	}
+
+	require.NoError(t, properties.InitDefinitions())
+
+	defaultTargetEndpoint := func(api.Target) (migration.TargetEndpoint, error) {
+		return &mock.TargetEndpointMock{
+			ConnectFunc:                func(ctx context.Context) error { return nil },
+			IsWaitingForOIDCTokensFunc: func() bool { return false },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	defaultSourceEndpointFunc := func(api.Source) (migration.SourceEndpoint, error) {
+		return &mock.SourceEndpointMock{
+			ConnectFunc: func(ctx context.Context) error { return nil },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			queueUUID := uuid.New()
+			d := daemonSetup(t)
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{queueRollbackCmd}, nil)
+			path := srvURL + "/1.0/queue/" + queueUUID.String() + "/:rollback"
+			if tc.deleteTarget {
+				path = path + "?delete=1"
+			}
+
+			batch := migration.Batch{
+				Name: "b1",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "default", TargetProject: "default", StoragePool: "default"},
+				},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					RollbackGracePeriod:      api.AsDuration(tc.gracePeriod),
+				},
+			}
+
+			_, err := d.batch.Create(d.ShutdownCtx, batch)
+			require.NoError(t, err)
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: defaultSourceEndpointFunc}
+			_, err = d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			tgt := migration.Target{Name: "tgt", TargetType: api.TARGETTYPE_INCUS, Properties: json.RawMessage(`{"endpoint": "bar", "create_limit": 5, "connection_timeout": "30s"}`), EndpointFunc: defaultTargetEndpoint}
+			_, err = d.target.Create(d.ShutdownCtx, tgt)
+			require.NoError(t, err)
+
+			inst := migration.Instance{
+				UUID:                 queueUUID,
+				Source:               src.Name,
+				SourceType:           src.SourceType,
+				LastUpdateFromSource: time.Now(),
+				Overrides:            api.InstanceOverride{},
+				Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm"}, Location: "vm"},
+			}
+
+			_, err = d.instance.Create(t.Context(), inst)
+			require.NoError(t, err)
+
+			q := migration.QueueEntry{
+				InstanceUUID:    queueUUID,
+				BatchName:       "b1",
+				MigrationStatus: tc.status,
+				SecretToken:     uuid.New(),
+				ImportStage:     migration.IMPORTSTAGE_COMPLETE,
+				Placement:       api.Placement{TargetName: "tgt", TargetProject: "default", StoragePools: map[string]string{"root": "default"}, Networks: map[string]api.NetworkPlacement{}, Running: tc.placementRunning},
+			}
+
+			_, err = d.queue.CreateEntry(t.Context(), q)
+			require.NoError(t, err)
+
+			time.Sleep(tc.finishedSinceTime)
+
+			var ranStop, ranRename, ranCleanup bool
+			origTarget := target.NewTarget
+			origSource := source.NewVMSource
+			defer func() {
+				target.NewTarget = origTarget
+				source.NewVMSource = origSource
+			}()
+
+			target.NewTarget = func(tgt api.Target) (target.Target, error) {
+				return &target.TargetMock{
+					TimeoutFunc:    func() time.Duration { return time.Second },
+					GetNameFunc:    func() string { return tgt.Name },
+					ConnectFunc:    func(ctx context.Context) error { return nil },
+					SetProjectFunc: func(project string) error { return nil },
+					GetInstanceFunc: func(name string) (*incusAPI.Instance, string, error) {
+						if tc.targetMissing || (name != "vm" && !slices.Contains(tc.existingNames, name)) {
+							return nil, "", incusAPI.StatusErrorf(http.StatusNotFound, "Instance not found")
+						}
+
+						status := "Stopped"
+						if tc.targetRunning {
+							status = "Running"
+						}
+
+						return &incusAPI.Instance{Name: name, Status: status}, "", nil
+					},
+					StopVMFunc: func(ctx context.Context, name string, force bool) error {
+						require.True(t, tc.wantStop)
+						ranStop = true
+						return nil
+					},
+					RenameVMFunc: func(ctx context.Context, name string, newName string) error {
+						require.True(t, tc.wantRename)
+						wantName := "vm-rolled-back"
+						if tc.wantRenamedTo != "" {
+							wantName = tc.wantRenamedTo
+						}
+
+						require.Equal(t, wantName, newName)
+						ranRename = true
+						return nil
+					},
+					CleanupVMFunc: func(ctx context.Context, name string, requireWorkerVolume bool) error {
+						require.True(t, tc.wantCleanup)
+						ranCleanup = true
+						return nil
+					},
+				}, nil
+			}
+
+			var ranPowerOn bool
+			source.NewVMSource = func(s api.Source) (source.Source, error) {
+				return &source.SourceMock{
+					TimeoutFunc:    func() time.Duration { return time.Second },
+					GetNameFunc:    func() string { return s.Name },
+					ConnectFunc:    func(ctx context.Context) error { return nil },
+					DisconnectFunc: func(ctx context.Context) error { return nil },
+					PowerOnVMFunc: func(ctx context.Context, name string) error {
+						require.True(t, tc.wantPowerOn)
+						ranPowerOn = true
+						return tc.powerOnErr
+					},
+				}, nil
+			}
+
+			statusCode, body := probeAPI(t, client, http.MethodPost, path, nil, nil)
+			require.Equal(t, tc.wantHTTPStatus, statusCode, body)
+			resultQueue, err := d.queue.GetByInstanceUUID(t.Context(), queueUUID)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, resultQueue.MigrationStatus)
+			require.Equal(t, tc.wantStop, ranStop)
+			require.Equal(t, tc.wantRename, ranRename)
+			require.Equal(t, tc.wantCleanup, ranCleanup)
+			require.Equal(t, tc.wantPowerOn, ranPowerOn)
+		})
+	}
+}
+
+func TestQueueAPI_history(t *testing.T) {
+	cases := []struct {
+		name string
+
+		uuid           string
+		removeEntry    bool
+		removeInstance bool
+
+		wantHTTPStatus int
+		wantStatuses   []api.MigrationStatusType
+	}{
+		{
+			name:           "success - state transitions are recorded",
+			wantHTTPStatus: http.StatusOK,
+			wantStatuses:   []api.MigrationStatusType{api.MIGRATIONSTATUS_WAITING, api.MIGRATIONSTATUS_IDLE, api.MIGRATIONSTATUS_FINISHED},
+		},
+		{
+			name:           "success - history is retained after the queue entry and instance are removed",
+			removeEntry:    true,
+			removeInstance: true,
+			wantHTTPStatus: http.StatusOK,
+			wantStatuses:   []api.MigrationStatusType{api.MIGRATIONSTATUS_WAITING, api.MIGRATIONSTATUS_IDLE, api.MIGRATIONSTATUS_FINISHED},
+		},
+		{
+			name:           "success - unknown instance has no history",
+			uuid:           uuid.NewString(),
+			wantHTTPStatus: http.StatusOK,
+			wantStatuses:   []api.MigrationStatusType{},
+		},
+		{
+			name:           "error - invalid UUID",
+			uuid:           "not-a-uuid",
+			wantHTTPStatus: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			queueUUID := uuid.New()
+			d := daemonSetup(t)
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{queueHistoryCmd}, nil)
+
+			batch := migration.Batch{
+				Name: "b1",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "default", TargetProject: "default", StoragePool: "default"},
+				},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			}
+
+			_, err := d.batch.Create(d.ShutdownCtx, batch)
+			require.NoError(t, err)
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+				return &mock.SourceEndpointMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+						return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+					},
+				}, nil
+			}}
+
+			_, err = d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			inst := migration.Instance{
+				UUID:                 queueUUID,
+				Source:               src.Name,
+				SourceType:           src.SourceType,
+				LastUpdateFromSource: time.Now(),
+				Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm"}, Location: "vm"},
+			}
+
+			_, err = d.instance.Create(t.Context(), inst)
+			require.NoError(t, err)
+
+			_, err = d.queue.CreateEntry(t.Context(), migration.QueueEntry{
+				InstanceUUID:    queueUUID,
+				BatchName:       batch.Name,
+				MigrationStatus: api.MIGRATIONSTATUS_WAITING,
+				SecretToken:     uuid.New(),
+				ImportStage:     migration.IMPORTSTAGE_BACKGROUND,
+				Placement:       api.Placement{TargetName: "tgt", TargetProject: "default", StoragePools: map[string]string{"root": "default"}, Networks: map[string]api.NetworkPlacement{}},
+			})
+			require.NoError(t, err)
+
+			_, err = d.queue.UpdateStatusByUUID(t.Context(), queueUUID, api.MIGRATIONSTATUS_IDLE, "Waiting for worker to connect", migration.IMPORTSTAGE_BACKGROUND, nil)
+			require.NoError(t, err)
+
+			// Changes to the status message alone are not recorded.
+			_, err = d.queue.UpdateStatusByUUID(t.Context(), queueUUID, api.MIGRATIONSTATUS_IDLE, "Worker progress", migration.IMPORTSTAGE_BACKGROUND, nil)
+			require.NoError(t, err)
+
+			_, err = d.queue.UpdateStatusByUUID(t.Context(), queueUUID, api.MIGRATIONSTATUS_FINISHED, string(api.MIGRATIONSTATUS_FINISHED), migration.IMPORTSTAGE_COMPLETE, nil)
+			require.NoError(t, err)
+
+			if tc.removeEntry {
+				require.NoError(t, d.queue.DeleteByUUID(t.Context(), queueUUID))
+			}
+
+			if tc.removeInstance {
+				require.NoError(t, d.instance.DeleteByUUID(t.Context(), queueUUID))
+			}
+
+			id := queueUUID.String()
+			if tc.uuid != "" {
+				id = tc.uuid
+			}
+
+			statusCode, body := probeAPI(t, client, http.MethodGet, srvURL+"/1.0/queue/"+id+"/history", nil, nil)
+			require.Equal(t, tc.wantHTTPStatus, statusCode, body)
+			if tc.wantHTTPStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Metadata []api.QueueHistoryEntry `json:"metadata"`
+			}
+
+			require.NoError(t, json.Unmarshal([]byte(body), &resp))
+
+			statuses := make([]api.MigrationStatusType, 0, len(resp.Metadata))
+			for _, entry := range resp.Metadata {
+				require.Equal(t, id, entry.InstanceUUID.String())
+				require.Equal(t, batch.Name, entry.BatchName)
+				require.False(t, entry.Date.IsZero())
+				statuses = append(statuses, entry.MigrationStatus)
+			}
+
+			require.Equal(t, tc.wantStatuses, statuses)
+		})
+	}
+}
@@ -3,12 +3,18 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	incusTLS "github.com/lxc/incus/v6/shared/tls"
 
+	"github.com/FuturFusion/migration-manager/internal/logger"
 	"github.com/FuturFusion/migration-manager/internal/migration"
 	"github.com/FuturFusion/migration-manager/internal/server/auth"
 	"github.com/FuturFusion/migration-manager/internal/server/response"
@@ -22,28 +28,28 @@ import (
 var sourcesCmd = APIEndpoint{
 	Path: "sources",
 
-	Get:  APIEndpointAction{Handler: sourcesGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+	Get:  APIEndpointAction{Handler: sourcesGet, AccessHandler: allowAuthenticated},
 	Post: APIEndpointAction{Handler: sourcesPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanCreate)},
 }
 
 var sourceCmd = APIEndpoint{
 	Path: "sources/{name}",
 
-	Delete: APIEndpointAction{Handler: sourceDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
-	Get:    APIEndpointAction{Handler: sourceGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
-	Put:    APIEndpointAction{Handler: sourcePut, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: sourceDelete, AccessHandler: allowPermission(auth.ObjectTypeSource, auth.EntitlementCanDelete, "name")},
+	Get:    APIEndpointAction{Handler: sourceGet, AccessHandler: allowPermission(auth.ObjectTypeSource, auth.EntitlementCanView, "name")},
+	Put:    APIEndpointAction{Handler: sourcePut, AccessHandler: allowPermission(auth.ObjectTypeSource, auth.EntitlementCanEdit, "name")},
 }
 
 var sourceSyncCmd = APIEndpoint{
 	Path: "sources/{name}/:sync",
 
-	Post: APIEndpointAction{Handler: sourceSyncPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
+	Post: APIEndpointAction{Handler: sourceSyncPost, AccessHandler: allowPermission(auth.ObjectTypeSource, auth.EntitlementCanDelete, "name")},
 }
 
 var sourceDumpCmd = APIEndpoint{
 	Path: "sources/{name}/:dump",
 
-	Post: APIEndpointAction{Handler: sourceDumpPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
+	Post: APIEndpointAction{Handler: sourceDumpPost, AccessHandler: allowPermission(auth.ObjectTypeSource, auth.EntitlementCanDelete, "name")},
 }
 
 // swagger:operation GET /1.0/sources sources sources_get
@@ -133,6 +139,11 @@ func sourcesGet(d *Daemon, r *http.Request) response.Response {
 		recursion = 0
 	}
 
+	canView, err := d.Authorizer().GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeSource)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if recursion > 0 {
 		sources, err := d.source.GetAll(r.Context())
 		if err != nil {
@@ -141,6 +152,10 @@ func sourcesGet(d *Daemon, r *http.Request) response.Response {
 
 		result := make([]api.Source, 0, len(sources))
 		for _, src := range sources {
+			if !canView(auth.ObjectSource(src.Name)) {
+				continue
+			}
+
 			if src.SourceType == api.SOURCETYPE_NSX && recursion > 1 {
 				nsxSource, err := source.NewInternalNSXSourceFrom(src.ToAPI())
 				if err != nil {
@@ -166,7 +181,8 @@ func sourcesGet(d *Daemon, r *http.Request) response.Response {
 			}
 
 			apiSrc := src.ToAPI()
-			_, apiSrc.Syncing = d.syncCache.Read(src.Name)
+			apiSrc.Syncing = d.isSourceSyncing(src.Name)
+			apiSrc.SyncStatus = d.sourceSyncStatus(src)
 			result = append(result, apiSrc)
 		}
 
@@ -180,6 +196,10 @@ func sourcesGet(d *Daemon, r *http.Request) response.Response {
 
 	result := make([]string, 0, len(sourceNames))
 	for _, name := range sourceNames {
+		if !canView(auth.ObjectSource(name)) {
+			continue
+		}
+
 		result = append(result, fmt.Sprintf("/%s/sources/%s", api.APIVersion, name))
 	}
 
@@ -239,7 +259,7 @@ func sourcesPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Trigger a scan of this new source for instances.
-	if src.GetExternalConnectivityStatus() == api.EXTERNALCONNECTIVITYSTATUS_OK && src.SourceType == api.SOURCETYPE_VMWARE {
+	if src.GetExternalConnectivityStatus() == api.EXTERNALCONNECTIVITYSTATUS_OK && slices.Contains(api.VMSourceTypes(), src.SourceType) {
 		err = d.syncOneSource(r.Context(), src)
 		if err != nil {
 			return response.SmartError(fmt.Errorf("Failed to initiate sync from source %q: %w", src.Name, err))
@@ -295,6 +315,12 @@ func sourceDelete(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Clean up the secrets of the removed instances.
+	err = d.pruneInstanceSecrets(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	d.logHandler.SendLifecycle(r.Context(), event.NewSourceEvent(event.SourceRemoved, r, apiSrc, apiSrc.Name))
 
 	return response.EmptySyncResponse
@@ -371,7 +397,8 @@ func sourceGet(d *Daemon, r *http.Request) response.Response {
 	}
 
 	apiSrc := src.ToAPI()
-	_, apiSrc.Syncing = d.syncCache.Read(src.Name)
+	apiSrc.Syncing = d.isSourceSyncing(src.Name)
+	apiSrc.SyncStatus = d.sourceSyncStatus(*src)
 	return response.SyncResponseETag(
 		true,
 		apiSrc,
@@ -442,6 +469,7 @@ func sourcePut(d *Daemon, r *http.Request) response.Response {
 		Name:       apiSrc.Name,
 		SourceType: currentSource.SourceType,
 		Properties: apiSrc.Properties,
+		SyncStatus: currentSource.SyncStatus,
 		EndpointFunc: func(s api.Source) (migration.SourceEndpoint, error) {
 			switch s.SourceType {
 			case api.SOURCETYPE_NSX:
@@ -463,7 +491,7 @@ func sourcePut(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Trigger a scan of this new source for instances.
-	if src.GetExternalConnectivityStatus() == api.EXTERNALCONNECTIVITYSTATUS_OK && src.SourceType == api.SOURCETYPE_VMWARE {
+	if src.GetExternalConnectivityStatus() == api.EXTERNALCONNECTIVITYSTATUS_OK && slices.Contains(api.VMSourceTypes(), src.SourceType) {
 		err = d.syncOneSource(r.Context(), *src)
 		if err != nil {
 			return response.SmartError(fmt.Errorf("Failed to initiate sync from source %q: %w", src.Name, err))
@@ -488,10 +516,20 @@ func sourcePut(d *Daemon, r *http.Request) response.Response {
 //	Sync source data
 //
 //	Perform a sync to fetch new source data from a specified source.
+//	If instances are selected, only those instances and the networks they use are refreshed.
 //
 //	---
+//	consumes:
+//	  - application/json
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: sync
+//	    description: Instances to sync
+//	    required: false
+//	    schema:
+//	      $ref: "#/definitions/SourceSyncPost"
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
@@ -509,12 +547,34 @@ func sourceSyncPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	var req api.SourceSyncPost
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return response.BadRequest(err)
+	}
+
 	status := src.GetExternalConnectivityStatus()
 	if status != api.EXTERNALCONNECTIVITYSTATUS_OK {
 		return response.SmartError(fmt.Errorf("Cannot sync source %q with connectivity status %q", src.Name, status))
 	}
 
-	err = d.syncOneSource(r.Context(), *src)
+	var sourceSpecificIDs []string
+	if !req.IsEmpty() {
+		if !slices.Contains(api.VMSourceTypes(), src.SourceType) {
+			return response.BadRequest(fmt.Errorf("Cannot sync individual instances of source %q with type %q", src.Name, src.SourceType))
+		}
+
+		sourceSpecificIDs, err = d.resolveSyncSourceIDs(r.Context(), src.Name, req)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if len(sourceSpecificIDs) == 0 {
+			return response.BadRequest(fmt.Errorf("No instances of source %q match the sync request", src.Name))
+		}
+	}
+
+	err = d.syncOneSource(r.Context(), *src, sourceSpecificIDs...)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -575,3 +635,82 @@ func sourceDumpPost(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// resolveSyncSourceIDs returns the source-specific IDs of the instances of the source selected by the sync request.
+// Given source-specific IDs may also be the inventory path or VM ID of a recorded instance.
+// Unrecorded IDs are passed on as-is, so that newly created VMs can be synced too.
+func (d *Daemon) resolveSyncSourceIDs(ctx context.Context, srcName string, req api.SourceSyncPost) ([]string, error) {
+	instances, err := d.instance.GetAllBySource(ctx, srcName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get instances of source %q: %w", srcName, err)
+	}
+
+	ids := []string{}
+	addID := func(id string) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range req.SourceSpecificIDs {
+		found := false
+		for _, inst := range instances {
+			if inst.Properties.SourceSpecificID == id || inst.Properties.Location == id || strings.HasSuffix(inst.Properties.SourceSpecificID, ":"+id) {
+				addID(inst.Properties.SourceSpecificID)
+				found = true
+			}
+		}
+
+		if !found {
+			addID(id)
+		}
+	}
+
+	for _, instUUID := range req.Instances {
+		found := false
+		for _, inst := range instances {
+			if inst.UUID == instUUID {
+				addID(inst.Properties.SourceSpecificID)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, migration.NewValidationErrf("Instance %q does not belong to source %q", instUUID, srcName)
+		}
+	}
+
+	if req.IncludeExpression != "" {
+		for _, inst := range instances {
+			match, err := inst.MatchesCriteria(req.IncludeExpression, true)
+			if err != nil {
+				return nil, migration.NewValidationErrf("Invalid include expression %q: %v", req.IncludeExpression, err)
+			}
+
+			if match {
+				addID(inst.Properties.SourceSpecificID)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// sourceSyncStatus returns the result of the last sync of the given source, along with the time of its next background sync.
+func (d *Daemon) sourceSyncStatus(src migration.Source) api.SourceSyncStatus {
+	status := src.SyncStatus
+	if d.config.Settings.DisableAutoSync || !slices.Contains(api.VMSourceTypes(), src.SourceType) {
+		return status
+	}
+
+	next, err := src.NextSync(status.LastSync, d.config.Settings.SyncInterval.Duration)
+	if err != nil {
+		slog.Warn("Failed to determine next source sync", slog.String("source", src.Name), logger.Err(err))
+		return status
+	}
+
+	status.NextSync = next
+
+	return status
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/migration/endpoint/mock"
+	"github.com/FuturFusion/migration-manager/internal/source"
+	"github.com/FuturFusion/migration-manager/internal/testing/boom"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func setupSyncSource(t *testing.T, d *Daemon) (migration.Source, map[string]migration.Instance) {
+	t.Helper()
+
+	src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+		return &mock.SourceEndpointMock{
+			ConnectFunc: func(ctx context.Context) error { return nil },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}}
+
+	_, err := d.source.Create(d.ShutdownCtx, src)
+	require.NoError(t, err)
+
+	instances := map[string]migration.Instance{}
+	for _, name := range []string{"vm1", "vm2", "vm3"} {
+		inst := migration.Instance{
+			UUID:                 uuid.New(),
+			Source:               src.Name,
+			SourceType:           src.SourceType,
+			LastUpdateFromSource: time.Now(),
+			Properties: api.InstanceProperties{
+				InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: name},
+				Location:                       "/dc/vm/" + name,
+				SourceSpecificID:               "VirtualMachine:" + name,
+			},
+		}
+
+		_, err = d.instance.Create(t.Context(), inst)
+		require.NoError(t, err)
+
+		instances[name] = inst
+	}
+
+	return src, instances
+}
+
+func TestResolveSyncSourceIDs(t *testing.T) {
+	d := daemonSetup(t)
+	src, instances := setupSyncSource(t, d)
+
+	cases := []struct {
+		name string
+		req  api.SourceSyncPost
+
+		wantIDs   []string
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "source-specific IDs, VM IDs and inventory paths",
+			req:  api.SourceSyncPost{SourceSpecificIDs: []string{"VirtualMachine:vm1", "vm2", "/dc/vm/vm1"}},
+
+			wantIDs:   []string{"VirtualMachine:vm1", "VirtualMachine:vm2"},
+			assertErr: require.NoError,
+		},
+		{
+			name: "unrecorded ID",
+			req:  api.SourceSyncPost{SourceSpecificIDs: []string{"vm-42"}},
+
+			wantIDs:   []string{"vm-42"},
+			assertErr: require.NoError,
+		},
+		{
+			name: "instance UUIDs and include expression",
+			req:  api.SourceSyncPost{Instances: []uuid.UUID{instances["vm3"].UUID}, IncludeExpression: `name == "vm2"`},
+
+			wantIDs:   []string{"VirtualMachine:vm3", "VirtualMachine:vm2"},
+			assertErr: require.NoError,
+		},
+		{
+			name: "include expression location alias",
+			req:  api.SourceSyncPost{IncludeExpression: "/dc/vm/vm1"},
+
+			wantIDs:   []string{"VirtualMachine:vm1"},
+			assertErr: require.NoError,
+		},
+		{
+			name: "no matches",
+			req:  api.SourceSyncPost{IncludeExpression: `name == "vm4"`},
+
+			wantIDs:   []string{},
+			assertErr: require.NoError,
+		},
+		{
+			name: "error - unknown instance",
+			req:  api.SourceSyncPost{Instances: []uuid.UUID{uuid.New()}},
+
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		ids, err := d.resolveSyncSourceIDs(t.Context(), src.Name, tc.req)
+		tc.assertErr(t, err)
+		if err == nil {
+			require.Equal(t, tc.wantIDs, ids)
+		}
+	}
+}
+
+func TestSyncSourceData_partial(t *testing.T) {
+	d := daemonSetup(t)
+	src, instances := setupSyncSource(t, d)
+
+	// vm1 was updated on the source, vm2 was removed from the source, and vm3 was not fetched.
+	updated := instances["vm1"]
+	updated.Properties.CPUs = 4
+	instancesBySrc := map[string]map[uuid.UUID]migration.Instance{src.Name: {updated.UUID: updated}}
+	networksBySrc := map[string]map[string]migration.Network{src.Name: {}}
+	partialIDs := map[string][]string{src.Name: {"VirtualMachine:vm1", "VirtualMachine:vm2"}}
+
+	_, err := d.syncSourceData(t.Context(), instancesBySrc, networksBySrc, partialIDs)
+	require.NoError(t, err)
+
+	dbInstances, err := d.instance.GetAllBySource(t.Context(), src.Name)
+	require.NoError(t, err)
+
+	byName := map[string]migration.Instance{}
+	for _, inst := range dbInstances {
+		byName[inst.Properties.Name] = inst
+	}
+
+	require.Len(t, byName, 2)
+	require.Equal(t, int64(4), byName["vm1"].Properties.CPUs)
+	require.Contains(t, byName, "vm3")
+}
+
+func TestSyncSources_recordsSyncStatus(t *testing.T) {
+	cases := []struct {
+		name string
+		sync func(d *Daemon, src migration.Source) error
+
+		wantRecorded bool
+	}{
+		{
+			name: "periodic sync of all sources",
+			sync: func(d *Daemon, src migration.Source) error { return d.trySyncAllSources(t.Context()) },
+
+			wantRecorded: true,
+		},
+		{
+			name: "full sync of one source",
+			sync: func(d *Daemon, src migration.Source) error { return d.syncOneSource(t.Context(), src) },
+
+			wantRecorded: true,
+		},
+		{
+			name: "partial sync of one source",
+			sync: func(d *Daemon, src migration.Source) error {
+				return d.syncOneSource(t.Context(), src, "VirtualMachine:vm1")
+			},
+
+			wantRecorded: false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			src, _ := setupSyncSource(t, d)
+
+			origSource := source.NewVMSource
+			defer func() {
+				source.NewVMSource = origSource
+			}()
+
+			source.NewVMSource = func(s api.Source) (source.Source, error) {
+				return &source.SourceMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					GetAllVMsFunc: func(ctx context.Context, sourceSpecificIDs ...string) (migration.Instances, migration.Networks, migration.Warnings, error) {
+						return nil, nil, nil, boom.Error
+					},
+				}, nil
+			}
+
+			// Hold a mark like a background sync would, which the sync must not clear.
+			d.beginSourceSync(src.Name)
+
+			syncStart := time.Now().UTC()
+			_ = tc.sync(d, src)
+
+			require.True(t, d.isSourceSyncing(src.Name))
+			d.endSourceSync(src.Name)
+			require.False(t, d.isSourceSyncing(src.Name))
+
+			dbSrc, err := d.source.GetByName(t.Context(), src.Name)
+			require.NoError(t, err)
+			status := dbSrc.SyncStatus
+			require.Equal(t, tc.wantRecorded, !status.LastSync.IsZero())
+			if tc.wantRecorded {
+				require.False(t, status.LastSync.Before(syncStart))
+				require.Contains(t, status.LastSyncError, boom.Error.Error())
+			}
+		})
+	}
+}
@@ -107,7 +107,8 @@ func systemBackupPost(d *Daemon, r *http.Request) response.Response {
 		includeMap[artUUID] = true
 	}
 
-	exclude := []string{}
+	// Instance secrets are never included in backups.
+	exclude := []string{filepath.Base(d.os.SecretDir)}
 	for _, a := range artifacts {
 		if !includeMap[a.UUID] {
 			exclude = append(exclude, filepath.Join(filepath.Base(d.os.ArtifactDir), a.UUID.String()))
@@ -157,7 +158,7 @@ func systemRestorePost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	for _, q := range queue {
-		if q.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && q.MigrationStatus != api.MIGRATIONSTATUS_ERROR && q.MigrationStatus != api.MIGRATIONSTATUS_CANCELED {
+		if q.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && q.MigrationStatus != api.MIGRATIONSTATUS_ERROR && q.MigrationStatus != api.MIGRATIONSTATUS_CANCELED && q.MigrationStatus != api.MIGRATIONSTATUS_ROLLED_BACK && q.MigrationStatus != api.MIGRATIONSTATUS_VALIDATION_FAILED {
 			return response.SmartError(fmt.Errorf("Unable to perform backup restore, queue entries are still migrating"))
 		}
 	}
@@ -363,7 +364,7 @@ func systemNetworkPut(d *Daemon, r *http.Request) response.Response {
 
 	runningEntries := migration.QueueEntries{}
 	for _, e := range entries {
-		if e.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && e.MigrationStatus != api.MIGRATIONSTATUS_ERROR {
+		if e.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && e.MigrationStatus != api.MIGRATIONSTATUS_ERROR && e.MigrationStatus != api.MIGRATIONSTATUS_VALIDATION_FAILED {
 			runningEntries = append(runningEntries, e)
 		}
 	}
@@ -469,7 +470,7 @@ func systemSecurityPut(d *Daemon, r *http.Request) response.Response {
 
 	runningEntries := migration.QueueEntries{}
 	for _, e := range entries {
-		if e.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && e.MigrationStatus != api.MIGRATIONSTATUS_ERROR {
+		if e.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && e.MigrationStatus != api.MIGRATIONSTATUS_ERROR && e.MigrationStatus != api.MIGRATIONSTATUS_VALIDATION_FAILED {
 			runningEntries = append(runningEntries, e)
 		}
 	}
@@ -534,7 +535,7 @@ func systemCertificateUpdate(d *Daemon, r *http.Request) response.Response {
 
 	runningEntries := migration.QueueEntries{}
 	for _, e := range entries {
-		if e.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && e.MigrationStatus != api.MIGRATIONSTATUS_ERROR {
+		if e.MigrationStatus != api.MIGRATIONSTATUS_FINISHED && e.MigrationStatus != api.MIGRATIONSTATUS_ERROR && e.MigrationStatus != api.MIGRATIONSTATUS_VALIDATION_FAILED {
 			runningEntries = append(runningEntries, e)
 		}
 	}
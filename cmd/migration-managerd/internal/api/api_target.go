@@ -27,16 +27,16 @@ import (
 var targetsCmd = APIEndpoint{
 	Path: "targets",
 
-	Get:  APIEndpointAction{Handler: targetsGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
+	Get:  APIEndpointAction{Handler: targetsGet, AccessHandler: allowAuthenticated},
 	Post: APIEndpointAction{Handler: targetsPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanCreate)},
 }
 
 var targetCmd = APIEndpoint{
 	Path: "targets/{name}",
 
-	Delete: APIEndpointAction{Handler: targetDelete, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanDelete)},
-	Get:    APIEndpointAction{Handler: targetGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView)},
-	Put:    APIEndpointAction{Handler: targetPut, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: targetDelete, AccessHandler: allowPermission(auth.ObjectTypeTarget, auth.EntitlementCanDelete, "name")},
+	Get:    APIEndpointAction{Handler: targetGet, AccessHandler: allowPermission(auth.ObjectTypeTarget, auth.EntitlementCanView, "name")},
+	Put:    APIEndpointAction{Handler: targetPut, AccessHandler: allowPermission(auth.ObjectTypeTarget, auth.EntitlementCanEdit, "name")},
 }
 
 // swagger:operation GET /1.0/targets targets targets_get
@@ -126,6 +126,11 @@ func targetsGet(d *Daemon, r *http.Request) response.Response {
 		recursion = 0
 	}
 
+	canView, err := d.Authorizer().GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, auth.ObjectTypeTarget)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if recursion == 1 {
 		targets, err := d.target.GetAll(r.Context())
 		if err != nil {
@@ -134,6 +139,10 @@ func targetsGet(d *Daemon, r *http.Request) response.Response {
 
 		result := make([]api.Target, 0, len(targets))
 		for _, tgt := range targets {
+			if !canView(auth.ObjectTarget(tgt.Name)) {
+				continue
+			}
+
 			result = append(result, tgt.ToAPI())
 		}
 
@@ -147,6 +156,10 @@ func targetsGet(d *Daemon, r *http.Request) response.Response {
 
 	result := make([]string, 0, len(targetNames))
 	for _, name := range targetNames {
+		if !canView(auth.ObjectTarget(name)) {
+			continue
+		}
+
 		result = append(result, fmt.Sprintf("/%s/targets/%s", api.APIVersion, name))
 	}
 
@@ -4,17 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"path"
 	"slices"
 	"strings"
 
 	"github.com/google/uuid"
 	incusAPI "github.com/lxc/incus/v6/shared/api"
 
+	"github.com/FuturFusion/migration-manager/internal/logger"
 	"github.com/FuturFusion/migration-manager/internal/migration"
 	"github.com/FuturFusion/migration-manager/internal/server/auth"
 	"github.com/FuturFusion/migration-manager/internal/server/response"
 	"github.com/FuturFusion/migration-manager/internal/source"
+	"github.com/FuturFusion/migration-manager/internal/target"
 	"github.com/FuturFusion/migration-manager/internal/transaction"
 	"github.com/FuturFusion/migration-manager/shared/api"
 	"github.com/FuturFusion/migration-manager/shared/api/event"
@@ -32,6 +36,13 @@ var workerCommandCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: workerCommandPost, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanEdit), Authenticator: TokenAuthenticate},
 }
 
+var workerDiskCmd = APIEndpoint{
+	Path: "worker/{uuid}/disk",
+
+	Get:  APIEndpointAction{Handler: workerDiskGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView), Authenticator: TokenAuthenticate},
+	Head: APIEndpointAction{Handler: workerDiskGet, AccessHandler: allowPermission(auth.ObjectTypeServer, auth.EntitlementCanView), Authenticator: TokenAuthenticate},
+}
+
 func instanceUUIDFromRequestURL(r *http.Request) (uuid.UUID, error) {
 	// Only allow GET and POST methods.
 	if r.Method != http.MethodPost {
@@ -135,6 +146,15 @@ func workerCommandPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Only hand out BitLocker key material to workers that need to unlock the Windows partition.
+	var bitLockerKey *api.BitLockerKey
+	if workerCommand.OSType == api.OSTYPE_WINDOWS && workerCommand.Command != api.WORKERCOMMAND_IDLE {
+		bitLockerKey, err = d.os.LoadBitLockerKey(instanceUUID)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	getLifecycleData := func(action api.LifecycleAction) (*api.EventLifecycle, error) {
 		var eventResp api.EventLifecycle
 		err := transaction.Do(r.Context(), func(ctx context.Context) error {
@@ -187,14 +207,18 @@ func workerCommandPost(d *Daemon, r *http.Request) response.Response {
 
 	d.queueHandler.RecordWorkerUpdate(instanceUUID)
 	return response.SyncResponseETag(true, api.WorkerCommand{
-		Command:             workerCommand.Command,
-		Location:            workerCommand.Location,
-		SourceType:          workerCommand.SourceType,
-		Source:              apiSourceJSON,
-		Distribution:        workerCommand.Distro,
-		DistributionVersion: workerCommand.DistroVersion,
-		OSType:              workerCommand.OSType,
-		Architecture:        workerCommand.Architecture,
+		Command:              workerCommand.Command,
+		Location:             workerCommand.Location,
+		SourceType:           workerCommand.SourceType,
+		Source:               apiSourceJSON,
+		Distribution:         workerCommand.Distro,
+		DistributionVersion:  workerCommand.DistroVersion,
+		OSType:               workerCommand.OSType,
+		Architecture:         workerCommand.Architecture,
+		BitLocker:            bitLockerKey,
+		PostMigrationScripts: workerCommand.PostMigrationScripts,
+		ChangeIDs:            workerCommand.ChangeIDs,
+		DiskVerification:     workerCommand.DiskVerification,
 	}, workerCommand)
 }
 
@@ -221,6 +245,18 @@ func workerUpdatePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Record the target volumes the data was copied to, so that the change IDs are only kept while those volumes exist.
+	if len(resp.ChangeIDs) > 0 {
+		volumes, err := d.targetVolumeUUIDs(r.Context(), instanceUUID)
+		if err != nil {
+			slog.Warn("Failed to get target volumes of copied disks", slog.String("instance", instanceUUID.String()), logger.Err(err))
+		}
+
+		for i, c := range resp.ChangeIDs {
+			resp.ChangeIDs[i].TargetVolume = volumes[c.TargetDevice]
+		}
+	}
+
 	updatedEntry, err := d.queue.ProcessWorkerUpdate(r.Context(), instanceUUID, resp)
 	if err != nil {
 		return response.SmartError(err)
@@ -313,3 +349,114 @@ func workerUpdatePost(d *Daemon, r *http.Request) response.Response {
 	d.queueHandler.RecordWorkerUpdate(instanceUUID)
 	return response.SyncResponse(true, nil)
 }
+
+// workerDiskGet serves a disk image of an instance from a file-based source to its worker.
+// Range requests are supported, so the worker can convert the image without first downloading it.
+func workerDiskGet(d *Daemon, r *http.Request) response.Response {
+	instanceUUID, err := uuid.Parse(r.PathValue("uuid"))
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Workers are only authenticated for their own instance.
+	instKey := r.URL.Query().Get("instance")
+	if instKey != "" && instKey != instanceUUID.String() {
+		return response.Forbidden(fmt.Errorf("Instance %q does not match requested instance %q", instKey, instanceUUID))
+	}
+
+	diskName := r.URL.Query().Get("name")
+	if diskName == "" {
+		return response.BadRequest(fmt.Errorf("Missing required 'name' query parameter"))
+	}
+
+	var src *migration.Source
+	err = transaction.Do(r.Context(), func(ctx context.Context) error {
+		inst, err := d.instance.GetByUUID(ctx, instanceUUID)
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(inst.Properties.Disks, func(disk api.InstancePropertiesDisk) bool { return disk.Name == diskName && disk.Supported }) {
+			return migration.ErrNotFound
+		}
+
+		src, err = d.source.GetByName(ctx, inst.Source)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	props, err := src.GetOVFProperties()
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	disk, err := source.OpenOVFDisk(*props, diskName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.FileResponse(r, []response.FileResponseEntry{{
+		Filename:     path.Base(diskName),
+		File:         disk,
+		FileSize:     disk.Size,
+		FileModified: disk.ModTime,
+		Cleanup:      func() { _ = disk.Close() },
+	}}, nil)
+}
+
+// targetVolumeUUIDs returns the UUIDs of the storage volumes of the target instance of the queued instance, by device name.
+func (d *Daemon) targetVolumeUUIDs(ctx context.Context, instanceUUID uuid.UUID) (map[string]string, error) {
+	var instName string
+	var placement api.Placement
+	var t *migration.Target
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		inst, err := d.instance.GetByUUID(ctx, instanceUUID)
+		if err != nil {
+			return err
+		}
+
+		q, err := d.queue.GetByInstanceUUID(ctx, instanceUUID)
+		if err != nil {
+			return err
+		}
+
+		t, err = d.target.GetByName(ctx, q.Placement.TargetName)
+		if err != nil {
+			return err
+		}
+
+		instName = inst.GetName()
+		placement = q.Placement
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := target.NewTarget(t.ToAPI())
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, it.Timeout())
+	defer cancel()
+
+	err = it.Connect(timeoutCtx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to target %q: %w", it.GetName(), err)
+	}
+
+	err = it.SetProject(placement.TargetProject)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to set project %q for target %q: %w", placement.TargetProject, it.GetName(), err)
+	}
+
+	return it.GetVolumeUUIDs(timeoutCtx, instName)
+}
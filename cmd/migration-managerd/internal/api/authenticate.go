@@ -12,6 +12,9 @@ import (
 	tlsutil "github.com/FuturFusion/migration-manager/internal/server/util"
 )
 
+// metricsProtocol is the protocol reported for clients authenticated with a dedicated metrics certificate.
+const metricsProtocol = "metrics"
+
 type authenticatorResponse struct {
 	username string
 	protocol string
@@ -36,6 +39,10 @@ func tlsAuthResponse(username string) *authenticatorResponse {
 	return &authenticatorResponse{username: username, protocol: incusAPI.AuthenticationMethodTLS}
 }
 
+func metricsAuthResponse(username string) *authenticatorResponse {
+	return &authenticatorResponse{username: username, protocol: metricsProtocol}
+}
+
 // TokenAuthenticate attempts normal authentication, and falls back to token-based authentication.
 // If using token-based authentication, the request will be assumed to be coming from the migration worker.
 func TokenAuthenticate(d *Daemon, w http.ResponseWriter, r *http.Request) (*authenticatorResponse, error) {
@@ -54,6 +61,29 @@ func TokenAuthenticate(d *Daemon, w http.ResponseWriter, r *http.Request) (*auth
 	return workerAuthResponse(), nil
 }
 
+// MetricsAuthenticate attempts normal authentication, and falls back to the trusted metrics certificates.
+// If authenticated by a metrics certificate, the request will only be authorized for the metrics endpoint.
+func MetricsAuthenticate(d *Daemon, w http.ResponseWriter, r *http.Request) (*authenticatorResponse, error) {
+	resp, err := DefaultAuthenticate(d, w, r)
+	if err == nil {
+		return resp, nil
+	}
+
+	if r.TLS == nil {
+		return nil, err
+	}
+
+	trustedFingerprints := d.TrustedMetricsFingerprints()
+	for _, cert := range r.TLS.PeerCertificates {
+		trusted, username := tlsutil.CheckTrustState(*cert, trustedFingerprints)
+		if trusted {
+			return metricsAuthResponse(username), nil
+		}
+	}
+
+	return nil, err
+}
+
 // DefaultAuthenticate validates an incoming http Request
 // It will check over what protocol it came, what type of request it is and
 // will validate the TLS certificate.
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/server/auth"
+	"github.com/FuturFusion/migration-manager/internal/transaction"
+)
+
+// authorizationObject returns the authorization object of the given type, identified by the given path values of the request, along with its parents.
+func (d *Daemon) authorizationObject(r *http.Request, objectType auth.ObjectType, pathValues ...string) (auth.Object, []auth.Object, error) {
+	if objectType == auth.ObjectTypeServer {
+		return auth.ObjectServer(), nil, nil
+	}
+
+	elements := make([]string, 0, len(pathValues))
+	for _, pathValue := range pathValues {
+		elements = append(elements, r.PathValue(pathValue))
+	}
+
+	object, err := auth.NewObject(objectType, elements...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Parents are only considered by fine-grained authorization, so skip looking them up otherwise.
+	if d.Authorizer().Driver() == auth.DriverTLS {
+		return object, nil, nil
+	}
+
+	var parents []auth.Object
+	err = transaction.Do(r.Context(), func(ctx context.Context) error {
+		switch objectType {
+		case auth.ObjectTypeBatch:
+			batch, err := d.batch.GetByName(ctx, elements[0])
+			if err != nil {
+				return err
+			}
+
+			parents = batchAuthParents(*batch)
+		case auth.ObjectTypeInstance:
+			instanceUUID, err := uuid.Parse(elements[0])
+			if err != nil {
+				// Let the handler report the invalid UUID.
+				return nil
+			}
+
+			instance, err := d.instance.GetByUUID(ctx, instanceUUID)
+			if err != nil {
+				return err
+			}
+
+			q, err := d.queue.GetByInstanceUUID(ctx, instanceUUID)
+			if err != nil && !errors.Is(err, migration.ErrNotFound) {
+				return err
+			}
+
+			parents = instanceAuthParents(*instance, q)
+		}
+
+		return nil
+	})
+	// Objects that don't exist have no parents, the handler will report them as not found.
+	if err != nil && !errors.Is(err, migration.ErrNotFound) {
+		return "", nil, err
+	}
+
+	return object, parents, nil
+}
+
+// batchAuthParents returns the authorization objects a batch inherits entitlements from.
+func batchAuthParents(batch migration.Batch) []auth.Object {
+	placement := batch.Defaults.Placement
+	if placement.Target == "" {
+		return nil
+	}
+
+	parents := []auth.Object{auth.ObjectTarget(placement.Target)}
+	if placement.TargetProject != "" {
+		parents = append(parents, auth.ObjectProject(placement.Target, placement.TargetProject))
+	}
+
+	return parents
+}
+
+// batchPlacementAuthParents returns the sets of authorization objects for every target and project a batch can place instances on.
+// This includes the default placement, and the targets and projects of the migration networks.
+func batchPlacementAuthParents(batch migration.Batch) [][]auth.Object {
+	parentSets := [][]auth.Object{batchAuthParents(batch)}
+	for _, n := range batch.Defaults.MigrationNetwork {
+		if n.Target == "" {
+			continue
+		}
+
+		parents := []auth.Object{auth.ObjectTarget(n.Target)}
+		if n.TargetProject != "" {
+			parents = append(parents, auth.ObjectProject(n.Target, n.TargetProject))
+		}
+
+		parentSets = append(parentSets, parents)
+	}
+
+	return parentSets
+}
+
+// checkBatchPlacementPermission checks the entitlement on the named batch against every target and project the given batch places instances on,
+// so that a client can't move a batch onto targets or projects it has no access to. This includes the placements returned by the placement scriptlet.
+// It also requires the can_edit entitlement on every instance the batch selects, so that a client can't migrate instances it has no access to.
+func (d *Daemon) checkBatchPlacementPermission(ctx context.Context, r *http.Request, name string, batch migration.Batch, entitlement auth.Entitlement) error {
+	authorizer := d.Authorizer()
+	parentSets := batchPlacementAuthParents(batch)
+
+	// The TLS driver grants access to all objects or none, so only fine-grained authorization needs to look at the selected instances.
+	if authorizer.Driver() != auth.DriverTLS {
+		var instances migration.Instances
+		var networks migration.Networks
+		var windows migration.Windows
+		err := transaction.Do(ctx, func(ctx context.Context) error {
+			var err error
+			instances, err = d.instance.GetAll(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed to get all instances: %w", err)
+			}
+
+			networks, err = d.network.GetAll(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed to get all networks: %w", err)
+			}
+
+			windows, err = d.window.GetAllByBatch(ctx, name)
+			if err != nil {
+				return fmt.Errorf("Failed to get migration windows for batch %q: %w", name, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		canEdit, err := authorizer.GetPermissionChecker(ctx, r, auth.EntitlementCanEdit, auth.ObjectTypeInstance)
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range instances {
+			isMatch, err := inst.MatchesCriteria(batch.IncludeExpression, false)
+			if err != nil {
+				return err
+			}
+
+			if !isMatch {
+				continue
+			}
+
+			if !canEdit(auth.ObjectInstance(inst.UUID.String()), auth.ObjectSource(inst.Source)) {
+				return incusAPI.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on instance %q selected by batch %q", auth.EntitlementCanEdit, inst.Properties.Location, name)
+			}
+
+			placement, err := d.batch.DeterminePlacement(ctx, inst, migration.FilterUsedNetworks(networks, migration.Instances{inst}), batch, windows)
+			if err != nil {
+				return fmt.Errorf("Failed to determine placement of instance %q: %w", inst.Properties.Location, err)
+			}
+
+			parents := []auth.Object{auth.ObjectTarget(placement.TargetName)}
+			if placement.TargetProject != "" {
+				parents = append(parents, auth.ObjectProject(placement.TargetName, placement.TargetProject))
+			}
+
+			if !slices.ContainsFunc(parentSets, func(p []auth.Object) bool { return slices.Equal(p, parents) }) {
+				parentSets = append(parentSets, parents)
+			}
+		}
+	}
+
+	for _, parents := range parentSets {
+		err := authorizer.CheckPermission(ctx, r, auth.ObjectBatch(name), entitlement, parents...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instanceAuthParents returns the authorization objects an instance inherits entitlements from.
+// If the instance is queued for migration, this includes the target and project it is placed on.
+// The batch is deliberately not a parent, so that access to a batch doesn't grant access to the instances it selects.
+func instanceAuthParents(instance migration.Instance, q *migration.QueueEntry) []auth.Object {
+	parents := []auth.Object{auth.ObjectSource(instance.Source)}
+	if q == nil || q.Placement.TargetName == "" {
+		return parents
+	}
+
+	parents = append(parents, auth.ObjectTarget(q.Placement.TargetName))
+	if q.Placement.TargetProject != "" {
+		parents = append(parents, auth.ObjectProject(q.Placement.TargetName, q.Placement.TargetProject))
+	}
+
+	return parents
+}
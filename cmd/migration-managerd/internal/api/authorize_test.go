@@ -0,0 +1,364 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/migration/endpoint/mock"
+	"github.com/FuturFusion/migration-manager/internal/server/auth"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// objectAuthorizer is a fine-grained authorizer for tests, granting all entitlements on the configured objects and the objects inheriting from them.
+type objectAuthorizer struct {
+	allowed []auth.Object
+}
+
+func (a *objectAuthorizer) Driver() string                        { return auth.DriverOpenFGA }
+func (a *objectAuthorizer) StopService(ctx context.Context) error { return nil }
+func (a *objectAuthorizer) SetLogger(log *slog.Logger)            {}
+
+func (a *objectAuthorizer) allows(object auth.Object, parents ...auth.Object) bool {
+	for _, allowed := range a.allowed {
+		if object == allowed {
+			return true
+		}
+
+		for _, parent := range parents {
+			if parent == allowed {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *objectAuthorizer) CheckPermission(ctx context.Context, r *http.Request, object auth.Object, entitlement auth.Entitlement, parents ...auth.Object) error {
+	if !a.allows(object, parents...) {
+		return incusAPI.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+	}
+
+	return nil
+}
+
+func (a *objectAuthorizer) GetPermissionChecker(ctx context.Context, r *http.Request, entitlement auth.Entitlement, objectType auth.ObjectType) (auth.PermissionChecker, error) {
+	return a.allows, nil
+}
+
+func TestAuthorization_objects(t *testing.T) {
+	instA := uuid.New()
+	instB := uuid.New()
+
+	cases := []struct {
+		name    string
+		allowed []auth.Object
+
+		wantBatches     []string
+		wantSources     []string
+		wantInstances   []string
+		wantQueue       []string
+		wantB1Status    int
+		wantInstBStatus int
+	}{
+		{
+			name:            "no entitlements",
+			wantBatches:     []string{},
+			wantSources:     []string{},
+			wantInstances:   []string{},
+			wantQueue:       []string{},
+			wantB1Status:    http.StatusForbidden,
+			wantInstBStatus: http.StatusForbidden,
+		},
+		{
+			name:            "batch inherits from target project",
+			allowed:         []auth.Object{auth.ObjectProject("tgt1", "p1")},
+			wantBatches:     []string{"/1.0/batches/b1"},
+			wantSources:     []string{},
+			wantInstances:   []string{"/1.0/instances/" + instA.String()},
+			wantQueue:       []string{"/1.0/queue/" + instA.String()},
+			wantB1Status:    http.StatusOK,
+			wantInstBStatus: http.StatusForbidden,
+		},
+		{
+			name:            "instance inherits from source",
+			allowed:         []auth.Object{auth.ObjectSource("src2")},
+			wantBatches:     []string{},
+			wantSources:     []string{"/1.0/sources/src2"},
+			wantInstances:   []string{"/1.0/instances/" + instB.String()},
+			wantQueue:       []string{},
+			wantB1Status:    http.StatusForbidden,
+			wantInstBStatus: http.StatusOK,
+		},
+		{
+			name:            "all targets and sources",
+			allowed:         []auth.Object{auth.ObjectTarget("tgt1"), auth.ObjectTarget("tgt2"), auth.ObjectSource("src1"), auth.ObjectSource("src2")},
+			wantBatches:     []string{"/1.0/batches/b1", "/1.0/batches/b2"},
+			wantSources:     []string{"/1.0/sources/src1", "/1.0/sources/src2"},
+			wantInstances:   []string{"/1.0/instances/" + instA.String(), "/1.0/instances/" + instB.String()},
+			wantQueue:       []string{"/1.0/queue/" + instA.String()},
+			wantB1Status:    http.StatusOK,
+			wantInstBStatus: http.StatusOK,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{batchesCmd, batchCmd, sourcesCmd, instancesCmd, instanceCmd, queueRootCmd}, nil)
+
+			for _, b := range []struct{ name, target, project string }{{"b1", "tgt1", "p1"}, {"b2", "tgt2", "default"}} {
+				_, err := d.batch.Create(d.ShutdownCtx, migration.Batch{
+					Name:              b.name,
+					Defaults:          api.BatchDefaults{Placement: api.BatchPlacement{Target: b.target, TargetProject: b.project, StoragePool: "default"}},
+					Status:            api.BATCHSTATUS_DEFINED,
+					IncludeExpression: "true",
+					Config: api.BatchConfig{
+						BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+						FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					},
+				})
+				require.NoError(t, err)
+			}
+
+			for _, name := range []string{"src1", "src2"} {
+				_, err := d.source.Create(d.ShutdownCtx, migration.Source{Name: name, SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+					return &mock.SourceEndpointMock{
+						ConnectFunc: func(ctx context.Context) error { return nil },
+						DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+							return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+						},
+					}, nil
+				}})
+				require.NoError(t, err)
+			}
+
+			for id, src := range map[uuid.UUID]string{instA: "src1", instB: "src2"} {
+				_, err := d.instance.Create(t.Context(), migration.Instance{
+					UUID:                 id,
+					Source:               src,
+					SourceType:           api.SOURCETYPE_VMWARE,
+					LastUpdateFromSource: time.Now(),
+					Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: src + "-vm"}, Location: src + "-vm"},
+				})
+				require.NoError(t, err)
+			}
+
+			_, err := d.queue.CreateEntry(t.Context(), migration.QueueEntry{
+				InstanceUUID:    instA,
+				BatchName:       "b1",
+				MigrationStatus: api.MIGRATIONSTATUS_WAITING,
+				SecretToken:     uuid.New(),
+				ImportStage:     migration.IMPORTSTAGE_BACKGROUND,
+				Placement:       api.Placement{TargetName: "tgt1", TargetProject: "p1", StoragePools: map[string]string{"root": "default"}, Networks: map[string]api.NetworkPlacement{}},
+			})
+			require.NoError(t, err)
+
+			d.authorizer = &objectAuthorizer{allowed: tc.allowed}
+
+			for path, want := range map[string][]string{"/1.0/batches": tc.wantBatches, "/1.0/sources": tc.wantSources, "/1.0/instances": tc.wantInstances, "/1.0/queue": tc.wantQueue} {
+				statusCode, body := probeAPI(t, client, http.MethodGet, srvURL+path, nil, nil)
+				require.Equal(t, http.StatusOK, statusCode, body)
+
+				var resp struct {
+					Metadata []string `json:"metadata"`
+				}
+
+				require.NoError(t, json.Unmarshal([]byte(body), &resp))
+				require.ElementsMatch(t, want, resp.Metadata, path)
+			}
+
+			statusCode, body := probeAPI(t, client, http.MethodGet, srvURL+"/1.0/batches/b1", nil, nil)
+			require.Equal(t, tc.wantB1Status, statusCode, body)
+
+			statusCode, body = probeAPI(t, client, http.MethodGet, srvURL+"/1.0/instances/"+instB.String(), nil, nil)
+			require.Equal(t, tc.wantInstBStatus, statusCode, body)
+		})
+	}
+}
+
+func TestAuthorization_batchPlacement(t *testing.T) {
+	toTgt2 := `
+def placement(instance, batch):
+    set_target("tgt2")
+`
+
+	cases := []struct {
+		name               string
+		method             string
+		path               string
+		target             string
+		targetProject      string
+		placementScriptlet string
+		allowed            []auth.Object
+
+		wantHTTPStatus int
+	}{
+		{
+			name:           "success - create batch in allowed project",
+			method:         http.MethodPost,
+			path:           "/1.0/batches",
+			target:         "tgt1",
+			targetProject:  "p1",
+			wantHTTPStatus: http.StatusCreated,
+		},
+		{
+			name:           "success - edit batch within allowed project",
+			method:         http.MethodPut,
+			path:           "/1.0/batches/b1",
+			target:         "tgt1",
+			targetProject:  "p1",
+			wantHTTPStatus: http.StatusCreated,
+		},
+		{
+			name:           "error - create batch in other project",
+			method:         http.MethodPost,
+			path:           "/1.0/batches",
+			target:         "tgt1",
+			targetProject:  "p2",
+			wantHTTPStatus: http.StatusForbidden,
+		},
+		{
+			name:           "error - move batch to other target",
+			method:         http.MethodPut,
+			path:           "/1.0/batches/b1",
+			target:         "tgt2",
+			targetProject:  "default",
+			wantHTTPStatus: http.StatusForbidden,
+		},
+		{
+			name:           "error - create batch selecting instances of other source",
+			method:         http.MethodPost,
+			path:           "/1.0/batches",
+			target:         "tgt1",
+			targetProject:  "p1",
+			allowed:        []auth.Object{auth.ObjectProject("tgt1", "p1")},
+			wantHTTPStatus: http.StatusForbidden,
+		},
+		{
+			name:           "error - edit batch selecting instances of other source",
+			method:         http.MethodPut,
+			path:           "/1.0/batches/b1",
+			target:         "tgt1",
+			targetProject:  "p1",
+			allowed:        []auth.Object{auth.ObjectProject("tgt1", "p1")},
+			wantHTTPStatus: http.StatusForbidden,
+		},
+		{
+			name:               "error - create batch with placement scriptlet placing on other target",
+			method:             http.MethodPost,
+			path:               "/1.0/batches",
+			target:             "tgt1",
+			targetProject:      "p1",
+			placementScriptlet: toTgt2,
+			wantHTTPStatus:     http.StatusForbidden,
+		},
+		{
+			name:               "error - edit batch with placement scriptlet placing on other target",
+			method:             http.MethodPut,
+			path:               "/1.0/batches/b1",
+			target:             "tgt1",
+			targetProject:      "p1",
+			placementScriptlet: toTgt2,
+			wantHTTPStatus:     http.StatusForbidden,
+		},
+		{
+			name:               "success - create batch with placement scriptlet placing on allowed target",
+			method:             http.MethodPost,
+			path:               "/1.0/batches",
+			target:             "tgt1",
+			targetProject:      "p1",
+			placementScriptlet: toTgt2,
+			allowed:            []auth.Object{auth.ObjectProject("tgt1", "p1"), auth.ObjectTarget("tgt2"), auth.ObjectSource("src1")},
+			wantHTTPStatus:     http.StatusCreated,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			client, srvURL := startTestDaemon(t, d, []APIEndpoint{batchesCmd, batchCmd}, nil)
+
+			batchConfig := api.BatchConfig{
+				BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+				FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+			}
+
+			_, err := d.batch.Create(d.ShutdownCtx, migration.Batch{
+				Name:              "b1",
+				Defaults:          api.BatchDefaults{Placement: api.BatchPlacement{Target: "tgt1", TargetProject: "p1", StoragePool: "default"}},
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Config:            batchConfig,
+			})
+			require.NoError(t, err)
+
+			_, err = d.source.Create(d.ShutdownCtx, migration.Source{Name: "src1", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+				return &mock.SourceEndpointMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+						return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+					},
+				}, nil
+			}})
+			require.NoError(t, err)
+
+			_, err = d.instance.Create(t.Context(), migration.Instance{
+				UUID:                 uuid.New(),
+				Source:               "src1",
+				SourceType:           api.SOURCETYPE_VMWARE,
+				LastUpdateFromSource: time.Now(),
+				Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm1"}, Location: "/vm1"},
+			})
+			require.NoError(t, err)
+
+			allowed := tc.allowed
+			if allowed == nil {
+				allowed = []auth.Object{auth.ObjectProject("tgt1", "p1"), auth.ObjectSource("src1")}
+			}
+
+			d.authorizer = &objectAuthorizer{allowed: allowed}
+
+			name := "b1"
+			if tc.method == http.MethodPost {
+				name = "b2"
+			}
+
+			config := batchConfig
+			config.PlacementScriptlet = tc.placementScriptlet
+
+			b, err := json.Marshal(api.BatchPut{
+				Name:              name,
+				IncludeExpression: "true",
+				Defaults:          api.BatchDefaults{Placement: api.BatchPlacement{Target: tc.target, TargetProject: tc.targetProject, StoragePool: "default"}},
+				Config:            config,
+			})
+			require.NoError(t, err)
+
+			statusCode, resp := probeAPI(t, client, tc.method, srvURL+tc.path, bytes.NewBuffer(b), nil)
+			require.Equal(t, tc.wantHTTPStatus, statusCode, resp)
+
+			batch, err := d.batch.GetByName(t.Context(), "b1")
+			require.NoError(t, err)
+			if tc.method == http.MethodPut && tc.wantHTTPStatus != http.StatusCreated {
+				require.Equal(t, "tgt1", batch.Defaults.Placement.Target)
+				require.Empty(t, batch.Config.PlacementScriptlet)
+			}
+		})
+	}
+}
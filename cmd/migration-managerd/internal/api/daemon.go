@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -71,7 +72,19 @@ type Daemon struct {
 	server       *http.Server
 
 	batchLock util.IDLock[string]
-	syncCache *util.Cache[string, struct{}]
+
+	// syncCache counts the running syncs of each source, so overlapping full and partial syncs do not clear each other's marker.
+	syncCache *util.Cache[string, int]
+
+	// sourceSyncLock serializes the syncs of each source, so a sync never records data older than that of an earlier sync.
+	sourceSyncLock util.IDLock[string]
+
+	// sourceWatchers records the running VM change watchers, keyed by source name.
+	sourceWatchers map[string]sourceWatcher
+
+	// validations records the instances whose validation checks are running in the background.
+	validationLock sync.Mutex
+	validations    map[uuid.UUID]struct{}
 
 	ShutdownCtx    context.Context    // Canceled when shutdown starts.
 	ShutdownCancel context.CancelFunc // Cancels the shutdownCtx to indicate shutdown starting.
@@ -87,7 +100,10 @@ func NewDaemon(logHandler *logger.Handler) *Daemon {
 		os:             sys.DefaultOS(),
 		logHandler:     logHandler,
 		batchLock:      util.NewIDLock[string](),
-		syncCache:      util.NewCache[string, struct{}](),
+		syncCache:      util.NewCache[string, int](),
+		sourceSyncLock: util.NewIDLock[string](),
+		sourceWatchers: map[string]sourceWatcher{},
+		validations:    map[uuid.UUID]struct{}{},
 		ShutdownCtx:    shutdownCtx,
 		ShutdownCancel: shutdownCancel,
 		ShutdownDoneCh: make(chan error),
@@ -96,16 +112,18 @@ func NewDaemon(logHandler *logger.Handler) *Daemon {
 	return d
 }
 
-// allowPermission is a wrapper to check access against a given object. Currently server is the only supported object.
-func allowPermission(objectType auth.ObjectType, entitlement auth.Entitlement) func(d *Daemon, r *http.Request) response.Response { // nolint:unparam
+// allowPermission is a wrapper to check access against a given object.
+// Objects other than the server are identified by the given path values of the request.
+func allowPermission(objectType auth.ObjectType, entitlement auth.Entitlement, pathValues ...string) func(d *Daemon, r *http.Request) response.Response {
 	return func(d *Daemon, r *http.Request) response.Response {
-		if objectType != auth.ObjectTypeServer {
-			return response.InternalError(fmt.Errorf("Unsupported object: %s", objectType))
+		object, parents, err := d.authorizationObject(r, objectType, pathValues...)
+		if err != nil {
+			return response.InternalError(fmt.Errorf("Failed to get authorization object: %w", err))
 		}
 
 		// Validate whether the user has the needed permission
 		authorizer := d.Authorizer()
-		err := authorizer.CheckPermission(r.Context(), r, auth.ObjectServer(), entitlement)
+		err = authorizer.CheckPermission(r.Context(), r, object, entitlement, parents...)
 		if err != nil {
 			return response.SmartError(err)
 		}
@@ -114,6 +132,13 @@ func allowPermission(objectType auth.ObjectType, entitlement auth.Entitlement) f
 	}
 }
 
+// allowAuthenticated lets any authenticated client through.
+// It is used by list endpoints, which filter the returned objects according to the permissions of the client,
+// and by endpoints that check permissions on objects that depend on the request body.
+func allowAuthenticated(d *Daemon, r *http.Request) response.Response {
+	return response.EmptySyncResponse
+}
+
 // cleanupCacheDir removes extraneous files from the Migration Manager cache directory.
 func (d *Daemon) cleanupCacheDir(ctx context.Context) error {
 	if d.queue != nil {
@@ -246,6 +271,11 @@ func (d *Daemon) Start() error {
 		return fmt.Errorf("Failed to sync active batches: %w", err)
 	}
 
+	err = d.pruneInstanceSecrets(d.ShutdownCtx)
+	if err != nil {
+		return err
+	}
+
 	// Setup web server
 	d.server = restServer(d)
 
@@ -309,7 +339,8 @@ func (d *Daemon) Start() error {
 		return d.renewServerCertificate(ctx, d.config.Security.ACME, false)
 	}, 24*time.Hour)
 
-	d.runPeriodicTask(d.ShutdownCtx, SyncTask, d.trySyncAllSources, time.Minute*10)
+	d.runPeriodicTask(d.ShutdownCtx, SyncTask, d.syncDueSources, 10*time.Second)
+	d.runPeriodicTask(d.ShutdownCtx, SourceWatchTask, d.reconcileSourceWatchers, time.Minute)
 
 	d.runPeriodicTask(d.ShutdownCtx, ImportTask, func(ctx context.Context) error {
 		// Cleanup of instances is set to false for testing. In practice we should set it to true, so that we can retry creating VMs in case it fails.
@@ -318,6 +349,7 @@ func (d *Daemon) Start() error {
 
 	d.runPeriodicTask(d.ShutdownCtx, PostImportTask, d.finalizeCompleteInstances, 10*time.Second)
 	d.runPeriodicTask(d.ShutdownCtx, CacheCleanupTask, d.cleanupCacheDir, 24*time.Hour)
+	d.runPeriodicTask(d.ShutdownCtx, WindowScheduleTask, d.expandWindowSchedules, time.Hour)
 
 	select {
 	case <-errgroupCtx.Done():
@@ -379,6 +411,13 @@ func (d *Daemon) TrustedFingerprints() []string {
 	return d.config.Security.TrustedTLSClientCertFingerprints
 }
 
+func (d *Daemon) TrustedMetricsFingerprints() []string {
+	d.configLock.Lock()
+	defer d.configLock.Unlock()
+
+	return d.config.Security.TrustedMetricsTLSClientCertFingerprints
+}
+
 func (d *Daemon) updateHTTPListener(address string) <-chan error {
 	ch := make(chan error, 1)
 	if address == "" {
@@ -922,6 +961,47 @@ func validateBackup(ctx context.Context, dir string) error {
 			return fmt.Errorf("Failed to update 'batches' table: %w", err)
 		}
 
+		// Instance secrets are not part of backups, so any redacted override must have its secret on this system.
+		rows, err := tx.QueryContext(ctx, `SELECT uuid, overrides FROM instances`)
+		if err != nil {
+			return fmt.Errorf("Failed to query 'instances' table: %w", err)
+		}
+
+		defer func() { _ = rows.Close() }()
+
+		for rows.Next() {
+			var instanceUUID uuid.UUID
+			var overrides string
+			err := rows.Scan(&instanceUUID, &overrides)
+			if err != nil {
+				return fmt.Errorf("Failed to scan 'instances' table: %w", err)
+			}
+
+			var override api.InstanceOverride
+			err = json.Unmarshal([]byte(overrides), &override)
+			if err != nil {
+				return fmt.Errorf("Failed to parse overrides of instance %q: %w", instanceUUID, err)
+			}
+
+			if override.BitLocker == nil {
+				continue
+			}
+
+			hasSecret, err := sys.HasInstanceSecret(filepath.Join(dir, "secrets"), instanceUUID)
+			if err != nil {
+				return err
+			}
+
+			if !hasSecret {
+				return fmt.Errorf("BitLocker key of instance %q is not available on this system", instanceUUID)
+			}
+		}
+
+		err = rows.Err()
+		if err != nil {
+			return fmt.Errorf("Failed to read 'instances' table: %w", err)
+		}
+
 		// Remove unincluded artifact records.
 		if len(existingUUIDs) == 0 {
 			_, err := tx.ExecContext(ctx, `DELETE FROM artifacts`)
@@ -955,6 +1035,16 @@ func validateBackup(ctx context.Context, dir string) error {
 	return nil
 }
 
+// pruneInstanceSecrets removes the stored secrets of instances that no longer exist.
+func (d *Daemon) pruneInstanceSecrets(ctx context.Context) error {
+	instanceUUIDs, err := d.instance.GetAllUUIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get instance records: %w", err)
+	}
+
+	return d.os.PruneInstanceSecrets(instanceUUIDs)
+}
+
 func (d *Daemon) restoreBackup(ctx context.Context) error {
 	backupTarball := filepath.Join(d.os.CacheDir, "backup.tar.gz")
 	_, err := os.Stat(backupTarball)
@@ -1004,6 +1094,20 @@ func (d *Daemon) restoreBackup(ctx context.Context) error {
 		}
 	})
 
+	// Instance secrets are not part of backups, so keep the existing ones across the restore.
+	secretDir := filepath.Join(d.os.CacheDir, "secrets.restore")
+	err = os.RemoveAll(secretDir)
+	if err != nil {
+		return fmt.Errorf("Failed to remove stale secrets directory %q: %w", secretDir, err)
+	}
+
+	err = os.Rename(d.os.SecretDir, secretDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to move instance secrets: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(secretDir) }()
+
 	err = os.RemoveAll(d.os.VarDir)
 	if err != nil {
 		return fmt.Errorf("failed to remove existing state files: %w", err)
@@ -1014,6 +1118,16 @@ func (d *Daemon) restoreBackup(ctx context.Context) error {
 		return fmt.Errorf("Failed to unpack backup tarball %q: %w", backupTarball, err)
 	}
 
+	err = os.RemoveAll(d.os.SecretDir)
+	if err != nil {
+		return fmt.Errorf("Failed to remove instance secrets from backup: %w", err)
+	}
+
+	err = os.Rename(secretDir, d.os.SecretDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to restore instance secrets: %w", err)
+	}
+
 	// Ensure the backup is valid.
 	err = validateBackup(ctx, d.os.VarDir)
 	if err != nil {
@@ -161,6 +161,7 @@ func TestMigration_beginImports(t *testing.T) {
 
 		ranCleanup           bool
 		resultMigrationState map[uuid.UUID]api.MigrationStatusType
+		resultBlockedCount   int
 		resultBatchState     api.BatchStatusType
 	}{
 		{
@@ -586,6 +587,106 @@ def placement(instance, batch):
 			resultBatchState:     api.BATCHSTATUS_RUNNING,
 			assertErr:            require.NoError,
 		},
+		{
+			name: "2 vms (1 disk, 1 nic), storage pool full -- both blocked",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{1: "10.0.0.10"}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{1: "10.0.0.11"}, api.OSTYPE_LINUX, false),
+			},
+
+			initialPlacements: map[uuid.UUID]api.Placement{
+				uuids["vm1"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm1_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+				uuids["vm2"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm2_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+			},
+
+			targetDetails: []target.IncusDetails{
+				{Name: "tgt", Projects: []string{"project1"}, StoragePools: []string{"pool1"}, NetworksByProject: netMap(setMap{"project1": {"net1"}}), InstancesByProject: setMap{"project1": {}}, StoragePoolFreeSpace: map[string]int64{"pool1": 512 * 1024 * 1024}},
+			},
+
+			hasVMwareSDK:    true,
+			hasWorker:       true,
+			hasWorkerVolume: false,
+			rerunScriptlet:  false,
+
+			resultMigrationState: map[uuid.UUID]api.MigrationStatusType{uuids["vm1"]: api.MIGRATIONSTATUS_BLOCKED, uuids["vm2"]: api.MIGRATIONSTATUS_BLOCKED},
+			resultBatchState:     api.BATCHSTATUS_RUNNING,
+			assertErr:            require.NoError,
+		},
+		{
+			name: "2 vms (1 disk, 1 nic), storage pool only fits one -- one blocked",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{1: "10.0.0.10"}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{1: "10.0.0.11"}, api.OSTYPE_LINUX, false),
+			},
+
+			initialPlacements: map[uuid.UUID]api.Placement{
+				uuids["vm1"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm1_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+				uuids["vm2"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm2_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+			},
+
+			targetDetails: []target.IncusDetails{
+				{Name: "tgt", Projects: []string{"project1"}, StoragePools: []string{"pool1"}, NetworksByProject: netMap(setMap{"project1": {"net1"}}), InstancesByProject: setMap{"project1": {}}, StoragePoolFreeSpace: map[string]int64{"pool1": 1536 * 1024 * 1024}},
+			},
+
+			hasVMwareSDK:    true,
+			hasWorker:       true,
+			hasWorkerVolume: false,
+			rerunScriptlet:  false,
+
+			resultBlockedCount: 1,
+			resultBatchState:   api.BATCHSTATUS_RUNNING,
+			assertErr:          require.NoError,
+		},
+		{
+			name: "2 vms (1 disk, 1 nic), project memory limit reached -- both blocked",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{1: "10.0.0.10"}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{1: "10.0.0.11"}, api.OSTYPE_LINUX, false),
+			},
+
+			initialPlacements: map[uuid.UUID]api.Placement{
+				uuids["vm1"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm1_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+				uuids["vm2"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm2_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+			},
+
+			targetDetails: []target.IncusDetails{
+				{Name: "tgt", Projects: []string{"project1"}, StoragePools: []string{"pool1"}, NetworksByProject: netMap(setMap{"project1": {"net1"}}), InstancesByProject: setMap{"project1": {}}, ProjectQuotas: map[string]target.ProjectQuota{"project1": {CPUs: -1, Memory: 512 * 1024 * 1024, Disk: -1}}},
+			},
+
+			hasVMwareSDK:    true,
+			hasWorker:       true,
+			hasWorkerVolume: false,
+			rerunScriptlet:  false,
+
+			resultMigrationState: map[uuid.UUID]api.MigrationStatusType{uuids["vm1"]: api.MIGRATIONSTATUS_BLOCKED, uuids["vm2"]: api.MIGRATIONSTATUS_BLOCKED},
+			resultBatchState:     api.BATCHSTATUS_RUNNING,
+			assertErr:            require.NoError,
+		},
+		{
+			name: "2 vms (1 disk, 1 nic), no member with enough memory -- both blocked",
+			instances: migration.Instances{
+				uuids.newTestInstance("vm1", map[int]bool{1: true}, map[int]string{1: "10.0.0.10"}, api.OSTYPE_LINUX, false),
+				uuids.newTestInstance("vm2", map[int]bool{1: true}, map[int]string{1: "10.0.0.11"}, api.OSTYPE_LINUX, false),
+			},
+
+			initialPlacements: map[uuid.UUID]api.Placement{
+				uuids["vm1"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm1_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+				uuids["vm2"]: {TargetName: "tgt", TargetProject: "project1", StoragePools: map[string]string{"vm2_disk_1": "pool1"}, Networks: map[string]api.NetworkPlacement{"00:00:00:00:00:01": {Network: "net1", NICType: api.INCUSNICTYPE_MANAGED}}},
+			},
+
+			targetDetails: []target.IncusDetails{
+				{Name: "tgt", Projects: []string{"project1"}, StoragePools: []string{"pool1"}, NetworksByProject: netMap(setMap{"project1": {"net1"}}), InstancesByProject: setMap{"project1": {}}, MemberResources: map[string]target.MemberResources{"member1": {CPUs: 4, FreeMemory: 512 * 1024 * 1024}, "member2": {CPUs: 4, FreeMemory: 256 * 1024 * 1024}}},
+			},
+
+			hasVMwareSDK:    true,
+			hasWorker:       true,
+			hasWorkerVolume: false,
+			rerunScriptlet:  false,
+
+			resultMigrationState: map[uuid.UUID]api.MigrationStatusType{uuids["vm1"]: api.MIGRATIONSTATUS_BLOCKED, uuids["vm2"]: api.MIGRATIONSTATUS_BLOCKED},
+			resultBatchState:     api.BATCHSTATUS_RUNNING,
+			assertErr:            require.NoError,
+		},
 		{
 			name: "failed instance start -- queue entry error",
 			instances: migration.Instances{
@@ -795,6 +896,19 @@ def placement(instance, batch):
 				}
 
 				require.Equal(t, tc.concurrentCreations, idleCount)
+			} else if tc.resultBlockedCount > 0 {
+				blockedCount := 0
+				qs, err := d.queue.GetAll(d.ShutdownCtx)
+				require.NoError(t, err)
+
+				for _, q := range qs {
+					if q.MigrationStatus == api.MIGRATIONSTATUS_BLOCKED {
+						t.Logf("Result message: %v", q.MigrationStatusMessage)
+						blockedCount++
+					}
+				}
+
+				require.Equal(t, tc.resultBlockedCount, blockedCount)
 			} else {
 				for instUUID, state := range tc.resultMigrationState {
 					q, err := d.queue.GetByInstanceUUID(d.ShutdownCtx, instUUID)
@@ -812,3 +926,138 @@ def placement(instance, batch):
 		})
 	}
 }
+
+func TestMigration_checkInstanceRename(t *testing.T) {
+	cases := []struct {
+		name          string
+		newName       string
+		targetNames   []string
+		targetNameErr error
+		otherProject  string
+
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:         "success - name unused",
+			newName:      "web-vm1",
+			targetNames:  []string{"db-vm1"},
+			otherProject: "default",
+
+			assertErr: require.NoError,
+		},
+		{
+			name:         "success - queued instance with the same name in another project",
+			newName:      "vm2",
+			otherProject: "other",
+
+			assertErr: require.NoError,
+		},
+		{
+			name:         "error - invalid name",
+			newName:      "web_vm1",
+			otherProject: "default",
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Name "web_vm1" is not a valid hostname`)
+			},
+		},
+		{
+			name:         "error - instance exists on target",
+			newName:      "web-vm1",
+			targetNames:  []string{"web-vm1"},
+			otherProject: "default",
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Instance already exists with name "web-vm1" on target "tgt" in project "default"`)
+			},
+		},
+		{
+			name:          "error - target instances unavailable",
+			newName:       "web-vm1",
+			targetNameErr: boom.Error,
+			otherProject:  "default",
+
+			assertErr: boom.ErrorIs,
+		},
+		{
+			name:         "error - queued instance with the same name in the same project",
+			newName:      "vm2",
+			otherProject: "default",
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Queued instance "/vm2" is also placed with name "vm2" on target "tgt" in project "default"`)
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			_, err := d.batch.Create(t.Context(), migration.Batch{
+				Name:              "b1",
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "default", TargetProject: "default", StoragePool: "default"},
+				},
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = d.source.Create(t.Context(), migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+				return &mock.SourceEndpointMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+						return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+					},
+				}, nil
+			}})
+			require.NoError(t, err)
+
+			instances := migration.Instances{}
+			for _, name := range []string{"vm1", "vm2"} {
+				inst, err := d.instance.Create(t.Context(), migration.Instance{
+					UUID:                 uuid.New(),
+					Source:               "src",
+					SourceType:           api.SOURCETYPE_VMWARE,
+					LastUpdateFromSource: time.Now(),
+					Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: name}, Location: "/" + name},
+				})
+				require.NoError(t, err)
+
+				instances = append(instances, inst)
+			}
+
+			projects := []string{"default", tc.otherProject}
+			entries := migration.QueueEntries{}
+			for j, inst := range instances {
+				q, err := d.queue.CreateEntry(t.Context(), migration.QueueEntry{
+					InstanceUUID:    inst.UUID,
+					BatchName:       "b1",
+					MigrationStatus: api.MIGRATIONSTATUS_IDLE,
+					SecretToken:     uuid.New(),
+					ImportStage:     migration.IMPORTSTAGE_BACKGROUND,
+					Placement:       api.Placement{TargetName: "tgt", TargetProject: projects[j], StoragePools: map[string]string{"root": "default"}, Networks: map[string]api.NetworkPlacement{}},
+				})
+				require.NoError(t, err)
+
+				entries = append(entries, q)
+			}
+
+			it := &target.TargetMock{
+				GetNameFunc: func() string { return "tgt" },
+				GetInstanceNamesFunc: func() ([]string, error) {
+					return tc.targetNames, tc.targetNameErr
+				},
+			}
+
+			err = d.checkInstanceRename(t.Context(), it, instances[0], entries[0], tc.newName)
+			tc.assertErr(t, err)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FuturFusion/migration-manager/internal/logger"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/target"
+	"github.com/FuturFusion/migration-manager/internal/transaction"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+const (
+	// maxPostMigrationScriptOutput is the amount of output from a post-migration script that is kept in the queue entry.
+	maxPostMigrationScriptOutput = 4096
+
+	// defaultPostMigrationScriptTimeout is used for first-boot scripts that do not set a timeout.
+	defaultPostMigrationScriptTimeout = 10 * time.Minute
+)
+
+// postMigrationScriptFile is a file of a script artifact to run inside a migrated instance.
+type postMigrationScriptFile struct {
+	artifact uuid.UUID
+	path     string
+	timeout  time.Duration
+}
+
+// checkPostMigrationScripts ensures each referenced script artifact exists, and can be run in its stage.
+func (d *Daemon) checkPostMigrationScripts(ctx context.Context, scripts []api.PostMigrationScript) error {
+	for _, s := range scripts {
+		art, err := d.artifact.GetByUUID(ctx, s.Artifact)
+		if err != nil {
+			if errors.Is(err, migration.ErrNotFound) {
+				return migration.NewValidationErrf("Post-migration script artifact %q does not exist", s.Artifact)
+			}
+
+			return fmt.Errorf("Failed to get post-migration script artifact %q: %w", s.Artifact, err)
+		}
+
+		if art.Type != api.ARTIFACTTYPE_SCRIPT {
+			return migration.NewValidationErrf("Artifact %q is of type %q, not %q", s.Artifact, art.Type, api.ARTIFACTTYPE_SCRIPT)
+		}
+
+		// The worker only mounts the root partition of Linux instances.
+		if s.Stage == api.POSTMIGRATIONSCRIPTSTAGE_WORKER && art.Properties.OS != api.OSTYPE_LINUX {
+			return migration.NewValidationErrf("Post-migration script artifact %q for OS %q cannot be run in stage %q", s.Artifact, art.Properties.OS, s.Stage)
+		}
+	}
+
+	return nil
+}
+
+// runFirstBootScripts runs the first-boot post-migration scripts that apply to the instance, and records their results in its queue entry.
+// The instance is started if it is not running, and stopped again afterwards if it should not be running after migration.
+func (d *Daemon) runFirstBootScripts(ctx context.Context, it target.Target, i migration.Instance, q migration.QueueEntry, batch migration.Batch) error {
+	files := []postMigrationScriptFile{}
+	for _, s := range batch.PostMigrationScripts(i, api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT) {
+		art, err := d.artifact.GetByUUID(ctx, s.Artifact)
+		if err != nil {
+			return fmt.Errorf("Failed to get post-migration script artifact %q: %w", s.Artifact, err)
+		}
+
+		timeout := s.Timeout.Duration
+		if timeout == 0 {
+			timeout = defaultPostMigrationScriptTimeout
+		}
+
+		if art.Properties.OS != i.GetOSType(true) {
+			continue
+		}
+
+		names := slices.Clone(art.Files)
+		slices.Sort(names)
+		for _, name := range names {
+			files = append(files, postMigrationScriptFile{artifact: art.UUID, path: filepath.Join(d.artifact.FileDirectory(art.UUID), name), timeout: timeout})
+		}
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	results, runErr := runFirstBootScripts(ctx, it, i, files)
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		entry, err := d.queue.GetByInstanceUUID(ctx, i.UUID)
+		if err != nil {
+			return err
+		}
+
+		entry.SetScriptResults(api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT, results)
+
+		return d.queue.Update(ctx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to record post-migration script results for instance %q: %w", i.GetName(), err)
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+
+	if !q.Placement.Running {
+		err := it.StopVM(ctx, i.GetName(), false)
+		if err != nil {
+			return fmt.Errorf("Failed to stop instance %q after running post-migration scripts: %w", i.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// runFirstBootScripts pushes each script file into the instance and runs it, stopping at the first script that fails.
+// Each script is stopped once its timeout expires, and removed from the instance after it has run.
+func runFirstBootScripts(ctx context.Context, it target.Target, i migration.Instance, files []postMigrationScriptFile) ([]api.PostMigrationScriptResult, error) {
+	// CheckIncusAgent also starts the instance if needed.
+	err := it.CheckIncusAgent(ctx, i.GetName())
+	if err != nil {
+		return nil, fmt.Errorf("Incus agent is not available to run post-migration scripts: %w", err)
+	}
+
+	destDir := "/tmp"
+	if i.GetOSType(true) == api.OSTYPE_WINDOWS {
+		destDir = "C:/Windows/Temp"
+	}
+
+	results := []api.PostMigrationScriptResult{}
+	for _, f := range files {
+		name := filepath.Base(f.path)
+		err := it.PushFile(i.GetName(), f.path, destDir)
+		if err != nil {
+			return results, fmt.Errorf("Failed to push post-migration script %q to instance %q: %w", name, i.GetName(), err)
+		}
+
+		dest := destDir + "/" + name
+		cmd := []string{dest}
+		if i.GetOSType(true) == api.OSTYPE_WINDOWS {
+			if strings.EqualFold(filepath.Ext(name), ".ps1") {
+				cmd = []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", dest}
+			} else {
+				cmd = []string{"cmd.exe", "/c", dest}
+			}
+		}
+
+		slog.Info("Running post-migration script", slog.String("instance", i.Properties.Location), slog.String("artifact", f.artifact.String()), slog.String("file", name))
+		exitCode, output, err := runFirstBootScript(ctx, it, i, cmd, dest, f.timeout)
+		if err != nil {
+			return results, fmt.Errorf("Failed to run post-migration script %q in instance %q: %w", name, i.GetName(), err)
+		}
+
+		output = strings.TrimSpace(output)
+		if len(output) > maxPostMigrationScriptOutput {
+			output = "..." + output[len(output)-maxPostMigrationScriptOutput:]
+		}
+
+		results = append(results, api.PostMigrationScriptResult{
+			Artifact: f.artifact,
+			File:     name,
+			Stage:    api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT,
+			ExitCode: exitCode,
+			Output:   output,
+			Date:     time.Now().UTC(),
+		})
+
+		if exitCode != 0 {
+			return results, fmt.Errorf("Post-migration script %q exited with code %d: %s", name, exitCode, output)
+		}
+	}
+
+	return results, nil
+}
+
+// runFirstBootScript runs the pushed script file within its timeout, and then removes it from the instance.
+func runFirstBootScript(ctx context.Context, it target.Target, i migration.Instance, cmd []string, dest string, timeout time.Duration) (int, string, error) {
+	defer func() {
+		rmCmd := []string{"rm", "-f", dest}
+		if i.GetOSType(true) == api.OSTYPE_WINDOWS {
+			rmCmd = []string{"cmd.exe", "/c", "del", "/f", "/q", strings.ReplaceAll(dest, "/", "\\")}
+		}
+
+		// Use a fresh timeout, as the script may have used up its own.
+		rmCtx, cancel := context.WithTimeout(ctx, it.Timeout())
+		defer cancel()
+
+		exitCode, output, err := it.RunCommand(rmCtx, i.GetName(), rmCmd)
+		if err != nil || exitCode != 0 {
+			slog.Warn("Failed to remove post-migration script from instance", slog.String("instance", i.Properties.Location), slog.String("file", dest), slog.Int("exit_code", exitCode), slog.String("output", output), logger.Err(err))
+		}
+	}()
+
+	scriptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	exitCode, output, err := it.RunCommand(scriptCtx, i.GetName(), cmd)
+	if err != nil {
+		if scriptCtx.Err() != nil && ctx.Err() == nil {
+			return -1, "", fmt.Errorf("Timed out after %s: %w", timeout, err)
+		}
+
+		return -1, "", err
+	}
+
+	return exitCode, output, nil
+}
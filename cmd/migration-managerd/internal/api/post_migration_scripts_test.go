@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/target"
+	"github.com/FuturFusion/migration-manager/internal/testing/boom"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestRunFirstBootScripts(t *testing.T) {
+	artUUID := uuid.MustParse("400f6ceb-659a-4b3c-8598-0bc9d20eafe3")
+	linux := migration.Instance{Properties: api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm1"}, Location: "/web/vm1", OS: "ubuntu64Guest"}}
+	windows := migration.Instance{Properties: api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm1"}, Location: "/web/vm1", OS: "windows2019srv_64Guest"}}
+
+	cases := []struct {
+		name     string
+		instance migration.Instance
+		files    []string
+		exitCode int
+		agentErr error
+		pushErr  error
+		runErr   error
+		hang     bool
+
+		wantCmds    [][]string
+		wantPushDir string
+		wantResults int
+		assertErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:        "success - linux",
+			instance:    linux,
+			files:       []string{"/artifacts/a.sh", "/artifacts/b.sh"},
+			wantCmds:    [][]string{{"/tmp/a.sh"}, {"rm", "-f", "/tmp/a.sh"}, {"/tmp/b.sh"}, {"rm", "-f", "/tmp/b.sh"}},
+			wantPushDir: "/tmp",
+			wantResults: 2,
+			assertErr:   require.NoError,
+		},
+		{
+			name:     "success - windows",
+			instance: windows,
+			files:    []string{"/artifacts/a.ps1", "/artifacts/b.cmd"},
+			wantCmds: [][]string{
+				{"powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", "C:/Windows/Temp/a.ps1"},
+				{"cmd.exe", "/c", "del", "/f", "/q", `C:\Windows\Temp\a.ps1`},
+				{"cmd.exe", "/c", "C:/Windows/Temp/b.cmd"},
+				{"cmd.exe", "/c", "del", "/f", "/q", `C:\Windows\Temp\b.cmd`},
+			},
+			wantPushDir: "C:/Windows/Temp",
+			wantResults: 2,
+			assertErr:   require.NoError,
+		},
+		{
+			name:        "error - script exits non-zero",
+			instance:    linux,
+			files:       []string{"/artifacts/a.sh", "/artifacts/b.sh"},
+			exitCode:    2,
+			wantCmds:    [][]string{{"/tmp/a.sh"}, {"rm", "-f", "/tmp/a.sh"}},
+			wantPushDir: "/tmp",
+			wantResults: 1,
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Post-migration script "a.sh" exited with code 2: output`)
+			},
+		},
+		{
+			name:      "error - agent unavailable",
+			instance:  linux,
+			files:     []string{"/artifacts/a.sh"},
+			agentErr:  boom.Error,
+			assertErr: boom.ErrorIs,
+		},
+		{
+			name:        "error - push file",
+			instance:    linux,
+			files:       []string{"/artifacts/a.sh"},
+			pushErr:     boom.Error,
+			wantPushDir: "/tmp",
+			assertErr:   boom.ErrorIs,
+		},
+		{
+			name:        "error - run command",
+			instance:    linux,
+			files:       []string{"/artifacts/a.sh"},
+			runErr:      boom.Error,
+			wantCmds:    [][]string{{"/tmp/a.sh"}, {"rm", "-f", "/tmp/a.sh"}},
+			wantPushDir: "/tmp",
+			assertErr:   boom.ErrorIs,
+		},
+		{
+			name:        "error - script times out",
+			instance:    linux,
+			files:       []string{"/artifacts/a.sh", "/artifacts/b.sh"},
+			hang:        true,
+			wantCmds:    [][]string{{"/tmp/a.sh"}, {"rm", "-f", "/tmp/a.sh"}},
+			wantPushDir: "/tmp",
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, `Failed to run post-migration script "a.sh" in instance "vm1": Timed out after 10ms`)
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		cmds := [][]string{}
+		it := &target.TargetMock{
+			CheckIncusAgentFunc: func(ctx context.Context, instanceName string) error {
+				return tc.agentErr
+			},
+			PushFileFunc: func(instanceName string, file string, destDir string) error {
+				require.Equal(t, "vm1", instanceName)
+				require.Equal(t, tc.wantPushDir, destDir)
+				return tc.pushErr
+			},
+			RunCommandFunc: func(ctx context.Context, instanceName string, cmd []string) (int, string, error) {
+				cmds = append(cmds, cmd)
+				if tc.hang && len(cmds)%2 == 1 {
+					<-ctx.Done()
+					return -1, "", ctx.Err()
+				}
+
+				return tc.exitCode, "output\n", tc.runErr
+			},
+			TimeoutFunc: func() time.Duration { return time.Second },
+		}
+
+		files := make([]postMigrationScriptFile, 0, len(tc.files))
+		for _, f := range tc.files {
+			files = append(files, postMigrationScriptFile{artifact: artUUID, path: f, timeout: 10 * time.Millisecond})
+		}
+
+		results, err := runFirstBootScripts(context.Background(), it, tc.instance, files)
+		tc.assertErr(t, err)
+		require.Len(t, results, tc.wantResults)
+		if tc.wantCmds != nil {
+			require.Equal(t, tc.wantCmds, cmds)
+		}
+
+		for _, r := range results {
+			require.Equal(t, artUUID, r.Artifact)
+			require.Equal(t, api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT, r.Stage)
+			require.Equal(t, tc.exitCode, r.ExitCode)
+			require.Equal(t, "output", r.Output)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/FuturFusion/migration-manager/internal/logger"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/source"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// sourceWatchRetryInterval is how long to wait before reconnecting a failed VM change watcher.
+const sourceWatchRetryInterval = time.Minute
+
+type sourceWatcher struct {
+	properties json.RawMessage
+	cancel     context.CancelFunc
+}
+
+// reconcileSourceWatchers starts a VM change watcher for each reachable VMware source with change watching enabled,
+// and stops watchers of sources that were removed, changed, or became unreachable.
+// Watchers are only managed from this periodic task, so the watcher map is not locked.
+func (d *Daemon) reconcileSourceWatchers(ctx context.Context) error {
+	sources, err := d.source.GetAll(ctx, api.SOURCETYPE_VMWARE)
+	if err != nil {
+		return fmt.Errorf("Failed to get all sources: %w", err)
+	}
+
+	wanted := map[string]migration.Source{}
+	for _, src := range sources {
+		props, err := src.GetVMwareProperties()
+		if err != nil {
+			return err
+		}
+
+		if props.WatchChanges && src.GetExternalConnectivityStatus() == api.EXTERNALCONNECTIVITYSTATUS_OK {
+			wanted[src.Name] = src
+		}
+	}
+
+	for name, watcher := range d.sourceWatchers {
+		src, ok := wanted[name]
+		if ok && bytes.Equal(src.Properties, watcher.properties) {
+			continue
+		}
+
+		slog.Info("Stopping VM change watcher", slog.String("source", name))
+		watcher.cancel()
+		delete(d.sourceWatchers, name)
+	}
+
+	for name, src := range wanted {
+		_, ok := d.sourceWatchers[name]
+		if ok {
+			continue
+		}
+
+		slog.Info("Starting VM change watcher", slog.String("source", name))
+		watchCtx, cancel := context.WithCancel(ctx)
+		d.sourceWatchers[name] = sourceWatcher{properties: src.Properties, cancel: cancel}
+		go d.watchSource(watchCtx, src)
+	}
+
+	return nil
+}
+
+// watchSource syncs VMs of the source as soon as they change, until the context is cancelled.
+// If the connection to the source fails, it is re-established after a delay.
+func (d *Daemon) watchSource(ctx context.Context, src migration.Source) {
+	log := slog.With(slog.String("source", src.Name))
+	for ctx.Err() == nil {
+		err := d.watchSourceOnce(ctx, src)
+		if err != nil {
+			log.Warn("Failed to watch source for VM changes", logger.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(sourceWatchRetryInterval):
+		}
+	}
+}
+
+// watchSourceOnce connects to the source and syncs changed VMs until the context is cancelled or the connection fails.
+func (d *Daemon) watchSourceOnce(ctx context.Context, src migration.Source) error {
+	s, err := source.NewVMSource(src.ToAPI())
+	if err != nil {
+		return err
+	}
+
+	// The watcher connection is long-lived, so don't apply the connection timeout to it.
+	err = s.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to source: %w", err)
+	}
+
+	defer func() { _ = s.Disconnect(context.Background()) }()
+
+	return s.WatchVMs(ctx, func(sourceSpecificIDs []string) {
+		slog.Debug("Detected VM changes on source", slog.String("source", src.Name), slog.Any("ids", sourceSpecificIDs))
+		err := d.syncChangedVMs(ctx, src.Name, sourceSpecificIDs)
+		if err != nil {
+			slog.Error("Failed to sync changed VMs", slog.String("source", src.Name), logger.Err(err))
+		}
+	})
+}
+
+// syncChangedVMs syncs the VMs with the given source-specific IDs that a watcher reported as changed.
+// The watcher outlives the source record it was started with, so the current record is used for the sync.
+func (d *Daemon) syncChangedVMs(ctx context.Context, srcName string, sourceSpecificIDs []string) error {
+	src, err := d.source.GetByName(ctx, srcName)
+	if err != nil {
+		return fmt.Errorf("Failed to get source: %w", err)
+	}
+
+	// The watcher is stopped with the next reconciliation, so leave the changes to the next full sync.
+	if src.GetExternalConnectivityStatus() != api.EXTERNALCONNECTIVITYSTATUS_OK {
+		slog.Debug("Skipping sync of changed VMs of unreachable source", slog.String("source", srcName))
+		return nil
+	}
+
+	return d.syncOneSource(ctx, *src, sourceSpecificIDs...)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/migration/endpoint/mock"
+	"github.com/FuturFusion/migration-manager/internal/source"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestReconcileSourceWatchers(t *testing.T) {
+	d := daemonSetup(t)
+	defer func() {
+		for _, w := range d.sourceWatchers {
+			w.cancel()
+		}
+	}()
+
+	endpointFunc := func(api.Source) (migration.SourceEndpoint, error) {
+		return &mock.SourceEndpointMock{
+			ConnectFunc: func(ctx context.Context) error { return nil },
+			DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+				return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+			},
+		}, nil
+	}
+
+	for _, name := range []string{"watched", "unwatched"} {
+		props := `{"endpoint": "https://127.0.0.1:1", "username":"u", "password":"p", "watch_changes": true}`
+		if name == "unwatched" {
+			props = `{"endpoint": "https://127.0.0.1:1", "username":"u", "password":"p"}`
+		}
+
+		_, err := d.source.Create(d.ShutdownCtx, migration.Source{Name: name, SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(props), EndpointFunc: endpointFunc})
+		require.NoError(t, err)
+	}
+
+	// Only sources with change watching enabled are watched.
+	require.NoError(t, d.reconcileSourceWatchers(t.Context()))
+	require.Len(t, d.sourceWatchers, 1)
+	watcher, ok := d.sourceWatchers["watched"]
+	require.True(t, ok)
+
+	// Watchers are kept while the source is unchanged.
+	require.NoError(t, d.reconcileSourceWatchers(t.Context()))
+	require.Equal(t, watcher.properties, d.sourceWatchers["watched"].properties)
+
+	// Watchers are restarted if the source changes, and stopped once change watching is disabled.
+	src, err := d.source.GetByName(t.Context(), "watched")
+	require.NoError(t, err)
+
+	props, err := src.GetVMwareProperties()
+	require.NoError(t, err)
+
+	props.SyncLimit = 2
+	src.Properties, err = json.Marshal(props)
+	require.NoError(t, err)
+	src.EndpointFunc = endpointFunc
+	require.NoError(t, d.source.Update(t.Context(), src.Name, src, d.instance))
+
+	require.NoError(t, d.reconcileSourceWatchers(t.Context()))
+	require.Len(t, d.sourceWatchers, 1)
+	require.NotEqual(t, watcher.properties, d.sourceWatchers["watched"].properties)
+
+	props.WatchChanges = false
+	src.Properties, err = json.Marshal(props)
+	require.NoError(t, err)
+	require.NoError(t, d.source.Update(t.Context(), src.Name, src, d.instance))
+
+	require.NoError(t, d.reconcileSourceWatchers(t.Context()))
+	require.Empty(t, d.sourceWatchers)
+}
+
+func TestSyncChangedVMs(t *testing.T) {
+	cases := []struct {
+		name   string
+		status api.ExternalConnectivityStatus
+
+		wantSynced bool
+	}{
+		{
+			name:   "reachable source syncs the changed VMs",
+			status: api.EXTERNALCONNECTIVITYSTATUS_OK,
+
+			wantSynced: true,
+		},
+		{
+			name:   "unreachable source is skipped",
+			status: api.EXTERNALCONNECTIVITYSTATUS_AUTH_ERROR,
+
+			wantSynced: false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+
+			_, err := d.source.Create(d.ShutdownCtx, migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+				return &mock.SourceEndpointMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+						return tc.status, nil
+					},
+				}, nil
+			}})
+			require.NoError(t, err)
+
+			origSource := source.NewVMSource
+			defer func() {
+				source.NewVMSource = origSource
+			}()
+
+			var syncedIDs []string
+			source.NewVMSource = func(s api.Source) (source.Source, error) {
+				return &source.SourceMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					GetAllVMsFunc: func(ctx context.Context, sourceSpecificIDs ...string) (migration.Instances, migration.Networks, migration.Warnings, error) {
+						syncedIDs = sourceSpecificIDs
+						return migration.Instances{}, migration.Networks{}, nil, nil
+					},
+				}, nil
+			}
+
+			err = d.syncChangedVMs(t.Context(), "src", []string{"VirtualMachine:vm1"})
+			require.NoError(t, err)
+			require.False(t, d.isSourceSyncing("src"))
+			if tc.wantSynced {
+				require.Equal(t, []string{"VirtualMachine:vm1"}, syncedIDs)
+			} else {
+				require.Nil(t, syncedIDs)
+			}
+		})
+	}
+}
@@ -124,8 +124,6 @@ func (d *Daemon) trySyncAllSources(ctx context.Context) (_err error) {
 				continue
 			}
 
-			d.syncCache.Write(src.Name, struct{}{}, nil)
-
 			if slices.Contains(api.VMSourceTypes(), src.SourceType) {
 				vmSourcesByName[src.Name] = src
 			}
@@ -141,7 +139,33 @@ func (d *Daemon) trySyncAllSources(ctx context.Context) (_err error) {
 		return err
 	}
 
-	defer func() { _ = d.syncCache.Replace(map[string]struct{}{}) }()
+	for _, src := range sources {
+		_, isVMSource := vmSourcesByName[src.Name]
+		_, isNetworkSource := networkSourcesByName[src.Name]
+		if !isVMSource && !isNetworkSource {
+			continue
+		}
+
+		d.beginSourceSync(src.Name)
+		defer d.endSourceSync(src.Name)
+
+		// Sources are returned sorted by name, so concurrent syncs take their locks in the same order.
+		d.sourceSyncLock.Lock(src.Name)
+		defer d.sourceSyncLock.Unlock(src.Name)
+	}
+
+	syncStart := time.Now().UTC()
+	srcErrs := map[string]error{}
+	defer func() {
+		for srcName := range vmSourcesByName {
+			err, ok := srcErrs[srcName]
+			if !ok {
+				err = _err
+			}
+
+			d.recordSyncStatus(ctx, srcName, syncStart, err)
+		}
+	}()
 
 	warnings := migration.Warnings{}
 	defer func() {
@@ -176,7 +200,7 @@ func (d *Daemon) trySyncAllSources(ctx context.Context) (_err error) {
 	networksBySrc := map[string]map[string]migration.Network{}
 	instancesBySrc := map[string]map[uuid.UUID]migration.Instance{}
 	for _, src := range vmSourcesByName {
-		props, err := src.GetVMwareProperties()
+		timeout, err := src.GetConnectionTimeout()
 		if err != nil {
 			return err
 		}
@@ -189,10 +213,11 @@ func (d *Daemon) trySyncAllSources(ctx context.Context) (_err error) {
 			continue
 		}
 
-		ctx, cancel := context.WithTimeout(ctx, props.ConnectionTimeout.Duration)
-		srcNetworks, srcInstances, importWarnings, err := fetchVMWareSourceData(ctx, src)
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		srcNetworks, srcInstances, importWarnings, err := fetchVMSourceData(ctx, src)
 		if err != nil {
 			cancel()
+			srcErrs[src.Name] = err
 			warnings = append(warnings, migration.NewSyncWarning(api.InstanceImportFailed, src.Name, err.Error()))
 			log.Error("Failed to fetch records from source", logger.Err(err))
 			continue
@@ -283,7 +308,7 @@ func (d *Daemon) trySyncAllSources(ctx context.Context) (_err error) {
 		instancesBySrc[srcName] = instancesByUUID
 	}
 
-	srcWarnings, err := d.syncSourceData(ctx, instancesBySrc, networksBySrc)
+	srcWarnings, err := d.syncSourceData(ctx, instancesBySrc, networksBySrc, nil)
 	if err != nil {
 		for srcName := range instancesBySrc {
 			warnings = append(warnings, migration.NewSyncWarning(api.InstanceImportFailed, srcName, fmt.Sprintf("Failed to update records: %v", err)))
@@ -297,11 +322,58 @@ func (d *Daemon) trySyncAllSources(ctx context.Context) (_err error) {
 	return nil
 }
 
-// syncSourceData fetches instance and network data from the source and updates our database records.
-func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source) error {
-	slog.Info("Syncing source", slog.String("source", src.Name))
-	d.syncCache.Write(src.Name, struct{}{}, nil)
-	defer d.syncCache.Delete(src.Name)
+// beginSourceSync marks the given source as syncing until the matching call to endSourceSync.
+func (d *Daemon) beginSourceSync(srcName string) {
+	d.syncCache.Write(srcName, 1, func(existingVal int, newVal int) int { return existingVal + newVal })
+}
+
+// endSourceSync releases a mark set by beginSourceSync.
+func (d *Daemon) endSourceSync(srcName string) {
+	d.syncCache.Write(srcName, -1, func(existingVal int, newVal int) int { return existingVal + newVal })
+}
+
+// isSourceSyncing returns whether any sync of the given source is running.
+func (d *Daemon) isSourceSyncing(srcName string) bool {
+	count, _ := d.syncCache.Read(srcName)
+
+	return count > 0
+}
+
+// recordSyncStatus records the result of a sync of the given source that started at the given time.
+func (d *Daemon) recordSyncStatus(ctx context.Context, srcName string, syncStart time.Time, err error) {
+	status := api.SourceSyncStatus{
+		LastSync:         syncStart,
+		LastSyncDuration: api.AsDuration(time.Since(syncStart)),
+	}
+
+	if err != nil {
+		status.LastSyncError = err.Error()
+	}
+
+	err = d.source.UpdateSyncStatus(ctx, srcName, status)
+	if err != nil {
+		slog.Error("Failed to record source sync status", slog.String("source", srcName), logger.Err(err))
+	}
+}
+
+// syncOneSource fetches instance and network data from the source and updates our database records.
+// If source-specific IDs are given, only those instances and the networks they use are refreshed.
+// Records and warnings of the rest of the source are left untouched, and the sync does not count towards the source's sync schedule.
+func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source, sourceSpecificIDs ...string) (_err error) {
+	partial := len(sourceSpecificIDs) > 0
+	slog.Info("Syncing source", slog.String("source", src.Name), slog.Int("instances", len(sourceSpecificIDs)))
+	d.beginSourceSync(src.Name)
+	defer d.endSourceSync(src.Name)
+
+	d.sourceSyncLock.Lock(src.Name)
+	defer d.sourceSyncLock.Unlock(src.Name)
+
+	syncStart := time.Now().UTC()
+	defer func() {
+		if !partial {
+			d.recordSyncStatus(ctx, src.Name, syncStart, _err)
+		}
+	}()
 
 	nsxSources, err := d.source.GetAll(ctx, api.SOURCETYPE_NSX)
 	if err != nil {
@@ -311,11 +383,14 @@ func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source) error
 	warnings := migration.Warnings{}
 	defer func() {
 		err := transaction.Do(ctx, func(ctx context.Context) error {
-			sourceScope := api.WarningScopeSync()
-			sourceScope.Entity = src.Name
-			err := d.warning.RemoveStale(ctx, sourceScope, warnings)
-			if err != nil {
-				return fmt.Errorf("Failed to clean up warnings: %w", err)
+			// A partial sync can't tell which of the source's warnings are stale.
+			if !partial {
+				sourceScope := api.WarningScopeSync()
+				sourceScope.Entity = src.Name
+				err := d.warning.RemoveStale(ctx, sourceScope, warnings)
+				if err != nil {
+					return fmt.Errorf("Failed to clean up warnings: %w", err)
+				}
 			}
 
 			for _, w := range warnings {
@@ -332,15 +407,15 @@ func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source) error
 		}
 	}()
 
-	props, err := src.GetVMwareProperties()
+	timeout, err := src.GetConnectionTimeout()
 	if err != nil {
 		return err
 	}
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, props.ConnectionTimeout.Duration)
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	srcNetworks, srcInstances, importWarnings, err := fetchVMWareSourceData(timeoutCtx, src)
+	srcNetworks, srcInstances, importWarnings, err := fetchVMSourceData(timeoutCtx, src, sourceSpecificIDs...)
 	if err != nil {
 		warnings = append(warnings, migration.NewSyncWarning(api.InstanceImportFailed, src.Name, err.Error()))
 		return err
@@ -396,24 +471,27 @@ func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source) error
 				}
 			}
 
-			vmwareSrc, err := source.NewVMSource(src.ToAPI())
-			if err != nil {
-				return fmt.Errorf("Failed to convert source %q to %q source: %w", src.Name, src.SourceType, err)
-			}
+			// Dumps of a VMware source don't record its NSX Managers, so only look for them on a live VMware source.
+			if src.SourceType == api.SOURCETYPE_VMWARE {
+				vmwareSrc, err := source.NewVMSource(src.ToAPI())
+				if err != nil {
+					return fmt.Errorf("Failed to convert source %q to %q source: %w", src.Name, src.SourceType, err)
+				}
 
-			err = vmwareSrc.Connect(ctx)
-			if err != nil {
-				return fmt.Errorf("Failed to connect to source %q: %w", src.Name, err)
-			}
+				err = vmwareSrc.Connect(ctx)
+				if err != nil {
+					return fmt.Errorf("Failed to connect to source %q: %w", src.Name, err)
+				}
 
-			internalSrc, ok := vmwareSrc.(*source.InternalVMwareSource)
-			if !ok {
-				return fmt.Errorf("Invalid underlying source %q type %T", src.Name, vmwareSrc)
-			}
+				internalSrc, ok := vmwareSrc.(*source.InternalVMwareSource)
+				if !ok {
+					return fmt.Errorf("Invalid underlying source %q type %T", src.Name, vmwareSrc)
+				}
 
-			nsxIP, err = internalSrc.GetNSXManagerIP(timeoutCtx)
-			if err != nil {
-				return fmt.Errorf("Failed to look for NSX Managers for source %q: %w", src.Name, err)
+				nsxIP, err = internalSrc.GetNSXManagerIP(timeoutCtx)
+				if err != nil {
+					return fmt.Errorf("Failed to look for NSX Managers for source %q: %w", src.Name, err)
+				}
 			}
 		}
 	}
@@ -445,7 +523,12 @@ func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source) error
 			}
 		}
 
-		srcWarnings, err := d.syncSourceData(ctx, instancesBySrc, networksBySrc)
+		var partialIDs map[string][]string
+		if partial {
+			partialIDs = map[string][]string{src.Name: sourceSpecificIDs}
+		}
+
+		srcWarnings, err := d.syncSourceData(ctx, instancesBySrc, networksBySrc, partialIDs)
 		if err != nil {
 			return err
 		}
@@ -457,7 +540,8 @@ func (d *Daemon) syncOneSource(ctx context.Context, src migration.Source) error
 }
 
 // syncSourceData is a helper that opens a transaction and updates the internal record of all sources with the supplied data.
-func (d *Daemon) syncSourceData(ctx context.Context, instancesBySrc map[string]map[uuid.UUID]migration.Instance, networksBySrc map[string]map[string]migration.Network) (migration.Warnings, error) {
+// For sources with an entry in partialIDs, only the instances with the given source-specific IDs are compared, and no networks are removed.
+func (d *Daemon) syncSourceData(ctx context.Context, instancesBySrc map[string]map[uuid.UUID]migration.Instance, networksBySrc map[string]map[string]migration.Network, partialIDs map[string][]string) (migration.Warnings, error) {
 	syncLock.Lock()
 	defer syncLock.Unlock()
 
@@ -527,7 +611,16 @@ func (d *Daemon) syncSourceData(ctx context.Context, instancesBySrc map[string]m
 				return fmt.Errorf("Failed to get internal instance records for source %q: %w", srcName, err)
 			}
 
+			ids, partial := partialIDs[srcName]
 			for _, inst := range allInstances {
+				// If only some instances were fetched, then omit the rest from consideration.
+				if partial && !slices.Contains(ids, inst.Properties.SourceSpecificID) {
+					_, ok := srcInstances[inst.UUID]
+					if !ok {
+						continue
+					}
+				}
+
 				// If the instance is already assigned to a running batch, then omit it from consideration, unless it is disabled.
 				if instanceIsMigrating[inst.UUID] && inst.DisabledReason(api.InstanceRestrictionOverride{}) == nil {
 					delete(srcInstances, inst.UUID)
@@ -606,6 +699,12 @@ func (d *Daemon) syncSourceData(ctx context.Context, instancesBySrc map[string]m
 					}
 				}
 
+				// If only some instances were fetched, then only update the networks they use.
+				_, partial := partialIDs[srcName]
+				if partial && !ok {
+					continue
+				}
+
 				existingNetworks[dbNetwork.SourceSpecificID] = dbNetwork
 			}
 
@@ -621,6 +720,12 @@ func (d *Daemon) syncSourceData(ctx context.Context, instancesBySrc map[string]m
 		return nil, err
 	}
 
+	// Clean up the secrets of any instances that were removed.
+	err = d.pruneInstanceSecrets(ctx)
+	if err != nil {
+		slog.Error("Failed to prune instance secrets", slog.Any("error", err))
+	}
+
 	return warnings, nil
 }
 
@@ -877,6 +982,11 @@ func fetchNSXSourceData(ctx context.Context, src migration.Source, vcenterSource
 		return false, err
 	}
 
+	firewall, err := s.GetFirewall(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	segmentData := map[string]*internalAPI.NSXSegment{}
 	for _, baseSegment := range segments {
 		for src, networks := range networksBySrc {
@@ -903,9 +1013,15 @@ func fetchNSXSourceData(ctx context.Context, src migration.Source, vcenterSource
 					}
 
 					log.Info("Recording NSX segment data", slog.String("segment", segment.Path), slog.String("network", network.Location))
+					vmUUIDs := make([]uuid.UUID, 0, len(segment.VMs))
+					for _, vm := range segment.VMs {
+						vmUUIDs = append(vmUUIDs, vm.UUID)
+					}
+
 					nsxProps := internalAPI.NSXNetworkProperties{
-						Source:  s.Name,
-						Segment: *segment,
+						Source:   s.Name,
+						Segment:  *segment,
+						Firewall: migration.NSXFirewallForVMs(*firewall, vmUUIDs),
 					}
 
 					if vcProps.TransportZoneUUID != uuid.Nil {
@@ -935,12 +1051,13 @@ func fetchNSXSourceData(ctx context.Context, src migration.Source, vcenterSource
 	return true, nil
 }
 
-// fetchVMWareSourceData connects to a VMWare source and returns the resources we care about, keyed by their unique identifiers.
-func fetchVMWareSourceData(ctx context.Context, src migration.Source) (map[string]migration.Network, map[uuid.UUID]migration.Instance, migration.Warnings, error) {
-	slog.Debug("Fetching VMware data for source", slog.String("source", src.Name))
+// fetchVMSourceData connects to a VM source and returns the resources we care about, keyed by their unique identifiers.
+// If source-specific IDs are given, only those instances are fetched.
+func fetchVMSourceData(ctx context.Context, src migration.Source, sourceSpecificIDs ...string) (map[string]migration.Network, map[uuid.UUID]migration.Instance, migration.Warnings, error) {
+	slog.Debug("Fetching VM data for source", slog.String("source", src.Name), slog.String("type", string(src.SourceType)))
 	s, err := source.NewVMSource(src.ToAPI())
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("Failed to create VMwareSource from source: %w", err)
+		return nil, nil, nil, fmt.Errorf("Failed to create %q source from source: %w", src.SourceType, err)
 	}
 
 	err = s.Connect(ctx)
@@ -948,7 +1065,7 @@ func fetchVMWareSourceData(ctx context.Context, src migration.Source) (map[strin
 		return nil, nil, nil, fmt.Errorf("Failed to connect to source: %w", err)
 	}
 
-	instances, allNetworks, warnings, err := s.GetAllVMs(ctx)
+	instances, allNetworks, warnings, err := s.GetAllVMs(ctx, sourceSpecificIDs...)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("Failed to get VMs: %w", err)
 	}
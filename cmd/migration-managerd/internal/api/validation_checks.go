@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/FuturFusion/migration-manager/internal/logger"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/target"
+	"github.com/FuturFusion/migration-manager/shared/api"
+	"github.com/FuturFusion/migration-manager/shared/api/event"
+)
+
+const (
+	// defaultValidationCheckTimeout is used for validation checks that do not set a timeout.
+	defaultValidationCheckTimeout = 5 * time.Minute
+
+	// maxValidationCheckOutput is the amount of output from a failed check that is kept in the queue entry status message.
+	maxValidationCheckOutput = 1024
+)
+
+// validationCheckRetryInterval is the time to wait between attempts of a failing validation check.
+var validationCheckRetryInterval = 5 * time.Second
+
+// runValidationChecks runs the given validation checks that apply to the instance inside the migrated target instance.
+// It returns a description of the checks that failed, or an empty string if all checks passed.
+func runValidationChecks(ctx context.Context, it target.Target, i migration.Instance, checks []api.BatchValidationCheck) (string, error) {
+	failures := []string{}
+	for _, check := range checks {
+		if check.IncludeExpression != "" {
+			match, err := i.MatchesCriteria(check.IncludeExpression, false)
+			if err != nil {
+				return "", fmt.Errorf("Failed to match validation check %q: %w", check.Name, err)
+			}
+
+			if !match {
+				continue
+			}
+		}
+
+		cmd, err := i.ValidationCheckCommand(check)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", check.Name, err))
+			continue
+		}
+
+		timeout := check.Timeout.Duration
+		if timeout == 0 {
+			timeout = defaultValidationCheckTimeout
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = runValidationCheck(checkCtx, it, i.GetName(), cmd)
+		cancel()
+		if err != nil {
+			slog.Warn("Validation check failed", slog.String("instance", i.Properties.Location), slog.String("check", check.Name), logger.Err(err))
+			failures = append(failures, fmt.Sprintf("%s: %v", check.Name, err))
+		}
+	}
+
+	return strings.Join(failures, "; "), nil
+}
+
+// runValidationCheck repeatedly runs the command inside the instance until it exits successfully, or the context is done.
+func runValidationCheck(ctx context.Context, it target.Target, instanceName string, cmd []string) error {
+	err := it.CheckIncusAgent(ctx, instanceName)
+	if err != nil {
+		return fmt.Errorf("Incus agent is not available: %w", err)
+	}
+
+	var lastErr error
+	for {
+		exitCode, output, err := it.RunCommand(ctx, instanceName, cmd)
+		if err == nil && exitCode == 0 {
+			return nil
+		}
+
+		if err != nil {
+			// Prefer the result of the last completed attempt over the context error.
+			if ctx.Err() != nil && lastErr != nil {
+				return lastErr
+			}
+
+			lastErr = err
+		} else {
+			output = strings.TrimSpace(output)
+			if len(output) > maxValidationCheckOutput {
+				output = "..." + output[len(output)-maxValidationCheckOutput:]
+			}
+
+			lastErr = fmt.Errorf("Exit code %d: %s", exitCode, output)
+		}
+
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-time.After(validationCheckRetryInterval):
+		}
+	}
+}
+
+// startValidationChecks runs the validation checks of the batch inside the migrated instance in the background, unless they are already running.
+func (d *Daemon) startValidationChecks(ctx context.Context, q migration.QueueEntry, i migration.Instance, t migration.Target, batch migration.Batch) {
+	d.validationLock.Lock()
+	defer d.validationLock.Unlock()
+
+	_, ok := d.validations[i.UUID]
+	if ok {
+		return
+	}
+
+	d.validations[i.UUID] = struct{}{}
+	go func() {
+		defer func() {
+			d.validationLock.Lock()
+			delete(d.validations, i.UUID)
+			d.validationLock.Unlock()
+		}()
+
+		err := d.validateInstance(ctx, q, i, t, batch)
+		if err != nil {
+			slog.Error("Failed to validate migrated instance", slog.String("batch", batch.Name), slog.String("instance", i.Properties.Location), logger.Err(err))
+		}
+	}()
+}
+
+// validateInstance runs the validation checks of the batch inside the migrated instance, and then records the result in its queue entry.
+// The checks run without holding the worker lock, which is only taken to record the result.
+// If the checks can't be run, the queue entry is left VALIDATING, so they are retried by the next run of the post-import task.
+func (d *Daemon) validateInstance(ctx context.Context, q migration.QueueEntry, i migration.Instance, t migration.Target, batch migration.Batch) error {
+	it, err := target.NewTarget(t.ToAPI())
+	if err != nil {
+		return fmt.Errorf("Failed to construct target %q: %w", t.Name, err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, it.Timeout())
+	err = it.Connect(timeoutCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("Failed to connect to target %q: %w", it.GetName(), err)
+	}
+
+	err = it.SetProject(q.Placement.TargetProject)
+	if err != nil {
+		return fmt.Errorf("Failed to set target %q project %q: %w", it.GetName(), q.Placement.TargetProject, err)
+	}
+
+	failure, err := runValidationChecks(ctx, it, i, batch.Config.ValidationChecks)
+	if err != nil {
+		failure = err.Error()
+	}
+
+	// Leave the checks to be run again after a restart.
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	workerLock.RLock()
+	defer workerLock.RUnlock()
+
+	// The queue entry may have been changed while the checks were running.
+	current, err := d.queue.GetByInstanceUUID(ctx, i.UUID)
+	if err != nil {
+		return fmt.Errorf("Failed to get queue entry: %w", err)
+	}
+
+	if current.MigrationStatus != api.MIGRATIONSTATUS_VALIDATING {
+		return nil
+	}
+
+	if failure != "" {
+		return d.failValidation(ctx, i, *current, batch, failure)
+	}
+
+	_, err = d.queue.UpdateStatusByUUID(ctx, i.UUID, api.MIGRATIONSTATUS_FINISHED, string(api.MIGRATIONSTATUS_FINISHED), current.ImportStage, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to update instance status to %q: %w", api.MIGRATIONSTATUS_FINISHED, err)
+	}
+
+	return d.finishCompletedBatch(ctx, batch.Name)
+}
+
+// failValidation sets the queue entry status to VALIDATION FAILED, and rolls back the migration if the batch is configured to do so.
+// The caller must hold the worker lock.
+func (d *Daemon) failValidation(ctx context.Context, i migration.Instance, q migration.QueueEntry, batch migration.Batch, failure string) error {
+	log := slog.With(
+		slog.String("method", "failValidation"),
+		slog.String("batch", batch.Name),
+		slog.String("instance", i.Properties.Location),
+	)
+
+	log.Warn("Instance failed validation checks", slog.String("failure", failure))
+	newQ, err := d.queue.UpdateStatusByUUID(ctx, i.UUID, api.MIGRATIONSTATUS_VALIDATION_FAILED, "Validation checks failed: "+failure, q.ImportStage, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to update instance status to %q: %w", api.MIGRATIONSTATUS_VALIDATION_FAILED, err)
+	}
+
+	d.logHandler.SendLifecycle(ctx, event.NewMigrationEvent(event.MigrationValidationFailed, i.ToAPI(), newQ.ToAPI(i.GetName(), d.queueHandler.LastWorkerUpdate(i.UUID), migration.Window{})))
+
+	if !batch.Config.RollbackOnValidationFailure {
+		return nil
+	}
+
+	log.Info("Rolling back instance after failed validation")
+	apiQueue, err := d.rollbackQueueEntry(ctx, i.UUID, false, "Rolled back to source after failed validation checks: "+failure)
+	if err != nil {
+		// The queue entry can still be rolled back manually.
+		log.Error("Failed to roll back instance after failed validation", logger.Err(err))
+		return nil
+	}
+
+	d.logHandler.SendLifecycle(ctx, event.NewQueueEntryEvent(event.QueueEntryRolledBack, nil, *apiQueue, apiQueue.InstanceUUID))
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/migration/endpoint/mock"
+	"github.com/FuturFusion/migration-manager/internal/target"
+	"github.com/FuturFusion/migration-manager/internal/testing/boom"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestRunValidationChecks(t *testing.T) {
+	validationCheckRetryInterval = time.Millisecond
+	defer func() { validationCheckRetryInterval = 5 * time.Second }()
+
+	inst := migration.Instance{Properties: api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm1"}, Location: "/web/vm1", OS: "ubuntu64Guest"}}
+
+	cases := []struct {
+		name       string
+		checks     []api.BatchValidationCheck
+		results    []int
+		agentErr   error
+		runErr     error
+		wantCmds   int
+		wantResult string
+	}{
+		{
+			name:     "all checks pass",
+			checks:   []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_PING}, {Name: "b", Type: api.VALIDATIONCHECK_COMMAND, Command: []string{"true"}}},
+			results:  []int{0, 0},
+			wantCmds: 2,
+		},
+		{
+			name:     "check passes after retry",
+			checks:   []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_SYSTEMD_UNIT, Target: "sshd.service"}},
+			results:  []int{3, 3, 0},
+			wantCmds: 3,
+		},
+		{
+			name:     "check skipped by include expression",
+			checks:   []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_PING, IncludeExpression: `location matches "^/db/"`}},
+			wantCmds: 0,
+		},
+		{
+			name:       "check times out",
+			checks:     []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_COMMAND, Command: []string{"false"}, Timeout: api.AsDuration(20 * time.Millisecond)}},
+			results:    []int{1},
+			wantResult: "a: Exit code 1: output",
+		},
+		{
+			name:       "unsupported check",
+			checks:     []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_WINDOWS_SERVICE, Target: "W3SVC"}},
+			wantResult: `a: Validation check type "windows-service" is not supported for OS type "linux"`,
+		},
+		{
+			name:       "agent unavailable",
+			checks:     []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_PING}, {Name: "b", Type: api.VALIDATIONCHECK_PING}},
+			agentErr:   boom.Error,
+			wantResult: "a: Incus agent is not available: boom!; b: Incus agent is not available: boom!",
+		},
+		{
+			name:       "exec error",
+			checks:     []api.BatchValidationCheck{{Name: "a", Type: api.VALIDATIONCHECK_PING, Timeout: api.AsDuration(20 * time.Millisecond)}},
+			runErr:     boom.Error,
+			wantResult: "a: boom!",
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, c.name)
+
+		var cmds int
+		it := &target.TargetMock{
+			CheckIncusAgentFunc: func(ctx context.Context, instanceName string) error {
+				return c.agentErr
+			},
+			RunCommandFunc: func(ctx context.Context, instanceName string, cmd []string) (int, string, error) {
+				require.Equal(t, "vm1", instanceName)
+				if c.runErr != nil {
+					return -1, "", c.runErr
+				}
+
+				exitCode := c.results[min(cmds, len(c.results)-1)]
+				cmds++
+				return exitCode, "output\n", nil
+			},
+		}
+
+		result, err := runValidationChecks(context.Background(), it, inst, c.checks)
+		require.NoError(t, err)
+		require.Equal(t, c.wantResult, result)
+		if c.wantResult == "" {
+			require.Equal(t, c.wantCmds, cmds)
+		}
+	}
+}
+
+func TestValidateInstance(t *testing.T) {
+	validationCheckRetryInterval = time.Millisecond
+	defer func() { validationCheckRetryInterval = 5 * time.Second }()
+
+	cases := []struct {
+		name       string
+		status     api.MigrationStatusType
+		exitCode   int
+		connectErr error
+
+		assertErr       require.ErrorAssertionFunc
+		wantStatus      api.MigrationStatusType
+		wantBatchStatus api.BatchStatusType
+	}{
+		{
+			name:   "success - checks pass and the batch finishes",
+			status: api.MIGRATIONSTATUS_VALIDATING,
+
+			assertErr:       require.NoError,
+			wantStatus:      api.MIGRATIONSTATUS_FINISHED,
+			wantBatchStatus: api.BATCHSTATUS_FINISHED,
+		},
+		{
+			name:     "success - failed check is recorded",
+			status:   api.MIGRATIONSTATUS_VALIDATING,
+			exitCode: 1,
+
+			assertErr:       require.NoError,
+			wantStatus:      api.MIGRATIONSTATUS_VALIDATION_FAILED,
+			wantBatchStatus: api.BATCHSTATUS_RUNNING,
+		},
+		{
+			name:   "success - queue entry changed while the checks were running",
+			status: api.MIGRATIONSTATUS_ERROR,
+
+			assertErr:       require.NoError,
+			wantStatus:      api.MIGRATIONSTATUS_ERROR,
+			wantBatchStatus: api.BATCHSTATUS_RUNNING,
+		},
+		{
+			name:       "error - target unreachable, checks are left to be retried",
+			status:     api.MIGRATIONSTATUS_VALIDATING,
+			connectErr: boom.Error,
+
+			assertErr:       boom.ErrorIs,
+			wantStatus:      api.MIGRATIONSTATUS_VALIDATING,
+			wantBatchStatus: api.BATCHSTATUS_RUNNING,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			d := daemonSetup(t)
+			batch := migration.Batch{
+				Name:              "b1",
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Defaults: api.BatchDefaults{
+					Placement: api.BatchPlacement{Target: "default", TargetProject: "default", StoragePool: "default"},
+				},
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					ValidationChecks:         []api.BatchValidationCheck{{Name: "check", Type: api.VALIDATIONCHECK_COMMAND, Command: []string{"true"}, Timeout: api.AsDuration(20 * time.Millisecond)}},
+				},
+			}
+
+			_, err := d.batch.Create(d.ShutdownCtx, batch)
+			require.NoError(t, err)
+
+			_, err = d.batch.UpdateStatusByName(t.Context(), batch.Name, api.BATCHSTATUS_RUNNING, string(api.BATCHSTATUS_RUNNING))
+			require.NoError(t, err)
+
+			src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"endpoint": "bar", "username":"u", "password":"p"}`), EndpointFunc: func(api.Source) (migration.SourceEndpoint, error) {
+				return &mock.SourceEndpointMock{
+					ConnectFunc: func(ctx context.Context) error { return nil },
+					DoBasicConnectivityCheckFunc: func() (api.ExternalConnectivityStatus, *x509.Certificate) {
+						return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+					},
+				}, nil
+			}}
+
+			_, err = d.source.Create(d.ShutdownCtx, src)
+			require.NoError(t, err)
+
+			inst := migration.Instance{
+				UUID:                 uuid.New(),
+				Source:               src.Name,
+				SourceType:           src.SourceType,
+				LastUpdateFromSource: time.Now(),
+				Properties:           api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm"}, Location: "vm", OS: "ubuntu64Guest"},
+			}
+
+			_, err = d.instance.Create(t.Context(), inst)
+			require.NoError(t, err)
+
+			q, err := d.queue.CreateEntry(t.Context(), migration.QueueEntry{
+				InstanceUUID:    inst.UUID,
+				BatchName:       batch.Name,
+				MigrationStatus: tc.status,
+				SecretToken:     uuid.New(),
+				ImportStage:     migration.IMPORTSTAGE_COMPLETE,
+				Placement:       api.Placement{TargetName: "tgt", TargetProject: "default", StoragePools: map[string]string{"root": "default"}, Networks: map[string]api.NetworkPlacement{}, Running: true},
+			})
+			require.NoError(t, err)
+
+			origTarget := target.NewTarget
+			defer func() {
+				target.NewTarget = origTarget
+			}()
+
+			target.NewTarget = func(tgt api.Target) (target.Target, error) {
+				return &target.TargetMock{
+					TimeoutFunc:         func() time.Duration { return time.Second },
+					GetNameFunc:         func() string { return tgt.Name },
+					ConnectFunc:         func(ctx context.Context) error { return tc.connectErr },
+					SetProjectFunc:      func(project string) error { return nil },
+					CheckIncusAgentFunc: func(ctx context.Context, instanceName string) error { return nil },
+					RunCommandFunc: func(ctx context.Context, instanceName string, cmd []string) (int, string, error) {
+						return tc.exitCode, "", nil
+					},
+				}, nil
+			}
+
+			err = d.validateInstance(t.Context(), q, inst, migration.Target{Name: "tgt", TargetType: api.TARGETTYPE_INCUS}, batch)
+			tc.assertErr(t, err)
+
+			resultQueue, err := d.queue.GetByInstanceUUID(t.Context(), inst.UUID)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, resultQueue.MigrationStatus)
+
+			resultBatch, err := d.batch.GetByName(t.Context(), batch.Name)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantBatchStatus, resultBatch.Status)
+		})
+	}
+}
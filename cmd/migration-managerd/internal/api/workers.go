@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -15,6 +16,7 @@ import (
 	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/revert"
 	incusTLS "github.com/lxc/incus/v6/shared/tls"
+	"github.com/lxc/incus/v6/shared/validate"
 
 	"github.com/FuturFusion/migration-manager/internal/logger"
 	"github.com/FuturFusion/migration-manager/internal/migration"
@@ -35,6 +37,9 @@ const (
 	PostImportTask   Task = "post-import"
 	ACMEUpdateTask   Task = "acme-update"
 	CacheCleanupTask Task = "cache-cleanup"
+	SourceWatchTask  Task = "source-watch"
+
+	WindowScheduleTask Task = "window-schedule"
 )
 
 func (d *Daemon) runPeriodicTask(ctx context.Context, task Task, f func(context.Context) error, interval time.Duration) {
@@ -44,49 +49,97 @@ func (d *Daemon) runPeriodicTask(ctx context.Context, task Task, f func(context.
 				return
 			}
 
-			// Don't run the sync task if it's disabled.
-			if task != SyncTask || !d.config.Settings.DisableAutoSync {
-				slog.Debug("Running periodic task", slog.Any("task", task))
-				err := f(ctx)
-				if err != nil {
-					slog.Error("Failed to run periodic task", slog.String("task", string(task)), logger.Err(err))
-				}
-			}
-
-			// Override the default duration for sync tasks.
-			if task == SyncTask {
-				interval = d.config.Settings.SyncInterval.Duration
+			slog.Debug("Running periodic task", slog.Any("task", task))
+			err := f(ctx)
+			if err != nil {
+				slog.Error("Failed to run periodic task", slog.String("task", string(task)), logger.Err(err))
 			}
 
-			now := time.Now().UTC()
 			ctx, cancel := context.WithTimeout(ctx, interval)
-			defer cancel()
+			<-ctx.Done()
+			cancel()
+		}
+	}()
+}
 
-			if task != SyncTask {
-				<-ctx.Done()
-				cancel()
-				continue
-			}
+// syncDueSources starts a background sync of every VM source that is due according to its own sync schedule.
+// Sources are synced independently, so a slow source does not hold back the others.
+func (d *Daemon) syncDueSources(ctx context.Context) error {
+	if d.config.Settings.DisableAutoSync {
+		return nil
+	}
 
-			// If this is a sync task, check every 1s in case the interval changed and restart the parent loop if the new interval has already passed.
-			var done bool
-			for !done {
-				select {
-				case <-ctx.Done():
-					done = true
-				default:
-					if time.Now().UTC().After(now.Add(d.config.Settings.SyncInterval.Duration)) {
-						done = true
-						continue
-					}
+	sources, err := d.source.GetAll(ctx, api.VMSourceTypes()...)
+	if err != nil {
+		return fmt.Errorf("Failed to get all sources: %w", err)
+	}
 
-					time.Sleep(1 * time.Second)
-				}
+	now := time.Now().UTC()
+	for _, src := range sources {
+		log := slog.With(slog.String("source", src.Name))
+		if src.GetExternalConnectivityStatus() != api.EXTERNALCONNECTIVITYSTATUS_OK {
+			continue
+		}
+
+		if d.isSourceSyncing(src.Name) {
+			continue
+		}
+
+		next, err := src.NextSync(src.SyncStatus.LastSync, d.config.Settings.SyncInterval.Duration)
+		if err != nil {
+			log.Error("Failed to determine next source sync", logger.Err(err))
+			continue
+		}
+
+		if now.Before(next) {
+			continue
+		}
+
+		blackout, err := src.InSyncBlackout(now)
+		if err != nil {
+			log.Error("Failed to determine source sync blackout", logger.Err(err))
+			continue
+		}
+
+		if blackout {
+			log.Debug("Skipping source sync during blackout period")
+			continue
+		}
+
+		// Mark the source as syncing before starting the sync, so the next check does not start it again.
+		d.beginSourceSync(src.Name)
+		go func(src migration.Source) {
+			defer d.endSourceSync(src.Name)
+
+			err := d.syncOneSource(ctx, src)
+			if err != nil {
+				log.Error("Failed to sync source", logger.Err(err))
 			}
+		}(src)
+	}
 
-			cancel()
+	return nil
+}
+
+// expandWindowSchedules generates the migration windows of every unfinished batch from its migration window schedules, keeping them populated up to the scheduling horizon.
+func (d *Daemon) expandWindowSchedules(ctx context.Context) error {
+	batches, err := d.batch.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get all batches: %w", err)
+	}
+
+	for _, b := range batches {
+		if len(b.MigrationWindowSchedules) == 0 || b.Status == api.BATCHSTATUS_FINISHED {
+			continue
 		}
-	}()
+
+		err := d.window.SyncSchedules(ctx, d.queue, b.Name, b.MigrationWindowSchedules, b.MigrationWindowExclusions)
+		if err != nil {
+			slog.Error("Failed to generate scheduled migration windows", slog.String("batch", b.Name), logger.Err(err))
+		}
+	}
+
+	return nil
 }
 
 func (d *Daemon) reassessBlockedInstances(ctx context.Context) error {
@@ -229,6 +282,55 @@ func (d *Daemon) reassessBlockedInstances(ctx context.Context) error {
 // but user API actions should grab the write lock, making them exclusive with the full set of periodic tasks.
 var workerLock sync.RWMutex
 
+// createTargetNetworks creates the given network definitions, previously reserved for the queue entry's placement, in its target project.
+// It returns the networks that were created, even if creating a later network failed.
+func createTargetNetworks(ctx context.Context, it target.Target, q migration.QueueEntry, defs []incusAPI.NetworksPost, reserved []api.BatchCreatedNetwork, batchName string) ([]api.BatchCreatedNetwork, error) {
+	if len(defs) == 0 {
+		return nil, nil
+	}
+
+	err := it.SetProject(q.Placement.TargetProject)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, def := range defs {
+		ctx, cancel := context.WithTimeout(ctx, it.Timeout())
+		err = it.CreateNetwork(ctx, def)
+		cancel()
+		if err != nil {
+			return reserved[:i], fmt.Errorf("Failed to create network %q on target %q in project %q: %w", def.Name, it.GetName(), q.Placement.TargetProject, err)
+		}
+
+		slog.Info("Created target network", slog.String("batch", batchName), slog.String("network", def.Name), slog.String("type", def.Type), slog.String("target", it.GetName()), slog.String("project", q.Placement.TargetProject), slog.String("source_network", reserved[i].SourceNetwork))
+	}
+
+	return reserved, nil
+}
+
+// recordCreatedNetworks records the networks created on targets for each batch, so that they are removed when the batch is reset.
+func (d *Daemon) recordCreatedNetworks(ctx context.Context, createdNetworks map[string][]api.BatchCreatedNetwork) error {
+	for batchName, networks := range createdNetworks {
+		if len(networks) == 0 {
+			continue
+		}
+
+		var batch *migration.Batch
+		err := transaction.Do(ctx, func(ctx context.Context) error {
+			var err error
+			batch, err = d.batch.AddCreatedNetworks(ctx, batchName, networks)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to record networks created for batch %q: %w", batchName, err)
+		}
+
+		d.logHandler.SendLifecycle(ctx, event.NewBatchEvent(event.BatchModified, nil, batch.ToAPI(nil), batch.Name))
+	}
+
+	return nil
+}
+
 // beginImports creates the target VMs for started batches.
 // It fetches all RUNNING batches with WAITING or BLOCKED instances, and moves the instances to CREATING state.
 // Errors encountered in one batch do not affect the processing of other batches.
@@ -254,6 +356,8 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 		return err
 	}
 
+	// Networks created on targets are recorded in a separate transaction, so that they can still be cleaned up if placement fails.
+	createdNetworks := map[string][]api.BatchCreatedNetwork{}
 	err = transaction.Do(ctx, func(ctx context.Context) error {
 		migrationState, err = d.queueHandler.GetMigrationState(ctx, api.BATCHSTATUS_RUNNING, api.MIGRATIONSTATUS_WAITING)
 		if err != nil {
@@ -277,7 +381,9 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 			return fmt.Errorf("Failed to get all targets: %w", err)
 		}
 
-		targetInfo := make([]target.IncusDetails, 0, len(allTargets))
+		targetInfo := make(map[string]*target.IncusDetails, len(allTargets))
+		targetClients := make(map[string]target.Target, len(allTargets))
+		targetLocks := make(map[string]*sync.Mutex, len(allTargets))
 		for _, t := range allTargets {
 			it, err := target.NewTarget(t.ToAPI())
 			if err != nil {
@@ -298,7 +404,9 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 				return err
 			}
 
-			targetInfo = append(targetInfo, *info)
+			targetInfo[info.Name] = info
+			targetClients[info.Name] = it
+			targetLocks[info.Name] = &sync.Mutex{}
 		}
 
 		placementLock := sync.Mutex{}
@@ -329,20 +437,49 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 					placementLock.Unlock()
 				}
 
-				var info *target.IncusDetails
-				for _, t := range targetInfo {
-					if t.Name == entry.Placement.TargetName {
-						info = &t
-						break
+				// Verify that the target placement actually exists and the instance can be placed there.
+				// Capacity and networks are reserved for each placed instance, so that instances placed on the same target in this pass account for each other.
+				placementLock.Lock()
+				info := targetInfo[entry.Placement.TargetName]
+				var defs []incusAPI.NetworksPost
+				var reserved []api.BatchCreatedNetwork
+				if state.Batch.Config.CreateTargetNetworks && info != nil {
+					var err error
+					defs, reserved, err = info.ReserveNetworks(entry, instance, allNetworks, state.Batch)
+					if err != nil {
+						placementErrs[instUUID] = err
+						placementLock.Unlock()
+						return nil
 					}
 				}
 
-				// Verify that the target placement actually exists and the instance can be placed there.
 				err := target.CanPlaceInstance(ctx, info, entry, instance.ToAPI(), state.Batch.ToAPI(nil))
 				if err != nil {
-					placementLock.Lock()
+					info.ReleaseNetworks(reserved)
 					placementErrs[instUUID] = err
 					placementLock.Unlock()
+					return nil
+				}
+
+				info.ReserveCapacity(entry, instance.ToAPI())
+				targetLock := targetLocks[info.Name]
+				placementLock.Unlock()
+
+				if len(defs) == 0 {
+					return nil
+				}
+
+				// Create the networks outside of the placement lock, but only one instance at a time per target as the client is shared.
+				targetLock.Lock()
+				created, err := createTargetNetworks(ctx, targetClients[info.Name], entry, defs, reserved, batchName)
+				targetLock.Unlock()
+
+				placementLock.Lock()
+				defer placementLock.Unlock()
+				createdNetworks[batchName] = append(createdNetworks[batchName], created...)
+				if err != nil {
+					info.ReleaseNetworks(reserved[len(created):])
+					placementErrs[instUUID] = err
 				}
 
 				return nil
@@ -354,7 +491,8 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 
 		// Write-lock the DB here after running the scriptlets and fetching target info.
 		var stateChanged bool
-		for _, state := range migrationState {
+		for batchName, state := range migrationState {
+			blocked := 0
 			for instUUID, q := range state.QueueEntries {
 				// Update the db record for any changed placements.
 				if state.Batch.Config.RerunScriptlets {
@@ -369,6 +507,7 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 				err, ok := placementErrs[instUUID]
 				if ok {
 					stateChanged = true
+					blocked++
 					blockedMsg := fmt.Sprintf("Cannot place instance: %v", err.Error())
 					_, err := d.queue.UpdateStatusByUUID(ctx, q.InstanceUUID, api.MIGRATIONSTATUS_BLOCKED, blockedMsg, q.ImportStage, q.GetWindowName())
 					if err != nil {
@@ -376,6 +515,19 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 					}
 				}
 			}
+
+			// Report on the batch when instances are waiting for their target, rather than waiting silently.
+			statusMsg := string(api.BATCHSTATUS_RUNNING)
+			if blocked > 0 {
+				statusMsg = fmt.Sprintf("Waiting for %d instances that cannot be placed on their target", blocked)
+			}
+
+			if state.Batch.StatusMessage != statusMsg {
+				_, err := d.batch.UpdateStatusByName(ctx, batchName, api.BATCHSTATUS_RUNNING, statusMsg)
+				if err != nil {
+					return fmt.Errorf("Failed to update status of batch %q: %w", batchName, err)
+				}
+			}
 		}
 
 		if stateChanged {
@@ -388,6 +540,13 @@ func (d *Daemon) beginImports(ctx context.Context, cleanupInstances bool) error
 
 		return nil
 	})
+
+	recordErr := d.recordCreatedNetworks(ctx, createdNetworks)
+	if recordErr != nil {
+		// The networks exist on the target regardless, so report them rather than failing silently.
+		log.Error("Failed to record created target networks, they must be removed manually", slog.Any("networks", createdNetworks), logger.Err(recordErr))
+	}
+
 	if err != nil {
 		return err
 	}
@@ -587,6 +746,53 @@ func (d *Daemon) ensureISOImagesExistInStoragePool(ctx context.Context, instance
 // vmCreateLock is a lock to allow serialized creation of VMs, to get around Incus placement issues. This set of locks is keyed by target name.
 var vmCreateLock util.IDLock[string] = util.NewIDLock[string]()
 
+// checkInstanceRename ensures the name set by the instance configuration scriptlet is valid, and that no other instance uses it in the target project.
+// As with placement, instances on the target are checked, and so are queued instances placed in the same project that may not have been created yet.
+func (d *Daemon) checkInstanceRename(ctx context.Context, it target.Target, inst migration.Instance, q migration.QueueEntry, newName string) error {
+	err := validate.IsHostname(newName)
+	if err != nil {
+		return fmt.Errorf("Name %q is not a valid hostname: %w", newName, err)
+	}
+
+	names, err := it.GetInstanceNames()
+	if err != nil {
+		return fmt.Errorf("Failed to get instances on target %q: %w", it.GetName(), err)
+	}
+
+	if slices.Contains(names, newName) {
+		return fmt.Errorf("Instance already exists with name %q on target %q in project %q", newName, q.Placement.TargetName, q.Placement.TargetProject)
+	}
+
+	var queued migration.Instances
+	err = transaction.Do(ctx, func(ctx context.Context) error {
+		entries, err := d.queue.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		samePlacement := migration.QueueEntries{}
+		for _, entry := range entries {
+			if entry.InstanceUUID != inst.UUID && entry.Placement.TargetName == q.Placement.TargetName && entry.Placement.TargetProject == q.Placement.TargetProject {
+				samePlacement = append(samePlacement, entry)
+			}
+		}
+
+		queued, err = d.instance.GetAllQueued(ctx, samePlacement)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to get queued instances: %w", err)
+	}
+
+	for _, other := range queued {
+		if other.GetName() == newName {
+			return fmt.Errorf("Queued instance %q is also placed with name %q on target %q in project %q", other.Properties.Location, newName, q.Placement.TargetName, q.Placement.TargetProject)
+		}
+	}
+
+	return nil
+}
+
 // Concurrently create target VMs for each instance record.
 // Any instance that fails the migration has its state set to ERROR.
 // - cleanupInstances determines whether a target VM should be deleted if it encounters an error.
@@ -668,6 +874,33 @@ func (d *Daemon) createTargetVM(ctx context.Context, b migration.Batch, inst mig
 		return fmt.Errorf("Failed to create instance definition: %w", err)
 	}
 
+	err = b.ApplyDefaultInstanceConfig(inst, &instanceDef.InstancePut)
+	if err != nil {
+		return fmt.Errorf("Failed to apply batch instance defaults: %w", err)
+	}
+
+	newName, err := d.batch.ApplyInstanceConfig(ctx, inst, usedNetworks, b, &instanceDef.InstancePut)
+	if err != nil {
+		return fmt.Errorf("Failed to apply instance configuration scriptlet: %w", err)
+	}
+
+	// Persist a new name as an override, as the target instance is looked up by name for the rest of the migration.
+	if newName != "" && newName != inst.GetName() {
+		err := d.checkInstanceRename(ctx, it, inst, q, newName)
+		if err != nil {
+			return fmt.Errorf("Instance configuration scriptlet cannot rename instance %q: %w", inst.GetName(), err)
+		}
+
+		log.Info("Renaming instance from instance configuration scriptlet", slog.String("name", newName))
+		inst.Overrides.Name = newName
+		err = d.instance.Update(ctx, &inst, true)
+		if err != nil {
+			return fmt.Errorf("Failed to rename instance to %q: %w", newName, err)
+		}
+
+		instanceDef.Name = newName
+	}
+
 	// Add a lock for this particular target, so each instance create operation on it is processed serially.
 	vmCreateLock.Lock(t.Name)
 	op, cleanup, err := it.CreateNewVM(timeoutCtx, inst, instanceDef, q.Placement, util.WorkerVolume(inst.GetArchitecture()))
@@ -718,6 +951,30 @@ func (d *Daemon) createTargetVM(ctx context.Context, b migration.Batch, inst mig
 		return fmt.Errorf("Failed to setup instance %q on migration target %q: %w", instanceDef.Name, it.GetName(), err)
 	}
 
+	// Recreated volumes hold none of the previously copied data, so only the change IDs of volumes that still exist are kept.
+	if len(q.ChangeIDs) > 0 {
+		volumes, err := it.GetVolumeUUIDs(timeoutCtx, instanceDef.Name)
+		if err != nil {
+			return fmt.Errorf("Failed to get storage volumes of instance %q on migration target %q: %w", instanceDef.Name, it.GetName(), err)
+		}
+
+		if q.RetainChangeIDs(volumes) {
+			err = transaction.Do(ctx, func(ctx context.Context) error {
+				entry, err := d.queue.GetByInstanceUUID(ctx, inst.UUID)
+				if err != nil {
+					return err
+				}
+
+				entry.ChangeIDs = q.ChangeIDs
+
+				return d.queue.Update(ctx, entry)
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to reset disk change IDs for instance %q: %w", instanceDef.Name, err)
+			}
+		}
+	}
+
 	var window migration.Window
 	if q.GetWindowName() != nil {
 		window = windows[*q.GetWindowName()]
@@ -756,10 +1013,10 @@ func (d *Daemon) createTargetVM(ctx context.Context, b migration.Batch, inst mig
 	return nil
 }
 
-// resetQueueEntry starts up the source VM, and sets the queue entry to an earlier step, in the event of a migration window deadline.
-// - If the deadline was reached during final import, then it is reset to IDLE and the worker is restarted.
-// - If the deadline was reached during post-import, then the target VM is deleted and the queue entry is reset to WAITING for a new instance creation.
-func (d *Daemon) resetQueueEntry(ctx context.Context, instUUID uuid.UUID, state queue.MigrationState) error {
+// resetQueueEntry starts up the source VM, and sets the queue entry to an earlier step, in the event of a migration window deadline or a failed instance group member.
+// - If the reset happens during final import, then it is reset to IDLE and the worker is restarted.
+// - If the reset happens during post-import, then the target VM is deleted and the queue entry is reset to WAITING for a new instance creation.
+func (d *Daemon) resetQueueEntry(ctx context.Context, instUUID uuid.UUID, state queue.MigrationState, reason string) error {
 	log := slog.With(
 		slog.String("method", "resetQueueEntry"),
 		slog.String("target", state.Targets[instUUID].Name),
@@ -811,10 +1068,10 @@ func (d *Daemon) resetQueueEntry(ctx context.Context, instUUID uuid.UUID, state
 	if state.QueueEntries[instUUID].MigrationStatus != api.MIGRATIONSTATUS_FINAL_IMPORT {
 		resetState = api.MIGRATIONSTATUS_WAITING
 		resetImportStage = migration.IMPORTSTAGE_BACKGROUND
-		log.Warn("Cleaning up target instance due to migration window deadline")
+		log.Warn("Cleaning up target instance", slog.String("reason", reason))
 		err := it.CleanupVM(timeoutCtx, state.Instances[instUUID].GetName(), false)
 		if err != nil {
-			return fmt.Errorf("Failed to clean up instance %q due to migration window deadline: %w", state.Instances[instUUID].Properties.Location, err)
+			return fmt.Errorf("Failed to clean up instance %q for reset: %w", state.Instances[instUUID].Properties.Location, err)
 		}
 	} else {
 		// Stop the migration worker so it doesn't interfere with our state cleanup.
@@ -825,7 +1082,6 @@ func (d *Daemon) resetQueueEntry(ctx context.Context, instUUID uuid.UUID, state
 	}
 
 	// Set the migration state to an earlier step.
-	reason := "Migration window ended, waiting for next migration window"
 	_, err = d.queue.UpdateStatusByUUID(ctx, instUUID, resetState, reason, resetImportStage, nil)
 	if err != nil {
 		return fmt.Errorf("Failed to reset queue entry %q status: %w", instUUID, err)
@@ -833,7 +1089,7 @@ func (d *Daemon) resetQueueEntry(ctx context.Context, instUUID uuid.UUID, state
 
 	// Restart the migration worker if the instance is still running.
 	if state.QueueEntries[instUUID].MigrationStatus == api.MIGRATIONSTATUS_FINAL_IMPORT {
-		log.Warn("Restarting migration worker due to migration window deadline")
+		log.Warn("Restarting migration worker", slog.String("reason", reason))
 		err := it.Exec(timeoutCtx, state.Instances[instUUID].GetName(), []string{"systemctl", "restart", "migration-manager-worker.service"})
 		if err != nil {
 			return fmt.Errorf("Failed to restart migration worker on restarting instance %q: %w", state.Instances[instUUID].Properties.Location, err)
@@ -851,25 +1107,68 @@ func (d *Daemon) finalizeCompleteInstances(ctx context.Context) (_err error) {
 	log := slog.With(slog.String("method", "finalizeCompleteInstances"))
 	var migrationState queue.BatchMigrationState
 
-	queueEntriesToReset := map[uuid.UUID]bool{}
+	queueEntriesToReset := map[uuid.UUID]string{}
 	windowsByQueueUUID := map[uuid.UUID]migration.Window{}
+	groupsByBatch := map[string]map[string]migration.InstanceGroupMembers{}
+	groupsByInstance := map[uuid.UUID]string{}
+	entriesByBatch := map[string]migration.QueueEntries{}
+	instancesByBatch := map[string]migration.Instances{}
+	tiersByInstance := map[uuid.UUID]*api.BatchBootTier{}
 	err := transaction.Do(ctx, func(ctx context.Context) error {
 		var err error
-		migrationState, err = d.queueHandler.GetMigrationState(ctx, api.BATCHSTATUS_RUNNING, api.MIGRATIONSTATUS_WORKER_DONE, api.MIGRATIONSTATUS_FINAL_IMPORT, api.MIGRATIONSTATUS_POST_IMPORT)
+		migrationState, err = d.queueHandler.GetMigrationState(ctx, api.BATCHSTATUS_RUNNING, api.MIGRATIONSTATUS_WORKER_DONE, api.MIGRATIONSTATUS_FINAL_IMPORT, api.MIGRATIONSTATUS_POST_IMPORT, api.MIGRATIONSTATUS_VALIDATING)
 		if err != nil {
 			return fmt.Errorf("Failed to compile migration state for final import steps: %w", err)
 		}
 
-		for _, s := range migrationState {
+		for batchName, s := range migrationState {
 			for _, q := range s.QueueEntries {
 				windowName := q.GetWindowName()
 				if windowName == nil {
 					continue
 				}
 
+				// Instances running validation checks have already been started on the target, so they are not reset.
 				windowsByQueueUUID[q.InstanceUUID] = s.Windows[*windowName]
-				if windowsByQueueUUID[q.InstanceUUID].Ended() {
-					queueEntriesToReset[q.InstanceUUID] = true
+				if windowsByQueueUUID[q.InstanceUUID].Ended() && q.MigrationStatus != api.MIGRATIONSTATUS_VALIDATING {
+					queueEntriesToReset[q.InstanceUUID] = "Migration window ended, waiting for next migration window"
+				}
+			}
+
+			entries, err := d.queue.GetAllByBatch(ctx, batchName)
+			if err != nil {
+				return fmt.Errorf("Failed to get queue entries for batch %q: %w", batchName, err)
+			}
+
+			instances, err := d.instance.GetAllQueued(ctx, entries)
+			if err != nil {
+				return fmt.Errorf("Failed to get instances for batch %q: %w", batchName, err)
+			}
+
+			groupsByBatch[batchName], err = s.Batch.GetInstanceGroups(entries, instances)
+			if err != nil {
+				return fmt.Errorf("Failed to get instance groups for batch %q: %w", batchName, err)
+			}
+
+			entriesByBatch[batchName] = entries
+			instancesByBatch[batchName] = instances
+			for _, inst := range instances {
+				tiersByInstance[inst.UUID], err = s.Batch.GetBootTier(inst)
+				if err != nil {
+					return fmt.Errorf("Failed to get boot order tier of instance %q: %w", inst.Properties.Location, err)
+				}
+			}
+
+			for groupName, members := range groupsByBatch[batchName] {
+				failed := members.Failed()
+				for _, m := range members {
+					groupsByInstance[m.Instance.UUID] = groupName
+
+					// Instance groups fail as a unit, so reset the in-progress members if any other member has failed.
+					q, ok := s.QueueEntries[m.Instance.UUID]
+					if ok && failed != nil && queueEntriesToReset[m.Instance.UUID] == "" && q.MigrationStatus != api.MIGRATIONSTATUS_VALIDATING {
+						queueEntriesToReset[m.Instance.UUID] = fmt.Sprintf("Instance group %q member %q failed, waiting for it to be retried", groupName, failed.Instance.Properties.Location)
+					}
 				}
 			}
 		}
@@ -894,12 +1193,43 @@ func (d *Daemon) finalizeCompleteInstances(ctx context.Context) (_err error) {
 		err  error
 	}
 
+	var mu sync.Mutex
 	finishedInstances := []uuid.UUID{}
 	conflictedEntries := []conflict{}
+	validating := map[uuid.UUID]bool{}
+	configure := func(instUUID uuid.UUID, state queue.MigrationState) error {
+		window := windowsByQueueUUID[instUUID]
+		isValidating, err := d.configureMigratedInstances(ctx, state.QueueEntries[instUUID], window, state.Instances[instUUID], state.Sources[instUUID], state.Targets[instUUID], state.Batch)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			conflictedEntries = append(conflictedEntries, conflict{uuid: instUUID, err: err})
+			return err
+		}
+
+		validating[instUUID] = isValidating
+		finishedInstances = append(finishedInstances, instUUID)
+		return nil
+	}
+
+	// Instances running validation checks hold back the members and boot order tiers that are configured after them.
+	isValidating := func(instUUID uuid.UUID) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return validating[instUUID]
+	}
+
 	err = util.RunConcurrentMap(migrationState, func(batchName string, state queue.MigrationState) error {
-		return util.RunConcurrentMap(state.Instances, func(instUUID uuid.UUID, instance migration.Instance) error {
-			if queueEntriesToReset[instUUID] {
-				return d.resetQueueEntry(ctx, instUUID, state)
+		instErr := util.RunConcurrentMap(state.Instances, func(instUUID uuid.UUID, instance migration.Instance) error {
+			if queueEntriesToReset[instUUID] != "" {
+				return d.resetQueueEntry(ctx, instUUID, state, queueEntriesToReset[instUUID])
+			}
+
+			// Resume validation checks that are not running, such as after a restart.
+			if state.QueueEntries[instUUID].MigrationStatus == api.MIGRATIONSTATUS_VALIDATING {
+				d.startValidationChecks(ctx, state.QueueEntries[instUUID], state.Instances[instUUID], state.Targets[instUUID], state.Batch)
+				return nil
 			}
 
 			// Skip queue entries that are still performing sync.
@@ -907,16 +1237,51 @@ func (d *Daemon) finalizeCompleteInstances(ctx context.Context) (_err error) {
 				return nil
 			}
 
-			window := windowsByQueueUUID[instUUID]
-			err := d.configureMigratedInstances(ctx, state.QueueEntries[instUUID], window, instance, state.Sources[instUUID], state.Targets[instUUID], state.Batch)
-			if err != nil {
-				conflictedEntries = append(conflictedEntries, conflict{uuid: instUUID, err: err})
-				return err
+			// Members of instance groups and boot order tiers are configured in order below.
+			if groupsByInstance[instUUID] != "" || tiersByInstance[instUUID] != nil {
+				return nil
+			}
+
+			return configure(instUUID, state)
+		})
+
+		// Once all members of an instance group are done, configure them in order, with members sharing a position configured concurrently.
+		groupErr := util.RunConcurrentMap(groupsByBatch[batchName], func(groupName string, members migration.InstanceGroupMembers) error {
+			for _, m := range members {
+				q, ok := state.QueueEntries[m.Instance.UUID]
+				if !ok {
+					q = m.QueueEntry
+				}
+
+				if queueEntriesToReset[m.Instance.UUID] != "" || (q.MigrationStatus != api.MIGRATIONSTATUS_WORKER_DONE && q.MigrationStatus != api.MIGRATIONSTATUS_FINISHED) {
+					return nil
+				}
+			}
+
+			for _, tier := range members.ByPosition() {
+				err := util.RunConcurrentList(tier, func(m migration.InstanceGroupMember) error {
+					if state.QueueEntries[m.Instance.UUID].MigrationStatus != api.MIGRATIONSTATUS_WORKER_DONE {
+						return nil
+					}
+
+					return configure(m.Instance.UUID, state)
+				})
+				if err != nil {
+					return fmt.Errorf("Failed to configure instance group %q: %w", groupName, err)
+				}
+
+				// Later members are configured once validation passed, with the next run.
+				if slices.ContainsFunc(tier, func(m migration.InstanceGroupMember) bool { return isValidating(m.Instance.UUID) }) {
+					return nil
+				}
 			}
 
-			finishedInstances = append(finishedInstances, instUUID)
 			return nil
 		})
+
+		tierErr := d.configureBootTiers(ctx, state, entriesByBatch[batchName], instancesByBatch[batchName], tiersByInstance, queueEntriesToReset, configure, isValidating)
+
+		return errors.Join(instErr, groupErr, tierErr)
 	})
 	if err != nil {
 		log.Error("Failed to configure migrated instances for all batches", slog.Any("error", err))
@@ -942,28 +1307,209 @@ func (d *Daemon) finalizeCompleteInstances(ctx context.Context) (_err error) {
 		}
 
 		for batch := range migrationState {
-			entries, err := d.queue.GetAllByBatch(ctx, batch)
+			err := d.finishCompletedBatch(ctx, batch)
 			if err != nil {
 				return err
 			}
+		}
 
-			finished := true
-			for _, entry := range entries {
-				if entry.MigrationStatus != api.MIGRATIONSTATUS_FINISHED {
-					finished = false
-					break
-				}
+		return nil
+	})
+}
+
+// finishCompletedBatch sets the running batch with the given name to FINISHED once all of its queue entries have finished.
+func (d *Daemon) finishCompletedBatch(ctx context.Context, batchName string) error {
+	return transaction.Do(ctx, func(ctx context.Context) error {
+		batch, err := d.batch.GetByName(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		if batch.Status != api.BATCHSTATUS_RUNNING {
+			return nil
+		}
+
+		entries, err := d.queue.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.MigrationStatus != api.MIGRATIONSTATUS_FINISHED {
+				return nil
+			}
+		}
+
+		_, err = d.batch.UpdateStatusByName(ctx, batchName, api.BATCHSTATUS_FINISHED, string(api.BATCHSTATUS_FINISHED))
+		if err != nil {
+			return fmt.Errorf("Failed to set batch status to %q: %w", api.BATCHSTATUS_FINISHED, err)
+		}
+
+		return nil
+	})
+}
+
+// configureBootTiers configures the instances of the batch that are assigned to a boot order tier, in ascending tier order.
+// An instance is only configured once no instance in a lower tier that is migrating in the same migration window holds it back, see QueueEntry.HoldsBackBootTier.
+// The start of each instance is persisted, so that a later run configures the next tier once the delay of the lower tiers has passed and their Incus agents have responded.
+// Nothing waits for these within a run, as it holds the worker lock.
+func (d *Daemon) configureBootTiers(ctx context.Context, state queue.MigrationState, entries migration.QueueEntries, instances migration.Instances, tiers map[uuid.UUID]*api.BatchBootTier, skip map[uuid.UUID]string, configure func(uuid.UUID, queue.MigrationState) error, validating func(uuid.UUID) bool) error {
+	instancesByUUID := make(map[uuid.UUID]migration.Instance, len(instances))
+	for _, inst := range instances {
+		instancesByUUID[inst.UUID] = inst
+	}
+
+	tierNumbers := []int{}
+	for instUUID, q := range state.QueueEntries {
+		if tiers[instUUID] != nil && q.MigrationStatus == api.MIGRATIONSTATUS_WORKER_DONE && skip[instUUID] == "" {
+			tierNumbers = append(tierNumbers, tiers[instUUID].Tier)
+		}
+	}
+
+	slices.Sort(tierNumbers)
+	tierNumbers = slices.Compact(tierNumbers)
+	if len(tierNumbers) == 0 {
+		return nil
+	}
+
+	// Check the Incus agent of each started instance at most once per run.
+	for i, e := range entries {
+		if tiers[e.InstanceUUID] == nil || e.BootStatus == nil || tiers[e.InstanceUUID].Tier >= tierNumbers[len(tierNumbers)-1] {
+			continue
+		}
+
+		if !e.AwaitingBootAgent(*tiers[e.InstanceUUID], &e.BootStatus.MigrationWindow) {
+			continue
+		}
+
+		ready, err := d.checkBootAgent(ctx, e, instancesByUUID[e.InstanceUUID])
+		if err != nil {
+			slog.Warn("Failed to check Incus agent of started instance", slog.String("instance", instancesByUUID[e.InstanceUUID].Properties.Location), logger.Err(err))
+			continue
+		}
+
+		if ready {
+			entries[i].BootStatus.AgentReady = true
+		}
+	}
+
+	var mu sync.Mutex
+	configured := map[uuid.UUID]bool{}
+	for _, tier := range tierNumbers {
+		ready := []uuid.UUID{}
+		for instUUID, q := range state.QueueEntries {
+			if tiers[instUUID] == nil || tiers[instUUID].Tier != tier || q.MigrationStatus != api.MIGRATIONSTATUS_WORKER_DONE || skip[instUUID] != "" {
+				continue
 			}
 
-			if finished {
-				_, err := d.batch.UpdateStatusByName(ctx, batch, api.BATCHSTATUS_FINISHED, string(api.BATCHSTATUS_FINISHED))
-				if err != nil {
-					return fmt.Errorf("Failed to set batch status to %q: %w", api.BATCHSTATUS_FINISHED, err)
+			blocked := slices.ContainsFunc(entries, func(e migration.QueueEntry) bool {
+				if tiers[e.InstanceUUID] == nil || tiers[e.InstanceUUID].Tier >= tier || configured[e.InstanceUUID] {
+					return false
 				}
+
+				return e.HoldsBackBootTier(instancesByUUID[e.InstanceUUID], *tiers[e.InstanceUUID], q.GetWindowName(), time.Now())
+			})
+
+			if !blocked {
+				ready = append(ready, instUUID)
 			}
 		}
 
-		return nil
+		if len(ready) == 0 {
+			continue
+		}
+
+		err := util.RunConcurrentList(ready, func(instUUID uuid.UUID) error {
+			err := configure(instUUID, state)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			configured[instUUID] = true
+			mu.Unlock()
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to configure instances in boot order tier %d: %w", tier, err)
+		}
+
+		// Later tiers are configured by a later run if the started instances hold them back.
+		holdsBack := slices.ContainsFunc(ready, func(instUUID uuid.UUID) bool {
+			return validating(instUUID) || tiers[instUUID].Delay.Duration > 0 || (tiers[instUUID].WaitForAgent && state.QueueEntries[instUUID].Placement.Running)
+		})
+
+		if holdsBack {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// checkBootAgent checks once whether the Incus agent of the started instance of the queue entry responds, and records it in the boot status of the queue entry.
+func (d *Daemon) checkBootAgent(ctx context.Context, q migration.QueueEntry, inst migration.Instance) (bool, error) {
+	var t *migration.Target
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		var err error
+		t, err = d.target.GetByName(ctx, q.Placement.TargetName)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("Failed to get target %q: %w", q.Placement.TargetName, err)
+	}
+
+	it, err := target.NewTarget(t.ToAPI())
+	if err != nil {
+		return false, fmt.Errorf("Failed to construct target %q: %w", t.Name, err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, it.Timeout())
+	defer cancel()
+
+	err = it.Connect(timeoutCtx)
+	if err != nil {
+		return false, fmt.Errorf("Failed to connect to target %q: %w", it.GetName(), err)
+	}
+
+	err = it.SetProject(q.Placement.TargetProject)
+	if err != nil {
+		return false, fmt.Errorf("Failed to set target %q project %q: %w", it.GetName(), q.Placement.TargetProject, err)
+	}
+
+	ready, err := it.IsIncusAgentRunning(timeoutCtx, inst.GetName())
+	if err != nil || !ready {
+		return false, err
+	}
+
+	err = d.updateBootStatus(ctx, q.InstanceUUID, func(status *api.BootStatus) {
+		status.AgentReady = true
+	})
+	if err != nil {
+		return false, fmt.Errorf("Failed to record Incus agent of instance %q: %w", inst.Properties.Location, err)
+	}
+
+	return true, nil
+}
+
+// updateBootStatus applies the given change to the boot status of the queue entry of the instance.
+func (d *Daemon) updateBootStatus(ctx context.Context, instUUID uuid.UUID, update func(status *api.BootStatus)) error {
+	return transaction.Do(ctx, func(ctx context.Context) error {
+		q, err := d.queue.GetByInstanceUUID(ctx, instUUID)
+		if err != nil {
+			return err
+		}
+
+		status := api.BootStatus{}
+		if q.BootStatus != nil {
+			status = *q.BootStatus
+		}
+
+		update(&status)
+		q.BootStatus = &status
+
+		return d.queue.Update(ctx, q)
 	})
 }
 
@@ -991,12 +1537,22 @@ func (d *Daemon) finalSourceAndTargetChecks(ctx context.Context, migrationState
 	instanceSourceIDs := map[string][]string{}
 	sourcesByName := map[string]migration.Source{}
 	batchesByInstance := map[uuid.UUID]string{}
+	bootTiersBySourceID := map[string]int{}
 	for _, s := range migrationState {
 		for _, inst := range s.Instances {
 			if s.QueueEntries[inst.UUID].MigrationStatus != api.MIGRATIONSTATUS_WORKER_DONE {
 				continue
 			}
 
+			tier, err := s.Batch.GetBootTier(inst)
+			if err != nil {
+				return fmt.Errorf("Failed to get boot order tier of instance %q: %w", inst.Properties.Location, err)
+			}
+
+			if tier != nil {
+				bootTiersBySourceID[inst.Source+"/"+inst.Properties.SourceSpecificID] = tier.Tier
+			}
+
 			batchesByInstance[inst.UUID] = s.Batch.Name
 			instancesByUUID[inst.UUID] = inst
 			if instanceSourceIDs[inst.Source] == nil {
@@ -1059,6 +1615,11 @@ func (d *Daemon) finalSourceAndTargetChecks(ctx context.Context, migrationState
 		srcNetworks = append(srcNetworks, networks...)
 	}
 
+	// Source VMs that are still running are powered off in descending boot order tier.
+	slices.SortStableFunc(srcInsts, func(a migration.Instance, b migration.Instance) int {
+		return bootTiersBySourceID[b.Source+"/"+b.Properties.SourceSpecificID] - bootTiersBySourceID[a.Source+"/"+a.Properties.SourceSpecificID]
+	})
+
 	type conflictState struct {
 		status     api.MigrationStatusType
 		message    string
@@ -1325,8 +1886,8 @@ func (d *Daemon) finalSourceAndTargetChecks(ctx context.Context, migrationState
 }
 
 // configureMigratedInstances updates the configuration of instances concurrently after they have finished migrating. Errors will result in the instance state becoming ERRORED.
-// If an instance succeeds, its state will be moved to FINISHED.
-func (d *Daemon) configureMigratedInstances(ctx context.Context, q migration.QueueEntry, w migration.Window, i migration.Instance, s migration.Source, t migration.Target, batch migration.Batch) (_err error) {
+// If an instance succeeds, its state will be moved to FINISHED, or to VALIDATING if the batch has validation checks to run in the background, in which case true is returned.
+func (d *Daemon) configureMigratedInstances(ctx context.Context, q migration.QueueEntry, w migration.Window, i migration.Instance, s migration.Source, t migration.Target, batch migration.Batch) (_ bool, _err error) {
 	log := slog.With(
 		slog.String("method", "configureMigratedInstances"),
 		slog.String("target", t.Name),
@@ -1387,7 +1948,7 @@ func (d *Daemon) configureMigratedInstances(ctx context.Context, q migration.Que
 
 	it, err := target.NewTarget(t.ToAPI())
 	if err != nil {
-		return fmt.Errorf("Failed to construct target %q: %w", t.Name, err)
+		return false, fmt.Errorf("Failed to construct target %q: %w", t.Name, err)
 	}
 
 	timeoutCtx, cancel := context.WithTimeout(ctx, it.Timeout())
@@ -1396,27 +1957,95 @@ func (d *Daemon) configureMigratedInstances(ctx context.Context, q migration.Que
 	// Connect to the target.
 	err = it.Connect(timeoutCtx)
 	if err != nil {
-		return fmt.Errorf("Failed to connect to target %q: %w", it.GetName(), err)
+		return false, fmt.Errorf("Failed to connect to target %q: %w", it.GetName(), err)
 	}
 
 	// Set the project.
 	err = it.SetProject(q.Placement.TargetProject)
 	if err != nil {
-		return fmt.Errorf("Failed to set target %q project %q: %w", it.GetName(), q.Placement.TargetProject, err)
+		return false, fmt.Errorf("Failed to set target %q project %q: %w", it.GetName(), q.Placement.TargetProject, err)
+	}
+
+	var networks migration.Networks
+	if batch.Config.MigrateNetworkACLs || batch.Config.InstanceConfigScriptlet != "" {
+		err := transaction.Do(ctx, func(ctx context.Context) error {
+			var err error
+			networks, err = d.network.GetAllBySource(ctx, i.Source)
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("Failed to get networks for source %q: %w", i.Source, err)
+		}
+	}
+
+	var acls *api.InstanceNetworkACLs
+	if batch.Config.MigrateNetworkACLs {
+		acls, err = i.NSXNetworkACLs(networks)
+		if err != nil {
+			return false, fmt.Errorf("Failed to translate network ACLs for instance %q: %w", i.GetName(), err)
+		}
+
+		for _, warning := range acls.Warnings {
+			log.Warn("Network ACL translation incomplete", slog.String("warning", warning))
+		}
 	}
 
-	err = it.SetPostMigrationVMConfig(timeoutCtx, i, q)
+	// Re-apply the batch instance defaults and configuration scriptlet, as post-migration configuration replaces profiles, NICs and resource limits.
+	usedNetworks := migration.FilterUsedNetworks(networks, migration.Instances{i})
+	configure := func(def *incusAPI.InstancePut) error {
+		err := batch.ApplyDefaultInstanceConfig(i, def)
+		if err != nil {
+			return err
+		}
+
+		_, err = d.batch.ApplyInstanceConfig(ctx, i, usedNetworks, batch, def)
+		return err
+	}
+
+	err = it.SetPostMigrationVMConfig(timeoutCtx, i, q, acls, configure)
+	if err != nil {
+		return false, fmt.Errorf("Failed to update post-migration config for instance %q in %q: %w", i.GetName(), it.GetName(), err)
+	}
+
+	err = d.runFirstBootScripts(ctx, it, i, q, batch)
 	if err != nil {
-		return fmt.Errorf("Failed to update post-migration config for instance %q in %q: %w", i.GetName(), it.GetName(), err)
+		return false, fmt.Errorf("Failed to run post-migration scripts for instance %q in %q: %w", i.GetName(), it.GetName(), err)
+	}
+
+	// Record the start of the instance, which holds back later boot order tiers of the batch.
+	err = d.updateBootStatus(ctx, i.UUID, func(status *api.BootStatus) {
+		*status = api.BootStatus{StartedAt: time.Now().UTC()}
+		if q.GetWindowName() != nil {
+			status.MigrationWindow = *q.GetWindowName()
+		}
+	})
+	if err != nil {
+		return false, fmt.Errorf("Failed to record start of instance %q: %w", i.GetName(), err)
+	}
+
+	// Validation checks run inside the instance, so they are skipped if the instance is not started after migration.
+	// The checks are retried until their timeouts, so they run in the background, without holding the worker lock.
+	if len(batch.Config.ValidationChecks) > 0 && q.Placement.Running {
+		newQ, err := d.queue.UpdateStatusByUUID(ctx, i.UUID, api.MIGRATIONSTATUS_VALIDATING, string(api.MIGRATIONSTATUS_VALIDATING), q.ImportStage, q.GetWindowName())
+		if err != nil {
+			return false, fmt.Errorf("Failed to update instance status to %q: %w", api.MIGRATIONSTATUS_VALIDATING, err)
+		}
+
+		// Failed validation is not retried, and the source VM is only powered on again if the migration is rolled back.
+		reverter.Success()
+
+		d.startValidationChecks(ctx, *newQ, i, t, batch)
+
+		return true, nil
 	}
 
 	// Update the instance status to finished, and remove its migration window.
 	_, err = d.queue.UpdateStatusByUUID(ctx, i.UUID, api.MIGRATIONSTATUS_FINISHED, string(api.MIGRATIONSTATUS_FINISHED), q.ImportStage, nil)
 	if err != nil {
-		return fmt.Errorf("Failed to update instance status to %q: %w", api.MIGRATIONSTATUS_FINISHED, err)
+		return false, fmt.Errorf("Failed to update instance status to %q: %w", api.MIGRATIONSTATUS_FINISHED, err)
 	}
 
 	reverter.Success()
 
-	return nil
+	return false, nil
 }
@@ -6,6 +6,7 @@ require (
 	github.com/Masterminds/sprig/v3 v3.2.2
 	github.com/Microsoft/go-winio v0.6.2
 	github.com/Rican7/retry v0.3.1
+	github.com/adhocore/gronx v1.19.6
 	github.com/expr-lang/expr v1.17.2
 	github.com/flosch/pongo2/v4 v4.0.2
 	github.com/fvbommel/sortorder v1.1.0
@@ -48,7 +49,6 @@ require (
 	github.com/AdaLogics/go-fuzz-headers v0.0.0-20240806141605-e8a1dd7889d6 // indirect
 	github.com/Masterminds/goutils v1.1.1 // indirect
 	github.com/Masterminds/semver/v3 v3.1.1 // indirect
-	github.com/adhocore/gronx v1.19.6 // indirect
 	github.com/antlr4-go/antlr/v4 v4.13.1 // indirect
 	github.com/apex/log v1.9.0 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
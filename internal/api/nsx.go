@@ -10,10 +10,12 @@ import (
 type NSXSourceProperties struct {
 	api.VMwareProperties `yaml:",inline"`
 
-	ComputeManagers []NSXComputeManager    `json:"compute_managers" yaml:"compute_managers"`
-	Segments        []NSXSegment           `json:"segments"         yaml:"segments"`
-	EdgeNodes       []NSXEdgeTransportNode `json:"edge_nodes"       yaml:"edge_nodes"`
-	Policies        []NSXSecurityPolicy    `json:"policies"         yaml:"policies"`
+	ComputeManagers []NSXComputeManager    `json:"compute_managers"   yaml:"compute_managers"`
+	Segments        []NSXSegment           `json:"segments"           yaml:"segments"`
+	EdgeNodes       []NSXEdgeTransportNode `json:"edge_nodes"         yaml:"edge_nodes"`
+	Policies        []NSXSecurityPolicy    `json:"policies"           yaml:"policies"`
+	Groups          []NSXGroup             `json:"groups,omitempty"   yaml:"groups,omitempty"`
+	Services        []NSXService           `json:"services,omitempty" yaml:"services,omitempty"`
 }
 
 // VCenterNetworkProperties is the set of network properties we can obtain from vCenter.
@@ -29,6 +31,16 @@ type NSXNetworkProperties struct {
 	Source        string           `json:"source"         yaml:"source"`
 	Segment       NSXSegment       `json:"segment"        yaml:"segment"`
 	TransportZone NSXTransportZone `json:"transport_zone" yaml:"transport_zone"`
+
+	// Distributed firewall configuration that applies to VMs on the segment.
+	Firewall *NSXDistributedFirewall `json:"firewall,omitempty" yaml:"firewall,omitempty"`
+}
+
+// NSXDistributedFirewall is the set of distributed firewall policies, along with the groups and services referenced by their rules.
+type NSXDistributedFirewall struct {
+	Policies []NSXSecurityPolicy `json:"policies" yaml:"policies"`
+	Groups   []NSXGroup          `json:"groups"   yaml:"groups"`
+	Services []NSXService        `json:"services" yaml:"services"`
 }
 
 // NSXSegment is an NSX segment from /policy/api/v1/{path/to/segmentID}.
@@ -176,8 +188,46 @@ type NSXDomain struct {
 
 // NSXSecurityPolicy is an NSX security policy from /policy/api/v1/{path/to/domain}/security-policies.
 type NSXSecurityPolicy struct {
-	Domain string    `json:"domain" yaml:"domain"`
-	Rules  []NSXRule `json:"rules"  yaml:"rules"`
+	Domain         string    `json:"domain"                    yaml:"domain"`
+	ID             string    `json:"id"                        yaml:"id"`
+	Name           string    `json:"display_name"              yaml:"display_name"`
+	Path           string    `json:"path"                      yaml:"path"`
+	Category       string    `json:"category,omitempty"        yaml:"category,omitempty"`
+	SequenceNumber int       `json:"sequence_number,omitempty" yaml:"sequence_number,omitempty"`
+	Scope          []string  `json:"scope"                     yaml:"scope"`
+	Rules          []NSXRule `json:"rules"                     yaml:"rules"`
+}
+
+// NSXGroup is an NSX group from /policy/api/v1/{path/to/group}, along with its effective members.
+type NSXGroup struct {
+	Path        string      `json:"path"         yaml:"path"`
+	Name        string      `json:"display_name" yaml:"display_name"`
+	IPAddresses []string    `json:"ip_addresses" yaml:"ip_addresses"`
+	VMs         []uuid.UUID `json:"vms"          yaml:"vms"`
+}
+
+// NSXService is an NSX service from /policy/api/v1/{path/to/service}.
+type NSXService struct {
+	Path    string            `json:"path"            yaml:"path"`
+	Name    string            `json:"display_name"    yaml:"display_name"`
+	Entries []NSXServiceEntry `json:"service_entries" yaml:"service_entries"`
+}
+
+// NSXServiceEntry is a sub-property of NSXService.
+type NSXServiceEntry struct {
+	Type             string   `json:"resource_type"               yaml:"resource_type"`
+	Name             string   `json:"display_name"                yaml:"display_name"`
+	L4Protocol       string   `json:"l4_protocol,omitempty"       yaml:"l4_protocol,omitempty"`
+	SourcePorts      []string `json:"source_ports,omitempty"      yaml:"source_ports,omitempty"`
+	DestinationPorts []string `json:"destination_ports,omitempty" yaml:"destination_ports,omitempty"`
+	Protocol         string   `json:"protocol,omitempty"          yaml:"protocol,omitempty"`
+	ICMPType         *int     `json:"icmp_type,omitempty"         yaml:"icmp_type,omitempty"`
+	ICMPCode         *int     `json:"icmp_code,omitempty"         yaml:"icmp_code,omitempty"`
+}
+
+// NSXGroupMember is an NSX virtual machine group member from /policy/api/v1/{path/to/group}/members/virtual-machines.
+type NSXGroupMember struct {
+	UUID uuid.UUID `json:"external_id" yaml:"external_id"`
 }
 
 // NSXRule is a combined object of all NSX security rules, used by NSXSecurityPolicy and NSXGatewayPolicy.
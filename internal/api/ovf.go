@@ -0,0 +1,6 @@
+package api
+
+// OVFNetworkProperties is the set of network properties we can obtain from the network section of an OVF descriptor.
+type OVFNetworkProperties struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
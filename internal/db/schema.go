@@ -24,6 +24,10 @@ CREATE TABLE "batches" (
     start_date         DATETIME NOT NULL,
     defaults           TEXT NOT NULL,
     config             TEXT NOT NULL,
+    created_networks TEXT NOT NULL DEFAULT 'null',
+    migration_window_schedules TEXT NOT NULL DEFAULT 'null',
+    instance_groups TEXT NOT NULL DEFAULT 'null',
+    migration_window_exclusions TEXT NOT NULL DEFAULT 'null',
     UNIQUE (name)
 );
 CREATE TABLE "instances" (
@@ -51,6 +55,7 @@ CREATE TABLE "migration_windows" (
     end      DATETIME NOT NULL,
     batch_id INTEGER NOT NULL,
     config   TEXT NOT NULL,
+    schedule TEXT NOT NULL DEFAULT '',
     UNIQUE(start, end, lockout, batch_id),
     UNIQUE(name, batch_id),
     FOREIGN KEY(batch_id) REFERENCES batches(id) ON DELETE CASCADE
@@ -80,16 +85,34 @@ CREATE TABLE "queue" (
     migration_window_id              INTEGER,
     placement                        TEXT NOT NULL,
     last_background_sync             DATETIME NOT NULL,
+    transfer_progress TEXT NOT NULL DEFAULT 'null',
+    script_results TEXT NOT NULL DEFAULT 'null',
+    change_ids TEXT NOT NULL DEFAULT 'null',
+    verification_results TEXT NOT NULL DEFAULT 'null',
+    boot_status TEXT NOT NULL DEFAULT 'null',
     FOREIGN KEY(migration_window_id) REFERENCES migration_windows(id),
     FOREIGN KEY(instance_id)         REFERENCES instances(id) ON DELETE CASCADE,
     FOREIGN KEY(batch_id)            REFERENCES batches(id) ON DELETE CASCADE,
     UNIQUE (instance_id)
 );
+CREATE TABLE queue_history (
+    id                       INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_uuid            TEXT NOT NULL,
+    batch_name               TEXT NOT NULL,
+    import_stage             TEXT NOT NULL,
+    migration_status         TEXT NOT NULL,
+    migration_status_message TEXT NOT NULL,
+    last_worker_status       INTEGER NOT NULL,
+    migration_window_name    TEXT NOT NULL,
+    date                     DATETIME NOT NULL
+);
+CREATE INDEX queue_history_instance_uuid_idx ON queue_history (instance_uuid);
 CREATE TABLE sources (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name VARCHAR(255) NOT NULL,
     source_type TEXT NOT NULL,
     properties TEXT NOT NULL,
+    sync_status TEXT NOT NULL DEFAULT 'null',
     UNIQUE (name)
 );
 CREATE TABLE targets (
@@ -116,5 +139,5 @@ CREATE TABLE warnings (
     UNIQUE (type, scope, entity_type, entity)
 	);
 
-INSERT INTO schema (version, updated_at) VALUES (18, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (29, strftime("%s"))
 `
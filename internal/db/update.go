@@ -79,6 +79,97 @@ var updates = map[int]schema.Update{
 	16: updateFromV15,
 	17: updateFromV16,
 	18: updateFromV17,
+	19: updateFromV18,
+	20: updateFromV19,
+	21: updateFromV20,
+	22: updateFromV21,
+	23: updateFromV22,
+	24: updateFromV23,
+	25: updateFromV24,
+	26: updateFromV25,
+	27: updateFromV26,
+	28: updateFromV27,
+	29: updateFromV28,
+}
+
+func updateFromV28(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE batches ADD COLUMN migration_window_exclusions TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV27(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE queue ADD COLUMN boot_status TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV26(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE sources ADD COLUMN sync_status TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV25(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE batches ADD COLUMN instance_groups TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV24(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE batches ADD COLUMN migration_window_schedules TEXT NOT NULL DEFAULT 'null';
+ALTER TABLE migration_windows ADD COLUMN schedule TEXT NOT NULL DEFAULT '';`)
+
+	return err
+}
+
+func updateFromV23(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE queue ADD COLUMN verification_results TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV22(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE queue ADD COLUMN change_ids TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV21(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE queue ADD COLUMN script_results TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV20(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE batches ADD COLUMN created_networks TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV19(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE queue ADD COLUMN transfer_progress TEXT NOT NULL DEFAULT 'null';`)
+
+	return err
+}
+
+func updateFromV18(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE queue_history (
+    id                       INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_uuid            TEXT NOT NULL,
+    batch_name               TEXT NOT NULL,
+    import_stage             TEXT NOT NULL,
+    migration_status         TEXT NOT NULL,
+    migration_status_message TEXT NOT NULL,
+    last_worker_status       INTEGER NOT NULL,
+    migration_window_name    TEXT NOT NULL,
+    date                     DATETIME NOT NULL
+);
+
+CREATE INDEX queue_history_instance_uuid_idx ON queue_history (instance_uuid);
+`)
+
+	return err
 }
 
 func updateFromV17(ctx context.Context, tx *sql.Tx) error {
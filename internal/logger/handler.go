@@ -14,6 +14,7 @@ type Handler struct {
 
 	handlers []slog.Handler
 	options  slog.HandlerOptions
+	stream   *eventStream
 }
 
 // NewLogHandler creates a new log handler with the given default options, level, and sub-handlers.
@@ -24,10 +25,13 @@ func NewLogHandler(level slog.Level, options slog.HandlerOptions) *Handler {
 		options.Level = &leveler
 	}
 
+	stream := newEventStream()
+
 	return &Handler{
 		LevelVar: &leveler,
 		options:  options,
-		handlers: []slog.Handler{},
+		handlers: []slog.Handler{stream},
+		stream:   stream,
 	}
 }
 
@@ -35,6 +39,12 @@ func (h *Handler) AddHandler(handler slog.Handler) {
 	h.handlers = append(h.handlers, handler)
 }
 
+// AddListener registers a new event listener receiving events of the given scopes.
+// Logging events are only delivered if they are at or above the given level.
+func (h *Handler) AddListener(level slog.Level, scopes []api.LogScope) *EventListener {
+	return h.stream.addListener(level, scopes)
+}
+
 // SetHandlers replaces the log handler set with additional config-based handlers, keeping any default handlers.
 func (h *Handler) SetHandlers(cfgs []api.SystemSettingsLog) error {
 	newHandlers := []slog.Handler{}
@@ -44,6 +54,8 @@ func (h *Handler) SetHandlers(cfgs []api.SystemSettingsLog) error {
 			newHandlers = append(newHandlers, h)
 		case *slog.TextHandler:
 			newHandlers = append(newHandlers, h)
+		case *eventStream:
+			newHandlers = append(newHandlers, h)
 		}
 	}
 
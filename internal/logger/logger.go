@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -46,6 +47,52 @@ func (h *Handler) SendLifecycle(ctx context.Context, event api.EventLifecycle) {
 	wg.Wait()
 }
 
+// newEvent converts the log record into an event of the lifecycle or logging scope.
+func newEvent(r slog.Record) (api.Event, error) {
+	event := api.Event{Time: r.Time.UTC()}
+
+	if r.Message != string(api.LogScopeLifecycle) {
+		ctxMap := map[string]string{}
+		r.Attrs(func(a slog.Attr) bool {
+			ctxMap[a.Key] = a.Value.String()
+			return true
+		})
+
+		b, err := json.Marshal(api.EventLogging{
+			Message: r.Message,
+			Level:   r.Level.String(),
+			Context: ctxMap,
+		})
+		if err != nil {
+			return api.Event{}, err
+		}
+
+		event.Type = api.LogScopeLogging
+		event.Metadata = b
+	} else {
+		var b []byte
+		var err error
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "event" {
+				b, err = json.Marshal(a.Value.Any())
+				if err != nil {
+					return false
+				}
+			}
+
+			return true
+		})
+		if err != nil {
+			return api.Event{}, err
+		}
+
+		event.Type = api.LogScopeLifecycle
+		event.Metadata = b
+	}
+
+	return event, nil
+}
+
 func InitLogger(filepath string, verbose bool, debug bool) (*Handler, error) {
 	level := slog.LevelWarn
 	if verbose {
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// eventListenerBufferSize is the number of events buffered per listener before new events are dropped.
+const eventListenerBufferSize = 256
+
+// EventListener receives events of the configured scopes from the log handler until it is closed.
+type EventListener struct {
+	stream *eventStream
+	level  slog.Level
+	scopes []api.LogScope
+
+	events chan api.Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events returns the channel over which events are delivered to the listener.
+func (l *EventListener) Events() <-chan api.Event {
+	return l.events
+}
+
+// Done returns a channel that is closed once the listener has been closed.
+func (l *EventListener) Done() <-chan struct{} {
+	return l.done
+}
+
+// Close removes the listener from the log handler.
+func (l *EventListener) Close() {
+	l.once.Do(func() {
+		l.stream.removeListener(l)
+		close(l.done)
+	})
+}
+
+// send delivers the event to the listener without blocking. Events are dropped if the listener is not keeping up.
+func (l *EventListener) send(event api.Event) {
+	select {
+	case <-l.done:
+	case l.events <- event:
+	default:
+	}
+}
+
+// eventStream is a slog.Handler that fans out events to connected listeners.
+type eventStream struct {
+	lock      sync.RWMutex
+	listeners []*EventListener
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{listeners: []*EventListener{}}
+}
+
+func (s *eventStream) addListener(level slog.Level, scopes []api.LogScope) *EventListener {
+	l := &EventListener{
+		stream: s,
+		level:  level,
+		scopes: scopes,
+		events: make(chan api.Event, eventListenerBufferSize),
+		done:   make(chan struct{}),
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.listeners = append(s.listeners, l)
+
+	return l
+}
+
+func (s *eventStream) removeListener(listener *EventListener) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.listeners = slices.DeleteFunc(s.listeners, func(l *EventListener) bool { return l == listener })
+}
+
+// Enabled implements slog.Handler.
+func (s *eventStream) Enabled(ctx context.Context, l slog.Level) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for _, listener := range s.listeners {
+		if l >= listener.level && slices.Contains(listener.scopes, api.LogScopeLogging) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle implements slog.Handler.
+func (s *eventStream) Handle(ctx context.Context, r slog.Record) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.listeners) == 0 {
+		return nil
+	}
+
+	event, err := newEvent(r)
+	if err != nil {
+		return err
+	}
+
+	for _, listener := range s.listeners {
+		if !slices.Contains(listener.scopes, event.Type) {
+			continue
+		}
+
+		if event.Type == api.LogScopeLogging && r.Level < listener.level {
+			continue
+		}
+
+		listener.send(event)
+	}
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (s *eventStream) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return s
+}
+
+// WithGroup implements slog.Handler.
+func (s *eventStream) WithGroup(name string) slog.Handler {
+	return s
+}
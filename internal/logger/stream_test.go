@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+	"github.com/FuturFusion/migration-manager/shared/api/event"
+)
+
+func TestEventListener(t *testing.T) {
+	cases := []struct {
+		name   string
+		level  slog.Level
+		scopes []api.LogScope
+
+		sendLog func(log *slog.Logger) func(msg string, args ...any)
+
+		wantTypes []api.LogScope
+	}{
+		{
+			name:      "success - can receive lifecycle and logging",
+			level:     slog.LevelWarn,
+			scopes:    []api.LogScope{api.LogScopeLifecycle, api.LogScopeLogging},
+			sendLog:   func(log *slog.Logger) func(msg string, args ...any) { return log.Error },
+			wantTypes: []api.LogScope{api.LogScopeLifecycle, api.LogScopeLogging},
+		},
+		{
+			name:      "success - logging omitted",
+			level:     slog.LevelWarn,
+			scopes:    []api.LogScope{api.LogScopeLifecycle},
+			sendLog:   func(log *slog.Logger) func(msg string, args ...any) { return log.Error },
+			wantTypes: []api.LogScope{api.LogScopeLifecycle},
+		},
+		{
+			name:      "success - lifecycle omitted",
+			level:     slog.LevelWarn,
+			scopes:    []api.LogScope{api.LogScopeLogging},
+			sendLog:   func(log *slog.Logger) func(msg string, args ...any) { return log.Error },
+			wantTypes: []api.LogScope{api.LogScopeLogging},
+		},
+		{
+			name:      "success - listener level below handler level",
+			level:     slog.LevelDebug,
+			scopes:    []api.LogScope{api.LogScopeLogging},
+			sendLog:   func(log *slog.Logger) func(msg string, args ...any) { return log.Debug },
+			wantTypes: []api.LogScope{api.LogScopeLogging},
+		},
+		{
+			name:      "success - discard log level",
+			level:     slog.LevelWarn,
+			scopes:    []api.LogScope{api.LogScopeLifecycle, api.LogScopeLogging},
+			sendLog:   func(log *slog.Logger) func(msg string, args ...any) { return log.Info },
+			wantTypes: []api.LogScope{api.LogScopeLifecycle},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			handler := NewLogHandler(slog.LevelWarn, slog.HandlerOptions{})
+			log := slog.New(handler)
+
+			listener := handler.AddListener(tc.level, tc.scopes)
+			defer listener.Close()
+
+			instance := api.Instance{InstanceProperties: api.InstanceProperties{UUID: uuid.New()}}
+			handler.SendLifecycle(context.Background(), event.NewMigrationEvent(event.MigrationCreated, instance, api.QueueEntry{InstanceUUID: instance.UUID}))
+			tc.sendLog(log)("TEST", slog.Any("key", "val"))
+
+			gotTypes := []api.LogScope{}
+			for range tc.wantTypes {
+				select {
+				case e := <-listener.Events():
+					require.False(t, e.Time.IsZero())
+					gotTypes = append(gotTypes, e.Type)
+					if e.Type == api.LogScopeLogging {
+						var logEvent api.EventLogging
+						require.NoError(t, json.Unmarshal(e.Metadata, &logEvent))
+						require.Equal(t, "TEST", logEvent.Message)
+						require.Equal(t, map[string]string{"key": "val"}, logEvent.Context)
+					}
+
+				case <-time.After(time.Second):
+					require.Fail(t, "Timed out waiting for event")
+				}
+			}
+
+			require.ElementsMatch(t, tc.wantTypes, gotTypes)
+
+			select {
+			case e := <-listener.Events():
+				require.Fail(t, "Unexpected event", "type: %q", e.Type)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			// Closed listeners no longer receive events.
+			listener.Close()
+			require.False(t, handler.Enabled(context.Background(), slog.LevelDebug))
+			handler.SendLifecycle(context.Background(), event.NewMigrationEvent(event.MigrationCreated, instance, api.QueueEntry{InstanceUUID: instance.UUID}))
+			require.Empty(t, listener.Events())
+		})
+	}
+}
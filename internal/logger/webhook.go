@@ -197,45 +197,9 @@ func (w *webhookLog) Enabled(ctx context.Context, l slog.Level) bool {
 
 // Handle implements slog.Handler.
 func (w *webhookLog) Handle(ctx context.Context, r slog.Record) error {
-	event := api.Event{Time: r.Time.UTC()}
-
-	if r.Message != string(api.LogScopeLifecycle) {
-		ctxMap := map[string]string{}
-		r.Attrs(func(a slog.Attr) bool {
-			ctxMap[a.Key] = a.Value.String()
-			return true
-		})
-
-		b, err := json.Marshal(api.EventLogging{
-			Message: r.Message,
-			Level:   r.Level.String(),
-			Context: ctxMap,
-		})
-		if err != nil {
-			return err
-		}
-
-		event.Type = api.LogScopeLogging
-		event.Metadata = b
-	} else {
-		var b []byte
-		var err error
-		r.Attrs(func(a slog.Attr) bool {
-			if a.Key == "event" {
-				b, err = json.Marshal(a.Value.Any())
-				if err != nil {
-					return false
-				}
-			}
-
-			return true
-		})
-		if err != nil {
-			return err
-		}
-
-		event.Type = api.LogScopeLifecycle
-		event.Metadata = b
+	event, err := newEvent(r)
+	if err != nil {
+		return err
 	}
 
 	if !slices.Contains(w.scopes, event.Type) {
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A Sample represents an OpenMetrics sample containing labels and the value.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// MetricSet represents a set of metrics.
+type MetricSet struct {
+	set map[MetricType][]Sample
+}
+
+// NewMetricSet returns a new, empty MetricSet.
+func NewMetricSet() *MetricSet {
+	return &MetricSet{set: map[MetricType][]Sample{}}
+}
+
+// AddSamples adds samples of the given metric type to the MetricSet.
+func (m *MetricSet) AddSamples(metricType MetricType, samples ...Sample) {
+	m.set[metricType] = append(m.set[metricType], samples...)
+}
+
+// String returns the MetricSet in the OpenMetrics text exposition format.
+func (m *MetricSet) String() string {
+	var out strings.Builder
+
+	metricTypes := make([]MetricType, 0, len(m.set))
+	for metricType := range m.set {
+		metricTypes = append(metricTypes, metricType)
+	}
+
+	slices.Sort(metricTypes)
+
+	for _, metricType := range metricTypes {
+		name := MetricNames[metricType]
+
+		fmt.Fprintf(&out, "# HELP %s %s\n", name, MetricHelp[metricType])
+		fmt.Fprintf(&out, "# TYPE %s gauge\n", name)
+
+		// Sort samples by their labels so the output is stable.
+		lines := make([]string, 0, len(m.set[metricType]))
+		for _, sample := range m.set[metricType] {
+			labelNames := make([]string, 0, len(sample.Labels))
+			for labelName := range sample.Labels {
+				labelNames = append(labelNames, labelName)
+			}
+
+			slices.Sort(labelNames)
+
+			labels := make([]string, 0, len(labelNames))
+			for _, labelName := range labelNames {
+				labels = append(labels, fmt.Sprintf(`%s="%s"`, labelName, labelValueReplacer.Replace(sample.Labels[labelName])))
+			}
+
+			value := strconv.FormatFloat(sample.Value, 'g', -1, 64)
+			if len(labels) > 0 {
+				lines = append(lines, fmt.Sprintf("%s{%s} %s\n", name, strings.Join(labels, ","), value))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s %s\n", name, value))
+			}
+		}
+
+		slices.Sort(lines)
+		for _, line := range lines {
+			out.WriteString(line)
+		}
+	}
+
+	out.WriteString("# EOF\n")
+
+	return out.String()
+}
+
+// labelValueReplacer escapes the characters that are not allowed in OpenMetrics label values.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/metrics"
+)
+
+func TestMetricSet_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples map[metrics.MetricType][]metrics.Sample
+
+		want string
+	}{
+		{
+			name: "success - empty",
+
+			want: "# EOF\n",
+		},
+		{
+			name: "success - sorted by metric type and labels",
+			samples: map[metrics.MetricType][]metrics.Sample{
+				metrics.Batches: {
+					{Labels: map[string]string{"status": "Running"}, Value: 2},
+				},
+				metrics.QueueEntries: {
+					{Labels: map[string]string{"status": "Idle", "batch": "b1"}, Value: 3},
+					{Labels: map[string]string{"status": "Finished", "batch": "b1"}, Value: 1},
+				},
+				metrics.SourceSyncDurationSeconds: {
+					{Labels: map[string]string{"source": "src1"}, Value: 1.5},
+				},
+			},
+
+			want: `# HELP migration_manager_queue_entries Number of queue entries per batch and migration status.
+# TYPE migration_manager_queue_entries gauge
+migration_manager_queue_entries{batch="b1",status="Finished"} 1
+migration_manager_queue_entries{batch="b1",status="Idle"} 3
+# HELP migration_manager_batches Number of batches per status.
+# TYPE migration_manager_batches gauge
+migration_manager_batches{status="Running"} 2
+# HELP migration_manager_source_sync_duration_seconds Duration of the last sync of a source in seconds.
+# TYPE migration_manager_source_sync_duration_seconds gauge
+migration_manager_source_sync_duration_seconds{source="src1"} 1.5
+# EOF
+`,
+		},
+		{
+			name: "success - escaped label values and no labels",
+			samples: map[metrics.MetricType][]metrics.Sample{
+				metrics.Warnings: {
+					{Labels: map[string]string{"type": "a \"quoted\"\\ntype\n"}, Value: 1},
+					{Value: 0},
+				},
+			},
+
+			want: `# HELP migration_manager_warnings Number of unacknowledged warnings per type.
+# TYPE migration_manager_warnings gauge
+migration_manager_warnings 0
+migration_manager_warnings{type="a \"quoted\"\\ntype\n"} 1
+# EOF
+`,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			set := metrics.NewMetricSet()
+			for metricType, samples := range tc.samples {
+				set.AddSamples(metricType, samples...)
+			}
+
+			require.Equal(t, tc.want, set.String())
+		})
+	}
+}
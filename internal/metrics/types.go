@@ -0,0 +1,51 @@
+package metrics
+
+// MetricType is a numeric code identifying the metric.
+type MetricType int
+
+const (
+	// QueueEntries represents the number of queue entries per batch and migration status.
+	QueueEntries MetricType = iota
+	// Batches represents the number of batches per batch status.
+	Batches
+	// SourceConnectivity represents the connectivity status of a source.
+	SourceConnectivity
+	// TargetConnectivity represents the connectivity status of a target.
+	TargetConnectivity
+	// Warnings represents the number of unacknowledged warnings per warning type.
+	Warnings
+	// SourceSyncDurationSeconds represents the duration of the last sync of a source.
+	SourceSyncDurationSeconds
+	// WorkerLastSeenSeconds represents the time since the last worker update for a migrating instance.
+	WorkerLastSeenSeconds
+	// TransferCopiedBytes represents the number of disk bytes copied for a migrating instance.
+	TransferCopiedBytes
+	// TransferTotalBytes represents the total number of disk bytes to copy for a migrating instance.
+	TransferTotalBytes
+)
+
+// MetricNames associates a metric type to its name.
+var MetricNames = map[MetricType]string{
+	QueueEntries:              "migration_manager_queue_entries",
+	Batches:                   "migration_manager_batches",
+	SourceConnectivity:        "migration_manager_source_connectivity",
+	TargetConnectivity:        "migration_manager_target_connectivity",
+	Warnings:                  "migration_manager_warnings",
+	SourceSyncDurationSeconds: "migration_manager_source_sync_duration_seconds",
+	WorkerLastSeenSeconds:     "migration_manager_worker_last_seen_seconds",
+	TransferCopiedBytes:       "migration_manager_transfer_copied_bytes",
+	TransferTotalBytes:        "migration_manager_transfer_total_bytes",
+}
+
+// MetricHelp associates a metric type to its help text.
+var MetricHelp = map[MetricType]string{
+	QueueEntries:              "Number of queue entries per batch and migration status.",
+	Batches:                   "Number of batches per status.",
+	SourceConnectivity:        "Connectivity status of a source, set to 1 for the current status.",
+	TargetConnectivity:        "Connectivity status of a target, set to 1 for the current status.",
+	Warnings:                  "Number of unacknowledged warnings per type.",
+	SourceSyncDurationSeconds: "Duration of the last sync of a source in seconds.",
+	WorkerLastSeenSeconds:     "Seconds since the last update from the worker of a migrating instance.",
+	TransferCopiedBytes:       "Number of disk bytes copied for a migrating instance.",
+	TransferTotalBytes:        "Total number of disk bytes to copy for a migrating instance.",
+}
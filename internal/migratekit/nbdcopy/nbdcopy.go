@@ -13,7 +13,7 @@ import (
 	"github.com/FuturFusion/migration-manager/internal/migratekit/progress"
 )
 
-func Run(message string, source string, destination string, size int64, targetIsClean bool, diskName string, statusCallback func(string, bool)) error {
+func Run(message string, source string, destination string, size int64, targetIsClean bool, diskName string, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 	log := slog.With(
 		slog.String("command", "nbdcopy"),
 		slog.String("source", source),
@@ -75,6 +75,7 @@ func Run(message string, source string, destination string, size int64, targetIs
 			}
 
 			bar.Set64(progress * size / 100)
+			progressCallback(diskName, progress*size/100, size)
 			statusCallback(fmt.Sprintf("%s %q: %02.2f%% complete", message, diskName, float64(progress)), false)
 		}
 
@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"sync"
+	"time"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// TransferTracker collects the progress of each disk being transferred, and derives the overall transfer rate and estimated time remaining.
+type TransferTracker struct {
+	mu    sync.Mutex
+	start time.Time
+	disks []api.DiskProgress
+}
+
+// NewTransferTracker returns a TransferTracker for the supported disks in the given list.
+func NewTransferTracker(disks []api.InstancePropertiesDisk) *TransferTracker {
+	t := &TransferTracker{
+		start: time.Now(),
+		disks: []api.DiskProgress{},
+	}
+
+	for _, disk := range disks {
+		if disk.Supported {
+			t.disks = append(t.disks, api.DiskProgress{Name: disk.Name, BytesTotal: disk.Capacity})
+		}
+	}
+
+	return t
+}
+
+// Update records the number of bytes transferred for the given disk.
+func (t *TransferTracker) Update(diskName string, bytesCopied int64, bytesTotal int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, disk := range t.disks {
+		if disk.Name == diskName {
+			t.disks[i].BytesCopied = bytesCopied
+			t.disks[i].BytesTotal = bytesTotal
+			return
+		}
+	}
+
+	t.disks = append(t.disks, api.DiskProgress{Name: diskName, BytesCopied: bytesCopied, BytesTotal: bytesTotal})
+}
+
+// Progress returns the current transfer progress across all disks.
+func (t *TransferTracker) Progress() api.DiskTransferProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	progress := api.DiskTransferProgress{Disks: make([]api.DiskProgress, len(t.disks))}
+	copy(progress.Disks, t.disks)
+	for _, disk := range t.disks {
+		progress.BytesCopied += disk.BytesCopied
+		progress.BytesTotal += disk.BytesTotal
+	}
+
+	elapsed := time.Since(t.start)
+	if elapsed <= 0 || progress.BytesCopied == 0 {
+		return progress
+	}
+
+	progress.BytesPerSecond = int64(float64(progress.BytesCopied) / elapsed.Seconds())
+	if progress.BytesPerSecond > 0 && progress.BytesTotal > progress.BytesCopied {
+		remaining := float64(progress.BytesTotal-progress.BytesCopied) / float64(progress.BytesPerSecond)
+		progress.ETA = api.AsDuration(time.Duration(remaining * float64(time.Second)).Truncate(time.Second))
+	}
+
+	return progress
+}
@@ -0,0 +1,96 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestTransferTracker_Progress(t *testing.T) {
+	disks := []api.InstancePropertiesDisk{
+		{Name: "disk1", Capacity: 1000, Supported: true},
+		{Name: "disk2", Capacity: 3000, Supported: true},
+		{Name: "cdrom", Capacity: 500, Supported: false},
+	}
+
+	tests := []struct {
+		name    string
+		updates []api.DiskProgress
+		elapsed time.Duration
+
+		want api.DiskTransferProgress
+	}{
+		{
+			name:    "success - no progress",
+			elapsed: 10 * time.Second,
+
+			want: api.DiskTransferProgress{
+				BytesTotal: 4000,
+				Disks:      []api.DiskProgress{{Name: "disk1", BytesTotal: 1000}, {Name: "disk2", BytesTotal: 3000}},
+			},
+		},
+		{
+			name:    "success - partial progress",
+			updates: []api.DiskProgress{{Name: "disk1", BytesCopied: 500, BytesTotal: 1000}, {Name: "disk1", BytesCopied: 1000, BytesTotal: 1000}},
+			elapsed: 10 * time.Second,
+
+			want: api.DiskTransferProgress{
+				BytesCopied:    1000,
+				BytesTotal:     4000,
+				BytesPerSecond: 100,
+				ETA:            api.AsDuration(30 * time.Second),
+				Disks:          []api.DiskProgress{{Name: "disk1", BytesCopied: 1000, BytesTotal: 1000}, {Name: "disk2", BytesTotal: 3000}},
+			},
+		},
+		{
+			name:    "success - complete",
+			updates: []api.DiskProgress{{Name: "disk1", BytesCopied: 1000, BytesTotal: 1000}, {Name: "disk2", BytesCopied: 3000, BytesTotal: 3000}},
+			elapsed: 20 * time.Second,
+
+			want: api.DiskTransferProgress{
+				BytesCopied:    4000,
+				BytesTotal:     4000,
+				BytesPerSecond: 200,
+				Disks:          []api.DiskProgress{{Name: "disk1", BytesCopied: 1000, BytesTotal: 1000}, {Name: "disk2", BytesCopied: 3000, BytesTotal: 3000}},
+			},
+		},
+		{
+			name:    "success - unknown disk",
+			updates: []api.DiskProgress{{Name: "disk3", BytesCopied: 1000, BytesTotal: 2000}},
+			elapsed: 10 * time.Second,
+
+			want: api.DiskTransferProgress{
+				BytesCopied:    1000,
+				BytesTotal:     6000,
+				BytesPerSecond: 100,
+				ETA:            api.AsDuration(50 * time.Second),
+				Disks:          []api.DiskProgress{{Name: "disk1", BytesTotal: 1000}, {Name: "disk2", BytesTotal: 3000}, {Name: "disk3", BytesCopied: 1000, BytesTotal: 2000}},
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			tracker := NewTransferTracker(disks)
+			tracker.start = time.Now().Add(-tc.elapsed)
+
+			for _, update := range tc.updates {
+				tracker.Update(update.Name, update.BytesCopied, update.BytesTotal)
+			}
+
+			got := tracker.Progress()
+
+			// The elapsed time is only approximate, so allow for some drift in the derived values.
+			require.InDelta(t, tc.want.BytesPerSecond, got.BytesPerSecond, 1)
+			require.InDelta(t, tc.want.ETA.Seconds(), got.ETA.Seconds(), 1)
+			got.BytesPerSecond = tc.want.BytesPerSecond
+			got.ETA = tc.want.ETA
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
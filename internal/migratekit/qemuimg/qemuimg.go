@@ -0,0 +1,93 @@
+package qemuimg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"github.com/FuturFusion/migration-manager/internal/migratekit/progress"
+)
+
+var progressRegex = regexp.MustCompile(`\(([\d.]+)/100%\)`)
+
+// Convert writes the source image to the destination block device as raw data, using `qemu-img convert`.
+func Convert(ctx context.Context, message string, source string, destination string, size int64, diskName string, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
+	log := slog.With(
+		slog.String("command", "qemu-img"),
+		slog.String("source", source),
+		slog.String("destination", destination),
+	)
+
+	// Skip target creation (-n) as the destination is an existing block device.
+	cmd := exec.CommandContext(ctx, "qemu-img", "convert", "-p", "-n", "-O", "raw", source, destination)
+
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Running command", slog.String("args", cmd.String()))
+	defer func() {
+		if len(stderr.String()) > 0 {
+			log.Error("Command errored", slog.String("stderr", stderr.String()))
+		}
+	}()
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	bar := progress.DataProgressBar("Full copy", size)
+	scanner := bufio.NewScanner(stdout)
+
+	// qemu-img rewrites its progress line in place using carriage returns.
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		i := bytes.IndexAny(data, "\r\n")
+		if i >= 0 {
+			return i + 1, data[:i], nil
+		}
+
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	})
+
+	for scanner.Scan() {
+		matches := progressRegex.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+
+		percent, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			log.Error("Error parsing progress: ", slog.Any("error", err))
+			continue
+		}
+
+		bar.Set64(int64(percent * float64(size) / 100))
+		progressCallback(diskName, int64(percent*float64(size)/100), size)
+		statusCallback(fmt.Sprintf("%s %q: %02.2f%% complete", message, diskName, percent), false)
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Error("Error reading progress: ", slog.Any("error", err))
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed to convert disk %q: %w (%s)", diskName, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return nil
+}
@@ -2,11 +2,9 @@ package target
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io/fs"
+	"io"
 	"os"
-	"strings"
 
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
@@ -17,14 +15,22 @@ import (
 type DiskTarget struct {
 	VirtualMachine *object.VirtualMachine
 	Disk           *types.VirtualDisk
+	DiskName       string
+	DeviceName     string
 	DeviceTarget   string
+	Snapshot       string
+	ChangeIDs      ChangeIDStore
 }
 
-func NewDiskTarget(vm *object.VirtualMachine, disk *types.VirtualDisk, deviceTarget string) (*DiskTarget, error) {
+func NewDiskTarget(vm *object.VirtualMachine, disk *types.VirtualDisk, diskName string, deviceName string, deviceTarget string, snapshot string, changeIDs ChangeIDStore) (*DiskTarget, error) {
 	return &DiskTarget{
 		VirtualMachine: vm,
 		Disk:           disk,
+		DiskName:       diskName,
+		DeviceName:     deviceName,
 		DeviceTarget:   deviceTarget,
+		Snapshot:       snapshot,
+		ChangeIDs:      changeIDs,
 	}, nil
 }
 
@@ -52,17 +58,47 @@ func (t *DiskTarget) Exists(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// GetCurrentChangeID returns the change ID of the data last copied to the target device.
+// The change ID is only returned if it was recorded for the same device of the same size, as data copied to any other device is not present on this one.
 func (t *DiskTarget) GetCurrentChangeID(ctx context.Context) (*vmware.ChangeID, error) {
-	diskParts := strings.Split(t.DeviceTarget, "/")
-	data, err := os.ReadFile(fmt.Sprintf("/tmp/migration-manager_%s.cid", diskParts[len(diskParts)-1]))
-	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+	if t.ChangeIDs == nil {
+		return nil, vmware.ErrInvalidChangeID
+	}
+
+	size, err := t.deviceSize()
+	if err != nil {
 		return nil, err
 	}
 
-	return vmware.ParseChangeID(string(data))
+	return t.ChangeIDs.GetChangeID(ctx, t.DiskName, t.DeviceName, size)
 }
 
 func (t *DiskTarget) WriteChangeID(ctx context.Context, changeID *vmware.ChangeID) error {
-	diskParts := strings.Split(t.DeviceTarget, "/")
-	return os.WriteFile(fmt.Sprintf("/tmp/migration-manager_%s.cid", diskParts[len(diskParts)-1]), []byte(changeID.Value), 0o644)
+	if t.ChangeIDs == nil {
+		return nil
+	}
+
+	size, err := t.deviceSize()
+	if err != nil {
+		return err
+	}
+
+	return t.ChangeIDs.WriteChangeID(ctx, t.DiskName, t.DeviceName, size, t.Snapshot, changeID)
+}
+
+// deviceSize returns the size in bytes of the target device.
+func (t *DiskTarget) deviceSize() (int64, error) {
+	f, err := os.Open(t.DeviceTarget)
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to determine size of device %q: %w", t.DeviceTarget, err)
+	}
+
+	return size, nil
 }
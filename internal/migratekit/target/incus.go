@@ -0,0 +1,73 @@
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// GetIncusDisk returns the path to the block device of the Incus disk that was created for the given source disk, and the name of its Incus device.
+func GetIncusDisk(ctx context.Context, client *http.Client, diskName string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unix.socket/1.0/devices", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	devices := map[string]map[string]string{}
+	err = json.Unmarshal(out, &devices)
+	if err != nil {
+		return "", "", err
+	}
+
+	var devName string
+	for id, cfg := range devices {
+		if cfg["user.migration_source"] == diskName {
+			devName = id
+			break
+		}
+	}
+
+	if devName == "" {
+		return "", "", fmt.Errorf("Failed to find any disk with migration source %q", diskName)
+	}
+
+	entries, err := os.ReadDir("/dev/disk/by-id")
+	if err != nil {
+		return "", "", err
+	}
+
+	diskID := "scsi-0QEMU_QEMU_HARDDISK_incus_" + devName
+	for _, e := range entries {
+		if e.Name() != diskID {
+			continue
+		}
+
+		diskPath, err := filepath.EvalSymlinks(filepath.Join("/dev/disk/by-id", e.Name()))
+		if err != nil {
+			return "", "", err
+		}
+
+		return diskPath, devName, nil
+	}
+
+	return "", "", fmt.Errorf("Failed to find disk with ID %q", diskID)
+}
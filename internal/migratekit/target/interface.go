@@ -17,3 +17,12 @@ type Target interface {
 	GetCurrentChangeID(context.Context) (*vmware.ChangeID, error)
 	WriteChangeID(context.Context, *vmware.ChangeID) error
 }
+
+// ChangeIDStore persists the change IDs of the data copied from source disks to their target devices, so that incremental copies can resume after the worker restarts.
+type ChangeIDStore interface {
+	// GetChangeID returns the change ID of the data last copied from the source disk to the target device of the given size, or nil if there is none.
+	GetChangeID(ctx context.Context, diskName string, targetDevice string, targetSize int64) (*vmware.ChangeID, error)
+
+	// WriteChangeID records the change ID of the data copied from the source disk to the target device of the given size out of the given snapshot.
+	WriteChangeID(ctx context.Context, diskName string, targetDevice string, targetSize int64, snapshot string, changeID *vmware.ChangeID) error
+}
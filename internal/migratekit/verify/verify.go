@@ -0,0 +1,83 @@
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// ChunkSize is the size of the blocks whose checksums are compared.
+const ChunkSize = 4 * 1024 * 1024
+
+// SampleInterval is the number of blocks out of which one is compared when sampling.
+const SampleInterval = 16
+
+// Disk compares the checksums of the blocks of the source and target disks of the given size, and returns the number of bytes compared along with the ranges that differ.
+// When sampling, one block out of every SampleInterval blocks is compared, starting from a random block so repeated verifications cover different parts of the disk.
+func Disk(ctx context.Context, source io.ReaderAt, target io.ReaderAt, size int64, mode api.DiskVerificationMode, progressCallback func(bytesVerified int64, offset int64)) (int64, []api.DiskRange, error) {
+	interval := int64(1)
+	first := int64(0)
+	switch mode {
+	case api.DISKVERIFICATION_FULL:
+	case api.DISKVERIFICATION_SAMPLE:
+		interval = SampleInterval
+		first = rand.Int64N(SampleInterval)
+	default:
+		return 0, nil, fmt.Errorf("Unsupported disk verification mode %q", mode)
+	}
+
+	sourceBuf := make([]byte, ChunkSize)
+	targetBuf := make([]byte, ChunkSize)
+
+	var bytesVerified int64
+	mismatches := []api.DiskRange{}
+	for offset := first * ChunkSize; offset < size; offset += interval * ChunkSize {
+		err := ctx.Err()
+		if err != nil {
+			return bytesVerified, mismatches, err
+		}
+
+		length := min(int64(ChunkSize), size-offset)
+		err = readAt(source, sourceBuf[:length], offset)
+		if err != nil {
+			return bytesVerified, mismatches, fmt.Errorf("Failed to read source disk at offset %d: %w", offset, err)
+		}
+
+		err = readAt(target, targetBuf[:length], offset)
+		if err != nil {
+			return bytesVerified, mismatches, fmt.Errorf("Failed to read target disk at offset %d: %w", offset, err)
+		}
+
+		if sha256.Sum256(sourceBuf[:length]) != sha256.Sum256(targetBuf[:length]) {
+			// Merge mismatches of adjacent blocks into a single range.
+			last := len(mismatches) - 1
+			if last >= 0 && mismatches[last].Offset+mismatches[last].Length == offset {
+				mismatches[last].Length += length
+			} else {
+				mismatches = append(mismatches, api.DiskRange{Offset: offset, Length: length})
+			}
+		}
+
+		bytesVerified += length
+		if progressCallback != nil {
+			progressCallback(bytesVerified, offset+length)
+		}
+	}
+
+	return bytesVerified, mismatches, nil
+}
+
+// readAt fills buf from the reader at the given offset.
+func readAt(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && n == len(buf)) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestDisk(t *testing.T) {
+	source := make([]byte, 32*ChunkSize+1024)
+	for i := range source {
+		source[i] = byte(i % 251)
+	}
+
+	modified := func(offsets ...int64) []byte {
+		target := bytes.Clone(source)
+		for _, offset := range offsets {
+			target[offset]++
+		}
+
+		return target
+	}
+
+	tests := []struct {
+		name   string
+		target []byte
+		mode   api.DiskVerificationMode
+
+		assertErr         require.ErrorAssertionFunc
+		wantBytesVerified int64
+		wantMismatches    []api.DiskRange
+	}{
+		{
+			name:   "success - full, identical",
+			target: bytes.Clone(source),
+			mode:   api.DISKVERIFICATION_FULL,
+
+			assertErr:         require.NoError,
+			wantBytesVerified: int64(len(source)),
+			wantMismatches:    []api.DiskRange{},
+		},
+		{
+			name:   "success - full, adjacent mismatches are merged",
+			target: modified(ChunkSize+10, 2*ChunkSize+10, 5*ChunkSize, 32*ChunkSize+1),
+			mode:   api.DISKVERIFICATION_FULL,
+
+			assertErr:         require.NoError,
+			wantBytesVerified: int64(len(source)),
+			wantMismatches: []api.DiskRange{
+				{Offset: ChunkSize, Length: 2 * ChunkSize},
+				{Offset: 5 * ChunkSize, Length: ChunkSize},
+				{Offset: 32 * ChunkSize, Length: 1024},
+			},
+		},
+		{
+			name:   "success - sample, identical",
+			target: bytes.Clone(source),
+			mode:   api.DISKVERIFICATION_SAMPLE,
+
+			assertErr:      require.NoError,
+			wantMismatches: []api.DiskRange{},
+		},
+		{
+			name:   "error - target too small",
+			target: source[:ChunkSize],
+			mode:   api.DISKVERIFICATION_FULL,
+
+			assertErr:         require.Error,
+			wantBytesVerified: ChunkSize,
+			wantMismatches:    []api.DiskRange{},
+		},
+		{
+			name:   "error - invalid mode",
+			target: bytes.Clone(source),
+			mode:   api.DiskVerificationMode("invalid"),
+
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		bytesVerified, mismatches, err := Disk(context.Background(), bytes.NewReader(source), bytes.NewReader(tc.target), int64(len(source)), tc.mode, nil)
+		tc.assertErr(t, err)
+
+		if tc.mode == api.DISKVERIFICATION_SAMPLE {
+			// One in every SampleInterval blocks is compared, and the final partial block may or may not be sampled.
+			require.GreaterOrEqual(t, bytesVerified, int64(2*ChunkSize))
+			require.LessOrEqual(t, bytesVerified, int64(2*ChunkSize+1024))
+		} else {
+			require.Equal(t, tc.wantBytesVerified, bytesVerified)
+		}
+
+		require.Equal(t, tc.wantMismatches, mismatches)
+	}
+}
+
+func TestDisk_SampleDetectsMismatch(t *testing.T) {
+	source := make([]byte, 2*SampleInterval*ChunkSize)
+	target := bytes.Repeat([]byte{1}, len(source))
+
+	bytesVerified, mismatches, err := Disk(context.Background(), bytes.NewReader(source), bytes.NewReader(target), int64(len(source)), api.DISKVERIFICATION_SAMPLE, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2*ChunkSize), bytesVerified)
+	require.Len(t, mismatches, 2)
+	require.Equal(t, int64(SampleInterval*ChunkSize), mismatches[1].Offset-mismatches[0].Offset)
+}
@@ -2,18 +2,13 @@ package vmware_nbdkit
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
-	"path/filepath"
 	"syscall"
-	"time"
 
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/methods"
@@ -26,8 +21,10 @@ import (
 	"github.com/FuturFusion/migration-manager/internal/migratekit/nbdkit"
 	"github.com/FuturFusion/migration-manager/internal/migratekit/progress"
 	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/verify"
 	"github.com/FuturFusion/migration-manager/internal/migratekit/vmware"
 	"github.com/FuturFusion/migration-manager/internal/util"
+	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
 const MaxChunkSize = 64 * 1024 * 1024
@@ -40,12 +37,14 @@ type VddkConfig struct {
 }
 
 type NbdkitServers struct {
-	VddkConfig     *VddkConfig
-	VirtualMachine *object.VirtualMachine
-	SnapshotRef    types.ManagedObjectReference
-	Servers        []*NbdkitServer
-	StatusCallback func(string, bool)
-	SDKPath        string
+	VddkConfig       *VddkConfig
+	VirtualMachine   *object.VirtualMachine
+	SnapshotRef      types.ManagedObjectReference
+	Servers          []*NbdkitServer
+	StatusCallback   func(string, bool)
+	ProgressCallback func(string, int64, int64)
+	SDKPath          string
+	ChangeIDs        target.ChangeIDStore
 }
 
 type NbdkitServer struct {
@@ -54,13 +53,15 @@ type NbdkitServer struct {
 	Nbdkit  *nbdkit.NbdkitServer
 }
 
-func NewNbdkitServers(vddk *VddkConfig, vm *object.VirtualMachine, sdkPath string, statusCallback func(string, bool)) *NbdkitServers {
+func NewNbdkitServers(vddk *VddkConfig, vm *object.VirtualMachine, sdkPath string, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) *NbdkitServers {
 	return &NbdkitServers{
-		VddkConfig:     vddk,
-		VirtualMachine: vm,
-		Servers:        []*NbdkitServer{},
-		StatusCallback: statusCallback,
-		SDKPath:        sdkPath,
+		VddkConfig:       vddk,
+		VirtualMachine:   vm,
+		Servers:          []*NbdkitServer{},
+		StatusCallback:   statusCallback,
+		ProgressCallback: progressCallback,
+		SDKPath:          sdkPath,
+		ChangeIDs:        changeIDs,
 	}
 }
 
@@ -209,70 +210,53 @@ func (s *NbdkitServers) Stop(ctx context.Context) error {
 	return nil
 }
 
-func getIncusDisk(ctx context.Context, client *http.Client, diskName string) (string, bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unix.socket/1.0/devices", nil)
-	if err != nil {
-		return "", false, err
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", false, err
-	}
-
-	defer func() { _ = resp.Body.Close() }()
-	out, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", false, err
-	}
-
-	devices := map[string]map[string]string{}
-	err = json.Unmarshal(out, &devices)
+func (s *NbdkitServers) MigrationCycle(ctx context.Context, diskValidator func([]*types.VirtualDisk) error, runV2V bool) error {
+	err := s.Start(ctx, diskValidator)
 	if err != nil {
-		return "", false, err
+		return err
 	}
-
-	var devName string
-	for id, cfg := range devices {
-		if cfg["user.migration_source"] == diskName {
-			devName = id
-			break
+	defer func() {
+		err := s.Stop(ctx)
+		if err != nil {
+			slog.Error("Failed to stop nbdkit servers", slog.Any("error", err))
 		}
-	}
+	}()
 
-	if devName == "" {
-		return "", false, fmt.Errorf("Failed to find any disk with migration source %q", diskName)
-	}
+	devIncus := util.UnixHTTPClient("/dev/incus/sock")
+	for _, server := range s.Servers {
+		diskName, _, err := vmware.IsSupportedDisk(server.Disk)
+		if err != nil {
+			return err
+		}
 
-	entries, err := os.ReadDir("/dev/disk/by-id")
-	if err != nil {
-		return "", false, err
-	}
+		diskID, devName, err := target.GetIncusDisk(ctx, devIncus, diskName)
+		if err != nil {
+			return err
+		}
 
-	diskID := "scsi-0QEMU_QEMU_HARDDISK_incus_" + devName
-	for _, e := range entries {
-		if e.Name() != diskID {
-			continue
+		if devName == "root" {
+			runV2V = false
 		}
 
-		diskPath, err := filepath.EvalSymlinks(filepath.Join("/dev/disk/by-id", e.Name()))
+		t, err := target.NewDiskTarget(s.VirtualMachine, server.Disk, diskName, devName, diskID, s.SnapshotRef.Value, s.ChangeIDs)
 		if err != nil {
-			return "", false, err
+			return err
 		}
 
-		return diskPath, devName == "root", nil
+		err = server.SyncToTarget(ctx, t, runV2V, s.StatusCallback, s.ProgressCallback)
+		if err != nil {
+			return err
+		}
 	}
 
-	return "", false, fmt.Errorf("Failed to find disk with ID %q", diskID)
+	return nil
 }
 
-func (s *NbdkitServers) MigrationCycle(ctx context.Context, diskValidator func([]*types.VirtualDisk) error, runV2V bool) error {
-	err := s.Start(ctx, diskValidator)
+// VerifyDisks compares the checksums of the disks of a new snapshot of the VM against the Incus disks they were copied to.
+func (s *NbdkitServers) VerifyDisks(ctx context.Context, mode api.DiskVerificationMode) ([]api.DiskVerificationResult, error) {
+	err := s.Start(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		err := s.Stop(ctx)
@@ -282,36 +266,85 @@ func (s *NbdkitServers) MigrationCycle(ctx context.Context, diskValidator func([
 	}()
 
 	devIncus := util.UnixHTTPClient("/dev/incus/sock")
-	for _, server := range s.Servers {
+	results := make([]api.DiskVerificationResult, 0, len(s.Servers))
+	for i, server := range s.Servers {
 		diskName, _, err := vmware.IsSupportedDisk(server.Disk)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		diskID, isRoot, err := getIncusDisk(ctx, devIncus, diskName)
+		diskPath, _, err := target.GetIncusDisk(ctx, devIncus, diskName)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if isRoot {
-			runV2V = false
-		}
-
-		t, err := target.NewDiskTarget(s.VirtualMachine, server.Disk, diskID)
+		msg := fmt.Sprintf("Verifying disk (%d/%d) %q", i+1, len(s.Servers), diskName)
+		result, err := server.verifyTarget(ctx, diskName, diskPath, mode, msg)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("Failed to verify disk %q: %w", diskName, err)
 		}
 
-		err = server.SyncToTarget(ctx, t, runV2V, s.StatusCallback)
-		if err != nil {
-			return err
-		}
+		results = append(results, *result)
 	}
 
-	return nil
+	return results, nil
+}
+
+func (s *NbdkitServer) verifyTarget(ctx context.Context, diskName string, path string, mode api.DiskVerificationMode, msg string) (*api.DiskVerificationResult, error) {
+	handle, err := libnbd.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	defer handle.Close()
+
+	err = handle.ConnectUri(s.Nbdkit.LibNBDExportName())
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fd.Close()
+
+	slog.Info("Starting disk verification", slog.String("disk", diskName), slog.String("mode", string(mode)))
+	s.Servers.StatusCallback(msg, true)
+	bytesVerified, mismatches, err := verify.Disk(ctx, nbdReader{handle: handle}, fd, s.Disk.CapacityInBytes, mode, func(bytesVerified int64, offset int64) {
+		s.Servers.StatusCallback(fmt.Sprintf("%s: %02.2f%% complete", msg, float64(offset)/float64(s.Disk.CapacityInBytes)*100.0), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Disk verification completed", slog.String("disk", diskName), slog.Int64("bytes_verified", bytesVerified), slog.Int("mismatched_ranges", len(mismatches)))
+
+	return &api.DiskVerificationResult{
+		Disk:             diskName,
+		Mode:             mode,
+		BytesVerified:    bytesVerified,
+		BytesTotal:       s.Disk.CapacityInBytes,
+		MismatchedRanges: mismatches,
+	}, nil
+}
+
+// nbdReader reads from an NBD export through libnbd.
+type nbdReader struct {
+	handle *libnbd.Libnbd
+}
+
+func (r nbdReader) ReadAt(p []byte, offset int64) (int, error) {
+	err := r.handle.Pread(p, uint64(offset), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
 }
 
-func (s *NbdkitServer) FullCopyToTarget(t target.Target, path string, targetIsClean bool, statusCallback func(string, bool)) error {
+func (s *NbdkitServer) FullCopyToTarget(t target.Target, path string, targetIsClean bool, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 	diskName, _, err := vmware.IsSupportedDisk(s.Disk)
 	if err != nil {
 		return err
@@ -346,6 +379,7 @@ func (s *NbdkitServer) FullCopyToTarget(t target.Target, path string, targetIsCl
 		targetIsClean,
 		diskName,
 		statusCallback,
+		progressCallback,
 	)
 	if err != nil {
 		return err
@@ -356,7 +390,7 @@ func (s *NbdkitServer) FullCopyToTarget(t target.Target, path string, targetIsCl
 	return nil
 }
 
-func (s *NbdkitServer) IncrementalCopyToTarget(ctx context.Context, t target.Target, path string, statusCallback func(string, bool)) error {
+func (s *NbdkitServer) IncrementalCopyToTarget(ctx context.Context, t target.Target, path string, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 	diskName, _, err := vmware.IsSupportedDisk(s.Disk)
 	if err != nil {
 		return err
@@ -443,6 +477,7 @@ func (s *NbdkitServer) IncrementalCopyToTarget(ctx context.Context, t target.Tar
 				}
 
 				bar.Set64(offset + chunkSize)
+				progressCallback(diskName, offset+chunkSize, s.Disk.CapacityInBytes)
 				statusCallback(fmt.Sprintf("Importing disk (%d/%d) %q: %02.2f%% complete", index, len(s.Servers.Servers), diskName, float64(offset+chunkSize)/float64(s.Disk.CapacityInBytes)*100.0), false)
 				offset += chunkSize
 			}
@@ -459,7 +494,7 @@ func (s *NbdkitServer) IncrementalCopyToTarget(ctx context.Context, t target.Tar
 	return nil
 }
 
-func (s *NbdkitServer) SyncToTarget(ctx context.Context, t target.Target, runV2V bool, statusCallback func(string, bool)) error {
+func (s *NbdkitServer) SyncToTarget(ctx context.Context, t target.Target, runV2V bool, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 	snapshotChangeId, err := vmware.GetChangeID(s.Disk)
 	if err != nil {
 		// Rather than returning an error when CBT isn't enabled, just proceed with a dummy change ID.
@@ -502,12 +537,12 @@ func (s *NbdkitServer) SyncToTarget(ctx context.Context, t target.Target, runV2V
 	}
 
 	if needFullCopy {
-		err = s.FullCopyToTarget(t, path, targetIsClean, statusCallback)
+		err = s.FullCopyToTarget(t, path, targetIsClean, statusCallback, progressCallback)
 		if err != nil {
 			return err
 		}
 	} else {
-		err = s.IncrementalCopyToTarget(ctx, t, path, statusCallback)
+		err = s.IncrementalCopyToTarget(ctx, t, path, statusCallback, progressCallback)
 		if err != nil {
 			return err
 		}
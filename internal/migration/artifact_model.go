@@ -114,6 +114,25 @@ func (a Artifact) Validate() error {
 			return NewValidationErrf("Artifact does not support versions")
 		}
 
+	case api.ARTIFACTTYPE_SCRIPT:
+		if a.Properties.SourceType != "" {
+			return NewValidationErrf("Artifact does not support a source type")
+		}
+
+		switch a.Properties.OS {
+		case api.OSTYPE_LINUX, api.OSTYPE_WINDOWS:
+		default:
+			return NewValidationErrf("Artifact has invalid OS %q", a.Properties.OS)
+		}
+
+		if len(a.Properties.Architectures) > 0 {
+			return NewValidationErrf("Artifact does not support architectures")
+		}
+
+		if len(a.Properties.Versions) > 0 {
+			return NewValidationErrf("Artifact does not support versions")
+		}
+
 	default:
 		return NewValidationErrf("Artifact has invalid type %q", a.Type)
 	}
@@ -122,6 +141,11 @@ func (a Artifact) Validate() error {
 }
 
 func (a Artifact) CollidesWith(arts Artifacts) error {
+	// Any number of script artifacts can exist, as they are referenced directly by UUID.
+	if a.Type == api.ARTIFACTTYPE_SCRIPT {
+		return nil
+	}
+
 	archMap := map[string]bool{}
 	verMap := map[string]bool{}
 
@@ -162,6 +186,39 @@ func (a Artifact) CollidesWith(arts Artifacts) error {
 	return nil
 }
 
+// ValidatePostMigrationScripts ensures the given post-migration script references are well-formed.
+func ValidatePostMigrationScripts(scripts []api.PostMigrationScript) error {
+	type scriptKey struct {
+		artifact uuid.UUID
+		stage    api.PostMigrationScriptStage
+	}
+
+	seen := map[scriptKey]bool{}
+	for _, s := range scripts {
+		if s.Artifact == uuid.Nil {
+			return fmt.Errorf("Post-migration script is missing an artifact")
+		}
+
+		err := s.Stage.Validate()
+		if err != nil {
+			return fmt.Errorf("Post-migration script %q is invalid: %w", s.Artifact, err)
+		}
+
+		if s.Timeout.Duration < 0 {
+			return fmt.Errorf("Post-migration script %q timeout %q must not be negative", s.Artifact, s.Timeout.String())
+		}
+
+		key := scriptKey{artifact: s.Artifact, stage: s.Stage}
+		if seen[key] {
+			return fmt.Errorf("Post-migration script %q cannot be used more than once in stage %q", s.Artifact, s.Stage)
+		}
+
+		seen[key] = true
+	}
+
+	return nil
+}
+
 func (a Artifact) ToAPI() api.Artifact {
 	return api.Artifact{
 		ArtifactPost: api.ArtifactPost{
@@ -150,6 +150,11 @@ func (a artifactService) HasRequiredArtifactsForInstance(artifacts Artifacts, in
 	var osArtifactExists bool
 	var driverArtifactExists bool
 	for _, art := range artifacts {
+		// Script artifacts are never required, and are referenced directly by batches and instance overrides.
+		if art.Type == api.ARTIFACTTYPE_SCRIPT {
+			continue
+		}
+
 		requiredFile, err := art.ToAPI().DefaultArtifactFile()
 		if err != nil {
 			return err
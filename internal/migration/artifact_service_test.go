@@ -154,6 +154,33 @@ func TestArtifact_Create(t *testing.T) {
 			},
 			assertErr: require.Error,
 		},
+		{
+			name: "success - linux script",
+			artifact: migration.Artifact{
+				UUID:       uuid.New(),
+				Type:       api.ARTIFACTTYPE_SCRIPT,
+				Properties: api.ArtifactPut{OS: api.OSTYPE_LINUX},
+			},
+			assertErr: require.NoError,
+		},
+		{
+			name: "error - fortigate script",
+			artifact: migration.Artifact{
+				UUID:       uuid.New(),
+				Type:       api.ARTIFACTTYPE_SCRIPT,
+				Properties: api.ArtifactPut{OS: api.OSTYPE_FORTIGATE},
+			},
+			assertErr: require.Error,
+		},
+		{
+			name: "error - script with architecture",
+			artifact: migration.Artifact{
+				UUID:       uuid.New(),
+				Type:       api.ARTIFACTTYPE_SCRIPT,
+				Properties: api.ArtifactPut{OS: api.OSTYPE_WINDOWS, Architectures: []string{"x86_64"}},
+			},
+			assertErr: require.Error,
+		},
 	}
 
 	for i, tc := range cases {
@@ -195,6 +222,21 @@ func TestArtifact_HasRequiredArtifactsForInstance(t *testing.T) {
 			}},
 			instance: migration.Instance{SourceType: api.SOURCETYPE_VMWARE},
 		},
+		{
+			name:      "success - linux from vmware (sdk, script)",
+			assertErr: require.NoError,
+			artifacts: []api.Artifact{
+				{
+					ArtifactPost: api.ArtifactPost{Type: api.ARTIFACTTYPE_SDK, ArtifactPut: api.ArtifactPut{SourceType: api.SOURCETYPE_VMWARE}},
+					Files:        []string{"vmware-sdk.tar.gz"},
+				},
+				{
+					ArtifactPost: api.ArtifactPost{Type: api.ARTIFACTTYPE_SCRIPT, ArtifactPut: api.ArtifactPut{OS: api.OSTYPE_LINUX}},
+					Files:        []string{"register.sh"},
+				},
+			},
+			instance: migration.Instance{SourceType: api.SOURCETYPE_VMWARE},
+		},
 		{
 			name:      "success - windows from vmware (sdk,virtio-win)",
 			assertErr: require.NoError,
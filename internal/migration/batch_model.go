@@ -2,8 +2,13 @@ package migration
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/validate"
 
 	"github.com/FuturFusion/migration-manager/internal/scriptlet"
@@ -19,9 +24,15 @@ type Batch struct {
 
 	StartDate time.Time
 
-	Constraints []api.BatchConstraint `db:"marshal=json"`
-	Config      api.BatchConfig       `db:"marshal=json"`
-	Defaults    api.BatchDefaults     `db:"marshal=json"`
+	Constraints    []api.BatchConstraint    `db:"marshal=json"`
+	InstanceGroups []api.BatchInstanceGroup `db:"marshal=json"`
+	Config         api.BatchConfig          `db:"marshal=json"`
+	Defaults       api.BatchDefaults        `db:"marshal=json"`
+
+	CreatedNetworks []api.BatchCreatedNetwork `db:"marshal=json"`
+
+	MigrationWindowSchedules  []api.MigrationWindowSchedule  `db:"marshal=json"`
+	MigrationWindowExclusions []api.MigrationWindowExclusion `db:"marshal=json"`
 }
 
 // GetIncusPlacement returns a TargetPlacement for the given instance and its networks.
@@ -96,6 +107,158 @@ func (b *Batch) GetIncusPlacement(instance Instance, usedNetworks Networks, plac
 	return resp, nil
 }
 
+// PostMigrationScripts returns the scripts to run on the instance in the given stage.
+// Scripts of the batch come first, followed by those from the instance overrides.
+func (b Batch) PostMigrationScripts(instance Instance, stage api.PostMigrationScriptStage) []api.PostMigrationScript {
+	var scripts []api.PostMigrationScript
+	for _, s := range append(slices.Clone(b.Config.PostMigrationScripts), instance.Overrides.PostMigrationScripts...) {
+		if s.Stage != stage {
+			continue
+		}
+
+		if !slices.ContainsFunc(scripts, func(existing api.PostMigrationScript) bool { return existing.Artifact == s.Artifact }) {
+			scripts = append(scripts, s)
+		}
+	}
+
+	return scripts
+}
+
+// ApplyDefaultInstanceConfig applies the profiles, configuration and devices from the batch defaults to the Incus definition of the instance.
+// The batch profiles are added after the default profile, and devices are merged into any existing device of the same name.
+func (b Batch) ApplyDefaultInstanceConfig(instance Instance, def *incusAPI.InstancePut) error {
+	if len(b.Defaults.Profiles) == 0 && len(b.Defaults.Config) == 0 && len(b.Defaults.Devices) == 0 {
+		return nil
+	}
+
+	fields := instanceTemplateFields(b, instance)
+
+	if def.Profiles == nil {
+		def.Profiles = []string{"default"}
+	}
+
+	for _, profile := range b.Defaults.Profiles {
+		if !slices.Contains(def.Profiles, profile) {
+			def.Profiles = append(def.Profiles, profile)
+		}
+	}
+
+	if def.Config == nil {
+		def.Config = map[string]string{}
+	}
+
+	for key, value := range b.Defaults.Config {
+		expanded, err := expandInstanceTemplate(value, fields)
+		if err != nil {
+			return fmt.Errorf("Failed to expand config key %q for instance %q: %w", key, instance.Properties.Location, err)
+		}
+
+		def.Config[key] = expanded
+	}
+
+	if def.Devices == nil {
+		def.Devices = map[string]map[string]string{}
+	}
+
+	for name, device := range b.Defaults.Devices {
+		newDevice := map[string]string{}
+		maps.Copy(newDevice, def.Devices[name])
+		for key, value := range device {
+			expanded, err := expandInstanceTemplate(value, fields)
+			if err != nil {
+				return fmt.Errorf("Failed to expand key %q of device %q for instance %q: %w", key, name, instance.Properties.Location, err)
+			}
+
+			newDevice[key] = expanded
+		}
+
+		def.Devices[name] = newDevice
+	}
+
+	return nil
+}
+
+// instanceTemplateFields returns the instance fields that can be referenced by templates in the batch defaults.
+func instanceTemplateFields(b Batch, instance Instance) map[string]any {
+	distro, distroVersion := instance.GetDistribution(true)
+
+	return map[string]any{
+		"uuid":                 instance.UUID.String(),
+		"name":                 instance.GetName(),
+		"location":             instance.Properties.Location,
+		"source":               instance.Source,
+		"os":                   instance.Properties.OS,
+		"os_type":              string(instance.GetOSType(true)),
+		"distribution":         string(distro),
+		"distribution_version": distroVersion,
+		"architecture":         instance.GetArchitecture(),
+		"batch":                b.Name,
+	}
+}
+
+// expandInstanceTemplate expands a template in the batch defaults with the given instance fields.
+func expandInstanceTemplate(value string, fields map[string]any) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tpl, err := template.New("").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	err = tpl.Execute(&sb, fields)
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// validateDefaultInstanceConfig ensures the profiles, configuration and devices in the batch defaults are valid.
+func (b Batch) validateDefaultInstanceConfig() error {
+	for _, profile := range b.Defaults.Profiles {
+		err := validate.IsAPIName(profile, false)
+		if err != nil {
+			return NewValidationErrf("Invalid batch defaults, profile %q is not a valid name: %v", profile, err)
+		}
+	}
+
+	// Templates are checked against a placeholder instance, to catch references to unknown fields.
+	fields := instanceTemplateFields(b, Instance{Properties: api.InstanceProperties{OS: "placeholder", OSDescription: "placeholder"}})
+	for key, value := range b.Defaults.Config {
+		if key == "" || strings.HasPrefix(key, "volatile.") || strings.HasPrefix(key, "user.migration.") {
+			return NewValidationErrf("Invalid batch defaults, config key %q cannot be set", key)
+		}
+
+		_, err := expandInstanceTemplate(value, fields)
+		if err != nil {
+			return NewValidationErrf("Invalid batch defaults, config key %q has an invalid template: %v", key, err)
+		}
+	}
+
+	for name, device := range b.Defaults.Devices {
+		err := validate.IsDeviceName(name)
+		if err != nil {
+			return NewValidationErrf("Invalid batch defaults, device name %q is not valid: %v", name, err)
+		}
+
+		if device["type"] == "" {
+			return NewValidationErrf("Invalid batch defaults, device %q is missing a type", name)
+		}
+
+		for key, value := range device {
+			_, err := expandInstanceTemplate(value, fields)
+			if err != nil {
+				return NewValidationErrf("Invalid batch defaults, key %q of device %q has an invalid template: %v", key, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (b Batch) CanStart() bool {
 	switch b.Status {
 	case api.BATCHSTATUS_DEFINED,
@@ -165,6 +328,11 @@ func (b Batch) Validate() error {
 		existingTargets[netCfg.Target][netCfg.TargetProject] = true
 	}
 
+	err = b.validateDefaultInstanceConfig()
+	if err != nil {
+		return err
+	}
+
 	err = b.Status.Validate()
 	if err != nil {
 		return NewValidationErrf("Invalid status: %v", err)
@@ -207,6 +375,59 @@ func (b Batch) Validate() error {
 		}
 	}
 
+	groupNames := map[string]bool{}
+	for _, g := range b.InstanceGroups {
+		err := validate.IsAPIName(g.Name, false)
+		if err != nil {
+			return NewValidationErrf("Invalid instance group, %q is not a valid name: %v", g.Name, err)
+		}
+
+		if groupNames[g.Name] {
+			return NewValidationErrf("Invalid instance group, name %q cannot be used more than once", g.Name)
+		}
+
+		groupNames[g.Name] = true
+		if g.IncludeExpression != "" {
+			_, _, err = Instance{}.CompileIncludeExpression(g.IncludeExpression, false)
+			if err != nil {
+				return NewValidationErrf("Invalid instance group %q, %q is not a valid include expression: %v", g.Name, g.IncludeExpression, err)
+			}
+		}
+
+		for _, expr := range g.Order {
+			_, _, err = Instance{}.CompileIncludeExpression(expr, false)
+			if err != nil {
+				return NewValidationErrf("Invalid instance group %q, order %q is not a valid include expression: %v", g.Name, expr, err)
+			}
+		}
+	}
+
+	scheduleNames := map[string]bool{}
+	for _, s := range b.MigrationWindowSchedules {
+		if scheduleNames[s.Name] {
+			return NewValidationErrf("Invalid migration window schedule, name %q cannot be used more than once", s.Name)
+		}
+
+		scheduleNames[s.Name] = true
+		err := ValidateWindowSchedule(s)
+		if err != nil {
+			return NewValidationErrf("Invalid migration window schedule %q: %v", s.Name, err)
+		}
+	}
+
+	exclusionNames := map[string]bool{}
+	for _, e := range b.MigrationWindowExclusions {
+		if exclusionNames[e.Name] {
+			return NewValidationErrf("Invalid migration window exclusion, name %q cannot be used more than once", e.Name)
+		}
+
+		exclusionNames[e.Name] = true
+		err := ValidateWindowExclusion(e)
+		if err != nil {
+			return NewValidationErrf("Invalid migration window exclusion %q: %v", e.Name, err)
+		}
+	}
+
 	if b.Status == api.BATCHSTATUS_DEFINED && !b.StartDate.IsZero() {
 		return NewValidationErrf("Cannot set start time before batch %q has started", b.Name)
 	}
@@ -218,6 +439,13 @@ func (b Batch) Validate() error {
 		}
 	}
 
+	if b.Config.InstanceConfigScriptlet != "" {
+		err := scriptlet.BatchInstanceConfigValidate(b.Config.InstanceConfigScriptlet, b.Name)
+		if err != nil {
+			return NewValidationErrf("Invalid instance configuration scriptlet: %v", err)
+		}
+	}
+
 	if b.Config.BackgroundSyncInterval.Duration <= 0 {
 		return NewValidationErrf("Invalid background sync interval %q", b.Config.BackgroundSyncInterval)
 	}
@@ -230,9 +458,117 @@ func (b Batch) Validate() error {
 		return NewValidationErrf("Final background sync limit %q cannot be greater than the background sync interval %q", b.Config.FinalBackgroundSyncLimit, b.Config.BackgroundSyncInterval)
 	}
 
+	if b.Config.CreateTargetNetworksUplink != "" && !b.Config.CreateTargetNetworks {
+		return NewValidationErrf("Invalid batch, uplink network %q requires target network creation to be enabled", b.Config.CreateTargetNetworksUplink)
+	}
+
+	checkNames := map[string]bool{}
+	for _, c := range b.Config.ValidationChecks {
+		err := validate.IsAPIName(c.Name, false)
+		if err != nil {
+			return NewValidationErrf("Invalid validation check, %q is not a valid name: %v", c.Name, err)
+		}
+
+		if checkNames[c.Name] {
+			return NewValidationErrf("Invalid validation check, name %q cannot be used more than once", c.Name)
+		}
+
+		checkNames[c.Name] = true
+		err = c.Type.Validate()
+		if err != nil {
+			return NewValidationErrf("Invalid validation check %q: %v", c.Name, err)
+		}
+
+		if c.Type == api.VALIDATIONCHECK_COMMAND && len(c.Command) == 0 {
+			return NewValidationErrf("Invalid validation check %q, command checks require a command", c.Name)
+		}
+
+		if c.Type != api.VALIDATIONCHECK_COMMAND && len(c.Command) > 0 {
+			return NewValidationErrf("Invalid validation check %q, only command checks can set a command", c.Name)
+		}
+
+		if c.Type == api.VALIDATIONCHECK_COMMAND && c.Target != "" {
+			return NewValidationErrf("Invalid validation check %q, command checks cannot set a target", c.Name)
+		}
+
+		if c.Target == "" && c.Type != api.VALIDATIONCHECK_COMMAND && c.Type != api.VALIDATIONCHECK_PING {
+			return NewValidationErrf("Invalid validation check %q, %s checks require a target", c.Name, c.Type)
+		}
+
+		if c.Timeout.Duration < 0 {
+			return NewValidationErrf("Invalid validation check %q, timeout %q must not be negative", c.Name, c.Timeout)
+		}
+
+		if c.IncludeExpression != "" {
+			_, _, err = Instance{}.CompileIncludeExpression(c.IncludeExpression, false)
+			if err != nil {
+				return NewValidationErrf("Invalid validation check %q, %q is not a valid include expression: %v", c.Name, c.IncludeExpression, err)
+			}
+		}
+	}
+
+	if b.Config.RollbackOnValidationFailure && len(b.Config.ValidationChecks) == 0 {
+		return NewValidationErrf("Invalid batch, rollback on validation failure requires validation checks")
+	}
+
+	err = ValidatePostMigrationScripts(b.Config.PostMigrationScripts)
+	if err != nil {
+		return NewValidationErrf("Invalid batch, %v", err)
+	}
+
+	err = b.Config.DiskVerification.Validate()
+	if err != nil {
+		return NewValidationErrf("Invalid batch, %v", err)
+	}
+
+	for _, t := range b.Config.BootOrder {
+		_, _, err = Instance{}.CompileIncludeExpression(t.IncludeExpression, false)
+		if err != nil {
+			return NewValidationErrf("Invalid boot order tier %d, %q is not a valid include expression: %v", t.Tier, t.IncludeExpression, err)
+		}
+
+		if t.Tier < 0 {
+			return NewValidationErrf("Invalid boot order tier %d, tier must not be negative", t.Tier)
+		}
+
+		if t.Delay.Duration < 0 {
+			return NewValidationErrf("Invalid boot order tier %d, delay %q must not be negative", t.Tier, t.Delay.String())
+		}
+	}
+
 	return nil
 }
 
+// GetBootTier returns the first boot order tier of the batch that matches the instance, or nil if the instance matches none.
+// Members of instance groups follow the order of their group instead, and are never assigned a boot order tier.
+func (b Batch) GetBootTier(instance Instance) (*api.BatchBootTier, error) {
+	if len(b.Config.BootOrder) == 0 {
+		return nil, nil
+	}
+
+	group, _, err := b.GetInstanceGroup(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	if group != "" {
+		return nil, nil
+	}
+
+	for _, t := range b.Config.BootOrder {
+		match, err := instance.MatchesCriteria(t.IncludeExpression, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if match {
+			return &t, nil
+		}
+	}
+
+	return nil, nil
+}
+
 type Batches []Batch
 
 // ToAPI returns the API representation of a batch.
@@ -244,15 +580,19 @@ func (b Batch) ToAPI(windows Windows) api.Batch {
 
 	return api.Batch{
 		BatchPut: api.BatchPut{
-			Name:              b.Name,
-			IncludeExpression: b.IncludeExpression,
-			MigrationWindows:  apiWindows,
-			Constraints:       b.Constraints,
-			Defaults:          b.Defaults,
-			Config:            b.Config,
+			Name:                      b.Name,
+			IncludeExpression:         b.IncludeExpression,
+			MigrationWindows:          apiWindows,
+			MigrationWindowSchedules:  b.MigrationWindowSchedules,
+			MigrationWindowExclusions: b.MigrationWindowExclusions,
+			Constraints:               b.Constraints,
+			InstanceGroups:            b.InstanceGroups,
+			Defaults:                  b.Defaults,
+			Config:                    b.Config,
 		},
-		StartDate:     b.StartDate,
-		Status:        b.Status,
-		StatusMessage: b.StatusMessage,
+		StartDate:       b.StartDate,
+		Status:          b.Status,
+		StatusMessage:   b.StatusMessage,
+		CreatedNetworks: b.CreatedNetworks,
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 
 	"github.com/FuturFusion/migration-manager/shared/api"
 )
@@ -19,6 +20,8 @@ type BatchService interface {
 	GetByName(ctx context.Context, name string) (*Batch, error)
 	Update(ctx context.Context, queueSvc QueueService, name string, batch *Batch) error
 	UpdateStatusByName(ctx context.Context, name string, status api.BatchStatusType, statusMessage string) (*Batch, error)
+	AddCreatedNetworks(ctx context.Context, name string, networks []api.BatchCreatedNetwork) (*Batch, error)
+	UpdateInstancesAssignedToBatch(ctx context.Context, batch Batch) error
 	Rename(ctx context.Context, oldName string, newName string) error
 	DeleteByName(ctx context.Context, name string) error
 	StartBatchByName(ctx context.Context, name string, windowSvc WindowService, networkSvc NetworkService, queueSvc QueueService) (*Batch, error)
@@ -26,6 +29,7 @@ type BatchService interface {
 	ResetBatchByName(ctx context.Context, name string, queueSvc QueueService, sourceSvc SourceService, targetSvc TargetService, force bool) (*Batch, error)
 
 	DeterminePlacement(ctx context.Context, instance Instance, usedNetworks Networks, batch Batch, windows Windows) (*api.Placement, error)
+	ApplyInstanceConfig(ctx context.Context, instance Instance, usedNetworks Networks, batch Batch, def *incusAPI.InstancePut) (string, error)
 }
 
 //go:generate go run github.com/matryer/moq -fmt goimports -pkg mock -out repo/mock/batch_repo_mock_gen.go -rm . BatchRepo
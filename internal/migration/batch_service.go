@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 	incusScriptlet "github.com/lxc/incus/v6/shared/scriptlet"
 
 	"github.com/FuturFusion/migration-manager/internal/scriptlet"
@@ -62,6 +63,13 @@ func (s batchService) Create(ctx context.Context, batch Batch) (Batch, error) {
 		}
 	}
 
+	if batch.Config.InstanceConfigScriptlet != "" {
+		err := scriptlet.BatchInstanceConfigSet(s.scriptletLoader, batch.Config.InstanceConfigScriptlet, batch.Name)
+		if err != nil {
+			return Batch{}, err
+		}
+	}
+
 	return batch, nil
 }
 
@@ -92,6 +100,7 @@ func (s batchService) GetByName(ctx context.Context, name string) (*Batch, error
 // canUpdateRunningBatch returns an error if the modified batch cannot be committed because the batch is already running.
 // - Placement and instance filtering cannot be modified for a running batch.
 // - Constraints that match to queue entries that have already entered final import cannot be added or removed.
+// - Instance groups cannot be modified while any queue entry has entered final import.
 func (s batchService) canUpdateRunningBatch(ctx context.Context, queueSvc QueueService, newBatch Batch, oldBatch Batch) error {
 	if oldBatch.Status == api.BATCHSTATUS_DEFINED {
 		return nil
@@ -150,6 +159,14 @@ func (s batchService) canUpdateRunningBatch(ctx context.Context, queueSvc QueueS
 		}
 	}
 
+	groupsEqual := slices.EqualFunc(oldBatch.InstanceGroups, newBatch.InstanceGroups, func(a api.BatchInstanceGroup, b api.BatchInstanceGroup) bool {
+		return a.Name == b.Name && a.IncludeExpression == b.IncludeExpression && slices.Equal(a.Order, b.Order)
+	})
+
+	if !groupsEqual && slices.ContainsFunc(queueEntries, func(q QueueEntry) bool { return q.IsCommitted() }) {
+		return fmt.Errorf("Cannot modify instance groups of batch %q while queue entries are committed to a migration: %w", oldBatch.Name, ErrOperationNotPermitted)
+	}
+
 	if oldBatch.Name != newBatch.Name {
 		return fmt.Errorf("Cannot rename running batch %q: %w", oldBatch.Name, ErrOperationNotPermitted)
 	}
@@ -191,12 +208,16 @@ func (s batchService) Update(ctx context.Context, queueSvc QueueService, name st
 	}
 
 	var updateScriptlet bool
+	var updateInstanceConfigScriptlet bool
 	err = transaction.Do(ctx, func(ctx context.Context) error {
 		oldBatch, err := s.repo.GetByName(ctx, name)
 		if err != nil {
 			return err
 		}
 
+		// The compiled scriptlet is kept by batch name, so it is also recompiled if the batch is renamed.
+		updateInstanceConfigScriptlet = (oldBatch.Config.InstanceConfigScriptlet != batch.Config.InstanceConfigScriptlet || name != batch.Name) && batch.Config.InstanceConfigScriptlet != ""
+
 		if oldBatch.Status != api.BATCHSTATUS_DEFINED && !util.InTestingMode() {
 			err := s.canUpdateRunningBatch(ctx, queueSvc, *batch, *oldBatch)
 			if err != nil {
@@ -231,6 +252,13 @@ func (s batchService) Update(ctx context.Context, queueSvc QueueService, name st
 		}
 	}
 
+	if updateInstanceConfigScriptlet {
+		err := scriptlet.BatchInstanceConfigSet(s.scriptletLoader, batch.Config.InstanceConfigScriptlet, batch.Name)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -255,6 +283,27 @@ func (s batchService) UpdateStatusByName(ctx context.Context, name string, statu
 	return batch, nil
 }
 
+// AddCreatedNetworks records networks that were created on targets for the batch, so they can be removed when the batch is reset.
+func (s batchService) AddCreatedNetworks(ctx context.Context, name string, networks []api.BatchCreatedNetwork) (*Batch, error) {
+	var batch *Batch
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		var err error
+		batch, err = s.repo.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		batch.CreatedNetworks = append(batch.CreatedNetworks, networks...)
+
+		return s.repo.Update(ctx, batch.Name, *batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
 func (s batchService) UpdateInstancesAssignedToBatch(ctx context.Context, batch Batch) error {
 	if batch.Status != api.BATCHSTATUS_DEFINED {
 		return fmt.Errorf("Cannot update batch %q: Currently in a migration phase: %w", batch.Name, ErrOperationNotPermitted)
@@ -395,8 +444,15 @@ func (s batchService) StartBatchByName(ctx context.Context, batchName string, wi
 			return fmt.Errorf("Failed to get queue entries: %w", err)
 		}
 
+		// Instances rolled back in another batch can be migrated again, replacing their old queue entry.
 		queueMap := make(map[uuid.UUID]bool, len(queueEntries))
+		rolledBack := map[uuid.UUID]bool{}
 		for _, entry := range queueEntries {
+			if entry.BatchName != batchName && entry.MigrationStatus == api.MIGRATIONSTATUS_ROLLED_BACK {
+				rolledBack[entry.InstanceUUID] = true
+				continue
+			}
+
 			queueMap[entry.InstanceUUID] = true
 		}
 
@@ -449,6 +505,13 @@ func (s batchService) StartBatchByName(ctx context.Context, batchName string, wi
 				message = "Instance no longer matches batch expression"
 			}
 
+			if rolledBack[inst.UUID] {
+				err = queueSvc.DeleteByUUID(ctx, inst.UUID)
+				if err != nil {
+					return fmt.Errorf("Failed to remove rolled back queue entry for instance %q: %w", inst.Properties.Location, err)
+				}
+			}
+
 			_, err = queueSvc.CreateEntry(ctx, QueueEntry{
 				InstanceUUID:           inst.UUID,
 				BatchName:              batchName,
@@ -533,7 +596,38 @@ func (s batchService) DeterminePlacement(ctx context.Context, instance Instance,
 	return batch.GetIncusPlacement(instance, usedNetworks, *rawPlacement)
 }
 
+// ApplyInstanceConfig runs the instance configuration scriptlet of the batch against the given Incus instance definition, modifying it in place.
+// If the scriptlet renames the instance, the new name is returned.
+func (s batchService) ApplyInstanceConfig(ctx context.Context, instance Instance, usedNetworks Networks, batch Batch, def *incusAPI.InstancePut) (string, error) {
+	if batch.Config.InstanceConfigScriptlet == "" {
+		return "", nil
+	}
+
+	apiNetworks := make([]api.Network, 0, len(usedNetworks))
+	for _, n := range usedNetworks {
+		apiNet, err := n.ToAPI()
+		if err != nil {
+			return "", err
+		}
+
+		apiNetworks = append(apiNetworks, *apiNet)
+	}
+
+	// The scriptlet is compiled when the batch is created or updated, so it only needs compiling here if it has not been loaded since the daemon started.
+	_, _, err := scriptlet.BatchInstanceConfigProgram(s.scriptletLoader, batch.Name)
+	if err != nil {
+		err := scriptlet.BatchInstanceConfigSet(s.scriptletLoader, batch.Config.InstanceConfigScriptlet, batch.Name)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return scriptlet.BatchInstanceConfigRun(ctx, s.scriptletLoader, instance.ToAPI(), batch.ToAPI(nil), apiNetworks, def)
+}
+
 // ResetBatchByName returns the batch to Defined state, and removes all associated queue entries. Also cleans up target and source concurrency limits.
+// The record of networks created for the batch is cleared, so the caller is responsible for removing them from the targets,
+// and for recording again any network it fails to remove.
 func (s batchService) ResetBatchByName(ctx context.Context, name string, queueSvc QueueService, sourceSvc SourceService, targetSvc TargetService, force bool) (*Batch, error) {
 	var batch *Batch
 	err := transaction.Do(ctx, func(ctx context.Context) error {
@@ -579,6 +673,7 @@ func (s batchService) ResetBatchByName(ctx context.Context, name string, queueSv
 		batch.Status = api.BATCHSTATUS_DEFINED
 		batch.StatusMessage = string(api.BATCHSTATUS_DEFINED)
 		batch.StartDate = time.Time{}
+		batch.CreatedNetworks = nil
 		err = s.repo.Update(ctx, name, *batch)
 		if err != nil {
 			return fmt.Errorf("Failed to reset batch %q: %w", name, err)
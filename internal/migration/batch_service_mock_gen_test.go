@@ -9,6 +9,7 @@ import (
 
 	"github.com/FuturFusion/migration-manager/internal/migration"
 	"github.com/FuturFusion/migration-manager/shared/api"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 )
 
 // Ensure, that BatchServiceMock does implement migration.BatchService.
@@ -21,6 +22,12 @@ var _ migration.BatchService = &BatchServiceMock{}
 //
 //		// make and configure a mocked migration.BatchService
 //		mockedBatchService := &BatchServiceMock{
+//			AddCreatedNetworksFunc: func(ctx context.Context, name string, networks []api.BatchCreatedNetwork) (*migration.Batch, error) {
+//				panic("mock out the AddCreatedNetworks method")
+//			},
+//			ApplyInstanceConfigFunc: func(ctx context.Context, instance migration.Instance, usedNetworks migration.Networks, batch migration.Batch, def *incusAPI.InstancePut) (string, error) {
+//				panic("mock out the ApplyInstanceConfig method")
+//			},
 //			CreateFunc: func(ctx context.Context, batch migration.Batch) (migration.Batch, error) {
 //				panic("mock out the Create method")
 //			},
@@ -60,6 +67,9 @@ var _ migration.BatchService = &BatchServiceMock{}
 //			UpdateFunc: func(ctx context.Context, queueSvc migration.QueueService, name string, batch *migration.Batch) error {
 //				panic("mock out the Update method")
 //			},
+//			UpdateInstancesAssignedToBatchFunc: func(ctx context.Context, batch migration.Batch) error {
+//				panic("mock out the UpdateInstancesAssignedToBatch method")
+//			},
 //			UpdateStatusByNameFunc: func(ctx context.Context, name string, status api.BatchStatusType, statusMessage string) (*migration.Batch, error) {
 //				panic("mock out the UpdateStatusByName method")
 //			},
@@ -70,6 +80,12 @@ var _ migration.BatchService = &BatchServiceMock{}
 //
 //	}
 type BatchServiceMock struct {
+	// AddCreatedNetworksFunc mocks the AddCreatedNetworks method.
+	AddCreatedNetworksFunc func(ctx context.Context, name string, networks []api.BatchCreatedNetwork) (*migration.Batch, error)
+
+	// ApplyInstanceConfigFunc mocks the ApplyInstanceConfig method.
+	ApplyInstanceConfigFunc func(ctx context.Context, instance migration.Instance, usedNetworks migration.Networks, batch migration.Batch, def *incusAPI.InstancePut) (string, error)
+
 	// CreateFunc mocks the Create method.
 	CreateFunc func(ctx context.Context, batch migration.Batch) (migration.Batch, error)
 
@@ -109,11 +125,36 @@ type BatchServiceMock struct {
 	// UpdateFunc mocks the Update method.
 	UpdateFunc func(ctx context.Context, queueSvc migration.QueueService, name string, batch *migration.Batch) error
 
+	// UpdateInstancesAssignedToBatchFunc mocks the UpdateInstancesAssignedToBatch method.
+	UpdateInstancesAssignedToBatchFunc func(ctx context.Context, batch migration.Batch) error
+
 	// UpdateStatusByNameFunc mocks the UpdateStatusByName method.
 	UpdateStatusByNameFunc func(ctx context.Context, name string, status api.BatchStatusType, statusMessage string) (*migration.Batch, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// AddCreatedNetworks holds details about calls to the AddCreatedNetworks method.
+		AddCreatedNetworks []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Name is the name argument value.
+			Name string
+			// Networks is the networks argument value.
+			Networks []api.BatchCreatedNetwork
+		}
+		// ApplyInstanceConfig holds details about calls to the ApplyInstanceConfig method.
+		ApplyInstanceConfig []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Instance is the instance argument value.
+			Instance migration.Instance
+			// UsedNetworks is the usedNetworks argument value.
+			UsedNetworks migration.Networks
+			// Batch is the batch argument value.
+			Batch migration.Batch
+			// Def is the def argument value.
+			Def *incusAPI.InstancePut
+		}
 		// Create holds details about calls to the Create method.
 		Create []struct {
 			// Ctx is the ctx argument value.
@@ -227,6 +268,13 @@ type BatchServiceMock struct {
 			// Batch is the batch argument value.
 			Batch *migration.Batch
 		}
+		// UpdateInstancesAssignedToBatch holds details about calls to the UpdateInstancesAssignedToBatch method.
+		UpdateInstancesAssignedToBatch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Batch is the batch argument value.
+			Batch migration.Batch
+		}
 		// UpdateStatusByName holds details about calls to the UpdateStatusByName method.
 		UpdateStatusByName []struct {
 			// Ctx is the ctx argument value.
@@ -239,20 +287,111 @@ type BatchServiceMock struct {
 			StatusMessage string
 		}
 	}
-	lockCreate             sync.RWMutex
-	lockDeleteByName       sync.RWMutex
-	lockDeterminePlacement sync.RWMutex
-	lockGetAll             sync.RWMutex
-	lockGetAllByState      sync.RWMutex
-	lockGetAllNames        sync.RWMutex
-	lockGetAllNamesByState sync.RWMutex
-	lockGetByName          sync.RWMutex
-	lockRename             sync.RWMutex
-	lockResetBatchByName   sync.RWMutex
-	lockStartBatchByName   sync.RWMutex
-	lockStopBatchByName    sync.RWMutex
-	lockUpdate             sync.RWMutex
-	lockUpdateStatusByName sync.RWMutex
+	lockAddCreatedNetworks             sync.RWMutex
+	lockApplyInstanceConfig            sync.RWMutex
+	lockCreate                         sync.RWMutex
+	lockDeleteByName                   sync.RWMutex
+	lockDeterminePlacement             sync.RWMutex
+	lockGetAll                         sync.RWMutex
+	lockGetAllByState                  sync.RWMutex
+	lockGetAllNames                    sync.RWMutex
+	lockGetAllNamesByState             sync.RWMutex
+	lockGetByName                      sync.RWMutex
+	lockRename                         sync.RWMutex
+	lockResetBatchByName               sync.RWMutex
+	lockStartBatchByName               sync.RWMutex
+	lockStopBatchByName                sync.RWMutex
+	lockUpdate                         sync.RWMutex
+	lockUpdateInstancesAssignedToBatch sync.RWMutex
+	lockUpdateStatusByName             sync.RWMutex
+}
+
+// AddCreatedNetworks calls AddCreatedNetworksFunc.
+func (mock *BatchServiceMock) AddCreatedNetworks(ctx context.Context, name string, networks []api.BatchCreatedNetwork) (*migration.Batch, error) {
+	if mock.AddCreatedNetworksFunc == nil {
+		panic("BatchServiceMock.AddCreatedNetworksFunc: method is nil but BatchService.AddCreatedNetworks was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Name     string
+		Networks []api.BatchCreatedNetwork
+	}{
+		Ctx:      ctx,
+		Name:     name,
+		Networks: networks,
+	}
+	mock.lockAddCreatedNetworks.Lock()
+	mock.calls.AddCreatedNetworks = append(mock.calls.AddCreatedNetworks, callInfo)
+	mock.lockAddCreatedNetworks.Unlock()
+	return mock.AddCreatedNetworksFunc(ctx, name, networks)
+}
+
+// AddCreatedNetworksCalls gets all the calls that were made to AddCreatedNetworks.
+// Check the length with:
+//
+//	len(mockedBatchService.AddCreatedNetworksCalls())
+func (mock *BatchServiceMock) AddCreatedNetworksCalls() []struct {
+	Ctx      context.Context
+	Name     string
+	Networks []api.BatchCreatedNetwork
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Name     string
+		Networks []api.BatchCreatedNetwork
+	}
+	mock.lockAddCreatedNetworks.RLock()
+	calls = mock.calls.AddCreatedNetworks
+	mock.lockAddCreatedNetworks.RUnlock()
+	return calls
+}
+
+// ApplyInstanceConfig calls ApplyInstanceConfigFunc.
+func (mock *BatchServiceMock) ApplyInstanceConfig(ctx context.Context, instance migration.Instance, usedNetworks migration.Networks, batch migration.Batch, def *incusAPI.InstancePut) (string, error) {
+	if mock.ApplyInstanceConfigFunc == nil {
+		panic("BatchServiceMock.ApplyInstanceConfigFunc: method is nil but BatchService.ApplyInstanceConfig was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		Instance     migration.Instance
+		UsedNetworks migration.Networks
+		Batch        migration.Batch
+		Def          *incusAPI.InstancePut
+	}{
+		Ctx:          ctx,
+		Instance:     instance,
+		UsedNetworks: usedNetworks,
+		Batch:        batch,
+		Def:          def,
+	}
+	mock.lockApplyInstanceConfig.Lock()
+	mock.calls.ApplyInstanceConfig = append(mock.calls.ApplyInstanceConfig, callInfo)
+	mock.lockApplyInstanceConfig.Unlock()
+	return mock.ApplyInstanceConfigFunc(ctx, instance, usedNetworks, batch, def)
+}
+
+// ApplyInstanceConfigCalls gets all the calls that were made to ApplyInstanceConfig.
+// Check the length with:
+//
+//	len(mockedBatchService.ApplyInstanceConfigCalls())
+func (mock *BatchServiceMock) ApplyInstanceConfigCalls() []struct {
+	Ctx          context.Context
+	Instance     migration.Instance
+	UsedNetworks migration.Networks
+	Batch        migration.Batch
+	Def          *incusAPI.InstancePut
+} {
+	var calls []struct {
+		Ctx          context.Context
+		Instance     migration.Instance
+		UsedNetworks migration.Networks
+		Batch        migration.Batch
+		Def          *incusAPI.InstancePut
+	}
+	mock.lockApplyInstanceConfig.RLock()
+	calls = mock.calls.ApplyInstanceConfig
+	mock.lockApplyInstanceConfig.RUnlock()
+	return calls
 }
 
 // Create calls CreateFunc.
@@ -767,6 +906,42 @@ func (mock *BatchServiceMock) UpdateCalls() []struct {
 	return calls
 }
 
+// UpdateInstancesAssignedToBatch calls UpdateInstancesAssignedToBatchFunc.
+func (mock *BatchServiceMock) UpdateInstancesAssignedToBatch(ctx context.Context, batch migration.Batch) error {
+	if mock.UpdateInstancesAssignedToBatchFunc == nil {
+		panic("BatchServiceMock.UpdateInstancesAssignedToBatchFunc: method is nil but BatchService.UpdateInstancesAssignedToBatch was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Batch migration.Batch
+	}{
+		Ctx:   ctx,
+		Batch: batch,
+	}
+	mock.lockUpdateInstancesAssignedToBatch.Lock()
+	mock.calls.UpdateInstancesAssignedToBatch = append(mock.calls.UpdateInstancesAssignedToBatch, callInfo)
+	mock.lockUpdateInstancesAssignedToBatch.Unlock()
+	return mock.UpdateInstancesAssignedToBatchFunc(ctx, batch)
+}
+
+// UpdateInstancesAssignedToBatchCalls gets all the calls that were made to UpdateInstancesAssignedToBatch.
+// Check the length with:
+//
+//	len(mockedBatchService.UpdateInstancesAssignedToBatchCalls())
+func (mock *BatchServiceMock) UpdateInstancesAssignedToBatchCalls() []struct {
+	Ctx   context.Context
+	Batch migration.Batch
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Batch migration.Batch
+	}
+	mock.lockUpdateInstancesAssignedToBatch.RLock()
+	calls = mock.calls.UpdateInstancesAssignedToBatch
+	mock.lockUpdateInstancesAssignedToBatch.RUnlock()
+	return calls
+}
+
 // UpdateStatusByName calls UpdateStatusByNameFunc.
 func (mock *BatchServiceMock) UpdateStatusByName(ctx context.Context, name string, status api.BatchStatusType, statusMessage string) (*migration.Batch, error) {
 	if mock.UpdateStatusByNameFunc == nil {
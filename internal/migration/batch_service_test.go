@@ -3,11 +3,13 @@ package migration_test
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/stretchr/testify/require"
 
 	"github.com/FuturFusion/migration-manager/internal/migration"
@@ -125,6 +127,381 @@ func TestBatchService_Create(t *testing.T) {
 				require.ErrorAs(tt, err, &verr, a...)
 			},
 		},
+		{
+			name: "success - validation checks",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					ValidationChecks: []api.BatchValidationCheck{
+						{Name: "gateway", Type: api.VALIDATIONCHECK_PING},
+						{Name: "web", Type: api.VALIDATIONCHECK_HTTP, Target: "http://localhost", IncludeExpression: `location matches "^/web/"`, Timeout: api.AsDuration(time.Minute)},
+						{Name: "ready", Type: api.VALIDATIONCHECK_COMMAND, Command: []string{"true"}},
+					},
+					RollbackOnValidationFailure: true,
+				},
+			},
+			repoCreateBatch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					ValidationChecks: []api.BatchValidationCheck{
+						{Name: "gateway", Type: api.VALIDATIONCHECK_PING},
+						{Name: "web", Type: api.VALIDATIONCHECK_HTTP, Target: "http://localhost", IncludeExpression: `location matches "^/web/"`, Timeout: api.AsDuration(time.Minute)},
+						{Name: "ready", Type: api.VALIDATIONCHECK_COMMAND, Command: []string{"true"}},
+					},
+					RollbackOnValidationFailure: true,
+				},
+			},
+
+			assertErr: require.NoError,
+		},
+		{
+			name: "error - validation check type invalid",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					ValidationChecks:         []api.BatchValidationCheck{{Name: "check", Type: "invalid"}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - validation check without target",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					ValidationChecks:         []api.BatchValidationCheck{{Name: "check", Type: api.VALIDATIONCHECK_SYSTEMD_UNIT}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - validation check name duplicate",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					ValidationChecks:         []api.BatchValidationCheck{{Name: "check", Type: api.VALIDATIONCHECK_PING}, {Name: "check", Type: api.VALIDATIONCHECK_PING}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - rollback on validation failure without checks",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:      api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit:    api.AsDuration(10 * time.Minute),
+					RollbackOnValidationFailure: true,
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - invalid disk verification mode",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					DiskVerification:         "partial",
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - invalid migration window schedule",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+				MigrationWindowSchedules: []api.MigrationWindowSchedule{{Name: "nightly", Cron: "0 22 * * *"}},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - invalid migration window exclusion",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+				MigrationWindowExclusions: []api.MigrationWindowExclusion{{Name: "freeze", Start: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 19, 0, 0, 0, 0, time.UTC)}},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - duplicate instance group name",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+				InstanceGroups: []api.BatchInstanceGroup{{Name: "webshop", IncludeExpression: "true"}, {Name: "webshop", IncludeExpression: "false"}},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - invalid instance group order expression",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+				InstanceGroups: []api.BatchInstanceGroup{{Name: "webshop", IncludeExpression: "true", Order: []string{"invalid =="}}},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - negative boot order tier",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					BootOrder:                []api.BatchBootTier{{IncludeExpression: "true", Tier: -1}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - post-migration script without artifact",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					PostMigrationScripts:     []api.PostMigrationScript{{Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - post-migration script stage invalid",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					PostMigrationScripts:     []api.PostMigrationScript{{Artifact: uuidA, Stage: "invalid"}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - post-migration script timeout negative",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          defaultPlacement,
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					PostMigrationScripts:     []api.PostMigrationScript{{Artifact: uuidA, Stage: api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT, Timeout: api.AsDuration(-time.Minute)}},
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - default profile invalid",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          api.BatchDefaults{Placement: defaultPlacement.Placement, Profiles: []string{"web profile"}},
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - default config key protected",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          api.BatchDefaults{Placement: defaultPlacement.Placement, Config: map[string]string{"user.migration.token": "abc"}},
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - default config template unknown field",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          api.BatchDefaults{Placement: defaultPlacement.Placement, Config: map[string]string{"user.owner": "{{ .owner }}"}},
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - default device missing type",
+			batch: migration.Batch{
+				ID:                1,
+				Name:              "one",
+				Defaults:          api.BatchDefaults{Placement: defaultPlacement.Placement, Devices: map[string]map[string]string{"gpu0": {"gputype": "physical"}}},
+				IncludeExpression: "true",
+				Status:            api.BATCHSTATUS_DEFINED,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+				},
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
 		{
 			name: "error - repo",
 			batch: migration.Batch{
@@ -1113,6 +1490,78 @@ func TestBatchService_UpdateStatusByName(t *testing.T) {
 	}
 }
 
+func TestBatchService_AddCreatedNetworks(t *testing.T) {
+	tests := []struct {
+		name               string
+		repoGetByNameBatch *migration.Batch
+		repoGetByNameErr   error
+		repoUpdateErr      error
+
+		assertErr           require.ErrorAssertionFunc
+		wantCreatedNetworks []api.BatchCreatedNetwork
+	}{
+		{
+			name: "success",
+			repoGetByNameBatch: &migration.Batch{
+				ID:              1,
+				Name:            "one",
+				Status:          api.BATCHSTATUS_RUNNING,
+				CreatedNetworks: []api.BatchCreatedNetwork{{Name: "net1", Target: "tgt", TargetProject: "default"}},
+			},
+
+			assertErr:           require.NoError,
+			wantCreatedNetworks: []api.BatchCreatedNetwork{{Name: "net1", Target: "tgt", TargetProject: "default"}, {Name: "net2", Target: "tgt", TargetProject: "default"}},
+		},
+		{
+			name:             "error - repo.GetByName",
+			repoGetByNameErr: boom.Error,
+
+			assertErr: boom.ErrorIs,
+		},
+		{
+			name: "error - repo.Update",
+			repoGetByNameBatch: &migration.Batch{
+				ID:     1,
+				Name:   "one",
+				Status: api.BATCHSTATUS_RUNNING,
+			},
+			repoUpdateErr: boom.Error,
+
+			assertErr: boom.ErrorIs,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			// Setup
+			var updated migration.Batch
+			repo := &mock.BatchRepoMock{
+				GetByNameFunc: func(ctx context.Context, name string) (*migration.Batch, error) {
+					return tc.repoGetByNameBatch, tc.repoGetByNameErr
+				},
+				UpdateFunc: func(ctx context.Context, name string, b migration.Batch) error {
+					updated = b
+					return tc.repoUpdateErr
+				},
+			}
+
+			batchSvc := migration.NewBatchService(repo, nil)
+
+			// Run test
+			batch, err := batchSvc.AddCreatedNetworks(context.Background(), "one", []api.BatchCreatedNetwork{{Name: "net2", Target: "tgt", TargetProject: "default"}})
+
+			// Assert
+			tc.assertErr(t, err)
+			if tc.wantCreatedNetworks != nil {
+				require.Equal(t, tc.wantCreatedNetworks, batch.CreatedNetworks)
+				require.Equal(t, tc.wantCreatedNetworks, updated.CreatedNetworks)
+			}
+		})
+	}
+}
+
 func TestBatchService_DeleteByName(t *testing.T) {
 	tests := []struct {
 		name                              string
@@ -1270,9 +1719,11 @@ func TestBatchService_StartBatchByName(t *testing.T) {
 		batchName            string
 		initBatchState       api.BatchStatusType
 		queueEntriesByBatch  map[string][]string
+		rolledBackEntries    []string
 		numMatchingInstances map[bool][]string
 
-		addedQueueEntries []string
+		addedQueueEntries   []string
+		deletedQueueEntries []string
 
 		repoGetByNameErr error
 		repoUpdateErr    error
@@ -1341,6 +1792,19 @@ func TestBatchService_StartBatchByName(t *testing.T) {
 
 			assertErr: require.NoError,
 		},
+		{
+			name:                 "success - instances rolled back in another batch are queued again",
+			batchName:            "one",
+			initBatchState:       api.BATCHSTATUS_DEFINED,
+			queueEntriesByBatch:  map[string][]string{"two": {asUUID(2), asUUID(3)}},
+			rolledBackEntries:    []string{asUUID(3)},
+			numMatchingInstances: map[bool][]string{true: {asUUID(1), asUUID(2), asUUID(3)}},
+
+			addedQueueEntries:   []string{asUUID(1), asUUID(3)},
+			deletedQueueEntries: []string{asUUID(3)},
+
+			assertErr: require.NoError,
+		},
 		{
 			name:      "error - empty name",
 			batchName: "",
@@ -1467,6 +1931,7 @@ func TestBatchService_StartBatchByName(t *testing.T) {
 			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
 
 			newQueueEntries := []string{}
+			deletedQueueEntries := []string{}
 			repo := &mock.BatchRepoMock{
 				GetByNameFunc: func(ctx context.Context, name string) (*migration.Batch, error) {
 					includeExpr := make([]string, 0, len(tc.numMatchingInstances[true]))
@@ -1497,12 +1962,21 @@ func TestBatchService_StartBatchByName(t *testing.T) {
 					entries := migration.QueueEntries{}
 					for batch, ids := range tc.queueEntriesByBatch {
 						for _, id := range ids {
-							entries = append(entries, migration.QueueEntry{BatchName: batch, InstanceUUID: uuid.MustParse(id)})
+							status := api.MIGRATIONSTATUS_WAITING
+							if slices.Contains(tc.rolledBackEntries, id) {
+								status = api.MIGRATIONSTATUS_ROLLED_BACK
+							}
+
+							entries = append(entries, migration.QueueEntry{BatchName: batch, InstanceUUID: uuid.MustParse(id), MigrationStatus: status})
 						}
 					}
 
 					return entries, tc.queueSvcGetAllErr
 				},
+				DeleteByUUIDFunc: func(ctx context.Context, id uuid.UUID) error {
+					deletedQueueEntries = append(deletedQueueEntries, id.String())
+					return nil
+				},
 				CreateEntryFunc: func(ctx context.Context, queue migration.QueueEntry) (migration.QueueEntry, error) {
 					if tc.queueSvcCreateErr == nil {
 						newQueueEntries = append(newQueueEntries, queue.InstanceUUID.String())
@@ -1549,6 +2023,7 @@ func TestBatchService_StartBatchByName(t *testing.T) {
 			tc.assertErr(t, err)
 
 			require.Len(t, newQueueEntries, len(tc.addedQueueEntries))
+			require.ElementsMatch(t, tc.deletedQueueEntries, deletedQueueEntries)
 		})
 	}
 }
@@ -2124,3 +2599,247 @@ def placement(instance, batch):
 		})
 	}
 }
+
+func TestBatchService_ApplyInstanceConfig(t *testing.T) {
+	newDef := func() incusAPI.InstancePut {
+		return incusAPI.InstancePut{
+			Config:  map[string]string{"limits.cpu": "2", "user.migration.uuid": "abc"},
+			Devices: incusAPI.DevicesMap{"root": {"type": "disk", "path": "/", "pool": "default"}, "eth0": {"type": "nic", "network": "net1"}},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		scriptlet string
+		instance  api.InstanceProperties
+		networks  migration.Networks
+
+		batchCreateAssertErr require.ErrorAssertionFunc
+		applyAssertErr       require.ErrorAssertionFunc
+		wantDef              incusAPI.InstancePut
+		wantName             string
+	}{
+		{
+			name: "success - no scriptlet",
+
+			wantDef:              newDef(),
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.NoError,
+		},
+		{
+			name:     "success - with scriptlet",
+			instance: api.InstanceProperties{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm1"}, Location: "/web/vm1"},
+			networks: migration.Networks{{SourceSpecificID: "srcnet1", Location: "/path/to/netname1"}},
+
+			scriptlet: `
+def instance_config(instance, batch, networks):
+			set_config("limits.cpu", "")
+			set_config("limits.memory", "4GiB")
+			set_config("user.owner", batch.name)
+			add_device("gpu0", {"type": "gpu", "gputype": "physical"})
+			remove_device("eth0")
+			set_device_config("root", "boot.priority", "1")
+			add_profile("web")
+			remove_profile("default")
+			set_name("web-" + instance.name + "-" + str(len(networks)))
+			`,
+
+			wantDef: incusAPI.InstancePut{
+				Config:   map[string]string{"limits.memory": "4GiB", "user.migration.uuid": "abc", "user.owner": "testbatch"},
+				Devices:  incusAPI.DevicesMap{"root": {"type": "disk", "path": "/", "pool": "default", "boot.priority": "1"}, "gpu0": {"type": "gpu", "gputype": "physical"}},
+				Profiles: []string{"web"},
+			},
+			wantName:             "web-vm1-1",
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.NoError,
+		},
+		{
+			name: "error - scriptlet syntax",
+
+			scriptlet: `
+def instance_config(instance, batch):
+			set_name("test")
+			`,
+
+			batchCreateAssertErr: require.Error,
+		},
+		{
+			name: "error - set protected config key",
+
+			scriptlet: `
+def instance_config(instance, batch, networks):
+			set_config("user.migration.token", "abc")
+			`,
+
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.Error,
+		},
+		{
+			name: "error - add device without type",
+
+			scriptlet: `
+def instance_config(instance, batch, networks):
+			add_device("gpu0", {"gputype": "physical"})
+			`,
+
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.Error,
+		},
+		{
+			name: "error - remove root disk",
+
+			scriptlet: `
+def instance_config(instance, batch, networks):
+			remove_device("root")
+			`,
+
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.Error,
+		},
+		{
+			name: "error - set config of unknown device",
+
+			scriptlet: `
+def instance_config(instance, batch, networks):
+			set_device_config("disk1", "boot.priority", "1")
+			`,
+
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.Error,
+		},
+		{
+			name: "error - invalid name",
+
+			scriptlet: `
+def instance_config(instance, batch, networks):
+			set_name("web_vm1")
+			`,
+
+			batchCreateAssertErr: require.NoError,
+			applyAssertErr:       require.Error,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+			ctx := context.Background()
+			repo := &mock.BatchRepoMock{
+				CreateFunc: func(ctx context.Context, batch migration.Batch) (int64, error) {
+					return 1, nil
+				},
+			}
+
+			instanceSvc := &InstanceServiceMock{
+				GetAllByBatchFunc: func(ctx context.Context, batch string) (migration.Instances, error) { return nil, nil },
+				GetAllFunc:        func(ctx context.Context) (migration.Instances, error) { return nil, nil },
+			}
+
+			batchSvc := migration.NewBatchService(repo, instanceSvc)
+			batch, err := batchSvc.Create(ctx, migration.Batch{
+				Name:              "testbatch",
+				Status:            api.BATCHSTATUS_DEFINED,
+				IncludeExpression: "true",
+				Defaults:          defaultPlacement,
+				Config: api.BatchConfig{
+					BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
+					FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+					InstanceConfigScriptlet:  tc.scriptlet,
+				},
+			})
+			tc.batchCreateAssertErr(t, err)
+
+			if err == nil {
+				def := newDef()
+				name, err := batchSvc.ApplyInstanceConfig(ctx, migration.Instance{Properties: tc.instance}, tc.networks, batch, &def)
+				tc.applyAssertErr(t, err)
+
+				if err == nil {
+					require.Equal(t, tc.wantDef, def)
+					require.Equal(t, tc.wantName, name)
+				}
+
+				// After a restart, the scriptlet is compiled on first use.
+				def = newDef()
+				name, err = migration.NewBatchService(repo, instanceSvc).ApplyInstanceConfig(ctx, migration.Instance{Properties: tc.instance}, tc.networks, batch, &def)
+				tc.applyAssertErr(t, err)
+
+				if err == nil {
+					require.Equal(t, tc.wantDef, def)
+					require.Equal(t, tc.wantName, name)
+				}
+			}
+		})
+	}
+}
+
+func TestBatch_ApplyDefaultInstanceConfig(t *testing.T) {
+	inst := migration.Instance{
+		UUID:       uuidA,
+		Source:     "src1",
+		SourceType: api.SOURCETYPE_VMWARE,
+		Properties: api.InstanceProperties{
+			InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm1"},
+			Location:                       "/web/vm1",
+			OS:                             "ubuntu64Guest",
+			OSDescription:                  "Ubuntu 22.04",
+		},
+		Overrides: api.InstanceOverride{InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "web-vm1"}},
+	}
+
+	cases := []struct {
+		name     string
+		defaults api.BatchDefaults
+		def      incusAPI.InstancePut
+
+		assertErr require.ErrorAssertionFunc
+		wantDef   incusAPI.InstancePut
+	}{
+		{
+			name: "success - no defaults",
+			def:  incusAPI.InstancePut{Config: map[string]string{"limits.cpu": "2"}},
+
+			assertErr: require.NoError,
+			wantDef:   incusAPI.InstancePut{Config: map[string]string{"limits.cpu": "2"}},
+		},
+		{
+			name: "success - profiles, config and devices",
+			defaults: api.BatchDefaults{
+				Profiles: []string{"web", "default"},
+				Config:   map[string]string{"limits.cpu": "4", "user.origin": "{{ .source }}:{{ .location }}", "user.name": "{{ .batch }}-{{ .name }}-{{ .os_type }}"},
+				Devices:  map[string]map[string]string{"root": {"type": "disk", "size.state": "1GiB"}, "share": {"type": "disk", "source": "/srv/{{ .name }}", "path": "/srv"}},
+			},
+			def: incusAPI.InstancePut{
+				Config:  map[string]string{"limits.cpu": "2"},
+				Devices: incusAPI.DevicesMap{"root": {"type": "disk", "path": "/", "pool": "default"}},
+			},
+
+			assertErr: require.NoError,
+			wantDef: incusAPI.InstancePut{
+				Profiles: []string{"default", "web"},
+				Config:   map[string]string{"limits.cpu": "4", "user.origin": "src1:/web/vm1", "user.name": "batch1-web-vm1-linux"},
+				Devices: incusAPI.DevicesMap{
+					"root":  {"type": "disk", "path": "/", "pool": "default", "size.state": "1GiB"},
+					"share": {"type": "disk", "source": "/srv/web-vm1", "path": "/srv"},
+				},
+			},
+		},
+		{
+			name:     "error - unknown template field",
+			defaults: api.BatchDefaults{Config: map[string]string{"user.owner": "{{ .owner }}"}},
+
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		batch := migration.Batch{Name: "batch1", Defaults: tc.defaults}
+		err := batch.ApplyDefaultInstanceConfig(inst, &tc.def)
+		tc.assertErr(t, err)
+		if err == nil {
+			require.Equal(t, tc.wantDef, tc.def)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package migration
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// InstanceGroupMember is a queued instance belonging to an instance group.
+type InstanceGroupMember struct {
+	QueueEntry QueueEntry
+	Instance   Instance
+
+	// Position of the member in the order of its group. Members with a lower position are started first and powered off last.
+	Position int
+}
+
+// InstanceGroupMembers is the list of members of an instance group, sorted by position.
+type InstanceGroupMembers []InstanceGroupMember
+
+// ReadyForWindow returns whether the member is ready to be assigned a migration window, meaning its background import has completed.
+func (m InstanceGroupMember) ReadyForWindow() bool {
+	return m.QueueEntry.ReadyForWindow(m.Instance)
+}
+
+// PoweredOff returns whether the member has completed its final import, meaning its source VM has been powered off.
+func (m InstanceGroupMember) PoweredOff() bool {
+	return m.QueueEntry.PoweredOff()
+}
+
+// Failed returns whether the member's migration has failed, failed its validation checks, or was cancelled or rolled back, and needs to be retried.
+func (m InstanceGroupMember) Failed() bool {
+	switch m.QueueEntry.MigrationStatus {
+	case api.MIGRATIONSTATUS_ERROR,
+		api.MIGRATIONSTATUS_CONFLICT,
+		api.MIGRATIONSTATUS_VALIDATION_FAILED,
+		api.MIGRATIONSTATUS_CANCELED,
+		api.MIGRATIONSTATUS_ROLLED_BACK:
+		return true
+	}
+
+	return false
+}
+
+// ReadyForWindow returns whether all members of the group are ready to be assigned a migration window.
+func (ms InstanceGroupMembers) ReadyForWindow() bool {
+	for _, m := range ms {
+		if !m.ReadyForWindow() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Failed returns the first member of the group whose migration has failed, if any.
+func (ms InstanceGroupMembers) Failed() *InstanceGroupMember {
+	for _, m := range ms {
+		if m.Failed() {
+			return &m
+		}
+	}
+
+	return nil
+}
+
+// Get returns the member of the group with the given instance UUID, if any.
+func (ms InstanceGroupMembers) Get(instanceUUID uuid.UUID) *InstanceGroupMember {
+	for _, m := range ms {
+		if m.Instance.UUID == instanceUUID {
+			return &m
+		}
+	}
+
+	return nil
+}
+
+// ByPosition splits the group into lists of members that share the same position, in ascending order.
+func (ms InstanceGroupMembers) ByPosition() []InstanceGroupMembers {
+	tiers := []InstanceGroupMembers{}
+	for i, m := range ms {
+		if i == 0 || ms[i-1].Position != m.Position {
+			tiers = append(tiers, InstanceGroupMembers{})
+		}
+
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], m)
+	}
+
+	return tiers
+}
+
+// GetInstanceGroup returns the name of the instance group that the instance belongs to in the batch, and its position within that group.
+// The group set by the instance override takes precedence, followed by the first batch instance group whose include expression matches.
+// Returns an empty name if the instance does not belong to any group.
+func (b Batch) GetInstanceGroup(instance Instance) (string, int, error) {
+	name := instance.Overrides.Group
+	if name == "" {
+		for _, g := range b.InstanceGroups {
+			if g.IncludeExpression == "" {
+				continue
+			}
+
+			match, err := instance.MatchesCriteria(g.IncludeExpression, false)
+			if err != nil {
+				return "", 0, err
+			}
+
+			if match {
+				name = g.Name
+				break
+			}
+		}
+	}
+
+	if name == "" {
+		return "", 0, nil
+	}
+
+	for _, g := range b.InstanceGroups {
+		if g.Name != name {
+			continue
+		}
+
+		for i, expr := range g.Order {
+			match, err := instance.MatchesCriteria(expr, false)
+			if err != nil {
+				return "", 0, err
+			}
+
+			if match {
+				return name, i, nil
+			}
+		}
+
+		return name, len(g.Order), nil
+	}
+
+	return name, 0, nil
+}
+
+// GetInstanceGroups returns the members of each instance group in the batch, keyed by group name.
+// Canceled and rolled back queue entries remain members of their group, so that the group waits for them to be retried.
+func (b Batch) GetInstanceGroups(entries QueueEntries, instances Instances) (map[string]InstanceGroupMembers, error) {
+	instancesByUUID := make(map[uuid.UUID]Instance, len(instances))
+	for _, inst := range instances {
+		instancesByUUID[inst.UUID] = inst
+	}
+
+	groups := map[string]InstanceGroupMembers{}
+	for _, q := range entries {
+		inst, ok := instancesByUUID[q.InstanceUUID]
+		if !ok {
+			return nil, fmt.Errorf("Failed to find instance %q for queue entry in batch %q", q.InstanceUUID, b.Name)
+		}
+
+		name, position, err := b.GetInstanceGroup(inst)
+		if err != nil {
+			return nil, err
+		}
+
+		if name != "" {
+			groups[name] = append(groups[name], InstanceGroupMember{QueueEntry: q, Instance: inst, Position: position})
+		}
+	}
+
+	for _, members := range groups {
+		slices.SortStableFunc(members, func(a InstanceGroupMember, b InstanceGroupMember) int {
+			return a.Position - b.Position
+		})
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,235 @@
+package migration_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/ptr"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestBatch_GetInstanceGroups(t *testing.T) {
+	newInstance := func(location string, group string) migration.Instance {
+		return migration.Instance{
+			UUID:       uuid.New(),
+			Properties: api.InstanceProperties{Location: location},
+			Overrides:  api.InstanceOverride{Group: group},
+		}
+	}
+
+	db := newInstance("/vcenter/webshop/db", "")
+	web1 := newInstance("/vcenter/webshop/web1", "")
+	web2 := newInstance("/vcenter/webshop/web2", "")
+	cache := newInstance("/vcenter/other/cache", "webshop")
+	mail := newInstance("/vcenter/webshop/mail", "mail")
+	other := newInstance("/vcenter/other/app", "")
+	canceled := newInstance("/vcenter/webshop/old", "")
+
+	batch := migration.Batch{
+		Name: "one",
+		InstanceGroups: []api.BatchInstanceGroup{
+			{Name: "webshop", IncludeExpression: `location matches "^/vcenter/webshop/"`, Order: []string{`location matches "db"`, `location matches "cache"`}},
+		},
+	}
+
+	instances := migration.Instances{db, web1, web2, cache, mail, other, canceled}
+	entries := migration.QueueEntries{}
+	for _, inst := range instances {
+		status := api.MIGRATIONSTATUS_IDLE
+		if inst.UUID == canceled.UUID {
+			status = api.MIGRATIONSTATUS_CANCELED
+		}
+
+		entries = append(entries, migration.QueueEntry{InstanceUUID: inst.UUID, BatchName: batch.Name, MigrationStatus: status})
+	}
+
+	groups, err := batch.GetInstanceGroups(entries, instances)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	positions := func(members migration.InstanceGroupMembers) map[string]int {
+		result := map[string]int{}
+		for _, m := range members {
+			result[m.Instance.Properties.Location] = m.Position
+		}
+
+		return result
+	}
+
+	// Instances without a position come last, and the override takes precedence over the group expression.
+	require.Equal(t, map[string]int{db.Properties.Location: 0, cache.Properties.Location: 1, web1.Properties.Location: 2, web2.Properties.Location: 2, canceled.Properties.Location: 2}, positions(groups["webshop"]))
+	require.Equal(t, db.UUID, groups["webshop"][0].Instance.UUID)
+	require.Equal(t, cache.UUID, groups["webshop"][1].Instance.UUID)
+	require.Len(t, groups["webshop"].ByPosition(), 3)
+
+	// Canceled members remain in the group, and fail it until they are retried.
+	require.NotNil(t, groups["webshop"].Failed())
+	require.Equal(t, canceled.UUID, groups["webshop"].Failed().Instance.UUID)
+	require.Nil(t, groups["mail"].Failed())
+
+	// Groups can be defined only by instance overrides.
+	require.Equal(t, map[string]int{mail.Properties.Location: 0}, positions(groups["mail"]))
+
+	// Instances missing from the list are reported.
+	_, err = batch.GetInstanceGroups(entries, migration.Instances{db})
+	require.Error(t, err)
+}
+
+func TestInstanceGroupMembers_ReadyForWindow(t *testing.T) {
+	background := migration.Instance{Properties: api.InstanceProperties{BackgroundImport: true}}
+
+	cases := []struct {
+		name    string
+		members migration.InstanceGroupMembers
+
+		wantReady      bool
+		wantPoweredOff bool
+		wantFailed     bool
+	}{
+		{
+			name: "all members idle after background import",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_BACKGROUND}},
+			},
+
+			wantReady: true,
+		},
+		{
+			name: "member still running background import",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_BACKGROUND_IMPORT, ImportStage: migration.IMPORTSTAGE_BACKGROUND}, Instance: background},
+			},
+
+			wantReady: false,
+		},
+		{
+			name: "member waiting for its first background import",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_BACKGROUND}, Instance: background},
+			},
+
+			wantReady: false,
+		},
+		{
+			name: "members past final import",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_WORKER_DONE, ImportStage: migration.IMPORTSTAGE_COMPLETE}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_COMPLETE}, Instance: background},
+			},
+
+			wantReady:      true,
+			wantPoweredOff: true,
+		},
+		{
+			name: "failed member",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINAL_IMPORT, ImportStage: migration.IMPORTSTAGE_FINAL}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_ERROR, ImportStage: migration.IMPORTSTAGE_FINAL}, Instance: background},
+			},
+
+			wantReady:  false,
+			wantFailed: true,
+		},
+		{
+			name: "member failed its validation checks",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_WORKER_DONE, ImportStage: migration.IMPORTSTAGE_COMPLETE}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_VALIDATION_FAILED, ImportStage: migration.IMPORTSTAGE_COMPLETE}, Instance: background},
+			},
+
+			wantReady:  false,
+			wantFailed: true,
+		},
+		{
+			name: "member rolled back",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_WORKER_DONE, ImportStage: migration.IMPORTSTAGE_COMPLETE}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_ROLLED_BACK, ImportStage: migration.IMPORTSTAGE_BACKGROUND}, Instance: background},
+			},
+
+			wantReady:  false,
+			wantFailed: true,
+		},
+		{
+			name: "member canceled",
+			members: migration.InstanceGroupMembers{
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL}, Instance: background},
+				{QueueEntry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_CANCELED, ImportStage: migration.IMPORTSTAGE_BACKGROUND}, Instance: background},
+			},
+
+			wantReady:  false,
+			wantFailed: true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		require.Equal(t, tc.wantReady, tc.members.ReadyForWindow())
+		require.Equal(t, tc.wantFailed, tc.members.Failed() != nil)
+
+		poweredOff := true
+		for _, m := range tc.members {
+			poweredOff = poweredOff && m.PoweredOff()
+		}
+
+		require.Equal(t, tc.wantPoweredOff, poweredOff)
+	}
+}
+
+func TestBatch_GetBootTier(t *testing.T) {
+	batch := migration.Batch{
+		InstanceGroups: []api.BatchInstanceGroup{{Name: "webshop", IncludeExpression: `location matches "^/webshop/"`}},
+		Config: api.BatchConfig{
+			BootOrder: []api.BatchBootTier{
+				{IncludeExpression: `location matches "db"`, Tier: 0, WaitForAgent: true},
+				{IncludeExpression: `location matches "^/vcenter/"`, Tier: 2},
+				{IncludeExpression: `location matches "^/vcenter/db/"`, Tier: 1},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		location string
+
+		wantTier *int
+	}{
+		{
+			name:     "first matching entry is used",
+			location: "/vcenter/db/primary",
+			wantTier: ptr.To(0),
+		},
+		{
+			name:     "later entry",
+			location: "/vcenter/web/frontend",
+			wantTier: ptr.To(2),
+		},
+		{
+			name:     "no matching entry",
+			location: "/other/web/frontend",
+		},
+		{
+			name:     "instance group member",
+			location: "/webshop/db",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		tier, err := batch.GetBootTier(migration.Instance{Properties: api.InstanceProperties{Location: tc.location}})
+		require.NoError(t, err)
+		if tc.wantTier == nil {
+			require.Nil(t, tier)
+		} else {
+			require.NotNil(t, tier)
+			require.Equal(t, *tc.wantTier, tier.Tier)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package migration
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -56,10 +57,29 @@ func (i Instance) Validate() error {
 		}
 	}
 
+	if i.Overrides.Group != "" {
+		err := validate.IsAPIName(i.Overrides.Group, false)
+		if err != nil {
+			return NewValidationErrf("Invalid instance override, group %q is not a valid name: %v", i.Overrides.Group, err)
+		}
+	}
+
 	if i.Overrides.StartedAfterMigration && i.Overrides.StoppedAfterMigration {
 		return NewValidationErrf("Invalid instance override, ambiguous post-migration power state")
 	}
 
+	if i.Overrides.BitLocker != nil {
+		err := ValidateBitLockerKey(*i.Overrides.BitLocker)
+		if err != nil {
+			return NewValidationErrf("Invalid instance override, %v", err)
+		}
+	}
+
+	err := ValidatePostMigrationScripts(i.Overrides.PostMigrationScripts)
+	if err != nil {
+		return NewValidationErrf("Invalid instance override, %v", err)
+	}
+
 	for _, nic := range i.Properties.NICs {
 		if nic.UUID == uuid.Nil {
 			return NewValidationErrf("Instance NIC %q has empty UUID", nic.Location)
@@ -67,7 +87,7 @@ func (i Instance) Validate() error {
 	}
 
 	osType := i.GetOSType(true)
-	err := api.ValidateOSType(string(osType))
+	err = api.ValidateOSType(string(osType))
 	if err != nil {
 		return NewValidationErrf("Invalid instance OS type %q: %v", osType, err)
 	}
@@ -120,6 +140,32 @@ func (i Instance) Validate() error {
 	return nil
 }
 
+var bitLockerRecoveryPasswordRegex = regexp.MustCompile(`^[0-9]{6}(-[0-9]{6}){7}$`)
+
+// ValidateBitLockerKey validates the BitLocker key material. Redacted values are considered valid.
+func ValidateBitLockerKey(key api.BitLockerKey) error {
+	if key.RecoveryPassword == "" && key.KeyFile == "" {
+		return fmt.Errorf("BitLocker key must contain either a recovery password or a key file")
+	}
+
+	if key.RecoveryPassword != "" && key.KeyFile != "" {
+		return fmt.Errorf("BitLocker key cannot contain both a recovery password and a key file")
+	}
+
+	if key.RecoveryPassword != "" && key.RecoveryPassword != api.RedactedSecret && !bitLockerRecoveryPasswordRegex.MatchString(key.RecoveryPassword) {
+		return fmt.Errorf("BitLocker recovery password must be 8 groups of 6 digits separated by dashes")
+	}
+
+	if key.KeyFile != "" && key.KeyFile != api.RedactedSecret {
+		_, err := base64.StdEncoding.DecodeString(key.KeyFile)
+		if err != nil {
+			return fmt.Errorf("BitLocker key file must be base64 encoded: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // DisabledReason returns the underlying reason for why the instance is disabled.
 func (i Instance) DisabledReason(overrides api.InstanceRestrictionOverride) error {
 	if i.Overrides.DisableMigration {
@@ -262,7 +308,7 @@ func (i *Instance) GetDistribution(applyOverrides bool) (api.Distro, string) {
 
 	osType := i.GetOSType(applyOverrides)
 	switch i.SourceType {
-	case api.SOURCETYPE_VMWARE:
+	case api.SOURCETYPE_VMWARE, api.SOURCETYPE_OVF, api.SOURCETYPE_VMWARE_DUMP:
 		switch osType {
 		case api.OSTYPE_FORTIGATE:
 		case api.OSTYPE_WINDOWS:
@@ -654,3 +700,65 @@ func (i Instance) ToAPI() api.Instance {
 
 	return apiInst
 }
+
+// ValidationCheckCommand returns the command to run inside the migrated instance to perform the given validation check.
+func (i Instance) ValidationCheckCommand(check api.BatchValidationCheck) ([]string, error) {
+	if check.Type == api.VALIDATIONCHECK_COMMAND {
+		return check.Command, nil
+	}
+
+	osType := i.GetOSType(true)
+	switch osType {
+	case api.OSTYPE_LINUX:
+		var script string
+		switch check.Type {
+		case api.VALIDATIONCHECK_PING:
+			target := shellQuote(check.Target)
+			if check.Target == "" {
+				target = `"$(ip route show default | awk '{print $3; exit}')"`
+			}
+
+			script = "ping -c 3 -W 5 " + target
+		case api.VALIDATIONCHECK_SYSTEMD_UNIT:
+			script = "systemctl is-active " + shellQuote(check.Target)
+		case api.VALIDATIONCHECK_HTTP:
+			url := shellQuote(check.Target)
+			script = "if command -v curl >/dev/null; then curl -fsS -o /dev/null " + url + "; else wget -q -O /dev/null " + url + "; fi"
+		default:
+			return nil, fmt.Errorf("Validation check type %q is not supported for OS type %q", check.Type, osType)
+		}
+
+		return []string{"sh", "-c", script}, nil
+	case api.OSTYPE_WINDOWS:
+		var script string
+		switch check.Type {
+		case api.VALIDATIONCHECK_PING:
+			target := powershellQuote(check.Target)
+			if check.Target == "" {
+				target = "(Get-NetRoute -DestinationPrefix '0.0.0.0/0' | Sort-Object RouteMetric | Select-Object -First 1).NextHop"
+			}
+
+			script = "if (-not (Test-Connection -ComputerName " + target + " -Count 3 -Quiet)) { exit 1 }"
+		case api.VALIDATIONCHECK_HTTP:
+			script = "Invoke-WebRequest -UseBasicParsing -Uri " + powershellQuote(check.Target) + " | Out-Null"
+		case api.VALIDATIONCHECK_WINDOWS_SERVICE:
+			script = "$s = Get-Service -Name " + powershellQuote(check.Target) + "; $s.Status; if ($s.Status -ne 'Running') { exit 1 }"
+		default:
+			return nil, fmt.Errorf("Validation check type %q is not supported for OS type %q", check.Type, osType)
+		}
+
+		return []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "$ErrorActionPreference = 'Stop'; " + script}, nil
+	}
+
+	return nil, fmt.Errorf("Validation check type %q is not supported for OS type %q", check.Type, osType)
+}
+
+// shellQuote quotes a string for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote quotes a string as a PowerShell literal string.
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
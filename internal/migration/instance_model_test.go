@@ -42,3 +42,142 @@ func TestInstance_GetOSType(t *testing.T) {
 		})
 	}
 }
+
+func TestInstance_ValidationCheckCommand(t *testing.T) {
+	linux := migration.Instance{Properties: api.InstanceProperties{OS: "ubuntu64Guest"}}
+	windows := migration.Instance{Properties: api.InstanceProperties{OS: "windows2019srv_64Guest"}}
+
+	tests := []struct {
+		name     string
+		instance migration.Instance
+		check    api.BatchValidationCheck
+
+		assertErr require.ErrorAssertionFunc
+		want      []string
+	}{
+		{
+			name:     "success - command",
+			instance: windows,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_COMMAND, Command: []string{"test", "-f", "/etc/ready"}},
+
+			assertErr: require.NoError,
+			want:      []string{"test", "-f", "/etc/ready"},
+		},
+		{
+			name:     "success - linux ping",
+			instance: linux,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_PING, Target: "10.0.0.1"},
+
+			assertErr: require.NoError,
+			want:      []string{"sh", "-c", "ping -c 3 -W 5 '10.0.0.1'"},
+		},
+		{
+			name:     "success - linux ping default gateway",
+			instance: linux,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_PING},
+
+			assertErr: require.NoError,
+			want:      []string{"sh", "-c", `ping -c 3 -W 5 "$(ip route show default | awk '{print $3; exit}')"`},
+		},
+		{
+			name:     "success - linux systemd unit with quoting",
+			instance: linux,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_SYSTEMD_UNIT, Target: "it's.service"},
+
+			assertErr: require.NoError,
+			want:      []string{"sh", "-c", `systemctl is-active 'it'\''s.service'`},
+		},
+		{
+			name:     "success - windows service",
+			instance: windows,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_WINDOWS_SERVICE, Target: "W3SVC"},
+
+			assertErr: require.NoError,
+			want:      []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "$ErrorActionPreference = 'Stop'; $s = Get-Service -Name 'W3SVC'; $s.Status; if ($s.Status -ne 'Running') { exit 1 }"},
+		},
+		{
+			name:     "success - windows http with quoting",
+			instance: windows,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_HTTP, Target: "http://localhost/?a='b'"},
+
+			assertErr: require.NoError,
+			want:      []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "$ErrorActionPreference = 'Stop'; Invoke-WebRequest -UseBasicParsing -Uri 'http://localhost/?a=''b''' | Out-Null"},
+		},
+		{
+			name:     "error - systemd unit on windows",
+			instance: windows,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_SYSTEMD_UNIT, Target: "sshd.service"},
+
+			assertErr: require.Error,
+		},
+		{
+			name:     "error - windows service on linux",
+			instance: linux,
+			check:    api.BatchValidationCheck{Type: api.VALIDATIONCHECK_WINDOWS_SERVICE, Target: "W3SVC"},
+
+			assertErr: require.Error,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.instance.ValidationCheckCommand(tc.check)
+
+			tc.assertErr(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateBitLockerKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  api.BitLockerKey
+
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:      "success - recovery password",
+			key:       api.BitLockerKey{RecoveryPassword: "123456-123456-123456-123456-123456-123456-123456-123456"},
+			assertErr: require.NoError,
+		},
+		{
+			name:      "success - key file",
+			key:       api.BitLockerKey{KeyFile: "a2V5ZmlsZQ=="},
+			assertErr: require.NoError,
+		},
+		{
+			name:      "success - redacted recovery password",
+			key:       api.BitLockerKey{RecoveryPassword: api.RedactedSecret},
+			assertErr: require.NoError,
+		},
+		{
+			name:      "error - empty key",
+			key:       api.BitLockerKey{},
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - both recovery password and key file",
+			key:       api.BitLockerKey{RecoveryPassword: "123456-123456-123456-123456-123456-123456-123456-123456", KeyFile: "a2V5ZmlsZQ=="},
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - malformed recovery password",
+			key:       api.BitLockerKey{RecoveryPassword: "123456-123456"},
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - key file not base64",
+			key:       api.BitLockerKey{KeyFile: "not base64!"},
+			assertErr: require.Error,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := migration.ValidateBitLockerKey(tc.key)
+
+			tc.assertErr(t, err)
+		})
+	}
+}
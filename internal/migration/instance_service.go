@@ -184,8 +184,8 @@ func (s instanceService) UpdateOverride(ctx context.Context, id uuid.UUID, newOv
 			return fmt.Errorf("Migration has already started for instance %q", instance.UUID)
 		}
 
-		// If a queue entry exists, disallow modifying fields that we use to determine worker config, unless the queue entry has been canceled.
-		if entry.MigrationStatus != api.MIGRATIONSTATUS_CANCELED {
+		// If a queue entry exists, disallow modifying fields that we use to determine worker config, unless the queue entry has been canceled or rolled back.
+		if entry.MigrationStatus != api.MIGRATIONSTATUS_CANCELED && entry.MigrationStatus != api.MIGRATIONSTATUS_ROLLED_BACK {
 			if oldOverrides.Name != newOverrides.Name ||
 				oldOverrides.OSType != newOverrides.OSType ||
 				oldOverrides.Distribution != newOverrides.Distribution ||
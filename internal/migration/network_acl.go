@@ -0,0 +1,470 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	internalAPI "github.com/FuturFusion/migration-manager/internal/api"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// nsxAny is the NSX wildcard for groups, services, profiles and scopes.
+const nsxAny = "ANY"
+
+// nsxCategories is the order in which NSX evaluates distributed firewall policy categories.
+var nsxCategories = []string{"Ethernet", "Emergency", "Infrastructure", "Environment", "Application"}
+
+// NSXNetworkACLName returns the name of the network ACL generated for the given instance.
+func NSXNetworkACLName(instanceName string) string {
+	return "nsx-" + instanceName
+}
+
+// NetworkACLInstanceConfigKey is the config key recording the target instance that a generated network ACL was created for.
+const NetworkACLInstanceConfigKey = "user.migration-manager.instance"
+
+// NSXFirewallForVMs returns the subset of the distributed firewall whose policies and rules apply to any of the given VMs.
+// Returns nil if no rules apply.
+func NSXFirewallForVMs(fw internalAPI.NSXDistributedFirewall, vms []uuid.UUID) *internalAPI.NSXDistributedFirewall {
+	groupsByPath := make(map[string]internalAPI.NSXGroup, len(fw.Groups))
+	for _, g := range fw.Groups {
+		groupsByPath[g.Path] = g
+	}
+
+	applies := func(scope []string) bool {
+		if len(scope) == 0 || slices.Contains(scope, nsxAny) {
+			return true
+		}
+
+		for _, path := range scope {
+			for _, vm := range groupsByPath[path].VMs {
+				if slices.Contains(vms, vm) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	result := &internalAPI.NSXDistributedFirewall{
+		Policies: []internalAPI.NSXSecurityPolicy{},
+		Groups:   []internalAPI.NSXGroup{},
+		Services: []internalAPI.NSXService{},
+	}
+
+	usedGroups := map[string]bool{}
+	usedServices := map[string]bool{}
+	for _, policy := range fw.Policies {
+		if !applies(policy.Scope) {
+			continue
+		}
+
+		rules := []internalAPI.NSXRule{}
+		for _, rule := range policy.Rules {
+			if rule.Disabled || !applies(rule.Scope) {
+				continue
+			}
+
+			for _, path := range append(slices.Clone(rule.SourceGroups), rule.DestinationGroups...) {
+				usedGroups[path] = true
+			}
+
+			for _, path := range rule.Services {
+				usedServices[path] = true
+			}
+
+			rules = append(rules, rule)
+		}
+
+		if len(rules) == 0 {
+			continue
+		}
+
+		policy.Rules = rules
+		result.Policies = append(result.Policies, policy)
+	}
+
+	if len(result.Policies) == 0 {
+		return nil
+	}
+
+	for _, g := range fw.Groups {
+		if usedGroups[g.Path] {
+			result.Groups = append(result.Groups, g)
+		}
+	}
+
+	for _, s := range fw.Services {
+		if usedServices[s.Path] {
+			result.Services = append(result.Services, s)
+		}
+	}
+
+	return result
+}
+
+// NSXNetworkACLs translates the NSX distributed firewall rules that apply to the instance into a network ACL for its NSX-backed NICs.
+// Rules that can't be represented are skipped, and reported as warnings.
+func (i Instance) NSXNetworkACLs(networks Networks) (*api.InstanceNetworkACLs, error) {
+	result := &api.InstanceNetworkACLs{
+		ACLs:     []api.NetworkACL{},
+		NICs:     map[string][]string{},
+		Warnings: []string{},
+	}
+
+	networksByID := map[string]Network{}
+	for _, n := range networks {
+		if n.Source == i.Source {
+			networksByID[n.SourceSpecificID] = n
+		}
+	}
+
+	merged := internalAPI.NSXDistributedFirewall{}
+	seen := map[string]bool{}
+	nics := []string{}
+	for _, nic := range i.Properties.NICs {
+		n, ok := networksByID[nic.SourceSpecificID]
+		if !ok || (n.Type != api.NETWORKTYPE_VMWARE_NSX && n.Type != api.NETWORKTYPE_VMWARE_DISTRIBUTED_NSX) {
+			continue
+		}
+
+		var props internalAPI.NSXNetworkProperties
+		err := json.Unmarshal(n.Properties, &props)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse network properties for network %q: %w", n.Location, err)
+		}
+
+		if props.Firewall == nil {
+			continue
+		}
+
+		nics = append(nics, nic.HardwareAddress)
+		for _, p := range props.Firewall.Policies {
+			if !seen["policy:"+p.Path] {
+				seen["policy:"+p.Path] = true
+				merged.Policies = append(merged.Policies, p)
+			}
+		}
+
+		for _, g := range props.Firewall.Groups {
+			if !seen["group:"+g.Path] {
+				seen["group:"+g.Path] = true
+				merged.Groups = append(merged.Groups, g)
+			}
+		}
+
+		for _, s := range props.Firewall.Services {
+			if !seen["service:"+s.Path] {
+				seen["service:"+s.Path] = true
+				merged.Services = append(merged.Services, s)
+			}
+		}
+	}
+
+	fw := NSXFirewallForVMs(merged, []uuid.UUID{i.UUID})
+	if fw == nil {
+		return result, nil
+	}
+
+	acl := api.NetworkACL{
+		Name:        NSXNetworkACLName(i.GetName()),
+		Description: fmt.Sprintf("Generated from NSX distributed firewall rules for %q", i.Properties.Location),
+		Ingress:     []api.NetworkACLRule{},
+		Egress:      []api.NetworkACLRule{},
+	}
+
+	t := nsxTranslator{
+		groups:   map[string]internalAPI.NSXGroup{},
+		services: map[string]internalAPI.NSXService{},
+	}
+
+	for _, g := range fw.Groups {
+		t.groups[g.Path] = g
+	}
+
+	for _, s := range fw.Services {
+		t.services[s.Path] = s
+	}
+
+	sortNSXPolicies(fw.Policies)
+
+	var hasAllow bool
+	var warnedOrder bool
+	for _, policy := range fw.Policies {
+		for _, rule := range policy.Rules {
+			warn := func(format string, args ...any) {
+				msg := fmt.Sprintf("Skipping NSX rule %q in policy %q: ", rule.Name, policy.Name) + fmt.Sprintf(format, args...)
+				result.Warnings = append(result.Warnings, msg)
+			}
+
+			ingress, egress := rule.Direction != "OUT", rule.Direction != "IN"
+
+			// Rules following a catch-all rule are never reached.
+			if ingress && result.DefaultIngressAction != "" {
+				ingress = false
+			}
+
+			if egress && result.DefaultEgressAction != "" {
+				egress = false
+			}
+
+			if !ingress && !egress {
+				continue
+			}
+
+			var action string
+			switch rule.Action {
+			case "ALLOW":
+				action = "allow"
+			case "DROP":
+				action = "drop"
+			case "REJECT":
+				action = "reject"
+			default:
+				warn("Unsupported action %q", rule.Action)
+				continue
+			}
+
+			if len(rule.Profiles) > 0 && !slices.Equal(rule.Profiles, []string{nsxAny}) {
+				warn("Context profiles are not supported")
+				continue
+			}
+
+			sources, err := t.addresses(rule.SourceGroups)
+			if err != nil {
+				warn("Invalid sources: %v", err)
+				continue
+			}
+
+			destinations, err := t.addresses(rule.DestinationGroups)
+			if err != nil {
+				warn("Invalid destinations: %v", err)
+				continue
+			}
+
+			aclRules, err := t.rules(rule.Services)
+			if err != nil {
+				warn("Invalid services: %v", err)
+				continue
+			}
+
+			// A rule matching all traffic determines the default action for its direction.
+			if sources == "" && destinations == "" && len(aclRules) == 1 && aclRules[0] == (api.NetworkACLRule{}) {
+				if ingress {
+					result.DefaultIngressAction = action
+				}
+
+				if egress {
+					result.DefaultEgressAction = action
+				}
+
+				continue
+			}
+
+			if action == "allow" {
+				hasAllow = true
+			} else if hasAllow && !warnedOrder {
+				warnedOrder = true
+				result.Warnings = append(result.Warnings, fmt.Sprintf("NSX rule %q in policy %q follows an allow rule, but network ACLs always apply drop and reject rules before allow rules", rule.Name, policy.Name))
+			}
+
+			for _, aclRule := range aclRules {
+				aclRule.Action = action
+				aclRule.Source = sources
+				aclRule.Destination = destinations
+				aclRule.Description = fmt.Sprintf("NSX rule %q from policy %q", rule.Name, policy.Name)
+
+				if ingress {
+					acl.Ingress = append(acl.Ingress, aclRule)
+				}
+
+				if egress {
+					acl.Egress = append(acl.Egress, aclRule)
+				}
+			}
+		}
+	}
+
+	hasRules := len(acl.Ingress) > 0 || len(acl.Egress) > 0
+	if !hasRules && result.DefaultIngressAction == "" && result.DefaultEgressAction == "" {
+		return result, nil
+	}
+
+	if hasRules {
+		result.ACLs = append(result.ACLs, acl)
+	}
+
+	// Default actions apply to the NICs even if no other rules were translated.
+	for _, hwaddr := range nics {
+		result.NICs[hwaddr] = []string{}
+		if hasRules {
+			result.NICs[hwaddr] = append(result.NICs[hwaddr], acl.Name)
+		}
+	}
+
+	return result, nil
+}
+
+// sortNSXPolicies sorts the policies and their rules in the order they are evaluated by NSX.
+func sortNSXPolicies(policies []internalAPI.NSXSecurityPolicy) {
+	categoryIndex := func(category string) int {
+		idx := slices.Index(nsxCategories, category)
+		if idx < 0 {
+			return len(nsxCategories)
+		}
+
+		return idx
+	}
+
+	slices.SortStableFunc(policies, func(a internalAPI.NSXSecurityPolicy, b internalAPI.NSXSecurityPolicy) int {
+		if categoryIndex(a.Category) != categoryIndex(b.Category) {
+			return categoryIndex(a.Category) - categoryIndex(b.Category)
+		}
+
+		return a.SequenceNumber - b.SequenceNumber
+	})
+
+	for _, p := range policies {
+		slices.SortStableFunc(p.Rules, func(a internalAPI.NSXRule, b internalAPI.NSXRule) int {
+			return a.SequenceNumber - b.SequenceNumber
+		})
+	}
+}
+
+// nsxTranslator resolves NSX groups and services referenced by rules.
+type nsxTranslator struct {
+	groups   map[string]internalAPI.NSXGroup
+	services map[string]internalAPI.NSXService
+}
+
+// addresses returns the comma separated list of addresses for the given NSX rule sources or destinations.
+// Returns an empty string if any address matches.
+func (t nsxTranslator) addresses(entries []string) (string, error) {
+	if len(entries) == 0 || slices.Contains(entries, nsxAny) {
+		return "", nil
+	}
+
+	addresses := []string{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry, "/") {
+			err := validateNSXAddress(entry)
+			if err != nil {
+				return "", err
+			}
+
+			addresses = append(addresses, entry)
+			continue
+		}
+
+		group, ok := t.groups[entry]
+		if !ok {
+			return "", fmt.Errorf("Unknown group %q", entry)
+		}
+
+		if len(group.IPAddresses) == 0 {
+			return "", fmt.Errorf("Group %q has no IP address members", group.Name)
+		}
+
+		for _, addr := range group.IPAddresses {
+			err := validateNSXAddress(addr)
+			if err != nil {
+				return "", fmt.Errorf("Group %q: %w", group.Name, err)
+			}
+
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return strings.Join(addresses, ","), nil
+}
+
+// validateNSXAddress checks that the address is an IP address, CIDR subnet, or IP range.
+func validateNSXAddress(addr string) error {
+	start, end, isRange := strings.Cut(addr, "-")
+	if isRange {
+		if net.ParseIP(start) == nil || net.ParseIP(end) == nil {
+			return fmt.Errorf("Invalid IP range %q", addr)
+		}
+
+		return nil
+	}
+
+	if net.ParseIP(addr) != nil {
+		return nil
+	}
+
+	_, _, err := net.ParseCIDR(addr)
+	if err != nil {
+		return fmt.Errorf("Invalid IP address %q", addr)
+	}
+
+	return nil
+}
+
+// rules returns the protocol specific parts of the ACL rules matching the given NSX services.
+// A single empty rule is returned if any service matches.
+func (t nsxTranslator) rules(services []string) ([]api.NetworkACLRule, error) {
+	if len(services) == 0 || slices.Contains(services, nsxAny) {
+		return []api.NetworkACLRule{{}}, nil
+	}
+
+	rules := []api.NetworkACLRule{}
+	for _, path := range services {
+		service, ok := t.services[path]
+		if !ok {
+			return nil, fmt.Errorf("Unknown service %q", path)
+		}
+
+		for _, entry := range service.Entries {
+			var rule api.NetworkACLRule
+			switch entry.Type {
+			case "L4PortSetServiceEntry":
+				switch entry.L4Protocol {
+				case "TCP":
+					rule.Protocol = "tcp"
+				case "UDP":
+					rule.Protocol = "udp"
+				default:
+					return nil, fmt.Errorf("Service %q has unsupported protocol %q", service.Name, entry.L4Protocol)
+				}
+
+				rule.SourcePort = strings.Join(entry.SourcePorts, ",")
+				rule.DestinationPort = strings.Join(entry.DestinationPorts, ",")
+			case "ICMPTypeServiceEntry":
+				switch entry.Protocol {
+				case "ICMPv4":
+					rule.Protocol = "icmp4"
+				case "ICMPv6":
+					rule.Protocol = "icmp6"
+				default:
+					return nil, fmt.Errorf("Service %q has unsupported protocol %q", service.Name, entry.Protocol)
+				}
+
+				if entry.ICMPType != nil {
+					rule.ICMPType = strconv.Itoa(*entry.ICMPType)
+				}
+
+				if entry.ICMPCode != nil {
+					rule.ICMPCode = strconv.Itoa(*entry.ICMPCode)
+				}
+
+			default:
+				return nil, fmt.Errorf("Service %q has unsupported service entry type %q", service.Name, entry.Type)
+			}
+
+			rules = append(rules, rule)
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("No service entries found")
+	}
+
+	return rules, nil
+}
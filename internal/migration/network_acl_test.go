@@ -0,0 +1,148 @@
+package migration_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	internalAPI "github.com/FuturFusion/migration-manager/internal/api"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/ptr"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestInstance_NSXNetworkACLs(t *testing.T) {
+	vmUUID := uuid.MustParse("b4b4a2b5-8f3e-4c1f-9d6e-0a1b2c3d4e5f")
+	otherUUID := uuid.MustParse("c5c5a2b5-8f3e-4c1f-9d6e-0a1b2c3d4e5f")
+
+	groups := []internalAPI.NSXGroup{
+		{Path: "/infra/domains/default/groups/web", Name: "web", IPAddresses: []string{"10.0.0.10", "10.0.0.11"}, VMs: []uuid.UUID{vmUUID}},
+		{Path: "/infra/domains/default/groups/db", Name: "db", IPAddresses: []string{"10.0.1.0/24"}, VMs: []uuid.UUID{otherUUID}},
+		{Path: "/infra/domains/default/groups/empty", Name: "empty", IPAddresses: []string{}, VMs: []uuid.UUID{}},
+	}
+
+	services := []internalAPI.NSXService{
+		{Path: "/infra/services/HTTPS", Name: "HTTPS", Entries: []internalAPI.NSXServiceEntry{{Type: "L4PortSetServiceEntry", L4Protocol: "TCP", DestinationPorts: []string{"443"}}}},
+		{Path: "/infra/services/ICMP-ALL", Name: "ICMP ALL", Entries: []internalAPI.NSXServiceEntry{{Type: "ICMPTypeServiceEntry", Protocol: "ICMPv4", ICMPType: ptr.To(8)}}},
+		{Path: "/infra/services/FTP", Name: "FTP", Entries: []internalAPI.NSXServiceEntry{{Type: "ALGTypeServiceEntry"}}},
+	}
+
+	rule := func(name string, seq int, action string, direction string, src []string, dst []string, svc []string) internalAPI.NSXRule {
+		return internalAPI.NSXRule{Name: name, SequenceNumber: seq, Action: action, Direction: direction, SourceGroups: src, DestinationGroups: dst, Services: svc, Scope: []string{"ANY"}}
+	}
+
+	tests := []struct {
+		name     string
+		networks func(props []byte) migration.Networks
+		policies []internalAPI.NSXSecurityPolicy
+
+		want *api.InstanceNetworkACLs
+	}{
+		{
+			name: "success - no NSX networks",
+			networks: func(props []byte) migration.Networks {
+				return migration.Networks{{SourceSpecificID: "net-1", Source: "vcenter", Type: api.NETWORKTYPE_VMWARE_STANDARD}}
+			},
+			policies: []internalAPI.NSXSecurityPolicy{{Name: "app", Scope: []string{"ANY"}, Rules: []internalAPI.NSXRule{rule("allow-all", 1, "ALLOW", "IN_OUT", nil, nil, nil)}}},
+
+			want: &api.InstanceNetworkACLs{ACLs: []api.NetworkACL{}, NICs: map[string][]string{}, Warnings: []string{}},
+		},
+		{
+			name: "success - policy scoped to other VMs",
+			policies: []internalAPI.NSXSecurityPolicy{{Name: "db", Scope: []string{"/infra/domains/default/groups/db"}, Rules: []internalAPI.NSXRule{
+				rule("allow-all", 1, "ALLOW", "IN_OUT", nil, nil, nil),
+			}}},
+
+			want: &api.InstanceNetworkACLs{ACLs: []api.NetworkACL{}, NICs: map[string][]string{}, Warnings: []string{}},
+		},
+		{
+			name: "success - groups, services and default rule",
+			policies: []internalAPI.NSXSecurityPolicy{
+				{Name: "default", Path: "/infra/domains/default/security-policies/default", Category: "Application", SequenceNumber: 10, Scope: []string{"ANY"}, Rules: []internalAPI.NSXRule{
+					rule("default-drop", 100, "DROP", "IN_OUT", []string{"ANY"}, []string{"ANY"}, []string{"ANY"}),
+					rule("after-default", 101, "ALLOW", "IN", nil, nil, nil),
+				}},
+				{Name: "web", Path: "/infra/domains/default/security-policies/web", Category: "Application", SequenceNumber: 1, Scope: []string{"/infra/domains/default/groups/web"}, Rules: []internalAPI.NSXRule{
+					rule("https", 2, "ALLOW", "IN", []string{"ANY"}, []string{"/infra/domains/default/groups/web"}, []string{"/infra/services/HTTPS"}),
+					rule("ping", 3, "ALLOW", "IN_OUT", []string{"192.168.0.0/16"}, nil, []string{"/infra/services/ICMP-ALL"}),
+					rule("db", 4, "REJECT", "OUT", nil, []string{"/infra/domains/default/groups/db"}, nil),
+				}},
+			},
+
+			want: &api.InstanceNetworkACLs{
+				ACLs: []api.NetworkACL{{
+					Name:        "nsx-vm01",
+					Description: `Generated from NSX distributed firewall rules for "/vcenter/vm01"`,
+					Ingress: []api.NetworkACLRule{
+						{Action: "allow", Destination: "10.0.0.10,10.0.0.11", Protocol: "tcp", DestinationPort: "443", Description: `NSX rule "https" from policy "web"`},
+						{Action: "allow", Source: "192.168.0.0/16", Protocol: "icmp4", ICMPType: "8", Description: `NSX rule "ping" from policy "web"`},
+					},
+					Egress: []api.NetworkACLRule{
+						{Action: "allow", Source: "192.168.0.0/16", Protocol: "icmp4", ICMPType: "8", Description: `NSX rule "ping" from policy "web"`},
+						{Action: "reject", Destination: "10.0.1.0/24", Description: `NSX rule "db" from policy "web"`},
+					},
+				}},
+				NICs:                 map[string][]string{"00:00:00:00:00:01": {"nsx-vm01"}},
+				DefaultIngressAction: "drop",
+				DefaultEgressAction:  "drop",
+				Warnings:             []string{`NSX rule "db" in policy "web" follows an allow rule, but network ACLs always apply drop and reject rules before allow rules`},
+			},
+		},
+		{
+			name: "success - unrepresentable rules",
+			policies: []internalAPI.NSXSecurityPolicy{{Name: "app", Scope: []string{"ANY"}, Rules: []internalAPI.NSXRule{
+				rule("jump", 1, "JUMP_TO_APPLICATION", "IN_OUT", nil, nil, nil),
+				rule("ftp", 2, "ALLOW", "IN", nil, nil, []string{"/infra/services/FTP"}),
+				rule("empty-group", 3, "ALLOW", "IN", []string{"/infra/domains/default/groups/empty"}, nil, nil),
+				{Name: "profile", SequenceNumber: 4, Action: "ALLOW", Profiles: []string{"/infra/context-profiles/HTTP"}, Scope: []string{"ANY"}},
+				{Name: "disabled", SequenceNumber: 5, Action: "ALLOW", Disabled: true, Scope: []string{"ANY"}},
+				rule("allow-out", 6, "ALLOW", "OUT", nil, nil, nil),
+			}}},
+
+			want: &api.InstanceNetworkACLs{
+				ACLs:                []api.NetworkACL{},
+				NICs:                map[string][]string{"00:00:00:00:00:01": {}},
+				DefaultEgressAction: "allow",
+				Warnings: []string{
+					`Skipping NSX rule "jump" in policy "app": Unsupported action "JUMP_TO_APPLICATION"`,
+					`Skipping NSX rule "ftp" in policy "app": Invalid services: Service "FTP" has unsupported service entry type "ALGTypeServiceEntry"`,
+					`Skipping NSX rule "empty-group" in policy "app": Invalid sources: Group "empty" has no IP address members`,
+					`Skipping NSX rule "profile" in policy "app": Context profiles are not supported`,
+				},
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			props, err := json.Marshal(internalAPI.NSXNetworkProperties{
+				Source:   "nsx",
+				Firewall: &internalAPI.NSXDistributedFirewall{Policies: tc.policies, Groups: groups, Services: services},
+			})
+			require.NoError(t, err)
+
+			networks := migration.Networks{{SourceSpecificID: "net-1", Source: "vcenter", Type: api.NETWORKTYPE_VMWARE_NSX, Properties: props}}
+			if tc.networks != nil {
+				networks = tc.networks(props)
+			}
+
+			inst := migration.Instance{
+				UUID:   vmUUID,
+				Source: "vcenter",
+				Properties: api.InstanceProperties{
+					InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{Name: "vm01"},
+					Location:                       "/vcenter/vm01",
+					NICs:                           []api.InstancePropertiesNIC{{SourceSpecificID: "net-1", HardwareAddress: "00:00:00:00:00:01"}},
+				},
+			}
+
+			got, err := inst.NSXNetworkACLs(networks)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package migration
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/expr-lang/expr"
 	"github.com/expr-lang/expr/vm"
 	"github.com/google/uuid"
+	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/validate"
 
 	internalAPI "github.com/FuturFusion/migration-manager/internal/api"
@@ -43,7 +45,7 @@ func (n Network) Validate() error {
 		return NewValidationErrf("Invalid network, name can not be empty")
 	}
 
-	types := []api.NetworkType{api.NETWORKTYPE_VMWARE_DISTRIBUTED, api.NETWORKTYPE_VMWARE_DISTRIBUTED_NSX, api.NETWORKTYPE_VMWARE_STANDARD, api.NETWORKTYPE_VMWARE_NSX}
+	types := []api.NetworkType{api.NETWORKTYPE_VMWARE_DISTRIBUTED, api.NETWORKTYPE_VMWARE_DISTRIBUTED_NSX, api.NETWORKTYPE_VMWARE_STANDARD, api.NETWORKTYPE_VMWARE_NSX, api.NETWORKTYPE_OVF}
 	if !slices.Contains(types, n.Type) {
 		return NewValidationErrf("Invalid network, type %q is invalid", n.Type)
 	}
@@ -61,6 +63,9 @@ func (n Network) Validate() error {
 		if n.Type == api.NETWORKTYPE_VMWARE_DISTRIBUTED_NSX || n.Type == api.NETWORKTYPE_VMWARE_NSX {
 			var props internalAPI.NSXNetworkProperties
 			err = json.Unmarshal(n.Properties, &props)
+		} else if n.Type == api.NETWORKTYPE_OVF {
+			var props internalAPI.OVFNetworkProperties
+			err = json.Unmarshal(n.Properties, &props)
 		} else {
 			var props internalAPI.VCenterNetworkProperties
 			err = json.Unmarshal(n.Properties, &props)
@@ -196,3 +201,116 @@ func (n Network) ToAPI() (*api.Network, error) {
 		Overrides:        n.Overrides,
 	}, nil
 }
+
+// TargetNetworkBatchConfigKey is the config key recording the batch that a target network was created for.
+const TargetNetworkBatchConfigKey = "user.migration-manager.batch"
+
+// TargetNetwork returns the Incus network to create on a target in place of the network, for NICs with the given placement.
+// Managed NICs on NSX networks use an OVN network with the gateway of the NSX segment, using the uplink as its uplink network.
+// Physical NICs with at most one VLAN use a physical network on the uplink interface, tagged with the VLAN.
+// All other NICs use a bridge without addressing that includes the uplink interface, as VLANs are set on the NIC itself.
+func (n Network) TargetNetwork(placement api.NetworkPlacement, batchName string, uplink string) (*incusAPI.NetworksPost, error) {
+	description := fmt.Sprintf("Created by Migration Manager from %q", n.Location)
+	if placement.VlanID != "" {
+		description = fmt.Sprintf("%s (VLAN %s)", description, placement.VlanID)
+	}
+
+	isNSX := n.Type == api.NETWORKTYPE_VMWARE_NSX || n.Type == api.NETWORKTYPE_VMWARE_DISTRIBUTED_NSX
+	if !isNSX || placement.NICType != api.INCUSNICTYPE_MANAGED {
+		// Without an uplink, the network would not be connected to anything outside of the target.
+		if uplink == "" {
+			return nil, fmt.Errorf("Cannot create network %q for %q NICs on network %q without an uplink interface", placement.Network, placement.NICType, n.Location)
+		}
+
+		if placement.NICType == api.INCUSNICTYPE_PHYSICAL && !strings.Contains(placement.VlanID, ",") {
+			network := &incusAPI.NetworksPost{
+				Name: placement.Network,
+				Type: "physical",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: description,
+					Config: map[string]string{
+						"parent":                    uplink,
+						TargetNetworkBatchConfigKey: batchName,
+					},
+				},
+			}
+
+			if placement.VlanID != "" {
+				network.Config["vlan"] = placement.VlanID
+			}
+
+			return network, nil
+		}
+
+		return &incusAPI.NetworksPost{
+			Name: placement.Network,
+			Type: "bridge",
+			NetworkPut: incusAPI.NetworkPut{
+				Description: description,
+				Config: map[string]string{
+					"bridge.external_interfaces": uplink,
+					"ipv4.address":               "none",
+					"ipv6.address":               "none",
+					TargetNetworkBatchConfigKey:  batchName,
+				},
+			},
+		}, nil
+	}
+
+	network := &incusAPI.NetworksPost{
+		Name: placement.Network,
+		Type: "ovn",
+		NetworkPut: incusAPI.NetworkPut{
+			Description: description,
+			Config: map[string]string{
+				"ipv4.address":              "none",
+				"ipv6.address":              "none",
+				TargetNetworkBatchConfigKey: batchName,
+			},
+		},
+	}
+
+	if uplink != "" {
+		network.Config["network"] = uplink
+	}
+
+	// Networks that were not synced from an NSX source have no segment data.
+	var props internalAPI.NSXNetworkProperties
+	err := json.Unmarshal(n.Properties, &props)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse network properties for network %q: %w", n.Location, err)
+	}
+
+	for _, subnet := range props.Segment.Subnets {
+		gateway := subnet.GatewayAddress
+		if gateway == "" {
+			continue
+		}
+
+		// Gateways are usually given in CIDR notation, otherwise use the prefix of the subnet.
+		if !strings.Contains(gateway, "/") {
+			_, prefix, found := strings.Cut(subnet.Networks, "/")
+			if !found {
+				return nil, fmt.Errorf("Failed to parse subnet %q of network %q", subnet.Networks, n.Location)
+			}
+
+			gateway = gateway + "/" + prefix
+		}
+
+		ip, _, err := net.ParseCIDR(gateway)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse gateway %q of network %q: %w", gateway, n.Location, err)
+		}
+
+		key := "ipv6.address"
+		if ip.To4() != nil {
+			key = "ipv4.address"
+		}
+
+		if network.Config[key] == "none" {
+			network.Config[key] = gateway
+		}
+	}
+
+	return network, nil
+}
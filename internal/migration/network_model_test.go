@@ -0,0 +1,153 @@
+package migration_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	incusAPI "github.com/lxc/incus/v6/shared/api"
+	"github.com/stretchr/testify/require"
+
+	internalAPI "github.com/FuturFusion/migration-manager/internal/api"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestNetwork_TargetNetwork(t *testing.T) {
+	nsxProps, err := json.Marshal(internalAPI.NSXNetworkProperties{
+		Segment: internalAPI.NSXSegment{Subnets: []internalAPI.NSXSegmentSubnet{
+			{GatewayAddress: "10.0.0.1/24", Networks: "10.0.0.0/24"},
+			{GatewayAddress: "fd00::1", Networks: "fd00::/64"},
+		}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		network   migration.Network
+		placement api.NetworkPlacement
+		uplink    string
+
+		assertErr require.ErrorAssertionFunc
+		want      *incusAPI.NetworksPost
+	}{
+		{
+			name:      "success - bridge with VLAN",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_DISTRIBUTED, Location: "/dc/network/pg10", Properties: json.RawMessage(`{"vlan_id":10}`)},
+			placement: api.NetworkPlacement{Network: "pg10", NICType: api.INCUSNICTYPE_BRIDGED, VlanID: "10"},
+			uplink:    "eth1",
+
+			assertErr: require.NoError,
+			want: &incusAPI.NetworksPost{
+				Name: "pg10",
+				Type: "bridge",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: `Created by Migration Manager from "/dc/network/pg10" (VLAN 10)`,
+					Config:      map[string]string{"bridge.external_interfaces": "eth1", "ipv4.address": "none", "ipv6.address": "none", migration.TargetNetworkBatchConfigKey: "b1"},
+				},
+			},
+		},
+		{
+			name:      "success - physical with VLAN",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_DISTRIBUTED, Location: "/dc/network/pg10", Properties: json.RawMessage(`{"vlan_id":10}`)},
+			placement: api.NetworkPlacement{Network: "pg10", NICType: api.INCUSNICTYPE_PHYSICAL, VlanID: "10"},
+			uplink:    "eth1",
+
+			assertErr: require.NoError,
+			want: &incusAPI.NetworksPost{
+				Name: "pg10",
+				Type: "physical",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: `Created by Migration Manager from "/dc/network/pg10" (VLAN 10)`,
+					Config:      map[string]string{"parent": "eth1", "vlan": "10", migration.TargetNetworkBatchConfigKey: "b1"},
+				},
+			},
+		},
+		{
+			name:      "success - physical with VLAN trunk uses a bridge",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_DISTRIBUTED, Location: "/dc/network/trunk", Properties: json.RawMessage(`{"vlan_ranges":["10-20"]}`)},
+			placement: api.NetworkPlacement{Network: "trunk", NICType: api.INCUSNICTYPE_PHYSICAL, VlanID: "10-20,30"},
+			uplink:    "eth1",
+
+			assertErr: require.NoError,
+			want: &incusAPI.NetworksPost{
+				Name: "trunk",
+				Type: "bridge",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: `Created by Migration Manager from "/dc/network/trunk" (VLAN 10-20,30)`,
+					Config:      map[string]string{"bridge.external_interfaces": "eth1", "ipv4.address": "none", "ipv6.address": "none", migration.TargetNetworkBatchConfigKey: "b1"},
+				},
+			},
+		},
+		{
+			name:      "success - NSX segment with bridged NIC",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_NSX, Location: "/dc/network/seg1", Properties: nsxProps},
+			placement: api.NetworkPlacement{Network: "seg1", NICType: api.INCUSNICTYPE_BRIDGED},
+			uplink:    "eth1",
+
+			assertErr: require.NoError,
+			want: &incusAPI.NetworksPost{
+				Name: "seg1",
+				Type: "bridge",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: `Created by Migration Manager from "/dc/network/seg1"`,
+					Config:      map[string]string{"bridge.external_interfaces": "eth1", "ipv4.address": "none", "ipv6.address": "none", migration.TargetNetworkBatchConfigKey: "b1"},
+				},
+			},
+		},
+		{
+			name:      "error - bridge without uplink",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_DISTRIBUTED, Location: "/dc/network/pg10", Properties: json.RawMessage(`{"vlan_id":10}`)},
+			placement: api.NetworkPlacement{Network: "pg10", NICType: api.INCUSNICTYPE_BRIDGED, VlanID: "10"},
+
+			assertErr: require.Error,
+		},
+		{
+			name:      "success - NSX segment with subnets",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_NSX, Location: "/dc/network/seg1", Properties: nsxProps},
+			placement: api.NetworkPlacement{Network: "seg1", NICType: api.INCUSNICTYPE_MANAGED},
+			uplink:    "UPLINK",
+
+			assertErr: require.NoError,
+			want: &incusAPI.NetworksPost{
+				Name: "seg1",
+				Type: "ovn",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: `Created by Migration Manager from "/dc/network/seg1"`,
+					Config:      map[string]string{"ipv4.address": "10.0.0.1/24", "ipv6.address": "fd00::1/64", "network": "UPLINK", migration.TargetNetworkBatchConfigKey: "b1"},
+				},
+			},
+		},
+		{
+			name:      "success - NSX-backed port group without segment data",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_DISTRIBUTED_NSX, Location: "/dc/network/seg2", Properties: json.RawMessage(`{"segment_id":"/infra/segments/seg2"}`)},
+			placement: api.NetworkPlacement{Network: "seg2", NICType: api.INCUSNICTYPE_MANAGED},
+
+			assertErr: require.NoError,
+			want: &incusAPI.NetworksPost{
+				Name: "seg2",
+				Type: "ovn",
+				NetworkPut: incusAPI.NetworkPut{
+					Description: `Created by Migration Manager from "/dc/network/seg2"`,
+					Config:      map[string]string{"ipv4.address": "none", "ipv6.address": "none", migration.TargetNetworkBatchConfigKey: "b1"},
+				},
+			},
+		},
+		{
+			name:      "error - invalid gateway",
+			network:   migration.Network{Type: api.NETWORKTYPE_VMWARE_NSX, Location: "/dc/network/seg3", Properties: json.RawMessage(`{"segment":{"subnets":[{"gateway_address":"10.0.0.1","network":"10.0.0.0"}]}}`)},
+			placement: api.NetworkPlacement{Network: "seg3", NICType: api.INCUSNICTYPE_MANAGED},
+
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			got, err := tc.network.TargetNetwork(tc.placement, "b1", tc.uplink)
+			tc.assertErr(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package migration
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,16 @@ type QueueEntry struct {
 	MigrationWindowName sql.NullString `db:"leftjoin=migration_windows.name"`
 
 	Placement api.Placement `db:"marshal=json"`
+
+	TransferProgress *api.DiskTransferProgress `db:"marshal=json"`
+
+	ScriptResults []api.PostMigrationScriptResult `db:"marshal=json"`
+
+	ChangeIDs []api.DiskChangeID `db:"marshal=json"`
+
+	VerificationResults []api.DiskVerificationResult `db:"marshal=json"`
+
+	BootStatus *api.BootStatus `db:"marshal=json"`
 }
 
 type QueueEntries []QueueEntry
@@ -37,6 +48,12 @@ type WorkerCommand struct {
 	DistroVersion string
 	OSType        api.OSType
 	Architecture  string
+
+	PostMigrationScripts []api.PostMigrationScript
+
+	ChangeIDs []api.DiskChangeID
+
+	DiskVerification api.DiskVerificationMode
 }
 
 func (q QueueEntry) IsMigrating() bool {
@@ -47,7 +64,8 @@ func (q QueueEntry) IsMigrating() bool {
 		api.MIGRATIONSTATUS_IDLE,
 		api.MIGRATIONSTATUS_FINAL_IMPORT,
 		api.MIGRATIONSTATUS_POST_IMPORT,
-		api.MIGRATIONSTATUS_WORKER_DONE:
+		api.MIGRATIONSTATUS_WORKER_DONE,
+		api.MIGRATIONSTATUS_VALIDATING:
 		return true
 	default:
 		return false
@@ -80,6 +98,75 @@ func (q QueueEntry) StatusBeforePlacement() bool {
 	return false
 }
 
+// InWindow returns whether the queue entry is assigned the migration window with the given name, or is not assigned any migration window if the name is nil.
+func (q QueueEntry) InWindow(name *string) bool {
+	windowName := q.GetWindowName()
+	if windowName == nil || name == nil {
+		return windowName == nil && name == nil
+	}
+
+	return *windowName == *name
+}
+
+// ReadyForWindow returns whether the queue entry is ready to be assigned a migration window, meaning the background import of the instance has completed.
+func (q QueueEntry) ReadyForWindow(instance Instance) bool {
+	switch q.MigrationStatus {
+	case api.MIGRATIONSTATUS_IDLE:
+		return q.ImportStage != IMPORTSTAGE_BACKGROUND || !instance.Properties.SupportsBackgroundImport()
+	case api.MIGRATIONSTATUS_FINAL_IMPORT,
+		api.MIGRATIONSTATUS_POST_IMPORT,
+		api.MIGRATIONSTATUS_WORKER_DONE,
+		api.MIGRATIONSTATUS_VALIDATING,
+		api.MIGRATIONSTATUS_FINISHED:
+		return true
+	}
+
+	return false
+}
+
+// AwaitingBootAgent returns whether the instance of the queue entry was started in the given migration window, and the boot order tier of the instance requires its Incus agent to respond before later tiers are started, which it has not yet.
+func (q QueueEntry) AwaitingBootAgent(tier api.BatchBootTier, windowName *string) bool {
+	if q.MigrationStatus != api.MIGRATIONSTATUS_FINISHED || q.BootStatus == nil || windowName == nil || q.BootStatus.MigrationWindow != *windowName {
+		return false
+	}
+
+	return tier.WaitForAgent && q.Placement.Running && !q.BootStatus.AgentReady
+}
+
+// HoldsBackBootTier returns whether the queue entry, assigned to the given boot order tier, holds back the start of instances in later tiers that are migrated in the given migration window.
+// Instances that are still migrating or running validation checks hold back later tiers, and so do started instances until the delay of their tier has passed and their Incus agent has responded, if required.
+// Instances that failed do not hold back later tiers.
+func (q QueueEntry) HoldsBackBootTier(instance Instance, tier api.BatchBootTier, windowName *string, now time.Time) bool {
+	switch q.MigrationStatus {
+	case api.MIGRATIONSTATUS_ERROR, api.MIGRATIONSTATUS_CONFLICT:
+		return false
+	case api.MIGRATIONSTATUS_FINISHED:
+		// Finished instances no longer have a migration window, so use the one they were started in.
+		if q.BootStatus == nil || windowName == nil || q.BootStatus.MigrationWindow != *windowName {
+			return false
+		}
+
+		return now.Before(q.BootStatus.StartedAt.Add(tier.Delay.Duration)) || q.AwaitingBootAgent(tier, windowName)
+	}
+
+	return q.InWindow(windowName) && q.ReadyForWindow(instance)
+}
+
+// PoweredOff returns whether the queue entry has completed its final import, meaning the source VM has been powered off.
+func (q QueueEntry) PoweredOff() bool {
+	switch q.MigrationStatus {
+	case api.MIGRATIONSTATUS_POST_IMPORT,
+		api.MIGRATIONSTATUS_WORKER_DONE,
+		api.MIGRATIONSTATUS_VALIDATING,
+		api.MIGRATIONSTATUS_FINISHED:
+		return true
+	case api.MIGRATIONSTATUS_IDLE:
+		return q.ImportStage == IMPORTSTAGE_COMPLETE
+	}
+
+	return false
+}
+
 // IsCommitted returns whether the queue entry is past the point of no return (the source VM has been powered off, or is about to be by some concurrent task).
 func (q QueueEntry) IsCommitted() bool {
 	switch q.MigrationStatus {
@@ -88,12 +175,15 @@ func (q QueueEntry) IsCommitted() bool {
 		api.MIGRATIONSTATUS_CREATING,
 		api.MIGRATIONSTATUS_ERROR,
 		api.MIGRATIONSTATUS_FINISHED,
+		api.MIGRATIONSTATUS_ROLLED_BACK,
+		api.MIGRATIONSTATUS_VALIDATION_FAILED,
 		api.MIGRATIONSTATUS_WAITING,
 		api.MIGRATIONSTATUS_BACKGROUND_IMPORT:
 		return false
 	case api.MIGRATIONSTATUS_FINAL_IMPORT,
 		api.MIGRATIONSTATUS_POST_IMPORT,
-		api.MIGRATIONSTATUS_WORKER_DONE:
+		api.MIGRATIONSTATUS_WORKER_DONE,
+		api.MIGRATIONSTATUS_VALIDATING:
 		return true
 	case api.MIGRATIONSTATUS_IDLE, api.MIGRATIONSTATUS_CONFLICT:
 		// We can be idle for many reasons:
@@ -183,7 +273,110 @@ func (q QueueEntry) ToAPI(instanceName string, lastWorkerUpdate time.Time, migra
 		InstanceName:           instanceName,
 		LastWorkerResponse:     lastWorkerUpdate,
 		MigrationWindow:        migrationWindow.ToAPI(),
+		TransferProgress:       q.TransferProgress,
+		ScriptResults:          q.ScriptResults,
+		ChangeIDs:              q.ChangeIDs,
+		VerificationResults:    q.VerificationResults,
+		BootStatus:             q.BootStatus,
 
 		Placement: q.Placement,
 	}
 }
+
+// SetScriptResults replaces the post-migration script results of the given stage, so that results of a restarted stage are not recorded twice.
+func (q *QueueEntry) SetScriptResults(stage api.PostMigrationScriptStage, results []api.PostMigrationScriptResult) {
+	newResults := make([]api.PostMigrationScriptResult, 0, len(q.ScriptResults)+len(results))
+	for _, r := range q.ScriptResults {
+		if r.Stage != stage {
+			newResults = append(newResults, r)
+		}
+	}
+
+	q.ScriptResults = append(newResults, results...)
+}
+
+// SetChangeIDs records the given disk change IDs, replacing any previously recorded change ID of the same disk.
+func (q *QueueEntry) SetChangeIDs(changeIDs []api.DiskChangeID) {
+	for _, c := range changeIDs {
+		idx := slices.IndexFunc(q.ChangeIDs, func(existing api.DiskChangeID) bool { return existing.Disk == c.Disk })
+		if idx >= 0 {
+			q.ChangeIDs[idx] = c
+		} else {
+			q.ChangeIDs = append(q.ChangeIDs, c)
+		}
+	}
+}
+
+// RetainChangeIDs drops the change IDs of data copied to target volumes that no longer exist, given the current volume UUIDs by device name.
+// Change IDs without a recorded volume are dropped too, as the volume they belong to is unknown. Returns whether any change ID was dropped.
+func (q *QueueEntry) RetainChangeIDs(volumes map[string]string) bool {
+	var changeIDs []api.DiskChangeID
+	for _, c := range q.ChangeIDs {
+		if c.TargetVolume != "" && volumes[c.TargetDevice] == c.TargetVolume {
+			changeIDs = append(changeIDs, c)
+		}
+	}
+
+	if len(changeIDs) == len(q.ChangeIDs) {
+		return false
+	}
+
+	q.ChangeIDs = changeIDs
+
+	return true
+}
+
+// QueueHistoryEntry records a single migration state transition of a queue entry.
+// History entries are not tied to the lifetime of the queue entry, instance or batch, so they remain available after the batch finishes.
+type QueueHistoryEntry struct {
+	ID           int64
+	InstanceUUID uuid.UUID
+	BatchName    string
+
+	ImportStage            ImportStage
+	MigrationStatus        api.MigrationStatusType
+	MigrationStatusMessage string
+	LastWorkerStatus       api.WorkerResponseType
+	MigrationWindowName    string
+
+	Date time.Time
+}
+
+type QueueHistoryEntries []QueueHistoryEntry
+
+// StateChanged returns whether the migration state of the queue entry differs from the given entry.
+// Changes to the status message alone are not considered a state change, as the worker reports its progress through it.
+func (q QueueEntry) StateChanged(other QueueEntry) bool {
+	return q.MigrationStatus != other.MigrationStatus ||
+		q.ImportStage != other.ImportStage ||
+		q.LastWorkerStatus != other.LastWorkerStatus ||
+		q.MigrationWindowName != other.MigrationWindowName ||
+		q.BatchName != other.BatchName
+}
+
+// HistoryEntry returns a history entry recording the current migration state of the queue entry.
+func (q QueueEntry) HistoryEntry() QueueHistoryEntry {
+	return QueueHistoryEntry{
+		InstanceUUID:           q.InstanceUUID,
+		BatchName:              q.BatchName,
+		ImportStage:            q.ImportStage,
+		MigrationStatus:        q.MigrationStatus,
+		MigrationStatusMessage: q.MigrationStatusMessage,
+		LastWorkerStatus:       q.LastWorkerStatus,
+		MigrationWindowName:    q.MigrationWindowName.String,
+		Date:                   time.Now().UTC(),
+	}
+}
+
+func (q QueueHistoryEntry) ToAPI() api.QueueHistoryEntry {
+	return api.QueueHistoryEntry{
+		InstanceUUID:           q.InstanceUUID,
+		BatchName:              q.BatchName,
+		ImportStage:            string(q.ImportStage),
+		MigrationStatus:        q.MigrationStatus,
+		MigrationStatusMessage: q.MigrationStatusMessage,
+		LastWorkerStatus:       q.LastWorkerStatus,
+		MigrationWindowName:    q.MigrationWindowName,
+		Date:                   q.Date,
+	}
+}
@@ -0,0 +1,156 @@
+package migration_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestQueueEntry_RetainChangeIDs(t *testing.T) {
+	rootChangeID := api.DiskChangeID{Disk: "[datastore] vm/vm.vmdk", ChangeID: "52 d2/12", TargetDevice: "root", TargetVolume: "vol-root"}
+	dataChangeID := api.DiskChangeID{Disk: "[datastore] vm/vm_1.vmdk", ChangeID: "52 d3/7", TargetDevice: "disk1", TargetVolume: "vol-disk1"}
+	unknownChangeID := api.DiskChangeID{Disk: "[datastore] vm/vm_2.vmdk", ChangeID: "52 d4/3", TargetDevice: "disk2"}
+
+	cases := []struct {
+		name      string
+		changeIDs []api.DiskChangeID
+		volumes   map[string]string
+
+		wantChangeIDs []api.DiskChangeID
+		wantDropped   bool
+	}{
+		{
+			name:      "all volumes still exist",
+			changeIDs: []api.DiskChangeID{rootChangeID, dataChangeID},
+			volumes:   map[string]string{"root": "vol-root", "disk1": "vol-disk1"},
+
+			wantChangeIDs: []api.DiskChangeID{rootChangeID, dataChangeID},
+		},
+		{
+			name:      "one volume recreated",
+			changeIDs: []api.DiskChangeID{rootChangeID, dataChangeID},
+			volumes:   map[string]string{"root": "vol-root", "disk1": "vol-new"},
+
+			wantChangeIDs: []api.DiskChangeID{rootChangeID},
+			wantDropped:   true,
+		},
+		{
+			name:      "all volumes recreated",
+			changeIDs: []api.DiskChangeID{rootChangeID, dataChangeID},
+			volumes:   map[string]string{"root": "vol-new-root", "disk1": "vol-new-disk1"},
+
+			wantDropped: true,
+		},
+		{
+			name:      "unknown volume",
+			changeIDs: []api.DiskChangeID{rootChangeID, unknownChangeID},
+			volumes:   map[string]string{"root": "vol-root", "disk2": ""},
+
+			wantChangeIDs: []api.DiskChangeID{rootChangeID},
+			wantDropped:   true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			q := migration.QueueEntry{ChangeIDs: tc.changeIDs}
+			require.Equal(t, tc.wantDropped, q.RetainChangeIDs(tc.volumes))
+			require.Equal(t, tc.wantChangeIDs, q.ChangeIDs)
+		})
+	}
+}
+
+func TestQueueEntry_HoldsBackBootTier(t *testing.T) {
+	now := time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC)
+	window := "w1"
+	otherWindow := "w2"
+	tier := api.BatchBootTier{Tier: 0, Delay: api.AsDuration(time.Minute), WaitForAgent: true}
+	started := func(at time.Time, agentReady bool) *api.BootStatus {
+		return &api.BootStatus{StartedAt: at, MigrationWindow: window, AgentReady: agentReady}
+	}
+
+	cases := []struct {
+		name  string
+		entry migration.QueueEntry
+		tier  api.BatchBootTier
+
+		wantHoldsBack     bool
+		wantAwaitingAgent bool
+	}{
+		{
+			name:  "still migrating in the window",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_POST_IMPORT, MigrationWindowName: sql.NullString{String: window, Valid: true}},
+			tier:  tier,
+
+			wantHoldsBack: true,
+		},
+		{
+			name:  "migrating in another window",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_POST_IMPORT, MigrationWindowName: sql.NullString{String: otherWindow, Valid: true}},
+			tier:  tier,
+		},
+		{
+			name:  "running validation checks",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_VALIDATING, MigrationWindowName: sql.NullString{String: window, Valid: true}, BootStatus: started(now.Add(-time.Hour), true)},
+			tier:  tier,
+
+			wantHoldsBack: true,
+		},
+		{
+			name:  "failed",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_ERROR, MigrationWindowName: sql.NullString{String: window, Valid: true}},
+			tier:  tier,
+		},
+		{
+			name:  "started within the delay",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Placement: api.Placement{Running: true}, BootStatus: started(now.Add(-time.Second), true)},
+			tier:  tier,
+
+			wantHoldsBack: true,
+		},
+		{
+			name:  "started without agent response",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Placement: api.Placement{Running: true}, BootStatus: started(now.Add(-time.Hour), false)},
+			tier:  tier,
+
+			wantHoldsBack:     true,
+			wantAwaitingAgent: true,
+		},
+		{
+			name:  "started and left stopped",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINISHED, BootStatus: started(now.Add(-time.Hour), false)},
+			tier:  tier,
+		},
+		{
+			name:  "started after the delay with agent response",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Placement: api.Placement{Running: true}, BootStatus: started(now.Add(-time.Hour), true)},
+			tier:  tier,
+		},
+		{
+			name:  "started in another window",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Placement: api.Placement{Running: true}, BootStatus: &api.BootStatus{StartedAt: now, MigrationWindow: otherWindow}},
+			tier:  tier,
+		},
+		{
+			name:  "finished without boot status",
+			entry: migration.QueueEntry{MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Placement: api.Placement{Running: true}},
+			tier:  tier,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			require.Equal(t, tc.wantHoldsBack, tc.entry.HoldsBackBootTier(migration.Instance{}, tc.tier, &window, now))
+			require.Equal(t, tc.wantAwaitingAgent, tc.entry.AwaitingBootAgent(tc.tier, &window))
+		})
+	}
+}
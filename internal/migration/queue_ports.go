@@ -18,10 +18,13 @@ type QueueService interface {
 	GetAllByBatchAndState(ctx context.Context, batch string, status ...api.MigrationStatusType) (QueueEntries, error)
 	GetAllNeedingImport(ctx context.Context, batch string, importStage ImportStage) (QueueEntries, error)
 	GetByInstanceUUID(ctx context.Context, id uuid.UUID) (*QueueEntry, error)
+	GetHistoryByInstanceUUID(ctx context.Context, id uuid.UUID) (QueueHistoryEntries, error)
 	Update(ctx context.Context, entry *QueueEntry) error
 	DeleteByUUID(ctx context.Context, id uuid.UUID) error
 	CancelByUUID(ctx context.Context, id uuid.UUID) (*QueueEntry, bool, error)
 	RetryByUUID(ctx context.Context, id uuid.UUID, networkSvc NetworkService) (*QueueEntry, error)
+	CanRollbackByUUID(ctx context.Context, id uuid.UUID) (*QueueEntry, error)
+	RollbackByUUID(ctx context.Context, id uuid.UUID, statusMessage string) (*QueueEntry, error)
 	DeleteAllByBatch(ctx context.Context, batch string) error
 
 	UpdateStatusByUUID(ctx context.Context, id uuid.UUID, status api.MigrationStatusType, statusMessage string, importStage ImportStage, windowID *string) (*QueueEntry, error)
@@ -47,4 +50,7 @@ type QueueRepo interface {
 	Update(ctx context.Context, entry QueueEntry) error
 	DeleteByUUID(ctx context.Context, id uuid.UUID) error
 	DeleteAllByBatch(ctx context.Context, batch string) error
+
+	CreateHistory(ctx context.Context, entry QueueHistoryEntry) (int64, error)
+	GetHistoryByInstanceUUID(ctx context.Context, id uuid.UUID) (QueueHistoryEntries, error)
 }
@@ -54,7 +54,19 @@ func (s queueService) CreateEntry(ctx context.Context, queue QueueEntry) (QueueE
 		return QueueEntry{}, err
 	}
 
-	queue.ID, err = s.repo.Create(ctx, queue)
+	err = transaction.Do(ctx, func(ctx context.Context) error {
+		queue.ID, err = s.repo.Create(ctx, queue)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.repo.CreateHistory(ctx, queue.HistoryEntry())
+		if err != nil {
+			return fmt.Errorf("Failed to record queue history for instance %q: %w", queue.InstanceUUID, err)
+		}
+
+		return nil
+	})
 	if err != nil {
 		return QueueEntry{}, err
 	}
@@ -86,6 +98,29 @@ func (s queueService) GetByInstanceUUID(ctx context.Context, id uuid.UUID) (*Que
 	return s.repo.GetByInstanceUUID(ctx, id)
 }
 
+func (s queueService) GetHistoryByInstanceUUID(ctx context.Context, id uuid.UUID) (QueueHistoryEntries, error) {
+	return s.repo.GetHistoryByInstanceUUID(ctx, id)
+}
+
+// updateWithHistory updates the queue entry and records a history entry if its migration state differs from the previous entry.
+func (s queueService) updateWithHistory(ctx context.Context, oldEntry QueueEntry, newEntry QueueEntry) error {
+	err := s.repo.Update(ctx, newEntry)
+	if err != nil {
+		return err
+	}
+
+	if !newEntry.StateChanged(oldEntry) {
+		return nil
+	}
+
+	_, err = s.repo.CreateHistory(ctx, newEntry.HistoryEntry())
+	if err != nil {
+		return fmt.Errorf("Failed to record queue history for instance %q: %w", newEntry.InstanceUUID, err)
+	}
+
+	return nil
+}
+
 func (s queueService) UpdateStatusByUUID(ctx context.Context, id uuid.UUID, status api.MigrationStatusType, statusMessage string, importStage ImportStage, windowID *string) (*QueueEntry, error) {
 	err := status.Validate()
 	if err != nil {
@@ -101,6 +136,7 @@ func (s queueService) UpdateStatusByUUID(ctx context.Context, id uuid.UUID, stat
 			return fmt.Errorf("Failed to get instance '%s': %w", id, err)
 		}
 
+		oldEntry := *q
 		q.MigrationStatus = status
 		q.MigrationStatusMessage = statusMessage
 		q.ImportStage = importStage
@@ -111,7 +147,7 @@ func (s queueService) UpdateStatusByUUID(ctx context.Context, id uuid.UUID, stat
 			q.MigrationWindowName = sql.NullString{Valid: true, String: *windowID}
 		}
 
-		return s.repo.Update(ctx, *q)
+		return s.updateWithHistory(ctx, oldEntry, *q)
 	})
 	if err != nil {
 		return nil, err
@@ -146,7 +182,14 @@ func (s queueService) UpdatePlacementByUUID(ctx context.Context, id uuid.UUID, p
 }
 
 func (s queueService) Update(ctx context.Context, entry *QueueEntry) error {
-	return s.repo.Update(ctx, *entry)
+	return transaction.Do(ctx, func(ctx context.Context) error {
+		oldEntry, err := s.repo.GetByInstanceUUID(ctx, entry.InstanceUUID)
+		if err != nil {
+			return fmt.Errorf("Failed to get queue entry %q: %w", entry.InstanceUUID, err)
+		}
+
+		return s.updateWithHistory(ctx, *oldEntry, *entry)
+	})
 }
 
 func (s queueService) DeleteByUUID(ctx context.Context, id uuid.UUID) error {
@@ -174,11 +217,14 @@ func (s queueService) DeleteAllByBatch(ctx context.Context, batch string) error
 // - If the instance does not match any constraint, the earliest valid migration window is used.
 // - The earliest migration window valid for the the first matching constraint will be used otherwise.
 // - Returns a 404 if no migration window can be found, but the instance matched a constraint.
+// - Instances in an instance group share the window of the first member to be assigned one, and otherwise return a 404 until all members are ready.
 func (s queueService) GetNextWindow(ctx context.Context, q QueueEntry) (*Window, error) {
 	var entries QueueEntries
 	var instances Instances
 	var windows Windows
+	var batchWindows Windows
 	var batch *Batch
+	windowsInUse := map[string]int{}
 	err := transaction.Do(ctx, func(ctx context.Context) error {
 		var err error
 		entries, err = s.GetAllByBatchAndState(ctx, q.BatchName, api.MIGRATIONSTATUS_IDLE, api.MIGRATIONSTATUS_FINAL_IMPORT, api.MIGRATIONSTATUS_POST_IMPORT, api.MIGRATIONSTATUS_WORKER_DONE)
@@ -186,7 +232,7 @@ func (s queueService) GetNextWindow(ctx context.Context, q QueueEntry) (*Window,
 			return fmt.Errorf("Failed to get idle queue entries for batch %q: %w", q.BatchName, err)
 		}
 
-		batchWindows, err := s.window.GetAllByBatch(ctx, q.BatchName)
+		batchWindows, err = s.window.GetAllByBatch(ctx, q.BatchName)
 		if err != nil {
 			return fmt.Errorf("Failed to get migration windows for batch %q: %w", q.BatchName, err)
 		}
@@ -207,7 +253,6 @@ func (s queueService) GetNextWindow(ctx context.Context, q QueueEntry) (*Window,
 			return fmt.Errorf("Failed to get all queue entries: %w", err)
 		}
 
-		windowsInUse := map[string]int{}
 		for _, e := range allEntries {
 			window := e.GetWindowName()
 			if window != nil {
@@ -237,6 +282,24 @@ func (s queueService) GetNextWindow(ctx context.Context, q QueueEntry) (*Window,
 		}
 	}
 
+	for _, inst := range instances {
+		if inst.UUID != q.InstanceUUID {
+			continue
+		}
+
+		groupWindow, groupWindows, err := s.getInstanceGroupWindows(ctx, *batch, inst, windows, batchWindows, windowsInUse)
+		if err != nil {
+			return nil, err
+		}
+
+		if groupWindow != nil {
+			return groupWindow, nil
+		}
+
+		windows = groupWindows
+		break
+	}
+
 	// Use the most recently added constraint that matches this queue entry's instance.
 	var constraint *api.BatchConstraint
 	constraints := batch.Constraints
@@ -302,6 +365,181 @@ func (s queueService) GetNextWindow(ctx context.Context, q QueueEntry) (*Window,
 	return nil, incusAPI.StatusErrorf(http.StatusNotFound, "Not assigning migration window for instance %q, maximum limit %d reached", q.InstanceUUID, constraint.MaxConcurrentInstances)
 }
 
+// getBatchQueue returns all queue entries of the batch, and their instances.
+func (s queueService) getBatchQueue(ctx context.Context, batchName string) (QueueEntries, Instances, error) {
+	var entries QueueEntries
+	var instances Instances
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		var err error
+		entries, err = s.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return fmt.Errorf("Failed to get queue entries for batch %q: %w", batchName, err)
+		}
+
+		instances, err = s.instance.GetAllQueued(ctx, entries)
+		if err != nil {
+			return fmt.Errorf("Failed to get instances for batch %q: %w", batchName, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, instances, nil
+}
+
+// getInstanceGroupMembers returns the name of the instance group that the instance belongs to, and all members of that group in the batch.
+// Returns an empty name if the instance does not belong to any group.
+func (s queueService) getInstanceGroupMembers(ctx context.Context, batch Batch, instance Instance) (string, InstanceGroupMembers, error) {
+	group, _, err := batch.GetInstanceGroup(instance)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if group == "" {
+		return "", nil, nil
+	}
+
+	entries, instances, err := s.getBatchQueue(ctx, batch.Name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	groups, err := batch.GetInstanceGroups(entries, instances)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to get instance groups for batch %q: %w", batch.Name, err)
+	}
+
+	return group, groups[group], nil
+}
+
+// getInstanceGroupWindows returns the migration windows that are valid for the instance group of the given instance.
+// - If the instance does not belong to a group, the given windows are returned unchanged.
+// - Returns a 404 if any member of the group has failed, until it is retried.
+// - If another member of the group has been assigned a migration window that has not ended, that window is returned on its own.
+// - Returns a 404 if any member of the group is not yet ready for a migration window.
+// - Otherwise, only windows with enough capacity for all members of the group are returned.
+func (s queueService) getInstanceGroupWindows(ctx context.Context, batch Batch, instance Instance, windows Windows, batchWindows Windows, windowsInUse map[string]int) (*Window, Windows, error) {
+	group, members, err := s.getInstanceGroupMembers(ctx, batch, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if group == "" {
+		return nil, windows, nil
+	}
+
+	failed := members.Failed()
+	if failed != nil {
+		return nil, nil, incusAPI.StatusErrorf(http.StatusNotFound, "Not assigning migration window for instance %q, waiting for failed member %q of instance group %q to be retried", instance.UUID, failed.Instance.Properties.Location, group)
+	}
+
+	var unassigned int
+	for _, m := range members {
+		windowName := m.QueueEntry.GetWindowName()
+		if windowName == nil {
+			unassigned++
+			continue
+		}
+
+		for _, w := range batchWindows {
+			if w.Name == *windowName && !w.Ended() {
+				return &w, nil, nil
+			}
+		}
+	}
+
+	if !members.ReadyForWindow() {
+		return nil, nil, incusAPI.StatusErrorf(http.StatusNotFound, "Not assigning migration window for instance %q, waiting for all members of instance group %q to be ready", instance.UUID, group)
+	}
+
+	groupWindows := Windows{}
+	for _, w := range batchWindows {
+		if w.Config.Capacity == 0 || windowsInUse[w.Name]+unassigned <= w.Config.Capacity {
+			groupWindows = append(groupWindows, w)
+		}
+	}
+
+	if len(batchWindows) > 0 && len(groupWindows) == 0 {
+		return nil, nil, incusAPI.StatusErrorf(http.StatusNotFound, "Not assigning migration window for instance %q, no migration window has capacity for all %d members of instance group %q", instance.UUID, unassigned, group)
+	}
+
+	return nil, groupWindows, nil
+}
+
+// getPendingPowerOff returns a status message if other instances of the batch must be powered off before the final import of the instance can begin, or an empty string otherwise.
+// - Members of an instance group wait for the members later in the group order to complete their final import.
+// - Instances in a boot order tier wait for instances in higher tiers that are migrating in the same migration window to complete their final import.
+func (s queueService) getPendingPowerOff(ctx context.Context, batchName string, instance Instance, windowName *string) (string, error) {
+	batch, err := s.batch.GetByName(ctx, batchName)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get batch %q: %w", batchName, err)
+	}
+
+	group, members, err := s.getInstanceGroupMembers(ctx, *batch, instance)
+	if err != nil {
+		return "", err
+	}
+
+	if group != "" {
+		self := members.Get(instance.UUID)
+		if self == nil {
+			return "", nil
+		}
+
+		for _, m := range members {
+			if m.Position > self.Position && !m.PoweredOff() {
+				return fmt.Sprintf("Waiting for members of instance group %q to power off", group), nil
+			}
+		}
+
+		return "", nil
+	}
+
+	tier, err := batch.GetBootTier(instance)
+	if err != nil {
+		return "", err
+	}
+
+	if tier == nil {
+		return "", nil
+	}
+
+	entries, instances, err := s.getBatchQueue(ctx, batch.Name)
+	if err != nil {
+		return "", err
+	}
+
+	instancesByUUID := make(map[uuid.UUID]Instance, len(instances))
+	for _, inst := range instances {
+		instancesByUUID[inst.UUID] = inst
+	}
+
+	for _, q := range entries {
+		if q.InstanceUUID == instance.UUID || !q.InWindow(windowName) {
+			continue
+		}
+
+		inst := instancesByUUID[q.InstanceUUID]
+		if !q.ReadyForWindow(inst) || q.PoweredOff() {
+			continue
+		}
+
+		otherTier, err := batch.GetBootTier(inst)
+		if err != nil {
+			return "", err
+		}
+
+		if otherTier != nil && otherTier.Tier > tier.Tier {
+			return fmt.Sprintf("Waiting for instances in boot order tier %d to power off", otherTier.Tier), nil
+		}
+	}
+
+	return "", nil
+}
+
 // NewWorkerCommandByInstanceID gets the next worker command for the instance with the given UUID, and updates the instance state accordingly.
 // An instance must be IDLE to have a next worker command.
 func (s queueService) NewWorkerCommandByInstanceUUID(ctx context.Context, id uuid.UUID) (WorkerCommand, error) {
@@ -349,6 +587,7 @@ func (s queueService) NewWorkerCommandByInstanceUUID(ctx context.Context, id uui
 			OSType:        instance.GetOSType(true),
 			Distro:        distro,
 			DistroVersion: distroVersion,
+			ChangeIDs:     queueEntry.ChangeIDs,
 		}
 
 		// If the last worker response was RUNNING, then skip validation and just send the response it wants.
@@ -364,7 +603,7 @@ func (s queueService) NewWorkerCommandByInstanceUUID(ctx context.Context, id uui
 				return fmt.Errorf("Unable to restart worker for instance in state %q: %w", queueEntry.MigrationStatus, ErrOperationNotPermitted)
 			}
 
-			return nil
+			return s.setBatchCommandConfig(ctx, &workerCommand, *queueEntry, *instance)
 		}
 
 		var sourceProperties api.VMwareProperties
@@ -430,9 +669,19 @@ func (s queueService) NewWorkerCommandByInstanceUUID(ctx context.Context, id uui
 
 				// If a migration window has not been defined, or it has and we have passed the start time, begin the final migration.
 				if queueEntry.ImportStage != IMPORTSTAGE_COMPLETE {
-					workerCommand.Command = api.WORKERCOMMAND_FINALIZE_IMPORT
-					newStatus = api.MIGRATIONSTATUS_FINAL_IMPORT
-					newStatusMessage = string(api.MIGRATIONSTATUS_FINAL_IMPORT)
+					// Instance groups and boot order tiers are powered off in reverse order, so hold off until the later instances have completed their final import.
+					pending, err := s.getPendingPowerOff(ctx, queueEntry.BatchName, *instance, windowName)
+					if err != nil {
+						return err
+					}
+
+					if pending != "" {
+						newStatusMessage = pending
+					} else {
+						workerCommand.Command = api.WORKERCOMMAND_FINALIZE_IMPORT
+						newStatus = api.MIGRATIONSTATUS_FINAL_IMPORT
+						newStatusMessage = string(api.MIGRATIONSTATUS_FINAL_IMPORT)
+					}
 				} else {
 					workerCommand.Command = api.WORKERCOMMAND_POST_IMPORT
 					newStatus = api.MIGRATIONSTATUS_POST_IMPORT
@@ -487,6 +736,11 @@ func (s queueService) NewWorkerCommandByInstanceUUID(ctx context.Context, id uui
 			}
 		}
 
+		err = s.setBatchCommandConfig(ctx, &workerCommand, *queueEntry, *instance)
+		if err != nil {
+			return err
+		}
+
 		if newStatus != api.MIGRATIONSTATUS_IDLE && newStatus != api.MIGRATIONSTATUS_POST_IMPORT {
 			s.source.RecordActiveImport(instance.Source)
 			s.target.RecordActiveImport(queueEntry.Placement.TargetName)
@@ -509,6 +763,32 @@ func (s queueService) NewWorkerCommandByInstanceUUID(ctx context.Context, id uui
 	return workerCommand, nil
 }
 
+// setBatchCommandConfig adds the worker stage post-migration scripts to commands that run post-import tasks, and the disk verification mode to final import commands.
+func (s queueService) setBatchCommandConfig(ctx context.Context, workerCommand *WorkerCommand, queueEntry QueueEntry, instance Instance) error {
+	if workerCommand.Command != api.WORKERCOMMAND_FINALIZE_IMPORT && workerCommand.Command != api.WORKERCOMMAND_POST_IMPORT {
+		return nil
+	}
+
+	batch, err := s.batch.GetByName(ctx, queueEntry.BatchName)
+	if err != nil {
+		return fmt.Errorf("Failed to get queue entry batch %q: %w", queueEntry.BatchName, err)
+	}
+
+	workerCommand.PostMigrationScripts = batch.PostMigrationScripts(instance, api.POSTMIGRATIONSCRIPTSTAGE_WORKER)
+
+	if workerCommand.Command == api.WORKERCOMMAND_FINALIZE_IMPORT {
+		workerCommand.DiskVerification = batch.Config.DiskVerification
+
+		// Only VMware sources can read the source disks again for verification, so it is skipped for other instances of the batch.
+		if workerCommand.DiskVerification != api.DISKVERIFICATION_NONE && instance.SourceType != api.SOURCETYPE_VMWARE {
+			slog.Warn("Skipping disk verification, as it is not supported by the instance's source", slog.String("instance", instance.Properties.Location), slog.String("source_type", string(instance.SourceType)), slog.String("batch", batch.Name))
+			workerCommand.DiskVerification = api.DISKVERIFICATION_NONE
+		}
+	}
+
+	return nil
+}
+
 func (s queueService) ProcessWorkerUpdate(ctx context.Context, id uuid.UUID, workerResp api.WorkerResponse) (QueueEntry, error) {
 	var entry *QueueEntry
 
@@ -529,6 +809,9 @@ func (s queueService) ProcessWorkerUpdate(ctx context.Context, id uuid.UUID, wor
 		switch workerResp.Status {
 		case api.WORKERRESPONSE_RUNNING:
 			entry.MigrationStatusMessage = workerResp.StatusMessage
+			if workerResp.Progress != nil {
+				entry.TransferProgress = workerResp.Progress
+			}
 
 		case api.WORKERRESPONSE_SUCCESS:
 			switch entry.MigrationStatus {
@@ -564,6 +847,20 @@ func (s queueService) ProcessWorkerUpdate(ctx context.Context, id uuid.UUID, wor
 			entry.MigrationStatusMessage = workerResp.StatusMessage
 		}
 
+		// Change IDs are reported as each disk finishes copying, so that an interrupted import can resume incrementally.
+		if workerResp.ChangeIDs != nil {
+			entry.SetChangeIDs(workerResp.ChangeIDs)
+		}
+
+		if workerResp.VerificationResults != nil {
+			entry.VerificationResults = workerResp.VerificationResults
+		}
+
+		// Worker stage post-migration scripts are reported once they have run, whether or not they succeeded.
+		if workerResp.ScriptResults != nil {
+			entry.SetScriptResults(api.POSTMIGRATIONSCRIPTSTAGE_WORKER, workerResp.ScriptResults)
+		}
+
 		if workerResp.Status != api.WORKERRESPONSE_RUNNING {
 			instance, err := s.instance.GetByUUID(ctx, id)
 			if err != nil {
@@ -609,6 +906,10 @@ func (s queueService) CancelByUUID(ctx context.Context, id uuid.UUID) (*QueueEnt
 			return fmt.Errorf("Queue entry %q is already finished", q.InstanceUUID)
 		}
 
+		if q.MigrationStatus == api.MIGRATIONSTATUS_VALIDATING || q.MigrationStatus == api.MIGRATIONSTATUS_VALIDATION_FAILED {
+			return fmt.Errorf("Queue entry %q has already finished migrating, roll it back instead", q.InstanceUUID)
+		}
+
 		newQueue, err = s.UpdateStatusByUUID(ctx, q.InstanceUUID, api.MIGRATIONSTATUS_CANCELED, q.MigrationStatusMessage, IMPORTSTAGE_BACKGROUND, nil)
 		if err != nil {
 			return err
@@ -623,7 +924,88 @@ func (s queueService) CancelByUUID(ctx context.Context, id uuid.UUID) (*QueueEnt
 	return newQueue, isCommitted, nil
 }
 
-// RetryByUUID restarts the queue entry if it has been cancelled.
+// CanRollbackByUUID returns the queue entry if it can be rolled back, without changing its state.
+// The rollback must happen within the batch's rollback grace period after the queue entry finished.
+// Queue entries that failed their validation checks can be rolled back at any time.
+// A queue entry that is already rolled back can be rolled back again, in case the previous attempt did not complete.
+func (s queueService) CanRollbackByUUID(ctx context.Context, id uuid.UUID) (*QueueEntry, error) {
+	var q *QueueEntry
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		var err error
+		q, err = s.repo.GetByInstanceUUID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		switch q.MigrationStatus {
+		case api.MIGRATIONSTATUS_ROLLED_BACK:
+		case api.MIGRATIONSTATUS_VALIDATION_FAILED:
+		case api.MIGRATIONSTATUS_FINISHED:
+			batch, err := s.batch.GetByName(ctx, q.BatchName)
+			if err != nil {
+				return err
+			}
+
+			gracePeriod := batch.Config.RollbackGracePeriod.Duration
+			if gracePeriod > 0 {
+				history, err := s.repo.GetHistoryByInstanceUUID(ctx, id)
+				if err != nil {
+					return err
+				}
+
+				var finishedDate time.Time
+				for _, h := range history {
+					if h.MigrationStatus == api.MIGRATIONSTATUS_FINISHED {
+						finishedDate = h.Date
+					}
+				}
+
+				if finishedDate.IsZero() {
+					return fmt.Errorf("Cannot roll back queue entry %q: Unable to determine when the migration finished: %w", id, ErrOperationNotPermitted)
+				}
+
+				if time.Since(finishedDate) > gracePeriod {
+					return fmt.Errorf("Cannot roll back queue entry %q: Rollback grace period of %s has passed: %w", id, gracePeriod, ErrOperationNotPermitted)
+				}
+			}
+
+		default:
+			return fmt.Errorf("Cannot roll back queue entry %q: Migration has not finished: %w", id, ErrOperationNotPermitted)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// RollbackByUUID marks a finished queue entry as rolled back, so that the source VM is considered authoritative again.
+// The queue entry must be eligible for a rollback according to CanRollbackByUUID.
+func (s queueService) RollbackByUUID(ctx context.Context, id uuid.UUID, statusMessage string) (*QueueEntry, error) {
+	s.workerLock.Lock()
+	defer s.workerLock.Unlock()
+
+	var newQueue *QueueEntry
+	err := transaction.Do(ctx, func(ctx context.Context) error {
+		_, err := s.CanRollbackByUUID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		newQueue, err = s.UpdateStatusByUUID(ctx, id, api.MIGRATIONSTATUS_ROLLED_BACK, statusMessage, IMPORTSTAGE_BACKGROUND, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newQueue, nil
+}
+
+// RetryByUUID restarts the queue entry if it has been cancelled or rolled back.
 func (s queueService) RetryByUUID(ctx context.Context, id uuid.UUID, networkSvc NetworkService) (*QueueEntry, error) {
 	s.workerLock.Lock()
 	defer s.workerLock.Unlock()
@@ -636,8 +1018,8 @@ func (s queueService) RetryByUUID(ctx context.Context, id uuid.UUID, networkSvc
 			return err
 		}
 
-		if q.MigrationStatus != api.MIGRATIONSTATUS_CANCELED {
-			return fmt.Errorf("Queue entry %q has not been cancelled and cleaned up", q.InstanceUUID)
+		if q.MigrationStatus != api.MIGRATIONSTATUS_CANCELED && q.MigrationStatus != api.MIGRATIONSTATUS_ROLLED_BACK {
+			return fmt.Errorf("Queue entry %q has not been cancelled or rolled back", q.InstanceUUID)
 		}
 
 		batch, err := s.batch.GetByName(ctx, q.BatchName)
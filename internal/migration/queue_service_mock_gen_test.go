@@ -22,6 +22,9 @@ var _ migration.QueueService = &QueueServiceMock{}
 //
 //		// make and configure a mocked migration.QueueService
 //		mockedQueueService := &QueueServiceMock{
+//			CanRollbackByUUIDFunc: func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, error) {
+//				panic("mock out the CanRollbackByUUID method")
+//			},
 //			CancelByUUIDFunc: func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, bool, error) {
 //				panic("mock out the CancelByUUID method")
 //			},
@@ -52,6 +55,9 @@ var _ migration.QueueService = &QueueServiceMock{}
 //			GetByInstanceUUIDFunc: func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, error) {
 //				panic("mock out the GetByInstanceUUID method")
 //			},
+//			GetHistoryByInstanceUUIDFunc: func(ctx context.Context, id uuid.UUID) (migration.QueueHistoryEntries, error) {
+//				panic("mock out the GetHistoryByInstanceUUID method")
+//			},
 //			GetNextWindowFunc: func(ctx context.Context, q migration.QueueEntry) (*migration.Window, error) {
 //				panic("mock out the GetNextWindow method")
 //			},
@@ -64,6 +70,9 @@ var _ migration.QueueService = &QueueServiceMock{}
 //			RetryByUUIDFunc: func(ctx context.Context, id uuid.UUID, networkSvc migration.NetworkService) (*migration.QueueEntry, error) {
 //				panic("mock out the RetryByUUID method")
 //			},
+//			RollbackByUUIDFunc: func(ctx context.Context, id uuid.UUID, statusMessage string) (*migration.QueueEntry, error) {
+//				panic("mock out the RollbackByUUID method")
+//			},
 //			UpdateFunc: func(ctx context.Context, entry *migration.QueueEntry) error {
 //				panic("mock out the Update method")
 //			},
@@ -80,6 +89,9 @@ var _ migration.QueueService = &QueueServiceMock{}
 //
 //	}
 type QueueServiceMock struct {
+	// CanRollbackByUUIDFunc mocks the CanRollbackByUUID method.
+	CanRollbackByUUIDFunc func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, error)
+
 	// CancelByUUIDFunc mocks the CancelByUUID method.
 	CancelByUUIDFunc func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, bool, error)
 
@@ -110,6 +122,9 @@ type QueueServiceMock struct {
 	// GetByInstanceUUIDFunc mocks the GetByInstanceUUID method.
 	GetByInstanceUUIDFunc func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, error)
 
+	// GetHistoryByInstanceUUIDFunc mocks the GetHistoryByInstanceUUID method.
+	GetHistoryByInstanceUUIDFunc func(ctx context.Context, id uuid.UUID) (migration.QueueHistoryEntries, error)
+
 	// GetNextWindowFunc mocks the GetNextWindow method.
 	GetNextWindowFunc func(ctx context.Context, q migration.QueueEntry) (*migration.Window, error)
 
@@ -122,6 +137,9 @@ type QueueServiceMock struct {
 	// RetryByUUIDFunc mocks the RetryByUUID method.
 	RetryByUUIDFunc func(ctx context.Context, id uuid.UUID, networkSvc migration.NetworkService) (*migration.QueueEntry, error)
 
+	// RollbackByUUIDFunc mocks the RollbackByUUID method.
+	RollbackByUUIDFunc func(ctx context.Context, id uuid.UUID, statusMessage string) (*migration.QueueEntry, error)
+
 	// UpdateFunc mocks the Update method.
 	UpdateFunc func(ctx context.Context, entry *migration.QueueEntry) error
 
@@ -133,6 +151,13 @@ type QueueServiceMock struct {
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// CanRollbackByUUID holds details about calls to the CanRollbackByUUID method.
+		CanRollbackByUUID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uuid.UUID
+		}
 		// CancelByUUID holds details about calls to the CancelByUUID method.
 		CancelByUUID []struct {
 			// Ctx is the ctx argument value.
@@ -205,6 +230,13 @@ type QueueServiceMock struct {
 			// ID is the id argument value.
 			ID uuid.UUID
 		}
+		// GetHistoryByInstanceUUID holds details about calls to the GetHistoryByInstanceUUID method.
+		GetHistoryByInstanceUUID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uuid.UUID
+		}
 		// GetNextWindow holds details about calls to the GetNextWindow method.
 		GetNextWindow []struct {
 			// Ctx is the ctx argument value.
@@ -237,6 +269,15 @@ type QueueServiceMock struct {
 			// NetworkSvc is the networkSvc argument value.
 			NetworkSvc migration.NetworkService
 		}
+		// RollbackByUUID holds details about calls to the RollbackByUUID method.
+		RollbackByUUID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID uuid.UUID
+			// StatusMessage is the statusMessage argument value.
+			StatusMessage string
+		}
 		// Update holds details about calls to the Update method.
 		Update []struct {
 			// Ctx is the ctx argument value.
@@ -269,6 +310,7 @@ type QueueServiceMock struct {
 			WindowID *string
 		}
 	}
+	lockCanRollbackByUUID              sync.RWMutex
 	lockCancelByUUID                   sync.RWMutex
 	lockCreateEntry                    sync.RWMutex
 	lockDeleteAllByBatch               sync.RWMutex
@@ -279,15 +321,53 @@ type QueueServiceMock struct {
 	lockGetAllByState                  sync.RWMutex
 	lockGetAllNeedingImport            sync.RWMutex
 	lockGetByInstanceUUID              sync.RWMutex
+	lockGetHistoryByInstanceUUID       sync.RWMutex
 	lockGetNextWindow                  sync.RWMutex
 	lockNewWorkerCommandByInstanceUUID sync.RWMutex
 	lockProcessWorkerUpdate            sync.RWMutex
 	lockRetryByUUID                    sync.RWMutex
+	lockRollbackByUUID                 sync.RWMutex
 	lockUpdate                         sync.RWMutex
 	lockUpdatePlacementByUUID          sync.RWMutex
 	lockUpdateStatusByUUID             sync.RWMutex
 }
 
+// CanRollbackByUUID calls CanRollbackByUUIDFunc.
+func (mock *QueueServiceMock) CanRollbackByUUID(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, error) {
+	if mock.CanRollbackByUUIDFunc == nil {
+		panic("QueueServiceMock.CanRollbackByUUIDFunc: method is nil but QueueService.CanRollbackByUUID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  uuid.UUID
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockCanRollbackByUUID.Lock()
+	mock.calls.CanRollbackByUUID = append(mock.calls.CanRollbackByUUID, callInfo)
+	mock.lockCanRollbackByUUID.Unlock()
+	return mock.CanRollbackByUUIDFunc(ctx, id)
+}
+
+// CanRollbackByUUIDCalls gets all the calls that were made to CanRollbackByUUID.
+// Check the length with:
+//
+//	len(mockedQueueService.CanRollbackByUUIDCalls())
+func (mock *QueueServiceMock) CanRollbackByUUIDCalls() []struct {
+	Ctx context.Context
+	ID  uuid.UUID
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  uuid.UUID
+	}
+	mock.lockCanRollbackByUUID.RLock()
+	calls = mock.calls.CanRollbackByUUID
+	mock.lockCanRollbackByUUID.RUnlock()
+	return calls
+}
+
 // CancelByUUID calls CancelByUUIDFunc.
 func (mock *QueueServiceMock) CancelByUUID(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, bool, error) {
 	if mock.CancelByUUIDFunc == nil {
@@ -652,6 +732,42 @@ func (mock *QueueServiceMock) GetByInstanceUUIDCalls() []struct {
 	return calls
 }
 
+// GetHistoryByInstanceUUID calls GetHistoryByInstanceUUIDFunc.
+func (mock *QueueServiceMock) GetHistoryByInstanceUUID(ctx context.Context, id uuid.UUID) (migration.QueueHistoryEntries, error) {
+	if mock.GetHistoryByInstanceUUIDFunc == nil {
+		panic("QueueServiceMock.GetHistoryByInstanceUUIDFunc: method is nil but QueueService.GetHistoryByInstanceUUID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  uuid.UUID
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetHistoryByInstanceUUID.Lock()
+	mock.calls.GetHistoryByInstanceUUID = append(mock.calls.GetHistoryByInstanceUUID, callInfo)
+	mock.lockGetHistoryByInstanceUUID.Unlock()
+	return mock.GetHistoryByInstanceUUIDFunc(ctx, id)
+}
+
+// GetHistoryByInstanceUUIDCalls gets all the calls that were made to GetHistoryByInstanceUUID.
+// Check the length with:
+//
+//	len(mockedQueueService.GetHistoryByInstanceUUIDCalls())
+func (mock *QueueServiceMock) GetHistoryByInstanceUUIDCalls() []struct {
+	Ctx context.Context
+	ID  uuid.UUID
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  uuid.UUID
+	}
+	mock.lockGetHistoryByInstanceUUID.RLock()
+	calls = mock.calls.GetHistoryByInstanceUUID
+	mock.lockGetHistoryByInstanceUUID.RUnlock()
+	return calls
+}
+
 // GetNextWindow calls GetNextWindowFunc.
 func (mock *QueueServiceMock) GetNextWindow(ctx context.Context, q migration.QueueEntry) (*migration.Window, error) {
 	if mock.GetNextWindowFunc == nil {
@@ -804,6 +920,46 @@ func (mock *QueueServiceMock) RetryByUUIDCalls() []struct {
 	return calls
 }
 
+// RollbackByUUID calls RollbackByUUIDFunc.
+func (mock *QueueServiceMock) RollbackByUUID(ctx context.Context, id uuid.UUID, statusMessage string) (*migration.QueueEntry, error) {
+	if mock.RollbackByUUIDFunc == nil {
+		panic("QueueServiceMock.RollbackByUUIDFunc: method is nil but QueueService.RollbackByUUID was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		ID            uuid.UUID
+		StatusMessage string
+	}{
+		Ctx:           ctx,
+		ID:            id,
+		StatusMessage: statusMessage,
+	}
+	mock.lockRollbackByUUID.Lock()
+	mock.calls.RollbackByUUID = append(mock.calls.RollbackByUUID, callInfo)
+	mock.lockRollbackByUUID.Unlock()
+	return mock.RollbackByUUIDFunc(ctx, id, statusMessage)
+}
+
+// RollbackByUUIDCalls gets all the calls that were made to RollbackByUUID.
+// Check the length with:
+//
+//	len(mockedQueueService.RollbackByUUIDCalls())
+func (mock *QueueServiceMock) RollbackByUUIDCalls() []struct {
+	Ctx           context.Context
+	ID            uuid.UUID
+	StatusMessage string
+} {
+	var calls []struct {
+		Ctx           context.Context
+		ID            uuid.UUID
+		StatusMessage string
+	}
+	mock.lockRollbackByUUID.RLock()
+	calls = mock.calls.RollbackByUUID
+	mock.lockRollbackByUUID.RUnlock()
+	return calls
+}
+
 // Update calls UpdateFunc.
 func (mock *QueueServiceMock) Update(ctx context.Context, entry *migration.QueueEntry) error {
 	if mock.UpdateFunc == nil {
@@ -128,6 +128,145 @@ func TestQueueService_GetByInstanceID(t *testing.T) {
 	}
 }
 
+func TestQueueService_RollbackByUUID(t *testing.T) {
+	tests := []struct {
+		name string
+
+		repoGetByInstanceUUID           migration.QueueEntry
+		repoGetHistoryByInstanceUUID    migration.QueueHistoryEntries
+		repoGetHistoryByInstanceUUIDErr error
+		batchSvcGetByName               migration.Batch
+		repoUpdateErr                   error
+
+		assertErr         require.ErrorAssertionFunc
+		wantHistoryStatus api.MigrationStatusType
+	}{
+		{
+			name:                  "success - finished without grace period",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_FINISHED},
+			batchSvcGetByName:     migration.Batch{Name: "b1"},
+
+			assertErr:         require.NoError,
+			wantHistoryStatus: api.MIGRATIONSTATUS_ROLLED_BACK,
+		},
+		{
+			name:                  "success - finished within grace period",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_FINISHED},
+			repoGetHistoryByInstanceUUID: migration.QueueHistoryEntries{
+				{InstanceUUID: uuidA, MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Date: time.Now().Add(-2 * time.Hour)},
+				{InstanceUUID: uuidA, MigrationStatus: api.MIGRATIONSTATUS_CANCELED, Date: time.Now().Add(-90 * time.Minute)},
+				{InstanceUUID: uuidA, MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Date: time.Now().Add(-30 * time.Minute)},
+			},
+			batchSvcGetByName: migration.Batch{Name: "b1", Config: api.BatchConfig{RollbackGracePeriod: api.AsDuration(time.Hour)}},
+
+			assertErr:         require.NoError,
+			wantHistoryStatus: api.MIGRATIONSTATUS_ROLLED_BACK,
+		},
+		{
+			name:                  "success - validation failed after grace period",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_VALIDATION_FAILED},
+			batchSvcGetByName:     migration.Batch{Name: "b1", Config: api.BatchConfig{RollbackGracePeriod: api.AsDuration(time.Hour)}},
+
+			assertErr:         require.NoError,
+			wantHistoryStatus: api.MIGRATIONSTATUS_ROLLED_BACK,
+		},
+		{
+			name:                  "success - already rolled back",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_ROLLED_BACK, MigrationStatusMessage: "Rolled back to source", ImportStage: migration.IMPORTSTAGE_BACKGROUND},
+
+			assertErr: require.NoError,
+		},
+		{
+			name:                  "error - grace period has passed",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_FINISHED},
+			repoGetHistoryByInstanceUUID: migration.QueueHistoryEntries{
+				{InstanceUUID: uuidA, MigrationStatus: api.MIGRATIONSTATUS_FINISHED, Date: time.Now().Add(-2 * time.Hour)},
+			},
+			batchSvcGetByName: migration.Batch{Name: "b1", Config: api.BatchConfig{RollbackGracePeriod: api.AsDuration(time.Hour)}},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorIs(tt, err, migration.ErrOperationNotPermitted, a...)
+			},
+		},
+		{
+			name:                  "error - finish date unknown",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_FINISHED},
+			batchSvcGetByName:     migration.Batch{Name: "b1", Config: api.BatchConfig{RollbackGracePeriod: api.AsDuration(time.Hour)}},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorIs(tt, err, migration.ErrOperationNotPermitted, a...)
+			},
+		},
+		{
+			name:                  "error - migration not finished",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_BACKGROUND_IMPORT},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorIs(tt, err, migration.ErrOperationNotPermitted, a...)
+			},
+		},
+		{
+			name:                            "error - repo.GetHistoryByInstanceUUID",
+			repoGetByInstanceUUID:           migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_FINISHED},
+			repoGetHistoryByInstanceUUIDErr: boom.Error,
+			batchSvcGetByName:               migration.Batch{Name: "b1", Config: api.BatchConfig{RollbackGracePeriod: api.AsDuration(time.Hour)}},
+
+			assertErr: boom.ErrorIs,
+		},
+		{
+			name:                  "error - repo.Update",
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "b1", MigrationStatus: api.MIGRATIONSTATUS_FINISHED},
+			batchSvcGetByName:     migration.Batch{Name: "b1"},
+			repoUpdateErr:         boom.Error,
+
+			assertErr: boom.ErrorIs,
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			var historyStatus api.MigrationStatusType
+			repo := &mock.QueueRepoMock{
+				GetByInstanceUUIDFunc: func(ctx context.Context, id uuid.UUID) (*migration.QueueEntry, error) {
+					entry := tc.repoGetByInstanceUUID
+					return &entry, nil
+				},
+				GetHistoryByInstanceUUIDFunc: func(ctx context.Context, id uuid.UUID) (migration.QueueHistoryEntries, error) {
+					return tc.repoGetHistoryByInstanceUUID, tc.repoGetHistoryByInstanceUUIDErr
+				},
+				UpdateFunc: func(ctx context.Context, entry migration.QueueEntry) error {
+					require.Equal(t, api.MIGRATIONSTATUS_ROLLED_BACK, entry.MigrationStatus)
+					return tc.repoUpdateErr
+				},
+				CreateHistoryFunc: func(ctx context.Context, entry migration.QueueHistoryEntry) (int64, error) {
+					historyStatus = entry.MigrationStatus
+					return 1, nil
+				},
+			}
+
+			batchSvc := &BatchServiceMock{
+				GetByNameFunc: func(ctx context.Context, name string) (*migration.Batch, error) {
+					return &tc.batchSvcGetByName, nil
+				},
+			}
+
+			queueSvc := migration.NewQueueService(repo, batchSvc, nil, nil, nil, nil)
+
+			// Run test
+			queueEntry, err := queueSvc.RollbackByUUID(context.Background(), uuidA, "Rolled back to source")
+
+			// Assert
+			tc.assertErr(t, err)
+			require.Equal(t, tc.wantHistoryStatus, historyStatus)
+			if err == nil {
+				require.Equal(t, api.MIGRATIONSTATUS_ROLLED_BACK, queueEntry.MigrationStatus)
+				require.Equal(t, "Rolled back to source", queueEntry.MigrationStatusMessage)
+			}
+		})
+	}
+}
 func TestQueueService_NewWorkerCommandByInstanceUUID(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -354,6 +493,122 @@ func TestQueueService_NewWorkerCommandByInstanceUUID(t *testing.T) {
 			wantMigrationStatus:        api.MIGRATIONSTATUS_FINAL_IMPORT,
 			wantMigrationStatusMessage: string(api.MIGRATIONSTATUS_FINAL_IMPORT),
 		},
+		{
+			name:    "success - waiting for later instance group members to power off",
+			uuidArg: uuidA,
+
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL, Placement: api.Placement{TargetName: "one"}},
+			repoGetAll: migration.QueueEntries{
+				{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL},
+				{InstanceUUID: uuidB, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL},
+			},
+
+			batchSvcGetByName: migration.Batch{
+				Defaults:       defaultPlacement,
+				Name:           "one",
+				InstanceGroups: []api.BatchInstanceGroup{{Name: "app", IncludeExpression: `location matches "^/some/instance/"`, Order: []string{`location matches "A$"`}}},
+			},
+			instanceSvcGetByIDInstance: migration.Instance{
+				UUID:       uuidA,
+				Source:     "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: api.InstanceProperties{
+					Location:      "/some/instance/A",
+					OS:            "ubuntu",
+					OSDescription: "Ubuntu 24.04",
+				},
+			},
+			instanceSvcGetQueued: migration.Instances{
+				{UUID: uuidA, Properties: api.InstanceProperties{Location: "/some/instance/A"}},
+				{UUID: uuidB, Properties: api.InstanceProperties{Location: "/some/instance/B"}},
+			},
+			sourceSvcGetByIDSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: []byte("{}"),
+			},
+
+			targetSvcGetByIDTarget: migration.Target{
+				ID:         1,
+				Name:       "one",
+				TargetType: api.TARGETTYPE_INCUS,
+				Properties: []byte("{}"),
+			},
+
+			assertErr: require.NoError,
+			wantWorkerCommand: migration.WorkerCommand{
+				Command:       api.WORKERCOMMAND_IDLE,
+				Location:      "/some/instance/A",
+				SourceType:    api.SOURCETYPE_VMWARE,
+				Source:        migration.Source{ID: 1, Name: "one", SourceType: api.SOURCETYPE_VMWARE, Properties: []byte("{}")},
+				Distro:        api.DISTRO_UBUNTU,
+				DistroVersion: "24.04",
+				OSType:        api.OSTYPE_LINUX,
+				Architecture:  osarch.ArchitectureDefault,
+			},
+			wantMigrationStatus:        api.MIGRATIONSTATUS_IDLE,
+			wantMigrationStatusMessage: `Waiting for members of instance group "app" to power off`,
+		},
+		{
+			name:    "success - waiting for higher boot order tiers to power off",
+			uuidArg: uuidA,
+
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL, Placement: api.Placement{TargetName: "one"}},
+			repoGetAll: migration.QueueEntries{
+				{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL},
+				{InstanceUUID: uuidB, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL},
+			},
+
+			batchSvcGetByName: migration.Batch{
+				Defaults: defaultPlacement,
+				Name:     "one",
+				Config: api.BatchConfig{
+					BootOrder: []api.BatchBootTier{{IncludeExpression: `location matches "A$"`, Tier: 0}, {IncludeExpression: `location matches "B$"`, Tier: 1}},
+				},
+			},
+			instanceSvcGetByIDInstance: migration.Instance{
+				UUID:       uuidA,
+				Source:     "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: api.InstanceProperties{
+					Location:      "/some/instance/A",
+					OS:            "ubuntu",
+					OSDescription: "Ubuntu 24.04",
+				},
+			},
+			instanceSvcGetQueued: migration.Instances{
+				{UUID: uuidA, Properties: api.InstanceProperties{Location: "/some/instance/A"}},
+				{UUID: uuidB, Properties: api.InstanceProperties{Location: "/some/instance/B"}},
+			},
+			sourceSvcGetByIDSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: []byte("{}"),
+			},
+
+			targetSvcGetByIDTarget: migration.Target{
+				ID:         1,
+				Name:       "one",
+				TargetType: api.TARGETTYPE_INCUS,
+				Properties: []byte("{}"),
+			},
+
+			assertErr: require.NoError,
+			wantWorkerCommand: migration.WorkerCommand{
+				Command:       api.WORKERCOMMAND_IDLE,
+				Location:      "/some/instance/A",
+				SourceType:    api.SOURCETYPE_VMWARE,
+				Source:        migration.Source{ID: 1, Name: "one", SourceType: api.SOURCETYPE_VMWARE, Properties: []byte("{}")},
+				Distro:        api.DISTRO_UBUNTU,
+				DistroVersion: "24.04",
+				OSType:        api.OSTYPE_LINUX,
+				Architecture:  osarch.ArchitectureDefault,
+			},
+			wantMigrationStatus:        api.MIGRATIONSTATUS_IDLE,
+			wantMigrationStatusMessage: `Waiting for instances in boot order tier 1 to power off`,
+		},
 		{
 			name:    "success - without migration window start time",
 			uuidArg: uuidA,
@@ -542,6 +797,105 @@ func TestQueueService_NewWorkerCommandByInstanceUUID(t *testing.T) {
 			wantMigrationStatus:        api.MIGRATIONSTATUS_FINAL_IMPORT,
 			wantMigrationStatusMessage: string(api.MIGRATIONSTATUS_FINAL_IMPORT),
 		},
+		{
+			name:                  "success - migration window started (perform final import with disk verification)",
+			uuidArg:               uuidA,
+			batchSvcGetByName:     migration.Batch{Defaults: defaultPlacement, Name: "one", Config: api.BatchConfig{DiskVerification: api.DISKVERIFICATION_FULL}},
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL, Placement: api.Placement{TargetName: "one"}},
+			instanceSvcGetByIDInstance: migration.Instance{
+				UUID:       uuidA,
+				Source:     "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: api.InstanceProperties{
+					Location:         "/some/instance/A",
+					OS:               "ubuntu",
+					OSDescription:    "Ubuntu 24.04",
+					BackgroundImport: true,
+				},
+			},
+			sourceSvcGetByIDSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: []byte("{}"),
+			},
+			targetSvcGetByIDTarget: migration.Target{
+				ID:         1,
+				Name:       "one",
+				TargetType: api.TARGETTYPE_INCUS,
+				Properties: []byte("{}"),
+			},
+			batchSvcGetWindows: migration.Windows{{Name: "w1", Start: time.Now().Add(-time.Minute)}},
+
+			assertErr: require.NoError,
+			wantWorkerCommand: migration.WorkerCommand{
+				Command:    api.WORKERCOMMAND_FINALIZE_IMPORT,
+				Location:   "/some/instance/A",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Source: migration.Source{
+					ID:         1,
+					Name:       "one",
+					SourceType: api.SOURCETYPE_VMWARE,
+					Properties: []byte("{}"),
+				},
+				Distro:           api.DISTRO_UBUNTU,
+				DistroVersion:    "24.04",
+				OSType:           api.OSTYPE_LINUX,
+				Architecture:     osarch.ArchitectureDefault,
+				DiskVerification: api.DISKVERIFICATION_FULL,
+			},
+			wantMigrationStatus:        api.MIGRATIONSTATUS_FINAL_IMPORT,
+			wantMigrationStatusMessage: string(api.MIGRATIONSTATUS_FINAL_IMPORT),
+		},
+		{
+			name:                  "success - migration window started (perform final import, disk verification skipped for ovf)",
+			uuidArg:               uuidA,
+			batchSvcGetByName:     migration.Batch{Defaults: defaultPlacement, Name: "one", Config: api.BatchConfig{DiskVerification: api.DISKVERIFICATION_FULL}},
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_FINAL, Placement: api.Placement{TargetName: "one"}},
+			instanceSvcGetByIDInstance: migration.Instance{
+				UUID:       uuidA,
+				Source:     "one",
+				SourceType: api.SOURCETYPE_OVF,
+				Properties: api.InstanceProperties{
+					Location:         "/some/instance/A",
+					OS:               "ubuntu",
+					OSDescription:    "Ubuntu 24.04",
+					BackgroundImport: true,
+				},
+			},
+			sourceSvcGetByIDSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_OVF,
+				Properties: []byte("{}"),
+			},
+			targetSvcGetByIDTarget: migration.Target{
+				ID:         1,
+				Name:       "one",
+				TargetType: api.TARGETTYPE_INCUS,
+				Properties: []byte("{}"),
+			},
+			batchSvcGetWindows: migration.Windows{{Name: "w1", Start: time.Now().Add(-time.Minute)}},
+
+			assertErr: require.NoError,
+			wantWorkerCommand: migration.WorkerCommand{
+				Command:    api.WORKERCOMMAND_FINALIZE_IMPORT,
+				Location:   "/some/instance/A",
+				SourceType: api.SOURCETYPE_OVF,
+				Source: migration.Source{
+					ID:         1,
+					Name:       "one",
+					SourceType: api.SOURCETYPE_OVF,
+					Properties: []byte("{}"),
+				},
+				Distro:        api.DISTRO_UBUNTU,
+				DistroVersion: "24.04",
+				OSType:        api.OSTYPE_LINUX,
+				Architecture:  osarch.ArchitectureDefault,
+			},
+			wantMigrationStatus:        api.MIGRATIONSTATUS_FINAL_IMPORT,
+			wantMigrationStatusMessage: string(api.MIGRATIONSTATUS_FINAL_IMPORT),
+		},
 		{
 			name:                  "success - migration window started (perform full initial import)",
 			uuidArg:               uuidA,
@@ -640,6 +994,63 @@ func TestQueueService_NewWorkerCommandByInstanceUUID(t *testing.T) {
 			wantMigrationStatus:        api.MIGRATIONSTATUS_POST_IMPORT,
 			wantMigrationStatusMessage: string(api.MIGRATIONSTATUS_POST_IMPORT),
 		},
+		{
+			name:    "success - migration window started (perform post import with post-migration scripts)",
+			uuidArg: uuidA,
+			batchSvcGetByName: migration.Batch{Defaults: defaultPlacement, Name: "one", Config: api.BatchConfig{PostMigrationScripts: []api.PostMigrationScript{
+				{Artifact: uuidB, Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER},
+				{Artifact: uuidA, Stage: api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT},
+			}}},
+			repoGetByInstanceUUID: migration.QueueEntry{InstanceUUID: uuidA, BatchName: "one", MigrationStatus: api.MIGRATIONSTATUS_IDLE, ImportStage: migration.IMPORTSTAGE_COMPLETE, Placement: api.Placement{TargetName: "one"}},
+			instanceSvcGetByIDInstance: migration.Instance{
+				UUID:       uuidA,
+				Source:     "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: api.InstanceProperties{
+					Location:         "/some/instance/A",
+					OS:               "ubuntu",
+					OSDescription:    "Ubuntu 24.04",
+					BackgroundImport: true,
+				},
+				Overrides: api.InstanceOverride{PostMigrationScripts: []api.PostMigrationScript{
+					{Artifact: uuidA, Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER},
+					{Artifact: uuidB, Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER},
+				}},
+			},
+			sourceSvcGetByIDSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: []byte("{}"),
+			},
+			targetSvcGetByIDTarget: migration.Target{
+				ID:         1,
+				Name:       "one",
+				TargetType: api.TARGETTYPE_INCUS,
+				Properties: []byte("{}"),
+			},
+			batchSvcGetWindows: migration.Windows{{Name: "w1", Start: time.Now().Add(-time.Minute)}},
+
+			assertErr: require.NoError,
+			wantWorkerCommand: migration.WorkerCommand{
+				Command:    api.WORKERCOMMAND_POST_IMPORT,
+				Location:   "/some/instance/A",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Source: migration.Source{
+					ID:         1,
+					Name:       "one",
+					SourceType: api.SOURCETYPE_VMWARE,
+					Properties: []byte("{}"),
+				},
+				Distro:               api.DISTRO_UBUNTU,
+				DistroVersion:        "24.04",
+				OSType:               api.OSTYPE_LINUX,
+				Architecture:         osarch.ArchitectureDefault,
+				PostMigrationScripts: []api.PostMigrationScript{{Artifact: uuidB, Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER}, {Artifact: uuidA, Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER}},
+			},
+			wantMigrationStatus:        api.MIGRATIONSTATUS_POST_IMPORT,
+			wantMigrationStatusMessage: string(api.MIGRATIONSTATUS_POST_IMPORT),
+		},
 		{
 			name:                  "success - migration window started (perform post import with full import)",
 			uuidArg:               uuidA,
@@ -1057,10 +1468,18 @@ func TestQueueService_NewWorkerCommandByInstanceUUID(t *testing.T) {
 					return tc.repoUpdateErr
 				},
 
+				CreateHistoryFunc: func(ctx context.Context, entry migration.QueueHistoryEntry) (int64, error) {
+					return 1, nil
+				},
+
 				GetAllByBatchAndStateFunc: func(ctx context.Context, batch string, statuses ...api.MigrationStatusType) (migration.QueueEntries, error) {
 					return tc.repoGetAll, tc.repoGetAllErr
 				},
 
+				GetAllByBatchFunc: func(ctx context.Context, batch string) (migration.QueueEntries, error) {
+					return tc.repoGetAll, tc.repoGetAllErr
+				},
+
 				GetAllFunc: func(ctx context.Context) (migration.QueueEntries, error) {
 					return tc.repoGetAll, nil
 				},
@@ -1133,11 +1552,65 @@ func TestQueueService_ProcessWorkerUpdate(t *testing.T) {
 		batchSvcGetByNameBatch migration.Batch
 		batchSvcGetByNameErr   error
 
+		progressArg      *api.DiskTransferProgress
+		scriptResultsArg []api.PostMigrationScriptResult
+		changeIDsArg     []api.DiskChangeID
+		verificationArg  []api.DiskVerificationResult
+
 		assertErr                  require.ErrorAssertionFunc
 		wantMigrationStatus        api.MigrationStatusType
 		wantMigrationStatusMessage string
 		wantImportStage            migration.ImportStage
+		wantTransferProgress       *api.DiskTransferProgress
+		wantScriptResults          []api.PostMigrationScriptResult
+		wantChangeIDs              []api.DiskChangeID
+		wantVerificationResults    []api.DiskVerificationResult
 	}{
+		{
+			name:                  "success - migration running with transfer progress",
+			uuidArg:               uuidA,
+			workerResponseTypeArg: api.WORKERRESPONSE_RUNNING,
+			statusStringArg:       "Importing disk (1/1) \"disk\": 25.00% complete",
+			progressArg:           &api.DiskTransferProgress{BytesCopied: 25, BytesTotal: 100, BytesPerSecond: 5, ETA: api.AsDuration(15 * time.Second), Disks: []api.DiskProgress{{Name: "disk", BytesCopied: 25, BytesTotal: 100}}},
+			repoGetByUUIDQueueEntry: &migration.QueueEntry{
+				InstanceUUID:    uuidA,
+				MigrationStatus: api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+				BatchName:       "one",
+				ImportStage:     migration.IMPORTSTAGE_BACKGROUND,
+			},
+
+			assertErr:                  require.NoError,
+			wantMigrationStatus:        api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+			wantMigrationStatusMessage: "Importing disk (1/1) \"disk\": 25.00% complete",
+			wantImportStage:            migration.IMPORTSTAGE_BACKGROUND,
+			wantTransferProgress:       &api.DiskTransferProgress{BytesCopied: 25, BytesTotal: 100, BytesPerSecond: 5, ETA: api.AsDuration(15 * time.Second), Disks: []api.DiskProgress{{Name: "disk", BytesCopied: 25, BytesTotal: 100}}},
+		},
+		{
+			name:                  "success - migration running with change IDs",
+			uuidArg:               uuidA,
+			workerResponseTypeArg: api.WORKERRESPONSE_RUNNING,
+			statusStringArg:       "Finished importing disk \"disk2\"",
+			changeIDsArg:          []api.DiskChangeID{{Disk: "disk2", ChangeID: "52 d2/14", Snapshot: "snapshot-2", TargetDevice: "disk1"}},
+			repoGetByUUIDQueueEntry: &migration.QueueEntry{
+				InstanceUUID:    uuidA,
+				MigrationStatus: api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+				BatchName:       "one",
+				ImportStage:     migration.IMPORTSTAGE_BACKGROUND,
+				ChangeIDs: []api.DiskChangeID{
+					{Disk: "disk1", ChangeID: "52 d1/12", Snapshot: "snapshot-1", TargetDevice: "root"},
+					{Disk: "disk2", ChangeID: "52 d2/12", Snapshot: "snapshot-1", TargetDevice: "disk1"},
+				},
+			},
+
+			assertErr:                  require.NoError,
+			wantMigrationStatus:        api.MIGRATIONSTATUS_BACKGROUND_IMPORT,
+			wantMigrationStatusMessage: "Finished importing disk \"disk2\"",
+			wantImportStage:            migration.IMPORTSTAGE_BACKGROUND,
+			wantChangeIDs: []api.DiskChangeID{
+				{Disk: "disk1", ChangeID: "52 d1/12", Snapshot: "snapshot-1", TargetDevice: "root"},
+				{Disk: "disk2", ChangeID: "52 d2/14", Snapshot: "snapshot-2", TargetDevice: "disk1"},
+			},
+		},
 		{
 			name:                  "success - migration running",
 			uuidArg:               uuidA,
@@ -1233,6 +1706,53 @@ func TestQueueService_ProcessWorkerUpdate(t *testing.T) {
 			wantMigrationStatusMessage: "Starting target instance",
 			wantImportStage:            migration.IMPORTSTAGE_COMPLETE,
 		},
+		{
+			name:                  "success - migration success post import with script results",
+			uuidArg:               uuidA,
+			workerResponseTypeArg: api.WORKERRESPONSE_SUCCESS,
+			statusStringArg:       "done",
+			scriptResultsArg:      []api.PostMigrationScriptResult{{Artifact: uuidB, File: "b.sh", Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER}},
+			repoGetByUUIDQueueEntry: &migration.QueueEntry{
+				InstanceUUID: uuidA,
+
+				MigrationStatus: api.MIGRATIONSTATUS_POST_IMPORT,
+				BatchName:       "one",
+				ImportStage:     migration.IMPORTSTAGE_COMPLETE,
+				Placement:       api.Placement{TargetName: "one"},
+				ScriptResults: []api.PostMigrationScriptResult{
+					{Artifact: uuidA, File: "a.sh", Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER, ExitCode: 1},
+					{Artifact: uuidA, File: "a.sh", Stage: api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT},
+				},
+			},
+
+			assertErr:                  require.NoError,
+			wantMigrationStatus:        api.MIGRATIONSTATUS_WORKER_DONE,
+			wantMigrationStatusMessage: "Starting target instance",
+			wantImportStage:            migration.IMPORTSTAGE_COMPLETE,
+			wantScriptResults: []api.PostMigrationScriptResult{
+				{Artifact: uuidA, File: "a.sh", Stage: api.POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT},
+				{Artifact: uuidB, File: "b.sh", Stage: api.POSTMIGRATIONSCRIPTSTAGE_WORKER},
+			},
+		},
+		{
+			name:                  "success - migration running with disk verification results",
+			uuidArg:               uuidA,
+			workerResponseTypeArg: api.WORKERRESPONSE_RUNNING,
+			statusStringArg:       "Disk verification completed",
+			verificationArg:       []api.DiskVerificationResult{{Disk: "disk", Mode: api.DISKVERIFICATION_FULL, BytesVerified: 100, BytesTotal: 100, MismatchedRanges: []api.DiskRange{{Offset: 10, Length: 10}}}},
+			repoGetByUUIDQueueEntry: &migration.QueueEntry{
+				InstanceUUID:    uuidA,
+				MigrationStatus: api.MIGRATIONSTATUS_FINAL_IMPORT,
+				BatchName:       "one",
+				ImportStage:     migration.IMPORTSTAGE_FINAL,
+			},
+
+			assertErr:                  require.NoError,
+			wantMigrationStatus:        api.MIGRATIONSTATUS_FINAL_IMPORT,
+			wantMigrationStatusMessage: "Disk verification completed",
+			wantImportStage:            migration.IMPORTSTAGE_FINAL,
+			wantVerificationResults:    []api.DiskVerificationResult{{Disk: "disk", Mode: api.DISKVERIFICATION_FULL, BytesVerified: 100, BytesTotal: 100, MismatchedRanges: []api.DiskRange{{Offset: 10, Length: 10}}}},
+		},
 		{
 			name:                  "success - migration failed",
 			uuidArg:               uuidA,
@@ -1307,8 +1827,15 @@ func TestQueueService_ProcessWorkerUpdate(t *testing.T) {
 					require.Equal(t, tc.wantMigrationStatus, i.MigrationStatus)
 					require.Equal(t, tc.wantMigrationStatusMessage, i.MigrationStatusMessage)
 					require.Equal(t, tc.wantImportStage, i.ImportStage)
+					require.Equal(t, tc.wantTransferProgress, i.TransferProgress)
+					require.Equal(t, tc.wantScriptResults, i.ScriptResults)
+					require.Equal(t, tc.wantChangeIDs, i.ChangeIDs)
+					require.Equal(t, tc.wantVerificationResults, i.VerificationResults)
 					return tc.repoUpdateStatusByUUIDErr
 				},
+				CreateHistoryFunc: func(ctx context.Context, entry migration.QueueHistoryEntry) (int64, error) {
+					return 1, nil
+				},
 			}
 
 			instanceSvc := &InstanceServiceMock{
@@ -1335,8 +1862,12 @@ func TestQueueService_ProcessWorkerUpdate(t *testing.T) {
 
 			// Run test
 			resp := api.WorkerResponse{
-				Status:        tc.workerResponseTypeArg,
-				StatusMessage: tc.statusStringArg,
+				Status:              tc.workerResponseTypeArg,
+				StatusMessage:       tc.statusStringArg,
+				Progress:            tc.progressArg,
+				ScriptResults:       tc.scriptResultsArg,
+				ChangeIDs:           tc.changeIDsArg,
+				VerificationResults: tc.verificationArg,
 			}
 
 			_, err := queueSvc.ProcessWorkerUpdate(context.Background(), tc.uuidArg, resp)
@@ -1720,3 +2251,158 @@ func TestQueueService_GetNextWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestQueueService_GetNextWindow_InstanceGroups(t *testing.T) {
+	type member struct {
+		status      api.MigrationStatusType
+		importStage migration.ImportStage
+		window      string
+	}
+
+	idle := member{status: api.MIGRATIONSTATUS_IDLE, importStage: migration.IMPORTSTAGE_FINAL}
+
+	cases := []struct {
+		name           string
+		members        []member
+		windows        migration.Windows
+		waitingEntries map[string]int
+
+		wantWindow string
+		assertErr  require.ErrorAssertionFunc
+	}{
+		{
+			name:       "success - all members ready",
+			members:    []member{idle, idle},
+			windows:    migration.Windows{{Name: "w0", Start: time.Now().Add(10 * time.Minute), End: time.Now().Add(time.Hour)}},
+			wantWindow: "w0",
+			assertErr:  require.NoError,
+		},
+		{
+			name:           "success - earlier window lacks capacity for the whole group",
+			members:        []member{idle, idle},
+			windows:        migration.Windows{{Name: "w0", Start: time.Now().Add(10 * time.Minute), End: time.Now().Add(time.Hour), Config: api.MigrationWindowConfig{Capacity: 3}}, {Name: "w1", Start: time.Now().Add(2 * time.Hour), End: time.Now().Add(3 * time.Hour), Config: api.MigrationWindowConfig{Capacity: 3}}},
+			waitingEntries: map[string]int{"w0": 2},
+			wantWindow:     "w1",
+			assertErr:      require.NoError,
+		},
+		{
+			name:           "success - window of another member is shared",
+			members:        []member{{status: api.MIGRATIONSTATUS_FINAL_IMPORT, importStage: migration.IMPORTSTAGE_FINAL, window: "w1"}, idle},
+			windows:        migration.Windows{{Name: "w0", Start: time.Now().Add(-10 * time.Minute), End: time.Now().Add(time.Hour)}, {Name: "w1", Start: time.Now().Add(-5 * time.Minute), End: time.Now().Add(time.Hour), Config: api.MigrationWindowConfig{Capacity: 1}}},
+			waitingEntries: map[string]int{"w1": 1},
+			wantWindow:     "w1",
+			assertErr:      require.NoError,
+		},
+		{
+			name:    "error - member still performing background import",
+			members: []member{{status: api.MIGRATIONSTATUS_BACKGROUND_IMPORT, importStage: migration.IMPORTSTAGE_BACKGROUND}, idle},
+			windows: migration.Windows{{Name: "w0", Start: time.Now().Add(10 * time.Minute), End: time.Now().Add(time.Hour)}},
+			assertErr: func(tt require.TestingT, err error, i ...any) {
+				require.True(tt, incusAPI.StatusErrorCheck(err, http.StatusNotFound))
+			},
+		},
+		{
+			name:    "error - member failed",
+			members: []member{{status: api.MIGRATIONSTATUS_ERROR, importStage: migration.IMPORTSTAGE_FINAL, window: "w0"}, idle},
+			windows: migration.Windows{{Name: "w0", Start: time.Now().Add(-10 * time.Minute), End: time.Now().Add(time.Hour)}},
+			assertErr: func(tt require.TestingT, err error, i ...any) {
+				require.True(tt, incusAPI.StatusErrorCheck(err, http.StatusNotFound))
+			},
+		},
+		{
+			name:    "error - member failed its validation checks",
+			members: []member{{status: api.MIGRATIONSTATUS_VALIDATION_FAILED, importStage: migration.IMPORTSTAGE_COMPLETE}, idle},
+			windows: migration.Windows{{Name: "w0", Start: time.Now().Add(-10 * time.Minute), End: time.Now().Add(time.Hour)}},
+			assertErr: func(tt require.TestingT, err error, i ...any) {
+				require.True(tt, incusAPI.StatusErrorCheck(err, http.StatusNotFound))
+			},
+		},
+		{
+			name:    "error - member canceled",
+			members: []member{{status: api.MIGRATIONSTATUS_CANCELED, importStage: migration.IMPORTSTAGE_BACKGROUND}, idle},
+			windows: migration.Windows{{Name: "w0", Start: time.Now().Add(-10 * time.Minute), End: time.Now().Add(time.Hour)}},
+			assertErr: func(tt require.TestingT, err error, i ...any) {
+				require.True(tt, incusAPI.StatusErrorCheck(err, http.StatusNotFound))
+			},
+		},
+		{
+			name:    "error - no window has capacity for the whole group",
+			members: []member{idle, idle, idle},
+			windows: migration.Windows{{Name: "w0", Start: time.Now().Add(10 * time.Minute), End: time.Now().Add(time.Hour), Config: api.MigrationWindowConfig{Capacity: 2}}},
+			assertErr: func(tt require.TestingT, err error, i ...any) {
+				require.True(tt, incusAPI.StatusErrorCheck(err, http.StatusNotFound))
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			// The last member is the queue entry requesting a window.
+			entries := migration.QueueEntries{}
+			instances := migration.Instances{}
+			for j, m := range tc.members {
+				instUUID := uuid.New()
+				entries = append(entries, migration.QueueEntry{
+					InstanceUUID:        instUUID,
+					BatchName:           "one",
+					MigrationStatus:     m.status,
+					ImportStage:         m.importStage,
+					MigrationWindowName: sql.NullString{Valid: m.window != "", String: m.window},
+				})
+
+				instances = append(instances, migration.Instance{
+					UUID:       instUUID,
+					Properties: api.InstanceProperties{Location: "/webshop/vm" + strconv.Itoa(j), BackgroundImport: true},
+				})
+			}
+
+			queueEntry := entries[len(entries)-1]
+			repo := &mock.QueueRepoMock{
+				GetAllByBatchAndStateFunc: func(ctx context.Context, batch string, statuses ...api.MigrationStatusType) (migration.QueueEntries, error) {
+					return entries, nil
+				},
+				GetAllByBatchFunc: func(ctx context.Context, batch string) (migration.QueueEntries, error) {
+					return entries, nil
+				},
+				GetAllFunc: func(ctx context.Context) (migration.QueueEntries, error) {
+					all := migration.QueueEntries{}
+					all = append(all, entries...)
+					for wName, count := range tc.waitingEntries {
+						for i := 0; i < count; i++ {
+							all = append(all, migration.QueueEntry{MigrationWindowName: sql.NullString{Valid: true, String: wName}})
+						}
+					}
+
+					return all, nil
+				},
+			}
+
+			instanceSvc := &InstanceServiceMock{
+				GetAllQueuedFunc: func(ctx context.Context, queue migration.QueueEntries) (migration.Instances, error) {
+					return instances, nil
+				},
+			}
+
+			batchSvc := &BatchServiceMock{
+				GetByNameFunc: func(ctx context.Context, name string) (*migration.Batch, error) {
+					return &migration.Batch{Name: name, InstanceGroups: []api.BatchInstanceGroup{{Name: "webshop", IncludeExpression: `location matches "^/webshop/"`}}}, nil
+				},
+			}
+
+			windowSvc := &WindowServiceMock{
+				GetAllByBatchFunc: func(ctx context.Context, batchName string) (migration.Windows, error) {
+					return tc.windows, nil
+				},
+			}
+
+			queueSvc := migration.NewQueueService(repo, batchSvc, instanceSvc, nil, nil, windowSvc)
+			w, err := queueSvc.GetNextWindow(context.Background(), queueEntry)
+			tc.assertErr(t, err)
+			if err == nil {
+				require.Equal(t, tc.wantWindow, w.Name)
+			}
+		})
+	}
+}
@@ -30,7 +30,9 @@ var (
 		Config: api.BatchConfig{
 			BackgroundSyncInterval:   api.AsDuration(10 * time.Minute),
 			FinalBackgroundSyncLimit: api.AsDuration(10 * time.Minute),
+			CreateTargetNetworks:     true,
 		},
+		CreatedNetworks: []api.BatchCreatedNetwork{{Name: "net1", Type: "bridge", Target: "TestTarget", TargetProject: "default", SourceNetwork: "/dc/network/net1"}},
 	}
 
 	batchB = migration.Batch{
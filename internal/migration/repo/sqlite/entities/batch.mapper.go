@@ -13,27 +13,27 @@ import (
 )
 
 var batchObjects = RegisterStmt(`
-SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.config, batches.defaults
+SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.instance_groups, batches.config, batches.defaults, batches.created_networks, batches.migration_window_schedules, batches.migration_window_exclusions
   FROM batches
   ORDER BY batches.name
 `)
 
 var batchObjectsByID = RegisterStmt(`
-SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.config, batches.defaults
+SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.instance_groups, batches.config, batches.defaults, batches.created_networks, batches.migration_window_schedules, batches.migration_window_exclusions
   FROM batches
   WHERE ( batches.id = ? )
   ORDER BY batches.name
 `)
 
 var batchObjectsByName = RegisterStmt(`
-SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.config, batches.defaults
+SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.instance_groups, batches.config, batches.defaults, batches.created_networks, batches.migration_window_schedules, batches.migration_window_exclusions
   FROM batches
   WHERE ( batches.name = ? )
   ORDER BY batches.name
 `)
 
 var batchObjectsByStatus = RegisterStmt(`
-SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.config, batches.defaults
+SELECT batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.instance_groups, batches.config, batches.defaults, batches.created_networks, batches.migration_window_schedules, batches.migration_window_exclusions
   FROM batches
   WHERE ( batches.status = ? )
   ORDER BY batches.name
@@ -58,13 +58,13 @@ SELECT batches.id FROM batches
 `)
 
 var batchCreate = RegisterStmt(`
-INSERT INTO batches (name, status, status_message, include_expression, start_date, constraints, config, defaults)
-  VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO batches (name, status, status_message, include_expression, start_date, constraints, instance_groups, config, defaults, created_networks, migration_window_schedules, migration_window_exclusions)
+  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `)
 
 var batchUpdate = RegisterStmt(`
 UPDATE batches
-  SET name = ?, status = ?, status_message = ?, include_expression = ?, start_date = ?, constraints = ?, config = ?, defaults = ?
+  SET name = ?, status = ?, status_message = ?, include_expression = ?, start_date = ?, constraints = ?, instance_groups = ?, config = ?, defaults = ?, created_networks = ?, migration_window_schedules = ?, migration_window_exclusions = ?
  WHERE id = ?
 `)
 
@@ -156,7 +156,7 @@ func GetBatch(ctx context.Context, db dbtx, name string) (_ *migration.Batch, _e
 // batchColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the Batch entity.
 func batchColumns() string {
-	return "batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.config, batches.defaults"
+	return "batches.id, batches.name, batches.status, batches.status_message, batches.include_expression, batches.start_date, batches.constraints, batches.instance_groups, batches.config, batches.defaults, batches.created_networks, batches.migration_window_schedules, batches.migration_window_exclusions"
 }
 
 // getBatches can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -166,9 +166,13 @@ func getBatches(ctx context.Context, stmt *sql.Stmt, args ...any) ([]migration.B
 	dest := func(scan func(dest ...any) error) error {
 		b := migration.Batch{}
 		var constraintsStr string
+		var instanceGroupsStr string
 		var configStr string
 		var defaultsStr string
-		err := scan(&b.ID, &b.Name, &b.Status, &b.StatusMessage, &b.IncludeExpression, &b.StartDate, &constraintsStr, &configStr, &defaultsStr)
+		var createdNetworksStr string
+		var migrationWindowSchedulesStr string
+		var migrationWindowExclusionsStr string
+		err := scan(&b.ID, &b.Name, &b.Status, &b.StatusMessage, &b.IncludeExpression, &b.StartDate, &constraintsStr, &instanceGroupsStr, &configStr, &defaultsStr, &createdNetworksStr, &migrationWindowSchedulesStr, &migrationWindowExclusionsStr)
 		if err != nil {
 			return err
 		}
@@ -178,6 +182,11 @@ func getBatches(ctx context.Context, stmt *sql.Stmt, args ...any) ([]migration.B
 			return err
 		}
 
+		err = unmarshalJSON(instanceGroupsStr, &b.InstanceGroups)
+		if err != nil {
+			return err
+		}
+
 		err = unmarshalJSON(configStr, &b.Config)
 		if err != nil {
 			return err
@@ -188,6 +197,21 @@ func getBatches(ctx context.Context, stmt *sql.Stmt, args ...any) ([]migration.B
 			return err
 		}
 
+		err = unmarshalJSON(createdNetworksStr, &b.CreatedNetworks)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(migrationWindowSchedulesStr, &b.MigrationWindowSchedules)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(migrationWindowExclusionsStr, &b.MigrationWindowExclusions)
+		if err != nil {
+			return err
+		}
+
 		objects = append(objects, b)
 
 		return nil
@@ -208,9 +232,13 @@ func getBatchesRaw(ctx context.Context, db dbtx, sql string, args ...any) ([]mig
 	dest := func(scan func(dest ...any) error) error {
 		b := migration.Batch{}
 		var constraintsStr string
+		var instanceGroupsStr string
 		var configStr string
 		var defaultsStr string
-		err := scan(&b.ID, &b.Name, &b.Status, &b.StatusMessage, &b.IncludeExpression, &b.StartDate, &constraintsStr, &configStr, &defaultsStr)
+		var createdNetworksStr string
+		var migrationWindowSchedulesStr string
+		var migrationWindowExclusionsStr string
+		err := scan(&b.ID, &b.Name, &b.Status, &b.StatusMessage, &b.IncludeExpression, &b.StartDate, &constraintsStr, &instanceGroupsStr, &configStr, &defaultsStr, &createdNetworksStr, &migrationWindowSchedulesStr, &migrationWindowExclusionsStr)
 		if err != nil {
 			return err
 		}
@@ -220,6 +248,11 @@ func getBatchesRaw(ctx context.Context, db dbtx, sql string, args ...any) ([]mig
 			return err
 		}
 
+		err = unmarshalJSON(instanceGroupsStr, &b.InstanceGroups)
+		if err != nil {
+			return err
+		}
+
 		err = unmarshalJSON(configStr, &b.Config)
 		if err != nil {
 			return err
@@ -230,6 +263,21 @@ func getBatchesRaw(ctx context.Context, db dbtx, sql string, args ...any) ([]mig
 			return err
 		}
 
+		err = unmarshalJSON(createdNetworksStr, &b.CreatedNetworks)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(migrationWindowSchedulesStr, &b.MigrationWindowSchedules)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(migrationWindowExclusionsStr, &b.MigrationWindowExclusions)
+		if err != nil {
+			return err
+		}
+
 		objects = append(objects, b)
 
 		return nil
@@ -457,7 +505,7 @@ func CreateBatch(ctx context.Context, db dbtx, object migration.Batch) (_ int64,
 		_err = mapErr(_err, "Batch")
 	}()
 
-	args := make([]any, 8)
+	args := make([]any, 12)
 
 	// Populate the statement arguments.
 	args[0] = object.Name
@@ -471,18 +519,42 @@ func CreateBatch(ctx context.Context, db dbtx, object migration.Batch) (_ int64,
 	}
 
 	args[5] = marshaledConstraints
+	marshaledInstanceGroups, err := marshalJSON(object.InstanceGroups)
+	if err != nil {
+		return -1, err
+	}
+
+	args[6] = marshaledInstanceGroups
 	marshaledConfig, err := marshalJSON(object.Config)
 	if err != nil {
 		return -1, err
 	}
 
-	args[6] = marshaledConfig
+	args[7] = marshaledConfig
 	marshaledDefaults, err := marshalJSON(object.Defaults)
 	if err != nil {
 		return -1, err
 	}
 
-	args[7] = marshaledDefaults
+	args[8] = marshaledDefaults
+	marshaledCreatedNetworks, err := marshalJSON(object.CreatedNetworks)
+	if err != nil {
+		return -1, err
+	}
+
+	args[9] = marshaledCreatedNetworks
+	marshaledMigrationWindowSchedules, err := marshalJSON(object.MigrationWindowSchedules)
+	if err != nil {
+		return -1, err
+	}
+
+	args[10] = marshaledMigrationWindowSchedules
+	marshaledMigrationWindowExclusions, err := marshalJSON(object.MigrationWindowExclusions)
+	if err != nil {
+		return -1, err
+	}
+
+	args[11] = marshaledMigrationWindowExclusions
 
 	// Prepared statement to use.
 	stmt, err := Stmt(db, batchCreate)
@@ -530,6 +602,11 @@ func UpdateBatch(ctx context.Context, db tx, name string, object migration.Batch
 		return err
 	}
 
+	marshaledInstanceGroups, err := marshalJSON(object.InstanceGroups)
+	if err != nil {
+		return err
+	}
+
 	marshaledConfig, err := marshalJSON(object.Config)
 	if err != nil {
 		return err
@@ -540,7 +617,22 @@ func UpdateBatch(ctx context.Context, db tx, name string, object migration.Batch
 		return err
 	}
 
-	result, err := stmt.Exec(object.Name, object.Status, object.StatusMessage, object.IncludeExpression, object.StartDate, marshaledConstraints, marshaledConfig, marshaledDefaults, id)
+	marshaledCreatedNetworks, err := marshalJSON(object.CreatedNetworks)
+	if err != nil {
+		return err
+	}
+
+	marshaledMigrationWindowSchedules, err := marshalJSON(object.MigrationWindowSchedules)
+	if err != nil {
+		return err
+	}
+
+	marshaledMigrationWindowExclusions, err := marshalJSON(object.MigrationWindowExclusions)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.Exec(object.Name, object.Status, object.StatusMessage, object.IncludeExpression, object.StartDate, marshaledConstraints, marshaledInstanceGroups, marshaledConfig, marshaledDefaults, marshaledCreatedNetworks, marshaledMigrationWindowSchedules, marshaledMigrationWindowExclusions, id)
 	if err != nil {
 		return fmt.Errorf("Update \"batches\" entry failed: %w", err)
 	}
@@ -11,14 +11,14 @@ import (
 )
 
 var migrationWindowObjects = RegisterStmt(`
-SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config
+SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config, migration_windows.schedule
   FROM migration_windows
   JOIN batches ON migration_windows.batch_id = batches.id
   ORDER BY migration_windows.start
 `)
 
 var migrationWindowObjectsByID = RegisterStmt(`
-SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config
+SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config, migration_windows.schedule
   FROM migration_windows
   JOIN batches ON migration_windows.batch_id = batches.id
   WHERE ( migration_windows.id = ? )
@@ -26,7 +26,7 @@ SELECT migration_windows.id, migration_windows.name, migration_windows.start, mi
 `)
 
 var migrationWindowObjectsByName = RegisterStmt(`
-SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config
+SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config, migration_windows.schedule
   FROM migration_windows
   JOIN batches ON migration_windows.batch_id = batches.id
   WHERE ( migration_windows.name = ? )
@@ -34,7 +34,7 @@ SELECT migration_windows.id, migration_windows.name, migration_windows.start, mi
 `)
 
 var migrationWindowObjectsByBatch = RegisterStmt(`
-SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config
+SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config, migration_windows.schedule
   FROM migration_windows
   JOIN batches ON migration_windows.batch_id = batches.id
   WHERE ( batch = ? )
@@ -42,7 +42,7 @@ SELECT migration_windows.id, migration_windows.name, migration_windows.start, mi
 `)
 
 var migrationWindowObjectsByNameAndBatch = RegisterStmt(`
-SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config
+SELECT migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config, migration_windows.schedule
   FROM migration_windows
   JOIN batches ON migration_windows.batch_id = batches.id
   WHERE ( migration_windows.name = ? AND batch = ? )
@@ -56,13 +56,13 @@ SELECT migration_windows.id FROM migration_windows
 `)
 
 var migrationWindowCreate = RegisterStmt(`
-INSERT INTO migration_windows (name, start, end, lockout, batch_id, config)
-  VALUES (?, ?, ?, ?, (SELECT batches.id FROM batches WHERE batches.name = ?), ?)
+INSERT INTO migration_windows (name, start, end, lockout, batch_id, config, schedule)
+  VALUES (?, ?, ?, ?, (SELECT batches.id FROM batches WHERE batches.name = ?), ?, ?)
 `)
 
 var migrationWindowUpdate = RegisterStmt(`
 UPDATE migration_windows
-  SET name = ?, start = ?, end = ?, lockout = ?, batch_id = (SELECT batches.id FROM batches WHERE batches.name = ?), config = ?
+  SET name = ?, start = ?, end = ?, lockout = ?, batch_id = (SELECT batches.id FROM batches WHERE batches.name = ?), config = ?, schedule = ?
  WHERE id = ?
 `)
 
@@ -125,7 +125,7 @@ func GetMigrationWindow(ctx context.Context, db dbtx, name string, batch string)
 // migrationWindowColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the MigrationWindow entity.
 func migrationWindowColumns() string {
-	return "migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config"
+	return "migration_windows.id, migration_windows.name, migration_windows.start, migration_windows.end, migration_windows.lockout, batches.name AS batch, migration_windows.config, migration_windows.schedule"
 }
 
 // getMigrationWindows can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -135,7 +135,7 @@ func getMigrationWindows(ctx context.Context, stmt *sql.Stmt, args ...any) ([]Mi
 	dest := func(scan func(dest ...any) error) error {
 		m := MigrationWindow{}
 		var configStr string
-		err := scan(&m.ID, &m.Name, &m.Start, &m.End, &m.Lockout, &m.Batch, &configStr)
+		err := scan(&m.ID, &m.Name, &m.Start, &m.End, &m.Lockout, &m.Batch, &configStr, &m.Schedule)
 		if err != nil {
 			return err
 		}
@@ -165,7 +165,7 @@ func getMigrationWindowsRaw(ctx context.Context, db dbtx, sql string, args ...an
 	dest := func(scan func(dest ...any) error) error {
 		m := MigrationWindow{}
 		var configStr string
-		err := scan(&m.ID, &m.Name, &m.Start, &m.End, &m.Lockout, &m.Batch, &configStr)
+		err := scan(&m.ID, &m.Name, &m.Start, &m.End, &m.Lockout, &m.Batch, &configStr, &m.Schedule)
 		if err != nil {
 			return err
 		}
@@ -338,7 +338,7 @@ func CreateMigrationWindow(ctx context.Context, db dbtx, object MigrationWindow)
 		_err = mapErr(_err, "Migration_window")
 	}()
 
-	args := make([]any, 6)
+	args := make([]any, 7)
 
 	// Populate the statement arguments.
 	args[0] = object.Name
@@ -352,6 +352,7 @@ func CreateMigrationWindow(ctx context.Context, db dbtx, object MigrationWindow)
 	}
 
 	args[5] = marshaledConfig
+	args[6] = object.Schedule
 
 	// Prepared statement to use.
 	stmt, err := Stmt(db, migrationWindowCreate)
@@ -399,7 +400,7 @@ func UpdateMigrationWindow(ctx context.Context, db tx, name string, batch string
 		return err
 	}
 
-	result, err := stmt.Exec(object.Name, object.Start, object.End, object.Lockout, object.Batch, marshaledConfig, id)
+	result, err := stmt.Exec(object.Name, object.Start, object.End, object.Lockout, object.Batch, marshaledConfig, object.Schedule, id)
 	if err != nil {
 		return fmt.Errorf("Update \"migration_windows\" entry failed: %w", err)
 	}
@@ -14,7 +14,7 @@ import (
 )
 
 var queueEntryObjects = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -23,7 +23,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByInstanceUUID = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -33,7 +33,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByBatchName = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -43,7 +43,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByMigrationStatus = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -53,7 +53,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByImportStage = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -63,7 +63,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByBatchNameAndMigrationStatus = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -73,7 +73,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByBatchNameAndImportStage = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -83,7 +83,7 @@ SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, qu
 `)
 
 var queueEntryObjectsByBatchNameAndMigrationStatusAndImportStage = RegisterStmt(`
-SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement
+SELECT queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status
   FROM queue
   JOIN instances ON queue.instance_id = instances.id
   JOIN batches ON queue.batch_id = batches.id
@@ -99,13 +99,13 @@ SELECT queue.id FROM queue
 `)
 
 var queueEntryCreate = RegisterStmt(`
-INSERT INTO queue (instance_id, batch_id, secret_token, import_stage, migration_status, migration_status_message, last_worker_status, last_background_sync, migration_window_id, placement)
-  VALUES ((SELECT instances.id FROM instances WHERE instances.uuid = ?), (SELECT batches.id FROM batches WHERE batches.name = ?), ?, ?, ?, ?, ?, ?, (SELECT migration_windows.id FROM migration_windows JOIN batches ON migration_windows.batch_id = batches.id WHERE migration_windows.name = ? AND batches.id = batch_id), ?)
+INSERT INTO queue (instance_id, batch_id, secret_token, import_stage, migration_status, migration_status_message, last_worker_status, last_background_sync, migration_window_id, placement, transfer_progress, script_results, change_ids, verification_results, boot_status)
+  VALUES ((SELECT instances.id FROM instances WHERE instances.uuid = ?), (SELECT batches.id FROM batches WHERE batches.name = ?), ?, ?, ?, ?, ?, ?, (SELECT migration_windows.id FROM migration_windows JOIN batches ON migration_windows.batch_id = batches.id WHERE migration_windows.name = ? AND batches.id = batch_id), ?, ?, ?, ?, ?, ?)
 `)
 
 var queueEntryUpdate = RegisterStmt(`
 UPDATE queue
-  SET instance_id = (SELECT instances.id FROM instances WHERE instances.uuid = ?), batch_id = (SELECT batches.id FROM batches WHERE batches.name = ?), secret_token = ?, import_stage = ?, migration_status = ?, migration_status_message = ?, last_worker_status = ?, last_background_sync = ?, migration_window_id = (SELECT migration_windows.id FROM migration_windows JOIN batches ON migration_windows.batch_id = batches.id WHERE migration_windows.name = ? AND batches.id = batch_id), placement = ?
+  SET instance_id = (SELECT instances.id FROM instances WHERE instances.uuid = ?), batch_id = (SELECT batches.id FROM batches WHERE batches.name = ?), secret_token = ?, import_stage = ?, migration_status = ?, migration_status_message = ?, last_worker_status = ?, last_background_sync = ?, migration_window_id = (SELECT migration_windows.id FROM migration_windows JOIN batches ON migration_windows.batch_id = batches.id WHERE migration_windows.name = ? AND batches.id = batch_id), placement = ?, transfer_progress = ?, script_results = ?, change_ids = ?, verification_results = ?, boot_status = ?
  WHERE id = ?
 `)
 
@@ -171,7 +171,7 @@ func GetQueueEntry(ctx context.Context, db dbtx, instanceUUID uuid.UUID) (_ *mig
 // queueEntryColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the QueueEntry entity.
 func queueEntryColumns() string {
-	return "queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement"
+	return "queue.id, instances.uuid AS instance_uuid, batches.name AS batch_name, queue.secret_token, queue.import_stage, queue.migration_status, queue.migration_status_message, queue.last_worker_status, queue.last_background_sync, migration_windows.name AS migration_window_name, queue.placement, queue.transfer_progress, queue.script_results, queue.change_ids, queue.verification_results, queue.boot_status"
 }
 
 // getQueueEntries can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -181,7 +181,12 @@ func getQueueEntries(ctx context.Context, stmt *sql.Stmt, args ...any) ([]migrat
 	dest := func(scan func(dest ...any) error) error {
 		q := migration.QueueEntry{}
 		var placementStr string
-		err := scan(&q.ID, &q.InstanceUUID, &q.BatchName, &q.SecretToken, &q.ImportStage, &q.MigrationStatus, &q.MigrationStatusMessage, &q.LastWorkerStatus, &q.LastBackgroundSync, &q.MigrationWindowName, &placementStr)
+		var transferProgressStr string
+		var scriptResultsStr string
+		var changeIDsStr string
+		var verificationResultsStr string
+		var bootStatusStr string
+		err := scan(&q.ID, &q.InstanceUUID, &q.BatchName, &q.SecretToken, &q.ImportStage, &q.MigrationStatus, &q.MigrationStatusMessage, &q.LastWorkerStatus, &q.LastBackgroundSync, &q.MigrationWindowName, &placementStr, &transferProgressStr, &scriptResultsStr, &changeIDsStr, &verificationResultsStr, &bootStatusStr)
 		if err != nil {
 			return err
 		}
@@ -191,6 +196,31 @@ func getQueueEntries(ctx context.Context, stmt *sql.Stmt, args ...any) ([]migrat
 			return err
 		}
 
+		err = unmarshalJSON(transferProgressStr, &q.TransferProgress)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(scriptResultsStr, &q.ScriptResults)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(changeIDsStr, &q.ChangeIDs)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(verificationResultsStr, &q.VerificationResults)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(bootStatusStr, &q.BootStatus)
+		if err != nil {
+			return err
+		}
+
 		objects = append(objects, q)
 
 		return nil
@@ -211,7 +241,12 @@ func getQueueEntriesRaw(ctx context.Context, db dbtx, sql string, args ...any) (
 	dest := func(scan func(dest ...any) error) error {
 		q := migration.QueueEntry{}
 		var placementStr string
-		err := scan(&q.ID, &q.InstanceUUID, &q.BatchName, &q.SecretToken, &q.ImportStage, &q.MigrationStatus, &q.MigrationStatusMessage, &q.LastWorkerStatus, &q.LastBackgroundSync, &q.MigrationWindowName, &placementStr)
+		var transferProgressStr string
+		var scriptResultsStr string
+		var changeIDsStr string
+		var verificationResultsStr string
+		var bootStatusStr string
+		err := scan(&q.ID, &q.InstanceUUID, &q.BatchName, &q.SecretToken, &q.ImportStage, &q.MigrationStatus, &q.MigrationStatusMessage, &q.LastWorkerStatus, &q.LastBackgroundSync, &q.MigrationWindowName, &placementStr, &transferProgressStr, &scriptResultsStr, &changeIDsStr, &verificationResultsStr, &bootStatusStr)
 		if err != nil {
 			return err
 		}
@@ -221,6 +256,31 @@ func getQueueEntriesRaw(ctx context.Context, db dbtx, sql string, args ...any) (
 			return err
 		}
 
+		err = unmarshalJSON(transferProgressStr, &q.TransferProgress)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(scriptResultsStr, &q.ScriptResults)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(changeIDsStr, &q.ChangeIDs)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(verificationResultsStr, &q.VerificationResults)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(bootStatusStr, &q.BootStatus)
+		if err != nil {
+			return err
+		}
+
 		objects = append(objects, q)
 
 		return nil
@@ -456,7 +516,7 @@ func CreateQueueEntry(ctx context.Context, db dbtx, object migration.QueueEntry)
 		_err = mapErr(_err, "Queue_entry")
 	}()
 
-	args := make([]any, 10)
+	args := make([]any, 15)
 
 	// Populate the statement arguments.
 	args[0] = object.InstanceUUID
@@ -474,6 +534,36 @@ func CreateQueueEntry(ctx context.Context, db dbtx, object migration.QueueEntry)
 	}
 
 	args[9] = marshaledPlacement
+	marshaledTransferProgress, err := marshalJSON(object.TransferProgress)
+	if err != nil {
+		return -1, err
+	}
+
+	args[10] = marshaledTransferProgress
+	marshaledScriptResults, err := marshalJSON(object.ScriptResults)
+	if err != nil {
+		return -1, err
+	}
+
+	args[11] = marshaledScriptResults
+	marshaledChangeIDs, err := marshalJSON(object.ChangeIDs)
+	if err != nil {
+		return -1, err
+	}
+
+	args[12] = marshaledChangeIDs
+	marshaledVerificationResults, err := marshalJSON(object.VerificationResults)
+	if err != nil {
+		return -1, err
+	}
+
+	args[13] = marshaledVerificationResults
+	marshaledBootStatus, err := marshalJSON(object.BootStatus)
+	if err != nil {
+		return -1, err
+	}
+
+	args[14] = marshaledBootStatus
 
 	// Prepared statement to use.
 	stmt, err := Stmt(db, queueEntryCreate)
@@ -521,7 +611,32 @@ func UpdateQueueEntry(ctx context.Context, db tx, instanceUUID uuid.UUID, object
 		return err
 	}
 
-	result, err := stmt.Exec(object.InstanceUUID, object.BatchName, object.SecretToken, object.ImportStage, object.MigrationStatus, object.MigrationStatusMessage, object.LastWorkerStatus, object.LastBackgroundSync, object.MigrationWindowName, marshaledPlacement, id)
+	marshaledTransferProgress, err := marshalJSON(object.TransferProgress)
+	if err != nil {
+		return err
+	}
+
+	marshaledScriptResults, err := marshalJSON(object.ScriptResults)
+	if err != nil {
+		return err
+	}
+
+	marshaledChangeIDs, err := marshalJSON(object.ChangeIDs)
+	if err != nil {
+		return err
+	}
+
+	marshaledVerificationResults, err := marshalJSON(object.VerificationResults)
+	if err != nil {
+		return err
+	}
+
+	marshaledBootStatus, err := marshalJSON(object.BootStatus)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.Exec(object.InstanceUUID, object.BatchName, object.SecretToken, object.ImportStage, object.MigrationStatus, object.MigrationStatusMessage, object.LastWorkerStatus, object.LastBackgroundSync, object.MigrationWindowName, marshaledPlacement, marshaledTransferProgress, marshaledScriptResults, marshaledChangeIDs, marshaledVerificationResults, marshaledBootStatus, id)
 	if err != nil {
 		return fmt.Errorf("Update \"queue\" entry failed: %w", err)
 	}
@@ -13,27 +13,27 @@ import (
 )
 
 var sourceObjects = RegisterStmt(`
-SELECT sources.id, sources.name, sources.source_type, sources.properties
+SELECT sources.id, sources.name, sources.source_type, sources.properties, sources.sync_status
   FROM sources
   ORDER BY sources.name
 `)
 
 var sourceObjectsBySourceType = RegisterStmt(`
-SELECT sources.id, sources.name, sources.source_type, sources.properties
+SELECT sources.id, sources.name, sources.source_type, sources.properties, sources.sync_status
   FROM sources
   WHERE ( sources.source_type = ? )
   ORDER BY sources.name
 `)
 
 var sourceObjectsByName = RegisterStmt(`
-SELECT sources.id, sources.name, sources.source_type, sources.properties
+SELECT sources.id, sources.name, sources.source_type, sources.properties, sources.sync_status
   FROM sources
   WHERE ( sources.name = ? )
   ORDER BY sources.name
 `)
 
 var sourceObjectsByNameAndSourceType = RegisterStmt(`
-SELECT sources.id, sources.name, sources.source_type, sources.properties
+SELECT sources.id, sources.name, sources.source_type, sources.properties, sources.sync_status
   FROM sources
   WHERE ( sources.name = ? AND sources.source_type = ? )
   ORDER BY sources.name
@@ -51,13 +51,13 @@ SELECT sources.id FROM sources
 `)
 
 var sourceCreate = RegisterStmt(`
-INSERT INTO sources (name, source_type, properties)
-  VALUES (?, ?, ?)
+INSERT INTO sources (name, source_type, properties, sync_status)
+  VALUES (?, ?, ?, ?)
 `)
 
 var sourceUpdate = RegisterStmt(`
 UPDATE sources
-  SET name = ?, source_type = ?, properties = ?
+  SET name = ?, source_type = ?, properties = ?, sync_status = ?
  WHERE id = ?
 `)
 
@@ -149,7 +149,7 @@ func GetSource(ctx context.Context, db dbtx, name string) (_ *migration.Source,
 // sourceColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the Source entity.
 func sourceColumns() string {
-	return "sources.id, sources.name, sources.source_type, sources.properties"
+	return "sources.id, sources.name, sources.source_type, sources.properties, sources.sync_status"
 }
 
 // getSources can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -158,7 +158,13 @@ func getSources(ctx context.Context, stmt *sql.Stmt, args ...any) ([]migration.S
 
 	dest := func(scan func(dest ...any) error) error {
 		s := migration.Source{}
-		err := scan(&s.ID, &s.Name, &s.SourceType, &s.Properties)
+		var syncStatusStr string
+		err := scan(&s.ID, &s.Name, &s.SourceType, &s.Properties, &syncStatusStr)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(syncStatusStr, &s.SyncStatus)
 		if err != nil {
 			return err
 		}
@@ -182,7 +188,13 @@ func getSourcesRaw(ctx context.Context, db dbtx, sql string, args ...any) ([]mig
 
 	dest := func(scan func(dest ...any) error) error {
 		s := migration.Source{}
-		err := scan(&s.ID, &s.Name, &s.SourceType, &s.Properties)
+		var syncStatusStr string
+		err := scan(&s.ID, &s.Name, &s.SourceType, &s.Properties, &syncStatusStr)
+		if err != nil {
+			return err
+		}
+
+		err = unmarshalJSON(syncStatusStr, &s.SyncStatus)
 		if err != nil {
 			return err
 		}
@@ -390,12 +402,18 @@ func CreateSource(ctx context.Context, db dbtx, object migration.Source) (_ int6
 		_err = mapErr(_err, "Source")
 	}()
 
-	args := make([]any, 3)
+	args := make([]any, 4)
 
 	// Populate the statement arguments.
 	args[0] = object.Name
 	args[1] = object.SourceType
 	args[2] = object.Properties
+	marshaledSyncStatus, err := marshalJSON(object.SyncStatus)
+	if err != nil {
+		return -1, err
+	}
+
+	args[3] = marshaledSyncStatus
 
 	// Prepared statement to use.
 	stmt, err := Stmt(db, sourceCreate)
@@ -438,7 +456,12 @@ func UpdateSource(ctx context.Context, db tx, name string, object migration.Sour
 		return fmt.Errorf("Failed to get \"sourceUpdate\" prepared statement: %w", err)
 	}
 
-	result, err := stmt.Exec(object.Name, object.SourceType, object.Properties, id)
+	marshaledSyncStatus, err := marshalJSON(object.SyncStatus)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.Exec(object.Name, object.SourceType, object.Properties, marshaledSyncStatus, id)
 	if err != nil {
 		return fmt.Errorf("Update \"sources\" entry failed: %w", err)
 	}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"slices"
 	"time"
 
+	"github.com/adhocore/gronx"
 	"github.com/lxc/incus/v6/shared/validate"
 
 	internalapi "github.com/FuturFusion/migration-manager/internal/api"
@@ -21,6 +23,9 @@ type Source struct {
 
 	Properties json.RawMessage
 
+	// SyncStatus is excluded from the ETag, so background syncs do not invalidate it.
+	SyncStatus api.SourceSyncStatus `json:"-" db:"marshal=json"`
+
 	EndpointFunc func(api.Source) (SourceEndpoint, error) `json:"-" db:"ignore"`
 }
 
@@ -34,7 +39,7 @@ func (s Source) Validate() error {
 		return NewValidationErrf("Invalid source, name %q: %v", s.Name, err)
 	}
 
-	if s.SourceType != api.SOURCETYPE_VMWARE && s.SourceType != api.SOURCETYPE_NSX {
+	if !slices.Contains([]api.SourceType{api.SOURCETYPE_VMWARE, api.SOURCETYPE_NSX, api.SOURCETYPE_OVF, api.SOURCETYPE_VMWARE_DUMP}, s.SourceType) {
 		return NewValidationErrf("Invalid source, %s is not a valid source type", s.SourceType)
 	}
 
@@ -45,6 +50,10 @@ func (s Source) Validate() error {
 	switch s.SourceType {
 	case api.SOURCETYPE_VMWARE:
 		err = s.validateSourceTypeVMware()
+	case api.SOURCETYPE_OVF:
+		err = s.validateSourceTypeOVF()
+	case api.SOURCETYPE_VMWARE_DUMP:
+		err = s.validateSourceTypeVMwareDump()
 	}
 
 	if err != nil {
@@ -74,6 +83,167 @@ func (s *Source) GetVMwareProperties() (*api.VMwareProperties, error) {
 	return &props, nil
 }
 
+// GetOVFProperties sets default values for missing fields, and returns the properties object for an OVF source.
+func (s *Source) GetOVFProperties() (*api.OVFProperties, error) {
+	if s.SourceType != api.SOURCETYPE_OVF {
+		return nil, fmt.Errorf("Source %q type is %q, not %q", s.Name, s.SourceType, api.SOURCETYPE_OVF)
+	}
+
+	err := s.SetDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	var props api.OVFProperties
+	err = json.Unmarshal(s.Properties, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	return &props, nil
+}
+
+// GetVMwareDumpProperties sets default values for missing fields, and returns the properties object for a VMware dump source.
+func (s *Source) GetVMwareDumpProperties() (*api.VMwareDumpProperties, error) {
+	if s.SourceType != api.SOURCETYPE_VMWARE_DUMP {
+		return nil, fmt.Errorf("Source %q type is %q, not %q", s.Name, s.SourceType, api.SOURCETYPE_VMWARE_DUMP)
+	}
+
+	err := s.SetDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	var props api.VMwareDumpProperties
+	err = json.Unmarshal(s.Properties, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	return &props, nil
+}
+
+// GetConnectionTimeout returns the timeout for connecting to and fetching data from a VM source.
+func (s *Source) GetConnectionTimeout() (time.Duration, error) {
+	switch s.SourceType {
+	case api.SOURCETYPE_VMWARE:
+		props, err := s.GetVMwareProperties()
+		if err != nil {
+			return 0, err
+		}
+
+		return props.ConnectionTimeout.Duration, nil
+	case api.SOURCETYPE_OVF:
+		props, err := s.GetOVFProperties()
+		if err != nil {
+			return 0, err
+		}
+
+		return props.ConnectionTimeout.Duration, nil
+	case api.SOURCETYPE_VMWARE_DUMP:
+		props, err := s.GetVMwareDumpProperties()
+		if err != nil {
+			return 0, err
+		}
+
+		return props.ConnectionTimeout.Duration, nil
+	default:
+		return 0, fmt.Errorf("Source %q type %q does not manage VMs", s.Name, s.SourceType)
+	}
+}
+
+// GetSyncSchedule returns the background sync schedule of a VM source, or nil if the source uses the global sync interval.
+func (s *Source) GetSyncSchedule() (*api.SourceSyncSchedule, error) {
+	switch s.SourceType {
+	case api.SOURCETYPE_VMWARE:
+		props, err := s.GetVMwareProperties()
+		if err != nil {
+			return nil, err
+		}
+
+		return props.SyncSchedule, nil
+	case api.SOURCETYPE_OVF:
+		props, err := s.GetOVFProperties()
+		if err != nil {
+			return nil, err
+		}
+
+		return props.SyncSchedule, nil
+	case api.SOURCETYPE_VMWARE_DUMP:
+		props, err := s.GetVMwareDumpProperties()
+		if err != nil {
+			return nil, err
+		}
+
+		return props.SyncSchedule, nil
+	default:
+		return nil, fmt.Errorf("Source %q type %q does not manage VMs", s.Name, s.SourceType)
+	}
+}
+
+// NextSync returns the time of the next background sync of the source, given the start of its last sync.
+// Sources without their own interval or cron expression are synced at the given default interval.
+// Sources that have never been synced are due immediately.
+func (s *Source) NextSync(lastSync time.Time, defaultInterval time.Duration) (time.Time, error) {
+	schedule, err := s.GetSyncSchedule()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if lastSync.IsZero() {
+		return time.Now().UTC(), nil
+	}
+
+	if schedule != nil && schedule.Cron != "" {
+		next, err := gronx.NextTickAfter(schedule.Cron, lastSync, false)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Failed to determine next sync for source %q: %w", s.Name, err)
+		}
+
+		return next.UTC(), nil
+	}
+
+	interval := defaultInterval
+	if schedule != nil && schedule.Interval.Duration > 0 {
+		interval = schedule.Interval.Duration
+	}
+
+	return lastSync.Add(interval).UTC(), nil
+}
+
+// InSyncBlackout returns whether background syncs of the source are suppressed at the given time.
+func (s *Source) InSyncBlackout(now time.Time) (bool, error) {
+	schedule, err := s.GetSyncSchedule()
+	if err != nil {
+		return false, err
+	}
+
+	if schedule == nil {
+		return false, nil
+	}
+
+	for _, b := range schedule.Blackouts {
+		if b.Cron == "" {
+			if !now.Before(b.Start) && now.Before(b.End) {
+				return true, nil
+			}
+
+			continue
+		}
+
+		start, err := gronx.PrevTickBefore(b.Cron, now, true)
+		if err != nil {
+			return false, fmt.Errorf("Failed to determine sync blackout for source %q: %w", s.Name, err)
+		}
+
+		if now.Before(start.Add(b.Duration.Duration)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // GetNSXProperties sets default values for missing fields, and returns the properties object for a NSX source.
 func (s *Source) GetNSXProperties() (*internalapi.NSXSourceProperties, error) {
 	if s.SourceType != api.SOURCETYPE_NSX {
@@ -128,12 +298,96 @@ func (s *Source) SetDefaults() error {
 			return NewValidationErrf("%v", err)
 		}
 
+		return nil
+	case api.SOURCETYPE_OVF:
+		var properties api.OVFProperties
+
+		err := json.Unmarshal(s.Properties, &properties)
+		if err != nil {
+			return NewValidationErrf("Invalid properties for %s source type: %v", s.SourceType, err)
+		}
+
+		properties.SetDefaults()
+
+		s.Properties, err = json.Marshal(properties)
+		if err != nil {
+			return NewValidationErrf("%v", err)
+		}
+
+		return nil
+	case api.SOURCETYPE_VMWARE_DUMP:
+		var properties api.VMwareDumpProperties
+
+		err := json.Unmarshal(s.Properties, &properties)
+		if err != nil {
+			return NewValidationErrf("Invalid properties for %s source type: %v", s.SourceType, err)
+		}
+
+		properties.SetDefaults()
+
+		s.Properties, err = json.Marshal(properties)
+		if err != nil {
+			return NewValidationErrf("%v", err)
+		}
+
 		return nil
 	default:
 		return nil
 	}
 }
 
+func (s Source) validateSourceTypeOVF() error {
+	var properties api.OVFProperties
+
+	err := json.Unmarshal(s.Properties, &properties)
+	if err != nil {
+		return NewValidationErrf("Invalid properties for OVF type: %v", err)
+	}
+
+	if !filepath.IsAbs(properties.Path) {
+		return NewValidationErrf("Invalid source, path %q must be an absolute path for source type OVF", properties.Path)
+	}
+
+	if properties.ConnectionTimeout.Duration <= time.Duration(0) {
+		return NewValidationErrf("Invalid source, connection timeout %q is not a valid duration", properties.ConnectionTimeout)
+	}
+
+	if properties.ImportLimit < 0 {
+		return NewValidationErrf("Invalid source, import limit must not be negative")
+	}
+
+	err = validateSyncSchedule(properties.SyncSchedule)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Source) validateSourceTypeVMwareDump() error {
+	var properties api.VMwareDumpProperties
+
+	err := json.Unmarshal(s.Properties, &properties)
+	if err != nil {
+		return NewValidationErrf("Invalid properties for VMware dump type: %v", err)
+	}
+
+	if !filepath.IsAbs(properties.Path) {
+		return NewValidationErrf("Invalid source, path %q must be an absolute path for source type VMware dump", properties.Path)
+	}
+
+	if properties.ConnectionTimeout.Duration <= time.Duration(0) {
+		return NewValidationErrf("Invalid source, connection timeout %q is not a valid duration", properties.ConnectionTimeout)
+	}
+
+	err = validateSyncSchedule(properties.SyncSchedule)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s Source) validateSourceTypeVMware() error {
 	var properties api.VMwareProperties
 
@@ -171,6 +425,56 @@ func (s Source) validateSourceTypeVMware() error {
 		return NewValidationErrf("Invalid source, specified datacenter must not be empty")
 	}
 
+	err = validateSyncSchedule(properties.SyncSchedule)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateSyncSchedule validates the optional background sync schedule of a VM source.
+func validateSyncSchedule(schedule *api.SourceSyncSchedule) error {
+	if schedule == nil {
+		return nil
+	}
+
+	if schedule.Interval.Duration < 0 {
+		return NewValidationErrf("Invalid source, sync interval %q must not be negative", schedule.Interval)
+	}
+
+	if schedule.Interval.Duration > 0 && schedule.Cron != "" {
+		return NewValidationErrf("Invalid source, sync interval and sync cron expression are mutually exclusive")
+	}
+
+	if schedule.Cron != "" && !gronx.IsValid(schedule.Cron) {
+		return NewValidationErrf("Invalid source, sync cron expression %q is not valid", schedule.Cron)
+	}
+
+	for _, b := range schedule.Blackouts {
+		recurring := b.Cron != "" || b.Duration.Duration != 0
+		fixed := !b.Start.IsZero() || !b.End.IsZero()
+		if recurring == fixed {
+			return NewValidationErrf("Invalid source, sync blackout must define either a cron expression and duration, or a start and end time")
+		}
+
+		if recurring {
+			if !gronx.IsValid(b.Cron) {
+				return NewValidationErrf("Invalid source, sync blackout cron expression %q is not valid", b.Cron)
+			}
+
+			if b.Duration.Duration <= 0 {
+				return NewValidationErrf("Invalid source, sync blackout duration %q must be greater than 0", b.Duration)
+			}
+
+			continue
+		}
+
+		if b.Start.IsZero() || b.End.IsZero() || !b.End.After(b.Start) {
+			return NewValidationErrf("Invalid source, sync blackout end %q must be after start %q", b.End, b.Start)
+		}
+	}
+
 	return nil
 }
 
@@ -185,6 +489,22 @@ func (s Source) GetExternalConnectivityStatus() api.ExternalConnectivityStatus {
 			return api.EXTERNALCONNECTIVITYSTATUS_UNKNOWN
 		}
 
+		return properties.ConnectivityStatus
+	case api.SOURCETYPE_OVF:
+		var properties api.OVFProperties
+		err := json.Unmarshal(s.Properties, &properties)
+		if err != nil {
+			return api.EXTERNALCONNECTIVITYSTATUS_UNKNOWN
+		}
+
+		return properties.ConnectivityStatus
+	case api.SOURCETYPE_VMWARE_DUMP:
+		var properties api.VMwareDumpProperties
+		err := json.Unmarshal(s.Properties, &properties)
+		if err != nil {
+			return api.EXTERNALCONNECTIVITYSTATUS_UNKNOWN
+		}
+
 		return properties.ConnectivityStatus
 	default:
 		return api.EXTERNALCONNECTIVITYSTATUS_UNKNOWN
@@ -241,6 +561,24 @@ func (s *Source) SetExternalConnectivityStatus(status api.ExternalConnectivitySt
 			return
 		}
 
+		properties.ConnectivityStatus = status
+		s.Properties, _ = json.Marshal(properties)
+	case api.SOURCETYPE_OVF:
+		var properties api.OVFProperties
+		err := json.Unmarshal(s.Properties, &properties)
+		if err != nil {
+			return
+		}
+
+		properties.ConnectivityStatus = status
+		s.Properties, _ = json.Marshal(properties)
+	case api.SOURCETYPE_VMWARE_DUMP:
+		var properties api.VMwareDumpProperties
+		err := json.Unmarshal(s.Properties, &properties)
+		if err != nil {
+			return
+		}
+
 		properties.ConnectivityStatus = status
 		s.Properties, _ = json.Marshal(properties)
 	}
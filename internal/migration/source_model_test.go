@@ -0,0 +1,127 @@
+package migration_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestSource_NextSync(t *testing.T) {
+	lastSync := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name       string
+		sourceType api.SourceType
+		properties string
+		lastSync   time.Time
+
+		wantNext time.Time
+	}{
+		{
+			name:       "global interval",
+			sourceType: api.SOURCETYPE_VMWARE,
+			properties: `{}`,
+			lastSync:   lastSync,
+
+			wantNext: lastSync.Add(10 * time.Minute),
+		},
+		{
+			name:       "source interval",
+			sourceType: api.SOURCETYPE_OVF,
+			properties: `{"sync_schedule":{"interval":"1h"}}`,
+			lastSync:   lastSync,
+
+			wantNext: lastSync.Add(time.Hour),
+		},
+		{
+			name:       "source cron",
+			sourceType: api.SOURCETYPE_VMWARE_DUMP,
+			properties: `{"sync_schedule":{"cron":"0 */4 * * *"}}`,
+			lastSync:   lastSync,
+
+			wantNext: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		src := migration.Source{Name: "src", SourceType: tc.sourceType, Properties: json.RawMessage(tc.properties)}
+		next, err := src.NextSync(tc.lastSync, 10*time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, tc.wantNext, next)
+	}
+
+	// A source that has never been synced is due immediately.
+	src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(`{"sync_schedule":{"interval":"1h"}}`)}
+	next, err := src.NextSync(time.Time{}, 10*time.Minute)
+	require.NoError(t, err)
+	require.False(t, next.After(time.Now().UTC()))
+
+	// Network sources have no sync schedule.
+	src = migration.Source{Name: "src", SourceType: api.SOURCETYPE_NSX, Properties: json.RawMessage(`{}`)}
+	_, err = src.NextSync(lastSync, 10*time.Minute)
+	require.Error(t, err)
+}
+
+func TestSource_InSyncBlackout(t *testing.T) {
+	properties := `{"sync_schedule":{"blackouts":[{"cron":"0 22 * * 5","duration":"8h"},{"start":"2025-12-22T00:00:00Z","end":"2025-12-24T00:00:00Z"}]}}`
+
+	cases := []struct {
+		name string
+		now  time.Time
+
+		wantBlackout bool
+	}{
+		{
+			name: "outside of blackouts",
+			now:  time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+
+			wantBlackout: false,
+		},
+		{
+			name: "start of recurring blackout",
+			now:  time.Date(2025, 1, 3, 22, 0, 0, 0, time.UTC),
+
+			wantBlackout: true,
+		},
+		{
+			name: "within recurring blackout",
+			now:  time.Date(2025, 1, 4, 5, 59, 0, 0, time.UTC),
+
+			wantBlackout: true,
+		},
+		{
+			name: "after recurring blackout",
+			now:  time.Date(2025, 1, 4, 6, 0, 0, 0, time.UTC),
+
+			wantBlackout: false,
+		},
+		{
+			name: "within fixed blackout",
+			now:  time.Date(2025, 12, 23, 12, 0, 0, 0, time.UTC),
+
+			wantBlackout: true,
+		},
+		{
+			name: "end of fixed blackout",
+			now:  time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+
+			wantBlackout: false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		src := migration.Source{Name: "src", SourceType: api.SOURCETYPE_VMWARE, Properties: json.RawMessage(properties)}
+		blackout, err := src.InSyncBlackout(tc.now)
+		require.NoError(t, err)
+		require.Equal(t, tc.wantBlackout, blackout)
+	}
+}
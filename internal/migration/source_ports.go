@@ -15,6 +15,7 @@ type SourceService interface {
 	GetAllNames(ctx context.Context, sourceTypes ...api.SourceType) ([]string, error)
 	GetByName(ctx context.Context, name string) (*Source, error)
 	Update(ctx context.Context, name string, source *Source, instanceService InstanceService) error
+	UpdateSyncStatus(ctx context.Context, name string, status api.SourceSyncStatus) error
 	DeleteByName(ctx context.Context, name string, instanceService InstanceService) error
 
 	InitImportCache(initial map[string]int) error
@@ -122,6 +122,21 @@ func (s sourceService) Update(ctx context.Context, name string, newSource *Sourc
 	})
 }
 
+// UpdateSyncStatus records the result of the last sync of the source with the given name.
+// Unlike Update, the source is neither validated nor checked for connectivity.
+func (s sourceService) UpdateSyncStatus(ctx context.Context, name string, status api.SourceSyncStatus) error {
+	return transaction.Do(ctx, func(ctx context.Context) error {
+		src, err := s.repo.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		src.SyncStatus = status
+
+		return s.repo.Update(ctx, name, *src)
+	})
+}
+
 func (s sourceService) DeleteByName(ctx context.Context, name string, instanceService InstanceService) error {
 	if name == "" {
 		return fmt.Errorf("Source name cannot be empty: %w", ErrOperationNotPermitted)
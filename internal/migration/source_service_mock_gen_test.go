@@ -51,6 +51,9 @@ var _ migration.SourceService = &SourceServiceMock{}
 //			UpdateFunc: func(ctx context.Context, name string, source *migration.Source, instanceService migration.InstanceService) error {
 //				panic("mock out the Update method")
 //			},
+//			UpdateSyncStatusFunc: func(ctx context.Context, name string, status api.SourceSyncStatus) error {
+//				panic("mock out the UpdateSyncStatus method")
+//			},
 //		}
 //
 //		// use mockedSourceService in code that requires migration.SourceService
@@ -88,6 +91,9 @@ type SourceServiceMock struct {
 	// UpdateFunc mocks the Update method.
 	UpdateFunc func(ctx context.Context, name string, source *migration.Source, instanceService migration.InstanceService) error
 
+	// UpdateSyncStatusFunc mocks the UpdateSyncStatus method.
+	UpdateSyncStatusFunc func(ctx context.Context, name string, status api.SourceSyncStatus) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Create holds details about calls to the Create method.
@@ -158,6 +164,15 @@ type SourceServiceMock struct {
 			// InstanceService is the instanceService argument value.
 			InstanceService migration.InstanceService
 		}
+		// UpdateSyncStatus holds details about calls to the UpdateSyncStatus method.
+		UpdateSyncStatus []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Name is the name argument value.
+			Name string
+			// Status is the status argument value.
+			Status api.SourceSyncStatus
+		}
 	}
 	lockCreate             sync.RWMutex
 	lockDeleteByName       sync.RWMutex
@@ -169,6 +184,7 @@ type SourceServiceMock struct {
 	lockRecordActiveImport sync.RWMutex
 	lockRemoveActiveImport sync.RWMutex
 	lockUpdate             sync.RWMutex
+	lockUpdateSyncStatus   sync.RWMutex
 }
 
 // Create calls CreateFunc.
@@ -526,3 +542,43 @@ func (mock *SourceServiceMock) UpdateCalls() []struct {
 	mock.lockUpdate.RUnlock()
 	return calls
 }
+
+// UpdateSyncStatus calls UpdateSyncStatusFunc.
+func (mock *SourceServiceMock) UpdateSyncStatus(ctx context.Context, name string, status api.SourceSyncStatus) error {
+	if mock.UpdateSyncStatusFunc == nil {
+		panic("SourceServiceMock.UpdateSyncStatusFunc: method is nil but SourceService.UpdateSyncStatus was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Name   string
+		Status api.SourceSyncStatus
+	}{
+		Ctx:    ctx,
+		Name:   name,
+		Status: status,
+	}
+	mock.lockUpdateSyncStatus.Lock()
+	mock.calls.UpdateSyncStatus = append(mock.calls.UpdateSyncStatus, callInfo)
+	mock.lockUpdateSyncStatus.Unlock()
+	return mock.UpdateSyncStatusFunc(ctx, name, status)
+}
+
+// UpdateSyncStatusCalls gets all the calls that were made to UpdateSyncStatus.
+// Check the length with:
+//
+//	len(mockedSourceService.UpdateSyncStatusCalls())
+func (mock *SourceServiceMock) UpdateSyncStatusCalls() []struct {
+	Ctx    context.Context
+	Name   string
+	Status api.SourceSyncStatus
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Name   string
+		Status api.SourceSyncStatus
+	}
+	mock.lockUpdateSyncStatus.RLock()
+	calls = mock.calls.UpdateSyncStatus
+	mock.lockUpdateSyncStatus.RUnlock()
+	return calls
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -70,6 +71,48 @@ func TestSourceService_Create(t *testing.T) {
 
 			assertErr: require.NoError,
 		},
+		{
+			name: "success - OVF",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_OVF,
+				Properties: json.RawMessage(`{
+  "path": "/srv/exports/",
+	"connectivity_status": "OK"
+}
+`),
+			},
+			repoCreateSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_OVF,
+				Properties: json.RawMessage(`{"path":"/srv/exports","connectivity_status":"OK","connection_timeout":"10m0s"}`),
+			},
+
+			assertErr: require.NoError,
+		},
+		{
+			name: "success - VMware dump",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE_DUMP,
+				Properties: json.RawMessage(`{
+  "path": "/srv/dump/",
+	"connectivity_status": "OK"
+}
+`),
+			},
+			repoCreateSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE_DUMP,
+				Properties: json.RawMessage(`{"path":"/srv/dump","connectivity_status":"OK","connection_timeout":"10m0s"}`),
+			},
+
+			assertErr: require.NoError,
+		},
 		{
 			name: "success - VMware with non-defaults",
 			source: migration.Source{
@@ -97,6 +140,30 @@ func TestSourceService_Create(t *testing.T) {
 
 			assertErr: require.NoError,
 		},
+		{
+			name: "success - VMware with sync schedule",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: json.RawMessage(`{
+  "endpoint": "endpoint.url",
+  "username": "user",
+  "password": "pass",
+	"connectivity_status": "OK",
+	"sync_schedule": {"cron": "0 */4 * * *", "blackouts": [{"cron": "0 22 * * 5", "duration": "8h"}, {"start": "2025-12-24T00:00:00Z", "end": "2025-12-27T00:00:00Z"}]}
+}
+`),
+			},
+			repoCreateSource: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: json.RawMessage(`{"endpoint":"endpoint.url","username":"user","password":"pass","connectivity_status":"OK","connection_timeout":"10m0s","sync_timeout":"10s","sync_limit":1,"datacenters":["/..."],"sync_schedule":{"cron":"0 */4 * * *","blackouts":[{"cron":"0 22 * * 5","duration":"8h0m0s"},{"start":"2025-12-24T00:00:00Z","end":"2025-12-27T00:00:00Z"}]}}`),
+			},
+
+			assertErr: require.NoError,
+		},
 		{
 			name: "error - invalid id",
 			source: migration.Source{
@@ -224,6 +291,79 @@ func TestSourceService_Create(t *testing.T) {
 				require.ErrorAs(tt, err, &verr, a...)
 			},
 		},
+		{
+			name: "error - OVF relative path",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_OVF,
+				Properties: json.RawMessage(`{
+  "path": "srv/exports"
+}
+`),
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - VMware sync interval and cron",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: json.RawMessage(`{"endpoint":"endpoint.url","username":"user","password":"pass","sync_schedule":{"interval":"1h","cron":"0 * * * *"}}`),
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - VMware invalid sync cron",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: json.RawMessage(`{"endpoint":"endpoint.url","username":"user","password":"pass","sync_schedule":{"cron":"invalid"}}`),
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - OVF sync blackout without duration",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_OVF,
+				Properties: json.RawMessage(`{"path":"/srv/exports","sync_schedule":{"blackouts":[{"cron":"0 22 * * 5"}]}}`),
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
+		{
+			name: "error - VMware dump sync blackout end before start",
+			source: migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE_DUMP,
+				Properties: json.RawMessage(`{"path":"/srv/dump","sync_schedule":{"blackouts":[{"start":"2025-12-27T00:00:00Z","end":"2025-12-24T00:00:00Z"}]}}`),
+			},
+
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				var verr migration.ErrValidation
+				require.ErrorAs(tt, err, &verr, a...)
+			},
+		},
 		{
 			name: "error - repo",
 			source: migration.Source{
@@ -633,6 +773,80 @@ func TestSourceService_UpdateByID(t *testing.T) {
 	}
 }
 
+func TestSourceService_UpdateSyncStatus(t *testing.T) {
+	status := api.SourceSyncStatus{
+		LastSync:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastSyncDuration: api.AsDuration(time.Minute),
+		LastSyncError:    "failed",
+	}
+
+	tests := []struct {
+		name                string
+		repoGetByNameSource *migration.Source
+		repoGetByNameErr    error
+		repoUpdateErr       error
+
+		assertErr  require.ErrorAssertionFunc
+		wantUpdate bool
+	}{
+		{
+			name: "success",
+			repoGetByNameSource: &migration.Source{
+				ID:         1,
+				Name:       "one",
+				SourceType: api.SOURCETYPE_VMWARE,
+				Properties: []byte(`{}`),
+			},
+
+			assertErr:  require.NoError,
+			wantUpdate: true,
+		},
+		{
+			name:             "error - repo.GetByName",
+			repoGetByNameErr: boom.Error,
+
+			assertErr: boom.ErrorIs,
+		},
+		{
+			name: "error - repo.Update",
+			repoGetByNameSource: &migration.Source{
+				ID:   1,
+				Name: "one",
+			},
+			repoUpdateErr: boom.Error,
+
+			assertErr:  boom.ErrorIs,
+			wantUpdate: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			repo := &mock.SourceRepoMock{
+				GetByNameFunc: func(ctx context.Context, name string) (*migration.Source, error) {
+					return tc.repoGetByNameSource, tc.repoGetByNameErr
+				},
+				UpdateFunc: func(ctx context.Context, name string, in migration.Source) error {
+					require.Equal(t, "one", name)
+					require.Equal(t, status, in.SyncStatus)
+					require.Equal(t, tc.repoGetByNameSource.Properties, in.Properties)
+					return tc.repoUpdateErr
+				},
+			}
+
+			sourceSvc := migration.NewSourceService(repo)
+
+			// Run test
+			err := sourceSvc.UpdateSyncStatus(context.Background(), "one", status)
+
+			// Assert
+			tc.assertErr(t, err)
+			require.Equal(t, tc.wantUpdate, len(repo.UpdateCalls()) == 1)
+		})
+	}
+}
+
 func TestSourceService_DeleteByName(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -0,0 +1,318 @@
+package migration
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// icalEvent holds the properties of an iCalendar VEVENT relevant to migration windows.
+type icalEvent struct {
+	uid       string
+	summary   string
+	status    string
+	start     time.Time
+	end       time.Time
+	allDay    bool
+	duration  time.Duration
+	recurring bool
+	capacity  int
+	freeze    bool
+}
+
+// icalDurationRegexp matches an RFC 5545 duration value, such as P1W, P1D or PT4H30M.
+var icalDurationRegexp = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseICalendarWindows returns a migration window for each event in the iCalendar data that has not ended by the given time.
+// Freeze events, or all events if freeze is set, are instead returned as exclusions during which no migrations can take place.
+// An event is a freeze event if its CATEGORIES property includes FREEZE.
+// Names are derived from the event summary (or UID) and start time. Cancelled events are skipped, and recurring events are not supported.
+// The capacity of a window is taken from the X-MIGRATION-MANAGER-CAPACITY property of its event, and is left unset if the event has none.
+func ParseICalendarWindows(r io.Reader, now time.Time, freeze bool) (Windows, []api.MigrationWindowExclusion, error) {
+	lines, err := unfoldICalendarLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	windows := Windows{}
+	exclusions := []api.MigrationWindowExclusion{}
+	names := map[string]bool{}
+
+	// Track nested components so that properties of sub-components like VALARM are ignored.
+	var components []string
+	var event *icalEvent
+	for i, line := range lines {
+		name, params, value, err := parseICalendarLine(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid iCalendar line %d: %w", i+1, err)
+		}
+
+		switch name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(value))
+			if strings.EqualFold(value, "VEVENT") {
+				event = &icalEvent{freeze: freeze}
+			}
+
+			continue
+		case "END":
+			if len(components) == 0 || components[len(components)-1] != strings.ToUpper(value) {
+				return nil, nil, fmt.Errorf("Invalid iCalendar line %d: Unexpected end of component %q", i+1, value)
+			}
+
+			components = components[:len(components)-1]
+			if strings.EqualFold(value, "VEVENT") {
+				w, err := event.toWindow()
+				if err != nil {
+					return nil, nil, err
+				}
+
+				isFreeze := event.freeze
+				event = nil
+				if w == nil || !w.End.After(now) {
+					continue
+				}
+
+				if names[w.Name] {
+					return nil, nil, fmt.Errorf("Multiple events result in migration window %q", w.Name)
+				}
+
+				names[w.Name] = true
+				if isFreeze {
+					exclusions = append(exclusions, api.MigrationWindowExclusion{Name: w.Name, Start: w.Start, End: w.End})
+				} else {
+					windows = append(windows, *w)
+				}
+			}
+
+			continue
+		}
+
+		if event == nil || components[len(components)-1] != "VEVENT" {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.uid = value
+		case "SUMMARY":
+			event.summary = value
+		case "STATUS":
+			event.status = strings.ToUpper(value)
+		case "CATEGORIES":
+			for _, category := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(category), "FREEZE") {
+					event.freeze = true
+				}
+			}
+
+		case "RRULE", "RDATE":
+			event.recurring = true
+		case "DTSTART":
+			event.start, event.allDay, err = parseICalendarTime(params, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid iCalendar line %d: %w", i+1, err)
+			}
+
+		case "DTEND":
+			event.end, _, err = parseICalendarTime(params, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid iCalendar line %d: %w", i+1, err)
+			}
+
+		case "DURATION":
+			event.duration, err = parseICalendarDuration(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid iCalendar line %d: %w", i+1, err)
+			}
+
+		case "X-MIGRATION-MANAGER-CAPACITY":
+			event.capacity, err = strconv.Atoi(value)
+			if err != nil || event.capacity <= 0 {
+				return nil, nil, fmt.Errorf("Invalid iCalendar line %d: Capacity %q must be greater than 0", i+1, value)
+			}
+		}
+	}
+
+	if len(components) > 0 {
+		return nil, nil, fmt.Errorf("Invalid iCalendar data: Component %q is not terminated", components[len(components)-1])
+	}
+
+	return windows, exclusions, nil
+}
+
+// toWindow returns the migration window for the event, or nil if the event is cancelled.
+func (e icalEvent) toWindow() (*Window, error) {
+	label := e.summary
+	if label == "" {
+		label = e.uid
+	}
+
+	if e.status == "CANCELLED" {
+		return nil, nil
+	}
+
+	if e.recurring {
+		return nil, fmt.Errorf("Recurring event %q is not supported, use a migration window schedule instead", label)
+	}
+
+	if e.start.IsZero() {
+		return nil, fmt.Errorf("Event %q has no start time", label)
+	}
+
+	end := e.end
+	if end.IsZero() {
+		switch {
+		case e.duration > 0:
+			end = e.start.Add(e.duration)
+		case e.allDay:
+			end = e.start.AddDate(0, 0, 1)
+		default:
+			return nil, fmt.Errorf("Event %q has no end time or duration", label)
+		}
+	}
+
+	prefix := slug.Make(label)
+	if prefix == "" {
+		prefix = "window"
+	}
+
+	suffix := "-" + e.start.UTC().Format("20060102-1504")
+	prefix = strings.TrimRight(prefix[:min(len(prefix), 64-len(suffix))], "-")
+
+	return &Window{
+		Name:   prefix + suffix,
+		Start:  e.start.UTC(),
+		End:    end.UTC(),
+		Config: api.MigrationWindowConfig{Capacity: e.capacity},
+	}, nil
+}
+
+// unfoldICalendarLines returns the content lines of the iCalendar data, joining lines that were folded onto multiple lines.
+func unfoldICalendarLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read iCalendar data: %w", err)
+	}
+
+	return lines, nil
+}
+
+// parseICalendarLine splits a content line into its upper-cased property name, parameters, and value.
+func parseICalendarLine(line string) (string, map[string]string, string, error) {
+	// The value starts after the first colon that isn't part of a quoted parameter value.
+	quoted := false
+	sep := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+
+	if sep < 0 {
+		return "", nil, "", fmt.Errorf("Missing value separator")
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	value := line[sep+1:]
+	value = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+
+	return strings.ToUpper(parts[0]), params, value, nil
+}
+
+// parseICalendarTime parses a DATE or DATE-TIME value, and returns whether it is a DATE value.
+// Times without a UTC designator or TZID parameter are interpreted as UTC.
+func parseICalendarTime(params map[string]string, value string) (time.Time, bool, error) {
+	loc := time.UTC
+	if params["TZID"] != "" {
+		var err error
+		loc, err = time.LoadLocation(params["TZID"])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("Unknown timezone %q: %w", params["TZID"], err)
+		}
+	}
+
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("Invalid date %q: %w", value, err)
+		}
+
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("Invalid time %q: %w", value, err)
+		}
+
+		return t, false, nil
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("Invalid time %q: %w", value, err)
+	}
+
+	return t, false, nil
+}
+
+// parseICalendarDuration parses a positive RFC 5545 duration value.
+func parseICalendarDuration(value string) (time.Duration, error) {
+	match := icalDurationRegexp.FindStringSubmatch(value)
+	if match == nil || match[1] == "-" || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("Invalid duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("Invalid duration %q: %w", value, err)
+		}
+
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
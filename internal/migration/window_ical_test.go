@@ -0,0 +1,152 @@
+package migration_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestParseICalendarWindows(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	calendar := func(events ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//EN\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+	}
+
+	cases := []struct {
+		name     string
+		calendar string
+		freeze   bool
+
+		assertErr      require.ErrorAssertionFunc
+		wantWindows    migration.Windows
+		wantExclusions []api.MigrationWindowExclusion
+	}{
+		{
+			name: "success - start and end times",
+			calendar: calendar(
+				"BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:Change window\\, datacenter A\r\nDTSTART:20260310T220000Z\r\nDTEND:20260311T020000Z\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:2@example.com\r\nSUMMARY:Zurich\r\nDTSTART;TZID=Europe/Zurich:20260312T220000\r\nDTEND;TZID=Europe/Zurich:20260313T020000\r\nEND:VEVENT\r\n",
+			),
+
+			assertErr: require.NoError,
+			wantWindows: migration.Windows{
+				{Name: "change-window-datacenter-a-20260310-2200", Start: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC)},
+				{Name: "zurich-20260312-2100", Start: time.Date(2026, 3, 12, 21, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 13, 1, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "success - duration, all-day event, folded lines, and nested alarm",
+			calendar: calendar(
+				"BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:Long maintenance\r\n  window\r\nDTSTART:20260310T220000Z\r\nDURATION:PT4H30M\r\nBEGIN:VALARM\r\nTRIGGER:-PT15M\r\nDURATION:PT5M\r\nEND:VALARM\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:all-day@example.com\r\nDTSTART;VALUE=DATE:20260314\r\nEND:VEVENT\r\n",
+			),
+
+			assertErr: require.NoError,
+			wantWindows: migration.Windows{
+				{Name: "long-maintenance-window-20260310-2200", Start: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 11, 2, 30, 0, 0, time.UTC)},
+				{Name: "all-dayatexample-com-20260314-0000", Start: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "success - past and cancelled events are skipped",
+			calendar: calendar(
+				"BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:Past\r\nDTSTART:20260210T220000Z\r\nDTEND:20260211T020000Z\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:2@example.com\r\nSUMMARY:Cancelled\r\nSTATUS:CANCELLED\r\nDTSTART:20260310T220000Z\r\nDTEND:20260311T020000Z\r\nEND:VEVENT\r\n",
+			),
+
+			assertErr:   require.NoError,
+			wantWindows: migration.Windows{},
+		},
+		{
+			name:     "success - event capacity",
+			calendar: calendar("BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:Small\r\nDTSTART:20260310T220000Z\r\nDURATION:PT4H\r\nX-MIGRATION-MANAGER-CAPACITY:5\r\nEND:VEVENT\r\n"),
+
+			assertErr: require.NoError,
+			wantWindows: migration.Windows{
+				{Name: "small-20260310-2200", Start: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC), Config: api.MigrationWindowConfig{Capacity: 5}},
+			},
+		},
+		{
+			name: "success - freeze events",
+			calendar: calendar(
+				"BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:Change window\r\nDTSTART:20260310T220000Z\r\nDTEND:20260311T020000Z\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:2@example.com\r\nSUMMARY:Year-end freeze\r\nCATEGORIES:Change management,Freeze\r\nDTSTART;VALUE=DATE:20260320\r\nDTEND;VALUE=DATE:20260322\r\nEND:VEVENT\r\n",
+			),
+
+			assertErr: require.NoError,
+			wantWindows: migration.Windows{
+				{Name: "change-window-20260310-2200", Start: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC)},
+			},
+			wantExclusions: []api.MigrationWindowExclusion{
+				{Name: "year-end-freeze-20260320-0000", Start: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "success - all events are freeze events",
+			calendar: calendar(
+				"BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:Release\r\nDTSTART:20260310T220000Z\r\nDTEND:20260311T020000Z\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:2@example.com\r\nSUMMARY:Past release\r\nDTSTART:20260210T220000Z\r\nDTEND:20260211T020000Z\r\nEND:VEVENT\r\n",
+			),
+			freeze: true,
+
+			assertErr:   require.NoError,
+			wantWindows: migration.Windows{},
+			wantExclusions: []api.MigrationWindowExclusion{
+				{Name: "release-20260310-2200", Start: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:      "error - invalid event capacity",
+			calendar:  calendar("BEGIN:VEVENT\r\nUID:1@example.com\r\nDTSTART:20260310T220000Z\r\nDURATION:PT4H\r\nX-MIGRATION-MANAGER-CAPACITY:0\r\nEND:VEVENT\r\n"),
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - recurring event",
+			calendar:  calendar("BEGIN:VEVENT\r\nUID:1@example.com\r\nDTSTART:20260310T220000Z\r\nDURATION:PT4H\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n"),
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - missing end time",
+			calendar:  calendar("BEGIN:VEVENT\r\nUID:1@example.com\r\nDTSTART:20260310T220000Z\r\nEND:VEVENT\r\n"),
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - invalid time",
+			calendar:  calendar("BEGIN:VEVENT\r\nUID:1@example.com\r\nDTSTART:2026-03-10\r\nDURATION:PT4H\r\nEND:VEVENT\r\n"),
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - duplicate windows",
+			calendar:  calendar("BEGIN:VEVENT\r\nUID:1@example.com\r\nSUMMARY:A\r\nDTSTART:20260310T220000Z\r\nDURATION:PT4H\r\nEND:VEVENT\r\nBEGIN:VEVENT\r\nUID:2@example.com\r\nSUMMARY:A\r\nDTSTART:20260310T220000Z\r\nDURATION:PT2H\r\nEND:VEVENT\r\n"),
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - unterminated component",
+			calendar:  "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1@example.com\r\n",
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		windows, exclusions, err := migration.ParseICalendarWindows(strings.NewReader(tc.calendar), now, tc.freeze)
+		tc.assertErr(t, err)
+		if err != nil {
+			continue
+		}
+
+		if tc.wantExclusions == nil {
+			tc.wantExclusions = []api.MigrationWindowExclusion{}
+		}
+
+		require.Equal(t, tc.wantWindows, windows)
+		require.Equal(t, tc.wantExclusions, exclusions)
+	}
+}
@@ -3,9 +3,12 @@ package migration
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/adhocore/gronx"
 	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/validate"
 
@@ -26,6 +29,9 @@ type Window struct {
 	Batch string `db:"join=batches.name&primary=yes"`
 
 	Config api.MigrationWindowConfig `db:"marshal=json"`
+
+	// Schedule is the name of the migration window schedule that generated the window, if any.
+	Schedule string
 }
 
 func (w Window) IsEmpty() bool {
@@ -176,10 +182,184 @@ func (ws Windows) GetEarliest(minDuration time.Duration) (*Window, error) {
 
 func (w Window) ToAPI() api.MigrationWindow {
 	return api.MigrationWindow{
-		Name:    w.Name,
-		Start:   w.Start,
-		End:     w.End,
-		Lockout: w.Lockout,
-		Config:  w.Config,
+		Name:     w.Name,
+		Start:    w.Start,
+		End:      w.End,
+		Lockout:  w.Lockout,
+		Config:   w.Config,
+		Schedule: w.Schedule,
+	}
+}
+
+// WindowScheduleHorizon is how far ahead of the current time migration window schedules are expanded into migration windows.
+const WindowScheduleHorizon = 14 * 24 * time.Hour
+
+// maxScheduledWindows is the maximum number of migration windows a single schedule can generate within the horizon.
+const maxScheduledWindows = 500
+
+// ExpandWindowSchedule returns the migration windows generated by the schedule that have not ended by the given time, and start before the end of the horizon.
+func ExpandWindowSchedule(schedule api.MigrationWindowSchedule, now time.Time) (Windows, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid timezone %q for migration window schedule %q: %w", schedule.Timezone, schedule.Name, err)
 	}
+
+	horizon := now.Add(WindowScheduleHorizon)
+	windows := Windows{}
+
+	// Start from windows that may still be open.
+	ref := now.Add(-schedule.Duration.Duration).In(loc)
+	for {
+		start, err := gronx.NextTickAfter(schedule.Cron, ref, false)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to determine next start time for migration window schedule %q: %w", schedule.Name, err)
+		}
+
+		if !start.Before(horizon) || (!schedule.Until.IsZero() && start.After(schedule.Until)) {
+			break
+		}
+
+		if len(windows) == maxScheduledWindows {
+			return nil, fmt.Errorf("Migration window schedule %q generates more than %d windows within %s", schedule.Name, maxScheduledWindows, WindowScheduleHorizon)
+		}
+
+		end := start.Add(schedule.Duration.Duration)
+		w := Window{
+			Name:     fmt.Sprintf("%s-%s", schedule.Name, start.UTC().Format("20060102-1504")),
+			Start:    start.UTC(),
+			End:      end.UTC(),
+			Config:   schedule.Config,
+			Schedule: schedule.Name,
+		}
+
+		if schedule.LockoutOffset.Duration > 0 {
+			w.Lockout = end.Add(-schedule.LockoutOffset.Duration).UTC()
+		}
+
+		windows = append(windows, w)
+		ref = start
+	}
+
+	return windows, nil
+}
+
+// ValidateWindowSchedule validates the migration window schedule, and that the windows it generates do not overlap.
+func ValidateWindowSchedule(schedule api.MigrationWindowSchedule) error {
+	err := validate.IsAPIName(schedule.Name, false)
+	if err != nil {
+		return fmt.Errorf("Schedule name %q cannot be used: %w", schedule.Name, err)
+	}
+
+	if !gronx.IsValid(schedule.Cron) {
+		return fmt.Errorf("Cron expression %q is not valid", schedule.Cron)
+	}
+
+	if schedule.Duration.Duration <= 0 {
+		return fmt.Errorf("Duration %q must be greater than 0", schedule.Duration)
+	}
+
+	if schedule.LockoutOffset.Duration < 0 || schedule.LockoutOffset.Duration >= schedule.Duration.Duration {
+		return fmt.Errorf("Lockout offset %q must be less than the duration %q", schedule.LockoutOffset, schedule.Duration)
+	}
+
+	if schedule.Config.Capacity < 0 {
+		return fmt.Errorf("Window capacity %q must be greater than 0", schedule.Config.Capacity)
+	}
+
+	windows, err := ExpandWindowSchedule(schedule, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for i, w := range windows {
+		err := w.Validate()
+		if err != nil {
+			return err
+		}
+
+		if i > 0 && w.Start.Before(windows[i-1].End) {
+			return fmt.Errorf("Window starting at %q overlaps with the previous window ending at %q", w.Start.String(), windows[i-1].End.String())
+		}
+	}
+
+	return nil
+}
+
+// ValidateWindowExclusion validates the migration window exclusion.
+func ValidateWindowExclusion(exclusion api.MigrationWindowExclusion) error {
+	err := validate.IsAPIName(exclusion.Name, false)
+	if err != nil {
+		return fmt.Errorf("Exclusion name %q cannot be used: %w", exclusion.Name, err)
+	}
+
+	if exclusion.Start.IsZero() || exclusion.End.IsZero() {
+		return fmt.Errorf("Exclusion must have a start and end time")
+	}
+
+	if !exclusion.End.After(exclusion.Start) {
+		return fmt.Errorf("Exclusion end time must be after its start time")
+	}
+
+	return nil
+}
+
+// Exclude returns the windows with the periods of the exclusions removed.
+// Windows that overlap the start or end of an exclusion are trimmed, and windows containing an exclusion are split around it.
+// Split windows are named after the original window with a counter suffix, and windows of which nothing remains are dropped.
+func (ws Windows) Exclude(exclusions []api.MigrationWindowExclusion) Windows {
+	sorted := slices.Clone(exclusions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	result := Windows{}
+	for _, w := range ws {
+		pieces := Windows{w}
+		for _, e := range sorted {
+			// Exclusions are sorted, so only the last piece can overlap with the next one.
+			last := pieces[len(pieces)-1]
+			if (!last.End.IsZero() && !e.Start.Before(last.End)) || (!last.Start.IsZero() && !e.End.After(last.Start)) {
+				continue
+			}
+
+			pieces = pieces[:len(pieces)-1]
+			if last.Start.IsZero() || e.Start.After(last.Start) {
+				before := last
+				before.End = e.Start
+				pieces = append(pieces, before)
+			}
+
+			if !last.End.IsZero() && !e.End.Before(last.End) {
+				break
+			}
+
+			after := last
+			after.Start = e.End
+			pieces = append(pieces, after)
+		}
+
+		n := 0
+		for _, p := range pieces {
+			if !p.Lockout.IsZero() {
+				// A piece that starts after the lockout time can't accept any instances.
+				if p.Lockout.Before(p.Start) {
+					continue
+				}
+
+				if !p.End.IsZero() && p.Lockout.After(p.End) {
+					p.Lockout = p.End
+				}
+			}
+
+			n++
+			if n > 1 {
+				suffix := fmt.Sprintf("-%d", n)
+				p.Name = strings.TrimRight(w.Name[:min(len(w.Name), 64-len(suffix))], "-") + suffix
+			}
+
+			result = append(result, p)
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,260 @@
+package migration_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+func TestExpandWindowSchedule(t *testing.T) {
+	schedule := api.MigrationWindowSchedule{
+		Name:          "nightly",
+		Cron:          "0 22 * * *",
+		Duration:      api.AsDuration(4 * time.Hour),
+		LockoutOffset: api.AsDuration(time.Hour),
+		Timezone:      "Europe/Zurich",
+		Until:         time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Config:        api.MigrationWindowConfig{Capacity: 3},
+	}
+
+	cases := []struct {
+		name     string
+		schedule api.MigrationWindowSchedule
+		now      time.Time
+
+		assertErr  require.ErrorAssertionFunc
+		wantStarts []time.Time
+	}{
+		{
+			name:     "success - timezone with daylight saving change",
+			schedule: schedule,
+			now:      time.Date(2026, 3, 27, 12, 0, 0, 0, time.UTC),
+
+			assertErr: require.NoError,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 27, 21, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 28, 21, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 29, 20, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 30, 20, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "success - includes the currently open window",
+			schedule: schedule,
+			now:      time.Date(2026, 3, 30, 22, 30, 0, 0, time.UTC),
+
+			assertErr: require.NoError,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 30, 20, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "success - limited by the horizon",
+			schedule: api.MigrationWindowSchedule{Name: "weekly", Cron: "0 0 * * 0", Duration: api.AsDuration(time.Hour)},
+			now:      time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+
+			assertErr: require.NoError,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "error - too many windows",
+			schedule: api.MigrationWindowSchedule{Name: "often", Cron: "* * * * *", Duration: api.AsDuration(time.Minute)},
+			now:      time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+
+			assertErr: require.Error,
+		},
+		{
+			name:     "error - invalid timezone",
+			schedule: api.MigrationWindowSchedule{Name: "nightly", Cron: "0 22 * * *", Duration: api.AsDuration(time.Hour), Timezone: "Invalid/Zone"},
+			now:      time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		windows, err := migration.ExpandWindowSchedule(tc.schedule, tc.now)
+		tc.assertErr(t, err)
+		if err != nil {
+			continue
+		}
+
+		require.Len(t, windows, len(tc.wantStarts))
+		for j, w := range windows {
+			require.Equal(t, tc.wantStarts[j], w.Start)
+			require.Equal(t, tc.wantStarts[j].Add(tc.schedule.Duration.Duration), w.End)
+			require.Equal(t, tc.schedule.Name+"-"+tc.wantStarts[j].Format("20060102-1504"), w.Name)
+			require.Equal(t, tc.schedule.Name, w.Schedule)
+			require.Equal(t, tc.schedule.Config, w.Config)
+			if tc.schedule.LockoutOffset.Duration > 0 {
+				require.Equal(t, w.End.Add(-tc.schedule.LockoutOffset.Duration), w.Lockout)
+			} else {
+				require.True(t, w.Lockout.IsZero())
+			}
+		}
+	}
+}
+
+func TestValidateWindowSchedule(t *testing.T) {
+	valid := api.MigrationWindowSchedule{
+		Name:          "nightly",
+		Cron:          "0 22 * * 1-5",
+		Duration:      api.AsDuration(4 * time.Hour),
+		LockoutOffset: api.AsDuration(time.Hour),
+		Timezone:      "Europe/Zurich",
+	}
+
+	cases := []struct {
+		name   string
+		modify func(s *api.MigrationWindowSchedule)
+
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:      "success",
+			modify:    func(s *api.MigrationWindowSchedule) {},
+			assertErr: require.NoError,
+		},
+		{
+			name:      "error - invalid name",
+			modify:    func(s *api.MigrationWindowSchedule) { s.Name = "night ly" },
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - invalid cron expression",
+			modify:    func(s *api.MigrationWindowSchedule) { s.Cron = "0 25 * * *" },
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - missing duration",
+			modify:    func(s *api.MigrationWindowSchedule) { s.Duration = api.Duration{} },
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - lockout offset not within the window",
+			modify:    func(s *api.MigrationWindowSchedule) { s.LockoutOffset = s.Duration },
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - invalid timezone",
+			modify:    func(s *api.MigrationWindowSchedule) { s.Timezone = "Invalid/Zone" },
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - negative capacity",
+			modify:    func(s *api.MigrationWindowSchedule) { s.Config.Capacity = -1 },
+			assertErr: require.Error,
+		},
+		{
+			name:      "error - overlapping windows",
+			modify:    func(s *api.MigrationWindowSchedule) { s.Cron = "0 * * * *" },
+			assertErr: require.Error,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		schedule := valid
+		tc.modify(&schedule)
+		tc.assertErr(t, migration.ValidateWindowSchedule(schedule))
+	}
+}
+
+func TestWindows_Exclude(t *testing.T) {
+	at := func(day int, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	window := migration.Window{Name: "w1", Start: at(10, 20), End: at(11, 4), Lockout: at(11, 3), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"}
+
+	cases := []struct {
+		name       string
+		windows    migration.Windows
+		exclusions []api.MigrationWindowExclusion
+
+		wantWindows migration.Windows
+	}{
+		{
+			name:        "no overlap",
+			windows:     migration.Windows{window},
+			exclusions:  []api.MigrationWindowExclusion{{Name: "e1", Start: at(11, 4), End: at(11, 8)}, {Name: "e2", Start: at(10, 12), End: at(10, 20)}},
+			wantWindows: migration.Windows{window},
+		},
+		{
+			name:        "exclusion covers the window",
+			windows:     migration.Windows{window},
+			exclusions:  []api.MigrationWindowExclusion{{Name: "e1", Start: at(10, 20), End: at(11, 4)}},
+			wantWindows: migration.Windows{},
+		},
+		{
+			name:       "exclusion overlaps the start",
+			windows:    migration.Windows{window},
+			exclusions: []api.MigrationWindowExclusion{{Name: "e1", Start: at(10, 18), End: at(10, 22)}},
+			wantWindows: migration.Windows{
+				{Name: "w1", Start: at(10, 22), End: at(11, 4), Lockout: at(11, 3), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"},
+			},
+		},
+		{
+			name:       "exclusion overlaps the end and lockout",
+			windows:    migration.Windows{window},
+			exclusions: []api.MigrationWindowExclusion{{Name: "e1", Start: at(11, 2), End: at(11, 6)}},
+			wantWindows: migration.Windows{
+				{Name: "w1", Start: at(10, 20), End: at(11, 2), Lockout: at(11, 2), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"},
+			},
+		},
+		{
+			name:       "exclusions within the window split it",
+			windows:    migration.Windows{window},
+			exclusions: []api.MigrationWindowExclusion{{Name: "e2", Start: at(11, 0), End: at(11, 1)}, {Name: "e1", Start: at(10, 21), End: at(10, 22)}},
+			wantWindows: migration.Windows{
+				{Name: "w1", Start: at(10, 20), End: at(10, 21), Lockout: at(10, 21), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"},
+				{Name: "w1-2", Start: at(10, 22), End: at(11, 0), Lockout: at(11, 0), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"},
+				{Name: "w1-3", Start: at(11, 1), End: at(11, 4), Lockout: at(11, 3), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"},
+			},
+		},
+		{
+			name:       "parts after the lockout time are dropped",
+			windows:    migration.Windows{{Name: "w1", Start: at(10, 20), End: at(11, 4), Lockout: at(10, 22)}},
+			exclusions: []api.MigrationWindowExclusion{{Name: "e1", Start: at(10, 21), End: at(10, 23)}},
+			wantWindows: migration.Windows{
+				{Name: "w1", Start: at(10, 20), End: at(10, 21), Lockout: at(10, 21)},
+			},
+		},
+		{
+			name:       "unlimited window",
+			windows:    migration.Windows{{Name: "w1"}},
+			exclusions: []api.MigrationWindowExclusion{{Name: "e1", Start: at(10, 20), End: at(11, 4)}},
+			wantWindows: migration.Windows{
+				{Name: "w1", End: at(10, 20)},
+				{Name: "w1-2", Start: at(11, 4)},
+			},
+		},
+		{
+			name:       "multiple windows",
+			windows:    migration.Windows{window, {Name: "w2", Start: at(11, 20), End: at(12, 4)}},
+			exclusions: []api.MigrationWindowExclusion{{Name: "e1", Start: at(11, 0), End: at(11, 22)}},
+			wantWindows: migration.Windows{
+				{Name: "w1", Start: at(10, 20), End: at(11, 0), Lockout: at(11, 0), Batch: "b1", Config: api.MigrationWindowConfig{Capacity: 5}, Schedule: "nightly"},
+				{Name: "w2", Start: at(11, 22), End: at(12, 4)},
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+		require.Equal(t, tc.wantWindows, tc.windows.Exclude(tc.exclusions))
+	}
+}
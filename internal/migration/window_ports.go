@@ -2,6 +2,8 @@ package migration
 
 import (
 	"context"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
 //go:generate go run github.com/matryer/moq -fmt goimports -pkg migration_test -out window_service_mock_gen_test.go -rm . WindowService
@@ -13,6 +15,7 @@ type WindowService interface {
 	GetAllByBatch(ctx context.Context, batchName string) (Windows, error)
 	Update(ctx context.Context, window *Window) error
 	ReplaceByBatch(ctx context.Context, queueSvc QueueService, batchName string, windows Windows) error
+	SyncSchedules(ctx context.Context, queueSvc QueueService, batchName string, schedules []api.MigrationWindowSchedule, exclusions []api.MigrationWindowExclusion) error
 	DeleteByNameAndBatch(ctx context.Context, queueSvc QueueService, name string, batchName string) error
 }
 
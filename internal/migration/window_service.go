@@ -3,8 +3,10 @@ package migration
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/FuturFusion/migration-manager/internal/transaction"
+	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
 type windowService struct {
@@ -72,6 +74,11 @@ func (s windowService) GetByNameAndBatch(ctx context.Context, name string, batch
 func (s windowService) ReplaceByBatch(ctx context.Context, queueSvc QueueService, batchName string, windows Windows) error {
 	newWindowsByName := map[string]Window{}
 	for _, w := range windows {
+		// Generated windows are managed by their schedule.
+		if w.Schedule != "" {
+			continue
+		}
+
 		err := w.Validate()
 		if err != nil {
 			return err
@@ -88,6 +95,15 @@ func (s windowService) ReplaceByBatch(ctx context.Context, queueSvc QueueService
 
 		oldWindowsByName := map[string]Window{}
 		for _, w := range oldWindows {
+			if w.Schedule != "" {
+				_, ok := newWindowsByName[w.Name]
+				if ok {
+					return fmt.Errorf("Window %q is generated by schedule %q and cannot be replaced", w.Name, w.Schedule)
+				}
+
+				continue
+			}
+
 			oldWindowsByName[w.Name] = w
 		}
 
@@ -128,6 +144,10 @@ func (s windowService) ReplaceByBatch(ctx context.Context, queueSvc QueueService
 
 		// Update and prune old windows.
 		for _, oldWindow := range oldWindows {
+			if oldWindow.Schedule != "" {
+				continue
+			}
+
 			newWindow, ok := newWindowsByName[oldWindow.Name]
 			if !ok {
 				err = s.repo.DeleteByNameAndBatch(ctx, oldWindow.Name, oldWindow.Batch)
@@ -163,6 +183,87 @@ func (s windowService) ReplaceByBatch(ctx context.Context, queueSvc QueueService
 	})
 }
 
+// SyncSchedules implements WindowService.
+func (s windowService) SyncSchedules(ctx context.Context, queueSvc QueueService, batchName string, schedules []api.MigrationWindowSchedule, exclusions []api.MigrationWindowExclusion) error {
+	now := time.Now().UTC()
+	newWindowsByName := map[string]Window{}
+	for _, schedule := range schedules {
+		windows, err := ExpandWindowSchedule(schedule, now)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range windows.Exclude(exclusions) {
+			w.Batch = batchName
+			newWindowsByName[w.Name] = w
+		}
+	}
+
+	return transaction.Do(ctx, func(ctx context.Context) error {
+		oldWindows, err := s.repo.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return fmt.Errorf("Failed to get existing migration windows for batch %q: %w", batchName, err)
+		}
+
+		qs, err := queueSvc.GetAllByBatch(ctx, batchName)
+		if err != nil {
+			return fmt.Errorf("Failed to get queue entries for batch %q: %w", batchName, err)
+		}
+
+		assigned := map[string]bool{}
+		for _, q := range qs {
+			windowName := q.GetWindowName()
+			if windowName != nil {
+				assigned[*windowName] = true
+			}
+		}
+
+		for _, oldWindow := range oldWindows {
+			newWindow, ok := newWindowsByName[oldWindow.Name]
+			if oldWindow.Schedule == "" {
+				if ok {
+					return fmt.Errorf("Window %q generated by schedule %q conflicts with an existing migration window", newWindow.Name, newWindow.Schedule)
+				}
+
+				continue
+			}
+
+			delete(newWindowsByName, oldWindow.Name)
+
+			// Windows assigned to queue entries are kept as they are, even if their schedule has since changed.
+			if assigned[oldWindow.Name] {
+				continue
+			}
+
+			if !ok {
+				err = s.repo.DeleteByNameAndBatch(ctx, oldWindow.Name, oldWindow.Batch)
+				if err != nil {
+					return fmt.Errorf("Failed to delete migration window %q from batch %q: %w", oldWindow.Name, oldWindow.Batch, err)
+				}
+
+				continue
+			}
+
+			newWindow.ID = oldWindow.ID
+			if oldWindow != newWindow {
+				err = s.repo.Update(ctx, newWindow)
+				if err != nil {
+					return fmt.Errorf("Failed to update migration window %q in batch %q: %w", newWindow.Name, newWindow.Batch, err)
+				}
+			}
+		}
+
+		for _, newWindow := range newWindowsByName {
+			_, err = s.repo.Create(ctx, newWindow)
+			if err != nil {
+				return fmt.Errorf("Failed to create migration window %q in batch %q: %w", newWindow.Name, newWindow.Batch, err)
+			}
+		}
+
+		return nil
+	})
+}
+
 // Update implements WindowService.
 func (s windowService) Update(ctx context.Context, window *Window) error {
 	err := window.Validate()
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
 )
 
 // Ensure, that WindowServiceMock does implement migration.WindowService.
@@ -38,6 +39,9 @@ var _ migration.WindowService = &WindowServiceMock{}
 //			ReplaceByBatchFunc: func(ctx context.Context, queueSvc migration.QueueService, batchName string, windows migration.Windows) error {
 //				panic("mock out the ReplaceByBatch method")
 //			},
+//			SyncSchedulesFunc: func(ctx context.Context, queueSvc migration.QueueService, batchName string, schedules []api.MigrationWindowSchedule, exclusions []api.MigrationWindowExclusion) error {
+//				panic("mock out the SyncSchedules method")
+//			},
 //			UpdateFunc: func(ctx context.Context, window *migration.Window) error {
 //				panic("mock out the Update method")
 //			},
@@ -66,6 +70,9 @@ type WindowServiceMock struct {
 	// ReplaceByBatchFunc mocks the ReplaceByBatch method.
 	ReplaceByBatchFunc func(ctx context.Context, queueSvc migration.QueueService, batchName string, windows migration.Windows) error
 
+	// SyncSchedulesFunc mocks the SyncSchedules method.
+	SyncSchedulesFunc func(ctx context.Context, queueSvc migration.QueueService, batchName string, schedules []api.MigrationWindowSchedule, exclusions []api.MigrationWindowExclusion) error
+
 	// UpdateFunc mocks the Update method.
 	UpdateFunc func(ctx context.Context, window *migration.Window) error
 
@@ -121,6 +128,19 @@ type WindowServiceMock struct {
 			// Windows is the windows argument value.
 			Windows migration.Windows
 		}
+		// SyncSchedules holds details about calls to the SyncSchedules method.
+		SyncSchedules []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// QueueSvc is the queueSvc argument value.
+			QueueSvc migration.QueueService
+			// BatchName is the batchName argument value.
+			BatchName string
+			// Schedules is the schedules argument value.
+			Schedules []api.MigrationWindowSchedule
+			// Exclusions is the exclusions argument value.
+			Exclusions []api.MigrationWindowExclusion
+		}
 		// Update holds details about calls to the Update method.
 		Update []struct {
 			// Ctx is the ctx argument value.
@@ -135,6 +155,7 @@ type WindowServiceMock struct {
 	lockGetAllByBatch        sync.RWMutex
 	lockGetByNameAndBatch    sync.RWMutex
 	lockReplaceByBatch       sync.RWMutex
+	lockSyncSchedules        sync.RWMutex
 	lockUpdate               sync.RWMutex
 }
 
@@ -370,6 +391,54 @@ func (mock *WindowServiceMock) ReplaceByBatchCalls() []struct {
 	return calls
 }
 
+// SyncSchedules calls SyncSchedulesFunc.
+func (mock *WindowServiceMock) SyncSchedules(ctx context.Context, queueSvc migration.QueueService, batchName string, schedules []api.MigrationWindowSchedule, exclusions []api.MigrationWindowExclusion) error {
+	if mock.SyncSchedulesFunc == nil {
+		panic("WindowServiceMock.SyncSchedulesFunc: method is nil but WindowService.SyncSchedules was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		QueueSvc   migration.QueueService
+		BatchName  string
+		Schedules  []api.MigrationWindowSchedule
+		Exclusions []api.MigrationWindowExclusion
+	}{
+		Ctx:        ctx,
+		QueueSvc:   queueSvc,
+		BatchName:  batchName,
+		Schedules:  schedules,
+		Exclusions: exclusions,
+	}
+	mock.lockSyncSchedules.Lock()
+	mock.calls.SyncSchedules = append(mock.calls.SyncSchedules, callInfo)
+	mock.lockSyncSchedules.Unlock()
+	return mock.SyncSchedulesFunc(ctx, queueSvc, batchName, schedules, exclusions)
+}
+
+// SyncSchedulesCalls gets all the calls that were made to SyncSchedules.
+// Check the length with:
+//
+//	len(mockedWindowService.SyncSchedulesCalls())
+func (mock *WindowServiceMock) SyncSchedulesCalls() []struct {
+	Ctx        context.Context
+	QueueSvc   migration.QueueService
+	BatchName  string
+	Schedules  []api.MigrationWindowSchedule
+	Exclusions []api.MigrationWindowExclusion
+} {
+	var calls []struct {
+		Ctx        context.Context
+		QueueSvc   migration.QueueService
+		BatchName  string
+		Schedules  []api.MigrationWindowSchedule
+		Exclusions []api.MigrationWindowExclusion
+	}
+	mock.lockSyncSchedules.RLock()
+	calls = mock.calls.SyncSchedules
+	mock.lockSyncSchedules.RUnlock()
+	return calls
+}
+
 // Update calls UpdateFunc.
 func (mock *WindowServiceMock) Update(ctx context.Context, window *migration.Window) error {
 	if mock.UpdateFunc == nil {
@@ -109,10 +109,11 @@ func TestWindowService_DeleteByNameAndBatch(t *testing.T) {
 
 func TestWindowService_ReplaceByBatch(t *testing.T) {
 	type window struct {
-		n string
-		s int
-		e int
-		c int
+		n   string
+		s   int
+		e   int
+		c   int
+		sch string
 	}
 
 	toWindows := func(ws []window, t time.Time, batch string) migration.Windows {
@@ -127,6 +128,7 @@ func TestWindowService_ReplaceByBatch(t *testing.T) {
 				Config: api.MigrationWindowConfig{
 					Capacity: w.c,
 				},
+				Schedule: w.sch,
 			}
 		}
 
@@ -213,6 +215,24 @@ func TestWindowService_ReplaceByBatch(t *testing.T) {
 			newWindows:   []window{{n: "w1", s: 1, e: 2}, {n: "w2", s: 2, e: 3}, {n: "w3", s: 3, e: 4}},
 			assertErr:    require.NoError,
 		},
+		{
+			name:           "success - generated windows are untouched",
+			batch:          "b1",
+			queueWindows:   []string{""},
+			oldWindows:     []window{{n: "w1", s: 1, e: 2}, {n: "g1", s: 2, e: 3, sch: "s1"}},
+			newWindows:     []window{{n: "w2", s: 3, e: 4}, {n: "g1", s: 20, e: 30, sch: "s1"}, {n: "g2", s: 4, e: 5, sch: "s1"}},
+			createdWindows: []item{{Value: "w2"}},
+			removedWindows: []item{{Value: "w1"}},
+			assertErr:      require.NoError,
+		},
+		{
+			name:         "error - generated window replaced by a regular window",
+			batch:        "b1",
+			queueWindows: []string{""},
+			oldWindows:   []window{{n: "w1", s: 1, e: 2}, {n: "g1", s: 2, e: 3, sch: "s1"}},
+			newWindows:   []window{{n: "w1", s: 1, e: 2}, {n: "g1", s: 2, e: 3}},
+			assertErr:    require.Error,
+		},
 		{
 			name:         "error - create, update, delete (queue entry window shrink start time)",
 			batch:        "b1",
@@ -361,3 +381,176 @@ func TestWindowService_ReplaceByBatch(t *testing.T) {
 		})
 	}
 }
+
+func TestWindowService_SyncSchedules(t *testing.T) {
+	schedule := api.MigrationWindowSchedule{
+		Name:          "nightly",
+		Cron:          "0 22 * * *",
+		Duration:      api.AsDuration(4 * time.Hour),
+		LockoutOffset: api.AsDuration(time.Hour),
+		Timezone:      "Europe/Zurich",
+		Config:        api.MigrationWindowConfig{Capacity: 5},
+	}
+
+	expected, err := migration.ExpandWindowSchedule(schedule, time.Now().UTC())
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	names := func(ws migration.Windows) []string {
+		result := make([]string, 0, len(ws))
+		for _, w := range ws {
+			result = append(result, w.Name)
+		}
+
+		return result
+	}
+
+	changedCapacity := expected[0]
+	changedCapacity.Batch = "b1"
+	changedCapacity.Config.Capacity = 1
+
+	freeze := api.MigrationWindowExclusion{Name: "freeze", Start: expected[0].Start.Add(-time.Hour), End: expected[0].End}
+
+	cases := []struct {
+		name       string
+		schedules  []api.MigrationWindowSchedule
+		exclusions []api.MigrationWindowExclusion
+
+		queueWindows []string
+		oldWindows   migration.Windows
+
+		wantCreated []string
+		wantUpdated []string
+		wantRemoved []string
+
+		repoGetErr    error
+		repoCreateErr error
+		queueGetErr   error
+
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:        "success - create all windows",
+			schedules:   []api.MigrationWindowSchedule{schedule},
+			wantCreated: names(expected),
+			assertErr:   require.NoError,
+		},
+		{
+			name:        "success - no schedules removes stale generated windows",
+			oldWindows:  migration.Windows{{Name: "w1", Batch: "b1"}, {Name: "nightly-old", Batch: "b1", Schedule: "nightly"}},
+			wantRemoved: []string{"nightly-old"},
+			assertErr:   require.NoError,
+		},
+		{
+			name:         "success - assigned generated windows are kept",
+			schedules:    []api.MigrationWindowSchedule{schedule},
+			queueWindows: []string{"nightly-old", expected[0].Name},
+			oldWindows:   migration.Windows{{Name: "nightly-old", Batch: "b1", Schedule: "nightly"}, changedCapacity},
+			wantCreated:  names(expected[1:]),
+			assertErr:    require.NoError,
+		},
+		{
+			name:        "success - unassigned generated windows are updated",
+			schedules:   []api.MigrationWindowSchedule{schedule},
+			oldWindows:  migration.Windows{changedCapacity},
+			wantCreated: names(expected[1:]),
+			wantUpdated: names(expected[:1]),
+			assertErr:   require.NoError,
+		},
+		{
+			name:        "success - excluded windows are not created",
+			schedules:   []api.MigrationWindowSchedule{schedule},
+			exclusions:  []api.MigrationWindowExclusion{freeze},
+			wantCreated: names(expected[1:]),
+			assertErr:   require.NoError,
+		},
+		{
+			name:        "success - excluded windows are removed",
+			schedules:   []api.MigrationWindowSchedule{schedule},
+			exclusions:  []api.MigrationWindowExclusion{freeze},
+			oldWindows:  migration.Windows{changedCapacity},
+			wantCreated: names(expected[1:]),
+			wantRemoved: names(expected[:1]),
+			assertErr:   require.NoError,
+		},
+		{
+			name:       "error - conflicting regular window",
+			schedules:  []api.MigrationWindowSchedule{schedule},
+			oldWindows: migration.Windows{{Name: expected[0].Name, Batch: "b1"}},
+			assertErr:  require.Error,
+		},
+		{
+			name:      "error - invalid timezone",
+			schedules: []api.MigrationWindowSchedule{{Name: "nightly", Cron: "0 22 * * *", Duration: api.AsDuration(time.Hour), Timezone: "Invalid/Zone"}},
+			assertErr: require.Error,
+		},
+		{
+			name:       "error - repo.GetAllByBatch",
+			schedules:  []api.MigrationWindowSchedule{schedule},
+			repoGetErr: boom.Error,
+			assertErr:  boom.ErrorIs,
+		},
+		{
+			name:        "error - queueSvc.GetAllByBatch",
+			schedules:   []api.MigrationWindowSchedule{schedule},
+			queueGetErr: boom.Error,
+			assertErr:   boom.ErrorIs,
+		},
+		{
+			name:          "error - repo.Create",
+			schedules:     []api.MigrationWindowSchedule{schedule},
+			repoCreateErr: boom.Error,
+			assertErr:     boom.ErrorIs,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			var created, updated, removed []string
+			repo := &mock.WindowRepoMock{
+				GetAllByBatchFunc: func(ctx context.Context, batchName string) (migration.Windows, error) {
+					return tc.oldWindows, tc.repoGetErr
+				},
+				DeleteByNameAndBatchFunc: func(ctx context.Context, name string, batchName string) error {
+					removed = append(removed, name)
+					return nil
+				},
+				CreateFunc: func(ctx context.Context, window migration.Window) (int64, error) {
+					require.Equal(t, "b1", window.Batch)
+					require.Equal(t, "nightly", window.Schedule)
+					created = append(created, window.Name)
+					return 1, tc.repoCreateErr
+				},
+				UpdateFunc: func(ctx context.Context, window migration.Window) error {
+					require.Equal(t, schedule.Config, window.Config)
+					updated = append(updated, window.Name)
+					return nil
+				},
+			}
+
+			queueSvc := &QueueServiceMock{
+				GetAllByBatchFunc: func(ctx context.Context, batch string) (migration.QueueEntries, error) {
+					entries := migration.QueueEntries{}
+					for _, w := range tc.queueWindows {
+						entries = append(entries, migration.QueueEntry{BatchName: "b1", MigrationWindowName: sql.NullString{Valid: true, String: w}})
+					}
+
+					return entries, tc.queueGetErr
+				},
+			}
+
+			windowSvc := migration.NewWindowService(repo)
+			err := windowSvc.SyncSchedules(context.Background(), queueSvc, "b1", tc.schedules, tc.exclusions)
+			tc.assertErr(t, err)
+			if err != nil {
+				return
+			}
+
+			require.ElementsMatch(t, tc.wantCreated, created)
+			require.ElementsMatch(t, tc.wantUpdated, updated)
+			require.ElementsMatch(t, tc.wantRemoved, removed)
+		})
+	}
+}
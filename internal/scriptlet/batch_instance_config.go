@@ -0,0 +1,274 @@
+package scriptlet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	incusAPI "github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/scriptlet"
+	"github.com/lxc/incus/v6/shared/validate"
+	"go.starlark.net/starlark"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// protectedConfigPrefixes are configuration keys managed by Migration Manager, which the instance configuration scriptlet cannot modify.
+var protectedConfigPrefixes = []string{"volatile.", "user.migration."}
+
+// BatchInstanceConfigRun runs the batch instance configuration scriptlet against the given instance definition, modifying it in place.
+// If the scriptlet sets a new name for the instance, it is returned.
+func BatchInstanceConfigRun(ctx context.Context, loader *scriptlet.Loader, instance api.Instance, batch api.Batch, usedNetworks []api.Network, def *incusAPI.InstancePut) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logFunc := CreateLogger(slog.Default(), "Batch instance configuration scriptlet")
+
+	if def.Config == nil {
+		def.Config = map[string]string{}
+	}
+
+	if def.Devices == nil {
+		def.Devices = map[string]map[string]string{}
+	}
+
+	// Incus applies the default profile if none are given, so make that explicit before modifying the list.
+	if def.Profiles == nil {
+		def.Profiles = []string{"default"}
+	}
+
+	var newName string
+	setConfigFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+		var value string
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "value", &value)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "" {
+			return nil, errors.New("Configuration key is empty")
+		}
+
+		for _, prefix := range protectedConfigPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				return nil, fmt.Errorf("Configuration key %q cannot be modified", key)
+			}
+		}
+
+		if value == "" {
+			delete(def.Config, key)
+		} else {
+			def.Config[key] = value
+		}
+
+		slog.Info("Batch instance configuration set config key for instance", slog.String("location", instance.Location), slog.String("key", key), slog.String("value", value))
+
+		return starlark.None, nil
+	}
+
+	addDeviceFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var devName string
+		var devConfig *starlark.Dict
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "device_name", &devName, "config", &devConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if devName == "" {
+			return nil, errors.New("Device name is empty")
+		}
+
+		dev := map[string]string{}
+		for _, item := range devConfig.Items() {
+			k, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("Device %q has non-string key %v", devName, item[0])
+			}
+
+			v, ok := starlark.AsString(item[1])
+			if !ok {
+				return nil, fmt.Errorf("Device %q has non-string value for key %q", devName, k)
+			}
+
+			dev[k] = v
+		}
+
+		if dev["type"] == "" {
+			return nil, fmt.Errorf("Device %q is missing a type", devName)
+		}
+
+		def.Devices[devName] = dev
+		slog.Info("Batch instance configuration added device for instance", slog.String("location", instance.Location), slog.String("device", devName), slog.String("type", dev["type"]))
+
+		return starlark.None, nil
+	}
+
+	removeDeviceFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var devName string
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "device_name", &devName)
+		if err != nil {
+			return nil, err
+		}
+
+		if devName == "root" {
+			return nil, errors.New("The root disk cannot be removed")
+		}
+
+		delete(def.Devices, devName)
+		slog.Info("Batch instance configuration removed device for instance", slog.String("location", instance.Location), slog.String("device", devName))
+
+		return starlark.None, nil
+	}
+
+	setDeviceConfigFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var devName string
+		var key string
+		var value string
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "device_name", &devName, "key", &key, "value", &value)
+		if err != nil {
+			return nil, err
+		}
+
+		dev, ok := def.Devices[devName]
+		if !ok {
+			return nil, fmt.Errorf("No device found with name %q on instance %q", devName, instance.Location)
+		}
+
+		if key == "" || key == "type" {
+			return nil, fmt.Errorf("Invalid configuration key %q for device %q", key, devName)
+		}
+
+		if value == "" {
+			delete(dev, key)
+		} else {
+			dev[key] = value
+		}
+
+		slog.Info("Batch instance configuration set device config key for instance", slog.String("location", instance.Location), slog.String("device", devName), slog.String("key", key), slog.String("value", value))
+
+		return starlark.None, nil
+	}
+
+	addProfileFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var profileName string
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "profile_name", &profileName)
+		if err != nil {
+			return nil, err
+		}
+
+		err = validate.IsAPIName(profileName, false)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid profile name %q: %w", profileName, err)
+		}
+
+		if !slices.Contains(def.Profiles, profileName) {
+			def.Profiles = append(def.Profiles, profileName)
+		}
+
+		slog.Info("Batch instance configuration added profile for instance", slog.String("location", instance.Location), slog.String("profile", profileName))
+
+		return starlark.None, nil
+	}
+
+	removeProfileFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var profileName string
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "profile_name", &profileName)
+		if err != nil {
+			return nil, err
+		}
+
+		def.Profiles = slices.DeleteFunc(def.Profiles, func(p string) bool { return p == profileName })
+		slog.Info("Batch instance configuration removed profile for instance", slog.String("location", instance.Location), slog.String("profile", profileName))
+
+		return starlark.None, nil
+	}
+
+	setNameFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var name string
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name)
+		if err != nil {
+			return nil, err
+		}
+
+		err = validate.IsHostname(name)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid instance name %q: %w", name, err)
+		}
+
+		newName = name
+		slog.Info("Batch instance configuration set name for instance", slog.String("location", instance.Location), slog.String("name", newName))
+
+		return starlark.None, nil
+	}
+
+	env := starlark.StringDict{
+		"log_info":  starlark.NewBuiltin("log_info", logFunc),
+		"log_warn":  starlark.NewBuiltin("log_warn", logFunc),
+		"log_error": starlark.NewBuiltin("log_error", logFunc),
+
+		"set_config":        starlark.NewBuiltin("set_config", setConfigFunc),
+		"add_device":        starlark.NewBuiltin("add_device", addDeviceFunc),
+		"remove_device":     starlark.NewBuiltin("remove_device", removeDeviceFunc),
+		"set_device_config": starlark.NewBuiltin("set_device_config", setDeviceConfigFunc),
+		"add_profile":       starlark.NewBuiltin("add_profile", addProfileFunc),
+		"remove_profile":    starlark.NewBuiltin("remove_profile", removeProfileFunc),
+		"set_name":          starlark.NewBuiltin("set_name", setNameFunc),
+	}
+
+	prog, thread, err := BatchInstanceConfigProgram(loader, batch.Name)
+	if err != nil {
+		return "", err
+	}
+
+	go func() {
+		<-ctx.Done()
+		thread.Cancel("Request finished")
+	}()
+
+	globals, err := prog.Init(thread, env)
+	if err != nil {
+		return "", fmt.Errorf("Failed initializing: %w", err)
+	}
+
+	globals.Freeze()
+
+	// Retrieve a global variable from starlark environment.
+	instanceConfig := globals[batchInstanceConfigFunc]
+	if instanceConfig == nil {
+		return "", fmt.Errorf("Scriptlet missing %q function", batchInstanceConfigFunc)
+	}
+
+	instv, err := scriptlet.StarlarkMarshal(instance)
+	if err != nil {
+		return "", fmt.Errorf("Marshalling request failed: %w", err)
+	}
+
+	batchv, err := scriptlet.StarlarkMarshal(batch)
+	if err != nil {
+		return "", fmt.Errorf("Marshalling request failed: %w", err)
+	}
+
+	netv, err := scriptlet.StarlarkMarshal(usedNetworks)
+	if err != nil {
+		return "", fmt.Errorf("Marshalling request failed: %w", err)
+	}
+
+	v, err := starlark.Call(thread, instanceConfig, nil, []starlark.Tuple{
+		{starlark.String("instance"), instv},
+		{starlark.String("batch"), batchv},
+		{starlark.String("networks"), netv},
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed to run: %w", err)
+	}
+
+	if v.Type() != "NoneType" {
+		return "", fmt.Errorf("Failed with unexpected return value: %v", v)
+	}
+
+	return newName, nil
+}
@@ -44,3 +44,45 @@ func BatchPlacementProgram(loader *scriptlet.Loader, batchName string) (*starlar
 func BatchPlacementSet(loader *scriptlet.Loader, src string, batchName string) error {
 	return loader.Set(BatchPlacementCompile, BatchPlacement(batchName), src)
 }
+
+const batchInstanceConfigFunc = "instance_config"
+
+// BatchInstanceConfig is the name used in Starlark for the batch instance configuration scriptlet.
+func BatchInstanceConfig(batchName string) string {
+	return batchName + "_" + batchInstanceConfigFunc
+}
+
+// BatchInstanceConfigValidate validates the batch instance configuration scriptlet.
+func BatchInstanceConfigValidate(src string, batchName string) error {
+	return scriptlet.Validate(BatchInstanceConfigCompile, BatchInstanceConfig(batchName), src, scriptlet.Declaration{
+		scriptlet.Required(batchInstanceConfigFunc): {"instance", "batch", "networks"},
+	})
+}
+
+// BatchInstanceConfigCompile compiles the batch instance configuration scriptlet.
+func BatchInstanceConfigCompile(name string, src string) (*starlark.Program, error) {
+	return scriptlet.Compile(name, src, []string{
+		"log_info",
+		"log_warn",
+		"log_error",
+
+		"set_config",
+		"add_device",
+		"remove_device",
+		"set_device_config",
+		"add_profile",
+		"remove_profile",
+		"set_name",
+	})
+}
+
+// BatchInstanceConfigProgram returns the precompiled batch instance configuration scriptlet program.
+func BatchInstanceConfigProgram(loader *scriptlet.Loader, batchName string) (*starlark.Program, *starlark.Thread, error) {
+	return loader.Program("Batch instance configuration", BatchInstanceConfig(batchName))
+}
+
+// BatchInstanceConfigSet compiles the batch instance configuration scriptlet into memory for use with BatchInstanceConfigRun.
+// If empty src is provided the current program is deleted.
+func BatchInstanceConfigSet(loader *scriptlet.Loader, src string, batchName string) error {
+	return loader.Set(BatchInstanceConfigCompile, BatchInstanceConfig(batchName), src)
+}
@@ -33,8 +33,9 @@ type authorizer interface {
 }
 
 // PermissionChecker is a type alias for a function that returns whether a user has required permissions on an object.
+// The parents of the object are the objects it inherits entitlements from, e.g. the target of a batch.
 // It is returned by Authorizer.GetPermissionChecker.
-type PermissionChecker func(object Object) bool
+type PermissionChecker func(object Object, parents ...Object) bool
 
 // Authorizer is the primary external API for this package.
 type Authorizer interface {
@@ -42,7 +43,8 @@ type Authorizer interface {
 	StopService(ctx context.Context) error
 	SetLogger(log *slog.Logger)
 
-	CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement) error
+	CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement, parents ...Object) error
+	GetPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, objectType ObjectType) (PermissionChecker, error)
 }
 
 // Opts is used as part of the LoadAuthorizer function so that only the relevant configuration fields are passed into a
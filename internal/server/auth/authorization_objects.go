@@ -58,8 +58,14 @@ type objectValidator struct {
 }
 
 var objectValidators = map[ObjectType]objectValidator{
-	ObjectTypeUser:   {minIdentifierElements: 1, maxIdentifierElements: 1},
-	ObjectTypeServer: {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeUser:     {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeServer:   {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeSource:   {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeTarget:   {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeProject:  {minIdentifierElements: 2, maxIdentifierElements: 2},
+	ObjectTypeBatch:    {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeInstance: {minIdentifierElements: 1, maxIdentifierElements: 1},
+	ObjectTypeArtifact: {minIdentifierElements: 1, maxIdentifierElements: 1},
 }
 
 // NewObject returns an Object of the given type. The passed in arguments must be in the correct
@@ -105,6 +111,42 @@ func ObjectServer() Object {
 	return object
 }
 
+// ObjectSource represents a source.
+func ObjectSource(sourceName string) Object {
+	object, _ := NewObject(ObjectTypeSource, sourceName)
+	return object
+}
+
+// ObjectTarget represents a target.
+func ObjectTarget(targetName string) Object {
+	object, _ := NewObject(ObjectTypeTarget, targetName)
+	return object
+}
+
+// ObjectProject represents a project on a target.
+func ObjectProject(targetName string, projectName string) Object {
+	object, _ := NewObject(ObjectTypeProject, targetName, projectName)
+	return object
+}
+
+// ObjectBatch represents a batch.
+func ObjectBatch(batchName string) Object {
+	object, _ := NewObject(ObjectTypeBatch, batchName)
+	return object
+}
+
+// ObjectInstance represents an instance.
+func ObjectInstance(instanceUUID string) Object {
+	object, _ := NewObject(ObjectTypeInstance, instanceUUID)
+	return object
+}
+
+// ObjectArtifact represents an artifact.
+func ObjectArtifact(artifactUUID string) Object {
+	object, _ := NewObject(ObjectTypeArtifact, artifactUUID)
+	return object
+}
+
 // escape escapes only the forward slash character as this is used as a delimiter. Everything else is allowed.
 func escape(s string) string {
 	return strings.ReplaceAll(s, "/", "%2F")
@@ -27,3 +27,31 @@ func (s *objectSuite) TestObjectUser() {
 		s.Equal("user:username", string(o))
 	})
 }
+
+func (s *objectSuite) TestObjectProject() {
+	s.NotPanics(func() {
+		o := ObjectProject("tgt", "my/project")
+		s.Equal("project:tgt/my%2Fproject", string(o))
+		s.Equal(ObjectTypeProject, o.Type())
+		s.Equal([]string{"tgt", "my/project"}, o.Elements())
+	})
+}
+
+func (s *objectSuite) TestObjectBatch() {
+	s.NotPanics(func() {
+		o := ObjectBatch("b1")
+		s.Equal("batch:b1", string(o))
+		s.Equal(ObjectTypeBatch, o.Type())
+	})
+}
+
+func (s *objectSuite) TestNewObject() {
+	_, err := NewObject(ObjectTypeProject, "tgt")
+	s.Error(err)
+
+	_, err = NewObject(ObjectTypeInstance, "a", "b")
+	s.Error(err)
+
+	_, err = NewObject(ObjectType("unknown"), "a")
+	s.Error(err)
+}
@@ -20,4 +20,22 @@ const (
 
 	// ObjectTypeServer represents a server.
 	ObjectTypeServer ObjectType = "server"
+
+	// ObjectTypeSource represents a source.
+	ObjectTypeSource ObjectType = "source"
+
+	// ObjectTypeTarget represents a target.
+	ObjectTypeTarget ObjectType = "target"
+
+	// ObjectTypeProject represents a project on a target.
+	ObjectTypeProject ObjectType = "project"
+
+	// ObjectTypeBatch represents a batch.
+	ObjectTypeBatch ObjectType = "batch"
+
+	// ObjectTypeInstance represents an instance, along with its queue entry.
+	ObjectTypeInstance ObjectType = "instance"
+
+	// ObjectTypeArtifact represents an artifact.
+	ObjectTypeArtifact ObjectType = "artifact"
 )
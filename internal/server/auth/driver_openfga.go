@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	"sync"
 	"time"
@@ -189,13 +190,66 @@ func (f *FGA) connect(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+
+		return nil
+	}
+
+	// Upgrade the model if it doesn't match the built in model, e.g. after new object types have been added.
+	outdated, err := modelOutdated(*readModelResponse.AuthorizationModel)
+	if err != nil {
+		return err
+	}
+
+	if outdated {
+		slog.Info("Upgrade OpenFGA model")
+
+		err := f.refreshModel(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to upgrade model: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// modelOutdated returns whether the relations of the given authorization model differ from the built in model.
+func modelOutdated(current openfga.AuthorizationModel) (bool, error) {
+	var builtin openfga.AuthorizationModel
+	err := json.Unmarshal([]byte(authModel), &builtin)
+	if err != nil {
+		return false, fmt.Errorf("Failed to unmarshal built in authorization model: %w", err)
+	}
+
+	relations := func(model openfga.AuthorizationModel) (map[string]string, error) {
+		result := make(map[string]string, len(model.TypeDefinitions))
+		for _, typeDef := range model.TypeDefinitions {
+			b, err := json.Marshal(typeDef.Relations)
+			if err != nil {
+				return nil, err
+			}
+
+			result[typeDef.Type] = string(b)
+		}
+
+		return result, nil
+	}
+
+	builtinRelations, err := relations(builtin)
+	if err != nil {
+		return false, err
+	}
+
+	currentRelations, err := relations(current)
+	if err != nil {
+		return false, err
+	}
+
+	return !maps.Equal(builtinRelations, currentRelations), nil
+}
+
 // CheckPermission returns an error if the user does not have the given Entitlement on the given Object.
-func (f *FGA) CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement) error {
+// The parents of the object are passed to OpenFGA as contextual tuples, so entitlements granted on them are inherited.
+func (f *FGA) CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement, parents ...Object) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -211,37 +265,174 @@ func (f *FGA) CheckPermission(ctx context.Context, r *http.Request, object Objec
 
 	// Use the TLS driver if the user authenticated with TLS.
 	if details.Protocol == api.AuthenticationMethodTLS {
-		return f.tls.CheckPermission(ctx, r, object, entitlement)
+		return f.tls.CheckPermission(ctx, r, object, entitlement, parents...)
 	}
 
 	// If offline, return a clear error to the user.
-	f.onlineMu.Lock()
-	online := f.online
-	defer f.onlineMu.Unlock()
-	if !online {
+	if !f.isOnline() {
 		return api.StatusErrorf(http.StatusForbidden, "The authorization server is currently offline, please try again later")
 	}
 
-	username := details.Username
+	slog.Debug("Checking OpenFGA relation", slog.Any("object", object), slog.Any("entitlement", entitlement), slog.String("url", r.URL.String()), slog.String("method", r.Method), slog.String("username", details.Username), slog.String("protocol", details.Protocol))
+	allowed, err := f.check(ctx, details.Username, object, entitlement, parents)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+	}
+
+	return nil
+}
+
+// entitlementRelations maps each Entitlement to the relation it is inherited through from parent objects.
+var entitlementRelations = map[Entitlement]string{
+	EntitlementCanCreate: "admin",
+	EntitlementCanDelete: "admin",
+	EntitlementCanEdit:   "operator",
+	EntitlementCanView:   "viewer",
+}
+
+// GetPermissionChecker returns a PermissionChecker which checks the given Entitlement on objects of the given ObjectType.
+// The objects the user is entitled to are listed when the PermissionChecker is created, so it should only be used for a single request.
+func (f *FGA) GetPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, objectType ObjectType) (PermissionChecker, error) {
+	allowAll := func(Object, ...Object) bool { return true }
+
+	details, err := f.requestDetails(r)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusForbidden, "Failed to extract request details: %v", err)
+	}
+
+	// Always allow full access via local unix socket.
+	if details.Protocol == "unix" {
+		return allowAll, nil
+	}
 
-	objectUser := ObjectUser(username)
+	// Use the TLS driver if the user authenticated with TLS.
+	if details.Protocol == api.AuthenticationMethodTLS {
+		return f.tls.GetPermissionChecker(ctx, r, entitlement, objectType)
+	}
+
+	// If offline, return a clear error to the user.
+	if !f.isOnline() {
+		return nil, api.StatusErrorf(http.StatusForbidden, "The authorization server is currently offline, please try again later")
+	}
+
+	// Entitlements on the server apply to every object.
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	allowed, err := f.check(checkCtx, details.Username, ObjectServer(), entitlement, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowed {
+		return allowAll, nil
+	}
+
+	// List the objects the user is entitled to once, instead of checking every object on its own.
+	objects, err := f.listObjects(ctx, details.Username, string(entitlement), objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parent objects are listed on first use, with the relation the entitlement is inherited through.
+	parentObjects := map[ObjectType]map[Object]bool{}
+	return func(object Object, parents ...Object) bool {
+		if object.Type() != objectType {
+			return false
+		}
+
+		if objects[object] {
+			return true
+		}
+
+		for _, parent := range parents {
+			listed, ok := parentObjects[parent.Type()]
+			if !ok {
+				var err error
+				listed, err = f.listObjects(ctx, details.Username, entitlementRelations[entitlement], parent.Type())
+				if err != nil {
+					f.logger.Error("Failed to list OpenFGA objects", slog.Any("type", parent.Type()), slog.Any("entitlement", entitlement), logger.Err(err))
+					listed = map[Object]bool{}
+				}
+
+				parentObjects[parent.Type()] = listed
+			}
+
+			if listed[parent] {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+func (f *FGA) isOnline() bool {
+	f.onlineMu.Lock()
+	defer f.onlineMu.Unlock()
+
+	return f.online
+}
+
+// check returns whether the user has the given Entitlement on the given Object.
+func (f *FGA) check(ctx context.Context, username string, object Object, entitlement Entitlement, parents []Object) (bool, error) {
 	body := client.ClientCheckRequest{
-		User:     objectUser.String(),
-		Relation: string(entitlement),
-		Object:   object.String(),
+		User:             ObjectUser(username).String(),
+		Relation:         string(entitlement),
+		Object:           object.String(),
+		ContextualTuples: contextualTuples(object, parents),
 	}
 
-	slog.Debug("Checking OpenFGA relation", slog.Any("object", object), slog.Any("entitlement", entitlement), slog.String("url", r.URL.String()), slog.String("method", r.Method), slog.String("username", username), slog.String("protocol", details.Protocol))
 	resp, err := f.client.Check(ctx).Body(body).Execute()
 	if err != nil {
-		return fmt.Errorf("Failed to check OpenFGA relation: %w", err)
+		return false, fmt.Errorf("Failed to check OpenFGA relation: %w", err)
 	}
 
-	if !resp.GetAllowed() {
-		return api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+	return resp.GetAllowed(), nil
+}
+
+// listObjects returns the objects of the given ObjectType on which the user has the given relation.
+func (f *FGA) listObjects(ctx context.Context, username string, relation string, objectType ObjectType) (map[Object]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	body := client.ClientListObjectsRequest{
+		User:     ObjectUser(username).String(),
+		Relation: relation,
+		Type:     string(objectType),
 	}
 
-	return nil
+	resp, err := f.client.ListObjects(ctx).Body(body).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list OpenFGA objects: %w", err)
+	}
+
+	objects := make(map[Object]bool, len(resp.GetObjects()))
+	for _, object := range resp.GetObjects() {
+		objects[Object(object)] = true
+	}
+
+	return objects, nil
+}
+
+// contextualTuples links the object to the server and to its parent objects, using the object type of the parent as relation.
+// This way entitlements are inherited without having to store a tuple in OpenFGA for every object.
+func contextualTuples(object Object, parents []Object) []client.ClientContextualTupleKey {
+	if object.Type() == ObjectTypeServer {
+		return nil
+	}
+
+	tuples := make([]client.ClientContextualTupleKey, 0, len(parents)+1)
+	tuples = append(tuples, client.ClientContextualTupleKey{User: ObjectServer().String(), Relation: string(ObjectTypeServer), Object: object.String()})
+	for _, parent := range parents {
+		tuples = append(tuples, client.ClientContextualTupleKey{User: parent.String(), Relation: string(parent.Type()), Object: object.String()})
+	}
+
+	return tuples
 }
 
 // sendTuples directly sends the write/deletion tuples to OpenFGA.
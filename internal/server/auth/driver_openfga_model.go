@@ -2,4 +2,4 @@ package auth
 
 // Code generated by Makefile; DO NOT EDIT.
 
-var authModel = `{"schema_version":"1.1","type_definitions":[{"type":"user"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"}]},"authenticated":{"directly_related_user_types":[{"type":"user","wildcard":{}}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"}]},"user":{"directly_related_user_types":[{"type":"user"}]},"viewer":{"directly_related_user_types":[{"type":"user"}]}}},"relations":{"admin":{"this":{}},"authenticated":{"this":{}},"can_create":{"computedUserset":{"relation":"admin"}},"can_delete":{"computedUserset":{"relation":"admin"}},"can_edit":{"computedUserset":{"relation":"operator"}},"can_view":{"computedUserset":{"relation":"viewer"}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}}]}},"user":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}}]}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"user"}}]}}},"type":"server"}]}`
+var authModel = `{"schema_version":"1.1","type_definitions":[{"type":"user"},{"metadata":{"relations":{"member":{"directly_related_user_types":[{"type":"user"}]}}},"relations":{"member":{"this":{}}},"type":"group"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"authenticated":{"directly_related_user_types":[{"type":"user","wildcard":{}}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"user":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"this":{}},"authenticated":{"this":{}},"can_create":{"computedUserset":{"relation":"admin"}},"can_delete":{"computedUserset":{"relation":"admin"}},"can_edit":{"computedUserset":{"relation":"operator"}},"can_view":{"computedUserset":{"relation":"viewer"}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}}]}},"user":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}}]}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"user"}}]}}},"type":"server"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"server":{"directly_related_user_types":[{"type":"server"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"this":{}},"can_create":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_create"},"tupleset":{"relation":"server"}}}]}},"can_delete":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_delete"},"tupleset":{"relation":"server"}}}]}},"can_edit":{"union":{"child":[{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"can_edit"},"tupleset":{"relation":"server"}}}]}},"can_view":{"union":{"child":[{"computedUserset":{"relation":"viewer"}},{"tupleToUserset":{"computedUserset":{"relation":"can_view"},"tupleset":{"relation":"server"}}}]}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}}]}},"server":{"this":{}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}}]}}},"type":"source"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"server":{"directly_related_user_types":[{"type":"server"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"this":{}},"can_create":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_create"},"tupleset":{"relation":"server"}}}]}},"can_delete":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_delete"},"tupleset":{"relation":"server"}}}]}},"can_edit":{"union":{"child":[{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"can_edit"},"tupleset":{"relation":"server"}}}]}},"can_view":{"union":{"child":[{"computedUserset":{"relation":"viewer"}},{"tupleToUserset":{"computedUserset":{"relation":"can_view"},"tupleset":{"relation":"server"}}}]}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}}]}},"server":{"this":{}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}}]}}},"type":"target"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"this":{}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}}]}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}}]}}},"type":"project"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"project":{"directly_related_user_types":[{"type":"project"}]},"server":{"directly_related_user_types":[{"type":"server"}]},"target":{"directly_related_user_types":[{"type":"target"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"union":{"child":[{"this":{}},{"tupleToUserset":{"computedUserset":{"relation":"admin"},"tupleset":{"relation":"target"}}},{"tupleToUserset":{"computedUserset":{"relation":"admin"},"tupleset":{"relation":"project"}}}]}},"can_create":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_create"},"tupleset":{"relation":"server"}}}]}},"can_delete":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_delete"},"tupleset":{"relation":"server"}}}]}},"can_edit":{"union":{"child":[{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"can_edit"},"tupleset":{"relation":"server"}}}]}},"can_view":{"union":{"child":[{"computedUserset":{"relation":"viewer"}},{"tupleToUserset":{"computedUserset":{"relation":"can_view"},"tupleset":{"relation":"server"}}}]}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"operator"},"tupleset":{"relation":"target"}}},{"tupleToUserset":{"computedUserset":{"relation":"operator"},"tupleset":{"relation":"project"}}}]}},"project":{"this":{}},"server":{"this":{}},"target":{"this":{}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"viewer"},"tupleset":{"relation":"target"}}},{"tupleToUserset":{"computedUserset":{"relation":"viewer"},"tupleset":{"relation":"project"}}}]}}},"type":"batch"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"project":{"directly_related_user_types":[{"type":"project"}]},"server":{"directly_related_user_types":[{"type":"server"}]},"source":{"directly_related_user_types":[{"type":"source"}]},"target":{"directly_related_user_types":[{"type":"target"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"union":{"child":[{"this":{}},{"tupleToUserset":{"computedUserset":{"relation":"admin"},"tupleset":{"relation":"source"}}},{"tupleToUserset":{"computedUserset":{"relation":"admin"},"tupleset":{"relation":"target"}}},{"tupleToUserset":{"computedUserset":{"relation":"admin"},"tupleset":{"relation":"project"}}}]}},"can_create":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_create"},"tupleset":{"relation":"server"}}}]}},"can_delete":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_delete"},"tupleset":{"relation":"server"}}}]}},"can_edit":{"union":{"child":[{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"can_edit"},"tupleset":{"relation":"server"}}}]}},"can_view":{"union":{"child":[{"computedUserset":{"relation":"viewer"}},{"tupleToUserset":{"computedUserset":{"relation":"can_view"},"tupleset":{"relation":"server"}}}]}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"operator"},"tupleset":{"relation":"source"}}},{"tupleToUserset":{"computedUserset":{"relation":"operator"},"tupleset":{"relation":"target"}}},{"tupleToUserset":{"computedUserset":{"relation":"operator"},"tupleset":{"relation":"project"}}}]}},"project":{"this":{}},"server":{"this":{}},"source":{"this":{}},"target":{"this":{}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"viewer"},"tupleset":{"relation":"source"}}},{"tupleToUserset":{"computedUserset":{"relation":"viewer"},"tupleset":{"relation":"target"}}},{"tupleToUserset":{"computedUserset":{"relation":"viewer"},"tupleset":{"relation":"project"}}}]}}},"type":"instance"},{"metadata":{"relations":{"admin":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"can_create":{},"can_delete":{},"can_edit":{},"can_view":{},"operator":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]},"server":{"directly_related_user_types":[{"type":"server"}]},"viewer":{"directly_related_user_types":[{"type":"user"},{"relation":"member","type":"group"}]}}},"relations":{"admin":{"this":{}},"can_create":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_create"},"tupleset":{"relation":"server"}}}]}},"can_delete":{"union":{"child":[{"computedUserset":{"relation":"admin"}},{"tupleToUserset":{"computedUserset":{"relation":"can_delete"},"tupleset":{"relation":"server"}}}]}},"can_edit":{"union":{"child":[{"computedUserset":{"relation":"operator"}},{"tupleToUserset":{"computedUserset":{"relation":"can_edit"},"tupleset":{"relation":"server"}}}]}},"can_view":{"union":{"child":[{"computedUserset":{"relation":"viewer"}},{"tupleToUserset":{"computedUserset":{"relation":"can_view"},"tupleset":{"relation":"server"}}}]}},"operator":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"admin"}}]}},"server":{"this":{}},"viewer":{"union":{"child":[{"this":{}},{"computedUserset":{"relation":"operator"}}]}}},"type":"artifact"}]}`
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+
+	openfga "github.com/openfga/go-sdk"
+	"github.com/openfga/go-sdk/client"
+	"github.com/stretchr/testify/suite"
+)
+
+type fgaSuite struct {
+	suite.Suite
+}
+
+func TestFGASuite(t *testing.T) {
+	suite.Run(t, new(fgaSuite))
+}
+
+func (s *fgaSuite) TestAuthModel() {
+	var model openfga.AuthorizationModel
+	s.Require().NoError(json.Unmarshal([]byte(authModel), &model))
+
+	types := map[string]openfga.TypeDefinition{}
+	for _, typeDef := range model.TypeDefinitions {
+		types[typeDef.Type] = typeDef
+	}
+
+	// Every object type that can be checked needs the entitlements and the server relation for inheriting from the server.
+	for objectType := range objectValidators {
+		typeDef, ok := types[string(objectType)]
+		s.Require().True(ok, "Missing type %q in authorization model", objectType)
+		if objectType == ObjectTypeUser {
+			continue
+		}
+
+		for _, entitlement := range []Entitlement{EntitlementCanCreate, EntitlementCanDelete, EntitlementCanEdit, EntitlementCanView} {
+			if objectType == ObjectTypeProject {
+				break
+			}
+
+			s.Contains(*typeDef.Relations, string(entitlement), "Missing entitlement %q on type %q", entitlement, objectType)
+		}
+
+		if objectType != ObjectTypeServer && objectType != ObjectTypeProject {
+			s.Contains(*typeDef.Relations, string(ObjectTypeServer), "Missing server relation on type %q", objectType)
+		}
+	}
+
+	// Entitlements are inherited from parent objects through their relations, which every parent type needs.
+	for _, parentType := range []ObjectType{ObjectTypeSource, ObjectTypeTarget, ObjectTypeProject} {
+		for entitlement, relation := range entitlementRelations {
+			s.Contains(*types[string(parentType)].Relations, relation, "Missing relation %q for entitlement %q on type %q", relation, entitlement, parentType)
+		}
+	}
+
+	outdated, err := modelOutdated(model)
+	s.NoError(err)
+	s.False(outdated)
+
+	delete(*types[string(ObjectTypeBatch)].Relations, "project")
+	outdated, err = modelOutdated(model)
+	s.NoError(err)
+	s.True(outdated)
+}
+
+func (s *fgaSuite) TestContextualTuples() {
+	s.Nil(contextualTuples(ObjectServer(), nil))
+
+	s.Equal([]client.ClientContextualTupleKey{
+		{User: "server:migration-manager", Relation: "server", Object: "batch:b1"},
+		{User: "target:tgt", Relation: "target", Object: "batch:b1"},
+		{User: "project:tgt/default", Relation: "project", Object: "batch:b1"},
+	}, contextualTuples(ObjectBatch("b1"), []Object{ObjectTarget("tgt"), ObjectProject("tgt", "default")}))
+}
@@ -21,7 +21,8 @@ func (t *TLS) load(ctx context.Context, certificateFingerprints []string, opts O
 }
 
 // CheckPermission returns an error if the user does not have the given Entitlement on the given Object.
-func (t *TLS) CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement) error {
+// TLS authorization is all-or-nothing, so the object and its parents are not considered.
+func (t *TLS) CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement, parents ...Object) error {
 	details, err := t.requestDetails(r)
 	if err != nil {
 		return api.StatusErrorf(http.StatusForbidden, "Failed to extract request details: %v", err)
@@ -54,3 +55,20 @@ func (t *TLS) CheckPermission(ctx context.Context, r *http.Request, object Objec
 
 	return api.StatusErrorf(http.StatusForbidden, "Client certificate not found")
 }
+
+// GetPermissionChecker returns a PermissionChecker which allows either all or none of the objects, depending on whether the client is trusted.
+func (t *TLS) GetPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, objectType ObjectType) (PermissionChecker, error) {
+	allowed := true
+	err := t.CheckPermission(ctx, r, ObjectServer(), entitlement)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusForbidden) {
+			return nil, err
+		}
+
+		allowed = false
+	}
+
+	return func(Object, ...Object) bool {
+		return allowed
+	}, nil
+}
@@ -0,0 +1,135 @@
+package sys
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// instanceSecret is the on-disk representation of the secrets belonging to an instance.
+type instanceSecret struct {
+	BitLocker *api.BitLockerKey `json:"bitlocker,omitempty"`
+}
+
+func instanceSecretFile(instanceUUID uuid.UUID) string {
+	return instanceUUID.String() + ".json"
+}
+
+func (s *OS) instanceSecretPath(instanceUUID uuid.UUID) string {
+	return filepath.Join(s.SecretDir, instanceSecretFile(instanceUUID))
+}
+
+// LoadBitLockerKey returns the stored BitLocker key for the instance, or nil if there is none.
+func (s *OS) LoadBitLockerKey(instanceUUID uuid.UUID) (*api.BitLockerKey, error) {
+	b, err := os.ReadFile(s.instanceSecretPath(instanceUUID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read secrets for instance %q: %w", instanceUUID, err)
+	}
+
+	var secret instanceSecret
+	err = json.Unmarshal(b, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse secrets for instance %q: %w", instanceUUID, err)
+	}
+
+	return secret.BitLocker, nil
+}
+
+// SaveBitLockerKey stores the BitLocker key for the instance, readable only by the daemon.
+func (s *OS) SaveBitLockerKey(instanceUUID uuid.UUID, key api.BitLockerKey) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	err := os.MkdirAll(s.SecretDir, 0o700)
+	if err != nil {
+		return fmt.Errorf("Failed to create directory %q: %w", s.SecretDir, err)
+	}
+
+	b, err := json.Marshal(instanceSecret{BitLocker: &key})
+	if err != nil {
+		return err
+	}
+
+	secretPath := s.instanceSecretPath(instanceUUID)
+	partPath := secretPath + ".part"
+	err = os.WriteFile(partPath, b, 0o600)
+	if err != nil {
+		_ = os.Remove(partPath)
+		return fmt.Errorf("Failed to write secrets for instance %q: %w", instanceUUID, err)
+	}
+
+	err = os.Rename(partPath, secretPath)
+	if err != nil {
+		_ = os.Remove(partPath)
+		return fmt.Errorf("Failed to commit secrets for instance %q: %w", instanceUUID, err)
+	}
+
+	return nil
+}
+
+// DeleteBitLockerKey removes any stored BitLocker key for the instance.
+func (s *OS) DeleteBitLockerKey(instanceUUID uuid.UUID) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	err := os.Remove(s.instanceSecretPath(instanceUUID))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to delete secrets for instance %q: %w", instanceUUID, err)
+	}
+
+	return nil
+}
+
+// HasInstanceSecret returns whether secrets are stored for the instance in the given secret directory.
+func HasInstanceSecret(secretDir string, instanceUUID uuid.UUID) (bool, error) {
+	_, err := os.Stat(filepath.Join(secretDir, instanceSecretFile(instanceUUID)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("Failed to check secrets for instance %q: %w", instanceUUID, err)
+	}
+
+	return true, nil
+}
+
+// PruneInstanceSecrets removes the stored secrets of all instances not in the given list.
+func (s *OS) PruneInstanceSecrets(instanceUUIDs []uuid.UUID) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	entries, err := os.ReadDir(s.SecretDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed to read directory %q: %w", s.SecretDir, err)
+	}
+
+	for _, e := range entries {
+		instanceUUID, err := uuid.Parse(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil || slices.Contains(instanceUUIDs, instanceUUID) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(s.SecretDir, e.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to delete secrets for instance %q: %w", instanceUUID, err)
+		}
+	}
+
+	return nil
+}
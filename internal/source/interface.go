@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
 	"github.com/FuturFusion/migration-manager/internal/migration"
 	"github.com/FuturFusion/migration-manager/shared/api"
 )
@@ -62,7 +63,14 @@ type Source interface {
 	// directly write to raw disk devices, overwriting any data that might already be present.
 	//
 	// Returns an error if there is a problem importing the disk(s).
-	ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, statusCallback func(string, bool)) error
+	ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error
+
+	// Compares the checksums of the source disks against the disks of the locally running VM they were imported to.
+	//
+	// Important: This should only be called from the migration manager worker, after the final disk import.
+	//
+	// Returns the verification result of each disk, or an error if the disks could not be read.
+	VerifyDisks(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error)
 
 	// IsRunning returns whether the VM is running.
 	IsRunning(ctx context.Context, vmName string) (bool, error)
@@ -80,6 +88,11 @@ type Source interface {
 	// Dump source data.
 	Dump(ctx context.Context) error
 
+	// Waits for VMs to change on the source, and calls the given function with the source-specific IDs of the changed VMs.
+	//
+	// Blocks until the context is cancelled, or returns an error if the connection fails or the source does not support watching.
+	WatchVMs(ctx context.Context, f func(sourceSpecificIDs []string)) error
+
 	// -----------------------------------------------
 
 	// VerifyBackgroundImport checks each supported disk for each VM to verify whether background import is supported, returning the list of UUIDs that fail the check.
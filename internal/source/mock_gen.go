@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
 	"github.com/FuturFusion/migration-manager/internal/migration"
 	"github.com/FuturFusion/migration-manager/shared/api"
 	"github.com/google/uuid"
@@ -51,7 +52,7 @@ var _ Source = &SourceMock{}
 //			GetNameFunc: func() string {
 //				panic("mock out the GetName method")
 //			},
-//			ImportDisksFunc: func(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, statusCallback func(string, bool)) error {
+//			ImportDisksFunc: func(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 //				panic("mock out the ImportDisks method")
 //			},
 //			IsConnectedFunc: func() bool {
@@ -72,6 +73,12 @@ var _ Source = &SourceMock{}
 //			VerifyBackgroundImportFunc: func(ctx context.Context, instances migration.Instances) (migration.Instances, error) {
 //				panic("mock out the VerifyBackgroundImport method")
 //			},
+//			VerifyDisksFunc: func(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error) {
+//				panic("mock out the VerifyDisks method")
+//			},
+//			WatchVMsFunc: func(ctx context.Context, f func(sourceSpecificIDs []string)) error {
+//				panic("mock out the WatchVMs method")
+//			},
 //			WithAdditionalRootCertificateFunc: func(rootCert *x509.Certificate)  {
 //				panic("mock out the WithAdditionalRootCertificate method")
 //			},
@@ -110,7 +117,7 @@ type SourceMock struct {
 	GetNameFunc func() string
 
 	// ImportDisksFunc mocks the ImportDisks method.
-	ImportDisksFunc func(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, statusCallback func(string, bool)) error
+	ImportDisksFunc func(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error
 
 	// IsConnectedFunc mocks the IsConnected method.
 	IsConnectedFunc func() bool
@@ -130,6 +137,12 @@ type SourceMock struct {
 	// VerifyBackgroundImportFunc mocks the VerifyBackgroundImport method.
 	VerifyBackgroundImportFunc func(ctx context.Context, instances migration.Instances) (migration.Instances, error)
 
+	// VerifyDisksFunc mocks the VerifyDisks method.
+	VerifyDisksFunc func(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error)
+
+	// WatchVMsFunc mocks the WatchVMs method.
+	WatchVMsFunc func(ctx context.Context, f func(sourceSpecificIDs []string)) error
+
 	// WithAdditionalRootCertificateFunc mocks the WithAdditionalRootCertificate method.
 	WithAdditionalRootCertificateFunc func(rootCert *x509.Certificate)
 
@@ -196,8 +209,12 @@ type SourceMock struct {
 			SdkPath string
 			// Disks is the disks argument value.
 			Disks []api.InstancePropertiesDisk
+			// ChangeIDs is the changeIDs argument value.
+			ChangeIDs target.ChangeIDStore
 			// StatusCallback is the statusCallback argument value.
 			StatusCallback func(string, bool)
+			// ProgressCallback is the progressCallback argument value.
+			ProgressCallback func(string, int64, int64)
 		}
 		// IsConnected holds details about calls to the IsConnected method.
 		IsConnected []struct {
@@ -233,6 +250,26 @@ type SourceMock struct {
 			// Instances is the instances argument value.
 			Instances migration.Instances
 		}
+		// VerifyDisks holds details about calls to the VerifyDisks method.
+		VerifyDisks []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// VmName is the vmName argument value.
+			VmName string
+			// SdkPath is the sdkPath argument value.
+			SdkPath string
+			// Mode is the mode argument value.
+			Mode api.DiskVerificationMode
+			// StatusCallback is the statusCallback argument value.
+			StatusCallback func(string, bool)
+		}
+		// WatchVMs holds details about calls to the WatchVMs method.
+		WatchVMs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F func(sourceSpecificIDs []string)
+		}
 		// WithAdditionalRootCertificate holds details about calls to the WithAdditionalRootCertificate method.
 		WithAdditionalRootCertificate []struct {
 			// RootCert is the rootCert argument value.
@@ -255,6 +292,8 @@ type SourceMock struct {
 	lockPowerOnVM                     sync.RWMutex
 	lockTimeout                       sync.RWMutex
 	lockVerifyBackgroundImport        sync.RWMutex
+	lockVerifyDisks                   sync.RWMutex
+	lockWatchVMs                      sync.RWMutex
 	lockWithAdditionalRootCertificate sync.RWMutex
 }
 
@@ -557,27 +596,31 @@ func (mock *SourceMock) GetNameCalls() []struct {
 }
 
 // ImportDisks calls ImportDisksFunc.
-func (mock *SourceMock) ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, statusCallback func(string, bool)) error {
+func (mock *SourceMock) ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 	if mock.ImportDisksFunc == nil {
 		panic("SourceMock.ImportDisksFunc: method is nil but Source.ImportDisks was just called")
 	}
 	callInfo := struct {
-		Ctx            context.Context
-		VmName         string
-		SdkPath        string
-		Disks          []api.InstancePropertiesDisk
-		StatusCallback func(string, bool)
+		Ctx              context.Context
+		VmName           string
+		SdkPath          string
+		Disks            []api.InstancePropertiesDisk
+		ChangeIDs        target.ChangeIDStore
+		StatusCallback   func(string, bool)
+		ProgressCallback func(string, int64, int64)
 	}{
-		Ctx:            ctx,
-		VmName:         vmName,
-		SdkPath:        sdkPath,
-		Disks:          disks,
-		StatusCallback: statusCallback,
+		Ctx:              ctx,
+		VmName:           vmName,
+		SdkPath:          sdkPath,
+		Disks:            disks,
+		ChangeIDs:        changeIDs,
+		StatusCallback:   statusCallback,
+		ProgressCallback: progressCallback,
 	}
 	mock.lockImportDisks.Lock()
 	mock.calls.ImportDisks = append(mock.calls.ImportDisks, callInfo)
 	mock.lockImportDisks.Unlock()
-	return mock.ImportDisksFunc(ctx, vmName, sdkPath, disks, statusCallback)
+	return mock.ImportDisksFunc(ctx, vmName, sdkPath, disks, changeIDs, statusCallback, progressCallback)
 }
 
 // ImportDisksCalls gets all the calls that were made to ImportDisks.
@@ -585,18 +628,22 @@ func (mock *SourceMock) ImportDisks(ctx context.Context, vmName string, sdkPath
 //
 //	len(mockedSource.ImportDisksCalls())
 func (mock *SourceMock) ImportDisksCalls() []struct {
-	Ctx            context.Context
-	VmName         string
-	SdkPath        string
-	Disks          []api.InstancePropertiesDisk
-	StatusCallback func(string, bool)
+	Ctx              context.Context
+	VmName           string
+	SdkPath          string
+	Disks            []api.InstancePropertiesDisk
+	ChangeIDs        target.ChangeIDStore
+	StatusCallback   func(string, bool)
+	ProgressCallback func(string, int64, int64)
 } {
 	var calls []struct {
-		Ctx            context.Context
-		VmName         string
-		SdkPath        string
-		Disks          []api.InstancePropertiesDisk
-		StatusCallback func(string, bool)
+		Ctx              context.Context
+		VmName           string
+		SdkPath          string
+		Disks            []api.InstancePropertiesDisk
+		ChangeIDs        target.ChangeIDStore
+		StatusCallback   func(string, bool)
+		ProgressCallback func(string, int64, int64)
 	}
 	mock.lockImportDisks.RLock()
 	calls = mock.calls.ImportDisks
@@ -802,6 +849,90 @@ func (mock *SourceMock) VerifyBackgroundImportCalls() []struct {
 	return calls
 }
 
+// VerifyDisks calls VerifyDisksFunc.
+func (mock *SourceMock) VerifyDisks(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error) {
+	if mock.VerifyDisksFunc == nil {
+		panic("SourceMock.VerifyDisksFunc: method is nil but Source.VerifyDisks was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		VmName         string
+		SdkPath        string
+		Mode           api.DiskVerificationMode
+		StatusCallback func(string, bool)
+	}{
+		Ctx:            ctx,
+		VmName:         vmName,
+		SdkPath:        sdkPath,
+		Mode:           mode,
+		StatusCallback: statusCallback,
+	}
+	mock.lockVerifyDisks.Lock()
+	mock.calls.VerifyDisks = append(mock.calls.VerifyDisks, callInfo)
+	mock.lockVerifyDisks.Unlock()
+	return mock.VerifyDisksFunc(ctx, vmName, sdkPath, mode, statusCallback)
+}
+
+// VerifyDisksCalls gets all the calls that were made to VerifyDisks.
+// Check the length with:
+//
+//	len(mockedSource.VerifyDisksCalls())
+func (mock *SourceMock) VerifyDisksCalls() []struct {
+	Ctx            context.Context
+	VmName         string
+	SdkPath        string
+	Mode           api.DiskVerificationMode
+	StatusCallback func(string, bool)
+} {
+	var calls []struct {
+		Ctx            context.Context
+		VmName         string
+		SdkPath        string
+		Mode           api.DiskVerificationMode
+		StatusCallback func(string, bool)
+	}
+	mock.lockVerifyDisks.RLock()
+	calls = mock.calls.VerifyDisks
+	mock.lockVerifyDisks.RUnlock()
+	return calls
+}
+
+// WatchVMs calls WatchVMsFunc.
+func (mock *SourceMock) WatchVMs(ctx context.Context, f func(sourceSpecificIDs []string)) error {
+	if mock.WatchVMsFunc == nil {
+		panic("SourceMock.WatchVMsFunc: method is nil but Source.WatchVMs was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		F   func(sourceSpecificIDs []string)
+	}{
+		Ctx: ctx,
+		F:   f,
+	}
+	mock.lockWatchVMs.Lock()
+	mock.calls.WatchVMs = append(mock.calls.WatchVMs, callInfo)
+	mock.lockWatchVMs.Unlock()
+	return mock.WatchVMsFunc(ctx, f)
+}
+
+// WatchVMsCalls gets all the calls that were made to WatchVMs.
+// Check the length with:
+//
+//	len(mockedSource.WatchVMsCalls())
+func (mock *SourceMock) WatchVMsCalls() []struct {
+	Ctx context.Context
+	F   func(sourceSpecificIDs []string)
+} {
+	var calls []struct {
+		Ctx context.Context
+		F   func(sourceSpecificIDs []string)
+	}
+	mock.lockWatchVMs.RLock()
+	calls = mock.calls.WatchVMs
+	mock.lockWatchVMs.RUnlock()
+	return calls
+}
+
 // WithAdditionalRootCertificate calls WithAdditionalRootCertificateFunc.
 func (mock *SourceMock) WithAdditionalRootCertificate(rootCert *x509.Certificate) {
 	if mock.WithAdditionalRootCertificateFunc == nil {
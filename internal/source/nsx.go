@@ -141,7 +141,7 @@ func (s *InternalNSXSource) FetchSourceData(ctx context.Context) error {
 		return fmt.Errorf("Failed to get edge nodes for source %q: %w", s.Name, err)
 	}
 
-	policies, err := s.GetSecurityPolicies(ctx)
+	firewall, err := s.GetFirewall(ctx)
 	if err != nil {
 		return fmt.Errorf("Failed to get security policies for source %q: %w", s.Name, err)
 	}
@@ -151,7 +151,9 @@ func (s *InternalNSXSource) FetchSourceData(ctx context.Context) error {
 		ComputeManagers:  computeManagers,
 		Segments:         segments,
 		EdgeNodes:        edgeNodes,
-		Policies:         policies,
+		Policies:         firewall.Policies,
+		Groups:           firewall.Groups,
+		Services:         firewall.Services,
 	}
 
 	return nil
@@ -426,28 +428,179 @@ func (s *InternalNSXSource) GetSecurityPolicies(ctx context.Context) ([]internal
 			return nil, fmt.Errorf("Failed to get security policies for %q: %w", s.Name, err)
 		}
 
-		policy := internalAPI.NSXSecurityPolicy{Domain: obj.Name, Rules: []internalAPI.NSXRule{}}
 		for _, entry := range allResults {
-			var obj internalAPI.NSXRule
+			var policy internalAPI.NSXSecurityPolicy
 			entryJSON, err := json.Marshal(entry)
 			if err != nil {
-				return nil, fmt.Errorf("Failed to parse security policie responses for %q: %w", s.Name, err)
+				return nil, fmt.Errorf("Failed to parse security policy responses for %q: %w", s.Name, err)
 			}
 
-			err = json.Unmarshal(entryJSON, &obj)
+			err = json.Unmarshal(entryJSON, &policy)
 			if err != nil {
 				return nil, fmt.Errorf("Security policy data from %q is invalid: %w", s.Name, err)
 			}
 
-			policy.Rules = append(policy.Rules, obj)
-		}
+			policy.Domain = obj.Name
+			policy.Rules = []internalAPI.NSXRule{}
+			ruleResults, err := s.paginatedGet(ctx, "policy/api/v1"+policy.Path+"/rules")
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get security policy rules for %q: %w", s.Name, err)
+			}
+
+			for _, entry := range ruleResults {
+				var rule internalAPI.NSXRule
+				entryJSON, err := json.Marshal(entry)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to parse security policy rule responses for %q: %w", s.Name, err)
+				}
+
+				err = json.Unmarshal(entryJSON, &rule)
+				if err != nil {
+					return nil, fmt.Errorf("Security policy rule data from %q is invalid: %w", s.Name, err)
+				}
+
+				policy.Rules = append(policy.Rules, rule)
+			}
 
-		policies = append(policies, policy)
+			policies = append(policies, policy)
+		}
 	}
 
 	return policies, nil
 }
 
+// GetFirewall fetches all distributed firewall security policies, and the groups and services referenced by their rules.
+func (s *InternalNSXSource) GetFirewall(ctx context.Context) (*internalAPI.NSXDistributedFirewall, error) {
+	policies, err := s.GetSecurityPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groupPaths := []string{}
+	servicePaths := []string{}
+	for _, policy := range policies {
+		groupPaths = append(groupPaths, policy.Scope...)
+		for _, rule := range policy.Rules {
+			groupPaths = append(groupPaths, rule.Scope...)
+			groupPaths = append(groupPaths, rule.SourceGroups...)
+			groupPaths = append(groupPaths, rule.DestinationGroups...)
+			servicePaths = append(servicePaths, rule.Services...)
+		}
+	}
+
+	groups, err := s.GetGroups(ctx, groupPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := s.GetServices(ctx, servicePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return &internalAPI.NSXDistributedFirewall{Policies: policies, Groups: groups, Services: services}, nil
+}
+
+// GetGroups fetches the groups and their effective members for the given group paths. Entries that are not group paths, such as "ANY" or literal IP addresses, are ignored.
+func (s *InternalNSXSource) GetGroups(ctx context.Context, groupPaths []string) ([]internalAPI.NSXGroup, error) {
+	if !s.isConnected {
+		return nil, fmt.Errorf("Already connected to endpoint %q", s.Endpoint)
+	}
+
+	groups := []internalAPI.NSXGroup{}
+	seen := map[string]bool{}
+	for _, groupPath := range groupPaths {
+		if !strings.HasPrefix(groupPath, "/") || seen[groupPath] {
+			continue
+		}
+
+		seen[groupPath] = true
+		b, err := s.httpGet(ctx, "policy/api/v1"+groupPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get group %q for %q: %w", groupPath, s.Name, err)
+		}
+
+		var group internalAPI.NSXGroup
+		err = json.Unmarshal(b, &group)
+		if err != nil {
+			return nil, fmt.Errorf("Group data from %q is invalid: %w", s.Name, err)
+		}
+
+		allResults, err := s.paginatedGet(ctx, "policy/api/v1"+groupPath+"/members/ip-addresses")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get IP address members of group %q for %q: %w", groupPath, s.Name, err)
+		}
+
+		group.IPAddresses = []string{}
+		for _, entry := range allResults {
+			ip, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("IP address member data of group %q from %q is invalid: %v", groupPath, s.Name, entry)
+			}
+
+			group.IPAddresses = append(group.IPAddresses, ip)
+		}
+
+		allResults, err = s.paginatedGet(ctx, "policy/api/v1"+groupPath+"/members/virtual-machines")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get VM members of group %q for %q: %w", groupPath, s.Name, err)
+		}
+
+		group.VMs = []uuid.UUID{}
+		for _, entry := range allResults {
+			var member internalAPI.NSXGroupMember
+			entryJSON, err := json.Marshal(entry)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse group member responses for %q: %w", s.Name, err)
+			}
+
+			err = json.Unmarshal(entryJSON, &member)
+			if err != nil {
+				return nil, fmt.Errorf("Group member data from %q is invalid: %w", s.Name, err)
+			}
+
+			group.VMs = append(group.VMs, member.UUID)
+		}
+
+		group.Path = groupPath
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// GetServices fetches the services for the given service paths. Entries that are not service paths, such as "ANY", are ignored.
+func (s *InternalNSXSource) GetServices(ctx context.Context, servicePaths []string) ([]internalAPI.NSXService, error) {
+	if !s.isConnected {
+		return nil, fmt.Errorf("Already connected to endpoint %q", s.Endpoint)
+	}
+
+	services := []internalAPI.NSXService{}
+	seen := map[string]bool{}
+	for _, servicePath := range servicePaths {
+		if !strings.HasPrefix(servicePath, "/") || seen[servicePath] {
+			continue
+		}
+
+		seen[servicePath] = true
+		b, err := s.httpGet(ctx, "policy/api/v1"+servicePath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get service %q for %q: %w", servicePath, s.Name, err)
+		}
+
+		var service internalAPI.NSXService
+		err = json.Unmarshal(b, &service)
+		if err != nil {
+			return nil, fmt.Errorf("Service data from %q is invalid: %w", s.Name, err)
+		}
+
+		service.Path = servicePath
+		services = append(services, service)
+	}
+
+	return services, nil
+}
+
 // GetComputeManagers gets all compute managers registered with the NSX Manager.
 func (s *InternalNSXSource) GetComputeManagers(ctx context.Context) ([]internalAPI.NSXComputeManager, error) {
 	if !s.isConnected {
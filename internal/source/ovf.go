@@ -0,0 +1,869 @@
+package source
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lxc/incus/v6/shared/osarch"
+
+	internalAPI "github.com/FuturFusion/migration-manager/internal/api"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/qemuimg"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/util"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// OVF resource types, as defined by the CIM_ResourceAllocationSettingData schema.
+const (
+	ovfResourceTypeOther        = 1
+	ovfResourceTypeProcessor    = 3
+	ovfResourceTypeMemory       = 4
+	ovfResourceTypeEthernet     = 10
+	ovfResourceTypeDisk         = 17
+	ovfResourceTypeLogicalDisk  = 31
+	ovfResourceSubTypeVMwareTPM = "vmware.vtpm"
+)
+
+// ovfDiskFormats maps disk image file extensions to the corresponding qemu block driver.
+var ovfDiskFormats = map[string]string{
+	".vmdk":  "vmdk",
+	".qcow2": "qcow2",
+	".vhd":   "vpc",
+	".vhdx":  "vhdx",
+	".img":   "raw",
+	".raw":   "raw",
+}
+
+type InternalOVFSource struct {
+	InternalSource    `yaml:",inline"`
+	api.OVFProperties `yaml:",inline"`
+
+	// diskURL returns the URL the worker reads the named disk image from.
+	diskURL func(diskName string) string
+}
+
+var _ Source = &InternalOVFSource{}
+
+func newInternalOVFSourceFrom(apiSource api.Source) (*InternalOVFSource, error) {
+	if apiSource.SourceType != api.SOURCETYPE_OVF {
+		return nil, errors.New("Source is not of type OVF")
+	}
+
+	var connProperties api.OVFProperties
+
+	err := json.Unmarshal(apiSource.Properties, &connProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	connProperties.SetDefaults()
+
+	return &InternalOVFSource{
+		InternalSource: InternalSource{
+			Source:            apiSource,
+			connectionTimeout: connProperties.ConnectionTimeout.Duration,
+		},
+		OVFProperties: connProperties,
+	}, nil
+}
+
+// WithDiskURL configures the function used by ImportDisks to determine the URL of each disk image.
+// The disk images are not directly reachable by the worker, so they are served to it by the migration manager.
+func (s *InternalOVFSource) WithDiskURL(diskURL func(diskName string) string) {
+	s.diskURL = diskURL
+}
+
+func (s *InternalOVFSource) Connect(ctx context.Context) error {
+	if s.isConnected {
+		return fmt.Errorf("Already connected to %q", s.Path)
+	}
+
+	// The worker only reads disk images through the migration manager.
+	if s.diskURL == nil {
+		err := checkOVFDirectory(s.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.isConnected = true
+	return nil
+}
+
+func (s *InternalOVFSource) DoBasicConnectivityCheck() (api.ExternalConnectivityStatus, *x509.Certificate) {
+	err := checkOVFDirectory(s.Path)
+	if err != nil {
+		slog.Warn("OVF source directory is not usable", slog.String("source", s.Name), slog.String("path", s.Path), slog.Any("error", err))
+		return api.EXTERNALCONNECTIVITYSTATUS_CANNOT_CONNECT, nil
+	}
+
+	return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+}
+
+func (s *InternalOVFSource) Disconnect(ctx context.Context) error {
+	if !s.isConnected {
+		return fmt.Errorf("Not connected to %q", s.Path)
+	}
+
+	s.isConnected = false
+	return nil
+}
+
+func (s *InternalOVFSource) WithAdditionalRootCertificate(rootCert *x509.Certificate) {}
+
+func (s *InternalOVFSource) GetAllVMs(ctx context.Context, sourceSpecificIDs ...string) (migration.Instances, migration.Networks, migration.Warnings, error) {
+	log := slog.With(slog.String("source", s.Name))
+	vms := migration.Instances{}
+	warnings := migration.Warnings{}
+	networksByName := map[string]migration.Network{}
+
+	err := filepath.WalkDir(s.Path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("Source connection timeout (%s) exceeded: %w", s.Timeout(), ctx.Err())
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".ovf" && ext != ".ova") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(s.Path, filePath)
+		if err != nil {
+			return err
+		}
+
+		log.Debug("Parsing OVF package", slog.String("path", relPath))
+		pkg, err := readOVFPackage(s.Path, filepath.ToSlash(relPath))
+		if err != nil {
+			warnings = append(warnings, migration.NewSyncWarning(api.InstanceImportFailed, s.Name, fmt.Sprintf("Failed to parse OVF package %q: %v", relPath, err)))
+			return nil
+		}
+
+		for _, network := range pkg.envelope.Networks {
+			b, err := json.Marshal(internalAPI.OVFNetworkProperties{Description: network.Description})
+			if err != nil {
+				return err
+			}
+
+			networksByName[network.Name] = migration.Network{
+				SourceSpecificID: network.Name,
+				Type:             api.NETWORKTYPE_OVF,
+				Location:         network.Name,
+				Source:           s.Name,
+				Properties:       b,
+			}
+		}
+
+		for _, vs := range pkg.envelope.allVirtualSystems() {
+			location := "/" + path.Join(pkg.path, vs.ID)
+			if len(sourceSpecificIDs) > 0 && !slices.Contains(sourceSpecificIDs, location) {
+				continue
+			}
+
+			inst, warningType, err := s.getVM(pkg, vs)
+			if err != nil {
+				warnings = append(warnings, migration.NewSyncWarning(warningType, s.Name, err.Error()))
+			}
+
+			if inst != nil {
+				vms = append(vms, *inst)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, warnings, err
+	}
+
+	networks := make(migration.Networks, 0, len(networksByName))
+	for _, network := range networksByName {
+		networks = append(networks, network)
+	}
+
+	return vms, networks, warnings, nil
+}
+
+func (s *InternalOVFSource) getVM(pkg *ovfPackage, vs ovfVirtualSystem) (*migration.Instance, api.WarningType, error) {
+	location := "/" + path.Join(pkg.path, vs.ID)
+	props, err := pkg.instanceProperties(vs)
+	if err != nil {
+		return nil, api.InstanceImportFailed, fmt.Errorf("Failed to record properties for VM %q: %w", location, err)
+	}
+
+	props.Location = location
+	props.SourceSpecificID = location
+	props.UUID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("ovf://"+s.Name+location))
+
+	// OVF descriptors rarely carry MAC addresses, so generate stable ones as NICs are identified by their address.
+	for i, nic := range props.NICs {
+		if nic.HardwareAddress == "" {
+			hash := sha1.Sum([]byte(props.UUID.String() + "/" + strconv.Itoa(i)))
+			props.NICs[i].HardwareAddress = fmt.Sprintf("00:16:3e:%02x:%02x:%02x", hash[0], hash[1], hash[2])
+		}
+	}
+
+	inst := migration.Instance{
+		UUID:                 props.UUID,
+		Source:               s.Name,
+		SourceType:           s.SourceType,
+		LastUpdateFromSource: time.Now().UTC(),
+		Properties:           *props,
+	}
+
+	if inst.GetOSType(false) == api.OSTYPE_WINDOWS {
+		_, err := util.ToWindowsVersion(inst.Properties.OSTemplate)
+		if err != nil {
+			return nil, api.InstanceImportFailed, fmt.Errorf("Failed to determine OS distribution version %q for Windows VM %q: %w", inst.Properties.OSTemplate, location, err)
+		}
+	}
+
+	err = inst.DisabledReason(api.InstanceRestrictionOverride{})
+	if err != nil {
+		// Return the instance as this should not be a fatal error.
+		return &inst, api.InstanceCannotMigrate, fmt.Errorf("%q: %w", location, err)
+	}
+
+	return &inst, "", nil
+}
+
+// DeleteVMSnapshot is a no-op as OVF packages have no snapshots.
+func (s *InternalOVFSource) DeleteVMSnapshot(ctx context.Context, vmName string, snapshotName string) error {
+	return nil
+}
+
+func (s *InternalOVFSource) VerifyDisks(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error) {
+	return nil, fmt.Errorf("Disk verification is not supported by %q sources", s.SourceType)
+}
+
+func (s *InternalOVFSource) ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
+	if s.diskURL == nil {
+		return fmt.Errorf("No disk image endpoint configured for source %q", s.Name)
+	}
+
+	supportedDisks := []api.InstancePropertiesDisk{}
+	for _, disk := range disks {
+		if disk.Supported {
+			supportedDisks = append(supportedDisks, disk)
+		}
+	}
+
+	devIncus := util.UnixHTTPClient("/dev/incus/sock")
+	for i, disk := range supportedDisks {
+		format, ok := ovfDiskFormats[strings.ToLower(path.Ext(disk.Name))]
+		if !ok {
+			return fmt.Errorf("Unsupported disk image format for disk %q", disk.Name)
+		}
+
+		diskPath, _, err := target.GetIncusDisk(ctx, devIncus, disk.Name)
+		if err != nil {
+			return err
+		}
+
+		diskURL, err := url.Parse(s.diskURL(disk.Name))
+		if err != nil {
+			return err
+		}
+
+		// Read the disk image over HTTP with the qemu curl block driver, so that it never needs to be stored by the worker.
+		imageOpts, err := json.Marshal(map[string]any{
+			"driver": format,
+			"file": map[string]any{
+				"driver":    diskURL.Scheme,
+				"url":       diskURL.String(),
+				"readahead": 64 * 1024 * 1024,
+				"timeout":   300,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = qemuimg.Convert(ctx, fmt.Sprintf("Importing disk (%d/%d)", i+1, len(supportedDisks)), "json:"+string(imageOpts), diskPath, disk.Capacity, disk.Name, statusCallback, progressCallback)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsRunning always returns false as OVF packages are not running VMs.
+func (s *InternalOVFSource) IsRunning(ctx context.Context, vmName string) (bool, error) {
+	return false, nil
+}
+
+// PowerOffVM is a no-op as OVF packages are not running VMs.
+func (s *InternalOVFSource) PowerOffVM(ctx context.Context, vmName string) error {
+	return nil
+}
+
+// PowerOnVM is a no-op as OVF packages are not running VMs.
+func (s *InternalOVFSource) PowerOnVM(ctx context.Context, vmName string) error {
+	return nil
+}
+
+func (s *InternalOVFSource) Dump(ctx context.Context) error {
+	return fmt.Errorf("Dump is not supported by %q sources", s.SourceType)
+}
+
+func (s *InternalOVFSource) WatchVMs(ctx context.Context, f func(sourceSpecificIDs []string)) error {
+	return fmt.Errorf("Watching VM changes is not supported by %q sources", s.SourceType)
+}
+
+// VerifyBackgroundImport returns no instances, as OVF packages do not support background import.
+func (s *InternalOVFSource) VerifyBackgroundImport(ctx context.Context, instances migration.Instances) (migration.Instances, error) {
+	return migration.Instances{}, nil
+}
+
+func (s *InternalOVFSource) GetBackgroundImport(ctx context.Context, instUUID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (s *InternalOVFSource) EnableBackgroundImport(ctx context.Context, instUUID uuid.UUID) error {
+	return fmt.Errorf("Background import is not supported by %q sources", s.SourceType)
+}
+
+// OVFDiskImage is a disk image referenced by an OVF descriptor, either as a plain file or as an entry of an OVA archive.
+type OVFDiskImage struct {
+	io.ReadSeeker
+
+	Size    int64
+	ModTime time.Time
+
+	file *os.File
+}
+
+// Close closes the underlying file.
+func (d *OVFDiskImage) Close() error {
+	return d.file.Close()
+}
+
+// OpenOVFDisk opens the disk image of the given name, as recorded by the OVF source with the given properties.
+func OpenOVFDisk(props api.OVFProperties, diskName string) (*OVFDiskImage, error) {
+	if !filepath.IsLocal(diskName) {
+		return nil, fmt.Errorf("Invalid disk name %q", diskName)
+	}
+
+	// Disks of OVA archives are named after the archive, followed by the path within the archive.
+	parts := strings.Split(diskName, "/")
+	for i := range parts[:len(parts)-1] {
+		archivePath := filepath.Join(props.Path, filepath.Join(parts[:i+1]...))
+		if strings.ToLower(filepath.Ext(archivePath)) != ".ova" {
+			continue
+		}
+
+		info, err := os.Stat(archivePath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		return openOVAEntry(archivePath, strings.Join(parts[i+1:], "/"))
+	}
+
+	f, err := os.Open(filepath.Join(props.Path, diskName))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &OVFDiskImage{ReadSeeker: f, Size: info.Size(), ModTime: info.ModTime(), file: f}, nil
+}
+
+// openOVAEntry returns a reader over the data of the named entry of an OVA archive.
+// OVA archives are uncompressed tar files, so entries can be read in place.
+func openOVAEntry(archivePath string, entryName string) (*OVFDiskImage, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			_ = f.Close()
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("File %q not found in OVA archive %q: %w", entryName, archivePath, fs.ErrNotExist)
+			}
+
+			return nil, err
+		}
+
+		if path.Clean(hdr.Name) != entryName {
+			continue
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			_ = f.Close()
+			return nil, fmt.Errorf("Entry %q of OVA archive %q is not a regular file", entryName, archivePath)
+		}
+
+		// The tar reader leaves the file offset at the start of the entry data.
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		return &OVFDiskImage{ReadSeeker: io.NewSectionReader(f, offset, hdr.Size), Size: hdr.Size, ModTime: info.ModTime(), file: f}, nil
+	}
+}
+
+func checkOVFDirectory(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+
+	return nil
+}
+
+// ovfPackage is a parsed OVF descriptor, along with the files available to it.
+type ovfPackage struct {
+	// Path of the OVF descriptor or OVA archive, relative to the source directory.
+	path string
+
+	envelope ovfEnvelope
+
+	// Files available to the descriptor, relative to the source directory.
+	files map[string]bool
+}
+
+// readOVFPackage parses the OVF descriptor or OVA archive at the given path, relative to the source directory.
+func readOVFPackage(dir string, relPath string) (*ovfPackage, error) {
+	pkg := &ovfPackage{path: relPath, files: map[string]bool{}}
+
+	f, err := os.Open(filepath.Join(dir, relPath))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var descriptor []byte
+	if strings.ToLower(path.Ext(relPath)) == ".ovf" {
+		descriptor, err = io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := os.ReadDir(filepath.Join(dir, filepath.Dir(relPath)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				pkg.files[path.Join(path.Dir(relPath), entry.Name())] = true
+			}
+		}
+	} else {
+		tr := tar.NewReader(f)
+		for {
+			hdr, err := tr.Next()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				return nil, err
+			}
+
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			name := path.Clean(hdr.Name)
+			pkg.files[path.Join(relPath, name)] = true
+
+			// The descriptor is the first file of the archive.
+			if descriptor == nil && strings.ToLower(path.Ext(name)) == ".ovf" {
+				descriptor, err = io.ReadAll(tr)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if descriptor == nil {
+			return nil, fmt.Errorf("No OVF descriptor found in OVA archive")
+		}
+	}
+
+	err = xml.Unmarshal(descriptor, &pkg.envelope)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse OVF descriptor: %w", err)
+	}
+
+	return pkg, nil
+}
+
+// diskName returns the name of the disk image referenced by the given file, relative to the source directory.
+func (p *ovfPackage) diskName(href string) string {
+	if strings.ToLower(path.Ext(p.path)) == ".ova" {
+		return path.Join(p.path, href)
+	}
+
+	return path.Join(path.Dir(p.path), href)
+}
+
+// instanceProperties maps the hardware of a virtual system to instance properties.
+func (p *ovfPackage) instanceProperties(vs ovfVirtualSystem) (*api.InstanceProperties, error) {
+	if len(vs.Hardware) == 0 {
+		return nil, fmt.Errorf("Virtual system %q has no virtual hardware section", vs.ID)
+	}
+
+	hw := vs.Hardware[0]
+
+	name := vs.Name
+	if name == "" {
+		name = vs.ID
+	}
+
+	nonalpha := regexp.MustCompile(`[^\-a-zA-Z0-9]+`)
+
+	description := vs.Annotation
+	if description == "" {
+		description = vs.Product
+	}
+
+	osName := vs.OperatingSystem.OSType
+	if osName == "" {
+		osName = vs.OperatingSystem.Description
+	}
+
+	archID := osarch.ARCH_64BIT_INTEL_X86
+	if strings.Contains(strings.ToLower(vs.OperatingSystem.OSType), "arm64") {
+		archID = osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN
+	}
+
+	arch, err := osarch.ArchitectureName(archID)
+	if err != nil {
+		return nil, err
+	}
+
+	props := &api.InstanceProperties{
+		InstancePropertiesConfigurable: api.InstancePropertiesConfigurable{
+			Name:         nonalpha.ReplaceAllString(name, ""),
+			Config:       map[string]string{},
+			Architecture: arch,
+		},
+		Description: description,
+		OS:          osName,
+		OSTemplate:  vs.OperatingSystem.Description,
+		LegacyBoot:  hw.firmware() != "efi",
+		SecureBoot:  hw.secureBoot(),
+		NICs:        []api.InstancePropertiesNIC{},
+		Disks:       []api.InstancePropertiesDisk{},
+		Snapshots:   []api.InstancePropertiesSnapshot{},
+	}
+
+	for _, item := range hw.items() {
+		switch item.ResourceType {
+		case ovfResourceTypeOther:
+			if item.ResourceSubType == ovfResourceSubTypeVMwareTPM {
+				props.TPM = true
+			}
+
+		case ovfResourceTypeProcessor:
+			props.CPUs = item.VirtualQuantity
+
+		case ovfResourceTypeMemory:
+			units := item.AllocationUnits
+			if units == "" {
+				units = "byte * 2^20"
+			}
+
+			multiplier, err := parseOVFAllocationUnits(units)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid memory allocation: %w", err)
+			}
+
+			props.Memory = item.VirtualQuantity * multiplier
+
+		case ovfResourceTypeEthernet:
+			if len(item.Connection) == 0 || item.Connection[0] == "" {
+				continue
+			}
+
+			props.NICs = append(props.NICs, api.InstancePropertiesNIC{
+				SourceSpecificID: item.Connection[0],
+				Location:         item.Connection[0],
+				HardwareAddress:  strings.ToLower(item.Address),
+			})
+
+		case ovfResourceTypeDisk, ovfResourceTypeLogicalDisk:
+			if len(item.HostResource) == 0 {
+				continue
+			}
+
+			disk, err := p.disk(item.HostResource[0])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid disk %q: %w", item.ElementName, err)
+			}
+
+			props.Disks = append(props.Disks, *disk)
+		}
+	}
+
+	return props, nil
+}
+
+// disk returns the disk referenced by the host resource of a disk item.
+func (p *ovfPackage) disk(hostResource string) (*api.InstancePropertiesDisk, error) {
+	var fileRef string
+	var capacity int64
+	if diskID, ok := strings.CutPrefix(hostResource, "ovf:/disk/"); ok {
+		idx := slices.IndexFunc(p.envelope.Disks, func(d ovfDisk) bool { return d.ID == diskID })
+		if idx < 0 {
+			return nil, fmt.Errorf("Disk %q not found in disk section", diskID)
+		}
+
+		disk := p.envelope.Disks[idx]
+		multiplier, err := parseOVFAllocationUnits(disk.CapacityAllocationUnits)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := strconv.ParseInt(disk.Capacity, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid capacity %q: %w", disk.Capacity, err)
+		}
+
+		fileRef = disk.FileRef
+		capacity = value * multiplier
+	} else if fileID, ok := strings.CutPrefix(hostResource, "ovf:/file/"); ok {
+		fileRef = fileID
+	} else {
+		return nil, fmt.Errorf("Unsupported host resource %q", hostResource)
+	}
+
+	// Disks without a file reference are blank, and have no data to import.
+	if fileRef == "" {
+		return &api.InstancePropertiesDisk{Name: hostResource, Capacity: capacity}, nil
+	}
+
+	idx := slices.IndexFunc(p.envelope.References, func(f ovfFile) bool { return f.ID == fileRef })
+	if idx < 0 {
+		return nil, fmt.Errorf("File %q not found in references", fileRef)
+	}
+
+	file := p.envelope.References[idx]
+	if capacity == 0 {
+		capacity = file.Size
+	}
+
+	name := p.diskName(file.Href)
+	_, knownFormat := ovfDiskFormats[strings.ToLower(path.Ext(file.Href))]
+	supported := knownFormat && file.Compression == "" && filepath.IsLocal(file.Href) && p.files[name]
+
+	return &api.InstancePropertiesDisk{Name: name, Capacity: capacity, Supported: supported}, nil
+}
+
+// parseOVFAllocationUnits returns the number of bytes for the given allocation units, like "byte * 2^20".
+func parseOVFAllocationUnits(units string) (int64, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(units, " ", ""))
+	switch normalized {
+	case "", "byte", "bytes":
+		return 1, nil
+	case "kilobytes":
+		return 1 << 10, nil
+	case "megabytes":
+		return 1 << 20, nil
+	case "gigabytes":
+		return 1 << 30, nil
+	case "terabytes":
+		return 1 << 40, nil
+	}
+
+	factor, ok := strings.CutPrefix(normalized, "byte*")
+	if !ok {
+		return 0, fmt.Errorf("Unsupported allocation units %q", units)
+	}
+
+	base, exponent, ok := strings.Cut(factor, "^")
+	if !ok {
+		exponent = "1"
+	}
+
+	baseVal, err := strconv.ParseInt(base, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Unsupported allocation units %q: %w", units, err)
+	}
+
+	exponentVal, err := strconv.ParseInt(exponent, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Unsupported allocation units %q: %w", units, err)
+	}
+
+	return int64(math.Pow(float64(baseVal), float64(exponentVal))), nil
+}
+
+type ovfEnvelope struct {
+	References     []ovfFile                    `xml:"References>File"`
+	Disks          []ovfDisk                    `xml:"DiskSection>Disk"`
+	Networks       []ovfNetwork                 `xml:"NetworkSection>Network"`
+	VirtualSystem  *ovfVirtualSystem            `xml:"VirtualSystem"`
+	VirtualSystems *ovfVirtualSystemsCollection `xml:"VirtualSystemCollection"`
+}
+
+// allVirtualSystems returns the top-level virtual system, or all virtual systems of the collection.
+func (e ovfEnvelope) allVirtualSystems() []ovfVirtualSystem {
+	if e.VirtualSystem != nil {
+		return []ovfVirtualSystem{*e.VirtualSystem}
+	}
+
+	if e.VirtualSystems != nil {
+		return e.VirtualSystems.allVirtualSystems()
+	}
+
+	return nil
+}
+
+type ovfVirtualSystemsCollection struct {
+	VirtualSystems []ovfVirtualSystem            `xml:"VirtualSystem"`
+	Collections    []ovfVirtualSystemsCollection `xml:"VirtualSystemCollection"`
+}
+
+func (c ovfVirtualSystemsCollection) allVirtualSystems() []ovfVirtualSystem {
+	systems := slices.Clone(c.VirtualSystems)
+	for _, collection := range c.Collections {
+		systems = append(systems, collection.allVirtualSystems()...)
+	}
+
+	return systems
+}
+
+type ovfFile struct {
+	ID          string `xml:"id,attr"`
+	Href        string `xml:"href,attr"`
+	Size        int64  `xml:"size,attr"`
+	Compression string `xml:"compression,attr"`
+}
+
+type ovfDisk struct {
+	ID                      string `xml:"diskId,attr"`
+	FileRef                 string `xml:"fileRef,attr"`
+	Capacity                string `xml:"capacity,attr"`
+	CapacityAllocationUnits string `xml:"capacityAllocationUnits,attr"`
+	Format                  string `xml:"format,attr"`
+}
+
+type ovfNetwork struct {
+	Name        string `xml:"name,attr"`
+	Description string `xml:"Description"`
+}
+
+type ovfVirtualSystem struct {
+	ID              string               `xml:"id,attr"`
+	Name            string               `xml:"Name"`
+	Annotation      string               `xml:"AnnotationSection>Annotation"`
+	Product         string               `xml:"ProductSection>Product"`
+	OperatingSystem ovfOperatingSystem   `xml:"OperatingSystemSection"`
+	Hardware        []ovfVirtualHardware `xml:"VirtualHardwareSection"`
+}
+
+type ovfOperatingSystem struct {
+	OSType      string `xml:"osType,attr"`
+	Description string `xml:"Description"`
+}
+
+type ovfVirtualHardware struct {
+	SystemType        string      `xml:"System>VirtualSystemType"`
+	Items             []ovfItem   `xml:"Item"`
+	StorageItems      []ovfItem   `xml:"StorageItem"`
+	EthernetPortItems []ovfItem   `xml:"EthernetPortItem"`
+	Config            []ovfConfig `xml:"Config"`
+}
+
+// items returns all resource allocation items, including OVF 2.0 storage and ethernet port items.
+func (h ovfVirtualHardware) items() []ovfItem {
+	items := slices.Clone(h.Items)
+	items = append(items, h.StorageItems...)
+	items = append(items, h.EthernetPortItems...)
+
+	return items
+}
+
+// firmware returns either "efi" or "bios", based on VMware extra configuration or the Hyper-V VM generation.
+func (h ovfVirtualHardware) firmware() string {
+	for _, cfg := range h.Config {
+		if cfg.Key == "firmware" {
+			return strings.ToLower(cfg.Value)
+		}
+	}
+
+	// Hyper-V generation 2 VMs always use UEFI.
+	if strings.Contains(h.SystemType, "Microsoft:Hyper-V:SubType:2") {
+		return "efi"
+	}
+
+	return "bios"
+}
+
+func (h ovfVirtualHardware) secureBoot() bool {
+	for _, cfg := range h.Config {
+		if cfg.Key == "bootOptions.efiSecureBootEnabled" || cfg.Key == "uefi.secureBoot.enabled" {
+			return strings.EqualFold(cfg.Value, "true")
+		}
+	}
+
+	return false
+}
+
+type ovfItem struct {
+	ElementName     string   `xml:"ElementName"`
+	ResourceType    int      `xml:"ResourceType"`
+	ResourceSubType string   `xml:"ResourceSubType"`
+	VirtualQuantity int64    `xml:"VirtualQuantity"`
+	AllocationUnits string   `xml:"AllocationUnits"`
+	Address         string   `xml:"Address"`
+	Connection      []string `xml:"Connection"`
+	HostResource    []string `xml:"HostResource"`
+}
+
+type ovfConfig struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
@@ -0,0 +1,264 @@
+package source
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+const testOVFDescriptor = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData" xmlns:vssd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData" xmlns:vmw="http://www.vmware.com/schema/ovf">
+  <References>
+    <File ovf:id="file1" ovf:href="disk1.vmdk" ovf:size="4"/>
+    <File ovf:id="file2" ovf:href="disk2.vdi" ovf:size="4"/>
+  </References>
+  <DiskSection>
+    <Disk ovf:diskId="vmdisk1" ovf:fileRef="file1" ovf:capacity="2" ovf:capacityAllocationUnits="byte * 2^30"/>
+    <Disk ovf:diskId="vmdisk2" ovf:fileRef="file2" ovf:capacity="1073741824"/>
+  </DiskSection>
+  <NetworkSection>
+    <Network ovf:name="VM Network">
+      <Description>The VM Network network</Description>
+    </Network>
+  </NetworkSection>
+  <VirtualSystem ovf:id="web01">
+    <Name>web 01</Name>
+    <AnnotationSection>
+      <Annotation>Web server</Annotation>
+    </AnnotationSection>
+    <OperatingSystemSection ovf:id="101" vmw:osType="ubuntu64Guest">
+      <Description>Ubuntu Linux (64-bit)</Description>
+    </OperatingSystemSection>
+    <VirtualHardwareSection>
+      <System>
+        <vssd:VirtualSystemType>vmx-19</vssd:VirtualSystemType>
+      </System>
+      <Item>
+        <rasd:ElementName>2 virtual CPU(s)</rasd:ElementName>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>2</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AllocationUnits>byte * 2^20</rasd:AllocationUnits>
+        <rasd:ElementName>4096MB of memory</rasd:ElementName>
+        <rasd:ResourceType>4</rasd:ResourceType>
+        <rasd:VirtualQuantity>4096</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:ElementName>Hard disk 1</rasd:ElementName>
+        <rasd:HostResource>ovf:/disk/vmdisk1</rasd:HostResource>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:ElementName>Hard disk 2</rasd:ElementName>
+        <rasd:HostResource>ovf:/disk/vmdisk2</rasd:HostResource>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:Address>00:50:56:AA:BB:CC</rasd:Address>
+        <rasd:Connection>VM Network</rasd:Connection>
+        <rasd:ElementName>Network adapter 1</rasd:ElementName>
+        <rasd:ResourceType>10</rasd:ResourceType>
+      </Item>
+      <vmw:Config ovf:required="false" vmw:key="firmware" vmw:value="efi"/>
+      <vmw:Config ovf:required="false" vmw:key="bootOptions.efiSecureBootEnabled" vmw:value="true"/>
+    </VirtualHardwareSection>
+  </VirtualSystem>
+</Envelope>
+`
+
+const testHyperVDescriptor = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData" xmlns:vssd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData">
+  <References>
+    <File ovf:id="file1" ovf:href="disks/db01.vhdx"/>
+  </References>
+  <VirtualSystemCollection ovf:id="collection">
+    <VirtualSystem ovf:id="db01">
+      <OperatingSystemSection ovf:id="1">
+        <Description>Debian</Description>
+      </OperatingSystemSection>
+      <VirtualHardwareSection>
+        <System>
+          <vssd:VirtualSystemType>Microsoft:Hyper-V:SubType:2</vssd:VirtualSystemType>
+        </System>
+        <Item>
+          <rasd:ResourceType>3</rasd:ResourceType>
+          <rasd:VirtualQuantity>4</rasd:VirtualQuantity>
+        </Item>
+        <Item>
+          <rasd:ResourceType>4</rasd:ResourceType>
+          <rasd:VirtualQuantity>1024</rasd:VirtualQuantity>
+        </Item>
+        <Item>
+          <rasd:HostResource>ovf:/file/file1</rasd:HostResource>
+          <rasd:ResourceType>31</rasd:ResourceType>
+        </Item>
+        <Item>
+          <rasd:Connection>LAN</rasd:Connection>
+          <rasd:ResourceType>10</rasd:ResourceType>
+        </Item>
+      </VirtualHardwareSection>
+    </VirtualSystem>
+  </VirtualSystemCollection>
+</Envelope>
+`
+
+func writeTestOVA(t *testing.T, archivePath string, files map[string]string, order []string) {
+	t.Helper()
+
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	tw := tar.NewWriter(f)
+	for _, name := range order {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})
+		require.NoError(t, err)
+
+		_, err = tw.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+}
+
+func TestOVFSource_GetAllVMs(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "web"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "web", "web01.ovf"), []byte(testOVFDescriptor), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "web", "disk1.vmdk"), []byte("vmdk"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "web", "disk2.vdi"), []byte("vdi!"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.ovf"), []byte("<Envelope"), 0o644))
+	writeTestOVA(t, filepath.Join(dir, "db01.ova"), map[string]string{"db01.ovf": testHyperVDescriptor, "disks/db01.vhdx": "vhdx"}, []string{"db01.ovf", "disks/db01.vhdx"})
+
+	props, err := json.Marshal(api.OVFProperties{Path: dir})
+	require.NoError(t, err)
+
+	src, err := NewVMSource(api.Source{SourcePut: api.SourcePut{Name: "exports", Properties: props}, SourceType: api.SOURCETYPE_OVF})
+	require.NoError(t, err)
+
+	require.NoError(t, src.Connect(context.Background()))
+	status, _ := src.DoBasicConnectivityCheck()
+	require.Equal(t, api.EXTERNALCONNECTIVITYSTATUS_OK, status)
+
+	vms, networks, warnings, err := src.GetAllVMs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, warnings, 3)
+	require.Equal(t, api.InstanceImportFailed, warnings[0].Type)
+	require.Contains(t, warnings[0].Messages[0], "broken.ovf")
+
+	// OVF packages never report guest agent data, so instances are restricted from migration by default.
+	require.Equal(t, api.InstanceCannotMigrate, warnings[1].Type)
+	require.Len(t, vms, 2)
+	require.Len(t, networks, 1)
+
+	vmsByLocation := map[string]migration.Instance{}
+	for _, vm := range vms {
+		vmsByLocation[vm.Properties.Location] = vm
+	}
+
+	web, ok := vmsByLocation["/web/web01.ovf/web01"]
+	require.True(t, ok)
+	require.Equal(t, "web01", web.Properties.Name)
+	require.Equal(t, "Web server", web.Properties.Description)
+	require.Equal(t, "ubuntu64Guest", web.Properties.OS)
+	require.Equal(t, "x86_64", web.Properties.Architecture)
+	require.Equal(t, int64(2), web.Properties.CPUs)
+	require.Equal(t, int64(4096*1024*1024), web.Properties.Memory)
+	require.False(t, web.Properties.LegacyBoot)
+	require.True(t, web.Properties.SecureBoot)
+	require.Equal(t, []api.InstancePropertiesDisk{
+		{Name: "web/disk1.vmdk", Capacity: 2 * 1024 * 1024 * 1024, Supported: true},
+		{Name: "web/disk2.vdi", Capacity: 1073741824, Supported: false},
+	}, web.Properties.Disks)
+	require.Equal(t, []api.InstancePropertiesNIC{{SourceSpecificID: "VM Network", Location: "VM Network", HardwareAddress: "00:50:56:aa:bb:cc"}}, web.Properties.NICs)
+
+	db, ok := vmsByLocation["/db01.ova/db01"]
+	require.True(t, ok)
+	require.Equal(t, int64(4), db.Properties.CPUs)
+	require.Equal(t, int64(1024*1024*1024), db.Properties.Memory)
+	require.False(t, db.Properties.LegacyBoot)
+	require.Equal(t, []api.InstancePropertiesDisk{{Name: "db01.ova/disks/db01.vhdx", Capacity: 0, Supported: true}}, db.Properties.Disks)
+	require.Len(t, db.Properties.NICs, 1)
+	require.Regexp(t, `^00:16:3e:[0-9a-f]{2}:[0-9a-f]{2}:[0-9a-f]{2}$`, db.Properties.NICs[0].HardwareAddress)
+
+	// Instance identifiers must be stable across syncs.
+	vms, _, _, err = src.GetAllVMs(context.Background(), "/db01.ova/db01")
+	require.NoError(t, err)
+	require.Len(t, vms, 1)
+	require.Equal(t, db.UUID, vms[0].UUID)
+	require.Equal(t, db.Properties.NICs, vms[0].Properties.NICs)
+
+	require.Equal(t, "VM Network", networks[0].SourceSpecificID)
+	require.Equal(t, api.NETWORKTYPE_OVF, networks[0].Type)
+}
+
+func TestOpenOVFDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "disk.vmdk"), []byte("plain disk"), 0o644))
+	writeTestOVA(t, filepath.Join(dir, "vm.ova"), map[string]string{"vm.ovf": "<Envelope/>", "disk1.vmdk": "first disk", "disk2.vmdk": "second disk"}, []string{"vm.ovf", "disk1.vmdk", "disk2.vmdk"})
+
+	cases := []struct {
+		name     string
+		diskName string
+
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name:        "success - plain file",
+			diskName:    "disk.vmdk",
+			wantContent: "plain disk",
+		},
+		{
+			name:        "success - OVA entry",
+			diskName:    "vm.ova/disk2.vmdk",
+			wantContent: "second disk",
+		},
+		{
+			name:     "error - missing OVA entry",
+			diskName: "vm.ova/disk3.vmdk",
+			wantErr:  true,
+		},
+		{
+			name:     "error - path outside of source",
+			diskName: "../disk.vmdk",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			disk, err := OpenOVFDisk(api.OVFProperties{Path: dir}, tc.diskName)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer func() { _ = disk.Close() }()
+
+			require.Equal(t, int64(len(tc.wantContent)), disk.Size)
+
+			// Seeking must stay within the entry.
+			_, err = disk.Seek(1, io.SeekStart)
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(disk)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantContent[1:], string(content))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package source
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"encoding/json"
@@ -51,6 +52,10 @@ var NewVMSource = func(s api.Source) (Source, error) {
 	switch s.SourceType {
 	case api.SOURCETYPE_VMWARE:
 		return newInternalVMwareSourceFrom(s)
+	case api.SOURCETYPE_OVF:
+		return newInternalOVFSourceFrom(s)
+	case api.SOURCETYPE_VMWARE_DUMP:
+		return newInternalVMwareDumpSourceFrom(s)
 	default:
 		return nil, fmt.Errorf("Unknown source type %q", s.SourceType)
 	}
@@ -262,27 +267,9 @@ func (s *InternalVMwareSource) GetAllVMs(ctx context.Context, sourceSpecificIDs
 		return nil, nil, nil, fmt.Errorf("Failed to get all network data: %w", err)
 	}
 
-	var catMap map[string]string
-	var tc *tags.Manager
-	if !s.isESXI {
-		c := rest.NewClient(s.govmomiClient.Client)
-		log.Debug("Connecting to vCenter REST API")
-		err := c.Login(ctx, url.UserPassword(s.Username, s.Password))
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("Failed to login to REST API: %w", err)
-		}
-
-		tc = tags.NewManager(c)
-		log.Debug("Fetching vCenter categories")
-		allCats, err := tc.GetCategories(ctx)
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("No tag categories found: %w", err)
-		}
-
-		catMap = make(map[string]string, len(allCats))
-		for _, cat := range allCats {
-			catMap[cat.ID] = cat.Name
-		}
+	tc, catMap, err := s.getTagManager(ctx)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	grp := errgroup.Group{}
@@ -295,7 +282,7 @@ func (s *InternalVMwareSource) GetAllVMs(ctx context.Context, sourceSpecificIDs
 
 	for _, vm := range vmRefs {
 		// Filter VMs, if a filter is supplied.
-		if len(sourceSpecificIDs) > 0 && !filter[vm.Reference().String()] {
+		if len(sourceSpecificIDs) > 0 && !filter[vm.Reference().String()] && !filter[vm.Reference().Value] {
 			continue
 		}
 
@@ -381,35 +368,9 @@ func (s *InternalVMwareSource) getVM(ctx context.Context, vm *object.VirtualMach
 		return nil, api.InstanceIgnored, fmt.Errorf("Not importing VM tagged as vCenter appliance %q", vm.InventoryPath)
 	}
 
-	if !s.isESXI {
-		log.Debug("Fetching vCenter tags")
-		vmTags, err := tc.GetAttachedTags(ctx, vm.Reference())
-		if err != nil {
-			return nil, api.InstanceImportFailed, fmt.Errorf("Failed to import tags for VM %q: %w", vm.InventoryPath, err)
-		}
-
-		for _, tag := range vmTags {
-			prefix := "tag." + catMap[tag.CategoryID]
-			if vmProps.Config[prefix] == "" {
-				vmProps.Config[prefix] = tag.Name
-			} else {
-				vmProps.Config[prefix] = vmProps.Config[prefix] + "," + tag.Name
-			}
-		}
-
-		// VMware returns an error if the VM happens to not have resource pools, so we only return early if there was a context deadline error.
-		log.Debug("Fetching VM resource pool name")
-		var pool mo.ResourcePool
-		err = property.DefaultCollector(s.govmomiClient.Client).RetrieveOne(ctx, *vmProperties.ResourcePool, []string{"name"}, &pool)
-		if err != nil {
-			log.Error("Failed determine resource pool name for VM", slog.Any("error", err))
-			if errors.Is(err, context.DeadlineExceeded) {
-				return nil, api.InstanceImportFailed, fmt.Errorf("Failed to fetch resource pool names for VM %q: %w", vm.InventoryPath, err)
-			}
-		} else {
-			resourcePoolKey := fmt.Sprintf("%s.resource_pool", s.SourceType)
-			vmProps.Config[resourcePoolKey] = pool.Name
-		}
+	err = s.addVMTagConfig(ctx, vm, vmProperties, tc, catMap, vmProps.Config)
+	if err != nil {
+		return nil, api.InstanceImportFailed, err
 	}
 
 	vmProps.SourceSpecificID = vm.Reference().String()
@@ -442,6 +403,139 @@ func (s *InternalVMwareSource) getVM(ctx context.Context, vm *object.VirtualMach
 	return &inst, "", nil
 }
 
+// getTagManager logs in to the vCenter REST API, and returns the tag manager along with the names of all tag categories by their ID.
+// No tag manager is returned for ESXi hosts, as they do not support tags.
+func (s *InternalVMwareSource) getTagManager(ctx context.Context) (*tags.Manager, map[string]string, error) {
+	if s.isESXI {
+		return nil, nil, nil
+	}
+
+	log := slog.With(slog.String("source", s.Name))
+	c := rest.NewClient(s.govmomiClient.Client)
+	log.Debug("Connecting to vCenter REST API")
+	err := c.Login(ctx, url.UserPassword(s.Username, s.Password))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to login to REST API: %w", err)
+	}
+
+	tc := tags.NewManager(c)
+	log.Debug("Fetching vCenter categories")
+	allCats, err := tc.GetCategories(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("No tag categories found: %w", err)
+	}
+
+	catMap := make(map[string]string, len(allCats))
+	for _, cat := range allCats {
+		catMap[cat.ID] = cat.Name
+	}
+
+	return tc, catMap, nil
+}
+
+// addVMTagConfig adds the vCenter tags and the resource pool name of the VM to the given instance config.
+func (s *InternalVMwareSource) addVMTagConfig(ctx context.Context, vm *object.VirtualMachine, vmProperties mo.VirtualMachine, tc *tags.Manager, catMap map[string]string, config map[string]string) error {
+	if s.isESXI {
+		return nil
+	}
+
+	log := slog.With(slog.String("location", vm.InventoryPath), slog.String("source", s.Name))
+	log.Debug("Fetching vCenter tags")
+	vmTags, err := tc.GetAttachedTags(ctx, vm.Reference())
+	if err != nil {
+		return fmt.Errorf("Failed to import tags for VM %q: %w", vm.InventoryPath, err)
+	}
+
+	for _, tag := range vmTags {
+		prefix := "tag." + catMap[tag.CategoryID]
+		if config[prefix] == "" {
+			config[prefix] = tag.Name
+		} else {
+			config[prefix] = config[prefix] + "," + tag.Name
+		}
+	}
+
+	// VMware returns an error if the VM happens to not have resource pools, so we only return early if there was a context deadline error.
+	log.Debug("Fetching VM resource pool name")
+	var pool mo.ResourcePool
+	err = property.DefaultCollector(s.govmomiClient.Client).RetrieveOne(ctx, *vmProperties.ResourcePool, []string{"name"}, &pool)
+	if err != nil {
+		log.Error("Failed determine resource pool name for VM", slog.Any("error", err))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("Failed to fetch resource pool names for VM %q: %w", vm.InventoryPath, err)
+		}
+	} else {
+		resourcePoolKey := fmt.Sprintf("%s.resource_pool", s.SourceType)
+		config[resourcePoolKey] = pool.Name
+	}
+
+	return nil
+}
+
+// watchedVMProperties are the VM properties whose changes are reported by WatchVMs.
+var watchedVMProperties = []string{
+	"name",
+	"config.changeVersion",
+	"runtime.powerState",
+	"guest.toolsRunningStatus",
+	"guest.guestFullName",
+	"guest.ipAddress",
+}
+
+// WatchVMs waits for VMs to be created, removed, reconfigured, renamed or power cycled on the source,
+// and calls the given function with the source-specific IDs of the changed VMs.
+// The function is called synchronously, and changes made in the meantime are reported with the next call.
+// WatchVMs blocks until the context is cancelled or the connection to the source fails.
+func (s *InternalVMwareSource) WatchVMs(ctx context.Context, f func(sourceSpecificIDs []string)) error {
+	if !s.isConnected {
+		return fmt.Errorf("Not connected to endpoint %q", s.Endpoint)
+	}
+
+	v := view.NewManager(s.govmomiClient.Client)
+	c, err := v.CreateContainerView(ctx, s.govmomiClient.ServiceContent.RootFolder, []string{"VirtualMachine"}, true)
+	if err != nil {
+		return fmt.Errorf("Failed to create container view for %s: %w", s.Name, err)
+	}
+
+	defer func() { _ = c.Destroy(context.Background()) }()
+
+	filter := new(property.WaitFilter).Add(c.Reference(), "VirtualMachine", watchedVMProperties, c.TraversalSpec())
+	err = property.WaitForUpdates(ctx, property.DefaultCollector(s.govmomiClient.Client), filter, vmUpdateHandler(filter, f))
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("Failed to wait for VM updates from %q: %w", s.Name, err)
+	}
+
+	return nil
+}
+
+// vmUpdateHandler returns a property collector callback for the given filter that calls f with the source-specific IDs of changed VMs.
+// The first updates of a new collector or filter report the current state of all VMs, which is already known from the last sync, so they are skipped.
+// That initial state may be split across several truncated update sets, and only ends with the first set that is not truncated.
+func vmUpdateHandler(filter *property.WaitFilter, f func(sourceSpecificIDs []string)) func([]types.ObjectUpdate) bool {
+	initial := true
+	return func(updates []types.ObjectUpdate) bool {
+		if initial {
+			initial = filter.Truncated
+			return false
+		}
+
+		ids := []string{}
+		for _, update := range updates {
+			if update.Obj.Type != "VirtualMachine" || slices.Contains(ids, update.Obj.String()) {
+				continue
+			}
+
+			ids = append(ids, update.Obj.String())
+		}
+
+		if len(ids) > 0 {
+			f(ids)
+		}
+
+		return false
+	}
+}
+
 func (s *InternalVMwareSource) getAllNetworks(ctx context.Context, networkLocationsByID map[string]string) (migration.Networks, error) {
 	log := slog.With(slog.String("source", s.Name))
 
@@ -547,6 +641,7 @@ func (s *InternalVMwareSource) getAllNetworks(ctx context.Context, networkLocati
 	return networksInUse, nil
 }
 
+// Dump writes the raw properties of each VM to the cache directory, along with a manifest of the data that a VMware dump source needs to replay them.
 func (s *InternalVMwareSource) Dump(ctx context.Context) error {
 	log := slog.With(slog.String("source", s.Name))
 
@@ -569,6 +664,7 @@ func (s *InternalVMwareSource) Dump(ctx context.Context) error {
 	}
 
 	vmRefs := []*object.VirtualMachine{}
+	netRefs := []object.NetworkReference{}
 	for _, p := range paths {
 		var notFoundErr *find.NotFoundError
 		log.Debug("Fetching VMs from source")
@@ -581,13 +677,49 @@ func (s *InternalVMwareSource) Dump(ctx context.Context) error {
 			log.Warn("Registered source has no VMs in path", slog.String("path", p))
 		}
 
+		log.Debug("Fetching networks from source")
+		pathNets, err := finder.NetworkList(ctx, p)
+		if err != nil {
+			if !errors.As(err, &notFoundErr) {
+				return err
+			}
+
+			log.Warn("Registered source has no networks in path", slog.String("path", p))
+		}
+
 		vmRefs = append(vmRefs, pathVMs...)
+		netRefs = append(netRefs, pathNets...)
 	}
 
 	if len(vmRefs) == 0 {
 		return fmt.Errorf("No VMs found on the source")
 	}
 
+	manifest := vmwareDumpManifest{
+		Version:          s.version,
+		NetworkLocations: map[string]string{},
+		Networks:         []vmwareDumpNetwork{},
+		VMs:              map[string]vmwareDumpVM{},
+	}
+
+	for _, n := range netRefs {
+		manifest.NetworkLocations[parseNetworkID(ctx, n)] = n.GetInventoryPath()
+	}
+
+	networks, err := s.getAllNetworks(ctx, manifest.NetworkLocations)
+	if err != nil {
+		return fmt.Errorf("Failed to get all network data: %w", err)
+	}
+
+	for _, n := range networks {
+		manifest.Networks = append(manifest.Networks, vmwareDumpNetwork{ID: n.SourceSpecificID, Type: n.Type, Location: n.Location, Properties: n.Properties})
+	}
+
+	tc, catMap, err := s.getTagManager(ctx)
+	if err != nil {
+		return err
+	}
+
 	for _, vm := range vmRefs {
 		log := slog.With(slog.String("location", vm.InventoryPath), slog.String("source", s.Name))
 		// Ignore any vCLS instances.
@@ -599,39 +731,56 @@ func (s *InternalVMwareSource) Dump(ctx context.Context) error {
 		ctx, cancel := context.WithTimeout(ctx, s.SyncTimeout.Duration)
 		var vmProperties mo.VirtualMachine
 		err := vm.Properties(ctx, vm.Reference(), []string{}, &vmProperties)
-		cancel()
 		if err != nil {
+			cancel()
 			return fmt.Errorf("Failed to fetch VMware properties for VM %q: %w", vm.InventoryPath, err)
 		}
 
 		// If a VM has no configuration, then it's just a stub, so skip it.
 		if vmProperties.Config == nil {
+			cancel()
 			log.Info("Skipping VM with no configuration")
 			continue
 		}
 
 		// Skip VM templates.
 		if vmProperties.Config.Template {
+			cancel()
 			log.Info("Skipping VM template")
 			continue
 		}
 
-		b, err := json.Marshal(vmProperties)
+		config := map[string]string{}
+		err = s.addVMTagConfig(ctx, vm, vmProperties, tc, catMap, config)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		// Record the VMOMI type names so that the devices of the VM can be decoded again.
+		var b bytes.Buffer
+		err = types.NewJSONEncoder(&b).Encode(vmProperties)
 		if err != nil {
 			log.Error("Failed to parse VM properties", slog.Any("error", err))
 			continue
 		}
 
-		// Dump the VM properties to the cache dir on errors.
-		fileName := filepath.Join(dumpDir, strings.ReplaceAll(vm.InventoryPath, "/", "_"))
-		err = os.WriteFile(fileName, b, 0o644)
+		fileName := strings.ReplaceAll(vm.InventoryPath, "/", "_")
+		err = os.WriteFile(filepath.Join(dumpDir, fileName), b.Bytes(), 0o644)
 		if err != nil {
 			log.Error("Failed to write VM properties", slog.Any("error", err))
 			continue
 		}
+
+		manifest.VMs[fileName] = vmwareDumpVM{Location: vm.InventoryPath, SourceSpecificID: vm.Reference().String(), Config: config}
 	}
 
-	return nil
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dumpDir, vmwareDumpManifestFile), b, 0o644)
 }
 
 // EnableBackgroundImport powers off the VM, deletes all snapshots, then turns on change tracking and powers back on the VM, if it was initially powered on.
@@ -0,0 +1,437 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
+	"github.com/FuturFusion/migration-manager/internal/migration"
+	"github.com/FuturFusion/migration-manager/internal/util"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// vmwareDumpManifestFile is the name of the manifest written next to the VM properties of a VMware source dump.
+const vmwareDumpManifestFile = "manifest.json"
+
+// vmwareDumpManifest records the data of a VMware source that is not part of the raw VM properties.
+type vmwareDumpManifest struct {
+	// Version of the vCenter or ESXi host, which determines the property definitions used to parse VMs.
+	Version string `json:"version"`
+
+	// Inventory paths of all networks, by network ID.
+	NetworkLocations map[string]string `json:"network_locations"`
+
+	// Networks in use by VMs.
+	Networks []vmwareDumpNetwork `json:"networks"`
+
+	// VMs, by the name of the file holding their properties.
+	VMs map[string]vmwareDumpVM `json:"vms"`
+}
+
+type vmwareDumpNetwork struct {
+	ID         string          `json:"id"`
+	Type       api.NetworkType `json:"type"`
+	Location   string          `json:"location"`
+	Properties json.RawMessage `json:"properties"`
+}
+
+type vmwareDumpVM struct {
+	Location         string `json:"location"`
+	SourceSpecificID string `json:"source_specific_id"`
+
+	// Instance config obtained outside of the VM properties, like vCenter tags and the resource pool.
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// InternalVMwareDumpSource replays the VMs of a VMware source from the output of Dump, without connecting to the VMware source.
+type InternalVMwareDumpSource struct {
+	InternalSource           `yaml:",inline"`
+	api.VMwareDumpProperties `yaml:",inline"`
+}
+
+var _ Source = &InternalVMwareDumpSource{}
+
+func newInternalVMwareDumpSourceFrom(apiSource api.Source) (*InternalVMwareDumpSource, error) {
+	if apiSource.SourceType != api.SOURCETYPE_VMWARE_DUMP {
+		return nil, errors.New("Source is not of type VMware dump")
+	}
+
+	var connProperties api.VMwareDumpProperties
+
+	err := json.Unmarshal(apiSource.Properties, &connProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	connProperties.SetDefaults()
+
+	return &InternalVMwareDumpSource{
+		InternalSource: InternalSource{
+			Source:            apiSource,
+			connectionTimeout: connProperties.ConnectionTimeout.Duration,
+		},
+		VMwareDumpProperties: connProperties,
+	}, nil
+}
+
+func (s *InternalVMwareDumpSource) Connect(ctx context.Context) error {
+	if s.isConnected {
+		return fmt.Errorf("Already connected to %q", s.Path)
+	}
+
+	_, err := os.Stat(s.Path)
+	if err != nil {
+		return err
+	}
+
+	s.isConnected = true
+	return nil
+}
+
+func (s *InternalVMwareDumpSource) DoBasicConnectivityCheck() (api.ExternalConnectivityStatus, *x509.Certificate) {
+	_, err := os.Stat(s.Path)
+	if err != nil {
+		slog.Warn("VMware dump is not usable", slog.String("source", s.Name), slog.String("path", s.Path), slog.Any("error", err))
+		return api.EXTERNALCONNECTIVITYSTATUS_CANNOT_CONNECT, nil
+	}
+
+	return api.EXTERNALCONNECTIVITYSTATUS_OK, nil
+}
+
+func (s *InternalVMwareDumpSource) Disconnect(ctx context.Context) error {
+	if !s.isConnected {
+		return fmt.Errorf("Not connected to %q", s.Path)
+	}
+
+	s.isConnected = false
+	return nil
+}
+
+func (s *InternalVMwareDumpSource) WithAdditionalRootCertificate(rootCert *x509.Certificate) {}
+
+func (s *InternalVMwareDumpSource) GetAllVMs(ctx context.Context, sourceSpecificIDs ...string) (migration.Instances, migration.Networks, migration.Warnings, error) {
+	log := slog.With(slog.String("source", s.Name))
+	log.Debug("Reading VMware dump", slog.String("path", s.Path))
+	manifest, files, err := readVMwareDump(s.Path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to read VMware dump %q: %w", s.Path, err)
+	}
+
+	// VM properties are parsed exactly as they would be by the VMware source that was dumped.
+	parser := &InternalVMwareSource{
+		InternalSource: InternalSource{
+			Source: api.Source{
+				SourcePut:  api.SourcePut{Name: s.Name},
+				SourceType: api.SOURCETYPE_VMWARE,
+			},
+			version: manifest.Version,
+		},
+	}
+
+	vms := migration.Instances{}
+	warnings := migration.Warnings{}
+	fileNames := make([]string, 0, len(manifest.VMs))
+	for fileName := range manifest.VMs {
+		fileNames = append(fileNames, fileName)
+	}
+
+	slices.Sort(fileNames)
+	for _, fileName := range fileNames {
+		if ctx.Err() != nil {
+			return nil, nil, warnings, fmt.Errorf("Source connection timeout (%s) exceeded: %w", s.Timeout(), ctx.Err())
+		}
+
+		vm := manifest.VMs[fileName]
+		if len(sourceSpecificIDs) > 0 && !slices.Contains(sourceSpecificIDs, vm.SourceSpecificID) {
+			continue
+		}
+
+		inst, warningType, err := s.getVM(parser, vm, files[fileName], manifest.NetworkLocations)
+		if err != nil {
+			warnings = append(warnings, migration.NewSyncWarning(warningType, s.Name, err.Error()))
+		}
+
+		if inst != nil {
+			vms = append(vms, *inst)
+		}
+	}
+
+	networks := make(migration.Networks, 0, len(manifest.Networks))
+	for _, n := range manifest.Networks {
+		networks = append(networks, migration.Network{
+			SourceSpecificID: n.ID,
+			Type:             n.Type,
+			Location:         n.Location,
+			Source:           s.Name,
+			Properties:       n.Properties,
+		})
+	}
+
+	return vms, networks, warnings, nil
+}
+
+func (s *InternalVMwareDumpSource) getVM(parser *InternalVMwareSource, vm vmwareDumpVM, data []byte, networkLocationsByID map[string]string) (*migration.Instance, api.WarningType, error) {
+	// Ignore any vCLS instances.
+	if strings.HasPrefix(path.Base(vm.Location), "vCLS-") {
+		return nil, api.InstanceIgnored, fmt.Errorf("Not importing vCLS tagged VM %q", vm.Location)
+	}
+
+	if data == nil {
+		return nil, api.InstanceImportFailed, fmt.Errorf("Missing VMware properties for VM %q", vm.Location)
+	}
+
+	var vmProperties mo.VirtualMachine
+	err := types.NewJSONDecoder(bytes.NewReader(data)).Decode(&vmProperties)
+	if err != nil {
+		return nil, api.InstanceImportFailed, fmt.Errorf("Failed to parse VMware properties for VM %q: %w", vm.Location, err)
+	}
+
+	if vmProperties.Config == nil {
+		return nil, api.InstanceIgnored, fmt.Errorf("Not importing VM with empty source config %q", vm.Location)
+	}
+
+	if vmProperties.Config.Template {
+		return nil, api.InstanceIgnored, fmt.Errorf("Not importing VM tagged as a template %q", vm.Location)
+	}
+
+	// Only the inventory path of the VM object is used to record its properties.
+	vmRef := object.NewVirtualMachine(nil, vmProperties.Self)
+	vmRef.InventoryPath = vm.Location
+
+	vmProps, err := parser.getVMProperties(vmRef, vmProperties, networkLocationsByID)
+	if err != nil {
+		return nil, api.InstanceImportFailed, fmt.Errorf("Failed to record properties for VM %q: %w", vm.Location, err)
+	}
+
+	if vmProps.Description == "VMware vCenter Server Appliance" {
+		return nil, api.InstanceIgnored, fmt.Errorf("Not importing VM tagged as vCenter appliance %q", vm.Location)
+	}
+
+	for k, v := range vm.Config {
+		vmProps.Config[k] = v
+	}
+
+	vmProps.SourceSpecificID = vm.SourceSpecificID
+	inst := migration.Instance{
+		UUID:                 vmProps.UUID,
+		Source:               s.Name,
+		SourceType:           s.SourceType,
+		LastUpdateFromSource: time.Now().UTC(),
+		Properties:           *vmProps,
+	}
+
+	if inst.GetOSType(false) == api.OSTYPE_WINDOWS {
+		osVer := inst.Properties.OSDescription
+		if osVer == "" {
+			osVer = inst.Properties.OSTemplate
+		}
+
+		_, err := util.ToWindowsVersion(osVer)
+		if err != nil {
+			return nil, api.InstanceImportFailed, fmt.Errorf("Failed to determine OS distribution version %q for Windows VM %q: %w", inst.Properties.OSDescription, inst.Properties.Location, err)
+		}
+	}
+
+	err = inst.DisabledReason(api.InstanceRestrictionOverride{})
+	if err != nil {
+		// Return the instance as this should not be a fatal error.
+		return &inst, api.InstanceCannotMigrate, fmt.Errorf("%q: %w", inst.Properties.Location, err)
+	}
+
+	return &inst, "", nil
+}
+
+// DeleteVMSnapshot is a no-op as replayed VMs have no snapshots to manage.
+func (s *InternalVMwareDumpSource) DeleteVMSnapshot(ctx context.Context, vmName string, snapshotName string) error {
+	return nil
+}
+
+func (s *InternalVMwareDumpSource) VerifyDisks(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error) {
+	return nil, fmt.Errorf("Instances of %q sources can not be migrated", s.SourceType)
+}
+
+func (s *InternalVMwareDumpSource) ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
+	return fmt.Errorf("Instances of %q sources can not be migrated", s.SourceType)
+}
+
+// IsRunning always returns false as replayed VMs are not running VMs.
+func (s *InternalVMwareDumpSource) IsRunning(ctx context.Context, vmName string) (bool, error) {
+	return false, nil
+}
+
+func (s *InternalVMwareDumpSource) PowerOffVM(ctx context.Context, vmName string) error {
+	return fmt.Errorf("Instances of %q sources can not be migrated", s.SourceType)
+}
+
+func (s *InternalVMwareDumpSource) PowerOnVM(ctx context.Context, vmName string) error {
+	return fmt.Errorf("Instances of %q sources can not be migrated", s.SourceType)
+}
+
+func (s *InternalVMwareDumpSource) Dump(ctx context.Context) error {
+	return fmt.Errorf("Dump is not supported by %q sources", s.SourceType)
+}
+
+func (s *InternalVMwareDumpSource) WatchVMs(ctx context.Context, f func(sourceSpecificIDs []string)) error {
+	return fmt.Errorf("Watching VM changes is not supported by %q sources", s.SourceType)
+}
+
+// VerifyBackgroundImport marks the disks of each instance as verified, as the datastores of the dumped source can not be checked.
+func (s *InternalVMwareDumpSource) VerifyBackgroundImport(ctx context.Context, instances migration.Instances) (migration.Instances, error) {
+	updatedInstances := migration.Instances{}
+	for _, inst := range instances {
+		if !inst.NeedsBackgroundImportVerification() {
+			continue
+		}
+
+		for i := range inst.Properties.Disks {
+			inst.Properties.Disks[i].BackgroundImportVerified = true
+		}
+
+		updatedInstances = append(updatedInstances, inst)
+	}
+
+	return updatedInstances, nil
+}
+
+func (s *InternalVMwareDumpSource) GetBackgroundImport(ctx context.Context, instUUID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (s *InternalVMwareDumpSource) EnableBackgroundImport(ctx context.Context, instUUID uuid.UUID) error {
+	return fmt.Errorf("Background import is not supported by %q sources", s.SourceType)
+}
+
+// readVMwareDump returns the manifest and the VM properties files of the VMware dump at the given path.
+// The path is either a dump directory, or a tar archive containing the files of a dump directory, optionally compressed with gzip.
+func readVMwareDump(dumpPath string) (*vmwareDumpManifest, map[string][]byte, error) {
+	info, err := os.Stat(dumpPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := map[string][]byte{}
+	if info.IsDir() {
+		entries, err := os.ReadDir(dumpPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+
+			files[entry.Name()], err = os.ReadFile(filepath.Join(dumpPath, entry.Name()))
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	} else {
+		files, err = readVMwareDumpArchive(dumpPath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Dumps without a manifest were written with plain JSON, which lacks the VMware type names needed to decode VM devices.
+	data, ok := files[vmwareDumpManifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("No %q found, dumps created by older versions can not be replayed and must be created again", vmwareDumpManifestFile)
+	}
+
+	var manifest vmwareDumpManifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse %q: %w", vmwareDumpManifestFile, err)
+	}
+
+	return &manifest, files, nil
+}
+
+// vmwareDumpMaxFileSize is the largest file read from a VMware dump archive.
+// VM properties are usually well below 1MiB, so this only guards against corrupt or malicious archives.
+const vmwareDumpMaxFileSize = 64 * 1024 * 1024
+
+// vmwareDumpMaxArchiveSize is the largest total size of the files read from a VMware dump archive.
+const vmwareDumpMaxArchiveSize = 4 * 1024 * 1024 * 1024
+
+// readVMwareDumpArchive returns the regular files of a tar archive by their base name, as archives usually contain the dump directory itself.
+// Archives containing more than one file with the same base name are rejected, as the files of a dump directory are always unique.
+func readVMwareDumpArchive(archivePath string) (map[string][]byte, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if strings.HasSuffix(archivePath, ".gz") || strings.HasSuffix(archivePath, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	files := map[string][]byte{}
+	var totalSize int64
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Base(hdr.Name)
+		_, ok := files[name]
+		if ok {
+			return nil, fmt.Errorf("Archive contains more than one file named %q", name)
+		}
+
+		if hdr.Size > vmwareDumpMaxFileSize {
+			return nil, fmt.Errorf("File %q in archive exceeds the maximum size of %d bytes", hdr.Name, vmwareDumpMaxFileSize)
+		}
+
+		totalSize += hdr.Size
+		if totalSize > vmwareDumpMaxArchiveSize {
+			return nil, fmt.Errorf("Archive contents exceed the maximum size of %d bytes", vmwareDumpMaxArchiveSize)
+		}
+
+		files[name], err = io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
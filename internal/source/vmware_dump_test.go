@@ -0,0 +1,303 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/FuturFusion/migration-manager/internal/properties"
+	"github.com/FuturFusion/migration-manager/internal/ptr"
+	"github.com/FuturFusion/migration-manager/shared/api"
+)
+
+// writeTestVMwareDump writes a dump directory as created by the VMware source, and returns its files by name.
+func writeTestVMwareDump(t *testing.T, dir string, vmUUID uuid.UUID) map[string][]byte {
+	t.Helper()
+
+	vm := mo.VirtualMachine{
+		Config: &types.VirtualMachineConfigInfo{
+			Name:                  "web01",
+			Firmware:              string(types.GuestOsDescriptorFirmwareTypeEfi),
+			GuestFullName:         "Ubuntu Linux (64-bit)",
+			ChangeTrackingEnabled: ptr.To(true),
+			BootOptions:           &types.VirtualMachineBootOptions{EfiSecureBootEnabled: ptr.To(false)},
+			ExtraConfig:           object.OptionValueListFromMap(map[string]string{"guestInfo.detailed.data": "architecture='X86' bitness='64' distroName='Ubuntu' prettyName='Ubuntu 24.04 LTS'"}),
+			Hardware: types.VirtualHardware{
+				NumCPU: 2,
+				Device: []types.BaseVirtualDevice{
+					&types.VirtualDisk{
+						VirtualDevice: types.VirtualDevice{Backing: &types.VirtualDiskFlatVer2BackingInfo{
+							VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: "[datastore] web01/web01.vmdk"},
+							Sharing:                      string(types.VirtualDiskSharingSharingNone),
+						}},
+						CapacityInBytes: 1024,
+					},
+					&types.VirtualVmxnet3{
+						VirtualVmxnet: types.VirtualVmxnet{
+							VirtualEthernetCard: types.VirtualEthernetCard{
+								VirtualDevice: types.VirtualDevice{Backing: &types.VirtualEthernetCardNetworkBackingInfo{
+									Network: &types.ManagedObjectReference{Type: "Network", Value: "network-1"},
+								}},
+								MacAddress: "00:50:56:aa:bb:cc",
+							},
+						},
+					},
+				},
+			},
+		},
+		Summary: types.VirtualMachineSummary{
+			Config: types.VirtualMachineConfigSummary{
+				MemorySizeMB: 1024,
+				TpmPresent:   ptr.To(false),
+				InstanceUuid: vmUUID.String(),
+			},
+		},
+		Capability: types.VirtualMachineCapability{SecureBootSupported: ptr.To(false)},
+		Guest: &types.GuestInfo{
+			Net: []types.GuestNicInfo{{Network: "VM Network", IpConfig: &types.NetIpConfigInfo{IpAddress: []types.NetIpConfigInfoIpAddress{{IpAddress: "10.0.0.10"}}}}},
+		},
+	}
+
+	vm.Self = types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-1"}
+
+	var b bytes.Buffer
+	require.NoError(t, types.NewJSONEncoder(&b).Encode(vm))
+
+	manifest := vmwareDumpManifest{
+		Version:          "8.0.2",
+		NetworkLocations: map[string]string{"network-1": "/dc/network/VM Network"},
+		Networks:         []vmwareDumpNetwork{{ID: "network-1", Type: api.NETWORKTYPE_VMWARE_STANDARD, Location: "/dc/network/VM Network", Properties: json.RawMessage(`{}`)}},
+		VMs: map[string]vmwareDumpVM{
+			"_dc_vm_web01":       {Location: "/dc/vm/web01", SourceSpecificID: "VirtualMachine:vm-1", Config: map[string]string{"tag.env": "prod"}},
+			"_dc_vm_vCLS-1":      {Location: "/dc/vm/vCLS-1", SourceSpecificID: "VirtualMachine:vm-2"},
+			"_dc_vm_missing-vm":  {Location: "/dc/vm/missing-vm", SourceSpecificID: "VirtualMachine:vm-3"},
+			"_dc_vm_invalid-vm":  {Location: "/dc/vm/invalid-vm", SourceSpecificID: "VirtualMachine:vm-4"},
+			"_dc_vm_template-vm": {Location: "/dc/vm/template-vm", SourceSpecificID: "VirtualMachine:vm-5"},
+		},
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		vmwareDumpManifestFile: manifestData,
+		"_dc_vm_web01":         b.Bytes(),
+		"_dc_vm_invalid-vm":    []byte(`{"config":`),
+		"_dc_vm_template-vm":   []byte(`{"_typeName":"VirtualMachine","config":{"_typeName":"VirtualMachineConfigInfo","template":true}}`),
+	}
+
+	for name, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	}
+
+	return files
+}
+
+func TestVMwareDumpSource_GetAllVMs(t *testing.T) {
+	require.NoError(t, properties.InitDefinitions())
+
+	vmUUID := uuid.New()
+	dumpDir := filepath.Join(t.TempDir(), "vcenter_dump")
+	require.NoError(t, os.Mkdir(dumpDir, 0o755))
+	files := writeTestVMwareDump(t, dumpDir, vmUUID)
+
+	// Archives contain the dump directory itself.
+	archivePath := filepath.Join(t.TempDir(), "vcenter_dump.tar.gz")
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "vcenter_dump/", Mode: 0o755, Typeflag: tar.TypeDir}))
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "vcenter_dump/" + name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(archivePath, archive.Bytes(), 0o644))
+
+	cases := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "success - directory",
+			path: dumpDir,
+		},
+		{
+			name: "success - archive",
+			path: archivePath,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			props, err := json.Marshal(api.VMwareDumpProperties{Path: tc.path})
+			require.NoError(t, err)
+
+			src, err := NewVMSource(api.Source{SourcePut: api.SourcePut{Name: "replay", Properties: props}, SourceType: api.SOURCETYPE_VMWARE_DUMP})
+			require.NoError(t, err)
+
+			require.NoError(t, src.Connect(context.Background()))
+			status, _ := src.DoBasicConnectivityCheck()
+			require.Equal(t, api.EXTERNALCONNECTIVITYSTATUS_OK, status)
+
+			vms, networks, warnings, err := src.GetAllVMs(context.Background())
+			require.NoError(t, err)
+			require.Len(t, vms, 1)
+			require.Len(t, warnings, 5)
+			require.Equal(t, api.InstanceImportFailed, warnings[0].Type)
+			require.Contains(t, warnings[0].Messages[0], "/dc/vm/invalid-vm")
+			require.Equal(t, api.InstanceImportFailed, warnings[1].Type)
+			require.Contains(t, warnings[1].Messages[0], "/dc/vm/missing-vm")
+			require.Equal(t, api.InstanceIgnored, warnings[2].Type)
+			require.Contains(t, warnings[2].Messages[0], "/dc/vm/template-vm")
+			require.Equal(t, api.InstanceIgnored, warnings[3].Type)
+			require.Contains(t, warnings[3].Messages[0], "vCLS")
+
+			// Background import is verified once the instance is queued.
+			require.Equal(t, api.InstanceCannotMigrate, warnings[4].Type)
+			require.Contains(t, warnings[4].Messages[0], "Verifying background import support")
+
+			vm := vms[0]
+			require.Equal(t, vmUUID, vm.UUID)
+			require.Equal(t, "replay", vm.Source)
+			require.Equal(t, api.SOURCETYPE_VMWARE_DUMP, vm.SourceType)
+			require.Equal(t, "VirtualMachine:vm-1", vm.Properties.SourceSpecificID)
+			require.Equal(t, "/dc/vm/web01", vm.Properties.Location)
+			require.Equal(t, "web01", vm.Properties.Name)
+			require.Equal(t, "x86_64", vm.Properties.Architecture)
+			require.Equal(t, "Ubuntu", vm.Properties.OS)
+			require.Equal(t, "Ubuntu 24.04 LTS", vm.Properties.OSDescription)
+			require.Equal(t, int64(2), vm.Properties.CPUs)
+			require.Equal(t, int64(1024*1024*1024), vm.Properties.Memory)
+			require.False(t, vm.Properties.LegacyBoot)
+			require.Equal(t, "prod", vm.Properties.Config["tag.env"])
+			require.Len(t, vm.Properties.Disks, 1)
+			require.Equal(t, int64(1024), vm.Properties.Disks[0].Capacity)
+			require.Len(t, vm.Properties.NICs, 1)
+			require.Equal(t, "/dc/network/VM Network", vm.Properties.NICs[0].Location)
+			require.Equal(t, "00:50:56:aa:bb:cc", vm.Properties.NICs[0].HardwareAddress)
+			require.Equal(t, "10.0.0.10", vm.Properties.NICs[0].IPv4Address)
+			distro, version := vm.GetDistribution(false)
+			require.Equal(t, api.DISTRO_UBUNTU, distro)
+			require.Equal(t, "24.04", version)
+
+			require.Len(t, networks, 1)
+			require.Equal(t, "network-1", networks[0].SourceSpecificID)
+			require.Equal(t, "replay", networks[0].Source)
+			require.Equal(t, api.NETWORKTYPE_VMWARE_STANDARD, networks[0].Type)
+
+			vms, _, warnings, err = src.GetAllVMs(context.Background(), "VirtualMachine:vm-1")
+			require.NoError(t, err)
+			require.Len(t, vms, 1)
+			require.Len(t, warnings, 1)
+
+			verified, err := src.VerifyBackgroundImport(context.Background(), vms)
+			require.NoError(t, err)
+			require.Len(t, verified, 1)
+			require.NoError(t, verified[0].DisabledReason(api.InstanceRestrictionOverride{}))
+
+			require.Error(t, src.ImportDisks(context.Background(), "/dc/vm/web01", "", nil, nil, nil, nil))
+		})
+	}
+}
+
+func TestVMwareDumpSource_GetAllVMs_noManifest(t *testing.T) {
+	dumpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dumpDir, "_dc_vm_web01"), []byte(`{}`), 0o644))
+
+	props, err := json.Marshal(api.VMwareDumpProperties{Path: dumpDir})
+	require.NoError(t, err)
+
+	src, err := NewVMSource(api.Source{SourcePut: api.SourcePut{Name: "replay", Properties: props}, SourceType: api.SOURCETYPE_VMWARE_DUMP})
+	require.NoError(t, err)
+
+	_, _, _, err = src.GetAllVMs(context.Background())
+	require.ErrorContains(t, err, vmwareDumpManifestFile)
+	require.ErrorContains(t, err, "must be created again")
+}
+
+func TestReadVMwareDumpArchive(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers []tar.Header
+
+		assertErr require.ErrorAssertionFunc
+		wantFiles []string
+	}{
+		{
+			name:      "success - files keyed by base name",
+			headers:   []tar.Header{{Name: "dump/manifest.json", Size: 2}, {Name: "dump/_dc_vm_web01", Size: 2}},
+			assertErr: require.NoError,
+			wantFiles: []string{"manifest.json", "_dc_vm_web01"},
+		},
+		{
+			name:    "error - duplicate base name",
+			headers: []tar.Header{{Name: "dump/manifest.json", Size: 2}, {Name: "other/manifest.json", Size: 2}},
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, "more than one file", a...)
+			},
+		},
+		{
+			name:    "error - file too large",
+			headers: []tar.Header{{Name: "dump/manifest.json", Size: vmwareDumpMaxFileSize + 1}},
+			assertErr: func(tt require.TestingT, err error, a ...any) {
+				require.ErrorContains(tt, err, "exceeds the maximum size", a...)
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			var archive bytes.Buffer
+			tw := tar.NewWriter(&archive)
+			complete := true
+			for _, hdr := range tc.headers {
+				hdr.Mode = 0o644
+				hdr.Typeflag = tar.TypeReg
+				require.NoError(t, tw.WriteHeader(&hdr))
+
+				// Oversized files are rejected based on their header, so don't write their contents.
+				if hdr.Size > vmwareDumpMaxFileSize {
+					complete = false
+					break
+				}
+
+				_, err := tw.Write(bytes.Repeat([]byte("x"), int(hdr.Size)))
+				require.NoError(t, err)
+			}
+
+			if complete {
+				require.NoError(t, tw.Close())
+			}
+
+			archivePath := filepath.Join(t.TempDir(), "dump.tar")
+			require.NoError(t, os.WriteFile(archivePath, archive.Bytes(), 0o644))
+
+			files, err := readVMwareDumpArchive(archivePath)
+			tc.assertErr(t, err)
+			if err == nil {
+				require.ElementsMatch(t, tc.wantFiles, slices.Collect(maps.Keys(files)))
+			}
+		})
+	}
+}
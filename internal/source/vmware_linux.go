@@ -13,6 +13,7 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/FuturFusion/migration-manager/internal/migratekit/nbdkit"
+	"github.com/FuturFusion/migration-manager/internal/migratekit/target"
 	"github.com/FuturFusion/migration-manager/internal/migratekit/vmware"
 	"github.com/FuturFusion/migration-manager/internal/migratekit/vmware_nbdkit"
 	"github.com/FuturFusion/migration-manager/shared/api"
@@ -25,13 +26,13 @@ type InternalVMwareSourceSpecific struct {
 	vddkConfig    *vmware_nbdkit.VddkConfig
 }
 
-func (s *InternalVMwareSource) ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, statusCallback func(string, bool)) error {
+func (s *InternalVMwareSource) ImportDisks(ctx context.Context, vmName string, sdkPath string, disks []api.InstancePropertiesDisk, changeIDs target.ChangeIDStore, statusCallback func(string, bool), progressCallback func(string, int64, int64)) error {
 	vm, err := s.getVMReference(ctx, vmName)
 	if err != nil {
 		return err
 	}
 
-	NbdkitServers := vmware_nbdkit.NewNbdkitServers(s.vddkConfig, vm, sdkPath, statusCallback)
+	NbdkitServers := vmware_nbdkit.NewNbdkitServers(s.vddkConfig, vm, sdkPath, changeIDs, statusCallback, progressCallback)
 
 	validator := func(srcDisks []*types.VirtualDisk) error {
 		if len(srcDisks) != len(disks) {
@@ -77,6 +78,17 @@ func (s *InternalVMwareSource) ImportDisks(ctx context.Context, vmName string, s
 	return err
 }
 
+func (s *InternalVMwareSource) VerifyDisks(ctx context.Context, vmName string, sdkPath string, mode api.DiskVerificationMode, statusCallback func(string, bool)) ([]api.DiskVerificationResult, error) {
+	vm, err := s.getVMReference(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+
+	NbdkitServers := vmware_nbdkit.NewNbdkitServers(s.vddkConfig, vm, sdkPath, nil, statusCallback, func(string, int64, int64) {})
+
+	return NbdkitServers.VerifyDisks(ctx, mode)
+}
+
 func (s *InternalVMwareSource) setVDDKConfig(endpointURL *url.URL, thumbprint string) {
 	s.vddkConfig = &vmware_nbdkit.VddkConfig{
 		Debug:       false,
@@ -0,0 +1,118 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestVMwareSource_WatchVMs(t *testing.T) {
+	simulator.Test(func(ctx context.Context, c *vim25.Client) {
+		src := &InternalVMwareSource{}
+		src.Name = "vcenter"
+		src.govmomiClient = &govmomi.Client{Client: c}
+		src.isConnected = true
+
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "DC0_H0_VM0")
+		require.NoError(t, err)
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		changed := make(chan []string, 10)
+		done := make(chan error)
+		go func() {
+			done <- src.WatchVMs(watchCtx, func(ids []string) {
+				changed <- ids
+			})
+		}()
+
+		// Keep renaming the VM until the watcher reports it, as the watcher ignores changes made before it started.
+		var ids []string
+		for i := 0; ids == nil; i++ {
+			require.Less(t, i, 100, "VM change was not reported")
+
+			task, err := vm.Rename(ctx, fmt.Sprintf("renamed-%d", i))
+			require.NoError(t, err)
+			require.NoError(t, task.Wait(ctx))
+
+			select {
+			case ids = <-changed:
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		require.Equal(t, []string{vm.Reference().String()}, ids)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+func TestVMwareSource_vmUpdateHandler(t *testing.T) {
+	vm1 := types.ObjectUpdate{Obj: types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-1"}}
+	vm2 := types.ObjectUpdate{Obj: types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-2"}}
+	folder := types.ObjectUpdate{Obj: types.ManagedObjectReference{Type: "Folder", Value: "group-1"}}
+
+	type updateSet struct {
+		truncated bool
+		updates   []types.ObjectUpdate
+	}
+
+	cases := []struct {
+		name string
+		sets []updateSet
+
+		wantIDs [][]string
+	}{
+		{
+			name: "initial state is skipped",
+			sets: []updateSet{
+				{updates: []types.ObjectUpdate{vm1, vm2}},
+				{updates: []types.ObjectUpdate{vm2, vm2, folder}},
+			},
+
+			wantIDs: [][]string{{"VirtualMachine:vm-2"}},
+		},
+		{
+			name: "truncated initial state is skipped",
+			sets: []updateSet{
+				{truncated: true, updates: []types.ObjectUpdate{vm1}},
+				{truncated: true, updates: []types.ObjectUpdate{vm2}},
+				{updates: []types.ObjectUpdate{}},
+				{updates: []types.ObjectUpdate{vm1}},
+				{updates: []types.ObjectUpdate{folder}},
+			},
+
+			wantIDs: [][]string{{"VirtualMachine:vm-1"}},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Logf("\n\nTEST %02d: %s\n\n", i, tc.name)
+
+			filter := new(property.WaitFilter)
+			ids := [][]string{}
+			handler := vmUpdateHandler(filter, func(sourceSpecificIDs []string) {
+				ids = append(ids, sourceSpecificIDs)
+			})
+
+			for _, set := range tc.sets {
+				filter.Truncated = set.truncated
+				require.False(t, handler(set.updates))
+			}
+
+			require.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
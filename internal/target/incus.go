@@ -1,6 +1,7 @@
 package target
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
@@ -16,6 +18,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	incus "github.com/lxc/incus/v6/client"
@@ -23,6 +26,7 @@ import (
 	"github.com/lxc/incus/v6/shared/osarch"
 	"github.com/lxc/incus/v6/shared/revert"
 	incusTLS "github.com/lxc/incus/v6/shared/tls"
+	"github.com/lxc/incus/v6/shared/units"
 	"gopkg.in/yaml.v3"
 
 	"github.com/FuturFusion/migration-manager/internal/migration"
@@ -226,7 +230,7 @@ func (t *InternalIncusTarget) SetProject(project string) error {
 }
 
 // SetPostMigrationVMConfig stops the target instance and applies post-migration configuration before restarting it.
-func (t *InternalIncusTarget) SetPostMigrationVMConfig(ctx context.Context, i migration.Instance, q migration.QueueEntry) error {
+func (t *InternalIncusTarget) SetPostMigrationVMConfig(ctx context.Context, i migration.Instance, q migration.QueueEntry, acls *api.InstanceNetworkACLs, configure func(def *incusAPI.InstancePut) error) error {
 	props := i.Properties
 	props.Apply(i.Overrides.InstancePropertiesConfigurable)
 
@@ -256,6 +260,13 @@ func (t *InternalIncusTarget) SetPostMigrationVMConfig(ctx context.Context, i mi
 	// Clear migration.stateful=false before starting the VM.
 	delete(apiDef.Config, "migration.stateful")
 
+	if acls != nil {
+		err = t.ensureNetworkACLs(acls.ACLs, i.GetName())
+		if err != nil {
+			return fmt.Errorf("Failed to create network ACLs for instance %q on target %q: %w", i.GetName(), t.GetName(), err)
+		}
+	}
+
 	// Delete any pre-existing NICs.
 	for name, dev := range apiDef.Devices {
 		if dev["type"] == "nic" {
@@ -297,20 +308,41 @@ func (t *InternalIncusTarget) SetPostMigrationVMConfig(ctx context.Context, i mi
 
 		case api.INCUSNICTYPE_MANAGED:
 			apiDef.Devices[nicDeviceName]["network"] = netCfg.Network
-			if nic.IPv4Address != "" {
+			var nicACLs []string
+			if acls != nil {
+				nicACLs = acls.NICs[nic.HardwareAddress]
+			}
+
+			if nic.IPv4Address != "" || nicACLs != nil {
 				network, _, err := t.incusClient.GetNetwork(netCfg.Network)
 				if err != nil {
 					return fmt.Errorf("Failed to fetch network configuration from target %q: %w", t.GetName(), err)
 				}
 
-				// Don't set ipv4 address for physical networks.
+				// Don't set ipv4 address or network ACLs for physical networks.
 				if slices.Contains([]string{"bridge", "ovn"}, network.Type) {
-					ipv4Info, err := nicDefs.Get(properties.InstanceNICIPv4Address)
-					if err != nil {
-						return err
+					if nic.IPv4Address != "" {
+						ipv4Info, err := nicDefs.Get(properties.InstanceNICIPv4Address)
+						if err != nil {
+							return err
+						}
+
+						apiDef.Devices[nicDeviceName][ipv4Info.Key] = nic.IPv4Address
 					}
 
-					apiDef.Devices[nicDeviceName][ipv4Info.Key] = nic.IPv4Address
+					if nicACLs != nil {
+						if len(nicACLs) > 0 {
+							apiDef.Devices[nicDeviceName]["security.acls"] = strings.Join(nicACLs, ",")
+						}
+
+						if acls.DefaultIngressAction != "" {
+							apiDef.Devices[nicDeviceName]["security.acls.default.ingress.action"] = acls.DefaultIngressAction
+						}
+
+						if acls.DefaultEgressAction != "" {
+							apiDef.Devices[nicDeviceName]["security.acls.default.egress.action"] = acls.DefaultEgressAction
+						}
+					}
 				}
 			}
 		}
@@ -424,8 +456,16 @@ func (t *InternalIncusTarget) SetPostMigrationVMConfig(ctx context.Context, i mi
 		apiDef.Config[secBootInfo.Key] = "false"
 	}
 
+	instanceDef := apiDef.Writable()
+	if configure != nil {
+		err = configure(&instanceDef)
+		if err != nil {
+			return fmt.Errorf("Failed to configure instance %q on target %q: %w", i.GetName(), t.GetName(), err)
+		}
+	}
+
 	// Update the instance in Incus.
-	op, err := t.UpdateInstance(i.GetName(), apiDef.Writable(), "")
+	op, err := t.UpdateInstance(i.GetName(), instanceDef, "")
 	if err != nil {
 		return fmt.Errorf("Failed to update instance %q on target %q: %w", i.GetName(), t.GetName(), err)
 	}
@@ -446,6 +486,63 @@ func (t *InternalIncusTarget) SetPostMigrationVMConfig(ctx context.Context, i mi
 	return nil
 }
 
+// ensureNetworkACLs creates the given network ACLs in the current project, or replaces the rules of existing network ACLs with the same name.
+// The ACLs record the instance they were created for, so they are removed along with it.
+func (t *InternalIncusTarget) ensureNetworkACLs(acls []api.NetworkACL, instanceName string) error {
+	for _, acl := range acls {
+		put := incusAPI.NetworkACLPut{
+			Description: acl.Description,
+			Config:      map[string]string{},
+			Ingress:     make([]incusAPI.NetworkACLRule, 0, len(acl.Ingress)),
+			Egress:      make([]incusAPI.NetworkACLRule, 0, len(acl.Egress)),
+		}
+
+		for _, rule := range acl.Ingress {
+			put.Ingress = append(put.Ingress, toIncusACLRule(rule))
+		}
+
+		for _, rule := range acl.Egress {
+			put.Egress = append(put.Egress, toIncusACLRule(rule))
+		}
+
+		existing, etag, err := t.incusClient.GetNetworkACL(acl.Name)
+		if err != nil && !incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if existing == nil {
+			put.Config[migration.NetworkACLInstanceConfigKey] = instanceName
+			err = t.incusClient.CreateNetworkACL(incusAPI.NetworkACLsPost{NetworkACLPost: incusAPI.NetworkACLPost{Name: acl.Name}, NetworkACLPut: put})
+		} else {
+			maps.Copy(put.Config, existing.Config)
+			put.Config[migration.NetworkACLInstanceConfigKey] = instanceName
+			err = t.incusClient.UpdateNetworkACL(acl.Name, put, etag)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to apply network ACL %q: %w", acl.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// toIncusACLRule converts the network ACL rule to its Incus representation.
+func toIncusACLRule(rule api.NetworkACLRule) incusAPI.NetworkACLRule {
+	return incusAPI.NetworkACLRule{
+		Action:          rule.Action,
+		Source:          rule.Source,
+		Destination:     rule.Destination,
+		Protocol:        rule.Protocol,
+		SourcePort:      rule.SourcePort,
+		DestinationPort: rule.DestinationPort,
+		ICMPType:        rule.ICMPType,
+		ICMPCode:        rule.ICMPCode,
+		Description:     rule.Description,
+		State:           "enabled",
+	}
+}
+
 func (t *InternalIncusTarget) fillInitialProperties(instance incusAPI.InstancesPost, inst migration.Instance, storagePool string, defs properties.RawPropertySet[api.TargetType]) (incusAPI.InstancesPost, error) {
 	diskDefs, err := defs.GetSubProperties(properties.InstanceDisks)
 	if err != nil {
@@ -730,7 +827,46 @@ func (t *InternalIncusTarget) SetupVM(ctx context.Context, instDef migration.Ins
 	return nil
 }
 
-// CleanupVM fully deletes the VM and all of its volumes.
+// deleteNetworkACLs deletes the network ACLs of the NICs of the deleted instance that were generated for it, unless they are used elsewhere.
+func (t *InternalIncusTarget) deleteNetworkACLs(instInfo *incusAPI.Instance) error {
+	aclNames := []string{}
+	for _, dev := range instInfo.Devices {
+		if dev["type"] != "nic" || dev["security.acls"] == "" {
+			continue
+		}
+
+		for _, name := range strings.Split(dev["security.acls"], ",") {
+			if !slices.Contains(aclNames, name) {
+				aclNames = append(aclNames, name)
+			}
+		}
+	}
+
+	for _, name := range aclNames {
+		acl, _, err := t.incusClient.GetNetworkACL(name)
+		if err != nil {
+			if incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
+				continue
+			}
+
+			return fmt.Errorf("Failed to get network ACL %q of instance %q: %w", name, instInfo.Name, err)
+		}
+
+		// Leave network ACLs that were created by someone else, or that are still in use, alone.
+		if acl.Config[migration.NetworkACLInstanceConfigKey] != instInfo.Name || len(acl.UsedBy) > 0 {
+			continue
+		}
+
+		err = t.incusClient.DeleteNetworkACL(name)
+		if err != nil {
+			return fmt.Errorf("Failed to delete network ACL %q of instance %q: %w", name, instInfo.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// CleanupVM fully deletes the VM and all of its volumes, and the network ACLs generated for it.
 func (t *InternalIncusTarget) CleanupVM(ctx context.Context, name string, requireWorkerVolume bool) error {
 	names, err := t.GetInstanceNames()
 	if err != nil {
@@ -773,6 +909,11 @@ func (t *InternalIncusTarget) CleanupVM(ctx context.Context, name string, requir
 		return fmt.Errorf("Failed to wait for delete operation for instance %q: %w", name, err)
 	}
 
+	err = t.deleteNetworkACLs(instInfo)
+	if err != nil {
+		return err
+	}
+
 	volsByPool := map[string]map[string]bool{}
 	for _, dev := range instInfo.Devices {
 		if dev["type"] == "disk" && dev["user.migration_source"] != "" && dev["source"] != "" && dev["pool"] != "" {
@@ -845,6 +986,15 @@ func (t *InternalIncusTarget) StopVM(ctx context.Context, name string, force boo
 	return op.WaitContext(ctx)
 }
 
+func (t *InternalIncusTarget) RenameVM(ctx context.Context, name string, newName string) error {
+	op, err := t.incusClient.RenameInstance(name, incusAPI.InstancePost{Name: newName})
+	if err != nil {
+		return err
+	}
+
+	return op.WaitContext(ctx)
+}
+
 func (t *InternalIncusTarget) PushFile(instanceName string, file string, destDir string) error {
 	fi, err := os.Lstat(file)
 	if err != nil {
@@ -932,6 +1082,17 @@ func (t *InternalIncusTarget) CheckIncusAgent(ctx context.Context, instanceName
 	return fmt.Errorf("Instance failed to start: %w", ctx.Err())
 }
 
+// IsIncusAgentRunning checks once, without waiting, whether the instance is running and its Incus agent is available.
+func (t *InternalIncusTarget) IsIncusAgentRunning(ctx context.Context, instanceName string) (bool, error) {
+	state, _, err := t.incusClient.GetInstanceState(instanceName)
+	if err != nil {
+		return false, fmt.Errorf("Failed to get state of instance %q: %w", instanceName, err)
+	}
+
+	// If there are processes, then infer that the agent is running.
+	return state.StatusCode == incusAPI.Running && state.Processes > 0, nil
+}
+
 func (t *InternalIncusTarget) Exec(ctx context.Context, instanceName string, cmd []string) error {
 	req := incusAPI.InstanceExecPost{
 		Command:     cmd,
@@ -949,18 +1110,166 @@ func (t *InternalIncusTarget) Exec(ctx context.Context, instanceName string, cmd
 	return op.WaitContext(ctx)
 }
 
+// execOutput collects the output of an exec session, which may be written concurrently from stdout and stderr.
+type execOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *execOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+func (t *InternalIncusTarget) RunCommand(ctx context.Context, instanceName string, cmd []string) (int, string, error) {
+	req := incusAPI.InstanceExecPost{
+		Command:     cmd,
+		WaitForWS:   true,
+		Interactive: false,
+	}
+
+	output := &execOutput{}
+	args := incus.InstanceExecArgs{
+		Stdout:   output,
+		Stderr:   output,
+		DataDone: make(chan bool),
+	}
+
+	op, err := t.incusClient.ExecInstance(instanceName, req, &args)
+	if err != nil {
+		return -1, "", err
+	}
+
+	err = op.WaitContext(ctx)
+	if err != nil {
+		return -1, "", err
+	}
+
+	// Wait for all output to be received.
+	select {
+	case <-args.DataDone:
+	case <-ctx.Done():
+		return -1, "", ctx.Err()
+	}
+
+	exitCode, ok := op.Get().Metadata["return"].(float64)
+	if !ok {
+		return -1, "", fmt.Errorf("Failed to determine exit code of command in instance %q", instanceName)
+	}
+
+	output.mu.Lock()
+	defer output.mu.Unlock()
+
+	return int(exitCode), output.buf.String(), nil
+}
+
 func (t *InternalIncusTarget) GetInstanceNames() ([]string, error) {
 	return t.incusClient.GetInstanceNames(incusAPI.InstanceTypeAny)
 }
 
+func (t *InternalIncusTarget) GetProfileNames() ([]string, error) {
+	return t.incusClient.GetProfileNames()
+}
+
 func (t *InternalIncusTarget) GetInstance(name string) (*incusAPI.Instance, string, error) {
 	return t.incusClient.GetInstance(name)
 }
 
+func (t *InternalIncusTarget) GetVolumeUUIDs(ctx context.Context, instanceName string) (map[string]string, error) {
+	inst, _, err := t.incusClient.GetInstance(instanceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Volumes are local to the cluster member that runs the instance.
+	c := t.incusClient.UseTarget(inst.Location)
+	uuids := map[string]string{}
+	for devName, dev := range inst.ExpandedDevices {
+		if dev["type"] != "disk" || dev["pool"] == "" {
+			continue
+		}
+
+		volType := "custom"
+		volName := dev["source"]
+		if dev["path"] == "/" {
+			volType = "virtual-machine"
+			volName = inst.Name
+		}
+
+		vol, _, err := c.GetStoragePoolVolume(dev["pool"], volType, volName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get storage volume of device %q: %w", devName, err)
+		}
+
+		uuids[devName] = vol.Config["volatile.uuid"]
+	}
+
+	return uuids, nil
+}
+
 func (t *InternalIncusTarget) GetNetworkNames() ([]string, error) {
 	return t.incusClient.GetNetworkNames()
 }
 
+func (t *InternalIncusTarget) CreateNetwork(ctx context.Context, network incusAPI.NetworksPost) error {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Bridges on clusters must be defined on each member before they can be created.
+	if network.Type == "bridge" && t.incusClient.IsClustered() {
+		members, err := t.incusClient.GetClusterMemberNames()
+		if err != nil {
+			return err
+		}
+
+		for i, member := range members {
+			err := t.incusClient.UseTarget(member).CreateNetwork(incusAPI.NetworksPost{Name: network.Name, Type: network.Type})
+			if err != nil {
+				return fmt.Errorf("Failed to define network %q on member %q: %w", network.Name, member, err)
+			}
+
+			// Deleting the pending network removes its definition from every member.
+			if i == 0 {
+				reverter.Add(func() {
+					err := t.incusClient.DeleteNetwork(network.Name)
+					if err != nil {
+						slog.Error("Failed to clean up pending network after error", slog.String("network", network.Name), slog.String("target", t.GetName()), slog.Any("error", err))
+					}
+				})
+			}
+		}
+	}
+
+	err := t.incusClient.CreateNetwork(network)
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	return nil
+}
+
+func (t *InternalIncusTarget) DeleteNetwork(ctx context.Context, name string, batchName string) error {
+	network, _, err := t.incusClient.GetNetwork(name)
+	if err != nil {
+		return err
+	}
+
+	// Leave networks that were replaced by someone else alone.
+	if network.Config[migration.TargetNetworkBatchConfigKey] != batchName {
+		return nil
+	}
+
+	if len(network.UsedBy) > 0 {
+		return fmt.Errorf("Network %q is still in use", name)
+	}
+
+	return t.incusClient.DeleteNetwork(name)
+}
+
 func (t *InternalIncusTarget) UpdateInstance(name string, instanceDef incusAPI.InstancePut, ETag string) (incus.Operation, error) {
 	return t.incusClient.UpdateInstance(name, instanceDef, ETag)
 }
@@ -1217,6 +1526,28 @@ type IncusDetails struct {
 	StoragePools       []string
 	NetworksByProject  map[string][]incusAPI.Network
 	InstancesByProject map[string][]string
+
+	// Free space in bytes keyed by storage pool name. Pools that do not report their size are omitted.
+	StoragePoolFreeSpace map[string]int64
+
+	// Remaining resources keyed by project name. Projects without any limits are omitted.
+	ProjectQuotas map[string]ProjectQuota
+
+	// Resources keyed by cluster member name, or by server name for standalone targets.
+	MemberResources map[string]MemberResources
+}
+
+// ProjectQuota is the remaining amount of each resource that a project can allocate, or -1 if the resource is not limited.
+type ProjectQuota struct {
+	CPUs   int64
+	Memory int64
+	Disk   int64
+}
+
+// MemberResources are the resources available to instances on a single target server.
+type MemberResources struct {
+	CPUs       int64
+	FreeMemory int64
 }
 
 // GetDetails fetches top-level details about the entities that exist on the target.
@@ -1257,15 +1588,258 @@ func (t *InternalIncusTarget) GetDetails(ctx context.Context) (*IncusDetails, er
 		instancesByProject[p] = instances
 	}
 
+	projectQuotas := map[string]ProjectQuota{}
+	for _, p := range projects {
+		state, err := t.incusClient.GetProjectState(p)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := func(key string) int64 {
+			res, ok := state.Resources[key]
+			if !ok || res.Limit < 0 {
+				return -1
+			}
+
+			return max(res.Limit-res.Usage, 0)
+		}
+
+		quota := ProjectQuota{CPUs: remaining("cpu"), Memory: remaining("memory"), Disk: remaining("disk")}
+		if quota != (ProjectQuota{CPUs: -1, Memory: -1, Disk: -1}) {
+			projectQuotas[p] = quota
+		}
+	}
+
+	// Each cluster member has its own resources and its own copy of local storage pools.
+	members := map[string]incus.InstanceServer{}
+	if t.incusClient.IsClustered() {
+		names, err := t.incusClient.GetClusterMemberNames()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			members[name] = t.incusClient.UseTarget(name)
+		}
+	} else {
+		srv, _, err := t.incusClient.GetServer()
+		if err != nil {
+			return nil, err
+		}
+
+		members[srv.Environment.ServerName] = t.incusClient
+	}
+
+	memberResources := map[string]MemberResources{}
+	poolFreeSpace := map[string]int64{}
+	for name, client := range members {
+		res, err := client.GetServerResources()
+		if err != nil {
+			return nil, err
+		}
+
+		memberResources[name] = MemberResources{
+			CPUs:       int64(res.CPU.Total),
+			FreeMemory: int64(res.Memory.Total) - int64(res.Memory.Used),
+		}
+
+		for _, pool := range pools {
+			res, err := client.GetStoragePoolResources(pool)
+			if err != nil {
+				slog.Warn("Failed to get storage pool resources, skipping capacity checks for the pool", slog.String("target", t.GetName()), slog.String("member", name), slog.String("pool", pool), slog.Any("error", err))
+				continue
+			}
+
+			if res.Space.Total == 0 {
+				continue
+			}
+
+			// The scheduler may pick any member, so only rely on the smallest amount of free space.
+			free := int64(res.Space.Total) - int64(res.Space.Used)
+			current, ok := poolFreeSpace[pool]
+			if !ok || free < current {
+				poolFreeSpace[pool] = free
+			}
+		}
+	}
+
 	return &IncusDetails{
-		Name:               t.GetName(),
-		Projects:           projects,
-		StoragePools:       pools,
-		NetworksByProject:  networksByProject,
-		InstancesByProject: instancesByProject,
+		Name:                 t.GetName(),
+		Projects:             projects,
+		StoragePools:         pools,
+		NetworksByProject:    networksByProject,
+		InstancesByProject:   instancesByProject,
+		StoragePoolFreeSpace: poolFreeSpace,
+		ProjectQuotas:        projectQuotas,
+		MemberResources:      memberResources,
 	}, nil
 }
 
+// ReserveNetworks returns the networks of the queue entry's placement that do not exist in its target project yet and are created for the batch,
+// and records them so that subsequent calls to CanPlaceInstance account for them.
+func (d *IncusDetails) ReserveNetworks(q migration.QueueEntry, inst migration.Instance, networks migration.Networks, batch migration.Batch) ([]incusAPI.NetworksPost, []api.BatchCreatedNetwork, error) {
+	defs := []incusAPI.NetworksPost{}
+	created := []api.BatchCreatedNetwork{}
+	project := q.Placement.TargetProject
+	if d.NetworksByProject == nil {
+		d.NetworksByProject = map[string][]incusAPI.Network{}
+	}
+
+	for _, hwaddr := range slices.Sorted(maps.Keys(q.Placement.Networks)) {
+		placement := q.Placement.Networks[hwaddr]
+		exists := slices.ContainsFunc(d.NetworksByProject[project], func(n incusAPI.Network) bool { return n.Name == placement.Network })
+		if exists {
+			continue
+		}
+
+		var nicID string
+		for _, nic := range inst.Properties.NICs {
+			if nic.HardwareAddress == hwaddr {
+				nicID = nic.SourceSpecificID
+				break
+			}
+		}
+
+		i := slices.IndexFunc(networks, func(n migration.Network) bool { return n.Source == inst.Source && n.SourceSpecificID == nicID })
+		if i == -1 {
+			return nil, nil, fmt.Errorf("No source network found for NIC %q of instance %q", hwaddr, inst.GetName())
+		}
+
+		def, err := networks[i].TargetNetwork(placement, batch.Name, batch.Config.CreateTargetNetworksUplink)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		d.NetworksByProject[project] = append(d.NetworksByProject[project], incusAPI.Network{Name: def.Name, Type: def.Type, Managed: true, NetworkPut: def.NetworkPut})
+		defs = append(defs, *def)
+		created = append(created, api.BatchCreatedNetwork{
+			Name:          def.Name,
+			Type:          def.Type,
+			Target:        d.Name,
+			TargetProject: project,
+			SourceNetwork: networks[i].Location,
+		})
+	}
+
+	return defs, created, nil
+}
+
+// ReleaseNetworks removes networks previously returned by ReserveNetworks that will not be created after all.
+func (d *IncusDetails) ReleaseNetworks(networks []api.BatchCreatedNetwork) {
+	if d == nil {
+		return
+	}
+
+	for _, n := range networks {
+		d.NetworksByProject[n.TargetProject] = slices.DeleteFunc(d.NetworksByProject[n.TargetProject], func(net incusAPI.Network) bool { return net.Name == n.Name })
+	}
+}
+
+// ReserveCapacity records the storage and project resources that will be consumed by the given queue entry,
+// so that subsequent calls to CanPlaceInstance account for it.
+func (d *IncusDetails) ReserveCapacity(q migration.QueueEntry, inst api.Instance) {
+	props := inst.InstanceProperties
+	props.Apply(inst.Overrides.InstancePropertiesConfigurable)
+
+	usage := requiredPoolCapacity(q.Placement, props)
+	for pool, size := range usage {
+		free, ok := d.StoragePoolFreeSpace[pool]
+		if ok {
+			d.StoragePoolFreeSpace[pool] = free - size
+		}
+	}
+
+	quota, ok := d.ProjectQuotas[q.Placement.TargetProject]
+	if !ok {
+		return
+	}
+
+	if quota.CPUs >= 0 {
+		quota.CPUs -= props.CPUs
+	}
+
+	if quota.Memory >= 0 {
+		quota.Memory -= props.Memory
+	}
+
+	if quota.Disk >= 0 {
+		for _, size := range usage {
+			quota.Disk -= size
+		}
+	}
+
+	d.ProjectQuotas[q.Placement.TargetProject] = quota
+}
+
+// requiredPoolCapacity returns the number of bytes needed in each storage pool to hold the instance's disks.
+// The root disk is always created, while other disks are only created if they are supported.
+func requiredPoolCapacity(placement api.Placement, props api.InstanceProperties) map[string]int64 {
+	usage := map[string]int64{}
+	for i, disk := range props.Disks {
+		if i > 0 && !disk.Supported {
+			continue
+		}
+
+		pool, ok := placement.StoragePools[disk.Name]
+		if ok {
+			usage[pool] += disk.Capacity
+		}
+	}
+
+	return usage
+}
+
+// CheckCapacity verifies that the target has enough resources left to host the instance.
+// Resources that are not reported by the target are not checked.
+func CheckCapacity(info *IncusDetails, placement api.Placement, inst api.Instance) error {
+	props := inst.InstanceProperties
+	props.Apply(inst.Overrides.InstancePropertiesConfigurable)
+
+	usage := requiredPoolCapacity(placement, props)
+	for _, pool := range slices.Sorted(maps.Keys(usage)) {
+		free, ok := info.StoragePoolFreeSpace[pool]
+		if ok && usage[pool] > free {
+			return fmt.Errorf("Storage pool %q on target %q has %s free, but instance disks require %s", pool, info.Name, units.GetByteSizeStringIEC(max(free, 0), 2), units.GetByteSizeStringIEC(usage[pool], 2))
+		}
+	}
+
+	quota, ok := info.ProjectQuotas[placement.TargetProject]
+	if ok {
+		if quota.CPUs >= 0 && props.CPUs > quota.CPUs {
+			return fmt.Errorf("Project %q on target %q has %d CPUs left in its limits, but instance requires %d", placement.TargetProject, info.Name, max(quota.CPUs, 0), props.CPUs)
+		}
+
+		if quota.Memory >= 0 && props.Memory > quota.Memory {
+			return fmt.Errorf("Project %q on target %q has %s of memory left in its limits, but instance requires %s", placement.TargetProject, info.Name, units.GetByteSizeStringIEC(max(quota.Memory, 0), 2), units.GetByteSizeStringIEC(props.Memory, 2))
+		}
+
+		var diskTotal int64
+		for _, size := range usage {
+			diskTotal += size
+		}
+
+		if quota.Disk >= 0 && diskTotal > quota.Disk {
+			return fmt.Errorf("Project %q on target %q has %s of disk space left in its limits, but instance disks require %s", placement.TargetProject, info.Name, units.GetByteSizeStringIEC(max(quota.Disk, 0), 2), units.GetByteSizeStringIEC(diskTotal, 2))
+		}
+	}
+
+	if len(info.MemberResources) > 0 {
+		fits := false
+		for _, res := range info.MemberResources {
+			if res.CPUs >= props.CPUs && res.FreeMemory >= props.Memory {
+				fits = true
+				break
+			}
+		}
+
+		if !fits {
+			return fmt.Errorf("No member of target %q has %d CPUs and %s of free memory available for the instance", info.Name, props.CPUs, units.GetByteSizeStringIEC(props.Memory, 2))
+		}
+	}
+
+	return nil
+}
+
 func CanPlaceInstance(ctx context.Context, info *IncusDetails, q migration.QueueEntry, inst api.Instance, batch api.Batch) error {
 	placement := q.Placement
 	if info == nil {
@@ -1373,5 +1947,13 @@ func CanPlaceInstance(ctx context.Context, info *IncusDetails, q migration.Queue
 		}
 	}
 
+	// Once the import is done, the instance already occupies its resources.
+	if !importDone {
+		err := CheckCapacity(info, placement, inst)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
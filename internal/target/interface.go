@@ -76,7 +76,9 @@ type Target interface {
 	SetProject(project string) error
 
 	// SetPostMigrationVMConfig stops the target instance and applies post-migration configuration before restarting it.
-	SetPostMigrationVMConfig(ctx context.Context, i migration.Instance, q migration.QueueEntry) error
+	// If network ACLs are given, they are created on the target and attached to the corresponding NICs.
+	// If configure is given, it is called with the final instance configuration before it is applied.
+	SetPostMigrationVMConfig(ctx context.Context, i migration.Instance, q migration.QueueEntry, acls *api.InstanceNetworkACLs, configure func(def *incusAPI.InstancePut) error) error
 
 	// Creates a VM definition for use with the Incus REST API.
 	CreateVMDefinition(instanceDef migration.Instance, usedNetworks migration.Networks, q migration.QueueEntry, fingerprint string, endpoint string, targetNetwork api.MigrationNetworkPlacement) (incusAPI.InstancesPost, error)
@@ -96,18 +98,37 @@ type Target interface {
 	// Stops a VM.
 	StopVM(ctx context.Context, name string, force bool) error
 
+	// Renames a stopped VM.
+	RenameVM(ctx context.Context, name string, newName string) error
+
 	// Push a file into a running instance.
 	PushFile(instanceName string, file string, destDir string) error
 
 	// Exec runs a command within an instance and wait for it to complete.
 	Exec(ctx context.Context, instanceName string, cmd []string) error
 
+	// RunCommand runs a command within an instance and waits for it to complete, returning its exit code and combined output.
+	RunCommand(ctx context.Context, instanceName string, cmd []string) (int, string, error)
+
 	// Wrapper around Incus' GetInstanceNames method.
 	GetInstanceNames() ([]string, error)
 
+	// Wrapper around Incus' GetProfileNames method.
+	GetProfileNames() ([]string, error)
+
 	// Wrapper around Incus' GetInstance method.
 	GetInstance(name string) (*incusAPI.Instance, string, error)
 
+	// GetVolumeUUIDs returns the UUIDs of the storage volumes attached to the instance, by device name.
+	GetVolumeUUIDs(ctx context.Context, instanceName string) (map[string]string, error)
+
+	// CreateNetwork creates the network in the current project.
+	CreateNetwork(ctx context.Context, network incusAPI.NetworksPost) error
+
+	// DeleteNetwork deletes the network from the current project if it was created for the given batch.
+	// Returns an error if the network is still in use.
+	DeleteNetwork(ctx context.Context, name string, batchName string) error
+
 	// Wrapper around Incus' UpdateInstance method.
 	UpdateInstance(name string, instanceDef incusAPI.InstancePut, ETag string) (incus.Operation, error)
 
@@ -123,7 +144,10 @@ type Target interface {
 	// CheckIncusAgent repeatedly calls Exec on the instance until the context errors out, or the exec succeeds.
 	CheckIncusAgent(ctx context.Context, instanceName string) error
 
-	// CleanupVM fully deletes the VM and all of its volumes. If requireWorkerVolume is true, the worker volume must be present for the VM to be cleaned up.
+	// IsIncusAgentRunning checks once, without waiting, whether the instance is running and its Incus agent is available.
+	IsIncusAgentRunning(ctx context.Context, instanceName string) (bool, error)
+
+	// CleanupVM fully deletes the VM and all of its volumes, and the network ACLs generated for it. If requireWorkerVolume is true, the worker volume must be present for the VM to be cleaned up.
 	CleanupVM(ctx context.Context, name string, requireWorkerVolume bool) error
 
 	// GetDetails fetches top-level details about the entities that exist on the target.
@@ -35,6 +35,9 @@ var _ Target = &TargetMock{}
 //			ConnectFunc: func(ctx context.Context) error {
 //				panic("mock out the Connect method")
 //			},
+//			CreateNetworkFunc: func(ctx context.Context, network incusAPI.NetworksPost) error {
+//				panic("mock out the CreateNetwork method")
+//			},
 //			CreateNewVMFunc: func(ctx context.Context, instDef migration.Instance, apiDef incusAPI.InstancesPost, placement api.Placement, bootISOImage string) (func(context.Context) error, func(t Target), error) {
 //				panic("mock out the CreateNewVM method")
 //			},
@@ -47,6 +50,9 @@ var _ Target = &TargetMock{}
 //			CreateVMDefinitionFunc: func(instanceDef migration.Instance, usedNetworks migration.Networks, q migration.QueueEntry, fingerprint string, endpoint string, targetNetwork api.MigrationNetworkPlacement) (incusAPI.InstancesPost, error) {
 //				panic("mock out the CreateVMDefinition method")
 //			},
+//			DeleteNetworkFunc: func(ctx context.Context, name string, batchName string) error {
+//				panic("mock out the DeleteNetwork method")
+//			},
 //			DeleteVMFunc: func(ctx context.Context, name string) error {
 //				panic("mock out the DeleteVM method")
 //			},
@@ -71,25 +77,40 @@ var _ Target = &TargetMock{}
 //			GetNameFunc: func() string {
 //				panic("mock out the GetName method")
 //			},
+//			GetProfileNamesFunc: func() ([]string, error) {
+//				panic("mock out the GetProfileNames method")
+//			},
 //			GetPropertiesFunc: func() json.RawMessage {
 //				panic("mock out the GetProperties method")
 //			},
 //			GetStoragePoolVolumeNamesFunc: func(pool string) ([]string, error) {
 //				panic("mock out the GetStoragePoolVolumeNames method")
 //			},
+//			GetVolumeUUIDsFunc: func(ctx context.Context, instanceName string) (map[string]string, error) {
+//				panic("mock out the GetVolumeUUIDs method")
+//			},
 //			IsConnectedFunc: func() bool {
 //				panic("mock out the IsConnected method")
 //			},
+//			IsIncusAgentRunningFunc: func(ctx context.Context, instanceName string) (bool, error) {
+//				panic("mock out the IsIncusAgentRunning method")
+//			},
 //			IsWaitingForOIDCTokensFunc: func() bool {
 //				panic("mock out the IsWaitingForOIDCTokens method")
 //			},
 //			PushFileFunc: func(instanceName string, file string, destDir string) error {
 //				panic("mock out the PushFile method")
 //			},
+//			RenameVMFunc: func(ctx context.Context, name string, newName string) error {
+//				panic("mock out the RenameVM method")
+//			},
+//			RunCommandFunc: func(ctx context.Context, instanceName string, cmd []string) (int, string, error) {
+//				panic("mock out the RunCommand method")
+//			},
 //			SetClientTLSCredentialsFunc: func(key string, cert string) error {
 //				panic("mock out the SetClientTLSCredentials method")
 //			},
-//			SetPostMigrationVMConfigFunc: func(ctx context.Context, i migration.Instance, q migration.QueueEntry) error {
+//			SetPostMigrationVMConfigFunc: func(ctx context.Context, i migration.Instance, q migration.QueueEntry, acls *api.InstanceNetworkACLs, configure func(def *incusAPI.InstancePut) error) error {
 //				panic("mock out the SetPostMigrationVMConfig method")
 //			},
 //			SetProjectFunc: func(project string) error {
@@ -129,6 +150,9 @@ type TargetMock struct {
 	// ConnectFunc mocks the Connect method.
 	ConnectFunc func(ctx context.Context) error
 
+	// CreateNetworkFunc mocks the CreateNetwork method.
+	CreateNetworkFunc func(ctx context.Context, network incusAPI.NetworksPost) error
+
 	// CreateNewVMFunc mocks the CreateNewVM method.
 	CreateNewVMFunc func(ctx context.Context, instDef migration.Instance, apiDef incusAPI.InstancesPost, placement api.Placement, bootISOImage string) (func(context.Context) error, func(t Target), error)
 
@@ -141,6 +165,9 @@ type TargetMock struct {
 	// CreateVMDefinitionFunc mocks the CreateVMDefinition method.
 	CreateVMDefinitionFunc func(instanceDef migration.Instance, usedNetworks migration.Networks, q migration.QueueEntry, fingerprint string, endpoint string, targetNetwork api.MigrationNetworkPlacement) (incusAPI.InstancesPost, error)
 
+	// DeleteNetworkFunc mocks the DeleteNetwork method.
+	DeleteNetworkFunc func(ctx context.Context, name string, batchName string) error
+
 	// DeleteVMFunc mocks the DeleteVM method.
 	DeleteVMFunc func(ctx context.Context, name string) error
 
@@ -165,26 +192,41 @@ type TargetMock struct {
 	// GetNameFunc mocks the GetName method.
 	GetNameFunc func() string
 
+	// GetProfileNamesFunc mocks the GetProfileNames method.
+	GetProfileNamesFunc func() ([]string, error)
+
 	// GetPropertiesFunc mocks the GetProperties method.
 	GetPropertiesFunc func() json.RawMessage
 
 	// GetStoragePoolVolumeNamesFunc mocks the GetStoragePoolVolumeNames method.
 	GetStoragePoolVolumeNamesFunc func(pool string) ([]string, error)
 
+	// GetVolumeUUIDsFunc mocks the GetVolumeUUIDs method.
+	GetVolumeUUIDsFunc func(ctx context.Context, instanceName string) (map[string]string, error)
+
 	// IsConnectedFunc mocks the IsConnected method.
 	IsConnectedFunc func() bool
 
+	// IsIncusAgentRunningFunc mocks the IsIncusAgentRunning method.
+	IsIncusAgentRunningFunc func(ctx context.Context, instanceName string) (bool, error)
+
 	// IsWaitingForOIDCTokensFunc mocks the IsWaitingForOIDCTokens method.
 	IsWaitingForOIDCTokensFunc func() bool
 
 	// PushFileFunc mocks the PushFile method.
 	PushFileFunc func(instanceName string, file string, destDir string) error
 
+	// RenameVMFunc mocks the RenameVM method.
+	RenameVMFunc func(ctx context.Context, name string, newName string) error
+
+	// RunCommandFunc mocks the RunCommand method.
+	RunCommandFunc func(ctx context.Context, instanceName string, cmd []string) (int, string, error)
+
 	// SetClientTLSCredentialsFunc mocks the SetClientTLSCredentials method.
 	SetClientTLSCredentialsFunc func(key string, cert string) error
 
 	// SetPostMigrationVMConfigFunc mocks the SetPostMigrationVMConfig method.
-	SetPostMigrationVMConfigFunc func(ctx context.Context, i migration.Instance, q migration.QueueEntry) error
+	SetPostMigrationVMConfigFunc func(ctx context.Context, i migration.Instance, q migration.QueueEntry, acls *api.InstanceNetworkACLs, configure func(def *incusAPI.InstancePut) error) error
 
 	// SetProjectFunc mocks the SetProject method.
 	SetProjectFunc func(project string) error
@@ -230,6 +272,13 @@ type TargetMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// CreateNetwork holds details about calls to the CreateNetwork method.
+		CreateNetwork []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Network is the network argument value.
+			Network incusAPI.NetworksPost
+		}
 		// CreateNewVM holds details about calls to the CreateNewVM method.
 		CreateNewVM []struct {
 			// Ctx is the ctx argument value.
@@ -278,6 +327,15 @@ type TargetMock struct {
 			// TargetNetwork is the targetNetwork argument value.
 			TargetNetwork api.MigrationNetworkPlacement
 		}
+		// DeleteNetwork holds details about calls to the DeleteNetwork method.
+		DeleteNetwork []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Name is the name argument value.
+			Name string
+			// BatchName is the batchName argument value.
+			BatchName string
+		}
 		// DeleteVM holds details about calls to the DeleteVM method.
 		DeleteVM []struct {
 			// Ctx is the ctx argument value.
@@ -318,6 +376,9 @@ type TargetMock struct {
 		// GetName holds details about calls to the GetName method.
 		GetName []struct {
 		}
+		// GetProfileNames holds details about calls to the GetProfileNames method.
+		GetProfileNames []struct {
+		}
 		// GetProperties holds details about calls to the GetProperties method.
 		GetProperties []struct {
 		}
@@ -326,9 +387,23 @@ type TargetMock struct {
 			// Pool is the pool argument value.
 			Pool string
 		}
+		// GetVolumeUUIDs holds details about calls to the GetVolumeUUIDs method.
+		GetVolumeUUIDs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+		}
 		// IsConnected holds details about calls to the IsConnected method.
 		IsConnected []struct {
 		}
+		// IsIncusAgentRunning holds details about calls to the IsIncusAgentRunning method.
+		IsIncusAgentRunning []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+		}
 		// IsWaitingForOIDCTokens holds details about calls to the IsWaitingForOIDCTokens method.
 		IsWaitingForOIDCTokens []struct {
 		}
@@ -341,6 +416,24 @@ type TargetMock struct {
 			// DestDir is the destDir argument value.
 			DestDir string
 		}
+		// RenameVM holds details about calls to the RenameVM method.
+		RenameVM []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Name is the name argument value.
+			Name string
+			// NewName is the newName argument value.
+			NewName string
+		}
+		// RunCommand holds details about calls to the RunCommand method.
+		RunCommand []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceName is the instanceName argument value.
+			InstanceName string
+			// Cmd is the cmd argument value.
+			Cmd []string
+		}
 		// SetClientTLSCredentials holds details about calls to the SetClientTLSCredentials method.
 		SetClientTLSCredentials []struct {
 			// Key is the key argument value.
@@ -356,6 +449,10 @@ type TargetMock struct {
 			I migration.Instance
 			// Q is the q argument value.
 			Q migration.QueueEntry
+			// Acls is the acls argument value.
+			Acls *api.InstanceNetworkACLs
+			// Configure is the configure argument value.
+			Configure func(def *incusAPI.InstancePut) error
 		}
 		// SetProject holds details about calls to the SetProject method.
 		SetProject []struct {
@@ -410,10 +507,12 @@ type TargetMock struct {
 	lockCheckIncusAgent                   sync.RWMutex
 	lockCleanupVM                         sync.RWMutex
 	lockConnect                           sync.RWMutex
+	lockCreateNetwork                     sync.RWMutex
 	lockCreateNewVM                       sync.RWMutex
 	lockCreateStoragePoolVolumeFromBackup sync.RWMutex
 	lockCreateStoragePoolVolumeFromISO    sync.RWMutex
 	lockCreateVMDefinition                sync.RWMutex
+	lockDeleteNetwork                     sync.RWMutex
 	lockDeleteVM                          sync.RWMutex
 	lockDisconnect                        sync.RWMutex
 	lockDoBasicConnectivityCheck          sync.RWMutex
@@ -422,11 +521,16 @@ type TargetMock struct {
 	lockGetInstance                       sync.RWMutex
 	lockGetInstanceNames                  sync.RWMutex
 	lockGetName                           sync.RWMutex
+	lockGetProfileNames                   sync.RWMutex
 	lockGetProperties                     sync.RWMutex
 	lockGetStoragePoolVolumeNames         sync.RWMutex
+	lockGetVolumeUUIDs                    sync.RWMutex
 	lockIsConnected                       sync.RWMutex
+	lockIsIncusAgentRunning               sync.RWMutex
 	lockIsWaitingForOIDCTokens            sync.RWMutex
 	lockPushFile                          sync.RWMutex
+	lockRenameVM                          sync.RWMutex
+	lockRunCommand                        sync.RWMutex
 	lockSetClientTLSCredentials           sync.RWMutex
 	lockSetPostMigrationVMConfig          sync.RWMutex
 	lockSetProject                        sync.RWMutex
@@ -546,6 +650,42 @@ func (mock *TargetMock) ConnectCalls() []struct {
 	return calls
 }
 
+// CreateNetwork calls CreateNetworkFunc.
+func (mock *TargetMock) CreateNetwork(ctx context.Context, network incusAPI.NetworksPost) error {
+	if mock.CreateNetworkFunc == nil {
+		panic("TargetMock.CreateNetworkFunc: method is nil but Target.CreateNetwork was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Network incusAPI.NetworksPost
+	}{
+		Ctx:     ctx,
+		Network: network,
+	}
+	mock.lockCreateNetwork.Lock()
+	mock.calls.CreateNetwork = append(mock.calls.CreateNetwork, callInfo)
+	mock.lockCreateNetwork.Unlock()
+	return mock.CreateNetworkFunc(ctx, network)
+}
+
+// CreateNetworkCalls gets all the calls that were made to CreateNetwork.
+// Check the length with:
+//
+//	len(mockedTarget.CreateNetworkCalls())
+func (mock *TargetMock) CreateNetworkCalls() []struct {
+	Ctx     context.Context
+	Network incusAPI.NetworksPost
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Network incusAPI.NetworksPost
+	}
+	mock.lockCreateNetwork.RLock()
+	calls = mock.calls.CreateNetwork
+	mock.lockCreateNetwork.RUnlock()
+	return calls
+}
+
 // CreateNewVM calls CreateNewVMFunc.
 func (mock *TargetMock) CreateNewVM(ctx context.Context, instDef migration.Instance, apiDef incusAPI.InstancesPost, placement api.Placement, bootISOImage string) (func(context.Context) error, func(t Target), error) {
 	if mock.CreateNewVMFunc == nil {
@@ -730,6 +870,46 @@ func (mock *TargetMock) CreateVMDefinitionCalls() []struct {
 	return calls
 }
 
+// DeleteNetwork calls DeleteNetworkFunc.
+func (mock *TargetMock) DeleteNetwork(ctx context.Context, name string, batchName string) error {
+	if mock.DeleteNetworkFunc == nil {
+		panic("TargetMock.DeleteNetworkFunc: method is nil but Target.DeleteNetwork was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		Name      string
+		BatchName string
+	}{
+		Ctx:       ctx,
+		Name:      name,
+		BatchName: batchName,
+	}
+	mock.lockDeleteNetwork.Lock()
+	mock.calls.DeleteNetwork = append(mock.calls.DeleteNetwork, callInfo)
+	mock.lockDeleteNetwork.Unlock()
+	return mock.DeleteNetworkFunc(ctx, name, batchName)
+}
+
+// DeleteNetworkCalls gets all the calls that were made to DeleteNetwork.
+// Check the length with:
+//
+//	len(mockedTarget.DeleteNetworkCalls())
+func (mock *TargetMock) DeleteNetworkCalls() []struct {
+	Ctx       context.Context
+	Name      string
+	BatchName string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		Name      string
+		BatchName string
+	}
+	mock.lockDeleteNetwork.RLock()
+	calls = mock.calls.DeleteNetwork
+	mock.lockDeleteNetwork.RUnlock()
+	return calls
+}
+
 // DeleteVM calls DeleteVMFunc.
 func (mock *TargetMock) DeleteVM(ctx context.Context, name string) error {
 	if mock.DeleteVMFunc == nil {
@@ -983,6 +1163,33 @@ func (mock *TargetMock) GetNameCalls() []struct {
 	return calls
 }
 
+// GetProfileNames calls GetProfileNamesFunc.
+func (mock *TargetMock) GetProfileNames() ([]string, error) {
+	if mock.GetProfileNamesFunc == nil {
+		panic("TargetMock.GetProfileNamesFunc: method is nil but Target.GetProfileNames was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockGetProfileNames.Lock()
+	mock.calls.GetProfileNames = append(mock.calls.GetProfileNames, callInfo)
+	mock.lockGetProfileNames.Unlock()
+	return mock.GetProfileNamesFunc()
+}
+
+// GetProfileNamesCalls gets all the calls that were made to GetProfileNames.
+// Check the length with:
+//
+//	len(mockedTarget.GetProfileNamesCalls())
+func (mock *TargetMock) GetProfileNamesCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetProfileNames.RLock()
+	calls = mock.calls.GetProfileNames
+	mock.lockGetProfileNames.RUnlock()
+	return calls
+}
+
 // GetProperties calls GetPropertiesFunc.
 func (mock *TargetMock) GetProperties() json.RawMessage {
 	if mock.GetPropertiesFunc == nil {
@@ -1042,6 +1249,42 @@ func (mock *TargetMock) GetStoragePoolVolumeNamesCalls() []struct {
 	return calls
 }
 
+// GetVolumeUUIDs calls GetVolumeUUIDsFunc.
+func (mock *TargetMock) GetVolumeUUIDs(ctx context.Context, instanceName string) (map[string]string, error) {
+	if mock.GetVolumeUUIDsFunc == nil {
+		panic("TargetMock.GetVolumeUUIDsFunc: method is nil but Target.GetVolumeUUIDs was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		InstanceName string
+	}{
+		Ctx:          ctx,
+		InstanceName: instanceName,
+	}
+	mock.lockGetVolumeUUIDs.Lock()
+	mock.calls.GetVolumeUUIDs = append(mock.calls.GetVolumeUUIDs, callInfo)
+	mock.lockGetVolumeUUIDs.Unlock()
+	return mock.GetVolumeUUIDsFunc(ctx, instanceName)
+}
+
+// GetVolumeUUIDsCalls gets all the calls that were made to GetVolumeUUIDs.
+// Check the length with:
+//
+//	len(mockedTarget.GetVolumeUUIDsCalls())
+func (mock *TargetMock) GetVolumeUUIDsCalls() []struct {
+	Ctx          context.Context
+	InstanceName string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		InstanceName string
+	}
+	mock.lockGetVolumeUUIDs.RLock()
+	calls = mock.calls.GetVolumeUUIDs
+	mock.lockGetVolumeUUIDs.RUnlock()
+	return calls
+}
+
 // IsConnected calls IsConnectedFunc.
 func (mock *TargetMock) IsConnected() bool {
 	if mock.IsConnectedFunc == nil {
@@ -1069,6 +1312,42 @@ func (mock *TargetMock) IsConnectedCalls() []struct {
 	return calls
 }
 
+// IsIncusAgentRunning calls IsIncusAgentRunningFunc.
+func (mock *TargetMock) IsIncusAgentRunning(ctx context.Context, instanceName string) (bool, error) {
+	if mock.IsIncusAgentRunningFunc == nil {
+		panic("TargetMock.IsIncusAgentRunningFunc: method is nil but Target.IsIncusAgentRunning was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		InstanceName string
+	}{
+		Ctx:          ctx,
+		InstanceName: instanceName,
+	}
+	mock.lockIsIncusAgentRunning.Lock()
+	mock.calls.IsIncusAgentRunning = append(mock.calls.IsIncusAgentRunning, callInfo)
+	mock.lockIsIncusAgentRunning.Unlock()
+	return mock.IsIncusAgentRunningFunc(ctx, instanceName)
+}
+
+// IsIncusAgentRunningCalls gets all the calls that were made to IsIncusAgentRunning.
+// Check the length with:
+//
+//	len(mockedTarget.IsIncusAgentRunningCalls())
+func (mock *TargetMock) IsIncusAgentRunningCalls() []struct {
+	Ctx          context.Context
+	InstanceName string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		InstanceName string
+	}
+	mock.lockIsIncusAgentRunning.RLock()
+	calls = mock.calls.IsIncusAgentRunning
+	mock.lockIsIncusAgentRunning.RUnlock()
+	return calls
+}
+
 // IsWaitingForOIDCTokens calls IsWaitingForOIDCTokensFunc.
 func (mock *TargetMock) IsWaitingForOIDCTokens() bool {
 	if mock.IsWaitingForOIDCTokensFunc == nil {
@@ -1136,6 +1415,86 @@ func (mock *TargetMock) PushFileCalls() []struct {
 	return calls
 }
 
+// RenameVM calls RenameVMFunc.
+func (mock *TargetMock) RenameVM(ctx context.Context, name string, newName string) error {
+	if mock.RenameVMFunc == nil {
+		panic("TargetMock.RenameVMFunc: method is nil but Target.RenameVM was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Name    string
+		NewName string
+	}{
+		Ctx:     ctx,
+		Name:    name,
+		NewName: newName,
+	}
+	mock.lockRenameVM.Lock()
+	mock.calls.RenameVM = append(mock.calls.RenameVM, callInfo)
+	mock.lockRenameVM.Unlock()
+	return mock.RenameVMFunc(ctx, name, newName)
+}
+
+// RenameVMCalls gets all the calls that were made to RenameVM.
+// Check the length with:
+//
+//	len(mockedTarget.RenameVMCalls())
+func (mock *TargetMock) RenameVMCalls() []struct {
+	Ctx     context.Context
+	Name    string
+	NewName string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Name    string
+		NewName string
+	}
+	mock.lockRenameVM.RLock()
+	calls = mock.calls.RenameVM
+	mock.lockRenameVM.RUnlock()
+	return calls
+}
+
+// RunCommand calls RunCommandFunc.
+func (mock *TargetMock) RunCommand(ctx context.Context, instanceName string, cmd []string) (int, string, error) {
+	if mock.RunCommandFunc == nil {
+		panic("TargetMock.RunCommandFunc: method is nil but Target.RunCommand was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		InstanceName string
+		Cmd          []string
+	}{
+		Ctx:          ctx,
+		InstanceName: instanceName,
+		Cmd:          cmd,
+	}
+	mock.lockRunCommand.Lock()
+	mock.calls.RunCommand = append(mock.calls.RunCommand, callInfo)
+	mock.lockRunCommand.Unlock()
+	return mock.RunCommandFunc(ctx, instanceName, cmd)
+}
+
+// RunCommandCalls gets all the calls that were made to RunCommand.
+// Check the length with:
+//
+//	len(mockedTarget.RunCommandCalls())
+func (mock *TargetMock) RunCommandCalls() []struct {
+	Ctx          context.Context
+	InstanceName string
+	Cmd          []string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		InstanceName string
+		Cmd          []string
+	}
+	mock.lockRunCommand.RLock()
+	calls = mock.calls.RunCommand
+	mock.lockRunCommand.RUnlock()
+	return calls
+}
+
 // SetClientTLSCredentials calls SetClientTLSCredentialsFunc.
 func (mock *TargetMock) SetClientTLSCredentials(key string, cert string) error {
 	if mock.SetClientTLSCredentialsFunc == nil {
@@ -1173,23 +1532,27 @@ func (mock *TargetMock) SetClientTLSCredentialsCalls() []struct {
 }
 
 // SetPostMigrationVMConfig calls SetPostMigrationVMConfigFunc.
-func (mock *TargetMock) SetPostMigrationVMConfig(ctx context.Context, i migration.Instance, q migration.QueueEntry) error {
+func (mock *TargetMock) SetPostMigrationVMConfig(ctx context.Context, i migration.Instance, q migration.QueueEntry, acls *api.InstanceNetworkACLs, configure func(def *incusAPI.InstancePut) error) error {
 	if mock.SetPostMigrationVMConfigFunc == nil {
 		panic("TargetMock.SetPostMigrationVMConfigFunc: method is nil but Target.SetPostMigrationVMConfig was just called")
 	}
 	callInfo := struct {
-		Ctx context.Context
-		I   migration.Instance
-		Q   migration.QueueEntry
+		Ctx       context.Context
+		I         migration.Instance
+		Q         migration.QueueEntry
+		Acls      *api.InstanceNetworkACLs
+		Configure func(def *incusAPI.InstancePut) error
 	}{
-		Ctx: ctx,
-		I:   i,
-		Q:   q,
+		Ctx:       ctx,
+		I:         i,
+		Q:         q,
+		Acls:      acls,
+		Configure: configure,
 	}
 	mock.lockSetPostMigrationVMConfig.Lock()
 	mock.calls.SetPostMigrationVMConfig = append(mock.calls.SetPostMigrationVMConfig, callInfo)
 	mock.lockSetPostMigrationVMConfig.Unlock()
-	return mock.SetPostMigrationVMConfigFunc(ctx, i, q)
+	return mock.SetPostMigrationVMConfigFunc(ctx, i, q, acls, configure)
 }
 
 // SetPostMigrationVMConfigCalls gets all the calls that were made to SetPostMigrationVMConfig.
@@ -1197,14 +1560,18 @@ func (mock *TargetMock) SetPostMigrationVMConfig(ctx context.Context, i migratio
 //
 //	len(mockedTarget.SetPostMigrationVMConfigCalls())
 func (mock *TargetMock) SetPostMigrationVMConfigCalls() []struct {
-	Ctx context.Context
-	I   migration.Instance
-	Q   migration.QueueEntry
+	Ctx       context.Context
+	I         migration.Instance
+	Q         migration.QueueEntry
+	Acls      *api.InstanceNetworkACLs
+	Configure func(def *incusAPI.InstancePut) error
 } {
 	var calls []struct {
-		Ctx context.Context
-		I   migration.Instance
-		Q   migration.QueueEntry
+		Ctx       context.Context
+		I         migration.Instance
+		Q         migration.QueueEntry
+		Acls      *api.InstanceNetworkACLs
+		Configure func(def *incusAPI.InstancePut) error
 	}
 	mock.lockSetPostMigrationVMConfig.RLock()
 	calls = mock.calls.SetPostMigrationVMConfig
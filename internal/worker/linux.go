@@ -6,6 +6,7 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	incusAPI "github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/util"
@@ -55,29 +57,34 @@ const (
 	logDir          string = "migration-manager"
 )
 
-func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, distro api.Distro, distroVersion string, dryRun bool) error {
+func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, distro api.Distro, distroVersion string, scripts []UserScript, dryRun bool) ([]api.PostMigrationScriptResult, error) {
 	// Clear any existing logs from a previousr run.
 	err := os.RemoveAll(filepath.Join("/tmp", logDir))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = os.MkdirAll(filepath.Join("/tmp", logDir), 0o755)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get the disto's major version, if possible.
 	if distro == api.DISTRO_OTHER {
+		// User-provided scripts cannot be skipped silently, as the instance would otherwise be left without the configuration they apply.
+		if len(scripts) > 0 {
+			return nil, fmt.Errorf("Could not determine Linux distribution, cannot run %d post-migration scripts", len(scripts))
+		}
+
 		slog.Info("Could not determine Linux distribution, not performing any post-migration actions")
-		return nil
+		return nil, nil
 	}
 
 	slog.Info("Preparing to perform post-migration configuration of VM")
 
 	err = cleanupClones()
 	if err != nil {
-		return fmt.Errorf("Failed to attempt cleanup of stale clone state")
+		return nil, fmt.Errorf("Failed to attempt cleanup of stale clone state")
 	}
 
 	defer func() { _ = cleanupClones() }()
@@ -85,7 +92,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	// Determine the root partition.
 	rootParent, rootPart, rootType, rootOpts, err := determineRootPartition(looksLikeLinuxRootPartition)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var mappings map[string]map[string]string
@@ -93,12 +100,12 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	if dryRun {
 		plan, err = getRequiredMounts(looksLikeLinuxRootPartition)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		mappings, err = setupDiskClone(plan)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		defer func() { _ = DeactivateVG() }()
@@ -116,7 +123,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 				slog.Info("Activating volume groups with filter", slog.String("config", filter), slog.String("vg_name", vgName))
 				err := ActivateVG(filter, vgName)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
 
@@ -129,7 +136,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 				if partType == PARTITION_TYPE_PLAIN && src == rootParent {
 					clonePart, err := getMatchingPartition(rootPart, clone)
 					if err != nil {
-						return err
+						return nil, err
 					}
 
 					rootPart = clonePart
@@ -138,14 +145,14 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 				// After activating the VG, ensure the mapping is to a loop device if performing dry-run.
 				err := ensureMountIsLoop(clone, partType)
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
 		}
 
 		err = ensureMountIsLoop(rootPart, rootType)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -153,7 +160,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	if !dryRun && rootType == PARTITION_TYPE_LVM {
 		err := ActivateVG()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		defer func() { _ = DeactivateVG() }()
@@ -162,7 +169,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	// Mount the migrated root partition.
 	err = DoMount(rootPart, chrootMountPath, rootOpts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() { _ = DoUnmount(chrootMountPath) }()
@@ -170,21 +177,21 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	// Bind-mount /dev/, /proc/ and /sys/ into the chroot.
 	err = DoMount("/dev/", filepath.Join(chrootMountPath, "dev"), []string{"-o", "bind"})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() { _ = DoUnmount(filepath.Join(chrootMountPath, "dev")) }()
 
 	err = DoMount("/proc/", filepath.Join(chrootMountPath, "proc"), []string{"-o", "bind"})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() { _ = DoUnmount(filepath.Join(chrootMountPath, "proc")) }()
 
 	err = DoMount("/sys/", filepath.Join(chrootMountPath, "sys"), []string{"-o", "bind"})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() { _ = DoUnmount(filepath.Join(chrootMountPath, "sys")) }()
@@ -192,14 +199,14 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	// Sometimes resolv.conf is a symlink to /run/... which wouldn't exist in a chroot.'
 	_, err = os.Stat(filepath.Join(chrootMountPath, "etc", "resolv.conf"))
 	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("Failed to parse resolv.conf: %w", err)
+		return nil, fmt.Errorf("Failed to parse resolv.conf: %w", err)
 	}
 
 	if err != nil {
 		slog.Info("Bind-mounting /etc/resolv.conf")
 		err = DoMount("/etc/resolv.conf", filepath.Join(chrootMountPath, "etc", "resolv.conf"), []string{"-o", "bind"})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		defer func() { _ = DoUnmount(filepath.Join(chrootMountPath, "etc", "resolv.conf")) }()
@@ -228,7 +235,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 				if ok {
 					part, err := getMatchingPartition(p.Path, clone)
 					if err != nil {
-						return err
+						return nil, err
 					}
 
 					log.Info("Found a matching fstab clone", slog.String("clone", part))
@@ -241,7 +248,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 		log.Info("Mounting the disk from fstab", slog.String("device", dev), slog.String("target", filepath.Join(chrootMountPath, mnt["path"])), slog.String("opts", strings.Join(opts, ",")))
 		err := DoMount(dev, filepath.Join(chrootMountPath, mnt["path"]), opts)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		defer func() { _ = DoUnmount(filepath.Join(chrootMountPath, mnt["path"])) }() //nolint: revive
@@ -251,25 +258,25 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	if distro != api.DISTRO_UBUNTU && distroVersion != "" {
 		versionInt, err = strconv.Atoi(distroVersion)
 		if err != nil {
-			return fmt.Errorf("Failed to parse distro version %q for distro %q: %w", distroVersion, distro, err)
+			return nil, fmt.Errorf("Failed to parse distro version %q for distro %q: %w", distroVersion, distro, err)
 		}
 	}
 
 	// Install incus-agent into the VM.
 	err = runScriptInChroot("install-incus-agent.sh")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if distro.IsRHELDerivative() && distro != api.DISTRO_AMZN {
 		err := runScriptInChroot("redhat-purge-open-vm-tools.sh")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		err = runScriptInChroot("dracut-add-virtio-drivers.sh", string(rootType))
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if distroVersion != "" {
@@ -277,7 +284,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 			if versionInt > 0 && versionInt <= 7 {
 				err := runScriptInChroot("add-incus-agent-override-for-old-systemd.sh")
 				if err != nil {
-					return err
+					return nil, err
 				}
 			}
 		}
@@ -287,7 +294,7 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 		if distroVersion != "" && versionInt <= 8 {
 			err := runScriptInChroot("add-incus-agent-override-for-old-systemd.sh")
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
@@ -296,53 +303,69 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 	case api.DISTRO_DEBIAN, api.DISTRO_UBUNTU:
 		err := runScriptInChroot("debian-purge-open-vm-tools.sh")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 	case api.DISTRO_SUSE:
 		err := runScriptInChroot("suse-purge-open-vm-tools.sh")
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		err = runScriptInChroot("dracut-add-virtio-drivers.sh", string(rootType))
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	c := internalUtil.UnixHTTPClient("/dev/incus/sock")
-	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	reqCtx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unix.socket/1.0/config/user.migration.hwaddrs", nil)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, "http://unix.socket/1.0/config/user.migration.hwaddrs", nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resp, err := c.Do(req)
 	if err != nil && !incusAPI.StatusErrorCheck(err, http.StatusNotFound) {
-		return err
+		return nil, err
 	}
 
 	if err == nil {
 		defer func() { _ = resp.Body.Close() }()
 		out, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Setup udev rules to create network device aliases.
 		err = runScriptInChroot("add-udev-network-rules.sh", string(out))
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	if !instance.LegacyBoot {
 		err := runScriptInChroot("reinstall-grub-uefi.sh")
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	var results []api.PostMigrationScriptResult
+	if !dryRun {
+		// Run user-provided scripts last, so that they see the instance as it will boot on the target.
+		for _, script := range scripts {
+			result, err := runUserScriptInChroot(ctx, script)
+			if err != nil {
+				return results, err
+			}
+
+			results = append(results, result)
+			if result.ExitCode != 0 {
+				return results, fmt.Errorf("Post-migration script %q exited with code %d: %s", result.File, result.ExitCode, result.Output)
+			}
 		}
 	}
 
@@ -351,12 +374,12 @@ func LinuxDoPostMigrationConfig(ctx context.Context, instance api.Instance, dist
 		tgtDir := filepath.Join(chrootMountPath, "var/log", logDir)
 		err = internalUtil.DirCopy(srcDir, tgtDir)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	slog.Info("Post-migration configuration complete!")
-	return nil
+	return results, nil
 }
 
 func ActivateVG(opts ...string) error {
@@ -481,6 +504,94 @@ func runScriptInChroot(scriptName string, args ...string) error {
 	return nil
 }
 
+// maxUserScriptOutput is the amount of output from a user-provided script that is reported back to migration manager.
+const maxUserScriptOutput = 4096
+
+// defaultUserScriptTimeout is used for user-provided scripts that do not set a timeout, matching first-boot scripts.
+const defaultUserScriptTimeout = 10 * time.Minute
+
+// UserScript is a user-provided post-migration script, run within the chroot of the migrated root partition.
+type UserScript struct {
+	// Artifact is the UUID of the script artifact that the file belongs to.
+	Artifact uuid.UUID
+
+	// Path is the location of the script file on the worker.
+	Path string
+
+	// Timeout is how long the script file can run before it is stopped.
+	Timeout time.Duration
+}
+
+func runUserScriptInChroot(ctx context.Context, script UserScript) (api.PostMigrationScriptResult, error) {
+	fileName := filepath.Base(script.Path)
+	chrootName := "migration-manager-script-" + fileName
+
+	slog.Info("Executing user script", slog.String("artifact", script.Artifact.String()), slog.String("file", fileName))
+	content, err := os.ReadFile(script.Path)
+	if err != nil {
+		return api.PostMigrationScriptResult{}, err
+	}
+
+	err = os.WriteFile(filepath.Join(chrootMountPath, chrootName), content, 0o755)
+	if err != nil {
+		return api.PostMigrationScriptResult{}, err
+	}
+
+	defer func() { _ = os.Remove(filepath.Join(chrootMountPath, chrootName)) }()
+
+	f, err := os.Create(filepath.Join("/tmp", logDir, "user-script-"+fileName+".log"))
+	if err != nil {
+		return api.PostMigrationScriptResult{}, err
+	}
+
+	defer f.Close()
+
+	timeout := script.Timeout
+	if timeout == 0 {
+		timeout = defaultUserScriptTimeout
+	}
+
+	scriptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(scriptCtx, "chroot", chrootMountPath, filepath.Join("/", chrootName))
+	cmd.Env = []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}
+	cmd.Stdout = io.MultiWriter(f, &output)
+	cmd.Stderr = io.MultiWriter(f, &output)
+
+	// Don't wait for background processes of a stopped script that still hold its output open.
+	cmd.WaitDelay = 10 * time.Second
+	err = cmd.Run()
+	if err != nil && scriptCtx.Err() != nil && ctx.Err() == nil {
+		return api.PostMigrationScriptResult{}, fmt.Errorf("Post-migration script %q timed out after %s: %w", fileName, timeout, err)
+	}
+
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return api.PostMigrationScriptResult{}, fmt.Errorf("Failed to run post-migration script %q: %w", fileName, err)
+		}
+
+		exitCode = exitErr.ExitCode()
+	}
+
+	out := strings.TrimSpace(output.String())
+	if len(out) > maxUserScriptOutput {
+		out = "..." + out[len(out)-maxUserScriptOutput:]
+	}
+
+	return api.PostMigrationScriptResult{
+		Artifact: script.Artifact,
+		File:     fileName,
+		Stage:    api.POSTMIGRATIONSCRIPTSTAGE_WORKER,
+		ExitCode: exitCode,
+		Output:   out,
+		Date:     time.Now().UTC(),
+	}, nil
+}
+
 func scanVGs() (LVSOutput, error) {
 	ret := LVSOutput{}
 	output, err := subprocess.RunCommand("lvs", "-o", "vg_name,lv_name", "--reportformat", "json")
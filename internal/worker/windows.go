@@ -3,9 +3,11 @@ package worker
 import (
 	"bufio"
 	"context"
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,6 +29,7 @@ import (
 
 	"github.com/FuturFusion/migration-manager/internal/logger"
 	internalUtil "github.com/FuturFusion/migration-manager/internal/util"
+	mmapi "github.com/FuturFusion/migration-manager/shared/api"
 )
 
 type BitLockerState int
@@ -152,7 +155,8 @@ func WindowsDetectBitLockerStatus(partition string) (BitLockerState, error) {
 	return BITLOCKERSTATE_UNKNOWN, fmt.Errorf("Failed to determine BitLocker status for %s", partition)
 }
 
-func WindowsOpenBitLockerPartition(partition string, encryptionKey string) error {
+// WindowsOpenBitLockerPartition unlocks the BitLocker partition with the given key, or with the clear key if no key is given.
+func WindowsOpenBitLockerPartition(partition string, key *mmapi.BitLockerKey) error {
 	if !util.PathExists(bitLockerMountPath) {
 		err := os.MkdirAll(bitLockerMountPath, 0o755)
 		if err != nil {
@@ -160,16 +164,57 @@ func WindowsOpenBitLockerPartition(partition string, encryptionKey string) error
 		}
 	}
 
-	if encryptionKey == "" {
-		_, err := subprocess.RunCommand("dislocker-fuse", "-V", partition, "--clearkey", "--", bitLockerMountPath)
-		return err
+	args := []string{"-V", partition}
+	switch {
+	case key == nil:
+		args = append(args, "--clearkey")
+	case key.RecoveryPassword != "":
+		args = append(args, "--recovery-password="+key.RecoveryPassword)
+	case key.KeyFile != "":
+		keyFile, err := base64.StdEncoding.DecodeString(key.KeyFile)
+		if err != nil {
+			return fmt.Errorf("Failed to decode BitLocker key file: %w", err)
+		}
+
+		f, err := os.CreateTemp("", "bitlocker_*.bek")
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = os.Remove(f.Name()) }()
+
+		_, err = f.Write(keyFile)
+		if err != nil {
+			_ = f.Close()
+			return fmt.Errorf("Failed to write BitLocker key file: %w", err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			return fmt.Errorf("Failed to write BitLocker key file: %w", err)
+		}
+
+		args = append(args, "--bekfile="+f.Name())
+	default:
+		return fmt.Errorf("No BitLocker recovery password or key file provided")
 	}
 
-	_, err := subprocess.RunCommand("dislocker-fuse", "-V", partition, "--recovery-password="+encryptionKey, "--", bitLockerMountPath)
-	return err
+	args = append(args, "--", bitLockerMountPath)
+	_, err := subprocess.RunCommand("dislocker-fuse", args...)
+	if err != nil {
+		// Avoid including the command line in the error, as it may contain the recovery password.
+		var runErr subprocess.RunError
+		if errors.As(err, &runErr) {
+			return fmt.Errorf("Failed to unlock BitLocker partition %q: %s", partition, strings.TrimSpace(runErr.StdErr().String()))
+		}
+
+		return fmt.Errorf("Failed to unlock BitLocker partition %q", partition)
+	}
+
+	return nil
 }
 
-func WindowsInjectDrivers(ctx context.Context, distroVersion string, osArchitecture, isoFile string, dryRun bool) error {
+func WindowsInjectDrivers(ctx context.Context, distroVersion string, osArchitecture, isoFile string, bitLockerKey *mmapi.BitLockerKey, dryRun bool) error {
 	slog.Info("Preparing to inject Windows drivers into VM")
 	// Clear any existing logs from a previousr run.
 	err := os.RemoveAll(filepath.Join("/tmp", logDir))
@@ -304,8 +349,17 @@ func WindowsInjectDrivers(ctx context.Context, distroVersion string, osArchitect
 		}
 
 		defer func() { _ = DoUnmount(windowsMainMountPath) }()
-	case BITLOCKERSTATE_CLEARKEY:
-		err = WindowsOpenBitLockerPartition(mainPartition, "")
+	case BITLOCKERSTATE_CLEARKEY, BITLOCKERSTATE_ENCRYPTED:
+		var key *mmapi.BitLockerKey
+		if bitLockerStatus == BITLOCKERSTATE_ENCRYPTED {
+			if bitLockerKey == nil {
+				return fmt.Errorf("BitLocker without a clear key detected and no recovery password or key file was provided")
+			}
+
+			key = bitLockerKey
+		}
+
+		err = WindowsOpenBitLockerPartition(mainPartition, key)
 		if err != nil {
 			return err
 		}
@@ -320,8 +374,7 @@ func WindowsInjectDrivers(ctx context.Context, distroVersion string, osArchitect
 
 		defer func() { _ = DoUnmount(windowsMainMountPath) }()
 	default:
-		// TODO -- Handle passing in of a recovery key for mounting BitLocker partition.
-		return fmt.Errorf("BitLocker without a clear key detected; bailing out for now")
+		return fmt.Errorf("Failed to determine BitLocker status of %q", mainPartition)
 	}
 
 	// Mount the Windows recovery partition.
@@ -13,6 +13,7 @@ const (
 	ARTIFACTTYPE_SDK     ArtifactType = "sdk"
 	ARTIFACTTYPE_OSIMAGE ArtifactType = "os-image"
 	ARTIFACTTYPE_DRIVER  ArtifactType = "driver"
+	ARTIFACTTYPE_SCRIPT  ArtifactType = "script"
 )
 
 // Artifact represents external resources uploaded to Migration Manager.
@@ -100,6 +101,10 @@ func (a Artifact) DefaultArtifactFile() (string, error) {
 			return "", fmt.Errorf("Unknown artifact source type %q", a.SourceType)
 		}
 
+	case ARTIFACTTYPE_SCRIPT:
+		// Script artifacts can contain any number of files, which are uploaded with their own names.
+		return "", fmt.Errorf("Artifact type %q has no default file", a.Type)
+
 	default:
 		return "", fmt.Errorf("Unknown artifact type %q", a.Type)
 	}
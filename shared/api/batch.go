@@ -3,6 +3,8 @@ package api
 import (
 	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type BatchStatusType string
@@ -36,6 +38,52 @@ func (b BatchStatusType) Validate() error {
 	return nil
 }
 
+type ValidationCheckType string
+
+const (
+	VALIDATIONCHECK_COMMAND         ValidationCheckType = "command"
+	VALIDATIONCHECK_PING            ValidationCheckType = "ping"
+	VALIDATIONCHECK_SYSTEMD_UNIT    ValidationCheckType = "systemd-unit"
+	VALIDATIONCHECK_HTTP            ValidationCheckType = "http"
+	VALIDATIONCHECK_WINDOWS_SERVICE ValidationCheckType = "windows-service"
+)
+
+// Validate ensures the ValidationCheckType is valid.
+func (v ValidationCheckType) Validate() error {
+	switch v {
+	case VALIDATIONCHECK_COMMAND:
+	case VALIDATIONCHECK_PING:
+	case VALIDATIONCHECK_SYSTEMD_UNIT:
+	case VALIDATIONCHECK_HTTP:
+	case VALIDATIONCHECK_WINDOWS_SERVICE:
+	default:
+		return fmt.Errorf("%q is not a valid validation check type", v)
+	}
+
+	return nil
+}
+
+type DiskVerificationMode string
+
+const (
+	DISKVERIFICATION_NONE   DiskVerificationMode = ""
+	DISKVERIFICATION_SAMPLE DiskVerificationMode = "sample"
+	DISKVERIFICATION_FULL   DiskVerificationMode = "full"
+)
+
+// Validate ensures the DiskVerificationMode is valid.
+func (m DiskVerificationMode) Validate() error {
+	switch m {
+	case DISKVERIFICATION_NONE:
+	case DISKVERIFICATION_SAMPLE:
+	case DISKVERIFICATION_FULL:
+	default:
+		return fmt.Errorf("%q is not a valid disk verification mode", m)
+	}
+
+	return nil
+}
+
 // Batch defines a collection of Instances to be migrated, possibly during a specific window of time.
 //
 // swagger:model
@@ -53,6 +101,52 @@ type Batch struct {
 	// Time in UTC when the batch was started.
 	// Example: 2025-01-01 01:00:00
 	StartDate time.Time `json:"start_date" yaml:"start_date"`
+
+	// Networks that were created on targets for the batch, and will be removed when the batch is reset.
+	CreatedNetworks []BatchCreatedNetwork `json:"created_networks" yaml:"created_networks"`
+}
+
+// BatchCreatedNetwork is a network created on a target for the instances of a batch.
+//
+// swagger:model
+type BatchCreatedNetwork struct {
+	// Name of the network on the target.
+	// Example: vmware
+	Name string `json:"name" yaml:"name"`
+
+	// Type of the network on the target.
+	// Example: bridge
+	Type string `json:"type" yaml:"type"`
+
+	// Target that the network was created on.
+	// Example: mytarget
+	Target string `json:"target" yaml:"target"`
+
+	// Target project that the network was created in.
+	// Example: default
+	TargetProject string `json:"target_project" yaml:"target_project"`
+
+	// Full inventory location path of the source network the network was created from.
+	// Example: /vcenter01/network/net0
+	SourceNetwork string `json:"source_network" yaml:"source_network"`
+}
+
+// BatchInstanceGroup defines a group of instances in a batch that are migrated together.
+// Members of a group are assigned the same migration window once all of them are ready, and are retried together if any of them fails.
+//
+// swagger:model
+type BatchInstanceGroup struct {
+	// Name of the instance group.
+	// Example: webshop
+	Name string `json:"name" yaml:"name"`
+
+	// Expression matching the instances of the batch that belong to the group. Instances can also be assigned to a group with an instance override.
+	// Example: location matches "^/vcenter/webshop/"
+	IncludeExpression string `json:"include_expression" yaml:"include_expression"`
+
+	// Expressions defining the order of the group members. Members matching an earlier expression are started first and powered off last. Members matching none of the expressions come last.
+	// Example: ["location matches 'db'"]
+	Order []string `json:"order" yaml:"order"`
 }
 
 // BatchPut defines the configurable fields of Batch.
@@ -71,9 +165,18 @@ type BatchPut struct {
 	// Set of migration window timings.
 	MigrationWindows []MigrationWindow `json:"migration_windows" yaml:"migration_windows"`
 
+	// Set of recurring migration windows, which are expanded into migration windows on a rolling horizon.
+	MigrationWindowSchedules []MigrationWindowSchedule `json:"migration_window_schedules" yaml:"migration_window_schedules"`
+
+	// Set of periods during which no migrations can take place. Overlapping migration windows, including generated ones, are trimmed or removed.
+	MigrationWindowExclusions []MigrationWindowExclusion `json:"migration_window_exclusions" yaml:"migration_window_exclusions"`
+
 	// Set of constraints to apply to the batch. For each instance, the last constraint in the list that matches will be applied.
 	Constraints []BatchConstraint `json:"constraints" yaml:"constraints"`
 
+	// Groups of instances in the batch that are migrated together.
+	InstanceGroups []BatchInstanceGroup `json:"instance_groups" yaml:"instance_groups"`
+
 	// Default configurations for the batch.
 	Defaults BatchDefaults `json:"defaults" yaml:"defaults"`
 
@@ -90,6 +193,17 @@ type BatchDefaults struct {
 
 	// Whether to forcibly use the configuration that will allow the migration to proceed, when encountering late-migration conflicts. May result in an out-of-date migration.
 	ForceConflictResolution bool `json:"force_conflict_resolution" yaml:"force_conflict_resolution"`
+
+	// Incus profiles applied to each created instance, after the default profile. The profiles must exist on the default target.
+	// Example: ["web"]
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Incus configuration applied to each created instance. Values can reference instance fields as templates, like {{ .name }}.
+	// Example: {"user.location": "{{ .location }}"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Incus devices added to each created instance, or merged into existing devices of the same name. Values can reference instance fields as templates.
+	Devices map[string]map[string]string `json:"devices" yaml:"devices"`
 }
 
 type MigrationNetworkPlacement struct {
@@ -124,6 +238,10 @@ type BatchConfig struct {
 	// Example: starlark scriptlet
 	PlacementScriptlet string `json:"placement_scriptlet" yaml:"placement_scriptlet"`
 
+	// The scriptlet used to modify the Incus configuration of queued instances before they are created on the target.
+	// Example: starlark scriptlet
+	InstanceConfigScriptlet string `json:"instance_config_scriptlet" yaml:"instance_config_scriptlet"`
+
 	// PostMigrationRetries is the maximum number of times post-migration steps will be retried upon errors.
 	// Example: 5
 	PostMigrationRetries int `json:"post_migration_retries" yaml:"post_migration_retries"`
@@ -136,6 +254,85 @@ type BatchConfig struct {
 
 	// The minimum amount of time before the migration window begins that background sync can be re-attempted.
 	FinalBackgroundSyncLimit Duration `json:"final_background_sync_limit" yaml:"final_background_sync_limit"`
+
+	// Whether to translate source firewall rules into network ACLs attached to the migrated instances.
+	// Example: true
+	MigrateNetworkACLs bool `json:"migrate_network_acls" yaml:"migrate_network_acls"`
+
+	// Amount of time after an instance finishes migrating during which the migration can be rolled back. If unset, there is no limit.
+	RollbackGracePeriod Duration `json:"rollback_grace_period" yaml:"rollback_grace_period"`
+
+	// Whether to create target networks that do not exist yet from their source networks, when placing instances.
+	// Example: true
+	CreateTargetNetworks bool `json:"create_target_networks" yaml:"create_target_networks"`
+
+	// Uplink of networks created on targets. OVN networks use it as their uplink network, and Incus picks one if unset.
+	// Bridge and physical networks use it as the host interface connecting them to the external network, and can't be created if unset.
+	// Example: UPLINK
+	CreateTargetNetworksUplink string `json:"create_target_networks_uplink" yaml:"create_target_networks_uplink"`
+
+	// Checks to run inside each migrated instance before its migration is considered finished.
+	ValidationChecks []BatchValidationCheck `json:"validation_checks" yaml:"validation_checks"`
+
+	// Whether to automatically roll back migrations whose validation checks fail.
+	// Example: true
+	RollbackOnValidationFailure bool `json:"rollback_on_validation_failure" yaml:"rollback_on_validation_failure"`
+
+	// Script artifacts to run on each migrated instance, before those set by instance overrides.
+	PostMigrationScripts []PostMigrationScript `json:"post_migration_scripts" yaml:"post_migration_scripts"`
+
+	// Whether to compare the checksums of the source and target disks after the final import, either of a sample of the disk blocks or of all of them.
+	// The migration fails before the target instance is started if the disks differ.
+	// Example: sample
+	DiskVerification DiskVerificationMode `json:"disk_verification" yaml:"disk_verification"`
+
+	// Tiers defining the order in which the source VMs are powered off for final import, and the migrated instances are started.
+	BootOrder []BatchBootTier `json:"boot_order" yaml:"boot_order"`
+}
+
+// BatchBootTier assigns matching instances to a tier of the boot order of a batch.
+// Instances in a lower tier are started before, and powered off after, instances in a higher tier that are migrated in the same migration window.
+type BatchBootTier struct {
+	// Expression used to select the instances of the tier. Instances use the first boot order entry that matches them.
+	// Example: location matches "^/vcenter/db/"
+	IncludeExpression string `json:"include_expression" yaml:"include_expression"`
+
+	// Tier number of the matching instances.
+	// Example: 0
+	Tier int `json:"tier" yaml:"tier"`
+
+	// Time to wait after starting the matching instances before starting the next tier.
+	Delay Duration `json:"delay" yaml:"delay"`
+
+	// Whether to wait until the Incus agent of each started instance responds before starting the next tier.
+	// Example: true
+	WaitForAgent bool `json:"wait_for_agent" yaml:"wait_for_agent"`
+}
+
+// BatchValidationCheck is a check that is run inside a migrated instance once its post-migration configuration is applied.
+type BatchValidationCheck struct {
+	// Name of the check.
+	// Example: webserver
+	Name string `json:"name" yaml:"name"`
+
+	// Type of the check, one of command, ping, systemd-unit, http or windows-service.
+	// Example: http
+	Type ValidationCheckType `json:"type" yaml:"type"`
+
+	// Command to run for command checks. The check passes if the command exits with status 0.
+	// Example: ["test", "-f", "/etc/app/ready"]
+	Command []string `json:"command" yaml:"command"`
+
+	// Host to ping, systemd unit, URL or Windows service to check. Ping checks use the instance's default gateway if empty.
+	// Example: http://localhost:8080/health
+	Target string `json:"target" yaml:"target"`
+
+	// Expression used to select the instances to run the check on. If empty, the check runs on all instances of the batch.
+	// Example: location matches "^/vcenter/web/"
+	IncludeExpression string `json:"include_expression" yaml:"include_expression"`
+
+	// How long to keep retrying the check before it is considered failed. Defaults to 5 minutes.
+	Timeout Duration `json:"timeout" yaml:"timeout"`
 }
 
 // BatchConstraint is a constraint to be applied to a batch to determine which instances can be migrated.
@@ -166,3 +363,37 @@ type InstanceRestrictionOverride struct {
 	// Allow migration of instances with no background import support.
 	AllowNoBackgroundImport bool `json:"allow_no_background_import" yaml:"allow_no_background_import"`
 }
+
+// BatchPlanEntry describes how an instance would be migrated if its batch were started.
+//
+// swagger:model
+type BatchPlanEntry struct {
+	// UUID for the instance; populated from the source and used across all migration manager operations
+	// Example: 26fa4eb7-8d4f-4bf8-9a6a-dd95d166dfad
+	InstanceUUID uuid.UUID `json:"instance_uuid" yaml:"instance_uuid"`
+
+	// The name of the instance
+	// Example: UbuntuServer
+	InstanceName string `json:"instance_name" yaml:"instance_name"`
+
+	// The inventory path of the instance on its source
+	// Example: /SHORT/Ubuntu
+	Location string `json:"location" yaml:"location"`
+
+	// The migration status the instance would start with
+	// Example: Waiting
+	MigrationStatus MigrationStatusType `json:"migration_status" yaml:"migration_status"`
+
+	// Configuration for which target the instance would be placed on.
+	Placement Placement `json:"placement" yaml:"placement"`
+
+	// The window that the instance would perform the final import steps in
+	MigrationWindow MigrationWindow `json:"migration_window" yaml:"migration_window"`
+
+	// Reasons that would keep the instance from being migrated
+	// Example: ["Missing required SDK artifact"]
+	BlockingReasons []string `json:"blocking_reasons" yaml:"blocking_reasons"`
+
+	// Networks that would be created on the target for the instance
+	CreatedNetworks []BatchCreatedNetwork `json:"created_networks" yaml:"created_networks"`
+}
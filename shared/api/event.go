@@ -8,9 +8,18 @@ import (
 )
 
 // Event represents a Migration Manager event.
+//
+// swagger:model
 type Event struct {
-	Time     time.Time       `json:"time"`
-	Type     LogScope        `json:"type"`
+	// Time at which the event occurred.
+	// Example: 2025-12-09T23:17:28.00Z
+	Time time.Time `json:"time"`
+
+	// Type of the event.
+	// Example: lifecycle
+	Type LogScope `json:"type"`
+
+	// Type-specific event data.
 	Metadata json.RawMessage `json:"metadata"`
 }
 
@@ -21,6 +21,9 @@ const (
 
 	// migration-final-completed (final migration has completed).
 	MigrationFinalCompleted api.LifecycleAction = "migration-final-completed"
+
+	// migration-validation-failed (migrated instance failed its post-migration validation checks).
+	MigrationValidationFailed api.LifecycleAction = "migration-validation-failed"
 )
 
 type MigrationDetails struct {
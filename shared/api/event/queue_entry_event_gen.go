@@ -14,10 +14,11 @@ import (
 )
 
 const (
-	QueueEntryCanceled api.LifecycleAction = "queue-entry-canceled"
-	QueueEntryRetried  api.LifecycleAction = "queue-entry-retried"
-	QueueEntryRemoved  api.LifecycleAction = "queue-entry-removed"
-	QueueEntryResolved api.LifecycleAction = "queue-entry-resolved"
+	QueueEntryCanceled   api.LifecycleAction = "queue-entry-canceled"
+	QueueEntryRetried    api.LifecycleAction = "queue-entry-retried"
+	QueueEntryRemoved    api.LifecycleAction = "queue-entry-removed"
+	QueueEntryResolved   api.LifecycleAction = "queue-entry-resolved"
+	QueueEntryRolledBack api.LifecycleAction = "queue-entry-rolled-back"
 )
 
 func QueueEntryURI(id uuid.UUID) string {
@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// RedactedSecret replaces secret values when they are returned by the API.
+const RedactedSecret = "<redacted>"
+
 // InstanceOverride defines a limited set of instance values that can be overridden as part of the migration process.
 //
 // swagger:model
@@ -46,4 +49,41 @@ type InstanceOverride struct {
 	// If true, after migration the associated target VM will be left stopped.
 	// Example: true
 	StoppedAfterMigration bool `json:"stopped_after_migration" yaml:"stopped_after_migration"`
+
+	// BitLocker key material used to unlock the encrypted Windows partition during post-migration.
+	// Key values are write-only and are always returned as "<redacted>". Sending back a redacted value keeps the stored key.
+	BitLocker *BitLockerKey `json:"bitlocker,omitempty" yaml:"bitlocker,omitempty"`
+
+	// Script artifacts to run on the migrated instance, after those set by its batch.
+	PostMigrationScripts []PostMigrationScript `json:"post_migration_scripts,omitempty" yaml:"post_migration_scripts,omitempty"`
+
+	// Name of the instance group that the instance belongs to in any batch, taking precedence over the include expressions of the batch instance groups.
+	// Example: webshop
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+}
+
+// BitLockerKey holds the key material used to unlock a BitLocker encrypted partition.
+// Only one of the recovery password or the key file should be set.
+//
+// swagger:model
+type BitLockerKey struct {
+	// BitLocker recovery password.
+	// Example: 123456-123456-123456-123456-123456-123456-123456-123456
+	RecoveryPassword string `json:"recovery_password,omitempty" yaml:"recovery_password,omitempty"`
+
+	// Base64 encoded BitLocker startup key (.BEK) file.
+	KeyFile string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+}
+
+// Redacted returns a copy of the key with all set values replaced by the redacted placeholder.
+func (k BitLockerKey) Redacted() BitLockerKey {
+	if k.RecoveryPassword != "" {
+		k.RecoveryPassword = RedactedSecret
+	}
+
+	if k.KeyFile != "" {
+		k.KeyFile = RedactedSecret
+	}
+
+	return k
 }
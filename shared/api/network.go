@@ -21,6 +21,9 @@ const (
 
 	// NETWORKTYPE_VMWARE_NSX is an opaque network managed by NSX.
 	NETWORKTYPE_VMWARE_NSX NetworkType = "nsx"
+
+	// NETWORKTYPE_OVF is a logical network declared in the network section of an OVF descriptor.
+	NETWORKTYPE_OVF NetworkType = "ovf"
 )
 
 type IncusNICType string
@@ -125,3 +128,87 @@ func (n *NetworkPlacement) Apply(overrides NetworkPlacement) {
 		n.VlanID = overrides.VlanID
 	}
 }
+
+// InstanceNetworkACLs defines the network ACLs generated for an instance from the firewall rules on its source.
+//
+// swagger:model
+type InstanceNetworkACLs struct {
+	// Network ACLs to create on the target.
+	ACLs []NetworkACL `json:"acls" yaml:"acls"`
+
+	// Names of the network ACLs to attach, keyed by NIC hardware address.
+	// Example: {"00:00:00:00:00:01": ["nsx-vm01"]}
+	NICs map[string][]string `json:"nics" yaml:"nics"`
+
+	// Action applied to ingress traffic not matching any ACL rule. Empty if the target default should be used.
+	// Example: drop
+	DefaultIngressAction string `json:"default_ingress_action,omitempty" yaml:"default_ingress_action,omitempty"`
+
+	// Action applied to egress traffic not matching any ACL rule. Empty if the target default should be used.
+	// Example: allow
+	DefaultEgressAction string `json:"default_egress_action,omitempty" yaml:"default_egress_action,omitempty"`
+
+	// Firewall rules that could not be represented as network ACL rules.
+	// Example: ["Skipping NSX rule \"web\": Unsupported action \"JUMP_TO_APPLICATION\""]
+	Warnings []string `json:"warnings" yaml:"warnings"`
+}
+
+// NetworkACL defines a network ACL to be created on the target.
+//
+// swagger:model
+type NetworkACL struct {
+	// Name of the network ACL.
+	// Example: nsx-vm01
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the network ACL.
+	// Example: Generated from NSX security policies
+	Description string `json:"description" yaml:"description"`
+
+	// Rules applied to ingress traffic.
+	Ingress []NetworkACLRule `json:"ingress" yaml:"ingress"`
+
+	// Rules applied to egress traffic.
+	Egress []NetworkACLRule `json:"egress" yaml:"egress"`
+}
+
+// NetworkACLRule defines a single network ACL rule, following the Incus network ACL rule format.
+//
+// swagger:model
+type NetworkACLRule struct {
+	// Action to take for matching traffic (allow, drop or reject).
+	// Example: allow
+	Action string `json:"action" yaml:"action"`
+
+	// Comma separated list of source addresses, empty for any.
+	// Example: 10.0.0.0/24
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Comma separated list of destination addresses, empty for any.
+	// Example: 10.0.1.10
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
+
+	// Protocol to match (tcp, udp, icmp4 or icmp6), empty for any.
+	// Example: tcp
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// Comma separated list of source ports or port ranges.
+	// Example: 1024-65535
+	SourcePort string `json:"source_port,omitempty" yaml:"source_port,omitempty"`
+
+	// Comma separated list of destination ports or port ranges.
+	// Example: 80,443
+	DestinationPort string `json:"destination_port,omitempty" yaml:"destination_port,omitempty"`
+
+	// ICMP message type.
+	// Example: 8
+	ICMPType string `json:"icmp_type,omitempty" yaml:"icmp_type,omitempty"`
+
+	// ICMP message code.
+	// Example: 0
+	ICMPCode string `json:"icmp_code,omitempty" yaml:"icmp_code,omitempty"`
+
+	// Description of the rule.
+	// Example: NSX rule "web" from policy "app"
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
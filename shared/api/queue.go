@@ -18,10 +18,13 @@ const (
 	MIGRATIONSTATUS_FINAL_IMPORT      MigrationStatusType = "Performing final import tasks"
 	MIGRATIONSTATUS_POST_IMPORT       MigrationStatusType = "Performing post-import tasks"
 	MIGRATIONSTATUS_WORKER_DONE       MigrationStatusType = "Worker tasks complete"
+	MIGRATIONSTATUS_VALIDATING        MigrationStatusType = "Running validation checks"
 	MIGRATIONSTATUS_FINISHED          MigrationStatusType = "Finished"
 	MIGRATIONSTATUS_ERROR             MigrationStatusType = "Error"
 	MIGRATIONSTATUS_CANCELED          MigrationStatusType = "Canceled"
 	MIGRATIONSTATUS_CONFLICT          MigrationStatusType = "Conflict"
+	MIGRATIONSTATUS_ROLLED_BACK       MigrationStatusType = "Rolled back"
+	MIGRATIONSTATUS_VALIDATION_FAILED MigrationStatusType = "Validation failed"
 )
 
 const ConflictResolvedMessage = "Conflict resolved"
@@ -40,7 +43,10 @@ func (m MigrationStatusType) Validate() error {
 	case MIGRATIONSTATUS_FINISHED:
 	case MIGRATIONSTATUS_IDLE:
 	case MIGRATIONSTATUS_WORKER_DONE:
+	case MIGRATIONSTATUS_VALIDATING:
 	case MIGRATIONSTATUS_CONFLICT:
+	case MIGRATIONSTATUS_ROLLED_BACK:
+	case MIGRATIONSTATUS_VALIDATION_FAILED:
 	default:
 		return fmt.Errorf("%s is not a valid migration status", m)
 	}
@@ -80,6 +86,72 @@ type QueueEntry struct {
 
 	// Configuration for which target the instance will be placed on.
 	Placement Placement `json:"placement" yaml:"placement"`
+
+	// The most recent disk transfer progress reported by the migration worker
+	TransferProgress *DiskTransferProgress `json:"transfer_progress,omitempty" yaml:"transfer_progress,omitempty"`
+
+	// Results of the post-migration scripts run for the instance
+	ScriptResults []PostMigrationScriptResult `json:"script_results,omitempty" yaml:"script_results,omitempty"`
+
+	// Change IDs of the data copied from the source disks, used to resume incremental disk imports
+	ChangeIDs []DiskChangeID `json:"change_ids,omitempty" yaml:"change_ids,omitempty"`
+
+	// Results of the verification of the imported disks against the source
+	VerificationResults []DiskVerificationResult `json:"verification_results,omitempty" yaml:"verification_results,omitempty"`
+
+	// Start of the migrated instance on the target, which holds back later boot order tiers of the batch
+	BootStatus *BootStatus `json:"boot_status,omitempty" yaml:"boot_status,omitempty"`
+}
+
+// BootStatus records the start of a migrated instance on the target.
+type BootStatus struct {
+	// Time in UTC at which the instance was started
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// Name of the migration window in which the instance was started
+	// Example: window1
+	MigrationWindow string `json:"migration_window" yaml:"migration_window"`
+
+	// Whether the Incus agent of the instance has responded since it was started
+	// Example: true
+	AgentReady bool `json:"agent_ready" yaml:"agent_ready"`
+}
+
+// QueueHistoryEntry records a single migration state transition of an instance in the migration queue.
+//
+// swagger:model
+type QueueHistoryEntry struct {
+	// UUID for the instance; populated from the source and used across all migration manager operations
+	// Example: 26fa4eb7-8d4f-4bf8-9a6a-dd95d166dfad
+	InstanceUUID uuid.UUID `json:"instance_uuid" yaml:"instance_uuid"`
+
+	// A human-friendly name for the batch
+	// Example: MyBatch
+	BatchName string `json:"batch_name" yaml:"batch_name"`
+
+	// The import stage of the instance
+	// Example: background
+	ImportStage string `json:"import_stage" yaml:"import_stage"`
+
+	// The migration status of the instance
+	// Example: Idle
+	MigrationStatus MigrationStatusType `json:"migration_status" yaml:"migration_status"`
+
+	// A free-form string to provide additional information about the migration status
+	// Example: "Waiting for migration window"
+	MigrationStatusMessage string `json:"migration_status_message" yaml:"migration_status_message"`
+
+	// The last response type received from the migration worker
+	// Example: 2
+	LastWorkerStatus WorkerResponseType `json:"last_worker_status" yaml:"last_worker_status"`
+
+	// Name of the migration window assigned to the instance
+	// Example: window1
+	MigrationWindowName string `json:"migration_window_name" yaml:"migration_window_name"`
+
+	// Time in UTC when the transition happened
+	// Example: 2025-01-01 01:00:00
+	Date time.Time `json:"date" yaml:"date"`
 }
 
 // Placement indicates the destination for a queue entry's instance.
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PostMigrationScriptStage string
+
+const (
+	// POSTMIGRATIONSCRIPTSTAGE_WORKER scripts are run by the migration worker, within a chroot of the migrated root partition.
+	POSTMIGRATIONSCRIPTSTAGE_WORKER PostMigrationScriptStage = "worker"
+
+	// POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT scripts are run inside the migrated instance once it has booted on the target.
+	POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT PostMigrationScriptStage = "first-boot"
+)
+
+// Validate ensures the PostMigrationScriptStage is valid.
+func (s PostMigrationScriptStage) Validate() error {
+	switch s {
+	case POSTMIGRATIONSCRIPTSTAGE_WORKER:
+	case POSTMIGRATIONSCRIPTSTAGE_FIRST_BOOT:
+	default:
+		return fmt.Errorf("%q is not a valid post-migration script stage", s)
+	}
+
+	return nil
+}
+
+// PostMigrationScript references a script artifact to run on migrated instances.
+// All files of the artifact are run in order of their names, on instances matching the OS of the artifact.
+//
+// swagger:model
+type PostMigrationScript struct {
+	// UUID of the script artifact.
+	// Example: 400f6ceb-659a-4b3c-8598-0bc9d20eafe3
+	Artifact uuid.UUID `json:"artifact" yaml:"artifact"`
+
+	// When to run the scripts, either by the worker within the migrated root partition, or on first boot of the migrated instance.
+	// Example: first-boot
+	Stage PostMigrationScriptStage `json:"stage" yaml:"stage"`
+
+	// How long each file of the script can run before it is stopped, in the worker or in the instance. Defaults to 10 minutes.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// PostMigrationScriptResult records the outcome of running a post-migration script.
+//
+// swagger:model
+type PostMigrationScriptResult struct {
+	// UUID of the script artifact.
+	// Example: 400f6ceb-659a-4b3c-8598-0bc9d20eafe3
+	Artifact uuid.UUID `json:"artifact" yaml:"artifact"`
+
+	// Name of the script file.
+	// Example: register.sh
+	File string `json:"file" yaml:"file"`
+
+	// Stage that the script was run in.
+	// Example: worker
+	Stage PostMigrationScriptStage `json:"stage" yaml:"stage"`
+
+	// Exit code of the script.
+	// Example: 0
+	ExitCode int `json:"exit_code" yaml:"exit_code"`
+
+	// Combined standard output and standard error of the script.
+	// Example: Registered with config management
+	Output string `json:"output" yaml:"output"`
+
+	// Time in UTC when the script finished.
+	// Example: 2025-01-01 01:00:00
+	Date time.Time `json:"date" yaml:"date"`
+}
@@ -5,18 +5,22 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type SourceType string
 
 const (
-	SOURCETYPE_VMWARE SourceType = "vmware"
-	SOURCETYPE_NSX    SourceType = "nsx"
+	SOURCETYPE_VMWARE      SourceType = "vmware"
+	SOURCETYPE_NSX         SourceType = "nsx"
+	SOURCETYPE_OVF         SourceType = "ovf"
+	SOURCETYPE_VMWARE_DUMP SourceType = "vmware-dump"
 )
 
 // VMSourceTypes are the list of source types that manage VMs.
 func VMSourceTypes() []SourceType {
-	return []SourceType{SOURCETYPE_VMWARE}
+	return []SourceType{SOURCETYPE_VMWARE, SOURCETYPE_OVF, SOURCETYPE_VMWARE_DUMP}
 }
 
 // NetworkSourceTypes are the list of source types that manage networks.
@@ -34,6 +38,9 @@ type Source struct {
 	// Example: true
 	Syncing bool `json:"syncing" yaml:"syncing"`
 
+	// Result of the most recent data sync of the source.
+	SyncStatus SourceSyncStatus `json:"sync_status" yaml:"sync_status"`
+
 	// SourceType defines the type of the source
 	// Example: vmware
 	SourceType SourceType `json:"source_type" yaml:"source_type"`
@@ -51,6 +58,88 @@ type SourcePut struct {
 	Properties json.RawMessage `json:"properties" yaml:"properties"`
 }
 
+// SourceSyncPost defines the instances to refresh when syncing a source.
+// If no instances are selected, the whole source is synced.
+//
+// swagger:model
+type SourceSyncPost struct {
+	// UUIDs of instances to sync
+	// Example: ["a2095069-a527-4b2a-ab23-1739325dcac7"]
+	Instances []uuid.UUID `json:"instances,omitempty" yaml:"instances,omitempty"`
+
+	// Source-specific IDs or inventory paths of instances to sync
+	// Example: ["vm-123", "/SHF/vm/Migration Tests/DebianTest"]
+	SourceSpecificIDs []string `json:"source_specific_ids,omitempty" yaml:"source_specific_ids,omitempty"`
+
+	// Include expression selecting recorded instances to sync
+	// Example: location matches "^/SHF/vm/Migration Tests/.*"
+	IncludeExpression string `json:"include_expression,omitempty" yaml:"include_expression,omitempty"`
+}
+
+// IsEmpty returns whether no instances are selected.
+func (s SourceSyncPost) IsEmpty() bool {
+	return len(s.Instances) == 0 && len(s.SourceSpecificIDs) == 0 && s.IncludeExpression == ""
+}
+
+// SourceSyncSchedule defines when a source is synced in the background.
+//
+// swagger:model
+type SourceSyncSchedule struct {
+	// Interval between background syncs of the source. Mutually exclusive with Cron.
+	// Example: 1h
+	Interval Duration `json:"interval,omitzero" yaml:"interval,omitempty"`
+
+	// Cron expression determining when the source is synced. Mutually exclusive with Interval.
+	// Example: 0 */4 * * *
+	Cron string `json:"cron,omitempty" yaml:"cron,omitempty"`
+
+	// Maintenance periods during which the source will not be synced in the background.
+	Blackouts []SourceSyncBlackout `json:"blackouts,omitempty" yaml:"blackouts,omitempty"`
+}
+
+// SourceSyncBlackout defines a period during which background syncs of a source are suppressed.
+// Either a recurring period (Cron and Duration), or a fixed period (Start and End) can be set.
+//
+// swagger:model
+type SourceSyncBlackout struct {
+	// Cron expression for the start of a recurring blackout period.
+	// Example: 0 22 * * 5
+	Cron string `json:"cron,omitempty" yaml:"cron,omitempty"`
+
+	// Length of a recurring blackout period.
+	// Example: 8h
+	Duration Duration `json:"duration,omitzero" yaml:"duration,omitempty"`
+
+	// Start of a fixed blackout period.
+	// Example: 2025-01-01T00:00:00Z
+	Start time.Time `json:"start,omitzero" yaml:"start,omitempty"`
+
+	// End of a fixed blackout period.
+	// Example: 2025-01-02T00:00:00Z
+	End time.Time `json:"end,omitzero" yaml:"end,omitempty"`
+}
+
+// SourceSyncStatus reports the result of the most recent background or manual sync of a source.
+//
+// swagger:model
+type SourceSyncStatus struct {
+	// Time the most recent sync started.
+	// Example: 2025-01-01T00:00:00Z
+	LastSync time.Time `json:"last_sync" yaml:"last_sync"`
+
+	// Duration of the most recent sync.
+	// Example: 5m
+	LastSyncDuration Duration `json:"last_sync_duration" yaml:"last_sync_duration"`
+
+	// Error message of the most recent sync, if it failed.
+	// Example: Failed to connect to source
+	LastSyncError string `json:"last_sync_error" yaml:"last_sync_error"`
+
+	// Time the next background sync is expected to start.
+	// Example: 2025-01-01T01:00:00Z
+	NextSync time.Time `json:"next_sync" yaml:"next_sync"`
+}
+
 // VMwareProperties defines the set of VMware specific properties of an endpoint that the migration manager can connect to.
 type VMwareProperties struct {
 	// Hostname or IP address of the source endpoint
@@ -93,6 +182,13 @@ type VMwareProperties struct {
 
 	// Datacenters to search for VMs, networks, and datastores. Defaults to all datacenters.
 	Datacenters []string `json:"datacenters" yaml:"datacenters"`
+
+	// Whether to watch the source for VM changes and sync changed VMs as they occur. Periodic syncs still run as a consistency check.
+	// Example: true
+	WatchChanges bool `json:"watch_changes,omitempty" yaml:"watch_changes,omitempty"`
+
+	// Per-source schedule for background syncs. Defaults to the global sync interval.
+	SyncSchedule *SourceSyncSchedule `json:"sync_schedule,omitempty" yaml:"sync_schedule,omitempty"`
 }
 
 // SetDefaults sets default values for source properties.
@@ -132,3 +228,63 @@ func (s *VMwareProperties) SetDefaults() {
 		s.Datacenters = []string{"/..."}
 	}
 }
+
+// OVFProperties defines the set of properties of a directory of OVF descriptors and OVA archives that the migration manager can import from.
+type OVFProperties struct {
+	// Directory on the migration manager host that is scanned for OVF descriptors and OVA archives
+	// Example: /srv/appliances
+	Path string `json:"path" yaml:"path"`
+
+	// Connectivity status of this source
+	ConnectivityStatus ExternalConnectivityStatus `json:"connectivity_status" yaml:"connectivity_status"`
+
+	// Maximum number of concurrent imports that can occur
+	// Example: 10
+	ImportLimit int `json:"import_limit,omitempty" yaml:"import_limit,omitempty"`
+
+	// Timeout for scanning the directory.
+	// Example: 10m
+	ConnectionTimeout Duration `json:"connection_timeout" yaml:"connection_timeout"`
+
+	// Per-source schedule for background syncs. Defaults to the global sync interval.
+	SyncSchedule *SourceSyncSchedule `json:"sync_schedule,omitempty" yaml:"sync_schedule,omitempty"`
+}
+
+// SetDefaults sets default values for source properties.
+func (s *OVFProperties) SetDefaults() {
+	if s.ConnectionTimeout == (Duration{}) {
+		s.ConnectionTimeout = AsDuration(10 * time.Minute)
+	}
+
+	if s.Path != "" {
+		s.Path = filepath.Clean(s.Path)
+	}
+}
+
+// VMwareDumpProperties defines the set of properties of a VMware source dump that the migration manager can replay instances from.
+type VMwareDumpProperties struct {
+	// Dump directory, or tar archive of a dump directory, on the migration manager host
+	// Example: /var/cache/migration-manager/vcenter01_dump
+	Path string `json:"path" yaml:"path"`
+
+	// Connectivity status of this source
+	ConnectivityStatus ExternalConnectivityStatus `json:"connectivity_status" yaml:"connectivity_status"`
+
+	// Timeout for reading the dump.
+	// Example: 10m
+	ConnectionTimeout Duration `json:"connection_timeout" yaml:"connection_timeout"`
+
+	// Per-source schedule for background syncs. Defaults to the global sync interval.
+	SyncSchedule *SourceSyncSchedule `json:"sync_schedule,omitempty" yaml:"sync_schedule,omitempty"`
+}
+
+// SetDefaults sets default values for source properties.
+func (s *VMwareDumpProperties) SetDefaults() {
+	if s.ConnectionTimeout == (Duration{}) {
+		s.ConnectionTimeout = AsDuration(10 * time.Minute)
+	}
+
+	if s.Path != "" {
+		s.Path = filepath.Clean(s.Path)
+	}
+}
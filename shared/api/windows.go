@@ -22,6 +22,57 @@ type MigrationWindow struct {
 
 	// Configuration for the window.
 	Config MigrationWindowConfig `json:"config" yaml:"config"`
+
+	// Name of the schedule that generated the window. Generated windows are managed by their schedule, and can't be modified directly.
+	// Example: weeknights
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+// MigrationWindowSchedule defines a recurring migration window, which is expanded into concrete migration windows ahead of time.
+//
+// swagger:model
+type MigrationWindowSchedule struct {
+	// Name of the schedule, used as the prefix for the names of the generated migration windows.
+	// Example: weeknights
+	Name string `json:"name" yaml:"name"`
+
+	// Cron expression for the start times of the migration windows, evaluated in the schedule's timezone.
+	// Example: 0 22 * * 1-5
+	Cron string `json:"cron" yaml:"cron"`
+
+	// Length of each migration window.
+	// Example: 6h
+	Duration Duration `json:"duration" yaml:"duration"`
+
+	// Amount of time before the end of each migration window at which the window locks out. If unset, the windows have no lockout time.
+	// Example: 30m
+	LockoutOffset Duration `json:"lockout_offset" yaml:"lockout_offset"`
+
+	// IANA timezone in which the cron expression is evaluated. Defaults to UTC.
+	// Example: Europe/Zurich
+	Timezone string `json:"timezone" yaml:"timezone"`
+
+	// Time after which no more migration windows are generated. If unset, windows are generated indefinitely.
+	Until time.Time `json:"until" yaml:"until"`
+
+	// Configuration for the generated windows.
+	Config MigrationWindowConfig `json:"config" yaml:"config"`
+}
+
+// MigrationWindowExclusion defines a period, such as a change freeze, during which no migrations can take place.
+// Migration windows of the batch that overlap it are trimmed, or removed if nothing of them remains.
+//
+// swagger:model
+type MigrationWindowExclusion struct {
+	// Name of the exclusion.
+	// Example: year-end-freeze-20261215-0000
+	Name string `json:"name" yaml:"name"`
+
+	// Start time of the exclusion.
+	Start time.Time `json:"start" yaml:"start"`
+
+	// End time of the exclusion.
+	End time.Time `json:"end" yaml:"end"`
 }
 
 type MigrationWindowConfig struct {
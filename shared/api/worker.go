@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type WorkerCommandType int
@@ -55,6 +56,19 @@ type WorkerCommand struct {
 	// Architecture of the instance
 	// Example: x86_64
 	Architecture string `json:"architecture" yaml:"architecture"`
+
+	// BitLocker key material used to unlock an encrypted Windows partition.
+	BitLocker *BitLockerKey `json:"bitlocker,omitempty" yaml:"bitlocker,omitempty"`
+
+	// Scripts to run within the migrated root partition during post-import tasks.
+	PostMigrationScripts []PostMigrationScript `json:"post_migration_scripts,omitempty" yaml:"post_migration_scripts,omitempty"`
+
+	// Change IDs of the data previously copied from the source disks, used to resume incremental disk imports.
+	ChangeIDs []DiskChangeID `json:"change_ids,omitempty" yaml:"change_ids,omitempty"`
+
+	// How to verify the imported disks against the source after the final import.
+	// Example: sample
+	DiskVerification DiskVerificationMode `json:"disk_verification,omitempty" yaml:"disk_verification,omitempty"`
 }
 
 // WorkerResponse defines a response received from a worker.
@@ -69,4 +83,127 @@ type WorkerResponse struct {
 
 	// Additional data included with the response.
 	Metadata []byte `json:"metadata" yaml:"metadata"`
+
+	// Structured progress of the disk transfer, if one is underway.
+	Progress *DiskTransferProgress `json:"progress,omitempty" yaml:"progress,omitempty"`
+
+	// Results of the post-migration scripts run by the worker.
+	ScriptResults []PostMigrationScriptResult `json:"script_results,omitempty" yaml:"script_results,omitempty"`
+
+	// Change IDs of the data copied from the source disks by the worker.
+	ChangeIDs []DiskChangeID `json:"change_ids,omitempty" yaml:"change_ids,omitempty"`
+
+	// Results of the verification of the imported disks against the source.
+	VerificationResults []DiskVerificationResult `json:"verification_results,omitempty" yaml:"verification_results,omitempty"`
+}
+
+// DiskTransferProgress reports the progress of the disks being transferred by a migration worker.
+//
+// swagger:model
+type DiskTransferProgress struct {
+	// Number of bytes transferred across all disks
+	// Example: 1073741824
+	BytesCopied int64 `json:"bytes_copied" yaml:"bytes_copied"`
+
+	// Total number of bytes to transfer across all disks
+	// Example: 4294967296
+	BytesTotal int64 `json:"bytes_total" yaml:"bytes_total"`
+
+	// Average transfer rate in bytes per second
+	// Example: 104857600
+	BytesPerSecond int64 `json:"bytes_per_second" yaml:"bytes_per_second"`
+
+	// Estimated time remaining until all disks have been transferred
+	// Example: 30s
+	ETA Duration `json:"eta" yaml:"eta"`
+
+	// Progress of the individual disks
+	Disks []DiskProgress `json:"disks" yaml:"disks"`
+}
+
+// DiskProgress reports the progress of a single disk being transferred by a migration worker.
+//
+// swagger:model
+type DiskProgress struct {
+	// Name of the disk
+	// Example: [datastore] vm/vm.vmdk
+	Name string `json:"name" yaml:"name"`
+
+	// Number of bytes of the disk transferred
+	// Example: 1073741824
+	BytesCopied int64 `json:"bytes_copied" yaml:"bytes_copied"`
+
+	// Size of the disk in bytes
+	// Example: 4294967296
+	BytesTotal int64 `json:"bytes_total" yaml:"bytes_total"`
+}
+
+// DiskChangeID records the changed block tracking ID of the data last copied from a source disk to the target instance.
+//
+// swagger:model
+type DiskChangeID struct {
+	// Name of the source disk
+	// Example: [datastore] vm/vm.vmdk
+	Disk string `json:"disk" yaml:"disk"`
+
+	// Change ID of the source disk at the time of the copy, empty if the copied data has since been modified on the target
+	// Example: 52 d2 3a 5e 6e 3f 5c 1b-2e 8c 4a 5f 4d 7e 0c 9a/12
+	ChangeID string `json:"change_id" yaml:"change_id"`
+
+	// Source snapshot the data was copied from
+	// Example: snapshot-1234
+	Snapshot string `json:"snapshot" yaml:"snapshot"`
+
+	// Name of the target instance device the data was copied to
+	// Example: root
+	TargetDevice string `json:"target_device" yaml:"target_device"`
+
+	// Size in bytes of the target device the data was copied to
+	// Example: 10737418240
+	TargetSize int64 `json:"target_size" yaml:"target_size"`
+
+	// UUID of the target storage volume the data was copied to, empty if unknown
+	// Example: 2b1f0a49-63de-4b5c-9a3b-2b84e5e8a9a4
+	TargetVolume string `json:"target_volume" yaml:"target_volume"`
+
+	// Time in UTC when the copy completed
+	// Example: 2025-01-01 01:00:00
+	LastUpdated time.Time `json:"last_updated" yaml:"last_updated"`
+}
+
+// DiskVerificationResult reports the outcome of comparing the checksums of a source disk against its copy on the target.
+//
+// swagger:model
+type DiskVerificationResult struct {
+	// Name of the source disk
+	// Example: [datastore] vm/vm.vmdk
+	Disk string `json:"disk" yaml:"disk"`
+
+	// Whether a sample of the disk blocks or all of them were compared
+	// Example: sample
+	Mode DiskVerificationMode `json:"mode" yaml:"mode"`
+
+	// Number of bytes that were compared
+	// Example: 268435456
+	BytesVerified int64 `json:"bytes_verified" yaml:"bytes_verified"`
+
+	// Size of the disk in bytes
+	// Example: 4294967296
+	BytesTotal int64 `json:"bytes_total" yaml:"bytes_total"`
+
+	// Ranges of the disk whose checksums differ between the source and the target
+	MismatchedRanges []DiskRange `json:"mismatched_ranges" yaml:"mismatched_ranges"`
+}
+
+// DiskRange is a range of bytes on a disk.
+//
+// swagger:model
+type DiskRange struct {
+	// Offset of the range in bytes
+	// Example: 4194304
+	Offset int64 `json:"offset" yaml:"offset"`
+
+	// Length of the range in bytes
+	// Example: 4194304
+	Length int64 `json:"length" yaml:"length"`
 }
@@ -85,7 +85,8 @@ export const uploadArtifactFile = (
   body: File | null,
 ): Promise<APIResponse<null>> => {
   return new Promise((resolve, reject) => {
-    fetch(`/1.0/artifacts/${uuid}/files`, {
+    const params = body ? `?name=${encodeURIComponent(body.name)}` : "";
+    fetch(`/1.0/artifacts/${uuid}/files${params}`, {
       method: "POST",
       headers: {
         "Content-Type": "application/octet-stream",
@@ -64,3 +64,22 @@ export const retryQueue = (uuid: string): Promise<APIResponse<null>> => {
       .catch(reject);
   });
 };
+
+export const rollbackQueue = (
+  uuid: string,
+  deleteTarget: boolean,
+): Promise<APIResponse<null>> => {
+  const params = new URLSearchParams();
+  if (deleteTarget) {
+    params.set("delete", "1");
+  }
+
+  return new Promise((resolve, reject) => {
+    fetch(`/1.0/queue/${uuid}/:rollback?${params.toString()}`, {
+      method: "POST",
+    })
+      .then((response) => response.json())
+      .then(resolve)
+      .catch(reject);
+  });
+};
@@ -56,6 +56,7 @@ test("renders and submit BatchForm", async () => {
     config: {
       background_sync_interval: "10m",
       final_background_sync_limit: "10m",
+      rollback_grace_period: "",
       post_migration_retries: 5,
       rerun_scriptlets: false,
       placement_scriptlet: "",
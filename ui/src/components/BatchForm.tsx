@@ -129,6 +129,7 @@ const BatchForm: FC<Props> = ({ batch, onSubmit }) => {
       rerun_scriptlets: false,
       background_sync_interval: "10m",
       final_background_sync_limit: "10m",
+      rollback_grace_period: "",
     },
     defaults: {
       placement: {
@@ -174,6 +175,7 @@ const BatchForm: FC<Props> = ({ batch, onSubmit }) => {
         rerun_scriptlets: batch.config.rerun_scriptlets,
         background_sync_interval: batch.config.background_sync_interval,
         final_background_sync_limit: batch.config.final_background_sync_limit,
+        rollback_grace_period: batch.config.rollback_grace_period,
       },
       defaults: {
         placement: {
@@ -588,6 +590,16 @@ const BatchForm: FC<Props> = ({ batch, onSubmit }) => {
                     onBlur={formik.handleBlur}
                   />
                 </Form.Group>
+                <Form.Group className="mb-3" controlId="rollbackGracePeriod">
+                  <Form.Label>Rollback grace period</Form.Label>
+                  <Form.Control
+                    type="text"
+                    name="config.rollback_grace_period"
+                    value={formik.values.config.rollback_grace_period}
+                    onChange={formik.handleChange}
+                    onBlur={formik.handleBlur}
+                  />
+                </Form.Group>
                 <Form.Group
                   className="mb-3"
                   controlId="force_conflict_resolution"
@@ -103,6 +103,12 @@ const BatchOverview = () => {
           {batch?.config.final_background_sync_limit}
         </div>
       </div>
+      <div className="row">
+        <div className="col-2 detail-table-header">Rollback grace period</div>
+        <div className="col-10 detail-table-cell">
+          {batch?.config.rollback_grace_period}
+        </div>
+      </div>
       <div className="row">
         <div className="col-2 detail-table-header">Allow unknown os</div>
         <div className="col-10 detail-table-cell">
@@ -6,6 +6,7 @@ import { Button } from "react-bootstrap";
 import { deleteQueue, resolveQueue, retryQueue } from "api/queue";
 import ModalWindow from "components/ModalWindow";
 import QueueCancelBtn from "components/QueueCancelBtn";
+import QueueRollbackBtn from "components/QueueRollbackBtn";
 import { useNotification } from "context/notificationContext";
 import { QueueEntry } from "types/queue";
 import {
@@ -111,6 +112,7 @@ const QueueActions: FC<Props> = ({ queueEntry }) => {
   return (
     <div>
       <QueueCancelBtn queueEntry={queueEntry} />
+      <QueueRollbackBtn queueEntry={queueEntry} />
       <RiResetLeftLine
         title="Retry"
         size={22}
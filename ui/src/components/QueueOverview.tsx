@@ -2,6 +2,8 @@ import { useQuery } from "@tanstack/react-query";
 import { useParams } from "react-router";
 import { fetchQueueItem } from "api/queue";
 import { formatDate } from "util/date";
+import { bytesToHumanReadable } from "util/instance";
+import { formatTransferProgress } from "util/queue";
 
 const QueueOverview = () => {
   const { uuid } = useParams();
@@ -52,6 +54,29 @@ const QueueOverview = () => {
           <div className="col-10 detail-table-cell">{queue?.batch_name}</div>
         </div>
       </div>
+      {queue?.transfer_progress && (
+        <>
+          <hr className="my-4" />
+          <h6 className="mb-3">Transfer progress</h6>
+          <div className="container">
+            <div className="row">
+              <div className="col-2 detail-table-header">Total</div>
+              <div className="col-10 detail-table-cell">
+                {formatTransferProgress(queue.transfer_progress)}
+              </div>
+            </div>
+            {queue.transfer_progress.disks.map((disk) => (
+              <div className="row" key={disk.name}>
+                <div className="col-2 detail-table-header">{disk.name}</div>
+                <div className="col-10 detail-table-cell">
+                  {bytesToHumanReadable(disk.bytes_copied)} /{" "}
+                  {bytesToHumanReadable(disk.bytes_total)}
+                </div>
+              </div>
+            ))}
+          </div>
+        </>
+      )}
       <hr className="my-4" />
       <h6 className="mb-3">Migration window</h6>
       <div className="container">
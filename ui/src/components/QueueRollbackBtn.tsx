@@ -0,0 +1,102 @@
+import { FC, useState } from "react";
+import { Button, Form } from "react-bootstrap";
+import { MdOutlineUndo } from "react-icons/md";
+import { useQueryClient } from "@tanstack/react-query";
+import { rollbackQueue } from "api/queue";
+import ModalWindow from "components/ModalWindow";
+import { useNotification } from "context/notificationContext";
+import { QueueEntry } from "types/queue";
+import { canRollbackQueueEntry } from "util/queue";
+
+interface Props {
+  queueEntry: QueueEntry;
+}
+
+const QueueRollbackBtn: FC<Props> = ({ queueEntry }) => {
+  const [showModal, setShowModal] = useState(false);
+  const [opInprogress, setOpInprogress] = useState(false);
+  const [deleteTarget, setDeleteTarget] = useState(false);
+  const { notify } = useNotification();
+  const queryClient = useQueryClient();
+
+  const rollbackStyle = {
+    cursor: "pointer",
+    color:
+      canRollbackQueueEntry(queueEntry) && !opInprogress ? "grey" : "lightgrey",
+  };
+
+  const handleRollback = () => {
+    if (!canRollbackQueueEntry(queueEntry) || opInprogress) {
+      return;
+    }
+
+    setOpInprogress(true);
+    rollbackQueue(queueEntry.instance_uuid, deleteTarget)
+      .then((response) => {
+        setOpInprogress(false);
+        setShowModal(false);
+        if (response.error_code == 0) {
+          notify.success(`Queue entry ${queueEntry.instance_uuid} rolled back`);
+          queryClient.invalidateQueries({ queryKey: ["queue"] });
+          return;
+        }
+        notify.error(response.error);
+      })
+      .catch((e) => {
+        setOpInprogress(false);
+        setShowModal(false);
+        notify.error(`Error during queue entry rollback: ${e}`);
+      });
+  };
+
+  return (
+    <>
+      <MdOutlineUndo
+        title="Rollback"
+        size={25}
+        style={rollbackStyle}
+        onClick={() => {
+          if (!canRollbackQueueEntry(queueEntry) || opInprogress) {
+            return;
+          }
+
+          setShowModal(true);
+        }}
+      />
+      <ModalWindow
+        show={showModal}
+        handleClose={() => setShowModal(false)}
+        title="Roll back queue entry"
+        footer={
+          <>
+            <Button variant="danger" onClick={handleRollback}>
+              Confirm
+            </Button>
+          </>
+        }
+      >
+        <p>
+          Are you sure you want to roll back the queue entry "
+          {queueEntry.instance_uuid}"?
+          <br />
+          The target instance will be stopped and renamed, and the source VM
+          will be powered on again if it was running before the migration.
+        </p>
+        <div className="my-3">
+          <Form.Group controlId="deleteTarget">
+            <Form.Check
+              type="checkbox"
+              label="Delete instance and volumes from target instead of renaming"
+              name="deleteTarget"
+              checked={deleteTarget}
+              onChange={(e) => setDeleteTarget(e.currentTarget.checked)}
+              disabled={opInprogress}
+            />
+          </Form.Group>
+        </div>
+      </ModalWindow>
+    </>
+  );
+};
+
+export default QueueRollbackBtn;
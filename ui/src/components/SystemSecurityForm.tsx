@@ -13,6 +13,8 @@ const SystemCertForm: FC<Props> = ({ security, onSubmit }) => {
   const formikInitialValues: SystemSecurity = {
     trusted_tls_client_cert_fingerprints:
       security?.trusted_tls_client_cert_fingerprints ?? [],
+    trusted_metrics_tls_client_cert_fingerprints:
+      security?.trusted_metrics_tls_client_cert_fingerprints ?? [],
     trusted_https_proxies: security?.trusted_https_proxies ?? [],
     oidc: {
       issuer: security?.oidc.issuer ?? "",
@@ -51,6 +53,10 @@ const SystemCertForm: FC<Props> = ({ security, onSubmit }) => {
           values.trusted_tls_client_cert_fingerprints.filter(
             (s) => s.trim() !== "",
           ),
+        trusted_metrics_tls_client_cert_fingerprints:
+          values.trusted_metrics_tls_client_cert_fingerprints.filter(
+            (s) => s.trim() !== "",
+          ),
         acme: {
           ...values.acme,
           provider_environment: values.acme.provider_environment.filter(
@@ -87,6 +93,28 @@ const SystemCertForm: FC<Props> = ({ security, onSubmit }) => {
                 onBlur={formik.handleBlur}
               />
             </Form.Group>
+            <Form.Group className="mb-3" controlId="trusted_metrics_tls_cert">
+              <Form.Label>
+                Trusted metrics TLS certification fingerprints
+              </Form.Label>
+              <Form.Control
+                type="text"
+                as="textarea"
+                rows={4}
+                name="trusted_metrics_tls_client_cert_fingerprints"
+                value={formik.values.trusted_metrics_tls_client_cert_fingerprints.join(
+                  "\n",
+                )}
+                onChange={(e) => {
+                  const lines = e.target.value.split("\n");
+                  formik.setFieldValue(
+                    "trusted_metrics_tls_client_cert_fingerprints",
+                    lines,
+                  );
+                }}
+                onBlur={formik.handleBlur}
+              />
+            </Form.Group>
             <Form.Group className="mb-3" controlId="trusted_https_proxies">
               <Form.Label>Trusted HTTPS proxies</Form.Label>
               <Form.Control
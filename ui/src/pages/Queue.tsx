@@ -3,6 +3,7 @@ import { useQuery } from "@tanstack/react-query";
 import { fetchQueue } from "api/queue";
 import DataTable from "components/DataTable";
 import QueueActions from "components/QueueActions";
+import { formatTransferProgress } from "util/queue";
 
 const Queue = () => {
   const refetchInterval = 10000; // 10 seconds
@@ -21,6 +22,7 @@ const Queue = () => {
     "Batch",
     "Status",
     "Detailed status",
+    "Transfer progress",
     "Target",
     "Target project",
     "Actions",
@@ -51,6 +53,10 @@ const Queue = () => {
           content: item.migration_status_message,
           sortKey: item.migration_status_message,
         },
+        {
+          content: formatTransferProgress(item.transfer_progress),
+          sortKey: item.transfer_progress?.bytes_copied,
+        },
         {
           content: item.placement.target_name,
           sortKey: item.placement.target_name,
@@ -1,4 +1,4 @@
-type ArtifactType = "sdk" | "os-image" | "driver";
+type ArtifactType = "sdk" | "os-image" | "driver" | "script";
 
 export interface Artifact {
   uuid?: string;
@@ -13,6 +13,7 @@ export interface BatchConfig {
   instance_restriction_overrides: InstanceRestrictionOverride;
   background_sync_interval: string;
   final_background_sync_limit: string;
+  rollback_grace_period: string;
 }
 
 export interface BatchPlacement {
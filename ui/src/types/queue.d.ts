@@ -9,6 +9,20 @@ export interface Placement {
   running: boolean;
 }
 
+export interface DiskProgress {
+  name: string;
+  bytes_copied: number;
+  bytes_total: number;
+}
+
+export interface DiskTransferProgress {
+  bytes_copied: number;
+  bytes_total: number;
+  bytes_per_second: number;
+  eta: string;
+  disks: DiskProgress[];
+}
+
 export interface QueueEntry {
   instance_uuid: string;
   instance_name: string;
@@ -17,4 +31,5 @@ export interface QueueEntry {
   batch_name: string;
   migration_window: MigrationWindow;
   placement: Placement;
+  transfer_progress?: DiskTransferProgress;
 }
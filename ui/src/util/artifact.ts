@@ -1,5 +1,6 @@
 export enum ArtifactType {
   Driver = "driver",
   OSImage = "os-image",
+  Script = "script",
   Sdk = "sdk",
 }
@@ -0,0 +1,33 @@
+import { test, expect } from "vitest";
+import { formatTransferProgress } from "util/queue";
+
+test("formatTransferProgress", () => {
+  expect(formatTransferProgress(undefined)).toBe("");
+  expect(
+    formatTransferProgress({
+      bytes_copied: 0,
+      bytes_total: 0,
+      bytes_per_second: 0,
+      eta: "0s",
+      disks: [],
+    }),
+  ).toBe("");
+  expect(
+    formatTransferProgress({
+      bytes_copied: 512,
+      bytes_total: 1024,
+      bytes_per_second: 0,
+      eta: "0s",
+      disks: [],
+    }),
+  ).toBe("50.00% (512.00 B / 1.00 KiB)");
+  expect(
+    formatTransferProgress({
+      bytes_copied: 1024,
+      bytes_total: 4096,
+      bytes_per_second: 2048,
+      eta: "1s",
+      disks: [],
+    }),
+  ).toBe("25.00% (1.00 KiB / 4.00 KiB), 2.00 KiB/s, ETA 1s");
+});
@@ -1,4 +1,5 @@
-import { QueueEntry } from "types/queue";
+import { DiskTransferProgress, QueueEntry } from "types/queue";
+import { bytesToHumanReadable } from "util/instance";
 
 export enum MigrationStatus {
   Blocked = "Blocked",
@@ -9,15 +10,23 @@ export enum MigrationStatus {
   FinalImport = "Performing final import tasks",
   PostImport = "Performing post-import tasks",
   WorkerDone = "Worker tasks complete",
+  Validating = "Running validation checks",
   Finished = "Finished",
   Error = "Error",
   Canceled = "Canceled",
   Conflict = "Conflict",
+  RolledBack = "Rolled back",
+  ValidationFailed = "Validation failed",
 }
 
 export const canDeleteQueueEntry = (queueEntry: QueueEntry) => {
   const status = queueEntry.migration_status;
-  if (status != MigrationStatus.Error && status != MigrationStatus.Finished) {
+  if (
+    status != MigrationStatus.Error &&
+    status != MigrationStatus.Finished &&
+    status != MigrationStatus.RolledBack &&
+    status != MigrationStatus.ValidationFailed
+  ) {
     return false;
   }
 
@@ -26,7 +35,10 @@ export const canDeleteQueueEntry = (queueEntry: QueueEntry) => {
 
 export const canCancelQueueEntry = (queueEntry: QueueEntry) => {
   const status = queueEntry.migration_status;
-  if (status != MigrationStatus.Canceled) {
+  if (
+    status != MigrationStatus.Canceled &&
+    status != MigrationStatus.RolledBack
+  ) {
     return true;
   }
 
@@ -42,6 +54,39 @@ export const canResolveQueueEntry = (queueEntry: QueueEntry) => {
   return false;
 };
 
+export const canRollbackQueueEntry = (queueEntry: QueueEntry) => {
+  const status = queueEntry.migration_status;
+  if (
+    status === MigrationStatus.Finished ||
+    status === MigrationStatus.RolledBack ||
+    status === MigrationStatus.ValidationFailed
+  ) {
+    return true;
+  }
+
+  return false;
+};
+
 export const canRetryQueueEntry = (queueEntry: QueueEntry) => {
   return !canCancelQueueEntry(queueEntry);
 };
+
+export const formatTransferProgress = (
+  progress: DiskTransferProgress | undefined,
+) => {
+  if (!progress || progress.bytes_total === 0) {
+    return "";
+  }
+
+  const percent = (progress.bytes_copied / progress.bytes_total) * 100;
+  let summary = `${percent.toFixed(2)}% (${bytesToHumanReadable(progress.bytes_copied)} / ${bytesToHumanReadable(progress.bytes_total)})`;
+  if (progress.bytes_per_second > 0) {
+    summary += `, ${bytesToHumanReadable(progress.bytes_per_second)}/s`;
+  }
+
+  if (progress.eta && progress.eta !== "0s") {
+    summary += `, ETA ${progress.eta}`;
+  }
+
+  return summary;
+};